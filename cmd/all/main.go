@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/buildinfo"
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/configdrift"
+	"github.com/geocoder89/eventhub/internal/db"
+	httpx "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cmd/all runs the HTTP API and the job worker in a single process. Both share
+// the pgx pool, the Prometheus registry and the tracer. Intended for small
+// deployments and local development; cmd/api + cmd/worker remain the default.
+func main() {
+	_ = godotenv.Load()
+	cfg := config.Load()
+
+	// Root context cancelled on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log := observability.NewLogger(cfg.Env)
+
+	if err := config.ValidateForAll(cfg); err != nil {
+		log.Error("invalid configuration", "err", err)
+		os.Exit(1)
+	}
+
+	log.Info("startup", "service", "eventhub-all", "instance", observability.InstanceID(), "build", buildinfo.Get(), "config", cfg.Snapshot())
+
+	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if otlpEndpoint == "" {
+		otlpEndpoint = "localhost:4317"
+	}
+
+	shutdownTracer, err := observability.InitTracer(context.Background(), "eventhub-all", otlpEndpoint)
+	if err != nil {
+		log.Error("otel init failed", "err", err)
+		os.Exit(1)
+	}
+	defer func() { _ = shutdownTracer(context.Background()) }()
+
+	base := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(observability.NewTraceHandler(base)))
+
+	pool, err := db.NewPool(cfg.DBURL)
+	if err != nil {
+		log.Error("db connection failed", "err", err)
+		os.Exit(1)
+	}
+
+	seedCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	err = db.EnsureAdminUser(seedCtx, pool, cfg)
+	cancel()
+	if err != nil {
+		pool.Close()
+		log.Error("failed to seed admin user", "err", err)
+		os.Exit(1)
+	}
+
+	// one registry for both halves so /metrics reports API and worker series together
+	reg := prometheus.NewRegistry()
+	prom := observability.NewProm(reg)
+
+	router, stopRouter := httpx.NewRouterWithMetrics(log, pool, cfg, reg, prom)
+
+	// announce this process's config fingerprint; a mismatch with the other
+	// live instances is logged as config.drift, now and on every refresh
+	driftDone := make(chan struct{})
+	go func() {
+		defer close(driftDone)
+		configdrift.NewReporter(postgres.NewServiceInstancesRepo(pool, prom), observability.InstanceID(), "all", cfg.Fingerprint()).Run(ctx)
+	}()
+
+	host, _ := os.Hostname()
+	workerID := host + "-" + strconv.Itoa(os.Getpid())
+
+	w, err := worker.NewFromConfig(cfg, pool, prom, workerID, cfg.WorkerHealthAddr)
+	if err != nil {
+		pool.Close()
+		log.Error("worker init failed", "err", err)
+		os.Exit(1)
+	}
+	w.PromRegistry = reg
+
+	// without a dedicated health listener the worker probes live on the API server
+	if cfg.WorkerHealthAddr == "" {
+		w.MountHealth(router, "/worker", reg)
+	}
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      cfg.WriteTimeout(),
+		IdleTimeout:       60 * time.Second,
+	}
+
+	go func() {
+		log.Info("server starting", "addr", srv.Addr, "env", cfg.Env, "worker_id", workerID)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("server failed", "err", err)
+			stop()
+		}
+	}()
+
+	// the worker gets its own context so it keeps draining after intake has stopped
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+
+	go func() {
+		defer close(workerDone)
+		if err := w.Run(workerCtx); err != nil {
+			// an API without its worker would accept jobs nobody runs
+			log.Error("worker.run_failed", "err", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("shutdown signal received")
+
+	// 1) stop HTTP intake
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("server graceful shutdown failed", "err", err)
+		_ = srv.Close()
+	} else {
+		log.Info("server stopped gracefully.")
+	}
+	// flush the router's buffered funnel counts while the pool is still open
+	stopRouter()
+
+	// 2) drain the worker
+	stopWorker()
+	<-workerDone
+	log.Info("worker stopped")
+
+	// 3) only now is nobody left using the pool
+	<-driftDone
+	pool.Close()
+}
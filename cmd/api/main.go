@@ -10,10 +10,13 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/geocoder89/eventhub/internal/buildinfo"
 	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/configdrift"
 	"github.com/geocoder89/eventhub/internal/db"
 	httpx "github.com/geocoder89/eventhub/internal/http"
 	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
 	"github.com/joho/godotenv"
 )
 
@@ -35,6 +38,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	// one line with everything needed to tell which build runs with what
+	log.Info("startup", "service", "eventhub-api", "instance", observability.InstanceID(), "build", buildinfo.Get(), "config", cfg.Snapshot())
+
 	pool, err := db.NewPool(cfg.DBURL)
 
 	if err != nil {
@@ -56,6 +62,14 @@ func main() {
 	}
 	cancel()
 
+	// announce this process's config fingerprint; a mismatch with the other
+	// live instances is logged as config.drift, now and on every refresh
+	driftDone := make(chan struct{})
+	go func() {
+		defer close(driftDone)
+		configdrift.NewReporter(postgres.NewServiceInstancesRepo(pool, nil), observability.InstanceID(), "api", cfg.Fingerprint()).Run(ctx)
+	}()
+
 	// setup log tracing with Open telemetry.
 
 	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
@@ -74,7 +88,7 @@ func main() {
 	base := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
 	slog.SetDefault(slog.New(observability.NewTraceHandler(base)))
 	// set up routers with the log
-	router := httpx.NewRouter(log, pool, cfg)
+	router, stopRouter := httpx.NewRouter(log, pool, cfg)
 
 	// server set up
 	srv := &http.Server{
@@ -82,7 +96,7 @@ func main() {
 		Handler:           router,
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       15 * time.Second,
-		WriteTimeout:      15 * time.Second,
+		WriteTimeout:      cfg.WriteTimeout(),
 		IdleTimeout:       60 * time.Second,
 	}
 
@@ -115,4 +129,8 @@ func main() {
 	} else {
 		log.Info("server stopped gracefully.")
 	}
+	// no requests are left to count, so the last funnel flush is complete
+	stopRouter()
+
+	<-driftDone
 }
@@ -8,10 +8,10 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
-	"time"
 
+	"github.com/geocoder89/eventhub/internal/buildinfo"
 	"github.com/geocoder89/eventhub/internal/config"
-	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/configdrift"
 	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/geocoder89/eventhub/internal/queue/worker"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
@@ -44,6 +44,7 @@ func main() {
 	base := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
 	logger := slog.New(observability.NewTraceHandler(base))
 	slog.SetDefault(logger)
+	logger.Info("startup", "service", "eventhub-worker", "instance", observability.InstanceID(), "build", buildinfo.Get(), "config", cfg.Snapshot())
 
 	pool, err := pgxpool.New(ctx, cfg.DBURL)
 	if err != nil {
@@ -52,55 +53,49 @@ func main() {
 	}
 	defer pool.Close()
 
-	// Prom registry (NOTE: you still need to expose /metrics on worker if you want to scrape it)
+	// served on the health server's /metrics
 	reg := prometheus.NewRegistry()
 	prom := observability.NewProm(reg)
 
-	jobsRepo := postgres.NewJobsRepo(pool, prom)
-	eventsRepo := postgres.NewEventsRepo(pool, prom)
-	registrationsRepo := postgres.NewRegistrationsRepo(pool, prom)
-	registrationCSVExportsRepo := postgres.NewRegistrationCSVExportsRepo(pool)
+	// announce this process's config fingerprint; a mismatch with the other
+	// live instances is logged as config.drift, now and on every refresh
+	driftDone := make(chan struct{})
+	go func() {
+		defer close(driftDone)
+		configdrift.NewReporter(postgres.NewServiceInstancesRepo(pool, prom), observability.InstanceID(), "worker", cfg.Fingerprint()).Run(ctx)
+	}()
 
 	host, _ := os.Hostname()
 	workerID := host + "-" + strconv.Itoa(os.Getpid())
 
-	healthAddr := os.Getenv("WORKER_HEALTH_ADDR")
+	healthAddr := cfg.WorkerHealthAddr
 	if healthAddr == "" {
 		healthAddr = ":8081"
 	}
 
-	baseNotifier := notifications.NewLogNotifier()
-	notifier := notifications.NewProtectedNotifier(baseNotifier, notifications.ProtectedNotifierConfig{
-		Timeout:          2 * time.Second,
-		FailureThreshold: 3,
-		Cooldown:         15 * time.Second,
-		HalfOpenMaxCalls: 1,
-	})
-
-	deliveriesRepo := postgres.NewNotificationsDeliveriesRepo(pool)
-
-	w := worker.New(worker.Config{
-		PollInterval:  2 * time.Second,
-		WorkerID:      workerID,
-		Concurrency:   1,
-		ShutdownGrace: 10 * time.Second,
-		LockTTL:       30 * time.Second,
-		HealthAddr:    healthAddr,
-	}, jobsRepo, eventsRepo, notifier, deliveriesRepo).
-		WithRegistrationCSVExporter(registrationsRepo, registrationCSVExportsRepo).
-		WithReadinessCheck(func(cctx context.Context) error {
-			return pool.Ping(cctx)
-		})
+	w, err := worker.NewFromConfig(cfg, pool, prom, workerID, healthAddr)
+	if err != nil {
+		slog.Default().ErrorContext(ctx, "worker init failed", "err", err)
+		os.Exit(1)
+	}
 	w.PromRegistry = reg
 
 	slog.Default().InfoContext(ctx, "worker.start",
 		"worker_id", workerID,
 		"health_addr", healthAddr,
+		"job_types", w.Handlers().Types(),
 	)
 
-	if err := w.Run(ctx); err != nil {
-		slog.Default().ErrorContext(ctx, "worker.run_failed", "err", err)
+	runErr := w.Run(ctx)
+	if runErr != nil {
+		slog.Default().ErrorContext(context.Background(), "worker.run_failed", "err", runErr)
 	}
+	<-driftDone
 
 	slog.Default().InfoContext(context.Background(), "worker.shutdown_complete")
+
+	if runErr != nil {
+		pool.Close()
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS event_funnel_daily (
+  event_id UUID NOT NULL,
+  day DATE NOT NULL,
+  stage TEXT NOT NULL,
+  reason TEXT NOT NULL DEFAULT 'ok',
+  count BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (event_id, day, stage, reason)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_funnel_daily;
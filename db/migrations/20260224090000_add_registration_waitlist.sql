@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE registrations
+ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'confirmed';
+
+ALTER TABLE registrations
+ADD COLUMN IF NOT EXISTS waitlist_position BIGINT NULL;
+
+ALTER TABLE registrations
+ADD CONSTRAINT registrations_status_check CHECK (status IN ('confirmed', 'waitlisted'));
+
+-- per-event waitlist sequence; bumped under the event row lock taken by CreateTx
+ALTER TABLE events
+ADD COLUMN IF NOT EXISTS waitlist_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_registrations_event_waitlist
+  ON registrations(event_id, waitlist_position)
+  WHERE status = 'waitlisted';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_registrations_event_waitlist;
+ALTER TABLE events DROP COLUMN IF EXISTS waitlist_seq;
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations DROP COLUMN IF EXISTS waitlist_position;
+ALTER TABLE registrations DROP COLUMN IF EXISTS status;
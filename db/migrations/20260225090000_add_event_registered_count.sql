@@ -0,0 +1,22 @@
+-- +goose Up
+-- cached confirmed-registration count; registrations remain the source of truth
+ALTER TABLE events
+ADD COLUMN IF NOT EXISTS registered_count INT NOT NULL DEFAULT 0;
+
+UPDATE events e
+SET registered_count = sub.n
+FROM (
+  SELECT event_id, COUNT(*)::int AS n
+  FROM registrations
+  WHERE status = 'confirmed'
+  GROUP BY event_id
+) sub
+WHERE sub.event_id = e.id;
+
+-- free-form outcome written by jobs that report something (e.g. events.verify_counters)
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS result JSONB NULL;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS result;
+ALTER TABLE events DROP COLUMN IF EXISTS registered_count;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- per-event registration restrictions; an empty domain list allows any email
+ALTER TABLE events
+ADD COLUMN IF NOT EXISTS requires_auth BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN IF NOT EXISTS allowed_email_domains TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN IF EXISTS allowed_email_domains;
+ALTER TABLE events DROP COLUMN IF EXISTS requires_auth;
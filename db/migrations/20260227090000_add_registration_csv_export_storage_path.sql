@@ -0,0 +1,16 @@
+-- +goose Up
+-- exports are streamed to an export store; csv_data is kept only for rows written before this
+ALTER TABLE registration_csv_exports
+ADD COLUMN IF NOT EXISTS storage_path TEXT NULL;
+
+ALTER TABLE registration_csv_exports
+ALTER COLUMN csv_data DROP NOT NULL;
+
+-- +goose Down
+DELETE FROM registration_csv_exports WHERE csv_data IS NULL;
+
+ALTER TABLE registration_csv_exports
+ALTER COLUMN csv_data SET NOT NULL;
+
+ALTER TABLE registration_csv_exports
+DROP COLUMN IF EXISTS storage_path;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- original response per idempotency key; BYTEA (not JSONB) so replays are byte-identical
+CREATE TABLE IF NOT EXISTS idempotent_responses (
+  idempotency_key TEXT PRIMARY KEY,
+  status_code INT NOT NULL,
+  body BYTEA NOT NULL,
+  job_id UUID NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotent_responses;
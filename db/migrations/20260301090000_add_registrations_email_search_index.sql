@@ -0,0 +1,7 @@
+-- +goose Up
+-- admin lookup of one person's registrations across all events, newest first
+CREATE INDEX IF NOT EXISTS idx_registrations_lower_email_created
+  ON registrations (LOWER(email), created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_registrations_lower_email_created;
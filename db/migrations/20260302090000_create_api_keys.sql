@@ -0,0 +1,27 @@
+-- +goose Up
+-- the user who created an event organizes it and may issue API keys for it
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS organizer_id UUID NULL REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_organizer_id ON events (organizer_id);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+  id UUID PRIMARY KEY,
+  owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL,
+  key_hash TEXT NOT NULL UNIQUE,
+  event_ids UUID[] NOT NULL DEFAULT '{}',
+  -- when set, the key covers every event with this organizer
+  organizer_id UUID NULL REFERENCES users(id) ON DELETE CASCADE,
+  actions TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  revoked_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_owner_id ON api_keys (owner_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
+DROP INDEX IF EXISTS idx_events_organizer_id;
+ALTER TABLE events DROP COLUMN IF EXISTS organizer_id;
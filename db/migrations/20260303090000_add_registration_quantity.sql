@@ -0,0 +1,12 @@
+-- +goose Up
+-- seats held by one registration (the registrant plus guests); events.registered_count
+-- now counts seats. Every existing row holds one seat, so the counter is already right.
+ALTER TABLE registrations
+  ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 1 CHECK (quantity >= 1);
+
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS max_quantity INT NOT NULL DEFAULT 1 CHECK (max_quantity >= 1);
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN IF EXISTS max_quantity;
+ALTER TABLE registrations DROP COLUMN IF EXISTS quantity;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- account export pages a user's events and jobs oldest first
+CREATE INDEX IF NOT EXISTS idx_events_organizer_created
+  ON events (organizer_id, created_at, id)
+  WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_user_created
+  ON jobs (user_id, created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_user_created;
+DROP INDEX IF EXISTS idx_events_organizer_created;
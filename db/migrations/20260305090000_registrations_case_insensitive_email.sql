@@ -0,0 +1,33 @@
+-- +goose Up
+-- flagged rows are mixed-case duplicates that predate the lowercase rule; they
+-- are kept for review (WHERE duplicate_of IS NOT NULL) instead of being dropped
+ALTER TABLE registrations
+  ADD COLUMN IF NOT EXISTS duplicate_of UUID NULL;
+
+-- keep the earliest registration per (event, lowercased email), flag the rest
+WITH ranked AS (
+  SELECT id,
+         FIRST_VALUE(id) OVER w AS keeper_id,
+         ROW_NUMBER() OVER w AS rn
+  FROM registrations
+  WINDOW w AS (PARTITION BY event_id, LOWER(email) ORDER BY created_at, id)
+)
+UPDATE registrations r
+SET duplicate_of = ranked.keeper_id
+FROM ranked
+WHERE r.id = ranked.id
+  AND ranked.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS registrations_event_lower_email_uniq
+  ON registrations (event_id, LOWER(email))
+  WHERE duplicate_of IS NULL;
+
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_event_email_uniq;
+
+-- +goose Down
+ALTER TABLE registrations
+  ADD CONSTRAINT registrations_event_email_uniq UNIQUE (event_id, email);
+
+DROP INDEX IF EXISTS registrations_event_lower_email_uniq;
+
+ALTER TABLE registrations DROP COLUMN IF EXISTS duplicate_of;
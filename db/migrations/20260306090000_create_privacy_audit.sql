@@ -0,0 +1,24 @@
+-- +goose Up
+-- one row per erasure request. The erased address itself is not kept, only
+-- its SHA-256, so a repeated request can still be matched to an earlier one.
+CREATE TABLE IF NOT EXISTS privacy_audit (
+  id UUID PRIMARY KEY,
+  action TEXT NOT NULL,
+  subject_email_sha256 TEXT NOT NULL,
+  requested_by_user_id UUID NULL,
+  requested_by_email TEXT NULL,
+  request_id TEXT NULL,
+  affected JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_privacy_audit_created_at
+  ON privacy_audit(created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_privacy_audit_subject
+  ON privacy_audit(subject_email_sha256);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_privacy_audit_subject;
+DROP INDEX IF EXISTS idx_privacy_audit_created_at;
+DROP TABLE IF EXISTS privacy_audit;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- percentages of capacity at which the organizer is alerted; an empty array disables alerts
+ALTER TABLE events
+ADD COLUMN IF NOT EXISTS capacity_alert_thresholds INT[] NOT NULL DEFAULT '{90,100}';
+
+-- deliveries that are not about one registration (organizer alerts) are
+-- deduplicated on (kind, dedupe_key) instead
+ALTER TABLE notification_deliveries
+ALTER COLUMN registration_id DROP NOT NULL,
+ADD COLUMN IF NOT EXISTS dedupe_key TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS notification_deliveries_kind_dedupe_key_uniq
+  ON notification_deliveries(kind, dedupe_key)
+  WHERE dedupe_key IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS notification_deliveries_kind_dedupe_key_uniq;
+DELETE FROM notification_deliveries WHERE registration_id IS NULL;
+ALTER TABLE notification_deliveries
+DROP COLUMN IF EXISTS dedupe_key,
+ALTER COLUMN registration_id SET NOT NULL;
+ALTER TABLE events DROP COLUMN IF EXISTS capacity_alert_thresholds;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- cancelling keeps the row (and its deliveries) as history instead of deleting it
+ALTER TABLE registrations
+ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ NULL;
+
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations
+ADD CONSTRAINT registrations_status_check CHECK (status IN ('confirmed', 'waitlisted', 'cancelled'));
+
+-- a cancelled attendee may register for the same event again
+DROP INDEX IF EXISTS registrations_event_lower_email_uniq;
+CREATE UNIQUE INDEX IF NOT EXISTS registrations_event_lower_email_uniq
+  ON registrations (event_id, LOWER(email))
+  WHERE duplicate_of IS NULL AND status <> 'cancelled';
+
+-- +goose Down
+-- cancelled rows would have been deleted before this migration
+DELETE FROM registrations WHERE status = 'cancelled';
+
+DROP INDEX IF EXISTS registrations_event_lower_email_uniq;
+CREATE UNIQUE INDEX IF NOT EXISTS registrations_event_lower_email_uniq
+  ON registrations (event_id, LOWER(email))
+  WHERE duplicate_of IS NULL;
+
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations
+ADD CONSTRAINT registrations_status_check CHECK (status IN ('confirmed', 'waitlisted'));
+
+ALTER TABLE registrations DROP COLUMN IF EXISTS cancelled_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- exports are deleted after the configured retention unless the requester asked to keep them longer
+ALTER TABLE registration_csv_exports
+ADD COLUMN IF NOT EXISTS keep_until TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_registration_csv_exports_created
+  ON registration_csv_exports(created_at, job_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_registration_csv_exports_created;
+
+ALTER TABLE registration_csv_exports
+DROP COLUMN IF EXISTS keep_until;
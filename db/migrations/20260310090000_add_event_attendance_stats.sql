@@ -0,0 +1,25 @@
+-- +goose Up
+-- confirmed attendees who never checked in are marked no_show once the event
+-- is finalized; their seats no longer count towards registered_count
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations
+ADD CONSTRAINT registrations_status_check CHECK (status IN ('confirmed', 'waitlisted', 'cancelled', 'no_show'));
+
+-- one row per finalized event; its presence is what makes finalization run once
+CREATE TABLE IF NOT EXISTS event_attendance_stats (
+  event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+  registrations INT NOT NULL,
+  checked_in INT NOT NULL,
+  no_shows INT NOT NULL,
+  finalized_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_attendance_stats;
+
+-- no-shows go back to confirmed; a recount restores registered_count
+UPDATE registrations SET status = 'confirmed' WHERE status = 'no_show';
+
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations
+ADD CONSTRAINT registrations_status_check CHECK (status IN ('confirmed', 'waitlisted', 'cancelled'));
@@ -0,0 +1,20 @@
+-- +goose Up
+-- optional window in which an event takes registrations; the API also keeps
+-- registration_closes_at at or before start_at
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS registration_opens_at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS registration_closes_at TIMESTAMPTZ NULL;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_registration_window_check;
+ALTER TABLE events
+ADD CONSTRAINT events_registration_window_check CHECK (
+  registration_opens_at IS NULL
+  OR registration_closes_at IS NULL
+  OR registration_opens_at < registration_closes_at
+);
+
+-- +goose Down
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_registration_window_check;
+ALTER TABLE events
+  DROP COLUMN IF EXISTS registration_closes_at,
+  DROP COLUMN IF EXISTS registration_opens_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- set once a user has proven they own their address; anonymous registrations
+-- made with it can then be claimed by the account
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+
+-- anonymous registrations are claimed by email
+CREATE INDEX IF NOT EXISTS idx_registrations_unclaimed_lower_email
+  ON registrations (LOWER(email))
+  WHERE user_id IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_registrations_unclaimed_lower_email;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
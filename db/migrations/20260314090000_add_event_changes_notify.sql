@@ -0,0 +1,25 @@
+-- +goose Up
+-- publishes the event id on event_changes whenever its seats change, whichever
+-- process made the change; live availability streams listen for it
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_event_changes() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('event_changes', NEW.id::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER events_notify_changes
+  AFTER UPDATE ON events
+  FOR EACH ROW
+  WHEN (
+    OLD.registered_count IS DISTINCT FROM NEW.registered_count
+    OR OLD.capacity IS DISTINCT FROM NEW.capacity
+    OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at
+  )
+  EXECUTE FUNCTION notify_event_changes();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS events_notify_changes ON events;
+DROP FUNCTION IF EXISTS notify_event_changes();
@@ -0,0 +1,12 @@
+-- +goose Up
+-- organizer branding for confirmation emails; NULL falls back to the platform sender
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS branding_reply_to TEXT NULL,
+  ADD COLUMN IF NOT EXISTS branding_display_name TEXT NULL,
+  ADD COLUMN IF NOT EXISTS branding_logo_url TEXT NULL;
+
+-- +goose Down
+ALTER TABLE events
+  DROP COLUMN IF EXISTS branding_logo_url,
+  DROP COLUMN IF EXISTS branding_display_name,
+  DROP COLUMN IF EXISTS branding_reply_to;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- wakes idle workers as soon as a job becomes claimable instead of on their
+-- next poll; the payload is the job type. Future run_at values are left to the
+-- poll; the one second of slack covers clock skew between app and database.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_jobs_new() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('jobs_new', NEW.type);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER jobs_notify_new
+  AFTER INSERT OR UPDATE OF status, run_at ON jobs
+  FOR EACH ROW
+  WHEN (NEW.status = 'pending' AND NEW.run_at <= NOW() + INTERVAL '1 second')
+  EXECUTE FUNCTION notify_jobs_new();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS jobs_notify_new ON jobs;
+DROP FUNCTION IF EXISTS notify_jobs_new();
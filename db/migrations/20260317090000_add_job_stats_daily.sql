@@ -0,0 +1,20 @@
+-- +goose Up
+-- per worker run and UTC day: what it claimed and how those jobs ended.
+-- worker_id is the worker id plus the run's start time; each run upserts its
+-- own row with its totals for the day, so restarts start a new row instead of
+-- losing the old one.
+CREATE TABLE IF NOT EXISTS job_stats_daily (
+  day DATE NOT NULL,
+  worker_id TEXT NOT NULL,
+  claimed BIGINT NOT NULL DEFAULT 0,
+  done BIGINT NOT NULL DEFAULT 0,
+  failed BIGINT NOT NULL DEFAULT 0,
+  retried BIGINT NOT NULL DEFAULT 0,
+  dead_lettered BIGINT NOT NULL DEFAULT 0,
+  timed_out BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (day, worker_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_stats_daily;
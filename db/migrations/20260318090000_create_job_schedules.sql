@@ -0,0 +1,22 @@
+-- +goose Up
+-- recurring jobs: the worker's scheduler enqueues a `type` job with `payload`
+-- whenever next_run_at has passed, then moves next_run_at to the cron
+-- expression's next match.
+CREATE TABLE IF NOT EXISTS job_schedules (
+  id UUID PRIMARY KEY,
+  type TEXT NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+  cron TEXT NOT NULL,
+  next_run_at TIMESTAMPTZ NOT NULL,
+  last_run_at TIMESTAMPTZ NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_schedules_due
+  ON job_schedules (next_run_at)
+  WHERE enabled;
+
+-- +goose Down
+DROP TABLE IF EXISTS job_schedules;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- admins can cancel jobs: pending ones move straight to 'cancelled', running
+-- ones get cancellation_requested and the worker aborts them on its next
+-- lock renewal.
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs
+  ADD CONSTRAINT jobs_status_check
+  CHECK (status IN ('pending', 'processing', 'done', 'failed', 'cancelled'));
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS cancellation_requested BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS cancellation_requested;
+
+UPDATE jobs SET status = 'failed', last_error = COALESCE(last_error, 'cancelled')
+WHERE status = 'cancelled';
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs
+  ADD CONSTRAINT jobs_status_check
+  CHECK (status IN ('pending', 'processing', 'done', 'failed'));
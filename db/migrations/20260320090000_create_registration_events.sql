@@ -0,0 +1,24 @@
+-- +goose Up
+-- registration lifecycle history: the repo writes one row per transition in
+-- the transaction that makes it, so organizers can reconstruct what happened
+-- to a sign-up. occurred_at uses clock_timestamp() so transitions made in one
+-- transaction (a cancellation and the promotion it frees) keep their order.
+CREATE TABLE IF NOT EXISTS registration_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  registration_id UUID NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+  event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL
+    CHECK (kind IN ('created', 'waitlisted', 'promoted', 'cancelled', 'transferred', 'checked_in', 'no_show')),
+  from_status TEXT NULL,
+  to_status TEXT NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_registration_events_registration
+  ON registration_events (registration_id, occurred_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_registration_events_event
+  ON registration_events (event_id, occurred_at, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS registration_events;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- terminal job failures are copied here in the statement that marks the job
+-- failed, so they survive pruning of the jobs table. A replay enqueues a fresh
+-- job and stamps replayed_at/replayed_job_id; retrying the failed job itself
+-- stamps its own id. At most one unreplayed dead letter exists per job.
+CREATE TABLE IF NOT EXISTS dead_letters (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  job_id UUID NOT NULL,
+  type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  attempts INT NOT NULL,
+  max_attempts INT NOT NULL,
+  priority INT NOT NULL DEFAULT 0,
+  idempotency_key TEXT NULL,
+  user_id UUID NULL,
+  job_created_at TIMESTAMPTZ NOT NULL,
+  failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  final_error TEXT NULL,
+  replayed_at TIMESTAMPTZ NULL,
+  replayed_job_id UUID NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS dead_letters_job_unreplayed_uniq
+  ON dead_letters (job_id)
+  WHERE replayed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_failed_at_id
+  ON dead_letters (failed_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_job_id
+  ON dead_letters (job_id, failed_at DESC);
+
+-- jobs that already failed
+INSERT INTO dead_letters (
+  job_id, type, payload, attempts, max_attempts, priority,
+  idempotency_key, user_id, job_created_at, failed_at, final_error
+)
+SELECT id, type, payload, attempts, max_attempts, priority,
+       idempotency_key, user_id, created_at, updated_at, last_error
+FROM jobs
+WHERE status = 'failed';
+
+-- +goose Down
+DROP TABLE IF EXISTS dead_letters;
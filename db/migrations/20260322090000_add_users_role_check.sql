@@ -0,0 +1,14 @@
+-- +goose Up
+-- roles are the user.Role constants. Rows written before the check get the
+-- case and spacing ParseRole forgives folded away; anything still unknown
+-- granted nothing before and becomes a plain user.
+UPDATE users SET role = lower(btrim(role)) WHERE role <> lower(btrim(role));
+UPDATE users SET role = 'user' WHERE role NOT IN ('user', 'admin');
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+  ADD CONSTRAINT users_role_check
+  CHECK (role IN ('user', 'admin'));
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- the latest attempt's queue wait (due to started) and run time, written by
+-- the worker when the attempt ends; NULL until a job has run.
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS queue_wait_ms BIGINT NULL,
+  ADD COLUMN IF NOT EXISTS execution_ms BIGINT NULL;
+
+-- +goose Down
+ALTER TABLE jobs
+  DROP COLUMN IF EXISTS execution_ms,
+  DROP COLUMN IF EXISTS queue_wait_ms;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- one row per job execution, written in batches by the worker after the
+-- fact. Best effort: a row can be missing, so this is history for debugging,
+-- not the source of truth for attempts.
+CREATE TABLE IF NOT EXISTS job_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  attempt INT NOT NULL,
+  worker_id TEXT NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NOT NULL,
+  outcome TEXT NOT NULL
+    CHECK (outcome IN ('done', 'retried', 'dead_lettered', 'cancelled', 'lock_lost', 'error')),
+  error TEXT NULL,
+  duration_ms BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_attempts_job
+  ON job_attempts (job_id, attempt, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_attempts;
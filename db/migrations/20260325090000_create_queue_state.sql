@@ -0,0 +1,16 @@
+-- +goose Up
+-- one row: the queue-wide switches admins flip during incidents. Workers
+-- read it before claiming, so a paused queue claims nothing while in-flight
+-- jobs finish.
+CREATE TABLE IF NOT EXISTS queue_state (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  paused BOOLEAN NOT NULL DEFAULT FALSE,
+  paused_at TIMESTAMPTZ NULL,
+  paused_by UUID NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO queue_state (id) VALUES (TRUE) ON CONFLICT (id) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS queue_state;
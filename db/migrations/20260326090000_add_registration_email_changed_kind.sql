@@ -0,0 +1,14 @@
+-- +goose Up
+-- organizers correct a mistyped attendee email; the change is recorded like a
+-- transition, with from_status = to_status and no addresses kept
+ALTER TABLE registration_events DROP CONSTRAINT IF EXISTS registration_events_kind_check;
+ALTER TABLE registration_events
+  ADD CONSTRAINT registration_events_kind_check
+  CHECK (kind IN ('created', 'waitlisted', 'promoted', 'cancelled', 'transferred', 'checked_in', 'no_show', 'email_changed'));
+
+-- +goose Down
+DELETE FROM registration_events WHERE kind = 'email_changed';
+ALTER TABLE registration_events DROP CONSTRAINT IF EXISTS registration_events_kind_check;
+ALTER TABLE registration_events
+  ADD CONSTRAINT registration_events_kind_check
+  CHECK (kind IN ('created', 'waitlisted', 'promoted', 'cancelled', 'transferred', 'checked_in', 'no_show'));
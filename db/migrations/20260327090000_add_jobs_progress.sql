@@ -0,0 +1,10 @@
+-- +goose Up
+-- the latest progress a long-running job reported, e.g.
+-- {"done": 1200, "total": 54000, "message": "..."}; NULL for jobs that never
+-- report.
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS progress JSONB NULL;
+
+-- +goose Down
+ALTER TABLE jobs
+  DROP COLUMN IF EXISTS progress;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- who cancelled a registration and why, for organizers: cancelled_by is the
+-- acting user's id, 'self' for the attendee or 'system'. The cancelled history
+-- row carries the same pair.
+ALTER TABLE registrations
+  ADD COLUMN IF NOT EXISTS cancelled_by TEXT NULL,
+  ADD COLUMN IF NOT EXISTS cancellation_reason TEXT NULL;
+
+ALTER TABLE registration_events
+  ADD COLUMN IF NOT EXISTS cancelled_by TEXT NULL,
+  ADD COLUMN IF NOT EXISTS cancellation_reason TEXT NULL;
+
+-- +goose Down
+ALTER TABLE registration_events
+  DROP COLUMN IF EXISTS cancellation_reason,
+  DROP COLUMN IF EXISTS cancelled_by;
+
+ALTER TABLE registrations
+  DROP COLUMN IF EXISTS cancellation_reason,
+  DROP COLUMN IF EXISTS cancelled_by;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- every API and worker process announces its config fingerprint here at
+-- startup and hourly, so instances that disagree (a different JWT secret, DB
+-- or queue settings) can be spotted. Only keyed hashes are stored, never a
+-- setting's value. A process removes its row when it stops; rows of crashed
+-- ones age out.
+CREATE TABLE IF NOT EXISTS service_instances (
+  id TEXT PRIMARY KEY,
+  service TEXT NOT NULL,
+  fingerprint TEXT NOT NULL,
+  fingerprint_parts JSONB NOT NULL DEFAULT '{}'::jsonb,
+  started_at TIMESTAMPTZ NOT NULL,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_instances_last_seen
+  ON service_instances (last_seen_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS service_instances;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- job types admins paused on their own, e.g. confirmations while the email
+-- provider is down. Workers leave pending jobs of these types unclaimed; the
+-- queue-wide switch in queue_state still stops everything.
+CREATE TABLE IF NOT EXISTS paused_job_types (
+  type TEXT PRIMARY KEY,
+  paused_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  paused_by UUID NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS paused_job_types;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Permanent send failures per recipient, counted within a window that starts
+-- at the first one; quarantined_at is set once they reach the threshold and
+-- stays until an operator lifts it (deleting the row).
+CREATE TABLE recipient_quarantines (
+  email TEXT PRIMARY KEY,
+  failures INT NOT NULL,
+  window_started_at TIMESTAMPTZ NOT NULL,
+  last_error TEXT NULL,
+  last_failed_at TIMESTAMPTZ NOT NULL,
+  quarantined_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX recipient_quarantines_quarantined_idx
+  ON recipient_quarantines (quarantined_at)
+  WHERE quarantined_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS recipient_quarantines;
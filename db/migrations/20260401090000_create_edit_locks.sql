@@ -0,0 +1,13 @@
+-- +goose Up
+-- Advisory edit locks on events, one per event. A row past expires_at is
+-- dead: acquiring takes it over and reading it deletes it.
+CREATE TABLE edit_locks (
+  event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+  holder_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token TEXT NOT NULL,
+  acquired_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS edit_locks;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- W3C traceparent/tracestate of the span that enqueued the job, so the
+-- worker's job.run span joins the producer's trace. NULL for jobs enqueued
+-- outside a trace.
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS trace_parent TEXT NULL,
+  ADD COLUMN IF NOT EXISTS trace_state TEXT NULL;
+
+-- +goose Down
+ALTER TABLE jobs
+  DROP COLUMN IF EXISTS trace_state,
+  DROP COLUMN IF EXISTS trace_parent;
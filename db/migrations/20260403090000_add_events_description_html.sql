@@ -0,0 +1,10 @@
+-- +goose Up
+-- the description rendered as HTML, gzipped. Lists skip it unless asked, so
+-- it costs nothing on the hot path; NULL rows (written before this column)
+-- are rendered from description when read.
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS description_html_gz BYTEA NULL;
+
+-- +goose Down
+ALTER TABLE events
+  DROP COLUMN IF EXISTS description_html_gz;
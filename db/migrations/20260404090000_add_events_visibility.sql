@@ -0,0 +1,16 @@
+-- +goose Up
+-- public events are listed; unlisted and private ones are only reachable by
+-- id. Listings filter on visibility = 'public', hence the partial index.
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+CREATE INDEX IF NOT EXISTS idx_events_listed_start_at_id
+  ON events(start_at ASC, id ASC)
+  WHERE deleted_at IS NULL AND visibility = 'public';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_listed_start_at_id;
+
+ALTER TABLE events
+  DROP COLUMN IF EXISTS visibility;
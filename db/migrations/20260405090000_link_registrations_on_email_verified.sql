@@ -0,0 +1,36 @@
+-- +goose Up
+-- once a user's email is verified, their past registrations made with it are
+-- linked to the account straight away, wherever the verification happened.
+-- An email another verified account also holds links nothing; the
+-- registrations.link_users job reports those.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION link_registrations_on_email_verified() RETURNS trigger AS $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM users o
+    WHERE o.id <> NEW.id
+      AND o.email_verified_at IS NOT NULL
+      AND LOWER(o.email) = LOWER(NEW.email)
+  ) THEN
+    RETURN NULL;
+  END IF;
+
+  UPDATE registrations
+  SET user_id = NEW.id,
+      updated_at = NOW()
+  WHERE user_id IS NULL
+    AND LOWER(email) = LOWER(NEW.email);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER users_link_registrations_on_email_verified
+  AFTER UPDATE OF email_verified_at ON users
+  FOR EACH ROW
+  WHEN (OLD.email_verified_at IS NULL AND NEW.email_verified_at IS NOT NULL)
+  EXECUTE FUNCTION link_registrations_on_email_verified();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS users_link_registrations_on_email_verified ON users;
+DROP FUNCTION IF EXISTS link_registrations_on_email_verified();
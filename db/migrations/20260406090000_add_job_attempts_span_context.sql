@@ -0,0 +1,11 @@
+-- +goose Up
+-- the span each attempt ran under, so a retry's span can link back to the
+-- attempts before it
+ALTER TABLE job_attempts
+  ADD COLUMN IF NOT EXISTS trace_id TEXT NULL,
+  ADD COLUMN IF NOT EXISTS span_id TEXT NULL;
+
+-- +goose Down
+ALTER TABLE job_attempts
+  DROP COLUMN IF EXISTS span_id,
+  DROP COLUMN IF EXISTS trace_id;
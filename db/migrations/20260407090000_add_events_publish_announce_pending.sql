@@ -0,0 +1,10 @@
+-- +goose Up
+-- set in the same UPDATE that publishes an event and cleared once every
+-- attendee's announcement is queued, so only the publish that did it (or its
+-- retry) announces, and only once
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS publish_announce_pending BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE events
+  DROP COLUMN IF EXISTS publish_announce_pending;
@@ -0,0 +1,83 @@
+// Package alerting pings a human about conditions the queue cannot fix on its
+// own: dead-lettered jobs, open circuit breakers, piles of stale jobs. It is a
+// hook, not an alerting stack; environments without Slack just log.
+package alerting
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Alerter delivers one alert. Labels identify what the alert is about (job
+// type, notifier, ...) and are part of what makes two alerts identical.
+type Alerter interface {
+	Notify(ctx context.Context, severity Severity, title, body string, labels map[string]string) error
+}
+
+// LogAlerter writes alerts to the log only.
+type LogAlerter struct {
+	log *slog.Logger
+}
+
+// NewLogAlerter logs to log, or to slog.Default() when log is nil.
+func NewLogAlerter(log *slog.Logger) *LogAlerter {
+	return &LogAlerter{log: log}
+}
+
+func (a *LogAlerter) Notify(ctx context.Context, severity Severity, title, body string, labels map[string]string) error {
+	log := a.log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	level := slog.LevelWarn
+	switch severity {
+	case SeverityInfo:
+		level = slog.LevelInfo
+	case SeverityCritical:
+		level = slog.LevelError
+	}
+
+	log.Log(ctx, level, "alert",
+		"severity", string(severity),
+		"title", title,
+		"body", body,
+		"labels", formatLabels(labels),
+	)
+	return nil
+}
+
+// formatLabels renders labels as "k=v, k=v" in key order.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NewDefault is the alerter the binaries use: Slack when webhookURL is set,
+// the log otherwise, with identical alerts collapsed for DefaultDedupWindow.
+func NewDefault(webhookURL string, maxPerMinute int) *Deduper {
+	var inner Alerter = NewLogAlerter(nil)
+	if strings.TrimSpace(webhookURL) != "" {
+		inner = NewSlackAlerter(webhookURL).WithRateLimit(maxPerMinute)
+	}
+	return NewDeduper(inner, DefaultDedupWindow)
+}
@@ -0,0 +1,122 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sentAlert struct {
+	severity Severity
+	title    string
+	body     string
+}
+
+type fakeAlerter struct {
+	mu   sync.Mutex
+	sent []sentAlert
+}
+
+func (f *fakeAlerter) Notify(ctx context.Context, severity Severity, title, body string, labels map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, sentAlert{severity, title, body})
+	return nil
+}
+
+func TestDeduper_CollapsesIdenticalAlertsWithinWindow(t *testing.T) {
+	inner := &fakeAlerter{}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := NewDeduper(inner, 10*time.Minute).WithClock(func() time.Time { return now })
+	ctx := context.Background()
+	labels := map[string]string{"job_type": "email.send"}
+
+	for i := 0; i < 5; i++ {
+		_ = d.Notify(ctx, SeverityCritical, "job dead-lettered", "boom", labels)
+	}
+	// a different label set is a different alert
+	_ = d.Notify(ctx, SeverityCritical, "job dead-lettered", "boom", map[string]string{"job_type": "webhook.deliver"})
+
+	if len(inner.sent) != 2 {
+		t.Fatalf("expected 2 alerts forwarded, got %d", len(inner.sent))
+	}
+
+	now = now.Add(10 * time.Minute)
+	_ = d.Notify(ctx, SeverityCritical, "job dead-lettered", "boom again", labels)
+
+	if len(inner.sent) != 3 {
+		t.Fatalf("expected the alert after the window to be forwarded, got %d", len(inner.sent))
+	}
+	if body := inner.sent[2].body; !strings.HasPrefix(body, "boom again") || !strings.Contains(body, "4 identical alerts suppressed") {
+		t.Fatalf("expected the suppressed count in the body, got %q", body)
+	}
+
+	// the counter starts over with the new run
+	now = now.Add(11 * time.Minute)
+	_ = d.Notify(ctx, SeverityCritical, "job dead-lettered", "quiet", labels)
+	if body := inner.sent[3].body; body != "quiet" {
+		t.Fatalf("expected no suppressed count, got %q", body)
+	}
+}
+
+func TestSlackAlerter_PostsAndRateLimits(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		texts = append(texts, body.Text)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := NewSlackAlerter(srv.URL).WithRateLimit(2)
+	a.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := a.Notify(ctx, SeverityWarning, "circuit open", "notifier failing", map[string]string{"notifier": "email"}); err != nil {
+			t.Fatalf("notify %d: %v", i, err)
+		}
+	}
+	if err := a.Notify(ctx, SeverityWarning, "circuit open", "notifier failing", nil); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	now = now.Add(time.Minute + time.Second)
+	if err := a.Notify(ctx, SeverityWarning, "circuit open", "recovered window", nil); err != nil {
+		t.Fatalf("expected a fresh minute to allow sends, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(texts) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(texts))
+	}
+	if !strings.Contains(texts[0], "[warning] circuit open") || !strings.Contains(texts[0], "notifier=email") {
+		t.Fatalf("unexpected message %q", texts[0])
+	}
+}
+
+func TestSlackAlerter_ReportsWebhookErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := NewSlackAlerter(srv.URL).Notify(context.Background(), SeverityCritical, "t", "b", nil)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultDedupWindow is how long identical alerts collapse into one.
+const DefaultDedupWindow = 10 * time.Minute
+
+// Deduper forwards the first of a run of identical alerts (same severity,
+// title and labels) and swallows the rest for window. The next alert sent
+// after the window carries a count of the ones swallowed.
+type Deduper struct {
+	inner  Alerter
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	recent map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	since      time.Time
+	suppressed int
+}
+
+func NewDeduper(inner Alerter, window time.Duration) *Deduper {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	return &Deduper{
+		inner:  inner,
+		window: window,
+		now:    time.Now,
+		recent: make(map[string]*dedupEntry),
+	}
+}
+
+// WithClock replaces time.Now, so tests can step across the window.
+func (d *Deduper) WithClock(now func() time.Time) *Deduper {
+	if now != nil {
+		d.now = now
+	}
+	return d
+}
+
+func (d *Deduper) Notify(ctx context.Context, severity Severity, title, body string, labels map[string]string) error {
+	key := string(severity) + "\x00" + title + "\x00" + formatLabels(labels)
+	now := d.now()
+
+	d.mu.Lock()
+	e, ok := d.recent[key]
+	if ok && now.Sub(e.since) < d.window {
+		e.suppressed++
+		d.mu.Unlock()
+		return nil
+	}
+	if ok && e.suppressed > 0 {
+		body += fmt.Sprintf("\n(%d identical alerts suppressed since %s)", e.suppressed, e.since.UTC().Format(time.RFC3339))
+	}
+	d.recent[key] = &dedupEntry{since: now}
+	d.prune(now)
+	d.mu.Unlock()
+
+	return d.inner.Notify(ctx, severity, title, body, labels)
+}
+
+// prune forgets quiet runs, so one-off labels (say, a job type that failed
+// once) do not accumulate. Callers hold d.mu.
+func (d *Deduper) prune(now time.Time) {
+	for k, e := range d.recent {
+		if e.suppressed == 0 && now.Sub(e.since) >= d.window {
+			delete(d.recent, k)
+		}
+	}
+}
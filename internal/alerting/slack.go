@@ -0,0 +1,105 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned for alerts dropped because too many were sent in
+// the current minute.
+var ErrRateLimited = errors.New("alert rate limit reached")
+
+// SlackAlerter posts alerts to a Slack incoming webhook. It sends at most
+// maxPerMinute alerts a minute so an incident cannot flood the channel; the
+// rest are dropped with ErrRateLimited.
+type SlackAlerter struct {
+	url    string
+	client *http.Client
+
+	mu           sync.Mutex
+	maxPerMinute int
+	windowEnd    time.Time
+	sent         int
+	now          func() time.Time
+}
+
+func NewSlackAlerter(webhookURL string) *SlackAlerter {
+	return &SlackAlerter{
+		url:          webhookURL,
+		client:       &http.Client{Timeout: 3 * time.Second},
+		maxPerMinute: 10,
+		now:          time.Now,
+	}
+}
+
+// WithRateLimit caps alerts per minute; n <= 0 keeps the default of 10.
+func (a *SlackAlerter) WithRateLimit(n int) *SlackAlerter {
+	if n > 0 {
+		a.maxPerMinute = n
+	}
+	return a
+}
+
+// WithHTTPClient replaces the default client (3s timeout).
+func (a *SlackAlerter) WithHTTPClient(c *http.Client) *SlackAlerter {
+	if c != nil {
+		a.client = c
+	}
+	return a
+}
+
+func (a *SlackAlerter) Notify(ctx context.Context, severity Severity, title, body string, labels map[string]string) error {
+	if !a.allow() {
+		return ErrRateLimited
+	}
+
+	text := fmt.Sprintf("*[%s] %s*\n%s", severity, title, body)
+	if len(labels) > 0 {
+		text += "\n`" + formatLabels(labels) + "`"
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("slack webhook: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *SlackAlerter) allow() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if now.After(a.windowEnd) {
+		a.windowEnd = now.Add(time.Minute)
+		a.sent = 0
+	}
+	if a.sent >= a.maxPerMinute {
+		return false
+	}
+	a.sent++
+	return true
+}
@@ -7,16 +7,17 @@ import (
 	"errors"
 	"time"
 
+	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 type Claims struct {
-	UserID    string `json:"sub"`
-	Email     string `json:"email"`
-	Role      string `json:"role"`
-	TokenType string `json:"typ"`
-	JTI       string `json:"jti"`
+	UserID    string    `json:"sub"`
+	Email     string    `json:"email"`
+	Role      user.Role `json:"role"`
+	TokenType string    `json:"typ"`
+	JTI       string    `json:"jti"`
 	jwt.RegisteredClaims
 }
 
@@ -34,7 +35,7 @@ func NewManager(secret string, accessTTL time.Duration, refreshTTL time.Duration
 	}
 }
 
-func (m *Manager) GenerateAccessToken(userID, email, role string) (string, error) {
+func (m *Manager) GenerateAccessToken(userID, email string, role user.Role) (string, error) {
 	now := time.Now().UTC()
 
 	claims := Claims{
@@ -53,7 +54,7 @@ func (m *Manager) GenerateAccessToken(userID, email, role string) (string, error
 	return token.SignedString(m.secret)
 }
 
-func (m *Manager) GenerateRefreshToken(userID, email, role string) (raw string, jti string, expiresAt time.Time, err error) {
+func (m *Manager) GenerateRefreshToken(userID, email string, role user.Role) (raw string, jti string, expiresAt time.Time, err error) {
 	now := time.Now().UTC()
 	jti = uuid.NewString()
 	expiresAt = now.Add(m.refreshTTL)
@@ -0,0 +1,65 @@
+// Package buildinfo identifies the running binary. Release builds stamp the
+// variables below through the linker:
+//
+//	go build -ldflags "-X github.com/geocoder89/eventhub/internal/buildinfo.Version=v1.4.0 \
+//	  -X github.com/geocoder89/eventhub/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/geocoder89/eventhub/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unstamped builds fall back to the VCS details the Go toolchain embeds.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set through -ldflags -X; see the package doc.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info is the build metadata logged at startup and served by the admin
+// diagnostics endpoint.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// Get returns the stamped metadata, filling an unstamped commit and build
+// time from the toolchain's VCS settings when the binary has them.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
@@ -0,0 +1,29 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet_UsesStampedValues(t *testing.T) {
+	defer func(v, c, b string) { Version, Commit, BuildTime = v, c, b }(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.3", "abc123", "2026-03-25T09:00:00Z"
+
+	got := Get()
+	if got.Version != "v1.2.3" || got.Commit != "abc123" || got.BuildTime != "2026-03-25T09:00:00Z" {
+		t.Fatalf("expected the stamped values, got %+v", got)
+	}
+	if got.GoVersion != runtime.Version() {
+		t.Fatalf("expected go version %q, got %q", runtime.Version(), got.GoVersion)
+	}
+}
+
+func TestGet_UnstampedBuildStillIdentifiesItself(t *testing.T) {
+	defer func(v, c, b string) { Version, Commit, BuildTime = v, c, b }(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "dev", "", ""
+
+	got := Get()
+	if got.Version != "dev" || got.Commit == "" || got.GoVersion == "" {
+		t.Fatalf("expected version, commit and go version to be present, got %+v", got)
+	}
+}
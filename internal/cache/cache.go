@@ -6,13 +6,15 @@ import (
 )
 
 type Cache struct {
-	mu  sync.RWMutex
-	ttl time.Duration
-	m   map[string]entry
+	mu       sync.RWMutex
+	ttl      time.Duration
+	maxStale time.Duration
+	m        map[string]entry
 }
 type entry struct {
-	val any
-	exp time.Time
+	val    any
+	stored time.Time
+	exp    time.Time
 }
 
 func New(ttl time.Duration) *Cache {
@@ -26,6 +28,15 @@ func New(ttl time.Duration) *Cache {
 	}
 }
 
+// WithMaxStale keeps expired entries for d longer, so GetStale can still
+// return them when the source of truth is unavailable. Get never does.
+func (c *Cache) WithMaxStale(d time.Duration) *Cache {
+	if d > 0 {
+		c.maxStale = d
+	}
+	return c
+}
+
 func (c *Cache) Get(key string) (any, bool) {
 	now := time.Now()
 	c.mu.RLock()
@@ -36,18 +47,44 @@ func (c *Cache) Get(key string) (any, bool) {
 	}
 
 	if now.After(e.exp) {
-		c.mu.Lock()
-		delete(c.m, key)
-		c.mu.Unlock()
+		if now.After(e.exp.Add(c.maxStale)) {
+			c.mu.Lock()
+			delete(c.m, key)
+			c.mu.Unlock()
+		}
 		return nil, false
 	}
 
 	return e.val, true
 }
 
+// GetStale returns the entry for key even after it expired, as long as it is
+// within the max-stale window, along with how long ago it was stored.
+func (c *Cache) GetStale(key string) (any, time.Duration, bool) {
+	now := time.Now()
+	c.mu.RLock()
+	e, ok := c.m[key]
+	c.mu.RUnlock()
+	if !ok || now.After(e.exp.Add(c.maxStale)) {
+		return nil, 0, false
+	}
+
+	return e.val, now.Sub(e.stored), true
+}
+
 func (c *Cache) Set(key string, val any) {
+	now := time.Now()
+	c.mu.Lock()
+	c.m[key] = entry{val: val, stored: now, exp: now.Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+// SetWithTTL stores val for ttl instead of the cache's own TTL, for entries
+// that share a cache (and its Clear) with shorter-lived ones.
+func (c *Cache) SetWithTTL(key string, val any, ttl time.Duration) {
+	now := time.Now()
 	c.mu.Lock()
-	c.m[key] = entry{val: val, exp: time.Now().Add(c.ttl)}
+	c.m[key] = entry{val: val, stored: now, exp: now.Add(ttl)}
 	c.mu.Unlock()
 }
 
@@ -62,3 +99,14 @@ func (c *Cache) Clear() {
 	c.m = make(map[string]entry)
 	c.mu.Unlock()
 }
+
+// Len is the number of entries held, including expired ones still kept for
+// GetStale or not yet evicted by a Get.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.m)
+}
@@ -0,0 +1,99 @@
+// Package canceltoken issues and verifies HMAC-signed tokens that let an
+// attendee cancel their own registration without logging in.
+package canceltoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid cancellation token")
+	ErrExpired = errors.New("cancellation token expired")
+)
+
+// Claims identify the registration a token may cancel.
+type Claims struct {
+	RegistrationID string
+	EventID        string
+	ExpiresAt      time.Time
+}
+
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{
+		secret: []byte(secret),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Sign returns "<payload>.<signature>", both base64url without padding.
+// The payload is "<registrationID>:<eventID>:<expiresAtUnix>".
+func (s *Signer) Sign(registrationID, eventID string) string {
+	exp := s.now().Add(s.ttl).Unix()
+	payload := registrationID + ":" + eventID + ":" + strconv.FormatInt(exp, 10)
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(s.mac(payload))
+}
+
+func (s *Signer) Verify(token string) (Claims, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalid
+	}
+
+	enc := base64.RawURLEncoding
+	rawPayload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return Claims{}, ErrInvalid
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil {
+		return Claims{}, ErrInvalid
+	}
+
+	payload := string(rawPayload)
+	if !hmac.Equal(sig, s.mac(payload)) {
+		return Claims{}, ErrInvalid
+	}
+
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return Claims{}, ErrInvalid
+	}
+
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Claims{}, ErrInvalid
+	}
+
+	claims := Claims{
+		RegistrationID: parts[0],
+		EventID:        parts[1],
+		ExpiresAt:      time.Unix(exp, 0).UTC(),
+	}
+
+	if !s.now().Before(claims.ExpiresAt) {
+		return Claims{}, ErrExpired
+	}
+
+	return claims, nil
+}
+
+func (s *Signer) mac(payload string) []byte {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
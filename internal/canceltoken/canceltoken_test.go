@@ -0,0 +1,64 @@
+package canceltoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	s := NewSigner("test-secret", time.Hour)
+
+	token := s.Sign("reg-1", "event-1")
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.RegistrationID != "reg-1" || claims.EventID != "event-1" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyRejectsExpired(t *testing.T) {
+	s := NewSigner("test-secret", time.Hour)
+	issued := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return issued }
+
+	token := s.Sign("reg-1", "event-1")
+
+	s.now = func() time.Time { return issued.Add(2 * time.Hour) }
+	if _, err := s.Verify(token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+}
+
+func TestVerifyRejectsTampered(t *testing.T) {
+	s := NewSigner("test-secret", time.Hour)
+	token := s.Sign("reg-1", "event-1")
+	payload, sig, _ := strings.Cut(token, ".")
+
+	other := NewSigner("other-secret", time.Hour).Sign("reg-1", "event-1")
+	otherPayload, _, _ := strings.Cut(other, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "no separator", token: payload},
+		{name: "bad encoding", token: payload + ".!!!"},
+		{name: "signature from another secret", token: other},
+		{name: "swapped payload", token: otherPayload + "x." + sig},
+		{name: "truncated signature", token: payload + "." + sig[:len(sig)-2]},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.Verify(tc.token); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+}
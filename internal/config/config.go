@@ -3,11 +3,15 @@ package config
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/geocoder89/eventhub/internal/security"
 )
 
 type Config struct {
@@ -25,6 +29,177 @@ type Config struct {
 	RedisAddr           string
 	RedisPassword       string
 	RedisDB             int
+
+	// empty means "use the default" for the standalone worker and
+	// "serve health under /worker/* on the API port" for the all-in-one binary
+	WorkerHealthAddr string
+
+	// how long after start_at an event still accepts registrations (door sign-ups)
+	RegistrationGraceMinutes int
+
+	// per-request DB query budget, enforced in dev/test only
+	QueryBudget         int
+	QueryBudgetHardFail bool
+
+	// signs self-service cancellation links; falls back to JWTSecret when empty
+	CancelTokenSecret   string
+	CancelTokenTTLHours int
+
+	// directory for generated exports; the API and worker must share it
+	ExportsDir string
+
+	// signs emailed account export download links; falls back to JWTSecret when empty
+	ExportLinkSecret   string
+	ExportLinkTTLHours int
+
+	// signs emailed address verification links; falls back to JWTSecret when empty
+	EmailVerifySecret   string
+	EmailVerifyTTLHours int
+
+	// keys the hash of the signing secrets in the config fingerprint; when
+	// empty the secrets are left out of it
+	ConfigFingerprintKey string
+
+	// registration exports are deleted this long after they are written;
+	// requesters may ask to keep one longer, up to ExportKeepMaxDays
+	ExportRetentionDays int
+	ExportKeepMaxDays   int
+
+	// attendance is finalized (no-shows marked) this long after an event starts
+	AttendanceFinalizeHours int
+
+	// pending jobs gain one claim priority point per JobAgingInterval past
+	// run_at, up to JobAgingMaxBoost; a zero interval keeps strict priority order
+	JobAgingInterval time.Duration
+	JobAgingMaxBoost int
+
+	// a single worker job run is cut off after JobTimeout and retried
+	JobTimeout time.Duration
+
+	// per-type caps on jobs running at once in one worker, as
+	// "type=n,type=n"; see TypeConcurrency
+	JobTypeConcurrency string
+
+	// enqueues whose payload is larger than JobPayloadMaxBytes or nested
+	// deeper than JobPayloadMaxDepth are refused; zero turns a check off
+	JobPayloadMaxBytes int
+	JobPayloadMaxDepth int
+
+	// each worker writes its totals for the day to job_stats_daily every
+	// JobStatsFlushInterval, and once more when it shuts down
+	JobStatsFlushInterval time.Duration
+
+	// how often workers look for recurring schedules that are due
+	JobSchedulerInterval time.Duration
+
+	// the worker's /readyz says "degraded": true once the oldest due pending
+	// job has waited longer than this; zero never degrades
+	WorkerDegradedPendingAge time.Duration
+
+	// registers the test.* job types the e2e scripts crash and drain workers
+	// with; production workers leave it off and dead-letter them
+	WorkerEnableTestJobs bool
+
+	// the API refuses new jobs with 503 while more than MaxPending jobs are
+	// due or the oldest due job has waited longer than MaxAge. Deferrable
+	// work (exports, reports, publishes scheduled far ahead) has its own,
+	// lower thresholds than standard publishes; confirmations are never
+	// refused. Zero turns a check off.
+	EnqueueGuardDeferrableMaxPending int
+	EnqueueGuardDeferrableMaxAge     time.Duration
+	EnqueueGuardStandardMaxPending   int
+	EnqueueGuardStandardMaxAge       time.Duration
+
+	// dead-letters, open notifier circuits and stale requeue storms post to
+	// this Slack webhook (logged only when empty), at most
+	// AlertSlackMaxPerMinute a minute; a requeue pass taking back at least
+	// AlertStaleRequeueThreshold jobs alerts, zero never does
+	AlertSlackWebhookURL       string
+	AlertSlackMaxPerMinute     int
+	AlertStaleRequeueThreshold int
+
+	// a recipient with RecipientQuarantineThreshold permanent send failures
+	// within RecipientQuarantineWindow is quarantined; zero never quarantines
+	RecipientQuarantineThreshold int
+	RecipientQuarantineWindow    time.Duration
+
+	// platform sender for outgoing email; EmailFromOrganizerName lets an
+	// event's branding display name replace EmailFromName in From
+	EmailFromAddress       string
+	EmailFromName          string
+	EmailReplyTo           string
+	EmailFromOrganizerName bool
+
+	// the page confirmation emails link to for cancelling, ?token= added;
+	// empty leaves the link out
+	EmailCancelPageURL string
+
+	// the page verification emails link to, ?token= added; it should call
+	// POST /auth/verify-email. Empty leaves the link out
+	EmailVerifyPageURL string
+
+	// NotifierDriver picks how the worker sends email: "log" only logs it,
+	// "smtp" submits it to SMTPHost:SMTPPort with STARTTLS when offered,
+	// "webhook" POSTs it, signed, to NotifierWebhookURL
+	NotifierDriver        string
+	SMTPHost              string
+	SMTPPort              int
+	SMTPUsername          string
+	SMTPPassword          string
+	NotifierWebhookURL    string
+	NotifierWebhookSecret string
+
+	// scheme for new password hashes; older schemes still verify and are
+	// upgraded on the user's next login. Zero costs take the defaults.
+	PasswordHashScheme        string
+	PasswordBcryptCost        int
+	PasswordArgon2MemoryKiB   int
+	PasswordArgon2Iterations  int
+	PasswordArgon2Parallelism int
+
+	// ?limit bounds for admin bulk operations; larger requests are clamped
+	// and flagged to the caller
+	AdminBulkDefaultLimit int
+	AdminBulkMaxLimit     int
+
+	// DB budgets handlers get from handlers.DBTimeout, per route class; each
+	// must be shorter than HTTPWriteTimeout so the error still reaches the client
+	HandlerDBTimeout time.Duration
+	AdminDBTimeout   time.Duration
+	ExportDBTimeout  time.Duration
+	HTTPWriteTimeout time.Duration
+
+	// how long past its TTL a cached GET /events page may still be served
+	// when the database read fails; zero turns stale serving off
+	EventsCacheMaxStale time.Duration
+}
+
+// RouteClass groups routes that share a DB time budget.
+type RouteClass string
+
+const (
+	RouteClassDefault RouteClass = "default"
+	RouteClassAdmin   RouteClass = "admin"
+	RouteClassExport  RouteClass = "export"
+)
+
+// DBTimeouts holds the resolved DB budget for each route class.
+type DBTimeouts struct {
+	Handler time.Duration
+	Admin   time.Duration
+	Export  time.Duration
+}
+
+// For returns the budget for class; unknown classes get the handler budget.
+func (t DBTimeouts) For(class RouteClass) time.Duration {
+	switch class {
+	case RouteClassAdmin:
+		return t.Admin
+	case RouteClassExport:
+		return t.Export
+	default:
+		return t.Handler
+	}
 }
 
 const (
@@ -50,6 +225,65 @@ func Load() Config {
 	redisAddr := getEnv("REDIS_ADDR", "127.0.0.1:6379")
 	redisPassword := getEnv("REDIS_PASSWORD", "")
 	redisDB := getEnvInt("REDIS_DB", 0)
+	registrationGrace := getEnvInt("REGISTRATION_GRACE_MINUTES", 30)
+	workerHealthAddr := getEnv("WORKER_HEALTH_ADDR", "")
+	queryBudget := getEnvInt("DB_QUERY_BUDGET", 10)
+	queryBudgetHardFail := getEnv("DB_QUERY_BUDGET_HARD_FAIL", "") == "true"
+	cancelTokenSecret := getEnv("CANCEL_TOKEN_SECRET", "")
+	cancelTokenTTLHours := getEnvInt("CANCEL_TOKEN_TTL_HOURS", 24*30)
+	exportsDir := getEnv("EXPORTS_DIR", "data/exports")
+	exportLinkSecret := getEnv("EXPORT_LINK_SECRET", "")
+	configFingerprintKey := getEnv("CONFIG_FINGERPRINT_KEY", "")
+	exportLinkTTLHours := getEnvInt("EXPORT_LINK_TTL_HOURS", 72)
+	emailVerifySecret := getEnv("EMAIL_VERIFY_SECRET", "")
+	emailVerifyTTLHours := getEnvInt("EMAIL_VERIFY_TTL_HOURS", 48)
+	exportRetentionDays := getEnvInt("EXPORT_RETENTION_DAYS", 30)
+	exportKeepMaxDays := getEnvInt("EXPORT_KEEP_MAX_DAYS", 365)
+	attendanceFinalizeHours := getEnvInt("ATTENDANCE_FINALIZE_HOURS", 12)
+	jobAgingInterval := getEnvDuration("JOB_AGING_INTERVAL", 0)
+	jobAgingMaxBoost := getEnvInt("JOB_AGING_MAX_BOOST", 10)
+	jobTimeout := getEnvDuration("JOB_TIMEOUT", 25*time.Second)
+	jobTypeConcurrency := getEnv("JOB_TYPE_CONCURRENCY", "")
+	jobPayloadMaxBytes := getEnvInt("JOB_PAYLOAD_MAX_BYTES", 256<<10)
+	jobPayloadMaxDepth := getEnvInt("JOB_PAYLOAD_MAX_DEPTH", 32)
+	jobStatsFlushInterval := getEnvDuration("JOB_STATS_FLUSH_INTERVAL", time.Minute)
+	jobSchedulerInterval := getEnvDuration("JOB_SCHEDULER_INTERVAL", 15*time.Second)
+	workerDegradedPendingAge := getEnvDuration("WORKER_DEGRADED_PENDING_AGE", 15*time.Minute)
+	workerEnableTestJobs := getEnv("WORKER_ENABLE_TEST_JOBS", "") == "true"
+	enqueueGuardDeferrableMaxPending := getEnvInt("ENQUEUE_GUARD_DEFERRABLE_MAX_PENDING", 50000)
+	enqueueGuardDeferrableMaxAge := getEnvDuration("ENQUEUE_GUARD_DEFERRABLE_MAX_AGE", 30*time.Minute)
+	enqueueGuardStandardMaxPending := getEnvInt("ENQUEUE_GUARD_STANDARD_MAX_PENDING", 200000)
+	enqueueGuardStandardMaxAge := getEnvDuration("ENQUEUE_GUARD_STANDARD_MAX_AGE", 2*time.Hour)
+	alertSlackWebhookURL := getEnv("ALERT_SLACK_WEBHOOK_URL", "")
+	alertSlackMaxPerMinute := getEnvInt("ALERT_SLACK_MAX_PER_MINUTE", 10)
+	alertStaleRequeueThreshold := getEnvInt("ALERT_STALE_REQUEUE_THRESHOLD", 10)
+	recipientQuarantineThreshold := getEnvInt("RECIPIENT_QUARANTINE_THRESHOLD", 3)
+	recipientQuarantineWindow := getEnvDuration("RECIPIENT_QUARANTINE_WINDOW", 24*time.Hour)
+	emailFromAddress := getEnv("EMAIL_FROM_ADDRESS", "no-reply@eventhub.local")
+	emailFromName := getEnv("EMAIL_FROM_NAME", "EventHub")
+	emailReplyTo := getEnv("EMAIL_REPLY_TO", "")
+	emailFromOrganizerName := getEnv("EMAIL_FROM_ORGANIZER_NAME", "true") == "true"
+	emailCancelPageURL := getEnv("EMAIL_CANCEL_PAGE_URL", "")
+	emailVerifyPageURL := getEnv("EMAIL_VERIFY_PAGE_URL", "")
+	notifierDriver := getEnv("NOTIFIER_DRIVER", "log")
+	smtpHost := getEnv("SMTP_HOST", "")
+	smtpPort := getEnvInt("SMTP_PORT", 587)
+	smtpUsername := getEnv("SMTP_USERNAME", "")
+	smtpPassword := getEnv("SMTP_PASSWORD", "")
+	notifierWebhookURL := getEnv("NOTIFIER_WEBHOOK_URL", "")
+	notifierWebhookSecret := getEnv("NOTIFIER_WEBHOOK_SECRET", "")
+	passwordHashScheme := getEnv("PASSWORD_HASH_SCHEME", security.SchemeArgon2id)
+	passwordBcryptCost := getEnvInt("PASSWORD_BCRYPT_COST", 10)
+	passwordArgon2Memory := getEnvInt("PASSWORD_ARGON2_MEMORY_KIB", 64*1024)
+	passwordArgon2Iterations := getEnvInt("PASSWORD_ARGON2_ITERATIONS", 3)
+	passwordArgon2Parallelism := getEnvInt("PASSWORD_ARGON2_PARALLELISM", 2)
+	adminBulkDefaultLimit := getEnvInt("ADMIN_BULK_DEFAULT_LIMIT", 50)
+	adminBulkMaxLimit := getEnvInt("ADMIN_BULK_MAX_LIMIT", 500)
+	handlerDBTimeout := getEnvDuration("HANDLER_DB_TIMEOUT", 2*time.Second)
+	adminDBTimeout := getEnvDuration("ADMIN_DB_TIMEOUT", 3*time.Second)
+	exportDBTimeout := getEnvDuration("EXPORT_DB_TIMEOUT", 10*time.Second)
+	httpWriteTimeout := getEnvDuration("HTTP_WRITE_TIMEOUT", 15*time.Second)
+	eventsCacheMaxStale := getEnvDuration("EVENTS_CACHE_MAX_STALE", 5*time.Minute)
 
 	return Config{
 		Env:                 env,
@@ -65,6 +299,239 @@ func Load() Config {
 		RedisAddr:           redisAddr,
 		RedisPassword:       redisPassword,
 		RedisDB:             redisDB,
+
+		WorkerHealthAddr:         workerHealthAddr,
+		RegistrationGraceMinutes: registrationGrace,
+		QueryBudget:              queryBudget,
+		QueryBudgetHardFail:      queryBudgetHardFail,
+		CancelTokenSecret:        cancelTokenSecret,
+		CancelTokenTTLHours:      cancelTokenTTLHours,
+		ExportsDir:               exportsDir,
+		ExportLinkSecret:         exportLinkSecret,
+		ExportLinkTTLHours:       exportLinkTTLHours,
+		EmailVerifySecret:        emailVerifySecret,
+		EmailVerifyTTLHours:      emailVerifyTTLHours,
+		ConfigFingerprintKey:     configFingerprintKey,
+		ExportRetentionDays:      exportRetentionDays,
+		ExportKeepMaxDays:        exportKeepMaxDays,
+		AttendanceFinalizeHours:  attendanceFinalizeHours,
+		JobAgingInterval:         jobAgingInterval,
+		JobAgingMaxBoost:         jobAgingMaxBoost,
+		JobTimeout:               jobTimeout,
+		JobTypeConcurrency:       jobTypeConcurrency,
+		JobPayloadMaxBytes:       jobPayloadMaxBytes,
+		JobPayloadMaxDepth:       jobPayloadMaxDepth,
+		JobStatsFlushInterval:    jobStatsFlushInterval,
+		JobSchedulerInterval:     jobSchedulerInterval,
+		WorkerDegradedPendingAge: workerDegradedPendingAge,
+		WorkerEnableTestJobs:     workerEnableTestJobs,
+
+		EnqueueGuardDeferrableMaxPending: enqueueGuardDeferrableMaxPending,
+		EnqueueGuardDeferrableMaxAge:     enqueueGuardDeferrableMaxAge,
+		EnqueueGuardStandardMaxPending:   enqueueGuardStandardMaxPending,
+		EnqueueGuardStandardMaxAge:       enqueueGuardStandardMaxAge,
+
+		EmailFromAddress:       emailFromAddress,
+		EmailFromName:          emailFromName,
+		EmailReplyTo:           emailReplyTo,
+		EmailFromOrganizerName: emailFromOrganizerName,
+		EmailCancelPageURL:     emailCancelPageURL,
+		EmailVerifyPageURL:     emailVerifyPageURL,
+
+		NotifierDriver: notifierDriver,
+		SMTPHost:       smtpHost,
+		SMTPPort:       smtpPort,
+		SMTPUsername:   smtpUsername,
+		SMTPPassword:   smtpPassword,
+
+		NotifierWebhookURL:    notifierWebhookURL,
+		NotifierWebhookSecret: notifierWebhookSecret,
+
+		AlertSlackWebhookURL:       alertSlackWebhookURL,
+		AlertSlackMaxPerMinute:     alertSlackMaxPerMinute,
+		AlertStaleRequeueThreshold: alertStaleRequeueThreshold,
+
+		RecipientQuarantineThreshold: recipientQuarantineThreshold,
+		RecipientQuarantineWindow:    recipientQuarantineWindow,
+
+		PasswordHashScheme:        passwordHashScheme,
+		PasswordBcryptCost:        passwordBcryptCost,
+		PasswordArgon2MemoryKiB:   passwordArgon2Memory,
+		PasswordArgon2Iterations:  passwordArgon2Iterations,
+		PasswordArgon2Parallelism: passwordArgon2Parallelism,
+
+		AdminBulkDefaultLimit: adminBulkDefaultLimit,
+		AdminBulkMaxLimit:     adminBulkMaxLimit,
+
+		HandlerDBTimeout: handlerDBTimeout,
+		AdminDBTimeout:   adminDBTimeout,
+		ExportDBTimeout:  exportDBTimeout,
+		HTTPWriteTimeout: httpWriteTimeout,
+
+		EventsCacheMaxStale: eventsCacheMaxStale,
+	}
+}
+
+// CancelTokenSigningSecret returns the secret used for cancellation tokens.
+func (c Config) CancelTokenSigningSecret() string {
+	if strings.TrimSpace(c.CancelTokenSecret) != "" {
+		return c.CancelTokenSecret
+	}
+	return c.JWTSecret
+}
+
+// CancelTokenTTL returns how long cancellation tokens stay valid (30 days when unset).
+func (c Config) CancelTokenTTL() time.Duration {
+	if c.CancelTokenTTLHours <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(c.CancelTokenTTLHours) * time.Hour
+}
+
+// ExportLinkSigningSecret returns the secret used for export download links.
+func (c Config) ExportLinkSigningSecret() string {
+	if strings.TrimSpace(c.ExportLinkSecret) != "" {
+		return c.ExportLinkSecret
+	}
+	return c.JWTSecret
+}
+
+// ExportLinkTTL returns how long export download links stay valid (72 hours when unset).
+func (c Config) ExportLinkTTL() time.Duration {
+	if c.ExportLinkTTLHours <= 0 {
+		return 72 * time.Hour
+	}
+	return time.Duration(c.ExportLinkTTLHours) * time.Hour
+}
+
+// EmailVerifySigningSecret returns the secret used for verification links.
+func (c Config) EmailVerifySigningSecret() string {
+	if strings.TrimSpace(c.EmailVerifySecret) != "" {
+		return c.EmailVerifySecret
+	}
+	return c.JWTSecret
+}
+
+// EmailVerifyTTL returns how long verification links stay valid (48 hours when unset).
+func (c Config) EmailVerifyTTL() time.Duration {
+	if c.EmailVerifyTTLHours <= 0 {
+		return 48 * time.Hour
+	}
+	return time.Duration(c.EmailVerifyTTLHours) * time.Hour
+}
+
+// ExportRetention returns how long registration exports are kept (30 days when unset).
+func (c Config) ExportRetention() time.Duration {
+	if c.ExportRetentionDays <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(c.ExportRetentionDays) * 24 * time.Hour
+}
+
+// ExportKeepMax returns the furthest keepUntil a requester may set (365 days when unset).
+func (c Config) ExportKeepMax() time.Duration {
+	if c.ExportKeepMaxDays <= 0 {
+		return 365 * 24 * time.Hour
+	}
+	return time.Duration(c.ExportKeepMaxDays) * 24 * time.Hour
+}
+
+// AttendanceFinalizeDelay returns how long after start an event's attendance
+// is finalized (12 hours when unset).
+func (c Config) AttendanceFinalizeDelay() time.Duration {
+	if c.AttendanceFinalizeHours <= 0 {
+		return 12 * time.Hour
+	}
+	return time.Duration(c.AttendanceFinalizeHours) * time.Hour
+}
+
+// JobAging returns the claim aging settings; aging is off unless
+// JOB_AGING_INTERVAL is set, and the boost is capped at 10 when unset.
+func (c Config) JobAging() (interval time.Duration, maxBoost int) {
+	if c.JobAgingInterval <= 0 {
+		return 0, 0
+	}
+	maxBoost = c.JobAgingMaxBoost
+	if maxBoost <= 0 {
+		maxBoost = 10
+	}
+	return c.JobAgingInterval, maxBoost
+}
+
+// TypeConcurrency returns the per-type worker caps from JobTypeConcurrency;
+// validate first, malformed entries are skipped here.
+func (c Config) TypeConcurrency() map[string]int {
+	limits, _ := parseTypeConcurrency(c.JobTypeConcurrency)
+	return limits
+}
+
+func parseTypeConcurrency(raw string) (map[string]int, error) {
+	limits := map[string]int{}
+	var err error
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		jobType, n, ok := strings.Cut(entry, "=")
+		limit, perr := strconv.Atoi(strings.TrimSpace(n))
+		jobType = strings.TrimSpace(jobType)
+		if !ok || jobType == "" || perr != nil || limit < 0 {
+			err = fmt.Errorf("malformed entry %q", entry)
+			continue
+		}
+		limits[jobType] = limit
+	}
+	return limits, err
+}
+
+// AdminBulkLimits returns the default and cap for admin bulk ?limit (50 and
+// 500 when unset).
+func (c Config) AdminBulkLimits() (defaultLimit, maxLimit int) {
+	defaultLimit, maxLimit = c.AdminBulkDefaultLimit, c.AdminBulkMaxLimit
+	if defaultLimit <= 0 {
+		defaultLimit = 50
+	}
+	if maxLimit <= 0 {
+		maxLimit = 500
+	}
+	return min(defaultLimit, maxLimit), maxLimit
+}
+
+// DBTimeouts returns the per-class handler DB budgets (2s, 3s and 10s when unset).
+func (c Config) DBTimeouts() DBTimeouts {
+	t := DBTimeouts{Handler: c.HandlerDBTimeout, Admin: c.AdminDBTimeout, Export: c.ExportDBTimeout}
+	if t.Handler <= 0 {
+		t.Handler = 2 * time.Second
+	}
+	if t.Admin <= 0 {
+		t.Admin = 3 * time.Second
+	}
+	if t.Export <= 0 {
+		t.Export = 10 * time.Second
+	}
+	return t
+}
+
+// WriteTimeout returns the HTTP server's write timeout (15 seconds when unset).
+func (c Config) WriteTimeout() time.Duration {
+	if c.HTTPWriteTimeout <= 0 {
+		return 15 * time.Second
+	}
+	return c.HTTPWriteTimeout
+}
+
+// PasswordParams returns the password hashing settings; validate first, the
+// numeric fields are narrowed without range checks here.
+func (c Config) PasswordParams() security.Params {
+	return security.Params{
+		Scheme:     c.PasswordHashScheme,
+		BcryptCost: c.PasswordBcryptCost,
+		Argon2: security.Argon2Params{
+			MemoryKiB:   uint32(c.PasswordArgon2MemoryKiB),
+			Iterations:  uint32(c.PasswordArgon2Iterations),
+			Parallelism: uint8(c.PasswordArgon2Parallelism),
+		},
 	}
 }
 
@@ -76,6 +543,33 @@ func ValidateForWorker(cfg Config) error {
 	return validate(cfg, false, false)
 }
 
+// ValidateForAll validates the single-process (API + embedded worker) mode.
+func ValidateForAll(cfg Config) error {
+	if err := validate(cfg, true, true); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(cfg.WorkerHealthAddr) == "" {
+		return nil
+	}
+
+	_, portStr, err := net.SplitHostPort(cfg.WorkerHealthAddr)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: WORKER_HEALTH_ADDR must be host:port (got %q)", cfg.WorkerHealthAddr)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid configuration: WORKER_HEALTH_ADDR port must be between 1 and 65535")
+	}
+
+	if port == cfg.Port {
+		return fmt.Errorf("invalid configuration: WORKER_HEALTH_ADDR port %d clashes with PORT; leave it empty to serve worker health under /worker/*", port)
+	}
+
+	return nil
+}
+
 func validate(cfg Config, requireAuthConfig bool, requireRedis bool) error {
 	var issues []string
 
@@ -103,6 +597,129 @@ func validate(cfg Config, requireAuthConfig bool, requireRedis bool) error {
 		}
 	}
 
+	if cfg.RegistrationGraceMinutes < 0 {
+		issues = append(issues, "REGISTRATION_GRACE_MINUTES must be zero or positive")
+	}
+
+	if cfg.CancelTokenTTLHours < 0 {
+		issues = append(issues, "CANCEL_TOKEN_TTL_HOURS must be zero or positive")
+	}
+
+	if cfg.ExportLinkTTLHours < 0 {
+		issues = append(issues, "EXPORT_LINK_TTL_HOURS must be zero or positive")
+	}
+
+	if cfg.EmailVerifyTTLHours < 0 {
+		issues = append(issues, "EMAIL_VERIFY_TTL_HOURS must be zero or positive")
+	}
+
+	if cfg.ExportRetentionDays < 0 || cfg.ExportKeepMaxDays < 0 {
+		issues = append(issues, "EXPORT_RETENTION_DAYS and EXPORT_KEEP_MAX_DAYS must be zero or positive")
+	}
+
+	if cfg.AttendanceFinalizeHours < 0 {
+		issues = append(issues, "ATTENDANCE_FINALIZE_HOURS must be zero or positive")
+	}
+
+	if cfg.JobAgingInterval < 0 || cfg.JobAgingMaxBoost < 0 {
+		issues = append(issues, "JOB_AGING_INTERVAL and JOB_AGING_MAX_BOOST must be zero or positive")
+	} else if cfg.JobAgingInterval > 0 && cfg.JobAgingInterval < time.Second {
+		issues = append(issues, "JOB_AGING_INTERVAL must be at least 1s")
+	}
+
+	// zero would read as "no timeout", which only per-type overrides offer
+	if cfg.JobTimeout <= 0 {
+		issues = append(issues, "JOB_TIMEOUT must be positive")
+	}
+
+	if _, err := parseTypeConcurrency(cfg.JobTypeConcurrency); err != nil {
+		issues = append(issues, "JOB_TYPE_CONCURRENCY must be type=n pairs separated by commas ("+err.Error()+")")
+	}
+
+	if cfg.JobPayloadMaxBytes < 0 || cfg.JobPayloadMaxDepth < 0 {
+		issues = append(issues, "JOB_PAYLOAD_MAX_BYTES and JOB_PAYLOAD_MAX_DEPTH must be zero or positive")
+	}
+
+	if cfg.JobStatsFlushInterval < 0 || (cfg.JobStatsFlushInterval > 0 && cfg.JobStatsFlushInterval < time.Second) {
+		issues = append(issues, "JOB_STATS_FLUSH_INTERVAL must be at least 1s")
+	}
+
+	if cfg.JobSchedulerInterval < 0 || (cfg.JobSchedulerInterval > 0 && cfg.JobSchedulerInterval < time.Second) {
+		issues = append(issues, "JOB_SCHEDULER_INTERVAL must be at least 1s")
+	}
+
+	if cfg.WorkerDegradedPendingAge < 0 {
+		issues = append(issues, "WORKER_DEGRADED_PENDING_AGE must be zero or positive")
+	}
+
+	if cfg.EnqueueGuardDeferrableMaxPending < 0 || cfg.EnqueueGuardDeferrableMaxAge < 0 ||
+		cfg.EnqueueGuardStandardMaxPending < 0 || cfg.EnqueueGuardStandardMaxAge < 0 {
+		issues = append(issues, "ENQUEUE_GUARD_* thresholds must be zero or positive")
+	}
+
+	if cfg.AlertSlackWebhookURL != "" && !strings.HasPrefix(cfg.AlertSlackWebhookURL, "https://") {
+		issues = append(issues, "ALERT_SLACK_WEBHOOK_URL must be an https URL")
+	}
+
+	if cfg.AlertSlackMaxPerMinute < 0 || cfg.AlertStaleRequeueThreshold < 0 {
+		issues = append(issues, "ALERT_SLACK_MAX_PER_MINUTE and ALERT_STALE_REQUEUE_THRESHOLD must be zero or positive")
+	}
+
+	switch cfg.NotifierDriver {
+	case "", "log":
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			issues = append(issues, "SMTP_HOST is required when NOTIFIER_DRIVER=smtp")
+		}
+		if cfg.SMTPPort <= 0 || cfg.SMTPPort > 65535 {
+			issues = append(issues, "SMTP_PORT must be a valid port")
+		}
+	case "webhook":
+		if u, err := url.Parse(cfg.NotifierWebhookURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			issues = append(issues, "NOTIFIER_WEBHOOK_URL must be an absolute http(s) URL when NOTIFIER_DRIVER=webhook")
+		}
+		if len(cfg.NotifierWebhookSecret) < 16 {
+			issues = append(issues, "NOTIFIER_WEBHOOK_SECRET must be at least 16 characters when NOTIFIER_DRIVER=webhook")
+		}
+	default:
+		issues = append(issues, "NOTIFIER_DRIVER must be log, smtp or webhook")
+	}
+
+	if cfg.EmailCancelPageURL != "" {
+		if u, err := url.Parse(cfg.EmailCancelPageURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			issues = append(issues, "EMAIL_CANCEL_PAGE_URL must be an absolute http(s) URL")
+		}
+	}
+
+	if cfg.EmailVerifyPageURL != "" {
+		if u, err := url.Parse(cfg.EmailVerifyPageURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			issues = append(issues, "EMAIL_VERIFY_PAGE_URL must be an absolute http(s) URL")
+		}
+	}
+
+	if cfg.RecipientQuarantineThreshold < 0 {
+		issues = append(issues, "RECIPIENT_QUARANTINE_THRESHOLD must be zero or positive")
+	}
+	if cfg.RecipientQuarantineThreshold > 0 && cfg.RecipientQuarantineWindow <= 0 {
+		issues = append(issues, "RECIPIENT_QUARANTINE_WINDOW must be positive")
+	}
+
+	if cfg.EventsCacheMaxStale < 0 {
+		issues = append(issues, "EVENTS_CACHE_MAX_STALE must be zero or positive")
+	}
+
+	if cfg.AdminBulkDefaultLimit < 0 || cfg.AdminBulkMaxLimit < 0 {
+		issues = append(issues, "ADMIN_BULK_DEFAULT_LIMIT and ADMIN_BULK_MAX_LIMIT must be zero or positive")
+	} else if cfg.AdminBulkMaxLimit > 0 && cfg.AdminBulkDefaultLimit > cfg.AdminBulkMaxLimit {
+		issues = append(issues, "ADMIN_BULK_DEFAULT_LIMIT must not exceed ADMIN_BULK_MAX_LIMIT")
+	}
+
+	issues = append(issues, validateDBTimeouts(cfg)...)
+
+	if requireAuthConfig {
+		issues = append(issues, validatePasswordHashing(cfg)...)
+	}
+
 	if requireRedis && strings.TrimSpace(cfg.RedisAddr) == "" {
 		issues = append(issues, "REDIS_ADDR is required")
 	}
@@ -147,6 +764,11 @@ func validate(cfg Config, requireAuthConfig bool, requireRedis bool) error {
 	return nil
 }
 
+// QueryBudgetEnabled reports whether the per-request query budget applies.
+func (c Config) QueryBudgetEnabled() bool {
+	return !isReleaseEnv(c.Env)
+}
+
 func isReleaseEnv(env string) bool {
 	e := strings.ToLower(strings.TrimSpace(env))
 	return e != "" && e != "dev" && e != "test"
@@ -186,3 +808,67 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+// getEnvDuration parses key as a Go duration ("30s", "2m"). A value that does
+// not parse is logged and ignored: falling through to a zero duration would
+// turn a typo into a timeout that cancels everything at once.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			slog.Warn("config.invalid_duration", "key", key, "value", v, "fallback", fallback, "err", err)
+			return fallback
+		}
+
+		return d
+	}
+	return fallback
+}
+
+func validateDBTimeouts(cfg Config) []string {
+	if cfg.HandlerDBTimeout < 0 || cfg.AdminDBTimeout < 0 || cfg.ExportDBTimeout < 0 || cfg.HTTPWriteTimeout < 0 {
+		return []string{"HANDLER_DB_TIMEOUT, ADMIN_DB_TIMEOUT, EXPORT_DB_TIMEOUT and HTTP_WRITE_TIMEOUT must be zero or positive"}
+	}
+
+	var issues []string
+	t, write := cfg.DBTimeouts(), cfg.WriteTimeout()
+	for _, b := range []struct {
+		env string
+		d   time.Duration
+	}{
+		{"HANDLER_DB_TIMEOUT", t.Handler},
+		{"ADMIN_DB_TIMEOUT", t.Admin},
+		{"EXPORT_DB_TIMEOUT", t.Export},
+	} {
+		if b.d >= write {
+			issues = append(issues, fmt.Sprintf("%s (%s) must be shorter than HTTP_WRITE_TIMEOUT (%s)", b.env, b.d, write))
+		}
+	}
+	return issues
+}
+
+func validatePasswordHashing(cfg Config) []string {
+	var issues []string
+
+	switch cfg.PasswordHashScheme {
+	case "", security.SchemeBcrypt, security.SchemeArgon2id:
+	default:
+		issues = append(issues, fmt.Sprintf("PASSWORD_HASH_SCHEME must be %q or %q", security.SchemeBcrypt, security.SchemeArgon2id))
+	}
+	if cfg.PasswordBcryptCost != 0 && (cfg.PasswordBcryptCost < 4 || cfg.PasswordBcryptCost > 31) {
+		issues = append(issues, "PASSWORD_BCRYPT_COST must be between 4 and 31")
+	}
+	if cfg.PasswordArgon2MemoryKiB < 0 || cfg.PasswordArgon2MemoryKiB > 4*1024*1024 {
+		issues = append(issues, "PASSWORD_ARGON2_MEMORY_KIB must be between 0 and 4194304")
+	}
+	if cfg.PasswordArgon2Iterations < 0 || cfg.PasswordArgon2Iterations > 100 {
+		issues = append(issues, "PASSWORD_ARGON2_ITERATIONS must be between 0 and 100")
+	}
+	if cfg.PasswordArgon2Parallelism < 0 || cfg.PasswordArgon2Parallelism > 255 {
+		issues = append(issues, "PASSWORD_ARGON2_PARALLELISM must be between 0 and 255")
+	}
+	if len(issues) == 0 && len(cfg.PasswordParams().Validate()) > 0 {
+		issues = append(issues, "PASSWORD_ARGON2_MEMORY_KIB must be at least 8 per PASSWORD_ARGON2_PARALLELISM thread")
+	}
+	return issues
+}
@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func baseConfig(env string) Config {
 	return Config{
@@ -13,6 +17,7 @@ func baseConfig(env string) Config {
 		JWTAccessTTLMinutes: 60,
 		JWTRefreshTTLDays:   14,
 		RedisAddr:           "redis:6379",
+		JobTimeout:          25 * time.Second,
 	}
 }
 
@@ -72,3 +77,189 @@ func TestValidateForWorker_RejectsReleaseInsecureDB(t *testing.T) {
 		t.Fatal("expected worker release validation error, got nil")
 	}
 }
+
+func TestValidateForAll_RejectsHealthPortClash(t *testing.T) {
+	cfg := baseConfig("dev")
+	cfg.WorkerHealthAddr = ":8080"
+
+	if err := ValidateForAll(cfg); err == nil {
+		t.Fatal("expected port clash error, got nil")
+	}
+}
+
+func TestValidateForAll_AllowsMergedOrSeparateHealth(t *testing.T) {
+	cfg := baseConfig("dev")
+
+	if err := ValidateForAll(cfg); err != nil {
+		t.Fatalf("ValidateForAll(merged) returned error: %v", err)
+	}
+
+	cfg.WorkerHealthAddr = "127.0.0.1:8081"
+	if err := ValidateForAll(cfg); err != nil {
+		t.Fatalf("ValidateForAll(separate) returned error: %v", err)
+	}
+}
+
+func TestValidateForAPI_PasswordHashing(t *testing.T) {
+	cfg := baseConfig("dev")
+	cfg.PasswordHashScheme = "md5"
+	cfg.PasswordArgon2Parallelism = 300
+
+	err := ValidateForAPI(cfg)
+	if err == nil {
+		t.Fatal("expected password hashing validation error, got nil")
+	}
+	for _, want := range []string{"PASSWORD_HASH_SCHEME", "PASSWORD_ARGON2_PARALLELISM"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %s in %v", want, err)
+		}
+	}
+
+	cfg = baseConfig("dev")
+	cfg.PasswordHashScheme = "bcrypt"
+	cfg.PasswordBcryptCost = 12
+	if err := ValidateForAPI(cfg); err != nil {
+		t.Fatalf("ValidateForAPI(bcrypt) returned error: %v", err)
+	}
+}
+
+func TestAdminBulkLimits(t *testing.T) {
+	cfg := baseConfig("dev")
+	if d, m := cfg.AdminBulkLimits(); d != 50 || m != 500 {
+		t.Fatalf("unset limits = %d/%d, want 50/500", d, m)
+	}
+
+	cfg.AdminBulkDefaultLimit = 100
+	cfg.AdminBulkMaxLimit = 20
+	if err := ValidateForAPI(cfg); err == nil || !strings.Contains(err.Error(), "ADMIN_BULK_DEFAULT_LIMIT") {
+		t.Fatalf("expected default-over-max validation error, got %v", err)
+	}
+}
+
+func TestValidateForAPI_DBTimeoutsShorterThanWriteTimeout(t *testing.T) {
+	cfg := baseConfig("dev")
+	if got := cfg.DBTimeouts(); got != (DBTimeouts{Handler: 2 * time.Second, Admin: 3 * time.Second, Export: 10 * time.Second}) {
+		t.Fatalf("unset timeouts = %+v", got)
+	}
+	if err := ValidateForAPI(cfg); err != nil {
+		t.Fatalf("defaults should validate: %v", err)
+	}
+
+	cfg.ExportDBTimeout = 20 * time.Second
+	if err := ValidateForAPI(cfg); err == nil || !strings.Contains(err.Error(), "EXPORT_DB_TIMEOUT") {
+		t.Fatalf("expected export timeout over the write timeout to fail, got %v", err)
+	}
+
+	// a longer write timeout makes room for it
+	cfg.HTTPWriteTimeout = 30 * time.Second
+	if err := ValidateForAPI(cfg); err != nil {
+		t.Fatalf("expected 20s export budget under a 30s write timeout to validate: %v", err)
+	}
+
+	cfg.AdminDBTimeout = -time.Second
+	if err := ValidateForAPI(cfg); err == nil || !strings.Contains(err.Error(), "ADMIN_DB_TIMEOUT") {
+		t.Fatalf("expected negative timeout to fail, got %v", err)
+	}
+}
+
+func TestValidateForAPI_JobAging(t *testing.T) {
+	cfg := baseConfig("dev")
+	if interval, boost := cfg.JobAging(); interval != 0 || boost != 0 {
+		t.Fatalf("expected aging off by default, got %s/%d", interval, boost)
+	}
+
+	cfg.JobAgingInterval = time.Minute
+	if interval, boost := cfg.JobAging(); interval != time.Minute || boost != 10 {
+		t.Fatalf("expected the default boost cap, got %s/%d", interval, boost)
+	}
+	if err := ValidateForAPI(cfg); err != nil {
+		t.Fatalf("expected aging settings to validate: %v", err)
+	}
+
+	cfg.JobAgingInterval = 500 * time.Millisecond
+	if err := ValidateForAPI(cfg); err == nil || !strings.Contains(err.Error(), "JOB_AGING_INTERVAL") {
+		t.Fatalf("expected a sub-second interval to fail, got %v", err)
+	}
+}
+
+func TestValidateForWorker_JobTimeoutMustBePositive(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		cfg := baseConfig("dev")
+		cfg.JobTimeout = d
+		if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "JOB_TIMEOUT must be positive") {
+			t.Fatalf("JOB_TIMEOUT=%s: expected a validation error, got %v", d, err)
+		}
+	}
+}
+
+func TestValidateForWorker_TypeConcurrency(t *testing.T) {
+	cfg := baseConfig("dev")
+	cfg.JobTypeConcurrency = "registrations.export_csv=2, account.export=1"
+	if err := ValidateForWorker(cfg); err != nil {
+		t.Fatalf("expected type caps to validate: %v", err)
+	}
+	got := cfg.TypeConcurrency()
+	if len(got) != 2 || got["registrations.export_csv"] != 2 || got["account.export"] != 1 {
+		t.Fatalf("unexpected caps %v", got)
+	}
+
+	cfg.JobTypeConcurrency = "registrations.export_csv=two"
+	if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "JOB_TYPE_CONCURRENCY") {
+		t.Fatalf("expected a malformed cap to fail, got %v", err)
+	}
+}
+
+func TestValidateForWorker_NotifierDriver(t *testing.T) {
+	cfg := baseConfig("dev")
+	cfg.NotifierDriver = "smtp"
+	if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "SMTP_HOST") {
+		t.Fatalf("expected smtp without a host to fail, got %v", err)
+	}
+
+	cfg.SMTPHost = "smtp.example.com"
+	cfg.SMTPPort = 587
+	cfg.EmailCancelPageURL = "https://eventhub.example/cancel"
+	if err := ValidateForWorker(cfg); err != nil {
+		t.Fatalf("expected the smtp settings to validate: %v", err)
+	}
+
+	cfg.EmailCancelPageURL = "/cancel"
+	if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "EMAIL_CANCEL_PAGE_URL") {
+		t.Fatalf("expected a relative cancel page to fail, got %v", err)
+	}
+
+	cfg.EmailCancelPageURL = ""
+	cfg.NotifierDriver = "webhook"
+	cfg.NotifierWebhookURL = "https://mailer.internal/confirmations"
+	cfg.NotifierWebhookSecret = "short"
+	if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "NOTIFIER_WEBHOOK_SECRET") {
+		t.Fatalf("expected a short webhook secret to fail, got %v", err)
+	}
+
+	cfg.NotifierWebhookSecret = "whsec_0123456789abcdef"
+	if err := ValidateForWorker(cfg); err != nil {
+		t.Fatalf("expected the webhook settings to validate: %v", err)
+	}
+
+	cfg.NotifierWebhookURL = "mailer.internal"
+	if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "NOTIFIER_WEBHOOK_URL") {
+		t.Fatalf("expected a relative webhook URL to fail, got %v", err)
+	}
+
+	cfg.NotifierDriver = "sendgrid"
+	if err := ValidateForWorker(cfg); err == nil || !strings.Contains(err.Error(), "NOTIFIER_DRIVER") {
+		t.Fatalf("expected an unknown driver to fail, got %v", err)
+	}
+}
+
+func TestGetEnvDuration_InvalidValueKeepsFallback(t *testing.T) {
+	t.Setenv("JOB_TIMEOUT", "25 seconds")
+	if got := getEnvDuration("JOB_TIMEOUT", 25*time.Second); got != 25*time.Second {
+		t.Fatalf("expected the fallback for an unparsable value, got %s", got)
+	}
+
+	t.Setenv("JOB_TIMEOUT", "40s")
+	if got := getEnvDuration("JOB_TIMEOUT", 25*time.Second); got != 40*time.Second {
+		t.Fatalf("expected 40s, got %s", got)
+	}
+}
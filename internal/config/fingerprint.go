@@ -0,0 +1,82 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fingerprintKey keys the hashes of the non-secret parts. It is fixed so
+// every process computes the same values. The signing secrets are never
+// hashed under it: anyone can read it here, so such a hash could be
+// brute-forced offline. They are keyed by ConfigFingerprintKey instead.
+const fingerprintKey = "eventhub/config-fingerprint/v1"
+
+// Fingerprint identifies the settings the API and workers must agree on.
+// Parts holds one keyed hash per group, so two fingerprints that differ show
+// which group drifted; Hash covers them all. Neither reveals a value.
+type Fingerprint struct {
+	Hash  string            `json:"hash"`
+	Parts map[string]string `json:"parts"`
+}
+
+// Fingerprint hashes the correctness-relevant subset of c: where the data
+// lives, the secrets tokens are signed with, the shared export directory, the
+// notifier driver and the queue knobs. Ports, timeouts and other per-process
+// settings are left out, so they may differ between replicas without
+// reporting drift. The secrets part is only there when ConfigFingerprintKey
+// is set, keyed by it.
+func (c Config) Fingerprint() Fingerprint {
+	agingInterval, agingMaxBoost := c.JobAging()
+
+	concurrency := c.TypeConcurrency()
+	types := make([]string, 0, len(concurrency))
+	for t, n := range concurrency {
+		types = append(types, fmt.Sprintf("%s=%d", t, n))
+	}
+	sort.Strings(types)
+
+	parts := map[string]string{
+		"db":       fingerprintPart(dbTarget(c.DBURL)),
+		"redis":    fingerprintPart(c.RedisAddr, fmt.Sprint(c.RedisDB)),
+		"exports":  fingerprintPart(c.ExportsDir),
+		"notifier": fingerprintPart(c.NotifierDriver),
+		"queue": fingerprintPart(
+			agingInterval.String(),
+			fmt.Sprint(agingMaxBoost),
+			c.JobTimeout.String(),
+			strings.Join(types, ","),
+			fmt.Sprint(c.JobPayloadMaxBytes),
+			fmt.Sprint(c.JobPayloadMaxDepth),
+		),
+	}
+
+	if c.ConfigFingerprintKey != "" {
+		parts["secrets"] = keyedFingerprintPart(c.ConfigFingerprintKey, c.JWTSecret, c.CancelTokenSigningSecret(), c.ExportLinkSigningSecret(), c.EmailVerifySigningSecret())
+	}
+
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	all := make([]string, 0, len(names))
+	for _, name := range names {
+		all = append(all, name+"="+parts[name])
+	}
+
+	return Fingerprint{Hash: fingerprintPart(all...), Parts: parts}
+}
+
+func fingerprintPart(values ...string) string {
+	return keyedFingerprintPart(fingerprintKey, values...)
+}
+
+func keyedFingerprintPart(key string, values ...string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redacted stands in for a field that is set but not on snapshotVisible.
+const redacted = "[redacted]"
+
+// snapshotVisible lists the Config fields a snapshot may show as they are.
+// Everything else is redacted, so a new secret is hidden until someone
+// decides otherwise; add a field here only when its value is safe to log.
+var snapshotVisible = map[string]bool{
+	"Env":                              true,
+	"Port":                             true,
+	"AdminName":                        true,
+	"AdminRole":                        true,
+	"JWTAccessTTLMinutes":              true,
+	"JWTRefreshTTLDays":                true,
+	"RedisAddr":                        true,
+	"RedisDB":                          true,
+	"WorkerHealthAddr":                 true,
+	"RegistrationGraceMinutes":         true,
+	"QueryBudget":                      true,
+	"QueryBudgetHardFail":              true,
+	"CancelTokenTTLHours":              true,
+	"ExportsDir":                       true,
+	"ExportLinkTTLHours":               true,
+	"EmailVerifyTTLHours":              true,
+	"ExportRetentionDays":              true,
+	"ExportKeepMaxDays":                true,
+	"AttendanceFinalizeHours":          true,
+	"JobAgingInterval":                 true,
+	"JobAgingMaxBoost":                 true,
+	"JobTimeout":                       true,
+	"JobTypeConcurrency":               true,
+	"JobPayloadMaxBytes":               true,
+	"JobPayloadMaxDepth":               true,
+	"JobStatsFlushInterval":            true,
+	"WorkerDegradedPendingAge":         true,
+	"JobSchedulerInterval":             true,
+	"EnqueueGuardDeferrableMaxPending": true,
+	"EnqueueGuardDeferrableMaxAge":     true,
+	"EnqueueGuardStandardMaxPending":   true,
+	"EnqueueGuardStandardMaxAge":       true,
+	"AlertSlackMaxPerMinute":           true,
+	"AlertStaleRequeueThreshold":       true,
+	"RecipientQuarantineThreshold":     true,
+	"RecipientQuarantineWindow":        true,
+	"EmailFromAddress":                 true,
+	"EmailFromName":                    true,
+	"EmailReplyTo":                     true,
+	"EmailFromOrganizerName":           true,
+	"EmailCancelPageURL":               true,
+	"EmailVerifyPageURL":               true,
+	"NotifierDriver":                   true,
+	"SMTPHost":                         true,
+	"SMTPPort":                         true,
+	"PasswordHashScheme":               true,
+	"PasswordBcryptCost":               true,
+	"PasswordArgon2MemoryKiB":          true,
+	"PasswordArgon2Iterations":         true,
+	"PasswordArgon2Parallelism":        true,
+	"AdminBulkDefaultLimit":            true,
+	"AdminBulkMaxLimit":                true,
+	"HandlerDBTimeout":                 true,
+	"AdminDBTimeout":                   true,
+	"ExportDBTimeout":                  true,
+	"HTTPWriteTimeout":                 true,
+	"EventsCacheMaxStale":              true,
+}
+
+// Snapshot is a loggable view of a Config: Fields holds every field, with
+// the ones not on the allowlist redacted (an unset one stays empty, so an
+// operator can still tell it is missing); Effective holds the values the
+// accessors resolve defaults and fallbacks to.
+type Snapshot struct {
+	Fields    map[string]any `json:"fields"`
+	Effective map[string]any `json:"effective"`
+}
+
+// Snapshot returns the config as it is safe to log or serve to admins.
+func (c Config) Snapshot() Snapshot {
+	fields := map[string]any{}
+	v := reflect.ValueOf(c)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, fv := t.Field(i).Name, v.Field(i)
+		switch {
+		case snapshotVisible[name]:
+			fields[name] = snapshotValue(fv.Interface())
+		case fv.IsZero():
+			fields[name] = ""
+		default:
+			fields[name] = redacted
+		}
+	}
+
+	timeouts := c.DBTimeouts()
+	agingInterval, agingMaxBoost := c.JobAging()
+	bulkDefault, bulkMax := c.AdminBulkLimits()
+	effective := map[string]any{
+		"db":                      dbTarget(c.DBURL),
+		"handlerDBTimeout":        timeouts.Handler.String(),
+		"adminDBTimeout":          timeouts.Admin.String(),
+		"exportDBTimeout":         timeouts.Export.String(),
+		"httpWriteTimeout":        c.WriteTimeout().String(),
+		"cancelTokenTTL":          c.CancelTokenTTL().String(),
+		"exportLinkTTL":           c.ExportLinkTTL().String(),
+		"emailVerifyTTL":          c.EmailVerifyTTL().String(),
+		"exportRetention":         c.ExportRetention().String(),
+		"exportKeepMax":           c.ExportKeepMax().String(),
+		"attendanceFinalizeDelay": c.AttendanceFinalizeDelay().String(),
+		"jobAgingInterval":        agingInterval.String(),
+		"jobAgingMaxBoost":        agingMaxBoost,
+		"adminBulkDefaultLimit":   bulkDefault,
+		"adminBulkMaxLimit":       bulkMax,
+		"queryBudgetEnabled":      c.QueryBudgetEnabled(),
+		"cancelTokenSecretSource": secretSource(c.CancelTokenSecret),
+		"exportLinkSecretSource":  secretSource(c.ExportLinkSecret),
+		"emailVerifySecretSource": secretSource(c.EmailVerifySecret),
+		"slackAlertsEnabled":      strings.TrimSpace(c.AlertSlackWebhookURL) != "",
+	}
+
+	return Snapshot{Fields: fields, Effective: effective}
+}
+
+func snapshotValue(v any) any {
+	if d, ok := v.(time.Duration); ok {
+		return d.String()
+	}
+	return v
+}
+
+// dbTarget is host:port/name from a DB URL, never its user or password.
+func dbTarget(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Host + u.Path
+}
+
+// secretSource says where a signing secret with a JWT fallback comes from.
+func secretSource(own string) string {
+	if strings.TrimSpace(own) != "" {
+		return "own"
+	}
+	return "jwt_secret"
+}
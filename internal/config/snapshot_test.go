@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_RedactsSecrets(t *testing.T) {
+	cfg := baseConfig("prod")
+	cfg.RedisPassword = "redis-secret-value"
+	cfg.CancelTokenSecret = "cancel-secret-value"
+	cfg.ExportLinkSecret = "export-secret-value"
+	cfg.EmailVerifySecret = "verify-secret-value"
+	cfg.AlertSlackWebhookURL = "https://hooks.slack.com/services/T000/B000/slack-secret-value"
+
+	snap := cfg.Snapshot()
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	for _, secret := range []string{
+		cfg.JWTSecret,
+		cfg.AdminPassword,
+		cfg.RedisPassword,
+		cfg.CancelTokenSecret,
+		cfg.ExportLinkSecret,
+		cfg.EmailVerifySecret,
+		"strong-db-password",
+		"slack-secret-value",
+	} {
+		if strings.Contains(string(raw), secret) {
+			t.Fatalf("snapshot leaks %q: %s", secret, raw)
+		}
+	}
+
+	for _, field := range []string{"JWTSecret", "AdminPassword", "RedisPassword", "CancelTokenSecret", "ExportLinkSecret", "EmailVerifySecret", "AlertSlackWebhookURL", "DBURL", "AdminEmail"} {
+		if snap.Fields[field] != redacted {
+			t.Fatalf("expected %s redacted, got %v", field, snap.Fields[field])
+		}
+	}
+	if got := snap.Effective["db"]; got != "db:5432/eventhub" {
+		t.Fatalf("expected the DB target without credentials, got %v", got)
+	}
+}
+
+func TestSnapshot_UnlistedFieldsAreRedacted(t *testing.T) {
+	snap := baseConfig("prod").Snapshot()
+
+	for name, v := range snap.Fields {
+		if snapshotVisible[name] {
+			continue
+		}
+		if v != redacted && v != "" {
+			t.Fatalf("field %s is not on the allowlist but shows %v", name, v)
+		}
+	}
+	for name := range snapshotVisible {
+		if _, ok := snap.Fields[name]; !ok {
+			t.Fatalf("allowlisted field %s is not a Config field", name)
+		}
+	}
+}
+
+func TestSnapshot_ShowsEffectiveValues(t *testing.T) {
+	cfg := baseConfig("dev")
+	cfg.HTTPWriteTimeout = 20 * time.Second
+
+	snap := cfg.Snapshot()
+	if snap.Fields["Env"] != "dev" || snap.Fields["HTTPWriteTimeout"] != "20s" {
+		t.Fatalf("expected allowlisted fields shown as set, got %v", snap.Fields)
+	}
+	if snap.Effective["handlerDBTimeout"] != "2s" || snap.Effective["httpWriteTimeout"] != "20s" {
+		t.Fatalf("expected resolved timeouts, got %v", snap.Effective)
+	}
+	if snap.Effective["cancelTokenSecretSource"] != "jwt_secret" {
+		t.Fatalf("expected the JWT fallback reported, got %v", snap.Effective["cancelTokenSecretSource"])
+	}
+	if snap.Fields["RedisPassword"] != "" {
+		t.Fatalf("expected an unset secret to stay empty, got %v", snap.Fields["RedisPassword"])
+	}
+}
@@ -0,0 +1,77 @@
+// Package configdrift spots API and worker processes running with settings
+// that must match but do not. Each process announces its config fingerprint
+// (see config.Config.Fingerprint); live instances are compared by it.
+package configdrift
+
+import (
+	"sort"
+	"time"
+)
+
+// Instance is one running process as it last announced itself. ID is
+// observability.InstanceID; Service is "api", "worker" or "all".
+type Instance struct {
+	ID          string            `json:"id"`
+	Service     string            `json:"service"`
+	Fingerprint string            `json:"fingerprint"`
+	Parts       map[string]string `json:"parts"`
+	StartedAt   time.Time         `json:"startedAt"`
+	LastSeenAt  time.Time         `json:"lastSeenAt"`
+}
+
+// Report compares live instances' fingerprints.
+type Report struct {
+	Drifted bool `json:"drifted"`
+	// Fingerprints maps every fingerprint seen to the ids of the instances
+	// announcing it, sorted.
+	Fingerprints map[string][]string `json:"fingerprints"`
+	// Parts names the fingerprint groups the instances disagree on, sorted.
+	Parts     []string   `json:"parts,omitempty"`
+	Instances []Instance `json:"instances"`
+}
+
+// Check compares instances. They have drifted when they do not all announce
+// the same fingerprint; fewer than two never drift.
+func Check(instances []Instance) Report {
+	rep := Report{Fingerprints: map[string][]string{}, Instances: instances}
+	if rep.Instances == nil {
+		rep.Instances = []Instance{}
+	}
+
+	partValues := map[string]map[string]bool{}
+	for _, in := range instances {
+		rep.Fingerprints[in.Fingerprint] = append(rep.Fingerprints[in.Fingerprint], in.ID)
+		for name, h := range in.Parts {
+			if partValues[name] == nil {
+				partValues[name] = map[string]bool{}
+			}
+			partValues[name][h] = true
+		}
+	}
+	for fp := range rep.Fingerprints {
+		sort.Strings(rep.Fingerprints[fp])
+	}
+
+	rep.Drifted = len(rep.Fingerprints) > 1
+	if !rep.Drifted {
+		return rep
+	}
+
+	for name, values := range partValues {
+		// a group one instance does not announce at all counts as drifted too
+		if len(values) > 1 || !everyInstanceHas(instances, name) {
+			rep.Parts = append(rep.Parts, name)
+		}
+	}
+	sort.Strings(rep.Parts)
+	return rep
+}
+
+func everyInstanceHas(instances []Instance, part string) bool {
+	for _, in := range instances {
+		if _, ok := in.Parts[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package configdrift
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+)
+
+func instance(id, fp string, parts map[string]string) Instance {
+	return Instance{ID: id, Service: "api", Fingerprint: fp, Parts: parts}
+}
+
+func TestCheck(t *testing.T) {
+	same := map[string]string{"db": "d1", "secrets": "s1", "queue": "q1"}
+	otherSecret := map[string]string{"db": "d1", "secrets": "s2", "queue": "q1"}
+	noQueue := map[string]string{"db": "d1", "secrets": "s1"}
+
+	tests := []struct {
+		name      string
+		instances []Instance
+		drifted   bool
+		parts     []string
+	}{
+		{name: "none"},
+		{name: "one", instances: []Instance{instance("a", "f1", same)}},
+		{name: "agree", instances: []Instance{instance("a", "f1", same), instance("b", "f1", same)}},
+		{name: "secret differs", instances: []Instance{instance("a", "f1", same), instance("b", "f2", otherSecret), instance("c", "f1", same)}, drifted: true, parts: []string{"secrets"}},
+		{name: "group missing", instances: []Instance{instance("a", "f1", same), instance("b", "f3", noQueue)}, drifted: true, parts: []string{"queue"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rep := Check(tc.instances)
+			if rep.Drifted != tc.drifted {
+				t.Fatalf("drifted = %v, want %v", rep.Drifted, tc.drifted)
+			}
+			if !reflect.DeepEqual(rep.Parts, tc.parts) {
+				t.Fatalf("parts = %v, want %v", rep.Parts, tc.parts)
+			}
+			if rep.Instances == nil {
+				t.Fatalf("expected a non-nil instance list")
+			}
+		})
+	}
+
+	rep := Check(tests[3].instances)
+	if want := []string{"a", "c"}; !reflect.DeepEqual(rep.Fingerprints["f1"], want) {
+		t.Fatalf("fingerprint f1 instances = %v, want %v", rep.Fingerprints["f1"], want)
+	}
+}
+
+// memStore is a Store in memory.
+type memStore struct {
+	mu        sync.Mutex
+	instances map[string]Instance
+}
+
+func (s *memStore) Announce(ctx context.Context, in Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.instances == nil {
+		s.instances = map[string]Instance{}
+	}
+	s.instances[in.ID] = in
+	return nil
+}
+
+func (s *memStore) ListLive(ctx context.Context, since time.Time) ([]Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []Instance{}
+	for _, in := range s.instances {
+		out = append(out, in)
+	}
+	return out, nil
+}
+
+func (s *memStore) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.instances, id)
+	return nil
+}
+
+func TestReporter_DetectsDivergentProcesses(t *testing.T) {
+	store := &memStore{}
+	apiCfg := config.Config{DBURL: "postgres://u:p@db:5432/eventhub", JWTSecret: "one-secret", ConfigFingerprintKey: "fp-key"}
+	workerCfg := apiCfg
+	workerCfg.JWTSecret = "another-secret"
+
+	api := NewReporter(store, "api-1", "api", apiCfg.Fingerprint())
+	if rep, err := api.Refresh(context.Background()); err != nil || rep.Drifted {
+		t.Fatalf("a lone instance should not drift, got %+v err=%v", rep, err)
+	}
+
+	worker := NewReporter(store, "worker-1", "worker", workerCfg.Fingerprint())
+	rep, err := worker.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if !rep.Drifted || !reflect.DeepEqual(rep.Parts, []string{"secrets"}) {
+		t.Fatalf("expected drift in secrets, got %+v", rep)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	if rep, _ := api.Refresh(context.Background()); rep.Drifted {
+		t.Fatalf("expected the stopped worker to be forgotten, got %+v", rep)
+	}
+}
@@ -0,0 +1,107 @@
+package configdrift
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+)
+
+// RefreshInterval is how often a process announces itself again.
+const RefreshInterval = time.Hour
+
+// LiveWindow is how long after its last announcement an instance still
+// counts as live. Two missed refreshes and it is treated as gone.
+const LiveWindow = 2*RefreshInterval + 5*time.Minute
+
+// Store keeps the announcements.
+type Store interface {
+	// Announce records in as seen now, replacing its earlier announcement.
+	Announce(ctx context.Context, in Instance) error
+	// ListLive returns the instances announced since since.
+	ListLive(ctx context.Context, since time.Time) ([]Instance, error)
+	// Remove forgets the instance with id.
+	Remove(ctx context.Context, id string) error
+}
+
+// Reporter announces one process and warns when the live instances
+// disagree.
+type Reporter struct {
+	store    Store
+	self     Instance
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewReporter(store Store, id, service string, fp config.Fingerprint) *Reporter {
+	return &Reporter{
+		store: store,
+		self: Instance{
+			ID:          id,
+			Service:     service,
+			Fingerprint: fp.Hash,
+			Parts:       fp.Parts,
+			StartedAt:   time.Now().UTC(),
+		},
+		interval: RefreshInterval,
+		now:      time.Now,
+	}
+}
+
+// Refresh announces the process and compares it with the other live
+// instances, logging config.drift when they disagree.
+func (r *Reporter) Refresh(ctx context.Context) (Report, error) {
+	if err := r.store.Announce(ctx, r.self); err != nil {
+		return Report{}, err
+	}
+
+	live, err := r.store.ListLive(ctx, r.now().Add(-LiveWindow))
+	if err != nil {
+		return Report{}, err
+	}
+
+	rep := Check(live)
+	if rep.Drifted {
+		slog.Default().WarnContext(ctx, "config.drift",
+			"instance", r.self.ID,
+			"service", r.self.Service,
+			"fingerprint", r.self.Fingerprint,
+			"parts", rep.Parts,
+			"fingerprints", rep.Fingerprints,
+		)
+	}
+	return rep, nil
+}
+
+// Run refreshes now and then every interval until ctx is cancelled, then
+// removes the process's announcement.
+func (r *Reporter) Run(ctx context.Context) {
+	refresh := func() {
+		rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if _, err := r.Refresh(rctx); err != nil {
+			slog.Default().Error("config.drift_check_failed", "instance", r.self.ID, "err", err)
+		}
+	}
+
+	refresh()
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			rctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			if err := r.store.Remove(rctx, r.self.ID); err != nil {
+				slog.Default().Error("config.drift_remove_failed", "instance", r.self.ID, "err", err)
+			}
+			cancel()
+			return
+
+		case <-t.C:
+			refresh()
+		}
+	}
+}
@@ -16,6 +16,9 @@ func NewPool(dbURL string) (*pgxpool.Pool, error) {
 
 	cfg.MaxConns = 5
 
+	// no-op unless the query budget middleware put a counter on the context
+	cfg.ConnConfig.Tracer = QueryTracer{}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
 	defer cancel()
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type queryCounterKey struct{}
+
+// QueryCounter tallies the queries run on behalf of one request. It is
+// attached to the request context and fed by QueryTracer.
+type QueryCounter struct {
+	mu      sync.Mutex
+	total   int
+	byQuery map[string]int
+}
+
+// QueryCount is one line of a QueryCounter breakdown.
+type QueryCount struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	c := &QueryCounter{byQuery: make(map[string]int)}
+	return context.WithValue(ctx, queryCounterKey{}, c), c
+}
+
+func QueryCounterFrom(ctx context.Context) (*QueryCounter, bool) {
+	c, ok := ctx.Value(queryCounterKey{}).(*QueryCounter)
+	return c, ok && c != nil
+}
+
+func (c *QueryCounter) Add(sql string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total++
+	c.byQuery[normalizeSQL(sql)]++
+}
+
+func (c *QueryCounter) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// Breakdown lists distinct statements, most frequent first, so an N+1 shows
+// up as one statement with a large count.
+func (c *QueryCounter) Breakdown() []QueryCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]QueryCount, 0, len(c.byQuery))
+	for q, n := range c.byQuery {
+		out = append(out, QueryCount{Query: q, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Query < out[j].Query
+	})
+	return out
+}
+
+// QueryTracer is a pgx tracer that counts every query whose context carries a
+// QueryCounter. Without one it does nothing.
+type QueryTracer struct{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if c, ok := QueryCounterFrom(ctx); ok {
+		c.Add(data.SQL)
+	}
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+const maxQueryKeyLen = 80
+
+func normalizeSQL(sql string) string {
+	s := strings.Join(strings.Fields(sql), " ")
+	if len(s) > maxQueryKeyLen {
+		s = s[:maxQueryKeyLen] + "..."
+	}
+	return s
+}
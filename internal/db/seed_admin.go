@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/config"
@@ -13,16 +14,24 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// EnsureAdminUser creates the configured admin account when it is missing.
+// An ADMIN_ROLE that is not a known role fails startup rather than seeding a
+// user who can do nothing.
 func EnsureAdminUser(ctx context.Context, pool *pgxpool.Pool, cfg config.Config) error {
 	if cfg.AdminEmail == "" || cfg.AdminPassword == "" {
 		return nil
 	}
 
+	role, err := user.ParseRole(cfg.AdminRole)
+	if err != nil {
+		return fmt.Errorf("ADMIN_ROLE: %w", err)
+	}
+
 	// check if the user exists
 
 	var dummy string
 
-	err := pool.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, cfg.AdminEmail).Scan(&dummy)
+	err = pool.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, cfg.AdminEmail).Scan(&dummy)
 
 	if err == nil {
 		return nil
@@ -32,7 +41,13 @@ func EnsureAdminUser(ctx context.Context, pool *pgxpool.Pool, cfg config.Config)
 		return err
 	}
 
-	hash, err := security.HashPassword(cfg.AdminPassword)
+	passwords, err := security.NewPasswords(cfg.PasswordParams())
+
+	if err != nil {
+		return err
+	}
+
+	hash, err := passwords.Hash(cfg.AdminPassword)
 
 	if err != nil {
 		return err
@@ -45,7 +60,7 @@ func EnsureAdminUser(ctx context.Context, pool *pgxpool.Pool, cfg config.Config)
 		Email:        cfg.AdminEmail,
 		PasswordHash: hash,
 		Name:         cfg.AdminName,
-		Role:         cfg.AdminRole,
+		Role:         role,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
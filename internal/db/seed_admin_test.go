@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+)
+
+func TestParseRole(t *testing.T) {
+	for in, want := range map[string]user.Role{
+		"admin":   user.RoleAdmin,
+		"user":    user.RoleUser,
+		"Admin":   user.RoleAdmin,
+		" USER\n": user.RoleUser,
+	} {
+		got, err := user.ParseRole(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseRole(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "admn", "superuser", "admin,user"} {
+		if _, err := user.ParseRole(in); !errors.Is(err, user.ErrUnknownRole) {
+			t.Fatalf("ParseRole(%q): expected ErrUnknownRole, got %v", in, err)
+		}
+	}
+}
+
+func TestEnsureAdminUser_RejectsUnknownRoleBeforeTheDatabase(t *testing.T) {
+	cfg := config.Config{AdminEmail: "admin@example.com", AdminPassword: "secret", AdminRole: "superuser"}
+
+	// a nil pool would panic if the seed got as far as querying
+	err := EnsureAdminUser(context.Background(), nil, cfg)
+	if !errors.Is(err, user.ErrUnknownRole) {
+		t.Fatalf("expected ErrUnknownRole, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package apikey
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewFromCreateRequest builds ownerID's key and its secret. raw goes back to
+// the caller once; only hash is stored.
+func NewFromCreateRequest(ownerID string, req CreateRequest) (k Key, raw, hash string, err error) {
+	raw, prefix, hash, err := Generate()
+	if err != nil {
+		return Key{}, "", "", err
+	}
+
+	k = Key{
+		ID:        uuid.NewString(),
+		OwnerID:   ownerID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		EventIDs:  dedupe(req.EventIDs),
+		CreatedAt: time.Now().UTC(),
+	}
+	if req.AllEvents {
+		k.OrganizerID = ownerID
+	}
+	for _, a := range dedupe(req.Actions) {
+		k.Actions = append(k.Actions, Action(a))
+	}
+
+	return k, raw, hash, nil
+}
+
+func dedupe(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCheckInNotUsed means nobody checked in to the event, so finalizing it
+// would mark every attendee a no-show.
+var ErrCheckInNotUsed = errors.New("event check-in was never used")
+
+// ErrAttendanceNotFinalized is returned for events without attendance stats yet.
+var ErrAttendanceNotFinalized = errors.New("event attendance not finalized")
+
+// AttendanceStats is recorded once per event when its attendance is finalized.
+// Registrations counts the confirmed registrations at that point, split into
+// those who checked in and the no-shows.
+type AttendanceStats struct {
+	EventID       string    `json:"eventId"`
+	Registrations int       `json:"registrations"`
+	CheckedIn     int       `json:"checkedIn"`
+	NoShows       int       `json:"noShows"`
+	NoShowRate    float64   `json:"noShowRate"`
+	FinalizedAt   time.Time `json:"finalizedAt"`
+}
+
+// AttendanceRollup sums the finalized attendance of one organizer's events.
+type AttendanceRollup struct {
+	Events        int               `json:"events"`
+	Registrations int               `json:"registrations"`
+	CheckedIn     int               `json:"checkedIn"`
+	NoShows       int               `json:"noShows"`
+	NoShowRate    float64           `json:"noShowRate"`
+	Recent        []AttendanceStats `json:"recent"`
+}
+
+// NoShowRate is noShows over registrations, 0 when there were none.
+func NoShowRate(noShows, registrations int) float64 {
+	if registrations <= 0 {
+		return 0
+	}
+	return float64(noShows) / float64(registrations)
+}
@@ -0,0 +1,46 @@
+package event
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Branding is how an organizer's confirmation emails present themselves.
+// Empty fields fall back to the platform sender.
+type Branding struct {
+	ReplyTo     string `json:"replyTo,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	LogoURL     string `json:"logoUrl,omitempty"`
+}
+
+// UpdateBrandingRequest replaces an event's branding; omitted fields are cleared.
+type UpdateBrandingRequest struct {
+	ReplyTo     string `json:"replyTo" binding:"omitempty,email,max=254"`
+	DisplayName string `json:"displayName" binding:"omitempty,max=80"`
+	LogoURL     string `json:"logoUrl" binding:"omitempty,url,max=500"`
+}
+
+var ErrInvalidBranding = errors.New("invalid branding")
+
+// Branding trims the request and checks what binding tags cannot: the display
+// name ends up in a mail header, and logos are loaded by mail clients.
+func (r UpdateBrandingRequest) Branding() (Branding, error) {
+	b := Branding{
+		ReplyTo:     strings.TrimSpace(r.ReplyTo),
+		DisplayName: strings.TrimSpace(r.DisplayName),
+		LogoURL:     strings.TrimSpace(r.LogoURL),
+	}
+
+	if strings.ContainsAny(b.DisplayName+b.ReplyTo, "\r\n<>\"") {
+		return Branding{}, ErrInvalidBranding
+	}
+	if b.LogoURL != "" {
+		u, err := url.Parse(b.LogoURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return Branding{}, ErrInvalidBranding
+		}
+	}
+
+	return b, nil
+}
@@ -0,0 +1,55 @@
+package event
+
+// CounterRepair is the outcome of recomputing registered_count for one event.
+type CounterRepair struct {
+	EventID  string `json:"eventId"`
+	Stored   int    `json:"stored"`
+	Actual   int    `json:"actual"`
+	Delta    int    `json:"delta"`
+	Repaired bool   `json:"repaired"`
+}
+
+// Availability is the public view of how many seats an event has left and who
+// may take them.
+type Availability struct {
+	EventID             string   `json:"eventId"`
+	Capacity            int      `json:"capacity"`
+	RegisteredCount     int      `json:"registeredCount"`
+	Remaining           int      `json:"remaining"`
+	Full                bool     `json:"full"`
+	MaxQuantity         int      `json:"maxQuantity"`
+	RequiresAuth        bool     `json:"requiresAuth"`
+	AllowedEmailDomains []string `json:"allowedEmailDomains"`
+}
+
+// DriftStats summarises a counter verification run.
+type DriftStats struct {
+	Checked  int `json:"checked"`
+	Drifted  int `json:"drifted"`
+	MaxDelta int `json:"maxDelta"`
+}
+
+func (s *DriftStats) Add(r CounterRepair) {
+	s.Checked++
+	if !r.Repaired {
+		return
+	}
+
+	s.Drifted++
+	d := r.Delta
+	if d < 0 {
+		d = -d
+	}
+	if d > s.MaxDelta {
+		s.MaxDelta = d
+	}
+}
+
+// PlatformStats are the platform-wide counts on the public landing page. Only
+// published events that were not deleted count, and only their registrations
+// that were not cancelled.
+type PlatformStats struct {
+	EventsHosted    int64 `json:"eventsHosted"`
+	Registrations   int64 `json:"registrations"`
+	EventsThisMonth int64 `json:"eventsThisMonth"`
+}
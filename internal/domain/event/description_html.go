@@ -0,0 +1,79 @@
+package event
+
+import (
+	"bytes"
+	"compress/gzip"
+	"html"
+	"io"
+	"strings"
+)
+
+// RenderDescriptionHTML renders a plain-text description as HTML: escaped,
+// a paragraph per blank-line separated block and <br> for single newlines.
+func RenderDescriptionHTML(description string) string {
+	text := strings.ReplaceAll(strings.TrimSpace(description), "\r\n", "\n")
+	if text == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		lines := strings.Split(para, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(strings.TrimSpace(line))
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(lines, "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// GzipDescriptionHTML renders description and gzips it, the form the HTML is
+// stored in.
+func GzipDescriptionHTML(description string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(RenderDescriptionHTML(description))); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WithDescriptionHTML fills in DescriptionHTML from the stored gzip copy, or
+// renders it from Description for events stored without one. A stored copy
+// that can't be read is rendered afresh too, and its error returned so the
+// caller can report it.
+func (e Event) WithDescriptionHTML() (Event, error) {
+	var rendered string
+	var err error
+	if e.DescriptionHTMLGzip != nil {
+		rendered, err = gunzipString(e.DescriptionHTMLGzip)
+	}
+	if e.DescriptionHTMLGzip == nil || err != nil {
+		rendered = RenderDescriptionHTML(e.Description)
+	}
+	e.DescriptionHTML = &rendered
+	return e, err
+}
+
+func gunzipString(b []byte) (string, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
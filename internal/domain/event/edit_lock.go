@@ -0,0 +1,38 @@
+package event
+
+import (
+	"errors"
+	"time"
+)
+
+// EditLockTTL is how long an edit lock lasts without being renewed.
+const EditLockTTL = 5 * time.Minute
+
+// ErrEditLockHeld means another admin holds a live edit lock on the event.
+var ErrEditLockHeld = errors.New("event edit lock held by someone else")
+
+// ErrEditLockNotFound means nobody holds a live edit lock on the event.
+var ErrEditLockNotFound = errors.New("event edit lock not found")
+
+// EditLock is an advisory lock an admin takes while editing an event. It
+// only warns other editors; updates are not blocked at the database. Token
+// is given to the holder alone and stays the same across renewals.
+type EditLock struct {
+	EventID     string    `json:"eventId"`
+	HolderID    string    `json:"holderId"`
+	HolderEmail string    `json:"holderEmail,omitempty"`
+	Token       string    `json:"token,omitempty"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Live reports whether the lock still holds at now.
+func (l EditLock) Live(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
+
+// Public is the lock as shown to anyone but its holder: without the token.
+func (l EditLock) Public() EditLock {
+	l.Token = ""
+	return l
+}
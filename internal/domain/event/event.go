@@ -2,20 +2,54 @@ package event
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
 )
 
 type Event struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description,omitempty"`
-	City        string    `json:"city,omitempty"`
-	Category    string    `json:"category,omitempty"`
-	Tags        []string  `json:"tags,omitempty"`
-	StartAt     time.Time `json:"startAt"`
-	Capacity    int       `json:"capacity"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+
+	// DescriptionHTML is Description rendered, stored gzipped as
+	// DescriptionHTMLGzip. Only the event detail and lists asking for it
+	// decompress it; see WithDescriptionHTML.
+	DescriptionHTML     *string `json:"descriptionHtml,omitempty"`
+	DescriptionHTMLGzip []byte  `json:"-"`
+
+	City     string    `json:"city,omitempty"`
+	Category string    `json:"category,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	StartAt  time.Time `json:"startAt"`
+	Capacity int       `json:"capacity"`
+
+	// registration restrictions: only logged-in users, optionally only from these email domains
+	RequiresAuth        bool     `json:"requiresAuth"`
+	AllowedEmailDomains []string `json:"allowedEmailDomains"`
+
+	// MaxQuantity caps the seats one registration may reserve (1 = no guests).
+	MaxQuantity int `json:"maxQuantity"`
+
+	// CapacityAlertThresholds are the percentages of Capacity at which the
+	// organizer is alerted, ascending; empty disables alerts.
+	CapacityAlertThresholds []int `json:"capacityAlertThresholds"`
+
+	// OrganizerID is the user who created the event; empty for events that predate ownership.
+	OrganizerID string `json:"organizerId,omitempty"`
+
+	// Visibility is public, unlisted or private; see VisibleTo.
+	Visibility Visibility `json:"visibility"`
+
+	// optional registration window, see RegistrationWindowAt
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt,omitempty"`
+
+	// seats taken, for registrationState; availability reports the count itself
+	RegisteredCount int `json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 // with pointers if optional, it will be nil
@@ -28,6 +62,10 @@ type ListEventsFilter struct {
 	Query    *string
 	Limit    int
 	Offset   int
+
+	// IncludeDescriptionHTML has ListCursor read and decompress each
+	// event's DescriptionHTML; lists leave it out otherwise.
+	IncludeDescriptionHTML bool
 }
 
 var ErrNotFound = errors.New("event not found")
@@ -40,6 +78,25 @@ type CreateEventRequest struct {
 	Tags        []string  `json:"tags" binding:"omitempty,max=20,dive,min=2,max=30"`
 	StartAt     time.Time `json:"startAt" binding:"required"`
 	Capacity    int       `json:"capacity" binding:"required,min=1,max=50000"`
+
+	RequiresAuth        bool     `json:"requiresAuth"`
+	AllowedEmailDomains []string `json:"allowedEmailDomains" binding:"omitempty,max=20,dive,min=3,max=253"`
+
+	// seats one registration may reserve; 0 means 1
+	MaxQuantity int `json:"maxQuantity" binding:"omitempty,min=1,max=50"`
+
+	// omitted means DefaultCapacityAlertThresholds, [] disables alerts
+	CapacityAlertThresholds []int `json:"capacityAlertThresholds" binding:"omitempty,max=5,dive,min=1,max=100"`
+
+	// optional; Validate checks them against StartAt
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt"`
+
+	// omitted means public
+	Visibility Visibility `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+
+	// set by the handler from the caller's identity, never from the body
+	OrganizerID string `json:"-"`
 }
 
 // a full update payload, might switch to a patch which optionally provides means for partial updates.
@@ -51,4 +108,94 @@ type UpdateEventRequest struct {
 	Tags        []string  `json:"tags" binding:"omitempty,max=20,dive,min=2,max=30"`
 	StartAt     time.Time `json:"startAt" binding:"required"`
 	Capacity    int       `json:"capacity" binding:"required,min=1,max=50000"`
+
+	RequiresAuth        bool     `json:"requiresAuth"`
+	AllowedEmailDomains []string `json:"allowedEmailDomains" binding:"omitempty,max=20,dive,min=3,max=253"`
+
+	// seats one registration may reserve; 0 means 1
+	MaxQuantity int `json:"maxQuantity" binding:"omitempty,min=1,max=50"`
+
+	// omitted means DefaultCapacityAlertThresholds, [] disables alerts
+	CapacityAlertThresholds []int `json:"capacityAlertThresholds" binding:"omitempty,max=5,dive,min=1,max=100"`
+
+	// optional; Validate checks them against StartAt
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt"`
+
+	// omitted keeps the current visibility
+	Visibility Visibility `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+}
+
+// DefaultCapacityAlertThresholds alert the organizer when an event is nearly
+// full and when it sells out.
+var DefaultCapacityAlertThresholds = []int{90, 100}
+
+// NormalizeCapacityAlertThresholds sorts and de-duplicates thresholds. nil
+// means the defaults; the result is never nil, so the column is always an array.
+func NormalizeCapacityAlertThresholds(thresholds []int) []int {
+	if thresholds == nil {
+		return slices.Clone(DefaultCapacityAlertThresholds)
+	}
+
+	out := slices.Clone(thresholds)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// CrossedCapacityThresholds returns the thresholds that registered went past
+// when it grew from before: t is crossed once seats reach t% of capacity.
+func CrossedCapacityThresholds(thresholds []int, capacity, before, registered int) []int {
+	if capacity <= 0 || registered <= before {
+		return nil
+	}
+
+	var out []int
+	for _, t := range thresholds {
+		if before*100 < t*capacity && registered*100 >= t*capacity {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// NormalizeEmailDomains lowercases, strips a leading "@" and de-duplicates the
+// allowed domains. It never returns nil, so the column is always an array.
+func NormalizeEmailDomains(domains []string) []string {
+	out := make([]string, 0, len(domains))
+	seen := make(map[string]struct{}, len(domains))
+
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "@")
+		if d == "" {
+			continue
+		}
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+		out = append(out, d)
+	}
+
+	return out
+}
+
+// EmailDomainAllowed reports whether email may register for an event restricted
+// to domains. An empty list allows every domain.
+func EmailDomainAllowed(email string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	for _, d := range domains {
+		if domain == d {
+			return true
+		}
+	}
+	return false
 }
@@ -18,7 +18,19 @@ func NewFromCreateRequest(req CreateEventRequest) Event {
 		Tags:        req.Tags,
 		StartAt:     req.StartAt,
 		Capacity:    req.Capacity,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+
+		RequiresAuth:        req.RequiresAuth,
+		AllowedEmailDomains: NormalizeEmailDomains(req.AllowedEmailDomains),
+		MaxQuantity:         max(req.MaxQuantity, 1),
+		OrganizerID:         req.OrganizerID,
+		Visibility:          NormalizeVisibility(req.Visibility),
+
+		RegistrationOpensAt:  req.RegistrationOpensAt,
+		RegistrationClosesAt: req.RegistrationClosesAt,
+
+		CapacityAlertThresholds: NormalizeCapacityAlertThresholds(req.CapacityAlertThresholds),
+
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 }
@@ -0,0 +1,28 @@
+package event
+
+// MaxPublishBatch bounds the events one batch publish takes.
+const MaxPublishBatch = 100
+
+// PublishItemStatus is what a batch publish did with one event.
+type PublishItemStatus string
+
+const (
+	PublishEnqueued PublishItemStatus = "enqueued"
+	// fine, but not enqueued because the rest of the batch was rejected
+	PublishValid PublishItemStatus = "valid"
+	// the id is not a UUID
+	PublishInvalidID PublishItemStatus = "invalid_id"
+	// no live event has the id
+	PublishNotFound         PublishItemStatus = "not_found"
+	PublishAlreadyPublished PublishItemStatus = "already_published"
+	// the id appears earlier in the same batch
+	PublishDuplicate PublishItemStatus = "duplicate"
+)
+
+// PublishItemResult reports one event of a batch publish; JobID is set
+// only for enqueued events.
+type PublishItemResult struct {
+	EventID string            `json:"eventId"`
+	Status  PublishItemStatus `json:"status"`
+	JobID   string            `json:"jobId,omitempty"`
+}
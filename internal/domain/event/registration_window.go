@@ -0,0 +1,89 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/validation"
+)
+
+// RegistrationState is whether an event takes registrations right now.
+type RegistrationState string
+
+const (
+	RegistrationNotOpen RegistrationState = "not_open"
+	RegistrationOpen    RegistrationState = "open"
+	RegistrationClosed  RegistrationState = "closed"
+	RegistrationFull    RegistrationState = "full"
+)
+
+var ErrInvalidRegistrationWindow = errors.New("registration window must satisfy opens < closes <= startAt")
+
+// ValidateRegistrationWindow checks optional open/close times against each
+// other and the start of the event.
+func ValidateRegistrationWindow(opensAt, closesAt *time.Time, startAt time.Time) error {
+	if len(registrationWindowErrors(opensAt, closesAt, startAt)) > 0 {
+		return ErrInvalidRegistrationWindow
+	}
+	return nil
+}
+
+// registrationWindowErrors is ValidateRegistrationWindow per field, for the
+// request bodies' Validate.
+func registrationWindowErrors(opensAt, closesAt *time.Time, startAt time.Time) []validation.FieldError {
+	var out []validation.FieldError
+
+	if opensAt != nil && !opensAt.Before(startAt) {
+		out = append(out, validation.FieldError{
+			Field: "registrationOpensAt", Rule: "ltfield", Param: "startAt",
+			Message: "must be before startAt",
+		})
+	}
+	if closesAt != nil && closesAt.After(startAt) {
+		out = append(out, validation.FieldError{
+			Field: "registrationClosesAt", Rule: "ltefield", Param: "startAt",
+			Message: "must not be after startAt",
+		})
+	}
+	if opensAt != nil && closesAt != nil && !opensAt.Before(*closesAt) {
+		out = append(out, validation.FieldError{
+			Field: "registrationOpensAt", Rule: "ltfield", Param: "registrationClosesAt",
+			Message: "must be before registrationClosesAt",
+		})
+	}
+	return out
+}
+
+// RegistrationWindowAt places now against the window: registration opens at
+// RegistrationOpensAt and closes at RegistrationClosesAt. Without a window an
+// event is open until the registration grace period after it starts, which
+// RegistrationRepo enforces separately.
+func (e Event) RegistrationWindowAt(now time.Time) RegistrationState {
+	if e.RegistrationOpensAt != nil && now.Before(*e.RegistrationOpensAt) {
+		return RegistrationNotOpen
+	}
+	if e.RegistrationClosesAt != nil && !now.Before(*e.RegistrationClosesAt) {
+		return RegistrationClosed
+	}
+	return RegistrationOpen
+}
+
+// RegistrationStateAt is RegistrationWindowAt, reporting an open event with
+// no seats left as full.
+func (e Event) RegistrationStateAt(now time.Time) RegistrationState {
+	s := e.RegistrationWindowAt(now)
+	if s == RegistrationOpen && e.RegisteredCount >= e.Capacity {
+		return RegistrationFull
+	}
+	return s
+}
+
+// MarshalJSON adds registrationState, computed when the event is written out.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plain Event
+	return json.Marshal(struct {
+		plain
+		RegistrationState RegistrationState `json:"registrationState"`
+	}{plain(e), e.RegistrationStateAt(time.Now())})
+}
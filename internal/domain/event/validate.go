@@ -0,0 +1,34 @@
+package event
+
+import (
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/validation"
+)
+
+// Validate checks the rules spanning fields: a new event starts in the future
+// and its registration window fits before the start.
+func (r CreateEventRequest) Validate() []validation.FieldError {
+	// a missing startAt is already reported by its required tag
+	if r.StartAt.IsZero() {
+		return nil
+	}
+
+	var out []validation.FieldError
+	if !r.StartAt.After(time.Now()) {
+		out = append(out, validation.FieldError{
+			Field: "startAt", Rule: "future",
+			Message: "must be in the future",
+		})
+	}
+	return append(out, registrationWindowErrors(r.RegistrationOpensAt, r.RegistrationClosesAt, r.StartAt)...)
+}
+
+// Validate checks the registration window against the start. Unlike create,
+// an update may keep (or move) an event into the past, e.g. to fix its record.
+func (r UpdateEventRequest) Validate() []validation.FieldError {
+	if r.StartAt.IsZero() {
+		return nil
+	}
+	return registrationWindowErrors(r.RegistrationOpensAt, r.RegistrationClosesAt, r.StartAt)
+}
@@ -0,0 +1,63 @@
+package event
+
+// Visibility decides where an event shows up and who may open it.
+type Visibility string
+
+const (
+	// VisibilityPublic events are listed and open to anyone.
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted events are left out of listings but open to anyone
+	// with the link, registration included.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPrivate events are unlisted and only visible to their
+	// organizer, admins and registrants; registering takes a login.
+	VisibilityPrivate Visibility = "private"
+)
+
+// NormalizeVisibility maps the empty value to VisibilityPublic.
+func NormalizeVisibility(v Visibility) Visibility {
+	if v == "" {
+		return VisibilityPublic
+	}
+	return v
+}
+
+// Listed reports whether e belongs in public listings.
+func (e Event) Listed() bool {
+	return NormalizeVisibility(e.Visibility) == VisibilityPublic
+}
+
+// Viewer is who is asking to see an event.
+type Viewer struct {
+	UserID     string
+	Admin      bool
+	Registered bool
+}
+
+// Access is the part of an event that decides who may open it.
+type Access struct {
+	Visibility  Visibility
+	OrganizerID string
+}
+
+// Access returns e's visibility and organizer.
+func (e Event) Access() Access {
+	return Access{Visibility: e.Visibility, OrganizerID: e.OrganizerID}
+}
+
+// VisibleTo reports whether v may open e. Only private events are
+// restricted: to a signed-in organizer, admin or registrant.
+func (e Event) VisibleTo(v Viewer) bool {
+	return e.Access().VisibleTo(v)
+}
+
+// VisibleTo reports whether v may open an event with access a.
+func (a Access) VisibleTo(v Viewer) bool {
+	if a.Visibility != VisibilityPrivate {
+		return true
+	}
+	if v.UserID == "" {
+		return false
+	}
+	return v.Admin || v.Registered || (a.OrganizerID != "" && a.OrganizerID == v.UserID)
+}
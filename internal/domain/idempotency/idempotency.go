@@ -0,0 +1,18 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("idempotent response not found")
+
+// Response is the first response written for an idempotency key, replayed
+// verbatim (apart from live fields) to duplicate submissions.
+type Response struct {
+	Key        string
+	StatusCode int
+	Body       []byte
+	JobID      *string
+	CreatedAt  time.Time
+}
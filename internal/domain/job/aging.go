@@ -0,0 +1,16 @@
+package job
+
+import "time"
+
+// Aging raises the claim priority of pending jobs the longer they wait past
+// run_at: one point per Interval, at most MaxBoost, so a steady stream of
+// high-priority work cannot starve the rest. The zero value disables it and
+// jobs are claimed by priority alone.
+type Aging struct {
+	Interval time.Duration
+	MaxBoost int
+}
+
+func (a Aging) Enabled() bool {
+	return a.Interval >= time.Second && a.MaxBoost > 0
+}
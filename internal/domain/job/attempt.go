@@ -0,0 +1,37 @@
+package job
+
+import "time"
+
+// AttemptOutcome is how one execution of a job ended.
+type AttemptOutcome string
+
+const (
+	AttemptDone         AttemptOutcome = "done"
+	AttemptRetried      AttemptOutcome = "retried"
+	AttemptDeadLettered AttemptOutcome = "dead_lettered"
+	AttemptCancelled    AttemptOutcome = "cancelled"
+	// another worker holds the job now and records its own attempt
+	AttemptLockLost AttemptOutcome = "lock_lost"
+	// the outcome could not be written; the stale requeue runs the job again
+	AttemptError AttemptOutcome = "error"
+	// cut short by shutdown and handed back unspent; not an attempt, so it
+	// is never stored in job_attempts
+	AttemptInterrupted AttemptOutcome = "interrupted"
+)
+
+// Attempt is one execution of a job, kept so a job that retried many times
+// shows every error rather than only the last one.
+type Attempt struct {
+	JobID      string         `json:"jobId"`
+	Attempt    int            `json:"attempt"`
+	WorkerID   string         `json:"workerId"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	Outcome    AttemptOutcome `json:"outcome"`
+	Error      *string        `json:"error,omitempty"`
+	DurationMs int64          `json:"durationMs"`
+	// the job.run span the attempt ran under, hex encoded; empty when
+	// tracing was off
+	TraceID string `json:"traceId,omitempty"`
+	SpanID  string `json:"spanId,omitempty"`
+}
@@ -0,0 +1,54 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// ErrDeadLetterReplayed: the dead letter was already put back on the queue,
+// by a replay or by retrying its job.
+var ErrDeadLetterReplayed = errors.New("dead letter already replayed")
+
+// DeadLetter is the copy of a job kept when it runs out of retries. It
+// outlives the job row, so what failed can still be inspected and replayed
+// after the jobs table is pruned.
+type DeadLetter struct {
+	ID             string          `json:"id"`
+	JobID          string          `json:"jobId"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"maxAttempts"`
+	Priority       int             `json:"priority,omitempty"`
+	IdempotencyKey *string         `json:"idempotencyKey,omitempty"`
+	UserID         *string         `json:"userId"`
+	JobCreatedAt   time.Time       `json:"jobCreatedAt"`
+	FailedAt       time.Time       `json:"failedAt"`
+	FinalError     *string         `json:"finalError,omitempty"`
+
+	// set once the work is back on the queue; ReplayedJobID is JobID itself
+	// when the original job was retried in place
+	ReplayedAt    *time.Time `json:"replayedAt,omitempty"`
+	ReplayedJobID *string    `json:"replayedJobId,omitempty"`
+}
+
+// Replayed reports whether the dead letter's work was already requeued.
+func (d DeadLetter) Replayed() bool {
+	return d.ReplayedAt != nil
+}
+
+// ReplayRequest is the fresh job a replay enqueues: same type, payload and
+// limits, a new ID and no attempts. The idempotency key stays with the
+// original job row.
+func (d DeadLetter) ReplayRequest() CreateRequest {
+	return CreateRequest{
+		Type:        d.Type,
+		Payload:     d.Payload,
+		MaxAttempts: d.MaxAttempts,
+		Priority:    d.Priority,
+		UserID:      d.UserID,
+	}
+}
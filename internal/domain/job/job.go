@@ -15,10 +15,47 @@ const (
 	StatusProcessing Status = "processing"
 	StatusDone       Status = "done"
 	StatusFailed     Status = "failed"
+	StatusCancelled  Status = "cancelled"
 )
 
+// Valid reports whether s is one of the statuses a job can be in.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusProcessing, StatusDone, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Terminal reports whether a job in s will never run again on its own.
+func (s Status) Terminal() bool {
+	switch s {
+	case StatusDone, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
 var ErrJobNotFound = errors.New("job not found")
 
+// ErrNotPurgeable: a purge asked for pending or processing jobs, which are
+// never deleted.
+var ErrNotPurgeable = errors.New("only done, failed and cancelled jobs can be purged")
+
+// ErrLockLost: the job is no longer processing under this worker's lock,
+// typically because the stale requeue handed it to someone else.
+var ErrLockLost = errors.New("job lock lost")
+
+// ErrJobNotCancellable: the job already finished (done, failed or cancelled).
+var ErrJobNotCancellable = errors.New("job is not cancellable")
+
+// ErrCancelRequested: an admin asked to cancel the job while it was running.
+// The worker aborts it and records it as cancelled instead of retrying.
+var ErrCancelRequested = errors.New("job cancellation requested")
+
+// ErrDuplicateIdempotencyKey: a job with the same idempotency key already exists.
+var ErrDuplicateIdempotencyKey = errors.New("duplicate job idempotency key")
+
 type Job struct {
 	ID          string          `json:"id"`
 	Type        string          `json:"type"`
@@ -31,15 +68,56 @@ type Job struct {
 	LockedBy    *string         `json:"lockedBy,omitempty"`
 	LastError   *string         `json:"lastError,omitempty"`
 	// new Idempotency key
-	IdempotencyKey *string   `json:"idempotencyKey,omitempty"`
-	Priority       int       `json:"priority,omitempty"` // added this for priority in a job
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+	Priority       int     `json:"priority,omitempty"` // added this for priority in a job
+	// priority pending jobs are claimed at once aging is applied (admin listing only)
+	EffectivePriority *int      `json:"effectivePriority,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
 
 	// actor context
 	UserID *string `json:"userId"`
+
+	// outcome reported by jobs that produce one (e.g. events.verify_counters)
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// latest attempt: wait from due to started, then run time (admin detail only)
+	QueueWaitMs *int64 `json:"queueWaitMs,omitempty"`
+	ExecutionMs *int64 `json:"executionMs,omitempty"`
+
+	// latest report of a long-running job (admin detail only)
+	Progress *Progress `json:"progress,omitempty"`
+
+	// W3C trace context of the span that enqueued the job; job.run is its child
+	TraceParent *string `json:"traceParent,omitempty"`
+	TraceState  *string `json:"traceState,omitempty"`
+}
+
+// Progress is how far a long-running job has got, as it last reported.
+// Total is 0 when the job cannot tell how much work there is.
+type Progress struct {
+	Done      int64     `json:"done"`
+	Total     int64     `json:"total"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Finished reports whether p covers all the work it counted.
+func (p Progress) Finished() bool {
+	return p.Total > 0 && p.Done >= p.Total
+}
+
+// ScheduledFilter bounds a listing of pending jobs waiting for their run_at
+// to RunAfter < run_at < RunBefore. A nil RunAfter means now, so only jobs
+// still in the future are listed; a nil RunBefore leaves the range open.
+type ScheduledFilter struct {
+	RunAfter  *time.Time
+	RunBefore *time.Time
+}
+
+// DefaultMaxAttempts applies to jobs enqueued without a limit of their own.
+const DefaultMaxAttempts = 25
+
 type CreateRequest struct {
 	Type           string
 	Payload        json.RawMessage
@@ -48,6 +126,10 @@ type CreateRequest struct {
 	IdempotencyKey *string
 	Priority       int // added for priority in a job
 	UserID         *string
+
+	// filled in from the enqueuing context by the repo when left empty
+	TraceParent string
+	TraceState  string
 }
 
 func New(req CreateRequest) Job {
@@ -56,7 +138,7 @@ func New(req CreateRequest) Job {
 	maxA := req.MaxAttempts
 
 	if maxA <= 0 {
-		maxA = 25
+		maxA = DefaultMaxAttempts
 	}
 
 	runAt := req.RunAt
@@ -73,10 +155,19 @@ func New(req CreateRequest) Job {
 		Attempts:       0,
 		MaxAttempts:    maxA,
 		IdempotencyKey: req.IdempotencyKey,
-		Priority:       req.Priority,
+		Priority:       ClampPriority(req.Priority),
 		RunAt:          runAt,
 		CreatedAt:      now,
 		UpdatedAt:      now,
 		UserID:         req.UserID,
+		TraceParent:    optionalString(req.TraceParent),
+		TraceState:     optionalString(req.TraceState),
+	}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
 }
@@ -0,0 +1,102 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PayloadLimits cap what a job payload may carry. Payloads are read on every
+// claim and retry, so oversized ones are turned away at enqueue. Zero fields
+// disable that check.
+type PayloadLimits struct {
+	MaxBytes int
+	MaxDepth int
+}
+
+var DefaultPayloadLimits = PayloadLimits{MaxBytes: 256 << 10, MaxDepth: 32}
+
+var ErrPayloadTooLarge = errors.New("job payload too large")
+
+// PayloadLimitError reports the measured payload against the limit it broke.
+type PayloadLimitError struct {
+	Size     int
+	MaxBytes int
+	Depth    int
+	MaxDepth int
+}
+
+func (e *PayloadLimitError) Error() string {
+	if e.MaxBytes > 0 && e.Size > e.MaxBytes {
+		return fmt.Sprintf("%v: %d bytes exceeds %d", ErrPayloadTooLarge, e.Size, e.MaxBytes)
+	}
+	return fmt.Sprintf("%v: nesting depth %d exceeds %d", ErrPayloadTooLarge, e.Depth, e.MaxDepth)
+}
+
+func (e *PayloadLimitError) Unwrap() error { return ErrPayloadTooLarge }
+
+// TooDeep tells a depth violation from a size one.
+func (e *PayloadLimitError) TooDeep() bool {
+	return !(e.MaxBytes > 0 && e.Size > e.MaxBytes)
+}
+
+// Check returns a *PayloadLimitError when payload breaks l.
+func (l PayloadLimits) Check(payload []byte) error {
+	size := len(payload)
+	if l.MaxBytes > 0 && size > l.MaxBytes {
+		return &PayloadLimitError{Size: size, MaxBytes: l.MaxBytes, MaxDepth: l.MaxDepth}
+	}
+	if l.MaxDepth > 0 {
+		if depth := jsonDepth(payload); depth > l.MaxDepth {
+			return &PayloadLimitError{Size: size, MaxBytes: l.MaxBytes, Depth: depth, MaxDepth: l.MaxDepth}
+		}
+	}
+	return nil
+}
+
+// OversizedPayload is a stored job whose payload is over the size limit,
+// typically one enqueued before the limit existed.
+type OversizedPayload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Status    Status    `json:"status"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PayloadReport is the result of a jobs.payload_report run. Offenders are
+// largest first; Truncated means more rows matched than were listed.
+type PayloadReport struct {
+	MaxBytes  int                `json:"maxBytes"`
+	Offenders []OversizedPayload `json:"offenders"`
+	Truncated bool               `json:"truncated"`
+}
+
+// jsonDepth is the deepest object/array nesting in raw JSON. It only tracks
+// brackets outside strings; the payload's validity is the database's problem.
+func jsonDepth(raw []byte) int {
+	depth, deepest := 0, 0
+	inString, escaped := false, false
+
+	for _, c := range raw {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			deepest = max(deepest, depth)
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return deepest
+}
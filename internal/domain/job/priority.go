@@ -0,0 +1,44 @@
+package job
+
+import (
+	"errors"
+	"strings"
+)
+
+// Priorities run from MinPriority to MaxPriority; higher is claimed first.
+const (
+	MinPriority = -100
+	MaxPriority = 100
+)
+
+// The named bands callers pick from instead of raw numbers. They sit 50
+// apart, so only an Aging.MaxBoost of 50 or more lets a waiting job catch
+// up with the band above.
+const (
+	PriorityHigh   = 50
+	PriorityNormal = 0
+	PriorityLow    = -50
+)
+
+// ErrUnknownPriority is returned for a priority name other than high,
+// normal or low.
+var ErrUnknownPriority = errors.New("priority must be high, normal or low")
+
+// ParsePriority maps a band name to its priority.
+func ParsePriority(name string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "high":
+		return PriorityHigh, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "low":
+		return PriorityLow, nil
+	default:
+		return 0, ErrUnknownPriority
+	}
+}
+
+// ClampPriority bounds p to MinPriority..MaxPriority.
+func ClampPriority(p int) int {
+	return max(MinPriority, min(p, MaxPriority))
+}
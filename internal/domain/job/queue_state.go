@@ -0,0 +1,24 @@
+package job
+
+import "time"
+
+// QueueState is the queue-wide switch admins flip during incidents. While
+// Paused, workers claim nothing; jobs already running finish. PausedTypes
+// are paused on their own while the rest of the queue runs.
+type QueueState struct {
+	Paused      bool         `json:"paused"`
+	PausedAt    *time.Time   `json:"pausedAt,omitempty"`
+	PausedBy    *string      `json:"pausedBy,omitempty"`
+	PausedTypes []PausedType `json:"pausedTypes"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// PausedType is one job type workers do not claim until it is resumed.
+type PausedType struct {
+	Type     string    `json:"type"`
+	PausedAt time.Time `json:"pausedAt"`
+	PausedBy *string   `json:"pausedBy,omitempty"`
+}
+
+// MaxTypeLength bounds the job type names an admin can pause.
+const MaxTypeLength = 100
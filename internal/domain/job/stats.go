@@ -0,0 +1,61 @@
+package job
+
+import "time"
+
+// DailyStats is one worker run's job outcomes for a UTC day. WorkerID names
+// the run (worker id and start time), so a restart never reuses a row.
+type DailyStats struct {
+	Day          time.Time
+	WorkerID     string
+	Claimed      int64
+	Done         int64
+	Failed       int64
+	Retried      int64
+	DeadLettered int64
+	TimedOut     int64
+}
+
+// Add sums o's counts into s.
+func (s *DailyStats) Add(o DailyStats) {
+	s.Claimed += o.Claimed
+	s.Done += o.Done
+	s.Failed += o.Failed
+	s.Retried += o.Retried
+	s.DeadLettered += o.DeadLettered
+	s.TimedOut += o.TimedOut
+}
+
+// QueueStats is the backlog the API judges the queue's health by: pending
+// jobs that are already due, and how long the oldest of them has waited past
+// its run_at. Jobs scheduled for later are not backlog and are left out.
+type QueueStats struct {
+	DuePending       int64
+	OldestPendingAge time.Duration
+}
+
+// Stats is the whole queue at a glance, for the worker's health endpoints:
+// how many jobs are in each status, and how long the oldest due pending job
+// has waited past its run_at (zero when nothing is due).
+type Stats struct {
+	Counts           map[Status]int64
+	OldestPendingAge time.Duration
+}
+
+// StaleLockError is the last_error of a job taken back because the worker
+// running it stopped extending its lock.
+const StaleLockError = "lock expired (worker crash?)"
+
+// StaleRequeue is what one pass over processing jobs whose lock expired did
+// with them. Each such job has used up an attempt: those with attempts left
+// went back to pending, the rest were dead-lettered, and the ones an admin
+// asked to cancel were cancelled.
+type StaleRequeue struct {
+	Requeued     int64
+	DeadLettered int64
+	Cancelled    int64
+}
+
+// Total is every job the pass took back.
+func (s StaleRequeue) Total() int64 {
+	return s.Requeued + s.DeadLettered + s.Cancelled
+}
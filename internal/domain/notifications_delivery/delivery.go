@@ -2,5 +2,9 @@ package notificationsdelivery
 
 import "errors"
 
+// KindEventPublished is the delivery telling an attendee their event was
+// published. Other kinds are named after the job type that sends them.
+const KindEventPublished = "event.published"
+
 var ErrAlreadySent = errors.New("notification already sent")
 var ErrInProgress = errors.New("notification send already in progress")
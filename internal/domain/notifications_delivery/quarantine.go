@@ -0,0 +1,35 @@
+package notificationsdelivery
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// StatusSkippedQuarantined is a delivery not attempted because its
+// recipient is quarantined. Lifting the quarantine lets a later job send it.
+const StatusSkippedQuarantined = "skipped_quarantined"
+
+var ErrQuarantineNotFound = errors.New("recipient is not quarantined")
+
+// QuarantinePolicy quarantines a recipient after Threshold permanent send
+// failures within Window of the first one. Zero Threshold never quarantines.
+type QuarantinePolicy struct {
+	Threshold int
+	Window    time.Duration
+}
+
+// Quarantine is a recipient deliveries skip until an operator lifts it.
+type Quarantine struct {
+	Email         string    `json:"email"`
+	Failures      int       `json:"failures"`
+	LastError     string    `json:"lastError,omitempty"`
+	LastFailedAt  time.Time `json:"lastFailedAt"`
+	QuarantinedAt time.Time `json:"quarantinedAt"`
+}
+
+// NormalizeRecipient is the form recipients are counted and quarantined
+// under, so case and stray spaces do not split one address into several.
+func NormalizeRecipient(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,40 @@
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Redacted replaces an erased address wherever the row itself is kept.
+const Redacted = "[redacted]"
+
+const ActionEraseByEmail = "erase_by_email"
+
+// ErasureRequest asks for everything tied to Email to be removed. The
+// requester fields end up in privacy_audit.
+type ErasureRequest struct {
+	Email             string
+	RequestedByUserID string
+	RequestedByEmail  string
+	RequestID         string
+}
+
+// ErasureSummary counts the rows touched per table: deleted for users,
+// registrations and refresh tokens, redacted for deliveries, jobs and dead
+// letters.
+type ErasureSummary struct {
+	Users                  int64 `json:"users"`
+	Registrations          int64 `json:"registrations"`
+	RefreshTokens          int64 `json:"refreshTokens"`
+	NotificationDeliveries int64 `json:"notificationDeliveries"`
+	Jobs                   int64 `json:"jobs"`
+	DeadLetters            int64 `json:"deadLetters"`
+}
+
+// EmailDigest identifies an erased address in the audit trail without
+// keeping it.
+func EmailDigest(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
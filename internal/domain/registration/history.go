@@ -0,0 +1,44 @@
+package registration
+
+import "time"
+
+// History kinds: the lifecycle transitions recorded in registration_events,
+// one row per transition, written in the same transaction as the change.
+const (
+	HistoryCreated    = "created"
+	HistoryWaitlisted = "waitlisted"
+	HistoryPromoted   = "promoted"
+	HistoryCancelled  = "cancelled"
+	HistoryCheckedIn  = "checked_in"
+	HistoryNoShow     = "no_show"
+
+	// HistoryEmailChanged records an organizer correcting the attendee's
+	// address; the status does not move and neither address is kept.
+	HistoryEmailChanged = "email_changed"
+
+	// HistoryTransferred is reserved for moving a registration to another
+	// attendee; nothing performs transfers yet.
+	HistoryTransferred = "transferred"
+)
+
+// HistoryEntry is one recorded transition. FromStatus is empty for the
+// transition that created the registration; CancelledBy and
+// CancellationReason are set on HistoryCancelled entries only.
+type HistoryEntry struct {
+	ID                 string    `json:"id"`
+	RegistrationID     string    `json:"registrationId"`
+	EventID            string    `json:"eventId"`
+	Kind               string    `json:"kind"`
+	FromStatus         string    `json:"fromStatus,omitempty"`
+	ToStatus           string    `json:"toStatus"`
+	CancelledBy        string    `json:"cancelledBy,omitempty"`
+	CancellationReason string    `json:"cancellationReason,omitempty"`
+	OccurredAt         time.Time `json:"occurredAt"`
+}
+
+// ActivityFilter bounds an event's activity listing to [From, To); nil
+// bounds are open.
+type ActivityFilter struct {
+	From *time.Time
+	To   *time.Time
+}
@@ -0,0 +1,32 @@
+package registration
+
+// MaxImportRows caps one bulk import request.
+const MaxImportRows = 5000
+
+const (
+	ImportCreated   = "created"
+	ImportDuplicate = "duplicate"
+	ImportInvalid   = "invalid"
+)
+
+// ImportRow is one attendee carried over from another ticketing tool.
+type ImportRow struct {
+	Name  string `json:"name" binding:"required,min=2,max=100"`
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+
+// ImportRowResult reports what happened to the row at Row (1-based, header
+// excluded). Details holds the validation errors of an invalid row.
+type ImportRowResult struct {
+	Row            int    `json:"row"`
+	Email          string `json:"email"`
+	Status         string `json:"status"`
+	RegistrationID string `json:"registrationId,omitempty"`
+	Details        any    `json:"details,omitempty"`
+}
+
+type ImportCounts struct {
+	Created   int `json:"created"`
+	Duplicate int `json:"duplicate"`
+	Invalid   int `json:"invalid"`
+}
@@ -0,0 +1,16 @@
+package registration
+
+// UserLinkBatch is one pass of linking registrations made before they were
+// tied to accounts.
+type UserLinkBatch struct {
+	Scanned int
+	Linked  int64
+
+	// Conflicts are the registrations left unlinked because more than one
+	// verified account holds their email.
+	Conflicts []string
+
+	// LastID is the last registration looked at, where the next batch
+	// starts; empty when there was nothing left.
+	LastID string
+}
@@ -0,0 +1,76 @@
+package registration
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// PIIVisibility is how much of an attendee's identity a caller may see.
+type PIIVisibility string
+
+const (
+	// PIIFull shows names, emails and check-in tokens as stored.
+	PIIFull PIIVisibility = "full"
+	// PIIMasked keeps the first letter of the name and of the email's local
+	// part ("s***@example.com") and drops the check-in token and the
+	// cancellation details.
+	PIIMasked PIIVisibility = "masked"
+)
+
+// Project returns r as a caller with visibility v may see it. Anything but
+// PIIFull masks.
+func (r Registration) Project(v PIIVisibility) Registration {
+	if v == PIIFull {
+		return r
+	}
+	r.Name = MaskName(r.Name)
+	r.Email = MaskEmail(r.Email)
+	r.CheckInToken = ""
+	r.CancelledBy = ""
+	r.CancellationReason = ""
+	return r
+}
+
+// ProjectAll applies Project to every registration in rs, in place.
+func ProjectAll(rs []Registration, v PIIVisibility) []Registration {
+	if v == PIIFull {
+		return rs
+	}
+	for i := range rs {
+		rs[i] = rs[i].Project(v)
+	}
+	return rs
+}
+
+// Project returns h as a caller with visibility v may see it: masked
+// callers do not learn who cancelled or why.
+func (h HistoryEntry) Project(v PIIVisibility) HistoryEntry {
+	if v == PIIFull {
+		return h
+	}
+	h.CancelledBy = ""
+	h.CancellationReason = ""
+	return h
+}
+
+// MaskEmail keeps the first character of the local part and the domain.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return maskRest(email)
+	}
+	return maskRest(local) + "@" + domain
+}
+
+// MaskName keeps the first letter of the name.
+func MaskName(name string) string {
+	return maskRest(strings.TrimSpace(name))
+}
+
+func maskRest(s string) string {
+	if s == "" {
+		return ""
+	}
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size] + "***"
+}
@@ -4,20 +4,81 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
-	"github.com/google/uuid"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StatusConfirmed  = "confirmed"
+	StatusWaitlisted = "waitlisted"
+	StatusCancelled  = "cancelled"
+
+	// StatusNoShow is set when an event is finalized on confirmed
+	// registrations that never checked in.
+	StatusNoShow = "no_show"
+)
+
+// Cancellation.By values other than a user id.
+const (
+	// CancelledBySelf: the attendee cancelled, signed in or with the link
+	// from their confirmation.
+	CancelledBySelf = "self"
+	// CancelledBySystem: no person did, e.g. a cleanup job.
+	CancelledBySystem = "system"
 )
 
+// Cancellation is who cancelled a registration and why. By is the acting
+// user's id, CancelledBySelf or CancelledBySystem; Reason is optional.
+type Cancellation struct {
+	By     string
+	Reason string
+}
+
 type Registration struct {
-	ID           string     `json:"id"`
-	EventID      string     `json:"eventId"`
-	UserID       string     `json:"userId"`
-	Name         string     `json:"name"`
-	Email        string     `json:"email"`
-	CheckInToken string     `json:"checkInToken,omitempty"`
-	CheckedInAt  *time.Time `json:"checkedInAt,omitempty"`
-	CreatedAt    time.Time  `json:"createdAt"`
-	UpdatedAt    time.Time  `json:"updatedAt"`
+	ID               string     `json:"id"`
+	EventID          string     `json:"eventId"`
+	UserID           string     `json:"userId"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Status           string     `json:"status"`
+	Quantity         int        `json:"quantity"`
+	WaitlistPosition *int64     `json:"waitlistPosition,omitempty"`
+	CheckInToken     string     `json:"checkInToken,omitempty"`
+	CheckedInAt      *time.Time `json:"checkedInAt"`
+	CancelledAt      *time.Time `json:"cancelledAt,omitempty"`
+	// CancelledBy and CancellationReason are for organizers only; attendee
+	// listings never load them and masked projections drop them.
+	CancelledBy        string    `json:"cancelledBy,omitempty"`
+	CancellationReason string    `json:"cancellationReason,omitempty"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+func (r Registration) IsWaitlisted() bool {
+	return r.Status == StatusWaitlisted
+}
+
+func (r Registration) IsCancelled() bool {
+	return r.Status == StatusCancelled
+}
+
+// WithEvent is a registration plus the basics of its event, for listings
+// that span events.
+type WithEvent struct {
+	Registration
+	EventTitle   string    `json:"eventTitle"`
+	EventCity    string    `json:"eventCity,omitempty"`
+	EventStartAt time.Time `json:"eventStartAt"`
+}
+
+// ListFilter narrows an event's registration listing; nil fields are ignored.
+// Cancelled registrations are left out unless IncludeCancelled is set.
+type ListFilter struct {
+	CheckedIn        *bool
+	IncludeCancelled bool
 }
 
 // if you are already registered.
@@ -26,13 +87,66 @@ var ErrAlreadyRegistered = errors.New("registration already exists")
 // error if event is full
 var ErrEventFull = errors.New("event is full")
 var ErrNotFound = errors.New("registration not found")
+
+// error if the event already started (past the registration grace period)
+var ErrEventEnded = errors.New("event has already started")
 var ErrAlreadyCheckedIn = errors.New("registration already checked in")
+var ErrAlreadyCancelled = errors.New("registration already cancelled")
+
+// errors outside the event's registration window
+var ErrRegistrationNotOpen = errors.New("registration for this event has not opened yet")
+var ErrRegistrationClosed = errors.New("registration for this event has closed")
+
+// the caller's account email is not verified, so it cannot claim registrations
+var ErrEmailNotVerified = errors.New("account email is not verified")
+
+// errors for events that restrict who may register
+var ErrAuthRequired = errors.New("event requires an authenticated user")
+var ErrEmailDomainNotAllowed = errors.New("email domain is not allowed for this event")
+
+// the corrected email is the one the registration already has
+var ErrEmailUnchanged = errors.New("registration already has this email")
+
+// FullError is ErrEventFull for a request that needs more seats than are
+// left; Remaining says how many would still fit.
+type FullError struct {
+	Remaining int
+}
+
+func (e *FullError) Error() string        { return ErrEventFull.Error() }
+func (e *FullError) Is(target error) bool { return target == ErrEventFull }
+
+// QuantityLimitError rejects a request for more seats than the event allows
+// on a single registration.
+type QuantityLimitError struct {
+	Max int
+}
+
+func (e *QuantityLimitError) Error() string {
+	return fmt.Sprintf("quantity exceeds the per-registration limit of %d", e.Max)
+}
 
 type CreateRegistrationRequest struct {
 	EventID string `json:"-"`
 	UserID  string `json:"-"`
 	Name    string `json:"name" binding:"required,min=2,max=100"`
 	Email   string `json:"email" binding:"required,email,max=254"`
+
+	// email from the caller's access token; on restricted events it is
+	// registered instead of Email
+	AuthEmail string `json:"-"`
+
+	// seats to reserve (the registrant plus guests); 0 means 1. The event's
+	// maxQuantity caps it further.
+	Quantity int `json:"quantity" binding:"omitempty,min=1,max=50"`
+
+	// when the event is full, join the waitlist instead of failing with ErrEventFull
+	JoinWaitlist bool `json:"joinWaitlist"`
+}
+
+// ChangeEmailRequest corrects the address a registration's emails go to.
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"required,email,max=254"`
 }
 
 // A factory to build a Registration from the incoming DTO
@@ -44,13 +158,21 @@ func NewFromCreateRequest(req CreateRegistrationRequest) Registration {
 		EventID:      req.EventID,
 		UserID:       req.UserID, // added user id from access token into request field.
 		Name:         req.Name,
-		Email:        req.Email,
+		Email:        NormalizeEmail(req.Email),
+		Status:       StatusConfirmed,
+		Quantity:     max(req.Quantity, 1),
 		CheckInToken: newCheckInToken(),
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
 }
 
+// NormalizeEmail lowercases and trims an address so one inbox maps to one
+// registration per event, however it was typed.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func newCheckInToken() string {
 	// 18 random bytes -> 24-char base64url token (no padding), suitable for QR payload.
 	b := make([]byte, 18)
@@ -14,6 +14,31 @@ type CSVExport struct {
 	FileName    string
 	ContentType string
 	RowCount    int
+
+	// StoragePath locates the file in the export store; Data is only set for
+	// exports written before they were streamed to the store.
+	StoragePath string
 	Data        []byte
 	CreatedAt   time.Time
+
+	// KeepUntil, set by the requester, holds the export past the retention.
+	KeepUntil *time.Time
+}
+
+// DeleteAfter is when cleanup may remove the export: retention after it was
+// written, or KeepUntil if that is later.
+func (e CSVExport) DeleteAfter(retention time.Duration) time.Time {
+	at := e.CreatedAt.Add(retention)
+	if e.KeepUntil != nil && e.KeepUntil.After(at) {
+		return *e.KeepUntil
+	}
+	return at
+}
+
+// ListFilter narrows the admin export listing. DueBy keeps only exports whose
+// DeleteAfter is at or before it.
+type ListFilter struct {
+	EventID string
+	DueBy   *time.Time
+	Limit   int
 }
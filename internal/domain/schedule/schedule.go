@@ -0,0 +1,107 @@
+// Package schedule holds recurring jobs: a job type and payload enqueued on a
+// cron expression by the worker's scheduler.
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/validation"
+	"github.com/google/uuid"
+)
+
+var ErrNotFound = errors.New("schedule not found")
+
+// Schedule enqueues a Type job with Payload at every time Cron matches.
+// NextRunAt is the next occurrence still to be enqueued; a disabled schedule
+// keeps it but enqueues nothing.
+type Schedule struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	Cron      string          `json:"cron"`
+	NextRunAt time.Time       `json:"nextRunAt"`
+	LastRunAt *time.Time      `json:"lastRunAt,omitempty"`
+	Enabled   bool            `json:"enabled"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// IdempotencyKey is the key of the job enqueued for the occurrence at runAt,
+// so an occurrence is enqueued once however often it is looked at.
+func IdempotencyKey(id string, runAt time.Time) string {
+	return fmt.Sprintf("schedule:%s:%s", id, runAt.UTC().Format(time.RFC3339))
+}
+
+type CreateRequest struct {
+	Type    string          `json:"type" binding:"required,max=100"`
+	Payload json.RawMessage `json:"payload"`
+	Cron    string          `json:"cron" binding:"required,max=100"`
+	Enabled *bool           `json:"enabled"`
+}
+
+// UpdateRequest replaces the schedule's settings. The next run is worked out
+// again from the (possibly new) expression.
+type UpdateRequest struct {
+	Type    string          `json:"type" binding:"required,max=100"`
+	Payload json.RawMessage `json:"payload"`
+	Cron    string          `json:"cron" binding:"required,max=100"`
+	Enabled bool            `json:"enabled"`
+}
+
+// Validate checks the cron expression, which binding tags cannot parse.
+func (r CreateRequest) Validate() []validation.FieldError {
+	return cronErrors(r.Cron)
+}
+
+func (r UpdateRequest) Validate() []validation.FieldError {
+	return cronErrors(r.Cron)
+}
+
+func cronErrors(expr string) []validation.FieldError {
+	// a missing expression is already reported by its required tag
+	if strings.TrimSpace(expr) == "" {
+		return nil
+	}
+
+	c, err := ParseCron(expr)
+	if err != nil {
+		return []validation.FieldError{{Field: "cron", Rule: "cron", Message: err.Error()}}
+	}
+	if c.Next(time.Now()).IsZero() {
+		return []validation.FieldError{{Field: "cron", Rule: "cron", Message: "never matches"}}
+	}
+	return nil
+}
+
+// NormalizePayload stores a missing payload as an empty object.
+func NormalizePayload(p json.RawMessage) json.RawMessage {
+	if len(p) == 0 || string(p) == "null" {
+		return json.RawMessage(`{}`)
+	}
+	return p
+}
+
+// NewFromCreateRequest builds a schedule whose first run is the expression's
+// next match after now. req must have passed Validate.
+func NewFromCreateRequest(req CreateRequest, now time.Time) (Schedule, error) {
+	c, err := ParseCron(req.Cron)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	now = now.UTC()
+	return Schedule{
+		ID:        uuid.NewString(),
+		Type:      strings.TrimSpace(req.Type),
+		Payload:   NormalizePayload(req.Payload),
+		Cron:      strings.TrimSpace(req.Cron),
+		NextRunAt: c.Next(now),
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
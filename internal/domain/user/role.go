@@ -0,0 +1,38 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Role is what a user may do. Only the constants below are valid; the users
+// table enforces the same set.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// ErrUnknownRole: the role is not one of the Role constants.
+var ErrUnknownRole = errors.New("unknown role")
+
+// Valid reports whether r is one of the Role constants, exactly.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// ParseRole reads a role from config or a request. Case and surrounding
+// space are forgiven; anything else unknown wraps ErrUnknownRole.
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if !r.Valid() {
+		return "", fmt.Errorf("%w %q (want %q or %q)", ErrUnknownRole, s, RoleUser, RoleAdmin)
+	}
+	return r, nil
+}
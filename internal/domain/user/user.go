@@ -1,13 +1,19 @@
 package user
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrEmailTaken: another account already uses the email.
+var ErrEmailTaken = errors.New("email is already in use")
 
 type User struct {
 	ID           string    `json:"id"`
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"` // never expose hash in JSON
 	Name         string    `json:"name"`
-	Role         string    `json:"role"`
+	Role         Role      `json:"role"`
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// kinds of registration activity a webhook can subscribe to
+const (
+	KindRegistrationCreated   = "registration.created"
+	KindRegistrationCancelled = "registration.cancelled"
+)
+
+// Kinds lists every kind a webhook may subscribe to.
+var Kinds = []string{KindRegistrationCreated, KindRegistrationCancelled}
+
+// SignatureHeader carries Sign(secret, body) on every delivery.
+const SignatureHeader = "X-EventHub-Signature"
+
+// secretPrefix marks generated secrets so they are recognisable in logs and secret scanners.
+const secretPrefix = "whsec_"
+
+var ErrNotFound = errors.New("webhook not found")
+
+// Webhook is an outbound subscription. Secret signs every delivery; it is
+// returned once, when the webhook is created.
+type Webhook struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	Enabled    bool      `json:"enabled"`
+	EventKinds []string  `json:"eventKinds"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Subscribes reports whether the webhook wants deliveries of kind.
+func (w Webhook) Subscribes(kind string) bool {
+	return w.Enabled && slices.Contains(w.EventKinds, kind)
+}
+
+type CreateRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventKinds []string `json:"eventKinds" binding:"required,min=1,max=10,dive,oneof=registration.created registration.cancelled"`
+	// Secret is generated when omitted
+	Secret  string `json:"secret" binding:"omitempty,min=16,max=128"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// UpdateRequest replaces the webhook's settings; the secret is kept unless a new one is sent.
+type UpdateRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventKinds []string `json:"eventKinds" binding:"required,min=1,max=10,dive,oneof=registration.created registration.cancelled"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	Enabled    bool     `json:"enabled"`
+}
+
+// Delivery is one attempt at posting a payload to a webhook.
+type Delivery struct {
+	ID         int64     `json:"id"`
+	WebhookID  string    `json:"webhookId"`
+	JobID      string    `json:"jobId"`
+	Kind       string    `json:"kind"`
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"statusCode,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	DurationMS int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Succeeded reports whether the endpoint answered with a 2xx.
+func (d Delivery) Succeeded() bool {
+	return d.StatusCode != nil && *d.StatusCode >= 200 && *d.StatusCode < 300
+}
+
+// NewFromCreateRequest builds a webhook, generating its secret when none was given.
+func NewFromCreateRequest(req CreateRequest) (Webhook, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = GenerateSecret(); err != nil {
+			return Webhook{}, err
+		}
+	}
+
+	now := time.Now().UTC()
+	w := Webhook{
+		ID:         uuid.NewString(),
+		URL:        strings.TrimSpace(req.URL),
+		Secret:     secret,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		EventKinds: NormalizeKinds(req.EventKinds),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	return w, nil
+}
+
+// NormalizeKinds drops duplicates, keeping the order of Kinds.
+func NormalizeKinds(kinds []string) []string {
+	out := make([]string, 0, len(kinds))
+	for _, k := range Kinds {
+		if slices.Contains(kinds, k) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// GenerateSecret returns a random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the X-EventHub-Signature value for body: "sha256=" and the hex
+// HMAC-SHA256 of the body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RegistrationData is the data of a registration.* delivery.
+type RegistrationData struct {
+	RegistrationID string `json:"registrationId"`
+	EventID        string `json:"eventId"`
+	UserID         string `json:"userId,omitempty"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	Status         string `json:"status"`
+	Quantity       int    `json:"quantity"`
+}
+
+// Envelope is the JSON body posted to a webhook. ID is the same on every
+// retry, so receivers can drop duplicates.
+type Envelope struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
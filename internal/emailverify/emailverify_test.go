@@ -0,0 +1,48 @@
+package emailverify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	s := NewSigner("test-secret", time.Hour)
+	issued := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return issued }
+
+	token, exp := s.Sign("user-1", `"ada:lovelace"@example.com`)
+	if !exp.Equal(issued.Add(time.Hour)) {
+		t.Fatalf("unexpected expiry %s", exp)
+	}
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.Email != `"ada:lovelace"@example.com` || !claims.ExpiresAt.Equal(exp) {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyRejectsExpiredAndTampered(t *testing.T) {
+	s := NewSigner("test-secret", time.Hour)
+	issued := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return issued }
+
+	token, _ := s.Sign("user-1", "ada@example.com")
+	payload, sig, _ := strings.Cut(token, ".")
+	other, _ := NewSigner("other-secret", time.Hour).Sign("user-1", "ada@example.com")
+
+	for _, bad := range []string{"", payload, payload + ".!!!", other, payload + "." + sig[:len(sig)-2]} {
+		if _, err := s.Verify(bad); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("expected ErrInvalid for %q, got %v", bad, err)
+		}
+	}
+
+	s.now = func() time.Time { return issued.Add(2 * time.Hour) }
+	if _, err := s.Verify(token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+}
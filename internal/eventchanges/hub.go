@@ -0,0 +1,133 @@
+// Package eventchanges fans out "this event's seats changed" notifications to
+// in-process subscribers such as live availability streams.
+package eventchanges
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Channel is the Postgres NOTIFY channel the events table publishes changed
+// event ids on.
+const Channel = "event_changes"
+
+// ListenFunc blocks, calling notify with the id of every changed event, until
+// ctx is done or the underlying connection fails.
+type ListenFunc func(ctx context.Context, notify func(eventID string)) error
+
+// Hub delivers changes to subscribers of the affected event. It only listens
+// while someone is subscribed: the first subscriber starts the listener and
+// the last one to leave stops it.
+type Hub struct {
+	listen ListenFunc
+	retry  time.Duration
+
+	mu     sync.Mutex
+	subs   map[string]map[chan struct{}]struct{}
+	count  int
+	cancel context.CancelFunc
+}
+
+func NewHub(listen ListenFunc) *Hub {
+	return &Hub{
+		listen: listen,
+		retry:  2 * time.Second,
+		subs:   make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// WithRetry sets how long to wait before listening again after the
+// connection drops.
+func (h *Hub) WithRetry(d time.Duration) *Hub {
+	if d > 0 {
+		h.retry = d
+	}
+	return h
+}
+
+// Subscribe returns a channel that is signalled after each change to eventID.
+// Changes arriving before the last signal was read collapse into one. Call
+// the returned func to unsubscribe.
+func (h *Hub) Subscribe(eventID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs[eventID] == nil {
+		h.subs[eventID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[eventID][ch] = struct{}{}
+	h.count++
+	if h.count == 1 && h.listen != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		h.cancel = cancel
+		go h.run(ctx)
+	}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			delete(h.subs[eventID], ch)
+			if len(h.subs[eventID]) == 0 {
+				delete(h.subs, eventID)
+			}
+			h.count--
+			if h.count == 0 && h.cancel != nil {
+				h.cancel()
+				h.cancel = nil
+			}
+		})
+	}
+}
+
+// Publish signals eventID's subscribers.
+func (h *Hub) Publish(eventID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[eventID] {
+		signal(ch)
+	}
+}
+
+// publishAll signals every subscriber, so they re-read whatever they may have
+// missed while the listener was down.
+func (h *Hub) publishAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (h *Hub) run(ctx context.Context) {
+	for {
+		err := h.listen(ctx, h.Publish)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Default().Warn("eventchanges.listen_failed", "err", err, "retry_in", h.retry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.retry):
+		}
+		h.publishAll()
+	}
+}
@@ -0,0 +1,75 @@
+package eventchanges
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHub_ListensOnlyWhileSubscribed(t *testing.T) {
+	started := make(chan func(string), 1)
+	stopped := make(chan struct{}, 1)
+	hub := NewHub(func(ctx context.Context, notify func(string)) error {
+		started <- notify
+		<-ctx.Done()
+		stopped <- struct{}{}
+		return ctx.Err()
+	})
+
+	updates, unsubscribe := hub.Subscribe("evt-1")
+	other, unsubscribeOther := hub.Subscribe("evt-2")
+
+	var notify func(string)
+	select {
+	case notify = <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("listener not started by the first subscriber")
+	}
+
+	// changes pile up into one signal, and only for the event that changed
+	notify("evt-1")
+	notify("evt-1")
+	<-updates
+	select {
+	case <-updates:
+		t.Fatalf("expected repeated changes to collapse into one signal")
+	case <-other:
+		t.Fatalf("evt-2 subscriber signalled for evt-1")
+	default:
+	}
+
+	unsubscribe()
+	select {
+	case <-stopped:
+		t.Fatalf("listener stopped while evt-2 is still subscribed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unsubscribeOther()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("listener still running without subscribers")
+	}
+}
+
+func TestHub_WakesEveryoneAfterReconnect(t *testing.T) {
+	calls := make(chan struct{}, 2)
+	hub := NewHub(func(ctx context.Context, notify func(string)) error {
+		calls <- struct{}{}
+		if len(calls) == 1 {
+			return context.DeadlineExceeded // connection dropped
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}).WithRetry(10 * time.Millisecond)
+
+	updates, unsubscribe := hub.Subscribe("evt-1")
+	defer unsubscribe()
+
+	select {
+	case <-updates:
+	case <-time.After(time.Second):
+		t.Fatalf("expected a wake-up after the listener reconnected")
+	}
+}
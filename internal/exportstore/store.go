@@ -0,0 +1,101 @@
+// Package exportstore holds generated export artifacts (CSV files) outside the
+// database so large exports never have to fit in memory or in a single row.
+package exportstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNotFound = errors.New("export artifact not found")
+
+// Store writes an artifact once and reads it back by the location it returned.
+type Store interface {
+	// Create returns a writer for a new artifact. The artifact becomes visible
+	// only after Close succeeds; location is what Open expects later.
+	Create(ctx context.Context, name string) (w io.WriteCloser, location string, err error)
+	Open(ctx context.Context, location string) (io.ReadCloser, error)
+	// Delete removes an artifact; it returns ErrNotFound when there is none.
+	Delete(ctx context.Context, location string) error
+}
+
+// DirStore keeps artifacts as files in one directory shared by the API and worker.
+type DirStore struct {
+	dir string
+}
+
+func NewDirStore(dir string) (*DirStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, errors.New("exportstore: directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) Create(ctx context.Context, name string) (io.WriteCloser, string, error) {
+	name = filepath.Base(name)
+	if name == "." || name == string(filepath.Separator) {
+		return nil, "", errors.New("exportstore: invalid artifact name")
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+name+".*.tmp")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &atomicFile{File: tmp, final: filepath.Join(s.dir, name)}, name, nil
+}
+
+func (s *DirStore) Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	// locations are bare file names; never let one escape the directory
+	if location == "" || filepath.Base(location) != location {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, location))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *DirStore) Delete(ctx context.Context, location string) error {
+	if location == "" || filepath.Base(location) != location {
+		return ErrNotFound
+	}
+
+	err := os.Remove(filepath.Join(s.dir, location))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// atomicFile renames the temp file into place on Close so readers never see a
+// half-written export.
+type atomicFile struct {
+	*os.File
+	final string
+}
+
+func (f *atomicFile) Close() error {
+	if err := f.File.Sync(); err != nil {
+		_ = f.File.Close()
+		_ = os.Remove(f.File.Name())
+		return err
+	}
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(f.File.Name())
+		return err
+	}
+	return os.Rename(f.File.Name(), f.final)
+}
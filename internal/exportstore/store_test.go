@@ -0,0 +1,104 @@
+package exportstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirStore_WriteThenOpen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirStore(dir)
+	if err != nil {
+		t.Fatalf("NewDirStore: %v", err)
+	}
+
+	w, location, err := store.Create(context.Background(), "event_1.csv")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := io.WriteString(w, "id,name\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// nothing is visible until Close
+	if _, err := store.Open(context.Background(), location); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound before Close, got %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := store.Open(context.Background(), location)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(got) != "id,name\n" {
+		t.Fatalf("unexpected contents %q", got)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected only the final file to remain, got %d entries", len(entries))
+	}
+}
+
+func TestDirStore_OpenRejectsPathsOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirStore(filepath.Join(dir, "exports"))
+	if err != nil {
+		t.Fatalf("NewDirStore: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	if _, err := store.Open(context.Background(), "../secret.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for traversal, got %v", err)
+	}
+}
+
+func TestDirStore_DeleteReportsMissingArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirStore(filepath.Join(dir, "exports"))
+	if err != nil {
+		t.Fatalf("NewDirStore: %v", err)
+	}
+
+	w, location, err := store.Create(context.Background(), "event_1.csv")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if err := store.Delete(context.Background(), location); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(context.Background(), location); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := store.Delete(context.Background(), "../secret.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for traversal, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "secret.txt")); err != nil {
+		t.Fatalf("file outside the store was touched: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package funnel
+
+import (
+	"time"
+)
+
+// Stage is a step in the registration funnel for a single event.
+type Stage string
+
+const (
+	StageView    Stage = "view"
+	StageAttempt Stage = "attempt"
+	StageSuccess Stage = "success"
+	StageCancel  Stage = "cancel"
+)
+
+// Reason buckets the outcome of a stage. Only attempts carry a failure reason,
+// every other stage is recorded with ReasonOK.
+const (
+	ReasonOK                    = "ok"
+	ReasonInvalidRequest        = "invalid_request"
+	ReasonAlreadyRegistered     = "already_registered"
+	ReasonEventFull             = "event_full"
+	ReasonWaitlisted            = "waitlisted"
+	ReasonEventEnded            = "event_ended"
+	ReasonRegistrationNotOpen   = "registration_not_open"
+	ReasonRegistrationClosed    = "registration_closed"
+	ReasonAuthRequired          = "auth_required"
+	ReasonEmailDomainNotAllowed = "email_domain_not_allowed"
+	ReasonNotFound              = "not_found"
+	ReasonError                 = "error"
+)
+
+const DayLayout = "2006-01-02"
+
+// Increment is a pending counter delta for one (event, day, stage, reason) bucket.
+type Increment struct {
+	EventID string
+	Day     time.Time
+	Stage   Stage
+	Reason  string
+	Count   int64
+}
+
+// DailyCount is a persisted counter row read back from the store.
+type DailyCount struct {
+	Day    time.Time
+	Stage  Stage
+	Reason string
+	Count  int64
+}
+
+type DayStats struct {
+	Day             string           `json:"day"`
+	Views           int64            `json:"views"`
+	Attempts        int64            `json:"attempts"`
+	Successes       int64            `json:"successes"`
+	Cancellations   int64            `json:"cancellations"`
+	AttemptFailures map[string]int64 `json:"attemptFailures"`
+}
+
+type Conversion struct {
+	ViewToAttempt    float64 `json:"viewToAttempt"`
+	AttemptToSuccess float64 `json:"attemptToSuccess"`
+	ViewToSuccess    float64 `json:"viewToSuccess"`
+	CancellationRate float64 `json:"cancellationRate"`
+}
+
+type Report struct {
+	EventID    string     `json:"eventId"`
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	Days       []DayStats `json:"days"`
+	Totals     DayStats   `json:"totals"`
+	Conversion Conversion `json:"conversion"`
+}
+
+// BuildReport folds raw counter rows into a dense daily series (days without
+// activity are zero-filled) plus totals and conversion rates over the range.
+func BuildReport(eventID string, from, to time.Time, rows []DailyCount) Report {
+	from = truncateDay(from)
+	to = truncateDay(to)
+
+	byDay := make(map[string]*DayStats)
+	days := make([]DayStats, 0)
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, DayStats{Day: d.Format(DayLayout), AttemptFailures: map[string]int64{}})
+	}
+	for i := range days {
+		byDay[days[i].Day] = &days[i]
+	}
+
+	totals := DayStats{AttemptFailures: map[string]int64{}}
+
+	for _, row := range rows {
+		ds, ok := byDay[truncateDay(row.Day).Format(DayLayout)]
+		if !ok {
+			continue
+		}
+
+		apply(ds, row)
+		apply(&totals, row)
+	}
+
+	return Report{
+		EventID:    eventID,
+		From:       from.Format(DayLayout),
+		To:         to.Format(DayLayout),
+		Days:       days,
+		Totals:     totals,
+		Conversion: conversionFor(totals),
+	}
+}
+
+func apply(ds *DayStats, row DailyCount) {
+	switch row.Stage {
+	case StageView:
+		ds.Views += row.Count
+	case StageAttempt:
+		ds.Attempts += row.Count
+		if row.Reason != "" && row.Reason != ReasonOK {
+			ds.AttemptFailures[row.Reason] += row.Count
+		}
+	case StageSuccess:
+		ds.Successes += row.Count
+	case StageCancel:
+		ds.Cancellations += row.Count
+	}
+}
+
+func conversionFor(t DayStats) Conversion {
+	return Conversion{
+		ViewToAttempt:    Rate(t.Attempts, t.Views),
+		AttemptToSuccess: Rate(t.Successes, t.Attempts),
+		ViewToSuccess:    Rate(t.Successes, t.Views),
+		CancellationRate: Rate(t.Cancellations, t.Successes),
+	}
+}
+
+// Rate returns num/den rounded to 4 decimal places, or 0 when den is 0.
+func Rate(num, den int64) float64 {
+	if den <= 0 {
+		return 0
+	}
+
+	r := float64(num) / float64(den)
+	return float64(int64(r*10000+0.5)) / 10000
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
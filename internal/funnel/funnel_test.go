@@ -0,0 +1,134 @@
+package funnel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		num, den int64
+		want     float64
+	}{
+		{name: "zero denominator", num: 5, den: 0, want: 0},
+		{name: "negative denominator", num: 5, den: -2, want: 0},
+		{name: "zero numerator", num: 0, den: 4, want: 0},
+		{name: "whole ratio", num: 3, den: 4, want: 0.75},
+		{name: "rounds down", num: 1, den: 3, want: 0.3333},
+		{name: "rounds up", num: 2, den: 3, want: 0.6667},
+		{name: "above one", num: 3, den: 2, want: 1.5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Rate(tc.num, tc.den); got != tc.want {
+				t.Fatalf("Rate(%d, %d) = %v, want %v", tc.num, tc.den, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildReport_ZeroFillsEveryDayInRange(t *testing.T) {
+	from := time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	rows := []DailyCount{
+		{Day: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Stage: StageView, Reason: ReasonOK, Count: 4},
+		// outside the range on both sides, so ignored
+		{Day: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), Stage: StageView, Reason: ReasonOK, Count: 100},
+		{Day: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), Stage: StageSuccess, Reason: ReasonOK, Count: 100},
+	}
+
+	r := BuildReport("evt-1", from, to, rows)
+
+	if r.EventID != "evt-1" || r.From != "2026-03-01" || r.To != "2026-03-04" {
+		t.Fatalf("unexpected header: %+v", r)
+	}
+	wantDays := []string{"2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04"}
+	if len(r.Days) != len(wantDays) {
+		t.Fatalf("expected %d days, got %d", len(wantDays), len(r.Days))
+	}
+	for i, d := range r.Days {
+		if d.Day != wantDays[i] {
+			t.Fatalf("day %d: got %s, want %s", i, d.Day, wantDays[i])
+		}
+		if d.AttemptFailures == nil {
+			t.Fatalf("day %s: attemptFailures should be an empty map, not nil", d.Day)
+		}
+		wantViews := int64(0)
+		if d.Day == "2026-03-02" {
+			wantViews = 4
+		}
+		if d.Views != wantViews || d.Attempts != 0 || d.Successes != 0 || d.Cancellations != 0 {
+			t.Fatalf("day %s: unexpected counts %+v", d.Day, d)
+		}
+	}
+
+	if r.Totals.Views != 4 || r.Totals.Successes != 0 {
+		t.Fatalf("out-of-range rows leaked into totals: %+v", r.Totals)
+	}
+	// no attempts or successes, so every ratio with that denominator is 0
+	if r.Conversion.AttemptToSuccess != 0 || r.Conversion.CancellationRate != 0 {
+		t.Fatalf("expected zero ratios on empty denominators, got %+v", r.Conversion)
+	}
+}
+
+func TestBuildReport_EmptyRangeHasZeroConversion(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	r := BuildReport("evt-1", day, day, nil)
+
+	if len(r.Days) != 1 {
+		t.Fatalf("expected a single zero day, got %+v", r.Days)
+	}
+	if r.Conversion != (Conversion{}) {
+		t.Fatalf("expected all-zero conversion, got %+v", r.Conversion)
+	}
+}
+
+func TestBuildReport_TotalsAndConversion(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	rows := []DailyCount{
+		{Day: day1, Stage: StageView, Reason: ReasonOK, Count: 6},
+		{Day: day1, Stage: StageAttempt, Reason: ReasonOK, Count: 2},
+		{Day: day1, Stage: StageAttempt, Reason: ReasonEventFull, Count: 1},
+		{Day: day1, Stage: StageSuccess, Reason: ReasonOK, Count: 2},
+		{Day: day2, Stage: StageView, Reason: ReasonOK, Count: 4},
+		{Day: day2, Stage: StageAttempt, Reason: ReasonOK, Count: 1},
+		{Day: day2, Stage: StageAttempt, Reason: ReasonEventFull, Count: 2},
+		{Day: day2, Stage: StageAttempt, Reason: ReasonAlreadyRegistered, Count: 1},
+		{Day: day2, Stage: StageSuccess, Reason: ReasonOK, Count: 1},
+		{Day: day2, Stage: StageCancel, Reason: ReasonOK, Count: 1},
+	}
+
+	r := BuildReport("evt-1", day1, day2, rows)
+
+	if got := r.Days[0]; got.Views != 6 || got.Attempts != 3 || got.Successes != 2 || got.AttemptFailures[ReasonEventFull] != 1 {
+		t.Fatalf("unexpected day 1: %+v", got)
+	}
+	if got := r.Days[1]; got.Views != 4 || got.Attempts != 4 || got.Successes != 1 || got.Cancellations != 1 {
+		t.Fatalf("unexpected day 2: %+v", got)
+	}
+
+	tot := r.Totals
+	if tot.Views != 10 || tot.Attempts != 7 || tot.Successes != 3 || tot.Cancellations != 1 {
+		t.Fatalf("unexpected totals: %+v", tot)
+	}
+	if _, ok := tot.AttemptFailures[ReasonOK]; ok {
+		t.Fatalf("successful attempts must not be bucketed as failures: %+v", tot.AttemptFailures)
+	}
+	if tot.AttemptFailures[ReasonEventFull] != 3 || tot.AttemptFailures[ReasonAlreadyRegistered] != 1 {
+		t.Fatalf("unexpected failure buckets: %+v", tot.AttemptFailures)
+	}
+
+	want := Conversion{
+		ViewToAttempt:    0.7,
+		AttemptToSuccess: 0.4286,
+		ViewToSuccess:    0.3,
+		CancellationRate: 0.3333,
+	}
+	if r.Conversion != want {
+		t.Fatalf("got conversion %+v, want %+v", r.Conversion, want)
+	}
+}
@@ -0,0 +1,129 @@
+package funnel
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Store persists batched counter increments.
+type Store interface {
+	ApplyIncrements(ctx context.Context, incs []Increment) error
+}
+
+type bucketKey struct {
+	eventID string
+	day     string
+	stage   Stage
+	reason  string
+}
+
+// Recorder buffers funnel counters in memory and flushes them to the store in
+// batches, so the request path never waits on a DB write.
+type Recorder struct {
+	store    Store
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[bucketKey]int64
+	now     func() time.Time
+}
+
+func NewRecorder(store Store, interval time.Duration) *Recorder {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	return &Recorder{
+		store:    store,
+		interval: interval,
+		pending:  make(map[bucketKey]int64),
+		now:      time.Now,
+	}
+}
+
+// Record adds one to the bucket for today (UTC). Safe to call on a nil Recorder.
+func (r *Recorder) Record(eventID string, stage Stage, reason string) {
+	if r == nil || eventID == "" {
+		return
+	}
+	if reason == "" {
+		reason = ReasonOK
+	}
+
+	k := bucketKey{
+		eventID: eventID,
+		day:     r.now().UTC().Format(DayLayout),
+		stage:   stage,
+		reason:  reason,
+	}
+
+	r.mu.Lock()
+	r.pending[k]++
+	r.mu.Unlock()
+}
+
+// Flush writes everything buffered so far. On store failure the counts are
+// merged back so the next flush retries them.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[bucketKey]int64)
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	incs := make([]Increment, 0, len(batch))
+	for k, n := range batch {
+		day, err := time.Parse(DayLayout, k.day)
+		if err != nil {
+			continue
+		}
+		incs = append(incs, Increment{
+			EventID: k.eventID,
+			Day:     day,
+			Stage:   k.stage,
+			Reason:  k.reason,
+			Count:   n,
+		})
+	}
+
+	if err := r.store.ApplyIncrements(ctx, incs); err != nil {
+		r.mu.Lock()
+		for k, n := range batch {
+			r.pending[k] += n
+		}
+		r.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Run flushes on every interval until ctx is cancelled, then flushes once more.
+func (r *Recorder) Run(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			if err := r.Flush(fctx); err != nil {
+				slog.Default().Error("funnel.flush_failed", "err", err, "final", true)
+			}
+			cancel()
+			return
+
+		case <-t.C:
+			fctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			if err := r.Flush(fctx); err != nil {
+				slog.Default().Error("funnel.flush_failed", "err", err)
+			}
+			cancel()
+		}
+	}
+}
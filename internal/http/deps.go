@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/auth"
+	"github.com/geocoder89/eventhub/internal/cache"
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/eventchanges"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/funnel"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/queue/redisclient"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Each store below is everything the router asks of one repository, across
+// all the handlers and middlewares that share it.
+
+type EventsStore interface {
+	handlers.EventsCreator
+	handlers.EventBrandingStore
+	handlers.EventsBatchReader
+	handlers.EventAccessReader
+	middlewares.EventOrganizers
+}
+
+type RegistrationsStore interface {
+	handlers.RegistrationCreator
+	handlers.RegistrationHistoryReader
+	handlers.RegistrationEmailChanger
+	handlers.RegistrationImporter
+	handlers.RegistrationSearcher
+	handlers.MyRegistrationsRepo
+	handlers.RegistrantLookup
+}
+
+type UsersStore interface {
+	handlers.UserReader
+	handlers.UserWriter
+	handlers.EmailVerifier
+}
+
+type JobsStore interface {
+	handlers.JobsCreator
+	handlers.AccountExportJobs
+	handlers.EmailVerificationJobs
+	handlers.ExportJobsReader
+	handlers.ImportJobsCreator
+	handlers.AdminJobsRepo
+	handlers.AdminDeadLettersRepo
+	handlers.AdminQueueRepo
+	handlers.QueueStatsReader
+}
+
+type RegistrationExportsStore interface {
+	handlers.RegistrationCSVExportsReader
+	handlers.RegistrationCSVExportsLister
+}
+
+type FunnelStore interface {
+	handlers.FunnelReader
+	funnel.Store
+}
+
+type APIKeysStore interface {
+	handlers.APIKeysRepository
+	middlewares.APIKeyStore
+}
+
+type AttendanceStore interface {
+	handlers.AttendanceStatsReader
+	handlers.EventAttendanceReader
+}
+
+// Dependencies is what NewRouterWithDeps builds the API from. Nothing in it
+// is created by the router, so several routers can be built side by side and
+// tests can swap any repo for an in-memory one. A nil repo only breaks the
+// routes that use it; the other optional fields fall back as documented.
+type Dependencies struct {
+	Config config.Config
+	Logger *slog.Logger // nil means slog.Default()
+
+	// Registry backs /metrics and Metrics reports into it; a fresh pair is
+	// created when either is nil.
+	Registry *prometheus.Registry
+	Metrics  *observability.Prom
+
+	Events              EventsStore
+	Registrations       RegistrationsStore
+	Users               UsersStore
+	RefreshTokens       handlers.RefreshTokenStore
+	Jobs                JobsStore
+	RegistrationExports RegistrationExportsStore
+	IdempotentResponses handlers.IdempotentResponseStore
+	Funnel              FunnelStore // nil disables funnel recording
+	EventCounters       handlers.EventCountersRepository
+	PlatformStats       handlers.PlatformStatsReader
+	Attendance          AttendanceStore
+	APIKeys             APIKeysStore
+	Privacy             handlers.UserEraser
+	Webhooks            handlers.WebhooksRepository
+	Schedules           handlers.SchedulesRepository
+	JobStats            handlers.JobStatsReader
+	JobAttempts         handlers.JobAttemptsReader      // nil turns off attempt history (503)
+	AdminAudits         middlewares.AdminAuditWriter    // nil skips the audit trail
+	ServiceInstances    handlers.ServiceInstancesReader // nil leaves config drift out of /admin/diagnostics
+	PublishBatch        handlers.PublishBatchEvents     // nil fails batch publishes
+	Suppressions        handlers.SuppressionsRepository // nil turns off /admin/suppressions (503)
+	EditLocks           handlers.EditLockStore          // nil turns off edit locks (503) and their update check
+
+	Tokens      *auth.Manager
+	EventsCache *cache.Cache      // nil serves every list from the repo
+	ExportStore exportstore.Store // nil fails stored export downloads
+
+	// EventChanges feeds live availability streams; nil turns them off (503).
+	EventChanges eventchanges.ListenFunc
+
+	// ReadyCheck backs /readyz; nil always reports ready.
+	ReadyCheck func() error
+
+	// PoolStats feeds /admin/diagnostics; nil leaves the pool out.
+	PoolStats func() handlers.PoolStats
+
+	// RateLimiter builds each route's limiter; nil means
+	// middlewares.NewRateLimiter on Clock.
+	RateLimiter func(limit int, window time.Duration) *middlewares.RateLimiter
+	Clock       func() time.Time // nil means time.Now
+}
+
+// NewTokenManager is the one place the JWT manager is built from config, so
+// the router and tests mint tokens with the same TTLs.
+func NewTokenManager(cfg config.Config) *auth.Manager {
+	return auth.NewManager(
+		cfg.JWTSecret,
+		time.Duration(cfg.JWTAccessTTLMinutes)*time.Minute,
+		time.Duration(cfg.JWTRefreshTTLDays)*24*time.Hour,
+	)
+}
+
+// PostgresDependencies wires the production repos on pool, Redis for the
+// readiness check, and the JWT manager and events cache from cfg.
+func PostgresDependencies(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config, reg *prometheus.Registry, prom *observability.Prom) Dependencies {
+	redis := redisclient.New(redisclient.Config{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	readyCheck := func() error {
+		// postgres ping
+		if pool != nil {
+
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+			err := pool.Ping(ctx)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// Redis ping
+
+		{
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			err := redis.Ping(ctx)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// wire up repositories
+	agingInterval, agingMaxBoost := cfg.JobAging()
+	jobsRepo := postgres.NewJobsRepo(pool, prom).
+		WithAging(job.Aging{Interval: agingInterval, MaxBoost: agingMaxBoost}).
+		WithPayloadLimits(job.PayloadLimits{MaxBytes: cfg.JobPayloadMaxBytes, MaxDepth: cfg.JobPayloadMaxDepth})
+	usersRepo := postgres.NewUsersRepo(pool)
+	eventsRepo := postgres.NewEventsRepo(pool, prom)
+	eventCountersRepo := postgres.NewEventCountersRepo(pool, prom)
+
+	deps := Dependencies{
+		Config:   cfg,
+		Logger:   log,
+		Registry: reg,
+		Metrics:  prom,
+
+		Events: eventsRepo,
+		Registrations: postgres.NewRegistrationsRepo(pool, prom).
+			WithJobs(jobsRepo).
+			WithGracePeriod(time.Duration(cfg.RegistrationGraceMinutes) * time.Minute),
+		Users:               usersRepo,
+		RefreshTokens:       postgres.NewRefreshTokensRepo(pool),
+		Jobs:                jobsRepo,
+		RegistrationExports: postgres.NewRegistrationCSVExportsRepo(pool),
+		IdempotentResponses: postgres.NewIdempotentResponsesRepo(pool, prom),
+		Funnel:              postgres.NewEventFunnelRepo(pool, prom),
+		EventCounters:       eventCountersRepo,
+		PlatformStats:       eventCountersRepo,
+		Attendance:          postgres.NewAttendanceRepo(pool, prom),
+		APIKeys:             postgres.NewAPIKeysRepo(pool, prom),
+		Privacy:             postgres.NewPrivacyRepo(pool, prom),
+		Webhooks:            postgres.NewWebhooksRepo(pool, prom),
+		Schedules:           postgres.NewSchedulesRepo(pool, prom).WithJobs(jobsRepo),
+		JobStats:            postgres.NewJobStatsRepo(pool, prom),
+		JobAttempts:         postgres.NewJobAttemptsRepo(pool, prom),
+		AdminAudits:         postgres.NewAdminActionAuditsRepo(pool),
+		ServiceInstances:    postgres.NewServiceInstancesRepo(pool, prom),
+		PublishBatch:        eventsRepo,
+		Suppressions:        postgres.NewRecipientQuarantinesRepo(pool, prom),
+		EditLocks:           postgres.NewEditLocksRepo(pool, prom),
+
+		Tokens:       NewTokenManager(cfg),
+		EventsCache:  cache.New(10 * time.Second).WithMaxStale(cfg.EventsCacheMaxStale),
+		EventChanges: postgres.ListenEventChanges(pool),
+		ReadyCheck:   readyCheck,
+	}
+	if pool != nil {
+		deps.PoolStats = func() handlers.PoolStats {
+			st := pool.Stat()
+			return handlers.PoolStats{
+				MaxConns:        st.MaxConns(),
+				TotalConns:      st.TotalConns(),
+				IdleConns:       st.IdleConns(),
+				AcquiredConns:   st.AcquiredConns(),
+				AcquireCount:    st.AcquireCount(),
+				EmptyAcquires:   st.EmptyAcquireCount(),
+				CanceledAcquire: st.CanceledAcquireCount(),
+			}
+		}
+	}
+
+	exportStore, err := exportstore.NewDirStore(cfg.ExportsDir)
+	if err != nil {
+		// downloads of stored exports fail until this is fixed; the rest of the API is fine
+		log.Error("export store init failed", "dir", cfg.ExportsDir, "err", err)
+	} else {
+		deps.ExportStore = exportStore
+	}
+
+	return deps
+}
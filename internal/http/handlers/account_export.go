@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/exportlink"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountExportJobs interface {
+	Create(ctx context.Context, req job.CreateRequest) (job.Job, error)
+	GetByIdempotencyKey(ctx context.Context, key string) (job.Job, error)
+	GetByID(ctx context.Context, id string) (job.Job, error)
+}
+
+type AccountExportHandler struct {
+	jobs  AccountExportJobs
+	store exportstore.Store
+	links *exportlink.Signer
+}
+
+func NewAccountExportHandler(jobsRepo AccountExportJobs, store exportstore.Store, links *exportlink.Signer) *AccountExportHandler {
+	return &AccountExportHandler{jobs: jobsRepo, store: store, links: links}
+}
+
+// Request handles POST /me/export: enqueues an account.export of the caller's
+// profile, events, their registrations and the jobs they started. One export
+// per user per day; asking again the same day returns that job.
+func (h *AccountExportHandler) Request(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	raw, err := jobs.AccountExportPayload{
+		UserID:      userID,
+		RequestedAt: time.Now().UTC(),
+		RequestID:   requestIDFrom(ctx),
+	}.JSON()
+	if err != nil {
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+	key := "account:export:user:" + userID + ":day:" + time.Now().UTC().Format("2006-01-02")
+
+	alreadyEnqueued := false
+	j, err := h.jobs.Create(cctx, job.CreateRequest{
+		Type:           jobs.TypeAccountExport,
+		Payload:        raw,
+		RunAt:          time.Now().UTC(),
+		MaxAttempts:    5,
+		IdempotencyKey: &key,
+		UserID:         &userID,
+	})
+	if err != nil {
+		if !postgres.IsUniqueViolation(err) {
+			RespondInternal(ctx, "Could not enqueue job")
+			return
+		}
+
+		j, err = h.jobs.GetByIdempotencyKey(cctx, key)
+		if err != nil {
+			RespondInternal(ctx, "Could not enqueue job")
+			return
+		}
+		alreadyEnqueued = true
+	}
+
+	ctx.Set(middlewares.CtxJobID, j.ID)
+	slog.Default().InfoContext(cctx, "job.enqueue",
+		"request_id", requestIDFrom(ctx),
+		"job_id", j.ID,
+		"job_type", j.Type,
+		"already_enqueued", alreadyEnqueued,
+	)
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"jobId":           j.ID,
+		"status":          j.Status,
+		"type":            j.Type,
+		"alreadyEnqueued": alreadyEnqueued,
+	})
+}
+
+// Download handles GET /me/export/download?token=...: the signed link from the
+// completion email, so it works without a bearer token.
+func (h *AccountExportHandler) Download(ctx *gin.Context) {
+	token := strings.TrimSpace(ctx.Query("token"))
+	if token == "" {
+		RespondBadRequest(ctx, "invalid_request", "token is required")
+		return
+	}
+
+	if h.links == nil || h.store == nil {
+		RespondInternal(ctx, "Account export downloads not configured")
+		return
+	}
+
+	claims, err := h.links.Verify(token)
+	if err != nil {
+		if errors.Is(err, exportlink.ErrExpired) {
+			RespondUnAuthorized(ctx, "token_expired", "download link has expired")
+			return
+		}
+		RespondUnAuthorized(ctx, "invalid_token", "download link is invalid")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	j, err := h.jobs.GetByID(cctx, claims.JobID)
+	if err != nil {
+		if errors.Is(err, job.ErrJobNotFound) {
+			RespondNotFound(ctx, "Export not found")
+			return
+		}
+		RespondInternal(ctx, "Could not fetch export")
+		return
+	}
+
+	// a valid signature for some other job type or owner still unlocks nothing
+	if j.Type != jobs.TypeAccountExport || j.UserID == nil || *j.UserID != claims.UserID {
+		RespondNotFound(ctx, "Export not found")
+		return
+	}
+
+	var result jobs.AccountExportResult
+	if len(j.Result) > 0 {
+		if err := json.Unmarshal(j.Result, &result); err != nil {
+			RespondInternal(ctx, "Could not fetch export")
+			return
+		}
+	}
+	if result.Stage != "done" || result.Location == "" {
+		RespondNotFound(ctx, "Export not ready")
+		return
+	}
+
+	file, err := h.store.Open(ctx.Request.Context(), result.Location)
+	if err != nil {
+		if errors.Is(err, exportstore.ErrNotFound) {
+			RespondNotFound(ctx, "Export file not found")
+			return
+		}
+		RespondInternal(ctx, "Could not open export")
+		return
+	}
+	defer file.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, "application/zip", file, map[string]string{
+		"Content-Disposition": `attachment; filename="` + result.FileName + `"`,
+	})
+}
@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/exportlink"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+type accountExportJobsRepo struct {
+	publishJobsRepo
+	byID map[string]job.Job
+}
+
+func (r *accountExportJobsRepo) GetByID(ctx context.Context, id string) (job.Job, error) {
+	j, ok := r.byID[id]
+	if !ok {
+		return job.Job{}, job.ErrJobNotFound
+	}
+	return j, nil
+}
+
+func TestAccountExportRequest_OncePerDay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &accountExportJobsRepo{publishJobsRepo: publishJobsRepo{byKey: map[string]job.Job{}}}
+	h := handlers.NewAccountExportHandler(repo, nil, nil)
+
+	r := gin.New()
+	r.POST("/me/export", withUser(newUUID(), "user"), h.Request)
+
+	type enqueued struct {
+		JobID           string `json:"jobId"`
+		Type            string `json:"type"`
+		AlreadyEnqueued bool   `json:"alreadyEnqueued"`
+	}
+
+	var first enqueued
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/me/export", nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("request %d got %d body=%s", i, w.Code, w.Body.String())
+		}
+
+		var body enqueued
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if i == 0 {
+			first = body
+			continue
+		}
+		if body.JobID != first.JobID || !body.AlreadyEnqueued || first.AlreadyEnqueued {
+			t.Fatalf("expected the same job back on the second request, got first=%+v second=%+v", first, body)
+		}
+	}
+	if first.Type != jobs.TypeAccountExport || len(repo.byKey) != 1 {
+		t.Fatalf("expected one %s job, got type=%s jobs=%d", jobs.TypeAccountExport, first.Type, len(repo.byKey))
+	}
+}
+
+func TestAccountExportDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := exportstore.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	out, location, err := store.Create(context.Background(), "account.zip")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, _ = io.WriteString(out, "PK-archive")
+	if err := out.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	owner := newUUID()
+	result := func(stage string) json.RawMessage {
+		raw, _ := json.Marshal(jobs.AccountExportResult{Stage: stage, FileName: "account.zip", Location: location})
+		return raw
+	}
+	doneJob := job.Job{ID: newUUID(), Type: jobs.TypeAccountExport, UserID: &owner, Result: result("done")}
+	runningJob := job.Job{ID: newUUID(), Type: jobs.TypeAccountExport, UserID: &owner, Result: result("events")}
+	csvJob := job.Job{ID: newUUID(), Type: jobs.TypeRegistrationsExportCSV, UserID: &owner, Result: result("done")}
+
+	repo := &accountExportJobsRepo{byID: map[string]job.Job{
+		doneJob.ID:    doneJob,
+		runningJob.ID: runningJob,
+		csvJob.ID:     csvJob,
+	}}
+	links := exportlink.NewSigner("test-secret", time.Hour)
+	h := handlers.NewAccountExportHandler(repo, store, links)
+
+	r := gin.New()
+	r.GET("/me/export/download", h.Download)
+
+	sign := func(jobID, userID string) string {
+		token, _ := links.Sign(jobID, userID)
+		return token
+	}
+	foreign, _ := exportlink.NewSigner("other-secret", time.Hour).Sign(doneJob.ID, owner)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "ready", token: sign(doneJob.ID, owner), wantStatus: http.StatusOK},
+		{name: "missing token", token: "", wantStatus: http.StatusBadRequest},
+		{name: "bad signature", token: foreign, wantStatus: http.StatusUnauthorized},
+		{name: "someone else's job", token: sign(doneJob.ID, newUUID()), wantStatus: http.StatusNotFound},
+		{name: "not an account export", token: sign(csvJob.ID, owner), wantStatus: http.StatusNotFound},
+		{name: "still running", token: sign(runningJob.ID, owner), wantStatus: http.StatusNotFound},
+		{name: "unknown job", token: sign(newUUID(), owner), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me/export/download?token="+tc.token, nil))
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+				t.Fatalf("unexpected content type %q", ct)
+			}
+			if w.Body.String() != "PK-archive" {
+				t.Fatalf("unexpected body %q", w.Body.String())
+			}
+		})
+	}
+}
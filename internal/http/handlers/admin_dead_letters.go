@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminDeadLettersRepo interface {
+	ListDeadLettersCursor(
+		ctx context.Context,
+		limit int,
+		afterFailedAt time.Time,
+		afterID string,
+	) (items []job.DeadLetter, nextCursor *string, hasMore bool, err error)
+	GetDeadLetter(ctx context.Context, id string) (job.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id string) (job.Job, error)
+}
+
+type AdminDeadLettersHandler struct {
+	repo AdminDeadLettersRepo
+}
+
+func NewAdminDeadLettersHandler(repo AdminDeadLettersRepo) *AdminDeadLettersHandler {
+	return &AdminDeadLettersHandler{repo: repo}
+}
+
+// GET /admin/dead-letters?limit=20&cursor=...
+
+func (h *AdminDeadLettersHandler) List(ctx *gin.Context) {
+	limit := parseIntDefault(ctx.Query("limit"), 20)
+	if limit < 1 || limit > 100 {
+		RespondBadRequest(ctx, "invalid_query", "limit must be between 1 and 100")
+		return
+	}
+
+	// DESC first-page sentinel: "far future" + max UUID
+	afterFailedAt := time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	afterID := "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		cur, err := utils.DecodeDeadLetterCursor(cursor)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "cursor is invalid")
+			return
+		}
+		afterFailedAt = cur.FailedAt
+		afterID = cur.ID
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, next, hasMore, err := h.repo.ListDeadLettersCursor(cctx, limit, afterFailedAt, afterID)
+	if err != nil {
+		RespondInternal(ctx, "Could not list dead letters")
+		return
+	}
+
+	RespondJSONWithETag(ctx, http.StatusOK, BuildCursorPageResponse(limit, items, hasMore, next, nil))
+}
+
+// GET /admin/dead-letters/:id
+
+func (h *AdminDeadLettersHandler) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !utils.IsUUID(id) {
+		RespondBadRequest(ctx, "invalid_request", "invalid_id")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	d, err := h.repo.GetDeadLetter(cctx, id)
+	if err != nil {
+		if errors.Is(err, job.ErrDeadLetterNotFound) {
+			RespondNotFound(ctx, "Dead letter not found")
+			return
+		}
+		RespondInternal(ctx, "Could not fetch dead letter")
+		return
+	}
+
+	ctx.Set(middlewares.CtxJobID, d.JobID)
+	RespondJSONWithETag(ctx, http.StatusOK, d)
+}
+
+// POST /admin/dead-letters/:id/replay
+//
+// Enqueues a fresh job from the dead letter; a dead letter is replayed once.
+func (h *AdminDeadLettersHandler) Replay(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !utils.IsUUID(id) {
+		RespondBadRequest(ctx, "invalid_request", "invalid_id")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	j, err := h.repo.ReplayDeadLetter(cctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, job.ErrDeadLetterNotFound):
+			RespondNotFound(ctx, "Dead letter not found")
+		case errors.Is(err, job.ErrDeadLetterReplayed):
+			RespondConflict(ctx, "dead_letter_replayed", "Dead letter was already replayed")
+		default:
+			RespondInternal(ctx, "Could not replay dead letter")
+		}
+		return
+	}
+
+	ctx.Set(middlewares.CtxJobID, j.ID)
+	ctx.JSON(http.StatusCreated, gin.H{
+		"deadLetterId": id,
+		"jobId":        j.ID,
+		"status":       j.Status,
+	})
+}
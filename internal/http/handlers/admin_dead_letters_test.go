@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeDeadLettersRepo struct {
+	listFn   func(ctx context.Context, limit int, afterFailedAt time.Time, afterID string) ([]job.DeadLetter, *string, bool, error)
+	replayFn func(ctx context.Context, id string) (job.Job, error)
+}
+
+func (f *fakeDeadLettersRepo) ListDeadLettersCursor(ctx context.Context, limit int, afterFailedAt time.Time, afterID string) ([]job.DeadLetter, *string, bool, error) {
+	if f.listFn != nil {
+		return f.listFn(ctx, limit, afterFailedAt, afterID)
+	}
+	return nil, nil, false, nil
+}
+
+func (f *fakeDeadLettersRepo) GetDeadLetter(ctx context.Context, id string) (job.DeadLetter, error) {
+	return job.DeadLetter{}, job.ErrDeadLetterNotFound
+}
+
+func (f *fakeDeadLettersRepo) ReplayDeadLetter(ctx context.Context, id string) (job.Job, error) {
+	if f.replayFn != nil {
+		return f.replayFn(ctx, id)
+	}
+	return job.Job{}, nil
+}
+
+func TestAdminDeadLettersList_Cursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	failedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	afterID := newUUID()
+	cursor, err := utils.EncodeDeadLetterCursor(failedAt, afterID)
+	if err != nil {
+		t.Fatalf("encode cursor: %v", err)
+	}
+
+	repo := &fakeDeadLettersRepo{
+		listFn: func(ctx context.Context, limit int, gotFailedAt time.Time, gotID string) ([]job.DeadLetter, *string, bool, error) {
+			if limit != 5 || !gotFailedAt.Equal(failedAt) || gotID != afterID {
+				t.Fatalf("unexpected page args: limit=%d failedAt=%s id=%s", limit, gotFailedAt, gotID)
+			}
+			return []job.DeadLetter{}, nil, false, nil
+		},
+	}
+	r := gin.New()
+	r.GET("/admin/dead-letters", handlers.NewAdminDeadLettersHandler(repo).List)
+
+	for target, want := range map[string]int{
+		"/admin/dead-letters?limit=5&cursor=" + cursor: http.StatusOK,
+		"/admin/dead-letters?cursor=not-a-cursor":      http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != want {
+			t.Fatalf("%s: got status %d, want %d, body=%s", target, w.Code, want, w.Body.String())
+		}
+	}
+}
+
+func TestAdminDeadLettersReplay_Statuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name string
+		id   string
+		err  error
+		want int
+	}{
+		{"replayed", newUUID(), nil, http.StatusCreated},
+		{"already replayed", newUUID(), job.ErrDeadLetterReplayed, http.StatusConflict},
+		{"unknown", newUUID(), job.ErrDeadLetterNotFound, http.StatusNotFound},
+		{"bad id", "nope", nil, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeDeadLettersRepo{
+				replayFn: func(ctx context.Context, id string) (job.Job, error) {
+					if tc.err != nil {
+						return job.Job{}, tc.err
+					}
+					return job.Job{ID: newUUID(), Status: job.StatusPending}, nil
+				},
+			}
+			r := gin.New()
+			r.POST("/admin/dead-letters/:id/replay", handlers.NewAdminDeadLettersHandler(repo).Replay)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dead-letters/"+tc.id+"/replay", nil))
+			if w.Code != tc.want {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+// EnqueueJobRequest is the body of POST /admin/jobs. Zero MaxAttempts takes
+// job.DefaultMaxAttempts; a missing RunAt means now.
+type EnqueueJobRequest struct {
+	Type           string          `json:"type" binding:"required"`
+	Payload        json.RawMessage `json:"payload" binding:"required"`
+	RunAt          *time.Time      `json:"runAt"`
+	MaxAttempts    int             `json:"maxAttempts" binding:"omitempty,min=1,max=100"`
+	Priority       int             `json:"priority" binding:"omitempty,min=-100,max=100"`
+	IdempotencyKey *string         `json:"idempotencyKey" binding:"omitempty,min=1,max=200"`
+}
+
+// EnqueueJob handles POST /admin/jobs: any job type a worker runs, for ops
+// and debugging. The payload goes through the jobs codec (strict decode, then
+// the type's field rules) and is stored canonicalized. With an idempotency
+// key a duplicate behaves like a duplicate publish: 202 alreadyEnqueued when
+// it matches, 409 idempotency_key_reuse when it asks for something else.
+func (h *JobsHandler) EnqueueJob(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	var req EnqueueJobRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	// the codec's legacy types are valid too, but no worker would run them
+	jobType := jobs.JobType(req.Type)
+	if !jobType.IsWorkerType() {
+		names := make([]string, 0, len(jobs.WorkerTypes()))
+		for _, t := range jobs.WorkerTypes() {
+			names = append(names, string(t))
+		}
+		RespondBadRequest(ctx, "Invalid request body", gin.H{"fields": []FieldError{{
+			Field:   "type",
+			Rule:    "oneof",
+			Param:   strings.Join(names, " "),
+			Message: "must be one of " + strings.Join(names, ", "),
+		}}})
+		return
+	}
+
+	payload, fields := decodeJobPayload(jobType, req.Payload)
+	if len(fields) > 0 {
+		RespondBadRequest(ctx, "Invalid request body", gin.H{"fields": fields})
+		return
+	}
+
+	runAt := time.Now().UTC()
+	if req.RunAt != nil {
+		// same clock drift allowance as a publish
+		if req.RunAt.Before(time.Now().UTC().Add(-30 * time.Second)) {
+			RespondBadRequest(ctx, "Invalid request body", gin.H{"fields": []FieldError{{
+				Field: "runAt", Rule: "future", Message: "must be now or in the future",
+			}}})
+			return
+		}
+		runAt = req.RunAt.UTC()
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	class := EnqueueStandard
+	if runAt.After(time.Now().UTC().Add(farFuturePublish)) {
+		class = EnqueueDeferrable
+	}
+	if !h.guard.Admit(ctx, cctx, class, req.Type) {
+		return
+	}
+
+	j, err := h.jobs.Create(cctx, job.CreateRequest{
+		Type:           req.Type,
+		Payload:        payload,
+		RunAt:          runAt,
+		MaxAttempts:    req.MaxAttempts,
+		Priority:       req.Priority,
+		IdempotencyKey: req.IdempotencyKey,
+		UserID:         &userID,
+	})
+	if err != nil {
+		if respondPayloadTooLarge(ctx, err) {
+			return
+		}
+		if req.IdempotencyKey == nil || !postgres.IsUniqueViolation(err) {
+			RespondInternal(ctx, "Could not enqueue job")
+			return
+		}
+
+		key := *req.IdempotencyKey
+		existing, gerr := h.jobs.GetByIdempotencyKey(cctx, key)
+		if gerr != nil {
+			RespondInternal(ctx, "Could not enqueue job")
+			return
+		}
+
+		diff, derr := enqueueRequestDiff(existing, req, payload, runAt)
+		if derr != nil {
+			RespondInternal(ctx, "Could not enqueue job")
+			return
+		}
+		if len(diff) > 0 {
+			RespondError(ctx, http.StatusConflict, "idempotency_key_reuse",
+				"A job with this idempotency key already exists and does not match this request.",
+				gin.H{"idempotencyKey": key, "jobId": existing.ID, "fields": diff})
+			return
+		}
+
+		h.respondAlreadyEnqueued(ctx, cctx, key, existing)
+		return
+	}
+
+	h.respondEnqueued(ctx, cctx, req.IdempotencyKey, j)
+}
+
+// decodeJobPayload runs raw through the codec for t and returns it
+// canonicalized, or the problems found, with fields named payload.<field>.
+func decodeJobPayload(t jobs.JobType, raw json.RawMessage) (json.RawMessage, []FieldError) {
+	decoded, err := jobs.DecodePayload(jobs.Job{Type: t, Payload: raw})
+	if err == nil {
+		err = jobs.ValidatePayload(t, decoded)
+	}
+	if err != nil {
+		var perr *jobs.PayloadError
+		if !errors.As(err, &perr) {
+			return nil, []FieldError{{Field: "payload", Rule: "required", Message: "is required"}}
+		}
+		fields := make([]FieldError, 0, len(perr.Fields))
+		for _, f := range perr.Fields {
+			f.Field = strings.TrimSuffix("payload."+f.Field, ".")
+			fields = append(fields, f)
+		}
+		return nil, fields
+	}
+
+	canonical, err := jobs.CanonicalJSON(raw)
+	if err != nil {
+		return nil, []FieldError{{Field: "payload", Rule: "json", Message: err.Error()}}
+	}
+	return canonical, nil
+}
+
+// enqueueRequestDiff names what req asks for differently from existing, the
+// job already holding its idempotency key. Optional fields left out of req
+// are not compared; runAt only while existing is untried, as for a publish.
+func enqueueRequestDiff(existing job.Job, req EnqueueJobRequest, payload json.RawMessage, runAt time.Time) ([]string, error) {
+	var fields []string
+	if existing.Type != req.Type {
+		fields = append(fields, "type")
+	}
+
+	payloadFields, err := jobs.PayloadDiff(existing.Payload, payload)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range payloadFields {
+		fields = append(fields, strings.TrimSuffix("payload."+f, "."))
+	}
+
+	untried := existing.Status == job.StatusPending && existing.Attempts == 0
+	if req.RunAt != nil && untried && existing.RunAt.Sub(runAt).Abs() > time.Millisecond {
+		fields = append(fields, "runAt")
+	}
+	if req.MaxAttempts != 0 && existing.MaxAttempts != req.MaxAttempts {
+		fields = append(fields, "maxAttempts")
+	}
+	if req.Priority != 0 && existing.Priority != req.Priority {
+		fields = append(fields, "priority")
+	}
+	return fields, nil
+}
@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/idempotency"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type enqueueResponse struct {
+	JobID           string `json:"jobId"`
+	Type            string `json:"type"`
+	AlreadyEnqueued bool   `json:"alreadyEnqueued"`
+	Error           struct {
+		Code    string `json:"code"`
+		Details struct {
+			Fields json.RawMessage `json:"fields"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func doEnqueue(t *testing.T, r http.Handler, body string) (*httptest.ResponseRecorder, enqueueResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/admin/jobs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp enqueueResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func newEnqueueRouter(repo *publishJobsRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	store := &memoryResponseStore{saved: map[string]idempotency.Response{}}
+	r := gin.New()
+	r.POST("/admin/jobs", withUser(newUUID(), "admin"), handlers.NewJobsHandler(repo, nil).WithResponseStore(store).EnqueueJob)
+	return r
+}
+
+func TestEnqueueJob_CreatesACanonicalJob(t *testing.T) {
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	eventID := newUUID()
+
+	w, resp := doEnqueue(t, newEnqueueRouter(repo),
+		`{"type":"event.publish","payload":{ "requestId": "r-1",  "eventId": "`+eventID+`" },"maxAttempts":3,"priority":5}`)
+	if w.Code != http.StatusAccepted || resp.Type != "event.publish" || resp.JobID == "" {
+		t.Fatalf("expected 202 with the job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	got := repo.byKey[resp.JobID]
+	if want := `{"eventId":"` + eventID + `","requestId":"r-1"}`; string(got.Payload) != want {
+		t.Fatalf("stored payload = %s, want %s", got.Payload, want)
+	}
+	if got.MaxAttempts != 3 || got.Priority != 5 {
+		t.Fatalf("expected maxAttempts 3 and priority 5, got %+v", got)
+	}
+}
+
+func TestEnqueueJob_RejectsWithFieldDetails(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+	}{
+		{name: "unknown type", body: `{"type":"test.crash","payload":{}}`, fields: []string{"type"}},
+		{name: "legacy codec type", body: `{"type":"publish_event","payload":{"eventId":"e-1"}}`, fields: []string{"type"}},
+		{name: "missing fields", body: `{"type":"registration.confirmation","payload":{"eventId":"nope"}}`,
+			fields: []string{"payload.registrationId", "payload.eventId", "payload.email"}},
+		{name: "unknown payload field", body: `{"type":"exports.cleanup","payload":{"batchSize":10,"dryRun":true}}`, fields: []string{"payload.dryRun"}},
+		{name: "wrong payload type", body: `{"type":"exports.cleanup","payload":{"batchSize":"ten"}}`, fields: []string{"payload.batchSize"}},
+		{name: "past run at", body: `{"type":"exports.cleanup","payload":{},"runAt":"2020-01-01T00:00:00Z"}`, fields: []string{"runAt"}},
+		{name: "max attempts out of range", body: `{"type":"exports.cleanup","payload":{},"maxAttempts":1000}`, fields: []string{"maxAttempts"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+			w, resp := doEnqueue(t, newEnqueueRouter(repo), tc.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var fields []handlers.FieldError
+			if err := json.Unmarshal(resp.Error.Details.Fields, &fields); err != nil {
+				t.Fatalf("decode fields: %v in %s", err, w.Body.String())
+			}
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Field)
+			}
+			if !reflect.DeepEqual(names, tc.fields) {
+				t.Fatalf("fields = %v, want %v", names, tc.fields)
+			}
+			if len(repo.byKey) != 0 {
+				t.Fatalf("expected nothing enqueued")
+			}
+		})
+	}
+}
+
+func TestEnqueueJob_IdempotencyKeyLikePublish(t *testing.T) {
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	r := newEnqueueRouter(repo)
+
+	body := `{"type":"jobs.purge","payload":{"olderThanDays":7},"idempotencyKey":"ops:purge:1"}`
+	w, first := doEnqueue(t, r, body)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// same request, keys reordered: the original job back
+	w, again := doEnqueue(t, r, `{"idempotencyKey":"ops:purge:1","payload":{ "olderThanDays": 7 },"type":"jobs.purge"}`)
+	if w.Code != http.StatusAccepted || again.JobID != first.JobID || !again.AlreadyEnqueued {
+		t.Fatalf("expected the original job replayed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w, conflict := doEnqueue(t, r, `{"type":"jobs.purge","payload":{"olderThanDays":1},"idempotencyKey":"ops:purge:1"}`)
+	if w.Code != http.StatusConflict || conflict.Error.Code != "idempotency_key_reuse" {
+		t.Fatalf("expected 409 idempotency_key_reuse, got %d: %s", w.Code, w.Body.String())
+	}
+	if string(conflict.Error.Details.Fields) != `["payload.olderThanDays"]` {
+		t.Fatalf("unexpected conflict fields %s", conflict.Error.Details.Fields)
+	}
+	if len(repo.byKey) != 1 {
+		t.Fatalf("expected one job, got %d", len(repo.byKey))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,10 +17,13 @@ import (
 
 type fakeAdminJobsRepo struct {
 	listCursorFn      func(ctx context.Context, status *string, limit int, afterUpdatedAt time.Time, afterID string) ([]job.Job, *string, bool, error)
+	listScheduledFn   func(ctx context.Context, filter job.ScheduledFilter, limit int, afterRunAt time.Time, afterID string) ([]job.Job, *string, bool, error)
 	countFn           func(ctx context.Context, status *string) (int, error)
 	getByIDFn         func(ctx context.Context, id string) (job.Job, error)
-	retryFn           func(ctx context.Context, id string) error
+	retryFn           func(ctx context.Context, id string, priority *int) error
+	cancelFn          func(ctx context.Context, id string) (job.Status, error)
 	retryManyFailedFn func(ctx context.Context, limit int) (int64, error)
+	purgeTerminalFn   func(ctx context.Context, olderThan time.Duration, statuses []string, limit int) (int64, error)
 }
 
 func (f *fakeAdminJobsRepo) ListCursor(ctx context.Context, status *string, limit int, afterUpdatedAt time.Time, afterID string) ([]job.Job, *string, bool, error) {
@@ -29,6 +33,13 @@ func (f *fakeAdminJobsRepo) ListCursor(ctx context.Context, status *string, limi
 	return nil, nil, false, nil
 }
 
+func (f *fakeAdminJobsRepo) ListScheduledCursor(ctx context.Context, filter job.ScheduledFilter, limit int, afterRunAt time.Time, afterID string) ([]job.Job, *string, bool, error) {
+	if f.listScheduledFn != nil {
+		return f.listScheduledFn(ctx, filter, limit, afterRunAt, afterID)
+	}
+	return nil, nil, false, nil
+}
+
 func (f *fakeAdminJobsRepo) Count(ctx context.Context, status *string) (int, error) {
 	if f.countFn != nil {
 		return f.countFn(ctx, status)
@@ -43,13 +54,20 @@ func (f *fakeAdminJobsRepo) GetByID(ctx context.Context, id string) (job.Job, er
 	return job.Job{}, nil
 }
 
-func (f *fakeAdminJobsRepo) Retry(ctx context.Context, id string) error {
+func (f *fakeAdminJobsRepo) Retry(ctx context.Context, id string, priority *int) error {
 	if f.retryFn != nil {
-		return f.retryFn(ctx, id)
+		return f.retryFn(ctx, id, priority)
 	}
 	return nil
 }
 
+func (f *fakeAdminJobsRepo) Cancel(ctx context.Context, id string) (job.Status, error) {
+	if f.cancelFn != nil {
+		return f.cancelFn(ctx, id)
+	}
+	return job.StatusCancelled, nil
+}
+
 func (f *fakeAdminJobsRepo) RetryManyFailed(ctx context.Context, limit int) (int64, error) {
 	if f.retryManyFailedFn != nil {
 		return f.retryManyFailedFn(ctx, limit)
@@ -57,6 +75,13 @@ func (f *fakeAdminJobsRepo) RetryManyFailed(ctx context.Context, limit int) (int
 	return 0, nil
 }
 
+func (f *fakeAdminJobsRepo) PurgeTerminal(ctx context.Context, olderThan time.Duration, statuses []string, limit int) (int64, error) {
+	if f.purgeTerminalFn != nil {
+		return f.purgeTerminalFn(ctx, olderThan, statuses, limit)
+	}
+	return 0, nil
+}
+
 func TestAdminJobsList_IncludeTotal(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -297,3 +322,453 @@ func TestAdminJobsGetByID_ETagNotModified(t *testing.T) {
 		t.Fatalf("expected repo get calls=2, got %d", getCalls)
 	}
 }
+
+func TestAdminJobsReprocessDead_BulkContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		query       string
+		wantLimit   int
+		wantResult  handlers.BulkResult
+		wantClamped string
+	}{
+		{"default limit", "", 5, handlers.BulkResult{Requested: 5, Applied: 5, Affected: 3}, ""},
+		{"within cap", "?limit=8", 8, handlers.BulkResult{Requested: 8, Applied: 8, Affected: 3}, ""},
+		{"over cap", "?limit=50", 10, handlers.BulkResult{Requested: 50, Applied: 10, Affected: 3, Clamped: true}, "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLimit := 0
+			repo := &fakeAdminJobsRepo{retryManyFailedFn: func(ctx context.Context, limit int) (int64, error) {
+				gotLimit = limit
+				return 3, nil
+			}}
+			h := handlers.NewAdminJobsHandler(repo).WithBulkLimits(handlers.BulkLimits{Default: 5, Max: 10})
+			r := gin.New()
+			r.POST("/admin/jobs/reprocess-dead", h.ReprocessDead)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/jobs/reprocess-dead"+tt.query, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, body=%s", w.Code, w.Body.String())
+			}
+			if gotLimit != tt.wantLimit {
+				t.Fatalf("repo limit = %d, want %d", gotLimit, tt.wantLimit)
+			}
+			var got handlers.BulkResult
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got != tt.wantResult {
+				t.Fatalf("result = %+v, want %+v", got, tt.wantResult)
+			}
+			if h := w.Header().Get(handlers.HeaderBulkClamped); h != tt.wantClamped {
+				t.Fatalf("%s = %q, want %q", handlers.HeaderBulkClamped, h, tt.wantClamped)
+			}
+		})
+	}
+}
+
+func TestAdminJobsReprocessDead_RejectsBadLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, q := range []string{"?limit=abc", "?limit=0", "?limit=-5"} {
+		repo := &fakeAdminJobsRepo{retryManyFailedFn: func(ctx context.Context, limit int) (int64, error) {
+			t.Fatalf("repo should not be called for %s", q)
+			return 0, nil
+		}}
+		r := gin.New()
+		r.POST("/admin/jobs/reprocess-dead", handlers.NewAdminJobsHandler(repo).ReprocessDead)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/jobs/reprocess-dead"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: got status %d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestAdminJobsCancel_StatusCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name     string
+		status   job.Status
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{"pending", job.StatusCancelled, nil, http.StatusOK, `"status":"cancelled"`},
+		{"processing", job.StatusProcessing, nil, http.StatusAccepted, `"cancellationRequested":true`},
+		{"finished", job.StatusDone, job.ErrJobNotCancellable, http.StatusConflict, "job_not_cancellable"},
+		{"missing", "", job.ErrJobNotFound, http.StatusNotFound, ""},
+		{"db down", "", errors.New("boom"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id := newUUID()
+			repo := &fakeAdminJobsRepo{
+				cancelFn: func(ctx context.Context, gotID string) (job.Status, error) {
+					if gotID != id {
+						t.Fatalf("expected id %s, got %s", id, gotID)
+					}
+					return tc.status, tc.err
+				},
+			}
+			r := gin.New()
+			r.POST("/admin/jobs/:id/cancel", handlers.NewAdminJobsHandler(repo).Cancel)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/jobs/"+id+"/cancel", nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d body=%s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantBody != "" && !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Fatalf("expected body to contain %s, got %s", tc.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminJobsList_RejectsUnknownStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got *string
+	repo := &fakeAdminJobsRepo{
+		listCursorFn: func(ctx context.Context, status *string, limit int, afterUpdatedAt time.Time, afterID string) ([]job.Job, *string, bool, error) {
+			got = status
+			return []job.Job{}, nil, false, nil
+		},
+	}
+	r := gin.New()
+	r.GET("/admin/jobs", handlers.NewAdminJobsHandler(repo).List)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs?status=cancelled", nil))
+	if w.Code != http.StatusOK || got == nil || *got != "cancelled" {
+		t.Fatalf("expected status=cancelled to reach the repo, got code=%d status=%v", w.Code, got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs?status=dead", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown status, got %d", w.Code)
+	}
+}
+
+type fakeJobAttemptsReader struct {
+	items []job.Attempt
+}
+
+func (f *fakeJobAttemptsReader) ListByJob(ctx context.Context, jobID string) ([]job.Attempt, error) {
+	return f.items, nil
+}
+
+func TestAdminJobsAttempts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	errMsg := "smtp down"
+	reader := &fakeJobAttemptsReader{items: []job.Attempt{
+		{Attempt: 1, WorkerID: "w-1", Outcome: job.AttemptRetried, Error: &errMsg, DurationMs: 40},
+		{Attempt: 2, WorkerID: "w-2", Outcome: job.AttemptDone, DurationMs: 12},
+	}}
+
+	cases := []struct {
+		name     string
+		id       string
+		getErr   error
+		reader   handlers.JobAttemptsReader
+		wantCode int
+		wantBody string
+	}{
+		{"history", newUUID(), nil, reader, http.StatusOK, `"outcome":"retried"`},
+		{"bad id", "nope", nil, reader, http.StatusBadRequest, ""},
+		{"missing job", newUUID(), job.ErrJobNotFound, reader, http.StatusNotFound, ""},
+		{"history off", newUUID(), nil, nil, http.StatusServiceUnavailable, "attempts_unavailable"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeAdminJobsRepo{getByIDFn: func(ctx context.Context, id string) (job.Job, error) {
+				return job.Job{ID: id}, tc.getErr
+			}}
+			h := handlers.NewAdminJobsHandler(repo)
+			if tc.reader != nil {
+				h.WithAttempts(tc.reader)
+			}
+			r := gin.New()
+			r.GET("/admin/jobs/:id/attempts", h.Attempts)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs/"+tc.id+"/attempts", nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d body=%s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantBody != "" && !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Fatalf("expected body to contain %s, got %s", tc.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminJobsPurge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name          string
+		query         string
+		wantCode      int
+		wantStatuses  string
+		wantOlderThan time.Duration
+		wantLimit     int
+	}{
+		{"defaults", "", http.StatusOK, "done", 30 * 24 * time.Hour, 50},
+		{"explicit", "?status=done,cancelled&olderThanDays=7&limit=900", http.StatusOK, "done,cancelled", 7 * 24 * time.Hour, 500},
+		{"pending is never purged", "?status=done,pending", http.StatusBadRequest, "", 0, 0},
+		{"processing is never purged", "?status=processing", http.StatusBadRequest, "", 0, 0},
+		{"zero days", "?olderThanDays=0", http.StatusBadRequest, "", 0, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			repo := &fakeAdminJobsRepo{purgeTerminalFn: func(ctx context.Context, olderThan time.Duration, statuses []string, limit int) (int64, error) {
+				called = true
+				if got := strings.Join(statuses, ","); got != tc.wantStatuses || olderThan != tc.wantOlderThan || limit != tc.wantLimit {
+					t.Fatalf("unexpected purge statuses=%s olderThan=%s limit=%d", got, olderThan, limit)
+				}
+				return 12, nil
+			}}
+			r := gin.New()
+			r.DELETE("/admin/jobs/purge", handlers.NewAdminJobsHandler(repo).Purge)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/jobs/purge"+tc.query, nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d body=%s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantCode != http.StatusOK {
+				if called {
+					t.Fatalf("expected a rejected purge not to reach the repo")
+				}
+				return
+			}
+			if !strings.Contains(w.Body.String(), `"deleted":12`) {
+				t.Fatalf("expected the deleted count, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminJobsScheduled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2026, 3, 26, 12, 0, 0, 0, time.UTC)
+	var gotFilter job.ScheduledFilter
+	var gotAfterRunAt time.Time
+	var gotAfterID string
+	repo := &fakeAdminJobsRepo{
+		listScheduledFn: func(ctx context.Context, filter job.ScheduledFilter, limit int, afterRunAt time.Time, afterID string) ([]job.Job, *string, bool, error) {
+			gotFilter, gotAfterRunAt, gotAfterID = filter, afterRunAt, afterID
+			return []job.Job{
+				{ID: "job-1", Type: "event.publish", Status: job.StatusPending, RunAt: now.Add(90 * time.Second)},
+				{ID: "job-2", Type: "event.publish", Status: job.StatusPending, RunAt: now.Add(26*time.Hour + 5*time.Minute)},
+			}, nil, false, nil
+		},
+	}
+
+	r := gin.New()
+	r.GET("/admin/jobs/scheduled", handlers.NewAdminJobsHandler(repo).WithClock(func() time.Time { return now }).Scheduled)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs/scheduled"+query, nil))
+		return w
+	}
+
+	w := get("?runBefore=2026-03-28T00:00:00Z")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Items []struct {
+			ID     string `json:"id"`
+			RunsIn string `json:"runsIn"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 2 || resp.Items[0].RunsIn != "1m 30s" || resp.Items[1].RunsIn != "1d 2h" {
+		t.Fatalf("unexpected items %+v", resp.Items)
+	}
+	if gotFilter.RunAfter != nil || gotFilter.RunBefore == nil || !gotFilter.RunBefore.Equal(time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected filter %+v", gotFilter)
+	}
+	if !gotAfterRunAt.Equal(time.Unix(0, 0)) || gotAfterID != "00000000-0000-0000-0000-000000000000" {
+		t.Fatalf("expected the first-page sentinel, got %s %s", gotAfterRunAt, gotAfterID)
+	}
+
+	for _, query := range []string{
+		"?runAfter=yesterday",
+		"?runAfter=2026-03-28T00:00:00Z&runBefore=2026-03-27T00:00:00Z",
+		"?cursor=bogus",
+		"?limit=101",
+	} {
+		if w := get(query); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d body=%s", query, w.Code, w.Body.String())
+		}
+	}
+}
+
+// computedFields re-encodes the fields the admin endpoints derive, keys
+// sorted, for comparing against a golden string.
+func computedFields(t *testing.T, item map[string]any) string {
+	t.Helper()
+	picked := map[string]any{}
+	for _, k := range []string{"nextRunIn", "retryBudgetRemaining", "lastTransitionAgo", "retrySchedule"} {
+		if v, ok := item[k]; ok {
+			picked[k] = v
+		}
+	}
+	b, err := json.Marshal(picked)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return string(b)
+}
+
+func TestAdminJobs_ComputedRetryFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2026, 3, 26, 12, 0, 0, 0, time.UTC)
+	lastErr := "smtp timeout"
+	jobsByID := map[string]job.Job{
+		// failed twice, waiting out its backoff; default backoff is 2s*2^attempt
+		"retrying": {ID: newUUID(), Type: "event.publish", Status: job.StatusPending, Attempts: 2, MaxAttempts: 5,
+			RunAt: now.Add(90 * time.Second), UpdatedAt: now.Add(-5 * time.Minute), LastError: &lastErr},
+		"overdue": {ID: newUUID(), Type: "event.publish", Status: job.StatusPending, Attempts: 0, MaxAttempts: 2,
+			RunAt: now.Add(-time.Minute), UpdatedAt: now.Add(-26 * time.Hour)},
+		"done": {ID: newUUID(), Type: "event.publish", Status: job.StatusDone, Attempts: 1, MaxAttempts: 5,
+			RunAt: now.Add(-time.Hour), UpdatedAt: now.Add(-42 * time.Second)},
+		"dead": {ID: newUUID(), Type: "event.publish", Status: job.StatusFailed, Attempts: 5, MaxAttempts: 5,
+			RunAt: now.Add(-time.Hour), UpdatedAt: now},
+	}
+	golden := map[string]string{
+		"retrying": `{"lastTransitionAgo":"5m 0s","nextRunIn":"1m 30s","retryBudgetRemaining":3,"retrySchedule":["1m 30s","1m 38s","1m 54s"]}`,
+		"overdue":  `{"lastTransitionAgo":"1d 2h","nextRunIn":"now","retryBudgetRemaining":2,"retrySchedule":["now","now"]}`,
+		"done":     `{"lastTransitionAgo":"42s","retryBudgetRemaining":4}`,
+		"dead":     `{"lastTransitionAgo":"now","retryBudgetRemaining":0}`,
+	}
+
+	clock := now
+	repo := &fakeAdminJobsRepo{
+		getByIDFn: func(ctx context.Context, id string) (job.Job, error) {
+			for _, j := range jobsByID {
+				if j.ID == id {
+					return j, nil
+				}
+			}
+			return job.Job{}, job.ErrJobNotFound
+		},
+		listCursorFn: func(ctx context.Context, status *string, limit int, afterUpdatedAt time.Time, afterID string) ([]job.Job, *string, bool, error) {
+			return []job.Job{jobsByID["retrying"], jobsByID["done"]}, nil, false, nil
+		},
+	}
+	h := handlers.NewAdminJobsHandler(repo).WithClock(func() time.Time { return clock })
+	r := gin.New()
+	r.GET("/admin/jobs", h.List)
+	r.GET("/admin/jobs/:id", h.GetByID)
+
+	for name, j := range jobsByID {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs/"+j.ID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d body=%s", name, w.Code, w.Body.String())
+		}
+		var item map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if got := computedFields(t, item); got != golden[name] {
+			t.Fatalf("%s:\n got  %s\n want %s", name, got, golden[name])
+		}
+		// the raw fields stay as they were
+		if item["runAt"] != j.RunAt.Format(time.RFC3339Nano) || item["attempts"] != float64(j.Attempts) {
+			t.Fatalf("%s: raw fields changed: %s", name, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs", nil))
+	var page struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(page.Items) != 2 || computedFields(t, page.Items[0]) != golden["retrying"] || computedFields(t, page.Items[1]) != golden["done"] {
+		t.Fatalf("unexpected list items: %s", w.Body.String())
+	}
+
+	// the countdowns move with the clock, the ETag does not
+	etag := w.Header().Get("ETag")
+	clock = now.Add(10 * time.Second)
+	req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 after the clock moved, got %d", w.Code)
+	}
+
+	// it is taken from the rendered jobs, so a different retry schedule is a
+	// different ETag
+	slower := handlers.NewAdminJobsHandler(repo).WithClock(func() time.Time { return clock }).
+		WithRetryDelay(func(jobType string, attempt int) time.Duration { return time.Hour })
+	r = gin.New()
+	r.GET("/admin/jobs", slower.List)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected a new ETag for the new schedule, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestAdminJobsRetry_Priority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got *int
+	calls := 0
+	repo := &fakeAdminJobsRepo{
+		retryFn: func(ctx context.Context, id string, priority *int) error {
+			calls++
+			got = priority
+			return nil
+		},
+	}
+	r := gin.New()
+	r.POST("/admin/jobs/:id/retry", handlers.NewAdminJobsHandler(repo).Retry)
+
+	do := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/jobs/"+newUUID()+"/retry"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(""); w.Code != http.StatusOK || got != nil {
+		t.Fatalf("expected a plain retry to keep the priority, got %d priority=%v", w.Code, got)
+	}
+	if w := do("?priority=high"); w.Code != http.StatusOK || got == nil || *got != job.PriorityHigh {
+		t.Fatalf("expected the retry bumped to high, got %d priority=%v", w.Code, got)
+	}
+
+	calls = 0
+	if w := do("?priority=asap"); w.Code != http.StatusBadRequest || calls != 0 {
+		t.Fatalf("expected 400 without retrying, got %d calls=%d", w.Code, calls)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminQueueRepo interface {
+	QueueState(ctx context.Context) (job.QueueState, error)
+	SetPaused(ctx context.Context, paused bool, by string) (job.QueueState, error)
+	PauseType(ctx context.Context, jobType, by string) (job.PausedType, error)
+	ResumeType(ctx context.Context, jobType string) (bool, error)
+}
+
+// AdminQueueHandler flips the queue-wide pause switch and pauses single job
+// types. Workers cache both for a few seconds, so a pause lands within that
+// and jobs already running are left to finish.
+type AdminQueueHandler struct {
+	repo AdminQueueRepo
+}
+
+func NewAdminQueueHandler(repo AdminQueueRepo) *AdminQueueHandler {
+	return &AdminQueueHandler{repo: repo}
+}
+
+// GET /admin/queue
+func (h *AdminQueueHandler) State(ctx *gin.Context) {
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	st, err := h.repo.QueueState(cctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not read queue state")
+		return
+	}
+	ctx.JSON(http.StatusOK, st)
+}
+
+// POST /admin/queue/pause
+func (h *AdminQueueHandler) Pause(ctx *gin.Context) {
+	h.setPaused(ctx, true)
+}
+
+// POST /admin/queue/resume
+func (h *AdminQueueHandler) Resume(ctx *gin.Context) {
+	h.setPaused(ctx, false)
+}
+
+func (h *AdminQueueHandler) setPaused(ctx *gin.Context, paused bool) {
+	userID, _ := middlewares.UserIDFromContext(ctx)
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	st, err := h.repo.SetPaused(cctx, paused, userID)
+	if err != nil {
+		RespondInternal(ctx, "Could not update queue state")
+		return
+	}
+	ctx.JSON(http.StatusOK, st)
+}
+
+// POST /admin/queue/types/:type/pause
+//
+// Workers stop claiming jobs of the type; they stay pending, untouched, until
+// it is resumed.
+func (h *AdminQueueHandler) PauseType(ctx *gin.Context) {
+	jobType, ok := jobTypeParam(ctx)
+	if !ok {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(ctx)
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if _, err := h.repo.PauseType(cctx, jobType, userID); err != nil {
+		RespondInternal(ctx, "Could not update queue state")
+		return
+	}
+	h.respondState(ctx, cctx)
+}
+
+// POST /admin/queue/types/:type/resume
+func (h *AdminQueueHandler) ResumeType(ctx *gin.Context) {
+	jobType, ok := jobTypeParam(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	// resuming a type that is not paused is a no-op, like resuming the queue
+	if _, err := h.repo.ResumeType(cctx, jobType); err != nil {
+		RespondInternal(ctx, "Could not update queue state")
+		return
+	}
+	h.respondState(ctx, cctx)
+}
+
+func (h *AdminQueueHandler) respondState(ctx *gin.Context, cctx context.Context) {
+	st, err := h.repo.QueueState(cctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not read queue state")
+		return
+	}
+	ctx.JSON(http.StatusOK, st)
+}
+
+// jobTypeParam reads :type, answering 400 itself when it is empty or too long.
+func jobTypeParam(ctx *gin.Context) (string, bool) {
+	jobType := strings.TrimSpace(ctx.Param("type"))
+	if jobType == "" || len(jobType) > job.MaxTypeLength {
+		RespondBadRequest(ctx, "invalid_request", fmt.Sprintf("type must be 1 to %d characters", job.MaxTypeLength))
+		return "", false
+	}
+	return jobType, true
+}
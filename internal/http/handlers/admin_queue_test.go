@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeQueueRepo struct {
+	state job.QueueState
+	by    string
+	err   error
+}
+
+func (f *fakeQueueRepo) QueueState(ctx context.Context) (job.QueueState, error) {
+	return f.state, f.err
+}
+
+func (f *fakeQueueRepo) SetPaused(ctx context.Context, paused bool, by string) (job.QueueState, error) {
+	if f.err != nil {
+		return job.QueueState{}, f.err
+	}
+	f.state.Paused, f.by = paused, by
+	return f.state, nil
+}
+
+func (f *fakeQueueRepo) PauseType(ctx context.Context, jobType, by string) (job.PausedType, error) {
+	if f.err != nil {
+		return job.PausedType{}, f.err
+	}
+	p := job.PausedType{Type: jobType, PausedBy: &by}
+	f.state.PausedTypes = append(f.state.PausedTypes, p)
+	return p, nil
+}
+
+func (f *fakeQueueRepo) ResumeType(ctx context.Context, jobType string) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	for i, p := range f.state.PausedTypes {
+		if p.Type == jobType {
+			f.state.PausedTypes = append(f.state.PausedTypes[:i], f.state.PausedTypes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestAdminQueue_PauseAndResume(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adminID := newUUID()
+	repo := &fakeQueueRepo{}
+	h := handlers.NewAdminQueueHandler(repo)
+	r := gin.New()
+	r.Use(withUser(adminID, user.RoleAdmin))
+	r.GET("/admin/queue", h.State)
+	r.POST("/admin/queue/pause", h.Pause)
+	r.POST("/admin/queue/resume", h.Resume)
+
+	for _, step := range []struct {
+		method, path string
+		wantPaused   bool
+	}{
+		{http.MethodPost, "/admin/queue/pause", true},
+		{http.MethodGet, "/admin/queue", true},
+		{http.MethodPost, "/admin/queue/resume", false},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(step.method, step.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: got %d body=%s", step.method, step.path, w.Code, w.Body.String())
+		}
+		var st job.QueueState
+		if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if st.Paused != step.wantPaused {
+			t.Fatalf("%s %s: expected paused=%t, got %+v", step.method, step.path, step.wantPaused, st)
+		}
+	}
+	if repo.by != adminID {
+		t.Fatalf("expected the admin recorded, got %q", repo.by)
+	}
+
+	repo.err = errors.New("db down")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/queue/pause", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 on a failed write, got %d", w.Code)
+	}
+}
+
+func TestAdminQueue_PauseAndResumeType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeQueueRepo{}
+	h := handlers.NewAdminQueueHandler(repo)
+	r := gin.New()
+	r.Use(withUser(newUUID(), user.RoleAdmin))
+	r.POST("/admin/queue/types/:type/pause", h.PauseType)
+	r.POST("/admin/queue/types/:type/resume", h.ResumeType)
+
+	post := func(path string) (*httptest.ResponseRecorder, job.QueueState) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		var st job.QueueState
+		_ = json.Unmarshal(w.Body.Bytes(), &st)
+		return w, st
+	}
+
+	w, st := post("/admin/queue/types/registration.confirmation/pause")
+	if w.Code != http.StatusOK || st.Paused || len(st.PausedTypes) != 1 || st.PausedTypes[0].Type != "registration.confirmation" {
+		t.Fatalf("expected only the type paused, got %d %s", w.Code, w.Body.String())
+	}
+
+	w, st = post("/admin/queue/types/registration.confirmation/resume")
+	if w.Code != http.StatusOK || len(st.PausedTypes) != 0 {
+		t.Fatalf("expected the type resumed, got %d %s", w.Code, w.Body.String())
+	}
+
+	// resuming again is a no-op
+	if w, _ = post("/admin/queue/types/registration.confirmation/resume"); w.Code != http.StatusOK {
+		t.Fatalf("expected resuming a running type to succeed, got %d", w.Code)
+	}
+
+	if w, _ = post("/admin/queue/types/" + strings.Repeat("x", job.MaxTypeLength+1) + "/pause"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an oversized type, got %d", w.Code)
+	}
+}
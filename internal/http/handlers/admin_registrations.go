@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RegistrationSearcher interface {
+	SearchByEmail(
+		ctx context.Context,
+		email string,
+		limit int,
+		beforeCreatedAt time.Time,
+		beforeID string,
+	) (items []registration.WithEvent, nextCursor *string, hasMore bool, err error)
+}
+
+type AdminRegistrationsHandler struct {
+	repo RegistrationSearcher
+}
+
+func NewAdminRegistrationsHandler(repo RegistrationSearcher) *AdminRegistrationsHandler {
+	return &AdminRegistrationsHandler{repo: repo}
+}
+
+// Search handles GET /admin/registrations?email=...: every registration for
+// one person across all events, newest first (support and GDPR requests).
+func (h *AdminRegistrationsHandler) Search(ctx *gin.Context) {
+	email := strings.TrimSpace(ctx.Query("email"))
+	if email == "" || len(email) > 254 || !strings.Contains(email, "@") {
+		RespondBadRequest(ctx, "invalid_query", "email must be a valid email address")
+		return
+	}
+
+	limit := parseIntDefault(ctx.Query("limit"), 20)
+	if limit < 1 || limit > 100 {
+		RespondBadRequest(ctx, "invalid_query", "limit must be between 1 and 100")
+		return
+	}
+
+	// DESC first-page sentinel: "far future" + max UUID
+	beforeCreatedAt := time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	beforeID := "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		cur, err := utils.DecodeRegistrationCursor(cursor)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "cursor is invalid")
+			return
+		}
+		beforeCreatedAt = cur.CreatedAt
+		beforeID = cur.ID
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, next, hasMore, err := h.repo.SearchByEmail(cctx, email, limit, beforeCreatedAt, beforeID)
+	if err != nil {
+		RespondInternal(ctx, "Could not search registrations")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BuildCursorPageResponse(limit, items, hasMore, next, nil))
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeRegistrationSearcher struct {
+	searchFn func(ctx context.Context, email string, limit int, beforeCreatedAt time.Time, beforeID string) ([]registration.WithEvent, *string, bool, error)
+}
+
+func (f *fakeRegistrationSearcher) SearchByEmail(ctx context.Context, email string, limit int, beforeCreatedAt time.Time, beforeID string) ([]registration.WithEvent, *string, bool, error) {
+	return f.searchFn(ctx, email, limit, beforeCreatedAt, beforeID)
+}
+
+func TestAdminRegistrationsSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cursorAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	cursorID := newUUID()
+	cursor, err := utils.EncodeRegistrationCursor(cursorAt, cursorID)
+	if err != nil {
+		t.Fatalf("encode cursor: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBefore time.Time
+		wantID     string
+	}{
+		{name: "missing email", query: "", wantStatus: http.StatusBadRequest},
+		{name: "not an email", query: "email=nobody", wantStatus: http.StatusBadRequest},
+		{name: "bad cursor", query: "email=a@example.com&cursor=nope", wantStatus: http.StatusBadRequest},
+		{name: "first page", query: "email=" + url.QueryEscape(" Sam@Example.com "), wantStatus: http.StatusOK, wantBefore: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), wantID: "ffffffff-ffff-ffff-ffff-ffffffffffff"},
+		{name: "next page", query: "email=sam@example.com&cursor=" + cursor, wantStatus: http.StatusOK, wantBefore: cursorAt, wantID: cursorID},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			repo := &fakeRegistrationSearcher{
+				searchFn: func(ctx context.Context, email string, limit int, beforeCreatedAt time.Time, beforeID string) ([]registration.WithEvent, *string, bool, error) {
+					called = true
+					if email != "Sam@Example.com" && email != "sam@example.com" {
+						t.Fatalf("expected trimmed email, got %q", email)
+					}
+					if !beforeCreatedAt.Equal(tc.wantBefore) || beforeID != tc.wantID {
+						t.Fatalf("got cursor (%v, %s), want (%v, %s)", beforeCreatedAt, beforeID, tc.wantBefore, tc.wantID)
+					}
+					return []registration.WithEvent{{
+						Registration: registration.Registration{ID: newUUID(), Email: "sam@example.com"},
+						EventTitle:   "Go Meetup",
+					}}, nil, false, nil
+				},
+			}
+
+			h := handlers.NewAdminRegistrationsHandler(repo)
+			r := gin.New()
+			r.GET("/admin/registrations", h.Search)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/registrations?"+tc.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				if called {
+					t.Fatalf("repo should not be called on invalid input")
+				}
+				return
+			}
+
+			var body struct {
+				Items []map[string]any `json:"items"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(body.Items) != 1 || body.Items[0]["eventTitle"] != "Go Meetup" || body.Items[0]["email"] != "sam@example.com" {
+				t.Fatalf("unexpected items: %v", body.Items)
+			}
+		})
+	}
+}
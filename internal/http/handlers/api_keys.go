@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/geocoder89/eventhub/internal/domain/apikey"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeysRepository interface {
+	Create(ctx context.Context, k apikey.Key, hash string) (apikey.Key, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]apikey.Key, error)
+	Revoke(ctx context.Context, ownerID, id string) error
+}
+
+type EventOrganizersReader interface {
+	Organizers(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+type APIKeysHandler struct {
+	keys   APIKeysRepository
+	events EventOrganizersReader
+}
+
+func NewAPIKeysHandler(keys APIKeysRepository, events EventOrganizersReader) *APIKeysHandler {
+	return &APIKeysHandler{keys: keys, events: events}
+}
+
+type createAPIKeyResponse struct {
+	// Key is the secret itself; it is only ever returned here.
+	Key    string     `json:"key"`
+	APIKey apikey.Key `json:"apiKey"`
+}
+
+// Create handles POST /api-keys: issues a key scoped to events the caller organizes.
+func (h *APIKeysHandler) Create(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing user identity")
+		return
+	}
+
+	var req apikey.CreateRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	k, raw, hash, err := apikey.NewFromCreateRequest(userID, req)
+	if err != nil {
+		RespondInternal(ctx, "Could not generate API key")
+		return
+	}
+
+	if len(k.EventIDs) == 0 && k.OrganizerID == "" {
+		RespondBadRequest(ctx, "eventIds or allEvents is required", nil)
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if len(k.EventIDs) > 0 {
+		organizers, err := h.events.Organizers(cctx, k.EventIDs)
+		if err != nil {
+			RespondInternal(ctx, "Could not verify event ownership")
+			return
+		}
+
+		for _, eventID := range k.EventIDs {
+			organizerID, found := organizers[eventID]
+			if !found {
+				RespondError(ctx, http.StatusNotFound, "not_found", "Event not found", gin.H{"eventId": eventID})
+				return
+			}
+			if organizerID != userID {
+				RespondError(ctx, http.StatusForbidden, "forbidden", "You can only issue keys for events you organize", gin.H{"eventId": eventID})
+				return
+			}
+		}
+	}
+
+	created, err := h.keys.Create(cctx, k, hash)
+	if err != nil {
+		RespondInternal(ctx, "Could not create API key")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createAPIKeyResponse{Key: raw, APIKey: created})
+}
+
+// List handles GET /api-keys: the caller's keys, newest first, without secrets.
+func (h *APIKeysHandler) List(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing user identity")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	keys, err := h.keys.ListByOwner(cctx, userID)
+	if err != nil {
+		RespondInternal(ctx, "Could not list API keys")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"items": keys})
+}
+
+// Revoke handles DELETE /api-keys/:id.
+func (h *APIKeysHandler) Revoke(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing user identity")
+		return
+	}
+
+	id := ctx.Param("id")
+	if !utils.IsUUID(id) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if err := h.keys.Revoke(cctx, userID, id); err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			RespondNotFound(ctx, "API key not found")
+			return
+		}
+		RespondInternal(ctx, "Could not revoke API key")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/apikey"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeAPIKeysRepo struct {
+	created []apikey.Key
+	hashes  []string
+}
+
+func (f *fakeAPIKeysRepo) Create(ctx context.Context, k apikey.Key, hash string) (apikey.Key, error) {
+	f.created = append(f.created, k)
+	f.hashes = append(f.hashes, hash)
+	return k, nil
+}
+
+func (f *fakeAPIKeysRepo) ListByOwner(ctx context.Context, ownerID string) ([]apikey.Key, error) {
+	return f.created, nil
+}
+
+func (f *fakeAPIKeysRepo) Revoke(ctx context.Context, ownerID, id string) error {
+	return apikey.ErrNotFound
+}
+
+type fakeOrganizers map[string]string
+
+func (f fakeOrganizers) Organizers(ctx context.Context, ids []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, id := range ids {
+		if org, ok := f[id]; ok {
+			out[id] = org
+		}
+	}
+	return out, nil
+}
+
+func TestAPIKeysCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owner := newUUID()
+	ownEvent := newUUID()
+	foreignEvent := newUUID()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "no scope", body: `{"name":"widget","actions":["register"]}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown action", body: `{"name":"widget","allEvents":true,"actions":["delete"]}`, wantStatus: http.StatusBadRequest},
+		{name: "foreign event", body: `{"name":"widget","eventIds":["` + foreignEvent + `"],"actions":["register"]}`, wantStatus: http.StatusForbidden},
+		{name: "missing event", body: `{"name":"widget","eventIds":["` + newUUID() + `"],"actions":["register"]}`, wantStatus: http.StatusNotFound},
+		{name: "own event", body: `{"name":"widget","eventIds":["` + ownEvent + `"],"actions":["register","read_availability"]}`, wantStatus: http.StatusCreated},
+		{name: "all events", body: `{"name":"widget","allEvents":true,"actions":["read_availability"]}`, wantStatus: http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeAPIKeysRepo{}
+			h := handlers.NewAPIKeysHandler(repo, fakeOrganizers{ownEvent: owner, foreignEvent: newUUID()})
+
+			r := gin.New()
+			r.POST("/api-keys", withUser(owner, "user"), h.Create)
+
+			req := httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus != http.StatusCreated {
+				if len(repo.created) != 0 {
+					t.Fatalf("no key should be stored")
+				}
+				return
+			}
+
+			var resp struct {
+				Key    string     `json:"key"`
+				APIKey apikey.Key `json:"apiKey"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !strings.HasPrefix(resp.Key, resp.APIKey.Prefix) || resp.APIKey.OwnerID != owner {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+			if repo.hashes[0] != apikey.Hash(resp.Key) || strings.Contains(repo.hashes[0], resp.Key) {
+				t.Fatalf("repo must store only the hash of the returned key")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// EventAttendanceReader reads the attendance recorded when an event is finalized.
+type EventAttendanceReader interface {
+	StatsForEvent(ctx context.Context, eventID string) (event.AttendanceStats, error)
+}
+
+// AttendanceStatsReader adds the per-organizer rollup.
+type AttendanceStatsReader interface {
+	EventAttendanceReader
+	OrganizerRollup(ctx context.Context, organizerID string, recentLimit int) (event.AttendanceRollup, error)
+}
+
+type AttendanceHandler struct {
+	repo AttendanceStatsReader
+}
+
+func NewAttendanceHandler(repo AttendanceStatsReader) *AttendanceHandler {
+	return &AttendanceHandler{repo: repo}
+}
+
+// GET /me/attendance-stats?recent=20: no-show rates across the caller's
+// finalized events.
+func (h *AttendanceHandler) MyStats(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	recent := parseIntDefault(ctx.Query("recent"), 20)
+	if recent < 0 || recent > 100 {
+		RespondBadRequest(ctx, "invalid_query", "recent must be between 0 and 100")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	rollup, err := h.repo.OrganizerRollup(cctx, userID, recent)
+	if err != nil {
+		RespondInternal(ctx, "Could not load attendance stats")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rollup)
+}
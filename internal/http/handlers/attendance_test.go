@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeAttendanceStats struct {
+	gotOrganizer string
+	gotRecent    int
+}
+
+func (f *fakeAttendanceStats) StatsForEvent(ctx context.Context, eventID string) (event.AttendanceStats, error) {
+	return event.AttendanceStats{}, event.ErrAttendanceNotFinalized
+}
+
+func (f *fakeAttendanceStats) OrganizerRollup(ctx context.Context, organizerID string, recentLimit int) (event.AttendanceRollup, error) {
+	f.gotOrganizer, f.gotRecent = organizerID, recentLimit
+	return event.AttendanceRollup{Events: 2, Registrations: 10, CheckedIn: 8, NoShows: 2, NoShowRate: 0.2, Recent: []event.AttendanceStats{}}, nil
+}
+
+func TestMyAttendanceStats_RollsUpForCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeAttendanceStats{}
+	userID := newUUID()
+	r := gin.New()
+	r.GET("/me/attendance-stats", withUser(userID, "user"), handlers.NewAttendanceHandler(repo).MyStats)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me/attendance-stats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	var got event.AttendanceRollup
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.NoShowRate != 0.2 || repo.gotOrganizer != userID || repo.gotRecent != 20 {
+		t.Fatalf("unexpected rollup %+v for organizer=%s recent=%d", got, repo.gotOrganizer, repo.gotRecent)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me/attendance-stats?recent=500", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for recent over 100, got %d", w.Code)
+	}
+}
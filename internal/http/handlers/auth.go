@@ -2,16 +2,20 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/auth"
 	"github.com/geocoder89/eventhub/internal/config"
 	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
 	"github.com/geocoder89/eventhub/internal/security"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type UserReader interface {
@@ -19,40 +23,55 @@ type UserReader interface {
 }
 
 type UserWriter interface {
-	Create(ctx context.Context, email, passwordHash, name, role string) (user.User, error)
+	Create(ctx context.Context, email, passwordHash, name string, role user.Role) (user.User, error)
 }
 
-type postgresTx interface {
-	Commit(ctx context.Context) error
-	Rollback(ctx context.Context) error
+// PasswordRehasher is optionally implemented by the UserWriter; Login uses it
+// to move hashes onto the current scheme.
+type PasswordRehasher interface {
+	ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) (bool, error)
 }
 
 type RefreshTokenStore interface {
-	BeginTx(ctx context.Context) (postgresTx, error)
-	Create(ctx context.Context, tx postgresTx, row postgres.RefreshTokenRow) error
-	GetForUpdate(ctx context.Context, tx postgresTx, id string) (postgres.RefreshTokenRow, error)
-	Revoke(ctx context.Context, tx postgresTx, id string, replacedBy *string) error
-	RevokeAllForUser(ctx context.Context, tx postgresTx, userID string) error
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	Create(ctx context.Context, tx pgx.Tx, row postgres.RefreshTokenRow) error
+	GetForUpdate(ctx context.Context, tx pgx.Tx, id string) (postgres.RefreshTokenRow, error)
+	Revoke(ctx context.Context, tx pgx.Tx, id string, replacedBy *string) error
+	RevokeAllForUser(ctx context.Context, tx pgx.Tx, userID string) error
 }
 
 type AuthHandler struct {
 	users        UserReader
 	userWriter   UserWriter
 	jwt          *auth.Manager
-	refreshStore *postgres.RefreshTokensRepo
+	refreshStore RefreshTokenStore
 	cfg          config.Config
+	passwords    *security.Passwords
+	metrics      *observability.Prom
 }
 
-func NewAuthHandler(users UserReader, userWriter UserWriter, jwtManager *auth.Manager, refreshStore *postgres.RefreshTokensRepo, cfg config.Config) *AuthHandler {
+func NewAuthHandler(users UserReader, userWriter UserWriter, jwtManager *auth.Manager, refreshStore RefreshTokenStore, cfg config.Config) *AuthHandler {
 	return &AuthHandler{
 		users:        users,
 		userWriter:   userWriter,
 		jwt:          jwtManager,
 		refreshStore: refreshStore,
 		cfg:          cfg,
+		passwords:    security.DefaultPasswords(),
 	}
 }
 
+func (h *AuthHandler) WithPasswords(p *security.Passwords) *AuthHandler {
+	h.passwords = p
+	return h
+}
+
+// WithMetrics counts login and refresh outcomes into p.
+func (h *AuthHandler) WithMetrics(p *observability.Prom) *AuthHandler {
+	h.metrics = p
+	return h
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
@@ -70,11 +89,11 @@ func (h *AuthHandler) SignUp(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(3 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
 
-	hash, err := security.HashPassword(req.Password)
+	hash, err := h.passwords.Hash(req.Password)
 
 	if err != nil {
 		RespondInternal(ctx, "Could not create user")
@@ -83,13 +102,11 @@ func (h *AuthHandler) SignUp(ctx *gin.Context) {
 
 	// default role for new users
 
-	role := "user"
-
-	u, err := h.userWriter.Create(cctx, req.Email, hash, req.Name, role)
+	u, err := h.userWriter.Create(cctx, req.Email, hash, req.Name, user.RoleUser)
 
 	if err != nil {
-		if err == postgres.ErrEmailAlreadyUsed {
-			RespondBadRequest(ctx, "email_taken", "Email is already in use.")
+		if errors.Is(err, user.ErrEmailTaken) {
+			RespondError(ctx, http.StatusConflict, "email_taken", "Email is already in use.", gin.H{"field": "email"})
 			return
 		}
 
@@ -131,23 +148,34 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 	if !BindJSON(ctx, &req) {
 		return
 	}
+
+	start := time.Now()
+	result := observability.AuthResultError
+	defer func() { h.metrics.ObserveLogin(result, time.Since(start)) }()
+
 	// short timeout for DB lookup
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	foundUser, err := h.users.GetByEmail(cctx, req.Email)
 	if err != nil {
+		result = observability.AuthResultInvalidCredentials
 		RespondUnAuthorized(ctx, "invalid_credentials", "Email or password is incorrect.")
 		return
 	}
 
-	err = security.CheckPassword(foundUser.PasswordHash, req.Password)
+	needsRehash, err := h.passwords.Verify(foundUser.PasswordHash, req.Password)
 
 	if err != nil {
+		result = observability.AuthResultInvalidCredentials
 		RespondUnAuthorized(ctx, "invalid_credentials", "Email or password is incorrect.")
 		return
 	}
 
+	if needsRehash {
+		go h.rehashPassword(foundUser.ID, foundUser.PasswordHash, req.Password)
+	}
+
 	accessToken, err := h.jwt.GenerateAccessToken(foundUser.ID, foundUser.Email, foundUser.Role)
 
 	if err != nil {
@@ -168,6 +196,7 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 	}
 
 	h.setRefreshCookie(ctx, rawRefreshToken, expiresAt)
+	result = observability.AuthResultSuccess
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"accessToken": accessToken,
@@ -177,9 +206,13 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 // Refresh Token functions
 
 func (h *AuthHandler) Refresh(ctx *gin.Context) {
+	result := observability.AuthResultError
+	defer func() { h.metrics.ObserveRefresh(result) }()
+
 	raw, err := ctx.Cookie(h.refreshCookieName())
 
 	if err != nil || raw == "" {
+		result = observability.AuthResultMissing
 		RespondUnAuthorized(ctx, "no_refresh", "Missing refresh token")
 		return
 	}
@@ -187,13 +220,14 @@ func (h *AuthHandler) Refresh(ctx *gin.Context) {
 	claims, err := h.jwt.VerifyRefreshToken(raw)
 
 	if err != nil {
+		result = observability.AuthResultInvalid
 		RespondUnAuthorized(ctx, "invalid_refresh", "Invalid refresh token")
 		return
 	}
 
 	// rotation with a tx with row lock
 
-	cctx, cancel := config.WithTimeout(3 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
 
@@ -209,6 +243,7 @@ func (h *AuthHandler) Refresh(ctx *gin.Context) {
 	row, err := h.refreshStore.GetForUpdate(cctx, tx, claims.JTI)
 
 	if err != nil {
+		result = observability.AuthResultInvalid
 		RespondUnAuthorized(ctx, "invalid_refresh", "Invalid refresh token")
 		return
 	}
@@ -216,11 +251,14 @@ func (h *AuthHandler) Refresh(ctx *gin.Context) {
 	//  check if it is revoked/expired
 
 	if row.RevokedAt != nil {
+		// a rotated or logged-out token coming back is a reuse signal
+		result = observability.AuthResultReused
 		RespondUnAuthorized(ctx, "invalid_refresh", "Invalid refresh token")
 		return
 	}
 
 	if time.Now().UTC().After(row.ExpiresAt) {
+		result = observability.AuthResultExpired
 		RespondUnAuthorized(ctx, "expired_refresh", "Refresh token expired.")
 		return
 	}
@@ -228,6 +266,7 @@ func (h *AuthHandler) Refresh(ctx *gin.Context) {
 	// verify hash matches the presented token (prevents token substitution)
 
 	if row.TokenHash != h.jwt.HashRefreshToken(raw) {
+		result = observability.AuthResultInvalid
 		RespondUnAuthorized(ctx, "invalid_refresh", "Invalid refresh token.")
 		return
 	}
@@ -281,6 +320,7 @@ func (h *AuthHandler) Refresh(ctx *gin.Context) {
 	}
 
 	h.setRefreshCookie(ctx, newRaw, newExpiresAt)
+	result = observability.AuthResultSuccess
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"accessToken": accessToken,
@@ -307,7 +347,7 @@ func (h *AuthHandler) Logout(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(3 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	tx, err := h.refreshStore.BeginTx(cctx)
@@ -392,3 +432,25 @@ func (h *AuthHandler) clearRefreshCookie(ctx *gin.Context) {
 		true,
 	)
 }
+
+// rehashPassword stores plain under the current scheme. It runs after the
+// response is on its way, so a failure only means trying again next login.
+func (h *AuthHandler) rehashPassword(userID, oldHash, plain string) {
+	rehasher, ok := h.userWriter.(PasswordRehasher)
+	if !ok {
+		return
+	}
+
+	newHash, err := h.passwords.Hash(plain)
+	if err != nil {
+		slog.Default().Warn("auth.rehash_failed", "user_id", userID, "err", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := rehasher.ReplacePasswordHash(ctx, userID, oldHash, newHash); err != nil {
+		slog.Default().Warn("auth.rehash_failed", "user_id", userID, "err", err)
+	}
+}
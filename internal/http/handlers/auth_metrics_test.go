@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/auth"
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/geocoder89/eventhub/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeAuthUsers struct {
+	users map[string]user.User
+}
+
+func (f fakeAuthUsers) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	u, ok := f.users[email]
+	if !ok {
+		return user.User{}, postgres.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (f fakeAuthUsers) Create(ctx context.Context, email, passwordHash, name string, role user.Role) (user.User, error) {
+	if _, ok := f.users[email]; ok {
+		return user.User{}, user.ErrEmailTaken
+	}
+	return user.User{}, nil
+}
+
+// newMetricsAuthHandler gets its own registry so counts never leak between tests.
+func newMetricsAuthHandler(t *testing.T) (*handlers.AuthHandler, *observability.Prom) {
+	t.Helper()
+
+	hash, err := security.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	users := fakeAuthUsers{users: map[string]user.User{
+		"ada@example.com": {ID: newUUID(), Email: "ada@example.com", PasswordHash: hash, Role: "user"},
+	}}
+
+	prom := observability.NewProm(prometheus.NewRegistry())
+	jwt := auth.NewManager("test-secret", 15*time.Minute, time.Hour)
+	h := handlers.NewAuthHandler(users, users, jwt, nil, config.Config{}).WithMetrics(prom)
+	return h, prom
+}
+
+func postJSON(r *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.1:4000"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginMetrics_CountsInvalidCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, prom := newMetricsAuthHandler(t)
+	r := gin.New()
+	r.POST("/login", h.Login)
+
+	for _, body := range []string{
+		`{"email":"ada@example.com","password":"wrong"}`,
+		`{"email":"nobody@example.com","password":"whatever"}`,
+	} {
+		if w := postJSON(r, "/login", body); w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d body=%s", w.Code, w.Body.String())
+		}
+	}
+	// a malformed body is not a login attempt
+	if w := postJSON(r, "/login", `{"email":"ada@example.com"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	if got := testutil.ToFloat64(prom.AuthLoginsTotal.WithLabelValues(observability.AuthResultInvalidCredentials)); got != 2 {
+		t.Fatalf("expected 2 invalid_credentials logins, got %v", got)
+	}
+	if got := testutil.CollectAndCount(prom.AuthLoginsTotal); got != 1 {
+		t.Fatalf("expected a single result series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(prom.AuthLoginDuration); got != 1 {
+		t.Fatalf("expected login latency to be observed, got %d series", got)
+	}
+}
+
+func TestRefreshMetrics_CountsMissingAndInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, prom := newMetricsAuthHandler(t)
+	r := gin.New()
+	r.POST("/auth/refresh", h.Refresh)
+
+	if w := postJSON(r, "/auth/refresh", ``); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "not-a-jwt"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+
+	if got := testutil.ToFloat64(prom.AuthRefreshesTotal.WithLabelValues(observability.AuthResultMissing)); got != 1 {
+		t.Fatalf("expected 1 missing refresh, got %v", got)
+	}
+	if got := testutil.ToFloat64(prom.AuthRefreshesTotal.WithLabelValues(observability.AuthResultInvalid)); got != 1 {
+		t.Fatalf("expected 1 invalid refresh, got %v", got)
+	}
+	if got := testutil.ToFloat64(prom.AuthTokenReuseTotal); got != 0 {
+		t.Fatalf("expected no reuse, got %v", got)
+	}
+}
+
+func TestLoginMetrics_CountsLockouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, prom := newMetricsAuthHandler(t)
+	limiter := middlewares.NewRateLimiter(2, time.Minute).WithOnLimited(prom.IncAuthLockout)
+	r := gin.New()
+	r.POST("/login", limiter.RateLimiterMiddleware(middlewares.KeyByIP), h.Login)
+
+	codes := make([]int, 0, 4)
+	for i := 0; i < 4; i++ {
+		codes = append(codes, postJSON(r, "/login", `{"email":"ada@example.com","password":"wrong"}`).Code)
+	}
+	if codes[2] != http.StatusTooManyRequests || codes[3] != http.StatusTooManyRequests {
+		t.Fatalf("expected the last two attempts to be limited, got %v", codes)
+	}
+
+	if got := testutil.ToFloat64(prom.AuthLockoutsTotal); got != 2 {
+		t.Fatalf("expected 2 lockouts, got %v", got)
+	}
+	if got := testutil.ToFloat64(prom.AuthLoginsTotal.WithLabelValues(observability.AuthResultInvalidCredentials)); got != 2 {
+		t.Fatalf("limited attempts should not reach the handler, got %v logins", got)
+	}
+}
+
+func TestSignUp_TakenEmailIsConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, _ := newMetricsAuthHandler(t)
+	r := gin.New()
+	r.POST("/signup", h.SignUp)
+
+	w := postJSON(r, "/signup", `{"email":"ada@example.com","password":"StrongPassword123!","name":"Ada"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Error struct {
+			Code    string            `json:"code"`
+			Details map[string]string `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Error.Code != "email_taken" || resp.Error.Details["field"] != "email" {
+		t.Fatalf("expected email_taken on field email, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AvailabilityChanges signals when an event's seats may have changed.
+type AvailabilityChanges interface {
+	Subscribe(eventID string) (<-chan struct{}, func())
+}
+
+// StreamLimits bound a live stream: at most one update per MinInterval, and
+// the connection is closed after MaxLifetime with a hint to reconnect.
+type StreamLimits struct {
+	MinInterval time.Duration
+	MaxLifetime time.Duration
+	KeepAlive   time.Duration
+	Retry       time.Duration
+}
+
+var DefaultStreamLimits = StreamLimits{
+	MinInterval: time.Second,
+	MaxLifetime: 5 * time.Minute,
+	KeepAlive:   15 * time.Second,
+	Retry:       3 * time.Second,
+}
+
+// WithLiveAvailability enables GET /events/:id/availability/stream.
+func (h *EventCountersHandler) WithLiveAvailability(changes AvailabilityChanges) *EventCountersHandler {
+	h.changes = changes
+	return h
+}
+
+// WithStreamLimits overrides DefaultStreamLimits; zero fields keep the default.
+func (h *EventCountersHandler) WithStreamLimits(l StreamLimits) *EventCountersHandler {
+	if l.MinInterval > 0 {
+		h.stream.MinInterval = l.MinInterval
+	}
+	if l.MaxLifetime > 0 {
+		h.stream.MaxLifetime = l.MaxLifetime
+	}
+	if l.KeepAlive > 0 {
+		h.stream.KeepAlive = l.KeepAlive
+	}
+	if l.Retry > 0 {
+		h.stream.Retry = l.Retry
+	}
+	return h
+}
+
+// StreamAvailability handles GET /events/:id/availability/stream: a
+// server-sent event stream that sends the current availability, then again
+// whenever the event's seats change.
+func (h *EventCountersHandler) StreamAvailability(ctx *gin.Context) {
+	if h.changes == nil {
+		RespondError(ctx, http.StatusServiceUnavailable, "stream_unavailable", "Live availability is not enabled", nil)
+		return
+	}
+
+	eventID := ctx.Param("id")
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	// subscribe before the first read so no change falls in between
+	updates, unsubscribe := h.changes.Subscribe(eventID)
+	defer unsubscribe()
+
+	availability, err := h.readAvailability(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			RespondNotFound(ctx, "Event not found")
+			return
+		}
+		RespondInternal(ctx, "Could not fetch event availability")
+		return
+	}
+
+	// the stream outlives the server's write timeout on purpose
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	s := &sseWriter{ctx: ctx}
+	s.retry(h.stream.Retry)
+	s.event("availability", availability)
+	if s.err != nil {
+		return
+	}
+	lastSent := time.Now()
+
+	lifetime := time.NewTimer(h.stream.MaxLifetime)
+	defer lifetime.Stop()
+	keepAlive := time.NewTicker(h.stream.KeepAlive)
+	defer keepAlive.Stop()
+
+	// a change inside MinInterval of the last update waits for this timer
+	var throttled <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+
+		case <-lifetime.C:
+			s.event("reconnect", gin.H{"retryAfterMs": h.stream.Retry.Milliseconds()})
+			return
+
+		case <-keepAlive.C:
+			s.comment("keep-alive")
+
+		case <-updates:
+			if throttled != nil {
+				continue
+			}
+			if wait := h.stream.MinInterval - time.Since(lastSent); wait > 0 {
+				throttled = time.After(wait)
+				continue
+			}
+			if !h.sendAvailability(ctx, s, eventID) {
+				return
+			}
+			lastSent = time.Now()
+
+		case <-throttled:
+			throttled = nil
+			if !h.sendAvailability(ctx, s, eventID) {
+				return
+			}
+			lastSent = time.Now()
+		}
+
+		if s.err != nil {
+			return
+		}
+	}
+}
+
+func (h *EventCountersHandler) readAvailability(ctx *gin.Context, eventID string) (event.Availability, error) {
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	return h.repo.Availability(cctx, eventID)
+}
+
+// sendAvailability reports whether the stream should stay open.
+func (h *EventCountersHandler) sendAvailability(ctx *gin.Context, s *sseWriter, eventID string) bool {
+	availability, err := h.readAvailability(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			s.event("closed", gin.H{"reason": "event_not_found"})
+			return false
+		}
+		// keep the stream; the next change reads again
+		slog.Default().WarnContext(ctx.Request.Context(), "availability.stream.read_failed", "event_id", eventID, "err", err)
+		return true
+	}
+
+	s.event("availability", availability)
+	return s.err == nil
+}
+
+// sseWriter writes server-sent events and flushes each one; the first write
+// error sticks.
+type sseWriter struct {
+	ctx *gin.Context
+	err error
+}
+
+func (s *sseWriter) write(format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	if _, s.err = fmt.Fprintf(s.ctx.Writer, format, args...); s.err == nil {
+		s.ctx.Writer.Flush()
+	}
+}
+
+func (s *sseWriter) retry(d time.Duration) {
+	s.write("retry: %d\n\n", d.Milliseconds())
+}
+
+func (s *sseWriter) comment(text string) {
+	s.write(": %s\n\n", text)
+}
+
+func (s *sseWriter) event(name string, v any) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.write("event: %s\ndata: %s\n\n", name, raw)
+}
@@ -0,0 +1,190 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/eventchanges"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeCountersRepo struct {
+	registered atomic.Int64
+}
+
+func (f *fakeCountersRepo) RecountEvent(ctx context.Context, eventID string) (event.CounterRepair, error) {
+	return event.CounterRepair{}, nil
+}
+
+func (f *fakeCountersRepo) Availability(ctx context.Context, eventID string) (event.Availability, error) {
+	if eventID == missingEventID {
+		return event.Availability{}, event.ErrNotFound
+	}
+	registered := int(f.registered.Load())
+	return event.Availability{EventID: eventID, Capacity: 10, RegisteredCount: registered, Remaining: 10 - registered}, nil
+}
+
+const missingEventID = "00000000-0000-0000-0000-00000000dead"
+
+// pipeWriter hands everything the handler writes to the test through a pipe,
+// the way a streaming client would see it.
+type pipeWriter struct {
+	header http.Header
+	pw     *io.PipeWriter
+	status int
+}
+
+func (w *pipeWriter) Header() http.Header         { return w.header }
+func (w *pipeWriter) WriteHeader(code int)        { w.status = code }
+func (w *pipeWriter) Write(b []byte) (int, error) { return w.pw.Write(b) }
+func (w *pipeWriter) Flush()                      {}
+
+type sseMessage struct {
+	Event string
+	Data  string
+}
+
+// openStream serves one stream request and returns its events as they arrive;
+// the channel closes when the handler returns.
+func openStream(t *testing.T, r *gin.Engine, path string) (<-chan sseMessage, context.CancelFunc) {
+	t.Helper()
+
+	pr, pw := io.Pipe()
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(reqCtx)
+	req.Header.Set("Accept", "text/event-stream")
+
+	go func() {
+		r.ServeHTTP(&pipeWriter{header: http.Header{}, pw: pw}, req)
+		_ = pw.Close()
+	}()
+
+	out := make(chan sseMessage, 16)
+	go func() {
+		defer close(out)
+		sc := bufio.NewScanner(pr)
+		var msg sseMessage
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				msg.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				msg.Data = strings.TrimPrefix(line, "data: ")
+			case line == "" && msg.Event != "":
+				out <- msg
+				msg = sseMessage{}
+			}
+		}
+	}()
+
+	t.Cleanup(cancel)
+	return out, cancel
+}
+
+func nextMessage(t *testing.T, msgs <-chan sseMessage, within time.Duration) sseMessage {
+	t.Helper()
+
+	select {
+	case m, ok := <-msgs:
+		if !ok {
+			t.Fatalf("stream closed early")
+		}
+		return m
+	case <-time.After(within):
+		t.Fatalf("no message within %s", within)
+	}
+	return sseMessage{}
+}
+
+func TestStreamAvailability_PushesChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeCountersRepo{}
+	hub := eventchanges.NewHub(nil)
+	h := handlers.NewEventCountersHandler(repo).
+		WithLiveAvailability(hub).
+		WithStreamLimits(handlers.StreamLimits{MinInterval: 10 * time.Millisecond})
+	r := setupRouter(http.MethodGet, "/events/:id/availability/stream", h.StreamAvailability)
+
+	eventID := newUUID()
+	msgs, _ := openStream(t, r, "/events/"+eventID+"/availability/stream")
+
+	first := nextMessage(t, msgs, time.Second)
+	var a event.Availability
+	if err := json.Unmarshal([]byte(first.Data), &a); err != nil || first.Event != "availability" || a.Remaining != 10 {
+		t.Fatalf("unexpected first message %+v", first)
+	}
+
+	// a registration lands
+	repo.registered.Store(3)
+	hub.Publish(eventID)
+
+	update := nextMessage(t, msgs, time.Second)
+	if err := json.Unmarshal([]byte(update.Data), &a); err != nil || update.Event != "availability" || a.Remaining != 7 {
+		t.Fatalf("unexpected update %+v", update)
+	}
+}
+
+func TestStreamAvailability_ThrottlesAndEndsWithReconnectHint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeCountersRepo{}
+	hub := eventchanges.NewHub(nil)
+	h := handlers.NewEventCountersHandler(repo).
+		WithLiveAvailability(hub).
+		WithStreamLimits(handlers.StreamLimits{MinInterval: 200 * time.Millisecond, MaxLifetime: 600 * time.Millisecond, Retry: 1500 * time.Millisecond})
+	r := setupRouter(http.MethodGet, "/events/:id/availability/stream", h.StreamAvailability)
+
+	eventID := newUUID()
+	msgs, _ := openStream(t, r, "/events/"+eventID+"/availability/stream")
+	nextMessage(t, msgs, time.Second)
+	opened := time.Now()
+
+	// a burst of changes becomes one update, no sooner than the interval
+	for i := 1; i <= 5; i++ {
+		repo.registered.Store(int64(i))
+		hub.Publish(eventID)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	update := nextMessage(t, msgs, time.Second)
+	var a event.Availability
+	if err := json.Unmarshal([]byte(update.Data), &a); err != nil || a.RegisteredCount != 5 {
+		t.Fatalf("expected the latest count in one update, got %+v", update)
+	}
+	if since := time.Since(opened); since < 150*time.Millisecond {
+		t.Fatalf("update sent %s after the previous one, expected throttling", since)
+	}
+
+	last := nextMessage(t, msgs, time.Second)
+	if last.Event != "reconnect" || !strings.Contains(last.Data, `"retryAfterMs":1500`) {
+		t.Fatalf("expected a reconnect hint, got %+v", last)
+	}
+	if _, ok := <-msgs; ok {
+		t.Fatalf("expected the stream to close after the reconnect hint")
+	}
+}
+
+func TestStreamAvailability_UnknownEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handlers.NewEventCountersHandler(&fakeCountersRepo{}).WithLiveAvailability(eventchanges.NewHub(nil))
+	r := setupRouter(http.MethodGet, "/events/:id/availability/stream", h.StreamAvailability)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+missingEventID+"/availability/stream", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d body=%s", w.Code, w.Body.String())
+	}
+}
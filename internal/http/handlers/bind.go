@@ -7,17 +7,16 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/geocoder89/eventhub/internal/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
-type FieldError struct {
-	Field   string `json:"field"`
-	Rule    string `json:"rule"`
-	Param   string `json:"param,omitempty"`
-	Message string `json:"message,omitempty"`
-}
+type FieldError = validation.FieldError
 
+// BindJSON decodes and validates the body into out, answering 400 on failure.
+// Bodies implementing validation.Validator have their cross-field problems
+// reported in the same details.fields array as the tag rule failures.
 func BindJSON(ctx *gin.Context, out interface{}) bool {
 	err := ctx.ShouldBindJSON(out)
 
@@ -27,9 +26,24 @@ func BindJSON(ctx *gin.Context, out interface{}) bool {
 		return false
 	}
 
+	if fields := crossFieldErrors(out); len(fields) > 0 {
+		RespondBadRequest(ctx, "Invalid request body", gin.H{"fields": fields})
+
+		return false
+	}
+
 	return true
 }
 
+func crossFieldErrors(out interface{}) []FieldError {
+	v, ok := out.(validation.Validator)
+	if !ok {
+		return nil
+	}
+
+	return v.Validate()
+}
+
 func parseBindError(err error, out interface{}) interface{} {
 	rootType := baseStructType(out)
 
@@ -52,6 +66,10 @@ func parseBindError(err error, out interface{}) interface{} {
 				Message: validationMessage(rule, param),
 			})
 		}
+
+		// the body decoded, so its cross-field rules can be checked as well
+		fields = append(fields, crossFieldErrors(out)...)
+
 		return gin.H{"fields": fields}
 	}
 
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// BindLimits caps a body before BindJSONWithLimits validates it, so a bulk
+// request is turned away before validation walks thousands of items. Zero
+// leaves a cap off.
+type BindLimits struct {
+	// MaxItems bounds every array in the body: the body itself when it is
+	// one, and arrays nested at any depth.
+	MaxItems int
+	// MaxBytes bounds the raw body. The MaxBodyBytes middleware's cap still
+	// applies on top, so raising it here has no effect.
+	MaxBytes int64
+}
+
+// itemLimitError: an array in the body holds more than BindLimits.MaxItems
+// items. Field is its JSON path, empty for the body itself.
+type itemLimitError struct {
+	Field string
+	Max   int
+	Items int
+}
+
+func (e *itemLimitError) Error() string {
+	return fmt.Sprintf("%s has %d items, at most %d allowed", e.fieldName(), e.Items, e.Max)
+}
+
+func (e *itemLimitError) fieldName() string {
+	if e.Field == "" {
+		return "body"
+	}
+	return e.Field
+}
+
+// bodyLimitError: the body is over BindLimits.MaxBytes or the middleware cap.
+type bodyLimitError struct {
+	Max int64
+}
+
+func (e *bodyLimitError) Error() string {
+	return fmt.Sprintf("request body is larger than %d bytes", e.Max)
+}
+
+// BindJSONWithLimits is BindJSON for bulk bodies: the body is decoded and
+// checked against limits before any validation runs. A body over a limit is
+// answered with a 400 naming the limit (details.maxBytes) or the offending
+// array and its cap (a details.fields entry with rule max_items).
+func BindJSONWithLimits(ctx *gin.Context, out interface{}, limits BindLimits) bool {
+	err := decodeJSONWithLimits(ctx.Request.Body, out, limits)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(out)
+	}
+
+	if err != nil {
+		var itemErr *itemLimitError
+		var bodyErr *bodyLimitError
+
+		switch {
+		case errors.As(err, &itemErr):
+			RespondBadRequest(ctx, "Invalid request body", gin.H{"fields": []FieldError{{
+				Field:   itemErr.fieldName(),
+				Rule:    "max_items",
+				Param:   strconv.Itoa(itemErr.Max),
+				Message: fmt.Sprintf("must have at most %d items", itemErr.Max),
+			}}})
+		case errors.As(err, &bodyErr):
+			RespondBadRequest(ctx, "Request body too large", gin.H{"maxBytes": bodyErr.Max})
+		default:
+			RespondBadRequest(ctx, "Invalid request body", parseBindError(err, out))
+		}
+		return false
+	}
+
+	if fields := crossFieldErrors(out); len(fields) > 0 {
+		RespondBadRequest(ctx, "Invalid request body", gin.H{"fields": fields})
+
+		return false
+	}
+
+	return true
+}
+
+// decodeJSONWithLimits reads body into out without validating it, returning
+// a *bodyLimitError or *itemLimitError for a body over limits.
+func decodeJSONWithLimits(body io.Reader, out interface{}, limits BindLimits) error {
+	if limits.MaxBytes > 0 {
+		body = io.LimitReader(body, limits.MaxBytes+1)
+	}
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return &bodyLimitError{Max: maxErr.Limit}
+		}
+		return err
+	}
+	if limits.MaxBytes > 0 && int64(len(raw)) > limits.MaxBytes {
+		return &bodyLimitError{Max: limits.MaxBytes}
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return err
+	}
+
+	if limits.MaxItems > 0 {
+		if itemErr := oversizedArray(reflect.ValueOf(out), "", limits.MaxItems); itemErr != nil {
+			return itemErr
+		}
+	}
+	return nil
+}
+
+// oversizedArray finds the first array under v, in field order, holding more
+// than max items. Byte slices are JSON strings, not arrays, and are skipped.
+func oversizedArray(v reflect.Value, path string, max int) *itemLimitError {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return oversizedArray(v.Elem(), path, max)
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		if v.Len() > max {
+			return &itemLimitError{Field: path, Max: max, Items: v.Len()}
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := oversizedArray(v.Index(i), path+"["+strconv.Itoa(i)+"]", max); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() || sf.Tag.Get("json") == "-" {
+				continue
+			}
+
+			fieldPath := path
+			if !sf.Anonymous || sf.Tag.Get("json") != "" {
+				fieldPath = joinJSONPath(path, jsonNameFromStructField(sf))
+			}
+			if err := oversizedArray(v.Field(i), fieldPath, max); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := oversizedArray(iter.Value(), joinJSONPath(path, fmt.Sprint(iter.Key().Interface())), max); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func joinJSONPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/geocoder89/eventhub/internal/domain/event"
 	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
@@ -129,3 +133,226 @@ func TestBindJSON_TypeMismatchUsesJSONFieldNames(t *testing.T) {
 		t.Fatalf("expected non-empty fields[0].message")
 	}
 }
+
+func bindRoute[T any](ctx *gin.Context) {
+	var req T
+	if !handlers.BindJSON(ctx, &req) {
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+func doBind(t *testing.T, handler gin.HandlerFunc, body string) (int, map[string]handlers.FieldError) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/bind", handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/bind", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	found := map[string]handlers.FieldError{}
+	if w.Code == http.StatusBadRequest {
+		var resp bindErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal error response: %v body=%s", err, w.Body.String())
+		}
+		for _, fieldErr := range resp.Error.Details.Fields {
+			found[fieldErr.Field+"/"+fieldErr.Param] = fieldErr
+		}
+	}
+	return w.Code, found
+}
+
+func TestBindJSON_CombinesTagAndCrossFieldErrors(t *testing.T) {
+	past := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	at := func(d time.Duration) string { return `"` + past.Add(d).Format(time.RFC3339) + `"` }
+
+	// title breaks its tag; startAt is past and the window is inverted
+	body := `{"title":"go","startAt":` + at(0) + `,"capacity":50,` +
+		`"registrationOpensAt":` + at(-time.Hour) + `,"registrationClosesAt":` + at(-2*time.Hour) + `}`
+
+	code, found := doBind(t, bindRoute[event.CreateEventRequest], body)
+	if code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", code)
+	}
+
+	want := map[string]string{
+		"title/3":  "min",
+		"startAt/": "future",
+		"registrationOpensAt/registrationClosesAt": "ltfield",
+	}
+	for key, rule := range want {
+		fieldErr, ok := found[key]
+		if !ok {
+			t.Fatalf("missing field error %q in %+v", key, found)
+		}
+		if fieldErr.Rule != rule || fieldErr.Message == "" {
+			t.Fatalf("field error %q: got %+v, want rule %q with a message", key, fieldErr, rule)
+		}
+	}
+	if len(found) != len(want) {
+		t.Fatalf("expected exactly %d field errors, got %+v", len(want), found)
+	}
+}
+
+func TestBindJSON_CrossFieldErrorsWithoutTagErrors(t *testing.T) {
+	startAt := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	at := func(d time.Duration) string { return `"` + startAt.Add(d).Format(time.RFC3339) + `"` }
+	body := `{"title":"Go Meetup","startAt":` + at(0) + `,"capacity":50,"registrationClosesAt":` + at(time.Second) + `}`
+
+	code, found := doBind(t, bindRoute[event.CreateEventRequest], body)
+	if code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", code)
+	}
+	if fieldErr, ok := found["registrationClosesAt/startAt"]; !ok || fieldErr.Rule != "ltefield" || len(found) != 1 {
+		t.Fatalf("expected only registrationClosesAt after startAt, got %+v", found)
+	}
+}
+
+func TestBindJSON_UpdateAllowsPastStart(t *testing.T) {
+	past := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	body := `{"title":"Go Meetup","startAt":"` + past + `","capacity":50}`
+
+	if code, found := doBind(t, bindRoute[event.UpdateEventRequest], body); code != http.StatusOK {
+		t.Fatalf("update with a past startAt got %d: %+v", code, found)
+	}
+	if code, _ := doBind(t, bindRoute[event.CreateEventRequest], body); code != http.StatusBadRequest {
+		t.Fatalf("create with a past startAt got %d, want 400", code)
+	}
+}
+
+type bulkTagsItem struct {
+	Name string   `json:"name" binding:"required"`
+	Tags []string `json:"tags"`
+}
+
+type bulkTagsRequest struct {
+	Items []bulkTagsItem `json:"items" binding:"required,dive"`
+}
+
+func doLimitedBind(t *testing.T, bodyCap int64, limits handlers.BindLimits, body string) (int, bindErrorResponse, map[string]any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	if bodyCap > 0 {
+		r.Use(middlewares.MaxBodyBytes(bodyCap))
+	}
+	r.POST("/bind", func(ctx *gin.Context) {
+		var req bulkTagsRequest
+		if !handlers.BindJSONWithLimits(ctx, &req, limits) {
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/bind", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp bindErrorResponse
+	var raw map[string]any
+	if w.Code == http.StatusBadRequest {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal error response: %v body=%s", err, w.Body.String())
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &raw)
+	}
+	return w.Code, resp, raw
+}
+
+func bulkItems(n int, tags int) string {
+	items := make([]string, n)
+	for i := range items {
+		tagList := make([]string, tags)
+		for j := range tagList {
+			tagList[j] = `"t` + strconv.Itoa(j) + `"`
+		}
+		items[i] = `{"name":"item` + strconv.Itoa(i) + `","tags":[` + strings.Join(tagList, ",") + `]}`
+	}
+	return `{"items":[` + strings.Join(items, ",") + `]}`
+}
+
+func TestBindJSONWithLimits_ArrayCap(t *testing.T) {
+	limits := handlers.BindLimits{MaxItems: 3}
+
+	if code, resp, _ := doLimitedBind(t, 0, limits, bulkItems(3, 1)); code != http.StatusOK {
+		t.Fatalf("3 items at a cap of 3 got %d: %+v", code, resp)
+	}
+
+	// item 0 has no name: the cap answers before validation would
+	body := `{"items":[{"tags":[]},{"name":"b"},{"name":"c"},{"name":"d"}]}`
+	code, resp, _ := doLimitedBind(t, 0, limits, body)
+	if code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", code)
+	}
+	if len(resp.Error.Details.Fields) != 1 {
+		t.Fatalf("expected exactly the cap violation, got %+v", resp.Error.Details.Fields)
+	}
+	fieldErr := resp.Error.Details.Fields[0]
+	if fieldErr.Field != "items" || fieldErr.Rule != "max_items" || fieldErr.Param != "3" || fieldErr.Message == "" {
+		t.Fatalf("unexpected field error %+v", fieldErr)
+	}
+}
+
+func TestBindJSONWithLimits_NestedArrays(t *testing.T) {
+	body := `{"items":[{"name":"a","tags":["x"]},{"name":"b","tags":["x","y","z"]}]}`
+
+	code, resp, _ := doLimitedBind(t, 0, handlers.BindLimits{MaxItems: 2}, body)
+	if code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", code)
+	}
+	if len(resp.Error.Details.Fields) != 1 {
+		t.Fatalf("expected one field error, got %+v", resp.Error.Details.Fields)
+	}
+	if fieldErr := resp.Error.Details.Fields[0]; fieldErr.Field != "items[1].tags" || fieldErr.Rule != "max_items" || fieldErr.Param != "2" {
+		t.Fatalf("expected the nested tags array named, got %+v", fieldErr)
+	}
+}
+
+func TestBindJSONWithLimits_ValidatesWithinLimits(t *testing.T) {
+	code, resp, _ := doLimitedBind(t, 0, handlers.BindLimits{MaxItems: 5}, `{"items":[{"tags":[]}]}`)
+	if code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", code)
+	}
+	if len(resp.Error.Details.Fields) != 1 || resp.Error.Details.Fields[0].Field != "items[0].name" || resp.Error.Details.Fields[0].Rule != "required" {
+		t.Fatalf("expected the usual validation errors, got %+v", resp.Error.Details.Fields)
+	}
+}
+
+func TestBindJSONWithLimits_BodySize(t *testing.T) {
+	body := bulkItems(20, 5)
+
+	tests := []struct {
+		name     string
+		bodyCap  int64
+		maxBytes int64
+		wantMax  float64
+	}{
+		{"route cap under the middleware", 1 << 20, 256, 256},
+		{"middleware cap under the route", 256, 1 << 20, 256},
+		{"middleware alone", 256, 0, 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, raw := doLimitedBind(t, tt.bodyCap, handlers.BindLimits{MaxBytes: tt.maxBytes}, body)
+			if code != http.StatusBadRequest {
+				t.Fatalf("got status %d, want 400", code)
+			}
+			details, _ := raw["error"].(map[string]any)["details"].(map[string]any)
+			if details["maxBytes"] != tt.wantMax {
+				t.Fatalf("expected maxBytes %v, got %+v", tt.wantMax, raw)
+			}
+		})
+	}
+
+	if code, resp, _ := doLimitedBind(t, 1<<20, handlers.BindLimits{MaxBytes: int64(len(body))}, body); code != http.StatusOK {
+		t.Fatalf("a body exactly at MaxBytes got %d: %+v", code, resp)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderBulkClamped is set to "true" when a bulk operation ran with a
+// smaller limit than the caller asked for.
+const HeaderBulkClamped = "X-Bulk-Clamped"
+
+// BulkLimits bound the ?limit of an admin bulk operation.
+type BulkLimits struct {
+	Default int
+	Max     int
+}
+
+var DefaultBulkLimits = BulkLimits{Default: 50, Max: 500}
+
+// BulkResult is the response every admin bulk operation returns, so callers
+// can tell when their limit was cut down to the cap.
+type BulkResult struct {
+	Requested int   `json:"requested"`
+	Applied   int   `json:"applied"`
+	Affected  int64 `json:"affected"`
+	Clamped   bool  `json:"clamped"`
+}
+
+// parseBulkLimit reads ?limit against limits and answers 400 itself when it
+// is not a positive number.
+func parseBulkLimit(ctx *gin.Context, limits BulkLimits) (BulkResult, bool) {
+	requested := limits.Default
+
+	if raw := ctx.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			RespondBadRequest(ctx, "invalid_request", "limit must be a positive number")
+			return BulkResult{}, false
+		}
+		requested = n
+	}
+
+	applied := min(requested, limits.Max)
+	return BulkResult{Requested: requested, Applied: applied, Clamped: applied < requested}, true
+}
+
+func respondBulk(ctx *gin.Context, status int, res BulkResult, extra gin.H) {
+	if res.Clamped {
+		ctx.Header(HeaderBulkClamped, "true")
+	}
+
+	body := gin.H{
+		"requested": res.Requested,
+		"applied":   res.Applied,
+		"affected":  res.Affected,
+		"clamped":   res.Clamped,
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	ctx.JSON(status, body)
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// DBTimeout derives the context for a handler's DB work from the request
+// context, bounded by the budget of the route's class. Either the budget
+// running out or the client going away cancels it. Routes mounted without
+// middlewares.DBTimeouts get the config defaults.
+func DBTimeout(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	timeouts := config.Config{}.DBTimeouts()
+	if v, ok := ctx.Get(middlewares.CtxDBTimeouts); ok {
+		if t, ok := v.(config.DBTimeouts); ok {
+			timeouts = t
+		}
+	}
+
+	return context.WithTimeout(ctx.Request.Context(), timeouts.For(middlewares.RouteClassFromContext(ctx)))
+}
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDBTimeout_BudgetPerRouteClass(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	timeouts := config.DBTimeouts{Handler: 1 * time.Second, Admin: 4 * time.Second, Export: 8 * time.Second}
+
+	r := gin.New()
+	r.Use(middlewares.DBTimeouts(timeouts))
+
+	budgets := map[string]time.Duration{}
+	record := func(c *gin.Context) {
+		cctx, cancel := handlers.DBTimeout(c)
+		defer cancel()
+		deadline, ok := cctx.Deadline()
+		if !ok {
+			t.Errorf("%s: expected a deadline", c.FullPath())
+		}
+		budgets[c.FullPath()] = time.Until(deadline)
+		c.Status(http.StatusNoContent)
+	}
+
+	r.GET("/public", record)
+	admin := r.Group("/admin", middlewares.RouteClass(config.RouteClassAdmin))
+	admin.GET("/jobs", record)
+	admin.GET("/import", middlewares.RouteClass(config.RouteClassExport), record)
+
+	for _, path := range []string{"/public", "/admin/jobs", "/admin/import"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	for path, want := range map[string]time.Duration{
+		"/public":       timeouts.Handler,
+		"/admin/jobs":   timeouts.Admin,
+		"/admin/import": timeouts.Export,
+	} {
+		got := budgets[path]
+		if got > want || got < want-time.Second/2 {
+			t.Fatalf("%s: expected a budget of ~%s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestDBTimeout_DefaultsAndRequestCancellation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var cctx context.Context
+	r := gin.New()
+	r.GET("/x", func(c *gin.Context) {
+		var cancel context.CancelFunc
+		cctx, cancel = handlers.DBTimeout(c)
+		defer cancel()
+
+		deadline, _ := cctx.Deadline()
+		if left := time.Until(deadline); left > 2*time.Second || left < time.Second {
+			t.Errorf("expected the 2s default budget, got %s", left)
+		}
+
+		// the client going away cancels DB work too
+		if !errors.Is(cctx.Err(), context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", cctx.Err())
+		}
+	})
+
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	cancelReq()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/x", nil).WithContext(reqCtx))
+	if cctx == nil {
+		t.Fatalf("handler did not run")
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+)
+
+type DebugHandler struct {
+	httpErrors *observability.ErrorRing
+	jobErrors  *observability.ErrorRing
+	instance   string
+}
+
+func NewDebugHandler(httpErrors, jobErrors *observability.ErrorRing, instance string) *DebugHandler {
+	return &DebugHandler{httpErrors: httpErrors, jobErrors: jobErrors, instance: instance}
+}
+
+// GET /admin/debug/recent-errors: the last 5xx responses and job failures
+// seen by this instance only; other replicas keep their own.
+func (h *DebugHandler) RecentErrors(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"instance":    h.instance,
+		"generatedAt": time.Now().UTC(),
+		"http":        h.httpErrors.Snapshot(),
+		"jobs":        h.jobErrors.Snapshot(),
+	})
+}
@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecentErrors_RecordsRedacted5xx(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobErrors := observability.NewErrorRing(10)
+	jobErrors.Add(observability.ErrorEvent{JobType: "registration.confirmation", JobID: "job-1", Class: "error", Error: "mailbox grace@example.com unavailable"})
+
+	r := gin.New()
+	r.POST("/boom", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("notify ada@example.com: connection refused"))
+		handlers.RespondInternal(ctx, "Could not register for event")
+	})
+	r.GET("/admin/debug/recent-errors", handlers.NewDebugHandler(observability.RecentHTTPErrors, jobErrors, "api-1").RecentErrors)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/boom", nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/debug/recent-errors", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected an uncacheable 200, got %d cache-control=%q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	if strings.Contains(w.Body.String(), "@example.com") {
+		t.Fatalf("email leaked into recent errors: %s", w.Body.String())
+	}
+
+	var got struct {
+		Instance string                     `json:"instance"`
+		HTTP     []observability.ErrorEvent `json:"http"`
+		Jobs     []observability.ErrorEvent `json:"jobs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Instance != "api-1" || len(got.Jobs) != 1 || len(got.HTTP) == 0 {
+		t.Fatalf("unexpected payload %s", w.Body.String())
+	}
+	last := got.HTTP[0]
+	if last.Route != "POST /boom" || last.Status != http.StatusInternalServerError || last.Code != "internal_error" {
+		t.Fatalf("unexpected http error %+v", last)
+	}
+	if !strings.Contains(last.Error, "connection refused") || !strings.Contains(last.Error, "[redacted-email]") {
+		t.Fatalf("expected the attached error, redacted, got %q", last.Error)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/cache"
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+)
+
+const descriptionHTMLSource = "Bring <snacks> & friends\n\nDoors at 6"
+const descriptionHTMLRendered = "<p>Bring &lt;snacks&gt; &amp; friends</p><p>Doors at 6</p>"
+
+// storedEvent is an event as the repo reads it, HTML still compressed.
+func storedEvent(t *testing.T, id string) event.Event {
+	t.Helper()
+	gz, err := event.GzipDescriptionHTML(descriptionHTMLSource)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	now := time.Now().UTC()
+	return event.Event{ID: id, Title: "Meetup", Description: descriptionHTMLSource, StartAt: now, CreatedAt: now, UpdatedAt: now, DescriptionHTMLGzip: gz}
+}
+
+func TestListEvents_DescriptionHTMLOnlyWhenIncluded(t *testing.T) {
+	id := newUUID()
+	var asked []bool
+	repo := &fakeEventsRepo{}
+	repo.listCursorFn = func(ctx context.Context, filter event.ListEventsFilter, afterStartAt time.Time, afterID string) ([]event.Event, *string, bool, error) {
+		asked = append(asked, filter.IncludeDescriptionHTML)
+		e := storedEvent(t, id)
+		if !filter.IncludeDescriptionHTML {
+			e.DescriptionHTMLGzip = nil
+		}
+		return []event.Event{e}, nil, false, nil
+	}
+
+	h := handlers.NewEventsHandlerWithCache(repo, cache.New(30*time.Second))
+	r := setupRouter(http.MethodGet, "/events", h.ListEvents)
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events"+query, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	plain := get("?limit=20", "")
+	if plain.Code != http.StatusOK {
+		t.Fatalf("plain list got %d body=%s", plain.Code, plain.Body.String())
+	}
+	var plainBody struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(plain.Body.Bytes(), &plainBody); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := plainBody.Items[0]["descriptionHtml"]; ok {
+		t.Fatalf("expected no descriptionHtml without include, got %s", plain.Body.String())
+	}
+
+	// the cached plain page must not answer the include request, and its
+	// ETag must not validate it either
+	included := get("?limit=20&include=descriptionHtml", plain.Header().Get("ETag"))
+	if included.Code != http.StatusOK {
+		t.Fatalf("include list got %d body=%s", included.Code, included.Body.String())
+	}
+	var includedBody struct {
+		Items []event.Event `json:"items"`
+	}
+	if err := json.Unmarshal(included.Body.Bytes(), &includedBody); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := includedBody.Items[0].DescriptionHTML; got == nil || *got != descriptionHTMLRendered {
+		t.Fatalf("expected the decompressed HTML, got %v", got)
+	}
+	if included.Header().Get("ETag") == plain.Header().Get("ETag") {
+		t.Fatal("expected the include response to carry its own ETag")
+	}
+	if len(asked) != 2 || asked[0] || !asked[1] {
+		t.Fatalf("expected the repo asked for the HTML only on the include request, got %v", asked)
+	}
+
+	if w := get("?limit=20&include=descriptionHtml", included.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for the include ETag, got %d", w.Code)
+	}
+}
+
+func TestListEvents_UnknownIncludeRejected(t *testing.T) {
+	h := handlers.NewEventsHandler(&fakeEventsRepo{})
+	r := setupRouter(http.MethodGet, "/events", h.ListEvents)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?include=descriptionHtml,attendees", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetEventById_DecompressesDescriptionHTML(t *testing.T) {
+	id := newUUID()
+	stored := storedEvent(t, id)
+	repo := &fakeEventsRepo{}
+	repo.getFn = func(ctx context.Context, _ string) (event.Event, error) { return stored, nil }
+
+	h := handlers.NewEventsHandler(repo)
+	r := setupRouter(http.MethodGet, "/events/:id", h.GetEventById)
+
+	detail := func() event.Event {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+id, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got %d body=%s", w.Code, w.Body.String())
+		}
+		var e event.Event
+		if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return e
+	}
+
+	if got := detail().DescriptionHTML; got == nil || *got != descriptionHTMLRendered {
+		t.Fatalf("expected the decompressed HTML, got %v", got)
+	}
+
+	// rows from before the column, and unreadable copies, render afresh
+	for _, gz := range [][]byte{nil, []byte("not gzip")} {
+		stored.DescriptionHTMLGzip = gz
+		if got := detail().DescriptionHTML; got == nil || *got != descriptionHTMLRendered {
+			t.Fatalf("expected HTML rendered from the description for %q, got %v", gz, got)
+		}
+	}
+}
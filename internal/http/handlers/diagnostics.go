@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/buildinfo"
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/configdrift"
+	"github.com/gin-gonic/gin"
+)
+
+// PoolStats is the slice of pgxpool.Stat diagnostics reports.
+type PoolStats struct {
+	MaxConns        int32 `json:"maxConns"`
+	TotalConns      int32 `json:"totalConns"`
+	IdleConns       int32 `json:"idleConns"`
+	AcquiredConns   int32 `json:"acquiredConns"`
+	AcquireCount    int64 `json:"acquireCount"`
+	EmptyAcquires   int64 `json:"emptyAcquireCount"`
+	CanceledAcquire int64 `json:"canceledAcquireCount"`
+}
+
+// ServiceInstancesReader lists the processes that announced their config
+// fingerprint recently.
+type ServiceInstancesReader interface {
+	ListLive(ctx context.Context, since time.Time) ([]configdrift.Instance, error)
+}
+
+type DiagnosticsHandler struct {
+	cfg      config.Config
+	instance string
+	started  time.Time
+
+	poolStats func() PoolStats
+	caches    map[string]func() int
+	features  map[string]bool
+	instances ServiceInstancesReader
+}
+
+func NewDiagnosticsHandler(cfg config.Config, instance string) *DiagnosticsHandler {
+	return &DiagnosticsHandler{cfg: cfg, instance: instance, started: time.Now().UTC(), caches: map[string]func() int{}}
+}
+
+// WithPoolStats reports the DB pool through stats; without it the pool is
+// left out of the response.
+func (h *DiagnosticsHandler) WithPoolStats(stats func() PoolStats) *DiagnosticsHandler {
+	h.poolStats = stats
+	return h
+}
+
+// WithCache reports the number of entries in a named in-process cache.
+func (h *DiagnosticsHandler) WithCache(name string, size func() int) *DiagnosticsHandler {
+	h.caches[name] = size
+	return h
+}
+
+// WithFeatures sets the on/off state of the optional features this process
+// was wired with.
+func (h *DiagnosticsHandler) WithFeatures(features map[string]bool) *DiagnosticsHandler {
+	h.features = features
+	return h
+}
+
+// WithConfigDrift compares the live instances' config fingerprints; without
+// it only this instance's fingerprint is reported.
+func (h *DiagnosticsHandler) WithConfigDrift(instances ServiceInstancesReader) *DiagnosticsHandler {
+	h.instances = instances
+	return h
+}
+
+// GET /admin/diagnostics: build, redacted config, runtime and pool state of
+// this instance only; other replicas answer for themselves. The one
+// cross-instance part is configDrift, read from their announcements.
+func (h *DiagnosticsHandler) Get(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	caches := make(map[string]int, len(h.caches))
+	for name, size := range h.caches {
+		caches[name] = size()
+	}
+
+	resp := gin.H{
+		"instance":    h.instance,
+		"generatedAt": time.Now().UTC(),
+		"startedAt":   h.started,
+		"uptime":      time.Since(h.started).Round(time.Second).String(),
+		"build":       buildinfo.Get(),
+		"config":      h.cfg.Snapshot(),
+		"runtime": gin.H{
+			"goroutines":     runtime.NumGoroutine(),
+			"gomaxprocs":     runtime.GOMAXPROCS(0),
+			"heapAllocBytes": mem.HeapAlloc,
+			"heapInuseBytes": mem.HeapInuse,
+			"heapObjects":    mem.HeapObjects,
+			"sysBytes":       mem.Sys,
+			"numGC":          mem.NumGC,
+		},
+		"caches":      caches,
+		"features":    h.features,
+		"fingerprint": h.cfg.Fingerprint(),
+	}
+	if h.poolStats != nil {
+		resp["pool"] = h.poolStats()
+	}
+	if h.instances != nil {
+		cctx, cancel := DBTimeout(ctx)
+		live, err := h.instances.ListLive(cctx, time.Now().Add(-configdrift.LiveWindow))
+		cancel()
+		if err != nil {
+			// the rest of the report is still worth having
+			slog.Default().ErrorContext(ctx.Request.Context(), "diagnostics.config_drift_failed", "err", err)
+			resp["configDrift"] = gin.H{"error": "could not read service instances"}
+		} else {
+			resp["configDrift"] = configdrift.Check(live)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,150 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/buildinfo"
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/configdrift"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDiagnostics_ReportsBuildRuntimeAndRedactedConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{
+		Env:           "prod",
+		DBURL:         "postgres://eventhub:db-pass-123@db:5432/eventhub",
+		JWTSecret:     "jwt-secret-123",
+		AdminPassword: "admin-pass-123",
+		RedisPassword: "redis-pass-123",
+	}
+	h := handlers.NewDiagnosticsHandler(cfg, "api-1").
+		WithPoolStats(func() handlers.PoolStats { return handlers.PoolStats{MaxConns: 4, AcquiredConns: 1} }).
+		WithCache("events", func() int { return 7 }).
+		WithFeatures(map[string]bool{"liveAvailability": true})
+
+	r := gin.New()
+	r.GET("/admin/diagnostics", h.Get)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/diagnostics", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected an uncacheable 200, got %d cache-control=%q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	for _, secret := range []string{"db-pass-123", "jwt-secret-123", "admin-pass-123", "redis-pass-123"} {
+		if strings.Contains(w.Body.String(), secret) {
+			t.Fatalf("diagnostics leaks %q: %s", secret, w.Body.String())
+		}
+	}
+
+	var got struct {
+		Instance string         `json:"instance"`
+		Build    buildinfo.Info `json:"build"`
+		Runtime  struct {
+			Goroutines int `json:"goroutines"`
+		} `json:"runtime"`
+		Pool     handlers.PoolStats `json:"pool"`
+		Caches   map[string]int     `json:"caches"`
+		Features map[string]bool    `json:"features"`
+		Config   config.Snapshot    `json:"config"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Instance != "api-1" || got.Build.Version == "" || got.Build.GoVersion == "" {
+		t.Fatalf("expected instance and build metadata, got %s", w.Body.String())
+	}
+	if got.Runtime.Goroutines == 0 || got.Pool.MaxConns != 4 || got.Caches["events"] != 7 || !got.Features["liveAvailability"] {
+		t.Fatalf("unexpected diagnostics %s", w.Body.String())
+	}
+	if got.Config.Fields["Env"] != "prod" || got.Config.Fields["JWTSecret"] != "[redacted]" {
+		t.Fatalf("expected the redacted config snapshot, got %v", got.Config.Fields)
+	}
+}
+
+type fakeServiceInstances struct {
+	instances []configdrift.Instance
+	err       error
+	since     time.Time
+}
+
+func (f *fakeServiceInstances) ListLive(ctx context.Context, since time.Time) ([]configdrift.Instance, error) {
+	f.since = since
+	return f.instances, f.err
+}
+
+func TestDiagnostics_ReportsConfigDrift(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{DBURL: "postgres://eventhub:db-pass-123@db:5432/eventhub", JWTSecret: "jwt-secret-123", ConfigFingerprintKey: "fp-key"}
+	fp := cfg.Fingerprint()
+	other := cfg
+	other.JWTSecret = "jwt-secret-456"
+	otherFP := other.Fingerprint()
+
+	tests := []struct {
+		name        string
+		instances   *fakeServiceInstances
+		wantDrifted bool
+		wantParts   []string
+		wantError   bool
+	}{
+		{name: "agree", instances: &fakeServiceInstances{instances: []configdrift.Instance{
+			{ID: "api-1", Service: "api", Fingerprint: fp.Hash, Parts: fp.Parts},
+			{ID: "worker-1", Service: "worker", Fingerprint: fp.Hash, Parts: fp.Parts},
+		}}},
+		{name: "secret drifted", instances: &fakeServiceInstances{instances: []configdrift.Instance{
+			{ID: "api-1", Service: "api", Fingerprint: fp.Hash, Parts: fp.Parts},
+			{ID: "worker-1", Service: "worker", Fingerprint: otherFP.Hash, Parts: otherFP.Parts},
+		}}, wantDrifted: true, wantParts: []string{"secrets"}},
+		{name: "read fails", instances: &fakeServiceInstances{err: errors.New("db down")}, wantError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := handlers.NewDiagnosticsHandler(cfg, "api-1").WithConfigDrift(tc.instances)
+
+			r := gin.New()
+			r.GET("/admin/diagnostics", h.Get)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/diagnostics", nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200 even when drift cannot be read, got %d", w.Code)
+			}
+			if strings.Contains(w.Body.String(), "jwt-secret-456") {
+				t.Fatalf("diagnostics leaks a secret: %s", w.Body.String())
+			}
+			if since := time.Since(tc.instances.since); since < configdrift.LiveWindow-time.Minute || since > configdrift.LiveWindow+time.Minute {
+				t.Fatalf("expected instances seen within the live window, asked since %v ago", since)
+			}
+
+			var got struct {
+				Fingerprint config.Fingerprint `json:"fingerprint"`
+				ConfigDrift struct {
+					Drifted bool     `json:"drifted"`
+					Parts   []string `json:"parts"`
+					Error   string   `json:"error"`
+				} `json:"configDrift"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Fingerprint.Hash != fp.Hash {
+				t.Fatalf("expected this instance's fingerprint %s, got %s", fp.Hash, got.Fingerprint.Hash)
+			}
+			if got.ConfigDrift.Drifted != tc.wantDrifted || strings.Join(got.ConfigDrift.Parts, ",") != strings.Join(tc.wantParts, ",") || (got.ConfigDrift.Error != "") != tc.wantError {
+				t.Fatalf("unexpected configDrift %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// EditLockTokenHeader carries the holder's lock token on an event update.
+const EditLockTokenHeader = "X-Edit-Lock-Token"
+
+type EditLockStore interface {
+	Acquire(ctx context.Context, eventID, holderID string, now time.Time, ttl time.Duration) (event.EditLock, error)
+	Get(ctx context.Context, eventID string, now time.Time) (event.EditLock, error)
+	Release(ctx context.Context, eventID, holderID string, force bool, now time.Time) (event.EditLock, error)
+}
+
+// EditLocksHandler serves the advisory locks admins take while editing an
+// event, so a second editor hears about the first before typing rather than
+// after. Locks last event.EditLockTTL and are renewed by acquiring again.
+type EditLocksHandler struct {
+	locks EditLockStore
+	now   func() time.Time
+}
+
+func NewEditLocksHandler(locks EditLockStore) *EditLocksHandler {
+	return &EditLocksHandler{locks: locks, now: time.Now}
+}
+
+// WithClock replaces time.Now for lock expiry.
+func (h *EditLocksHandler) WithClock(now func() time.Time) *EditLocksHandler {
+	h.now = now
+	return h
+}
+
+// Acquire handles POST /events/:id/edit-lock: takes or renews the caller's
+// lock and returns it with its token.
+func (h *EditLocksHandler) Acquire(ctx *gin.Context) {
+	eventID, userID, ok := h.request(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	l, err := h.locks.Acquire(cctx, eventID, userID, h.now().UTC(), event.EditLockTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, event.ErrEditLockHeld):
+			respondEditLockHeld(ctx, l)
+		case errors.Is(err, event.ErrNotFound):
+			RespondNotFound(ctx, "Event not found")
+		default:
+			RespondInternal(ctx, "Could not acquire edit lock")
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, l)
+}
+
+// Get handles GET /events/:id/edit-lock: who, if anyone, is editing the
+// event. Only the holder sees the token.
+func (h *EditLocksHandler) Get(ctx *gin.Context) {
+	eventID, userID, ok := h.request(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	l, err := h.locks.Get(cctx, eventID, h.now().UTC())
+	if err != nil {
+		if errors.Is(err, event.ErrEditLockNotFound) {
+			ctx.JSON(http.StatusOK, gin.H{"locked": false, "lock": nil})
+			return
+		}
+		RespondInternal(ctx, "Could not load edit lock")
+		return
+	}
+	if l.HolderID != userID {
+		l = l.Public()
+	}
+	ctx.JSON(http.StatusOK, gin.H{"locked": true, "lock": l})
+}
+
+// Release handles DELETE /events/:id/edit-lock. Releasing someone else's
+// live lock takes ?force=true; with no lock there is nothing to do.
+func (h *EditLocksHandler) Release(ctx *gin.Context) {
+	eventID, userID, ok := h.request(ctx)
+	if !ok {
+		return
+	}
+
+	force, err := parseBoolQuery(ctx, "force")
+	if err != nil {
+		RespondBadRequest(ctx, "invalid_query", "force must be true or false")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	l, err := h.locks.Release(cctx, eventID, userID, force, h.now().UTC())
+	if err != nil {
+		if errors.Is(err, event.ErrEditLockHeld) {
+			respondEditLockHeld(ctx, l)
+			return
+		}
+		RespondInternal(ctx, "Could not release edit lock")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// allowUpdate warns an update of eventID off with 409 edit_lock_held while
+// someone else holds a live lock on it, unless the request carries that
+// lock's token or ?force=true. The lock is advisory, so an update goes
+// ahead when the lock can't be read.
+func (h *EditLocksHandler) allowUpdate(ctx *gin.Context, cctx context.Context, eventID string) bool {
+	if h == nil || h.locks == nil {
+		return true
+	}
+
+	force, err := parseBoolQuery(ctx, "force")
+	if err != nil {
+		RespondBadRequest(ctx, "invalid_query", "force must be true or false")
+		return false
+	}
+
+	l, err := h.locks.Get(cctx, eventID, h.now().UTC())
+	if err != nil {
+		if !errors.Is(err, event.ErrEditLockNotFound) {
+			slog.Default().WarnContext(cctx, "event.edit_lock_check_failed",
+				"request_id", requestIDFrom(ctx),
+				"event_id", eventID,
+				"err", err,
+			)
+		}
+		return true
+	}
+
+	userID, _ := middlewares.UserIDFromContext(ctx)
+	if l.HolderID == userID || ctx.GetHeader(EditLockTokenHeader) == l.Token {
+		return true
+	}
+	if force {
+		slog.Default().InfoContext(cctx, "event.edit_lock_overridden",
+			"request_id", requestIDFrom(ctx),
+			"event_id", eventID,
+			"holder_id", l.HolderID,
+			"user_id", userID,
+		)
+		return true
+	}
+
+	respondEditLockHeld(ctx, l)
+	return false
+}
+
+func (h *EditLocksHandler) request(ctx *gin.Context) (eventID, userID string, ok bool) {
+	if h.locks == nil {
+		RespondError(ctx, http.StatusServiceUnavailable, "edit_locks_unavailable", "Edit locks are not enabled", nil)
+		return "", "", false
+	}
+
+	userID, ok = middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return "", "", false
+	}
+
+	eventID = ctx.Param("id")
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return "", "", false
+	}
+	return eventID, userID, true
+}
+
+func respondEditLockHeld(ctx *gin.Context, l event.EditLock) {
+	RespondError(ctx, http.StatusConflict, "edit_lock_held",
+		"Someone else is editing this event.",
+		gin.H{"holder": l.Public()})
+}
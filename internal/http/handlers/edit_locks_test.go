@@ -0,0 +1,264 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeEditLocks keeps one lock per event and expires it by the now it is
+// given, as the repo does.
+type fakeEditLocks struct {
+	locks   map[string]event.EditLock
+	tokens  int
+	missing map[string]bool
+}
+
+func newFakeEditLocks() *fakeEditLocks {
+	return &fakeEditLocks{locks: map[string]event.EditLock{}, missing: map[string]bool{}}
+}
+
+func (f *fakeEditLocks) Acquire(ctx context.Context, eventID, holderID string, now time.Time, ttl time.Duration) (event.EditLock, error) {
+	if f.missing[eventID] {
+		return event.EditLock{}, event.ErrNotFound
+	}
+	l, ok := f.locks[eventID]
+	switch {
+	case ok && l.HolderID == holderID:
+		if !l.Live(now) {
+			l.AcquiredAt = now
+		}
+	case ok && l.Live(now):
+		return l, event.ErrEditLockHeld
+	default:
+		f.tokens++
+		l = event.EditLock{EventID: eventID, HolderID: holderID, Token: fmt.Sprintf("token-%d", f.tokens), AcquiredAt: now}
+	}
+	l.ExpiresAt = now.Add(ttl)
+	f.locks[eventID] = l
+	return l, nil
+}
+
+func (f *fakeEditLocks) Get(ctx context.Context, eventID string, now time.Time) (event.EditLock, error) {
+	l, ok := f.locks[eventID]
+	if !ok || !l.Live(now) {
+		delete(f.locks, eventID)
+		return event.EditLock{}, event.ErrEditLockNotFound
+	}
+	return l, nil
+}
+
+func (f *fakeEditLocks) Release(ctx context.Context, eventID, holderID string, force bool, now time.Time) (event.EditLock, error) {
+	l, err := f.Get(ctx, eventID, now)
+	if err != nil {
+		return event.EditLock{}, nil
+	}
+	if l.HolderID != holderID && !force {
+		return l, event.ErrEditLockHeld
+	}
+	delete(f.locks, eventID)
+	return event.EditLock{}, nil
+}
+
+type editLockRig struct {
+	locks   *fakeEditLocks
+	clock   time.Time
+	updates int
+}
+
+func newEditLockRig() *editLockRig {
+	return &editLockRig{locks: newFakeEditLocks(), clock: time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (rig *editLockRig) do(t *testing.T, method, path, userID string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	locks := handlers.NewEditLocksHandler(rig.locks).WithClock(func() time.Time { return rig.clock })
+	events := handlers.NewEventsHandler(&fakeEventsRepo{
+		updateFn: func(ctx context.Context, id string, req event.UpdateEventRequest) (event.Event, error) {
+			rig.updates++
+			return event.Event{ID: id, Title: req.Title}, nil
+		},
+	}).WithEditLocks(locks)
+
+	r := gin.New()
+	auth := withUser(userID, "admin")
+	r.POST("/events/:id/edit-lock", auth, locks.Acquire)
+	r.GET("/events/:id/edit-lock", auth, locks.Get)
+	r.DELETE("/events/:id/edit-lock", auth, locks.Release)
+	r.PUT("/events/:id", auth, events.UpdateEvent)
+
+	var body *strings.Reader
+	if method == http.MethodPut {
+		body = strings.NewReader(`{"title":"Go Meetup","startAt":"2030-01-01T18:00:00Z","capacity":50}`)
+	} else {
+		body = strings.NewReader("")
+	}
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeEditLock(t *testing.T, w *httptest.ResponseRecorder) event.EditLock {
+	t.Helper()
+	var l event.EditLock
+	if err := json.Unmarshal(w.Body.Bytes(), &l); err != nil {
+		t.Fatalf("decode lock: %v (%s)", err, w.Body.String())
+	}
+	return l
+}
+
+func TestEditLock_AcquireAndRenew(t *testing.T) {
+	rig := newEditLockRig()
+	eventID, alice := newUUID(), newUUID()
+
+	w := rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", alice, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	first := decodeEditLock(t, w)
+	if first.HolderID != alice || first.Token == "" || !first.ExpiresAt.Equal(rig.clock.Add(event.EditLockTTL)) {
+		t.Fatalf("unexpected lock %+v", first)
+	}
+
+	rig.clock = rig.clock.Add(4 * time.Minute)
+	renewed := decodeEditLock(t, rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", alice, nil))
+	if renewed.Token != first.Token || !renewed.AcquiredAt.Equal(first.AcquiredAt) {
+		t.Fatalf("expected the renewal to keep the lock, got %+v after %+v", renewed, first)
+	}
+	if !renewed.ExpiresAt.Equal(rig.clock.Add(event.EditLockTTL)) {
+		t.Fatalf("expected expiry pushed to %v, got %v", rig.clock.Add(event.EditLockTTL), renewed.ExpiresAt)
+	}
+
+	var got struct {
+		Locked bool            `json:"locked"`
+		Lock   *event.EditLock `json:"lock"`
+	}
+	w = rig.do(t, http.MethodGet, "/events/"+eventID+"/edit-lock", newUUID(), nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if !got.Locked || got.Lock == nil || got.Lock.HolderID != alice || got.Lock.Token != "" {
+		t.Fatalf("expected the holder without the token, got %s", w.Body.String())
+	}
+
+	w = rig.do(t, http.MethodDelete, "/events/"+eventID+"/edit-lock", alice, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	w = rig.do(t, http.MethodGet, "/events/"+eventID+"/edit-lock", alice, nil)
+	if !strings.Contains(w.Body.String(), `"locked":false`) {
+		t.Fatalf("expected no lock after release, got %s", w.Body.String())
+	}
+}
+
+func TestEditLock_Conflict(t *testing.T) {
+	rig := newEditLockRig()
+	eventID, alice, bob := newUUID(), newUUID(), newUUID()
+
+	rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", alice, nil)
+
+	var resp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				Holder event.EditLock `json:"holder"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	for _, method := range []string{http.MethodPost, http.MethodDelete, http.MethodPut} {
+		path := "/events/" + eventID + "/edit-lock"
+		if method == http.MethodPut {
+			path = "/events/" + eventID
+		}
+		w := rig.do(t, method, path, bob, nil)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("%s: expected 409, got %d: %s", method, w.Code, w.Body.String())
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Error.Code != "edit_lock_held" || resp.Error.Details.Holder.HolderID != alice || resp.Error.Details.Holder.Token != "" {
+			t.Fatalf("%s: expected edit_lock_held naming the holder, got %s", method, w.Body.String())
+		}
+	}
+	if rig.updates != 0 {
+		t.Fatalf("expected no update, got %d", rig.updates)
+	}
+
+	// the holder updates freely, by identity or by token
+	if w := rig.do(t, http.MethodPut, "/events/"+eventID, alice, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected the holder's update through, got %d: %s", w.Code, w.Body.String())
+	}
+	token := rig.locks.locks[eventID].Token
+	w := rig.do(t, http.MethodPut, "/events/"+eventID, bob, map[string]string{handlers.EditLockTokenHeader: token})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the lock token to pass, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEditLock_Expiry(t *testing.T) {
+	rig := newEditLockRig()
+	eventID, alice, bob := newUUID(), newUUID(), newUUID()
+
+	first := decodeEditLock(t, rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", alice, nil))
+
+	rig.clock = rig.clock.Add(event.EditLockTTL)
+	if w := rig.do(t, http.MethodPut, "/events/"+eventID, bob, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected an expired lock ignored, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", bob, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected bob to take over the expired lock, got %d: %s", w.Code, w.Body.String())
+	}
+	taken := decodeEditLock(t, w)
+	if taken.HolderID != bob || taken.Token == first.Token {
+		t.Fatalf("expected a new lock for bob, got %+v", taken)
+	}
+}
+
+func TestEditLock_Force(t *testing.T) {
+	rig := newEditLockRig()
+	eventID, alice, bob := newUUID(), newUUID(), newUUID()
+
+	rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", alice, nil)
+
+	if w := rig.do(t, http.MethodPut, "/events/"+eventID+"?force=true", bob, nil); w.Code != http.StatusOK || rig.updates != 1 {
+		t.Fatalf("expected the forced update through, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := rig.do(t, http.MethodPut, "/events/"+eventID+"?force=maybe", bob, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad force, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := rig.do(t, http.MethodDelete, "/events/"+eventID+"/edit-lock?force=true", bob, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected the forced release, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, held := rig.locks.locks[eventID]; held {
+		t.Fatal("expected the lock gone")
+	}
+}
+
+func TestEditLock_UnknownEvent(t *testing.T) {
+	rig := newEditLockRig()
+	eventID := newUUID()
+	rig.locks.missing[eventID] = true
+
+	if w := rig.do(t, http.MethodPost, "/events/"+eventID+"/edit-lock", newUUID(), nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := rig.do(t, http.MethodPost, "/events/nope/edit-lock", newUUID(), nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
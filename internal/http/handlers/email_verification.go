@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerifier records that a user proved they own an address.
+type EmailVerifier interface {
+	MarkEmailVerified(ctx context.Context, id, email string) (bool, error)
+	EmailVerified(ctx context.Context, id string) (bool, error)
+}
+
+// EmailVerificationJobs queues user.email_verification jobs.
+type EmailVerificationJobs interface {
+	CreateOrGet(ctx context.Context, req job.CreateRequest) (j job.Job, created bool, err error)
+}
+
+type EmailVerificationHandler struct {
+	users  EmailVerifier
+	jobs   EmailVerificationJobs
+	signer *emailverify.Signer
+}
+
+func NewEmailVerificationHandler(users EmailVerifier, jobsRepo EmailVerificationJobs, signer *emailverify.Signer) *EmailVerificationHandler {
+	return &EmailVerificationHandler{users: users, jobs: jobsRepo, signer: signer}
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required,max=1024"`
+}
+
+// Verify handles POST /auth/verify-email: the signed token from the
+// verification email, so it works without a bearer token. A token for an
+// address the user has since changed is invalid.
+func (h *EmailVerificationHandler) Verify(ctx *gin.Context) {
+	var req VerifyEmailRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	claims, err := h.signer.Verify(req.Token)
+	if err != nil {
+		if errors.Is(err, emailverify.ErrExpired) {
+			RespondUnAuthorized(ctx, "token_expired", "verification link has expired")
+			return
+		}
+		RespondUnAuthorized(ctx, "invalid_token", "verification link is invalid")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	ok, err := h.users.MarkEmailVerified(cctx, claims.UserID, claims.Email)
+	if err != nil {
+		_ = ctx.Error(err)
+		RespondInternal(ctx, "Could not verify email")
+		return
+	}
+	if !ok {
+		RespondUnAuthorized(ctx, "invalid_token", "verification link is invalid")
+		return
+	}
+
+	slog.Default().InfoContext(cctx, "users.email_verified",
+		"request_id", requestIDFrom(ctx),
+		"user_id", claims.UserID,
+	)
+
+	ctx.JSON(http.StatusOK, gin.H{"verified": true})
+}
+
+// Request handles POST /me/email-verification: emails the caller a new
+// verification link. Asking again within the hour returns the job already
+// queued.
+func (h *EmailVerificationHandler) Request(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	verified, err := h.users.EmailVerified(cctx, userID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+			return
+		}
+		_ = ctx.Error(err)
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
+	if verified {
+		RespondError(ctx, http.StatusConflict, "already_verified", "Email is already verified.", nil)
+		return
+	}
+
+	j, created, err := enqueueEmailVerification(cctx, h.jobs, userID)
+	if err != nil {
+		_ = ctx.Error(err)
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
+
+	ctx.Set(middlewares.CtxJobID, j.ID)
+	slog.Default().InfoContext(cctx, "job.enqueue",
+		"request_id", requestIDFrom(ctx),
+		"job_id", j.ID,
+		"job_type", j.Type,
+		"already_enqueued", !created,
+	)
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"jobId":           j.ID,
+		"status":          j.Status,
+		"type":            j.Type,
+		"alreadyEnqueued": !created,
+	})
+}
+
+// enqueueEmailVerification queues at most one verification email per user
+// per hour.
+func enqueueEmailVerification(ctx context.Context, jobsRepo EmailVerificationJobs, userID string) (job.Job, bool, error) {
+	raw, err := jobs.UserEmailVerificationPayload{UserID: userID}.JSON()
+	if err != nil {
+		return job.Job{}, false, err
+	}
+
+	now := time.Now().UTC()
+	key := "user:email_verification:user:" + userID + ":hour:" + now.Format("2006-01-02T15")
+	return jobsRepo.CreateOrGet(ctx, job.CreateRequest{
+		Type:           jobs.TypeUserEmailVerification,
+		Payload:        raw,
+		RunAt:          now,
+		MaxAttempts:    5,
+		IdempotencyKey: &key,
+		UserID:         &userID,
+	})
+}
@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeEmailVerifier holds each user's address and whether it is verified.
+type fakeEmailVerifier struct {
+	emails   map[string]string
+	verified map[string]bool
+}
+
+func (f *fakeEmailVerifier) MarkEmailVerified(ctx context.Context, id, email string) (bool, error) {
+	if !strings.EqualFold(f.emails[id], email) {
+		return false, nil
+	}
+	f.verified[id] = true
+	return true, nil
+}
+
+func (f *fakeEmailVerifier) EmailVerified(ctx context.Context, id string) (bool, error) {
+	if _, ok := f.emails[id]; !ok {
+		return false, postgres.ErrUserNotFound
+	}
+	return f.verified[id], nil
+}
+
+func postVerifyEmail(r *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/verify-email", strings.NewReader(`{"token":"`+token+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := newUUID()
+	users := &fakeEmailVerifier{emails: map[string]string{userID: "ada@example.com"}, verified: map[string]bool{}}
+	signer := emailverify.NewSigner("secret", time.Hour)
+	h := handlers.NewEmailVerificationHandler(users, nil, signer)
+
+	r := gin.New()
+	r.POST("/auth/verify-email", h.Verify)
+
+	expired, _ := emailverify.NewSigner("secret", -time.Hour).Sign(userID, "ada@example.com")
+	forged, _ := emailverify.NewSigner("other", time.Hour).Sign(userID, "ada@example.com")
+	// sent before the user changed their address
+	stale, _ := signer.Sign(userID, "old@example.com")
+	for name, tc := range map[string]struct {
+		token string
+		code  string
+	}{
+		"expired": {expired, "token_expired"},
+		"forged":  {forged, "invalid_token"},
+		"stale":   {stale, "invalid_token"},
+	} {
+		w := postVerifyEmail(r, tc.token)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), tc.code) {
+			t.Fatalf("%s: expected 401 %s, got %d %s", name, tc.code, w.Code, w.Body.String())
+		}
+	}
+	if users.verified[userID] {
+		t.Fatal("a rejected token verified the user")
+	}
+
+	token, _ := signer.Sign(userID, "ada@example.com")
+	w := postVerifyEmail(r, token)
+	if w.Code != http.StatusOK || !users.verified[userID] {
+		t.Fatalf("expected the user verified, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestEmailVerification_OncePerHourUntilVerified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := newUUID()
+	users := &fakeEmailVerifier{emails: map[string]string{userID: "ada@example.com"}, verified: map[string]bool{}}
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	h := handlers.NewEmailVerificationHandler(users, repo, emailverify.NewSigner("secret", time.Hour))
+
+	r := gin.New()
+	r.POST("/me/email-verification", withUser(userID, "user"), h.Request)
+
+	type enqueued struct {
+		JobID           string `json:"jobId"`
+		Type            string `json:"type"`
+		AlreadyEnqueued bool   `json:"alreadyEnqueued"`
+	}
+	var got [2]enqueued
+	for i := range got {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/me/email-verification", nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("request %d got %d body=%s", i, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got[i]); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	if got[0].Type != jobs.TypeUserEmailVerification || got[0].AlreadyEnqueued ||
+		got[1].JobID != got[0].JobID || !got[1].AlreadyEnqueued || len(repo.byKey) != 1 {
+		t.Fatalf("expected one %s job, got %+v jobs=%d", jobs.TypeUserEmailVerification, got, len(repo.byKey))
+	}
+
+	users.verified[userID] = true
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/me/email-verification", nil))
+	if w.Code != http.StatusConflict || len(repo.byKey) != 1 {
+		t.Fatalf("expected 409 once verified, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+)
+
+// EnqueueClass is how much a kind of enqueue can wait for the queue to
+// drain; each class gets its own back-pressure thresholds.
+type EnqueueClass string
+
+const (
+	// EnqueueCritical is never refused: confirmations a registrant is
+	// waiting for, which registration enqueues without asking the guard.
+	EnqueueCritical EnqueueClass = "critical"
+	// EnqueueStandard is work that should run soon, such as publishing now.
+	EnqueueStandard EnqueueClass = "standard"
+	// EnqueueDeferrable is work nobody is waiting on right away: exports,
+	// reports, publishes scheduled far ahead. It is refused first.
+	EnqueueDeferrable EnqueueClass = "deferrable"
+)
+
+// farFuturePublish is how far ahead a publish must be scheduled to count as
+// deferrable rather than standard.
+const farFuturePublish = time.Hour
+
+const (
+	// queueStatsTTL is how long one backlog reading is reused; the count
+	// scans every due pending row, so it must not run per request.
+	queueStatsTTL = 10 * time.Second
+	// backpressureRetryAfter is what a refused caller is told to wait.
+	backpressureRetryAfter = 30 * time.Second
+)
+
+// QueueStatsReader reports the due pending backlog.
+type QueueStatsReader interface {
+	QueueStats(ctx context.Context) (job.QueueStats, error)
+}
+
+// BackpressureLimits are one class's thresholds; zero turns a check off.
+type BackpressureLimits struct {
+	MaxPending int64
+	MaxAge     time.Duration
+}
+
+// BackpressureDecision is the guard's verdict on one enqueue. Reason names
+// the threshold the backlog is over ("max_pending" or "max_age"), or is
+// empty when the queue is within the class's limits.
+type BackpressureDecision struct {
+	Allowed bool
+	Reason  string
+	Stats   job.QueueStats
+}
+
+// EnqueueGuard refuses enqueues while the queue is too far behind, so a
+// backlog is not made worse by work that can wait. It reads the backlog at
+// most once per queueStatsTTL and lets everything through when it cannot.
+type EnqueueGuard struct {
+	stats  QueueStatsReader
+	limits map[EnqueueClass]BackpressureLimits
+	now    func() time.Time
+	prom   *observability.Prom
+
+	mu        sync.Mutex
+	cached    job.QueueStats
+	fetchedAt time.Time
+}
+
+func NewEnqueueGuard(stats QueueStatsReader, limits map[EnqueueClass]BackpressureLimits) *EnqueueGuard {
+	return &EnqueueGuard{stats: stats, limits: limits, now: time.Now}
+}
+
+// WithClock replaces time.Now for the stats cache.
+func (g *EnqueueGuard) WithClock(now func() time.Time) *EnqueueGuard {
+	if now != nil {
+		g.now = now
+	}
+	return g
+}
+
+// WithMetrics counts the guard's decisions into prom.
+func (g *EnqueueGuard) WithMetrics(prom *observability.Prom) *EnqueueGuard {
+	g.prom = prom
+	return g
+}
+
+// Check judges an enqueue of class against the cached backlog. Critical
+// enqueues are always allowed without a reading.
+func (g *EnqueueGuard) Check(ctx context.Context, class EnqueueClass) BackpressureDecision {
+	if class == EnqueueCritical {
+		return BackpressureDecision{Allowed: true}
+	}
+
+	stats, ok := g.queueStats(ctx)
+	if !ok {
+		return BackpressureDecision{Allowed: true}
+	}
+
+	d := BackpressureDecision{Stats: stats}
+	limits := g.limits[class]
+	switch {
+	case limits.MaxPending > 0 && stats.DuePending > limits.MaxPending:
+		d.Reason = "max_pending"
+	case limits.MaxAge > 0 && stats.OldestPendingAge > limits.MaxAge:
+		d.Reason = "max_age"
+	}
+	d.Allowed = d.Reason == ""
+	return d
+}
+
+// Admit checks an enqueue of jobType and, when the queue is overloaded for
+// its class, answers 503 queue_overloaded with a Retry-After. It reports
+// whether the caller may go on; a nil guard admits everything.
+func (g *EnqueueGuard) Admit(ctx *gin.Context, cctx context.Context, class EnqueueClass, jobType string) bool {
+	if g == nil {
+		return true
+	}
+
+	d := g.Check(cctx, class)
+	if d.Allowed {
+		g.prom.IncJobEnqueueBackpressure(jobType, string(class), "allowed")
+		return true
+	}
+
+	g.prom.IncJobEnqueueBackpressure(jobType, string(class), "rejected")
+	slog.Default().WarnContext(cctx, "job.enqueue_rejected",
+		"request_id", requestIDFrom(ctx),
+		"job_type", jobType,
+		"class", string(class),
+		"reason", d.Reason,
+		"due_pending", d.Stats.DuePending,
+		"oldest_pending_age_ms", d.Stats.OldestPendingAge.Milliseconds(),
+	)
+
+	retryAfter := int(backpressureRetryAfter / time.Second)
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	RespondError(ctx, http.StatusServiceUnavailable, "queue_overloaded",
+		"The job queue is overloaded; try again later.",
+		gin.H{"reason": d.Reason, "retryAfterSeconds": retryAfter})
+	return false
+}
+
+// queueStats returns the backlog, reading it when the cached one is older
+// than queueStatsTTL. A failed read is logged and reported as not ok, so the
+// guard fails open instead of taking the API down with the database.
+func (g *EnqueueGuard) queueStats(ctx context.Context) (job.QueueStats, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if !g.fetchedAt.IsZero() && now.Sub(g.fetchedAt) < queueStatsTTL {
+		return g.cached, true
+	}
+
+	stats, err := g.stats.QueueStats(ctx)
+	if err != nil {
+		slog.Default().WarnContext(ctx, "job.enqueue_backpressure.stats_failed", "err", err)
+		return job.QueueStats{}, false
+	}
+
+	g.cached, g.fetchedAt = stats, now
+	return stats, true
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+)
+
+type fakeQueueStats struct {
+	stats job.QueueStats
+	err   error
+	reads int
+}
+
+func (f *fakeQueueStats) QueueStats(ctx context.Context) (job.QueueStats, error) {
+	f.reads++
+	return f.stats, f.err
+}
+
+var guardLimits = map[handlers.EnqueueClass]handlers.BackpressureLimits{
+	handlers.EnqueueStandard:   {MaxPending: 200, MaxAge: 2 * time.Hour},
+	handlers.EnqueueDeferrable: {MaxPending: 50, MaxAge: 30 * time.Minute},
+}
+
+func TestEnqueueGuard_ThresholdsPerClass(t *testing.T) {
+	cases := []struct {
+		name       string
+		stats      job.QueueStats
+		class      handlers.EnqueueClass
+		wantReason string
+	}{
+		{"healthy queue admits deferrable work", job.QueueStats{DuePending: 10, OldestPendingAge: time.Minute}, handlers.EnqueueDeferrable, ""},
+		{"deferrable refused over its pending limit", job.QueueStats{DuePending: 51}, handlers.EnqueueDeferrable, "max_pending"},
+		{"deferrable refused over its age limit", job.QueueStats{DuePending: 1, OldestPendingAge: 31 * time.Minute}, handlers.EnqueueDeferrable, "max_age"},
+		{"standard still admitted at the deferrable limit", job.QueueStats{DuePending: 51, OldestPendingAge: 31 * time.Minute}, handlers.EnqueueStandard, ""},
+		{"standard refused over its own limit", job.QueueStats{DuePending: 201}, handlers.EnqueueStandard, "max_pending"},
+		{"critical never refused", job.QueueStats{DuePending: 1_000_000, OldestPendingAge: 24 * time.Hour}, handlers.EnqueueCritical, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := handlers.NewEnqueueGuard(&fakeQueueStats{stats: tc.stats}, guardLimits)
+
+			d := g.Check(context.Background(), tc.class)
+			if d.Reason != tc.wantReason || d.Allowed != (tc.wantReason == "") {
+				t.Fatalf("got %+v, want reason %q", d, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestEnqueueGuard_CachesStatsAndFailsOpen(t *testing.T) {
+	now := time.Date(2026, 3, 26, 9, 0, 0, 0, time.UTC)
+	stats := &fakeQueueStats{stats: job.QueueStats{DuePending: 100}}
+	g := handlers.NewEnqueueGuard(stats, guardLimits).WithClock(func() time.Time { return now })
+
+	g.Check(context.Background(), handlers.EnqueueDeferrable)
+	g.Check(context.Background(), handlers.EnqueueDeferrable)
+	if stats.reads != 1 {
+		t.Fatalf("expected one stats read within the TTL, got %d", stats.reads)
+	}
+
+	now = now.Add(11 * time.Second)
+	stats.err = errors.New("db down")
+	if d := g.Check(context.Background(), handlers.EnqueueDeferrable); !d.Allowed {
+		t.Fatalf("expected the guard to fail open when stats cannot be read, got %+v", d)
+	}
+	if stats.reads != 2 {
+		t.Fatalf("expected a fresh read after the TTL, got %d", stats.reads)
+	}
+}
+
+func TestPublishEvent_QueueOverloaded(t *testing.T) {
+	eventID := newUUID()
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	stats := &fakeQueueStats{stats: job.QueueStats{DuePending: 500}}
+	r := newPublishRouter(handlers.NewJobsHandler(repo, nil).
+		WithEnqueueGuard(handlers.NewEnqueueGuard(stats, guardLimits)))
+
+	w := doPublish(r, eventID)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After, got %d headers=%v body=%s", w.Code, w.Header(), w.Body.String())
+	}
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error.Code != "queue_overloaded" {
+		t.Fatalf("expected queue_overloaded, got %s", w.Body.String())
+	}
+	if len(repo.byKey) != 0 {
+		t.Fatalf("expected nothing enqueued, got %v", repo.byKey)
+	}
+
+	// a backlog the standard class tolerates still admits a publish now
+	stats.stats = job.QueueStats{DuePending: 100}
+	r = newPublishRouter(handlers.NewJobsHandler(repo, nil).
+		WithEnqueueGuard(handlers.NewEnqueueGuard(stats, guardLimits)))
+	if w := doPublish(r, eventID); w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 under the standard limit, got %d body=%s", w.Code, w.Body.String())
+	}
+}
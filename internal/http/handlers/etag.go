@@ -11,7 +11,14 @@ import (
 )
 
 func RespondJSONWithETag(ctx *gin.Context, status int, payload interface{}) {
-	etag, err := buildETag(payload)
+	RespondJSONWithETagOf(ctx, status, payload, payload)
+}
+
+// RespondJSONWithETagOf is RespondJSONWithETag with the ETag taken from
+// tagged instead of payload, for payloads carrying fields computed as of the
+// request (countdowns) that should not make every response look changed.
+func RespondJSONWithETagOf(ctx *gin.Context, status int, tagged, payload interface{}) {
+	etag, err := buildETag(tagged)
 	if err != nil {
 		ctx.JSON(status, payload)
 		return
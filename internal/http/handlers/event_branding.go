@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BrandingReader loads an event's organizer branding.
+type BrandingReader interface {
+	GetBranding(ctx context.Context, id string) (event.Branding, error)
+}
+
+type EventBrandingStore interface {
+	EventOrganizersReader
+	BrandingReader
+	UpdateBranding(ctx context.Context, id string, b event.Branding) (event.Branding, error)
+}
+
+type EventBrandingHandler struct {
+	events EventBrandingStore
+}
+
+func NewEventBrandingHandler(events EventBrandingStore) *EventBrandingHandler {
+	return &EventBrandingHandler{events: events}
+}
+
+// Get handles GET /events/:id/branding for the event's organizer or an admin.
+func (h *EventBrandingHandler) Get(ctx *gin.Context) {
+	eventID, ok := h.authorize(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	b, err := h.events.GetBranding(cctx, eventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			RespondNotFound(ctx, "Event not found")
+			return
+		}
+		RespondInternal(ctx, "Could not load event branding")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, b)
+}
+
+// Update handles PUT /events/:id/branding: replaces the reply-to address,
+// display name and logo used on the event's confirmation emails.
+func (h *EventBrandingHandler) Update(ctx *gin.Context) {
+	eventID, ok := h.authorize(ctx)
+	if !ok {
+		return
+	}
+
+	var req event.UpdateBrandingRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	b, err := req.Branding()
+	if err != nil {
+		RespondError(ctx, http.StatusBadRequest, "invalid_branding", "displayName and replyTo must be plain text and logoUrl an https URL", nil)
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	updated, err := h.events.UpdateBranding(cctx, eventID, b)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			RespondNotFound(ctx, "Event not found")
+			return
+		}
+		RespondInternal(ctx, "Could not update event branding")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// authorize lets through the event's organizer and admins.
+func (h *EventBrandingHandler) authorize(ctx *gin.Context) (string, bool) {
+	return authorizeEventOrganizer(ctx, h.events, "You can only brand events you organize")
+}
+
+// authorizeEventOrganizer resolves the :id event and lets through its
+// organizer and admins; anyone else gets 403 with forbidden as the message.
+func authorizeEventOrganizer(ctx *gin.Context, events EventOrganizersReader, forbidden string) (string, bool) {
+	eventID := ctx.Param("id")
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "event id must be a valid UUID")
+		return "", false
+	}
+
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing user identity")
+		return "", false
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	organizers, err := events.Organizers(cctx, []string{eventID})
+	if err != nil {
+		RespondInternal(ctx, "Could not verify event ownership")
+		return "", false
+	}
+
+	organizerID, found := organizers[eventID]
+	if !found {
+		RespondNotFound(ctx, "Event not found")
+		return "", false
+	}
+	if role, _ := middlewares.RoleFromContext(ctx); role != user.RoleAdmin && organizerID != userID {
+		RespondError(ctx, http.StatusForbidden, "forbidden", forbidden, nil)
+		return "", false
+	}
+
+	return eventID, true
+}
+
+// withBranding copies eventID's branding into a confirmation payload. A failed
+// lookup only costs the branding: the email goes out from the platform sender.
+func withBranding(ctx context.Context, reader BrandingReader, eventID string, p jobs.RegistrationConfirmationPayload) jobs.RegistrationConfirmationPayload {
+	if reader == nil {
+		return p
+	}
+
+	b, err := reader.GetBranding(ctx, eventID)
+	if err != nil {
+		slog.Default().WarnContext(ctx, "registration.branding_lookup_failed", "event_id", eventID, "err", err)
+		return p
+	}
+
+	p.ReplyTo = b.ReplyTo
+	p.OrganizerName = b.DisplayName
+	p.LogoURL = b.LogoURL
+	return p
+}
@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeBrandingStore struct {
+	fakeOrganizers
+	branding map[string]event.Branding
+}
+
+func (f *fakeBrandingStore) GetBranding(ctx context.Context, id string) (event.Branding, error) {
+	return f.branding[id], nil
+}
+
+func (f *fakeBrandingStore) UpdateBranding(ctx context.Context, id string, b event.Branding) (event.Branding, error) {
+	f.branding[id] = b
+	return b, nil
+}
+
+func TestEventBranding_OnlyOrganizerOrAdminCanUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID, organizerID := newUUID(), newUUID()
+	store := &fakeBrandingStore{fakeOrganizers: fakeOrganizers{eventID: organizerID}, branding: map[string]event.Branding{}}
+	h := handlers.NewEventBrandingHandler(store)
+
+	put := func(userID string, role user.Role, body string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.PUT("/events/:id/branding", withUser(userID, role), h.Update)
+		req := httptest.NewRequest(http.MethodPut, "/events/"+eventID+"/branding", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"replyTo":"hello@jazzclub.example","displayName":"  Jazz Club  ","logoUrl":"https://cdn.example/logo.png"}`
+	if w := put(newUUID(), "user", body); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a stranger, got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := put(organizerID, "user", body); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the organizer, got %d body=%s", w.Code, w.Body.String())
+	}
+	if got := store.branding[eventID]; got.DisplayName != "Jazz Club" || got.ReplyTo != "hello@jazzclub.example" {
+		t.Fatalf("unexpected stored branding %+v", got)
+	}
+	if w := put(newUUID(), "admin", `{}`); w.Code != http.StatusOK || store.branding[eventID] != (event.Branding{}) {
+		t.Fatalf("expected an admin to clear the branding, got %d %+v", w.Code, store.branding[eventID])
+	}
+}
+
+func TestEventBranding_RejectsUnsafeValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID, organizerID := newUUID(), newUUID()
+	store := &fakeBrandingStore{fakeOrganizers: fakeOrganizers{eventID: organizerID}, branding: map[string]event.Branding{}}
+	r := gin.New()
+	r.PUT("/events/:id/branding", withUser(organizerID, "user"), handlers.NewEventBrandingHandler(store).Update)
+
+	for _, body := range []string{
+		`{"replyTo":"not-an-email"}`,
+		`{"logoUrl":"http://cdn.example/logo.png"}`,
+		`{"displayName":"Jazz\r\nBcc: everyone@example.com"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/events/"+eventID+"/branding", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, w.Code)
+		}
+	}
+	if len(store.branding) != 0 {
+		t.Fatalf("nothing should have been stored, got %+v", store.branding)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/funnel"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type EventCounterRepairer interface {
+	RecountEvent(ctx context.Context, eventID string) (event.CounterRepair, error)
+}
+
+type EventAvailabilityReader interface {
+	Availability(ctx context.Context, eventID string) (event.Availability, error)
+}
+
+type EventCountersRepository interface {
+	EventCounterRepairer
+	EventAvailabilityReader
+}
+
+type EventCountersHandler struct {
+	repo    EventCountersRepository
+	changes AvailabilityChanges
+	stream  StreamLimits
+	funnel  FunnelRecorder
+}
+
+func NewEventCountersHandler(repo EventCountersRepository) *EventCountersHandler {
+	return &EventCountersHandler{repo: repo, stream: DefaultStreamLimits}
+}
+
+// WithFunnel records each availability check as a funnel view.
+func (h *EventCountersHandler) WithFunnel(rec FunnelRecorder) *EventCountersHandler {
+	h.funnel = rec
+	return h
+}
+
+// Recount handles POST /admin/events/:id/recount: targeted repair of one
+// event's registered_count.
+func (h *EventCountersHandler) Recount(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	repair, err := h.repo.RecountEvent(cctx, eventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			RespondNotFound(ctx, "Event not found")
+			return
+		}
+		RespondInternal(ctx, "Could not recount event registrations")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, repair)
+}
+
+// GetAvailability handles GET /events/:id/availability: remaining seats plus
+// the event's registration restrictions, so clients can prompt for login up front.
+func (h *EventCountersHandler) GetAvailability(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	availability, err := h.repo.Availability(cctx, eventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			RespondNotFound(ctx, "Event not found")
+			return
+		}
+		RespondInternal(ctx, "Could not fetch event availability")
+		return
+	}
+
+	// checking availability is the top of the registration funnel
+	if h.funnel != nil {
+		h.funnel.Record(eventID, funnel.StageView, funnel.ReasonOK)
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
@@ -0,0 +1,143 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeRegistrants map[string]bool
+
+func (f fakeRegistrants) HasActiveRegistration(ctx context.Context, eventID, userID string) (bool, error) {
+	return f[eventID+"/"+userID], nil
+}
+
+func TestGetEventById_VisibilityByViewer(t *testing.T) {
+	organizerID, registrantID, strangerID := newUUID(), newUUID(), newUUID()
+	events := map[event.Visibility]string{
+		event.VisibilityPublic:   newUUID(),
+		event.VisibilityUnlisted: newUUID(),
+		event.VisibilityPrivate:  newUUID(),
+	}
+	repo := &fakeEventsRepo{}
+	repo.getFn = func(ctx context.Context, id string) (event.Event, error) {
+		for v, eventID := range events {
+			if eventID == id {
+				return event.Event{ID: id, Title: "Event", StartAt: time.Now().Add(time.Hour), OrganizerID: organizerID, Visibility: v}, nil
+			}
+		}
+		return event.Event{}, event.ErrNotFound
+	}
+	registrants := fakeRegistrants{}
+	for _, id := range events {
+		registrants[id+"/"+registrantID] = true
+	}
+	h := handlers.NewEventsHandler(repo).WithRegistrants(registrants)
+
+	viewers := map[string]gin.HandlerFunc{
+		"anonymous":  func(c *gin.Context) { c.Next() },
+		"stranger":   withUser(strangerID, user.RoleUser),
+		"registrant": withUser(registrantID, user.RoleUser),
+		"organizer":  withUser(organizerID, user.RoleUser),
+		"admin":      withUser(newUUID(), user.RoleAdmin),
+	}
+	// only a private event is hidden, and only from those with no claim to it
+	hidden := map[string]bool{"anonymous": true, "stranger": true}
+
+	for name, mw := range viewers {
+		r := gin.New()
+		r.GET("/events/:id", mw, h.GetEventById)
+		r.GET("/events/batch", mw, h.GetEventsBatch)
+
+		for v, id := range events {
+			want := http.StatusOK
+			if v == event.VisibilityPrivate && hidden[name] {
+				want = http.StatusNotFound
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+id, nil))
+			if w.Code != want {
+				t.Fatalf("%s viewing %s: got %d, want %d", name, v, w.Code, want)
+			}
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/batch?ids="+id, nil))
+			var body struct {
+				Count    int      `json:"count"`
+				NotFound []string `json:"notFound"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode batch: %v", err)
+			}
+			if (body.Count == 1) != (want == http.StatusOK) {
+				t.Fatalf("%s batching %s: got count=%d notFound=%v", name, v, body.Count, body.NotFound)
+			}
+		}
+	}
+}
+
+type fakeEventAccess map[string]event.Access
+
+func (f fakeEventAccess) GetAccess(ctx context.Context, id string) (event.Access, error) {
+	a, ok := f[id]
+	if !ok {
+		return event.Access{}, event.ErrNotFound
+	}
+	return a, nil
+}
+
+func TestRequireEventVisible_HidesPrivateEventsOnEveryRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	organizerID, registrantID := newUUID(), newUUID()
+	publicID, privateID, missingID := newUUID(), newUUID(), newUUID()
+	access := fakeEventAccess{
+		publicID:  {Visibility: event.VisibilityPublic, OrganizerID: organizerID},
+		privateID: {Visibility: event.VisibilityPrivate, OrganizerID: organizerID},
+	}
+	registrants := fakeRegistrants{privateID + "/" + registrantID: true}
+
+	viewers := map[string]gin.HandlerFunc{
+		"anonymous":  func(c *gin.Context) { c.Next() },
+		"stranger":   withUser(newUUID(), user.RoleUser),
+		"registrant": withUser(registrantID, user.RoleUser),
+		"organizer":  withUser(organizerID, user.RoleUser),
+		"admin":      withUser(newUUID(), user.RoleAdmin),
+	}
+	hidden := map[string]bool{"anonymous": true, "stranger": true}
+
+	for name, mw := range viewers {
+		reached := false
+		r := gin.New()
+		r.POST("/events/:id/register", mw, handlers.RequireEventVisible(access, registrants), func(c *gin.Context) {
+			reached = true
+			c.Status(http.StatusCreated)
+		})
+
+		for id, want := range map[string]int{publicID: http.StatusCreated, privateID: http.StatusCreated, missingID: http.StatusCreated} {
+			if id == privateID && hidden[name] {
+				want = http.StatusNotFound
+			}
+			reached = false
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/events/"+id+"/register", nil))
+			if w.Code != want {
+				t.Fatalf("%s on %s: got %d, want %d", name, id, w.Code, want)
+			}
+			// a missing event is the handler's to report
+			if reached != (want != http.StatusNotFound) {
+				t.Fatalf("%s on %s: handler reached=%v", name, id, reached)
+			}
+		}
+	}
+}
@@ -11,8 +11,9 @@ import (
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/cache"
-	"github.com/geocoder89/eventhub/internal/config"
 	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/geocoder89/eventhub/internal/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -36,8 +37,11 @@ type EventsCreator interface {
 }
 
 type EventsHandler struct {
-	repo  EventsCreator
-	cache *cache.Cache
+	repo        EventsCreator
+	cache       *cache.Cache
+	metrics     *observability.Prom
+	editLocks   *EditLocksHandler
+	registrants RegistrantLookup
 }
 
 func NewEventsHandler(repo EventsCreator) *EventsHandler {
@@ -48,6 +52,18 @@ func NewEventsHandlerWithCache(repo EventsCreator, c *cache.Cache) *EventsHandle
 	return &EventsHandler{repo: repo, cache: c}
 }
 
+// WithMetrics counts event lists served stale into p.
+func (h *EventsHandler) WithMetrics(p *observability.Prom) *EventsHandler {
+	h.metrics = p
+	return h
+}
+
+// WithEditLocks has updates check the event's edit lock first.
+func (h *EventsHandler) WithEditLocks(locks *EditLocksHandler) *EventsHandler {
+	h.editLocks = locks
+	return h
+}
+
 // function to make sure, what is returned is a number for the limit query
 
 func parseIntDefault(s string, fallback int) int {
@@ -69,7 +85,12 @@ func (e *EventsHandler) CreateEvent(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	// the creator owns the event and can issue API keys scoped to it
+	if userID, ok := middlewares.UserIDFromContext(ctx); ok {
+		req.OrganizerID = userID
+	}
+
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
 
@@ -134,14 +155,20 @@ func (h *EventsHandler) ListEvents(ctx *gin.Context) {
 		toPtr = &t
 	}
 
+	includeDescriptionHTML, ok := parseEventsInclude(ctx)
+	if !ok {
+		return
+	}
+
 	filter := event.ListEventsFilter{
-		City:     cityPtr,
-		Category: categoryPtr,
-		Tag:      tagPtr,
-		From:     fromPtr,
-		To:       toPtr,
-		Query:    queryPtr,
-		Limit:    limit,
+		City:                   cityPtr,
+		Category:               categoryPtr,
+		Tag:                    tagPtr,
+		From:                   fromPtr,
+		To:                     toPtr,
+		Query:                  queryPtr,
+		Limit:                  limit,
+		IncludeDescriptionHTML: includeDescriptionHTML,
 	}
 
 	includeTotal := ctx.Query("includeTotal") == "true"
@@ -166,7 +193,7 @@ func (h *EventsHandler) ListEvents(ctx *gin.Context) {
 	cacheKey := ""
 
 	if cacheable {
-		cacheKey = utils.BuildEventsListCacheKey(limit, cityPtr, categoryPtr, tagPtr, fromPtr, toPtr, queryPtr)
+		cacheKey = utils.BuildEventsListCacheKey(limit, cityPtr, categoryPtr, tagPtr, fromPtr, toPtr, queryPtr, includeDescriptionHTML)
 
 		v, ok := h.cache.Get(cacheKey)
 
@@ -178,23 +205,34 @@ func (h *EventsHandler) ListEvents(ctx *gin.Context) {
 		slog.Info("events.list.cache_miss", "key", cacheKey)
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	items, next, hasMore, err := h.repo.ListCursor(cctx, filter, afterStartAt, afterID)
 	if err != nil {
+		if cacheable && h.serveStale(ctx, cacheKey, err) {
+			return
+		}
 		RespondInternal(ctx, "Could not list events")
 		return
 	}
+	if includeDescriptionHTML {
+		for i := range items {
+			items[i] = withDescriptionHTML(cctx, items[i])
+		}
+	}
 
+	// the page is still worth serving when only its total is missing
+	var partial partialResult
 	var total *int
 	if includeTotal {
 		t, err := h.repo.Count(cctx, filter)
 		if err != nil {
-			RespondInternal(ctx, "Could not count events")
-			return
+			slog.Default().WarnContext(cctx, "events.list.count_failed", "err", err)
+			partial.warn(WarningCountUnavailable, "The total could not be counted; it is null.")
+		} else {
+			total = &t
 		}
-		total = &t
 	}
 
 	resp := BuildCursorPageResponse(limit, items, hasMore, next, total)
@@ -203,7 +241,158 @@ func (h *EventsHandler) ListEvents(ctx *gin.Context) {
 		h.cache.Set(cacheKey, resp)
 	}
 
-	RespondJSONWithETag(ctx, http.StatusOK, resp)
+	respondPartial(ctx, resp, &partial)
+}
+
+// parseEventsInclude reads ?include=, a comma-separated list of the fields
+// a list leaves out unless asked. descriptionHtml is the only one: it is
+// stored compressed, so lists skip reading it by default.
+func parseEventsInclude(ctx *gin.Context) (descriptionHTML bool, ok bool) {
+	raw := strings.TrimSpace(ctx.Query("include"))
+	if raw == "" {
+		return false, true
+	}
+	for _, field := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(field) {
+		case "descriptionHtml":
+			descriptionHTML = true
+		case "":
+		default:
+			RespondBadRequest(ctx, "invalid_query", "include may only list descriptionHtml")
+			return false, false
+		}
+	}
+	return descriptionHTML, true
+}
+
+// withDescriptionHTML decompresses e's stored description HTML for the
+// response. A copy that can't be read is logged and rendered afresh.
+func withDescriptionHTML(ctx context.Context, e event.Event) event.Event {
+	e, err := e.WithDescriptionHTML()
+	if err != nil {
+		slog.Default().WarnContext(ctx, "events.description_html_unreadable", "event_id", e.ID, "err", err)
+	}
+	e.DescriptionHTMLGzip = nil
+	return e
+}
+
+// MaxEventsBatch caps the ids one GET /events/batch reads.
+const MaxEventsBatch = 50
+
+// GetEventsBatch handles GET /events/batch?ids=a,b,c: up to MaxEventsBatch
+// events in the order asked. Unknown or deleted ids are listed in notFound;
+// an id whose read fails is left out and reported as item_errors, a partial
+// result, rather than failing the rest.
+func (h *EventsHandler) GetEventsBatch(ctx *gin.Context) {
+	raw := strings.TrimSpace(ctx.Query("ids"))
+	if raw == "" {
+		RespondBadRequest(ctx, "invalid_query", "ids is required")
+		return
+	}
+
+	ids := make([]string, 0)
+	seen := map[string]bool{}
+	for _, id := range strings.Split(raw, ",") {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if !utils.IsUUID(id) {
+			RespondBadRequest(ctx, "invalid_query", "ids must be comma-separated UUIDs")
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxEventsBatch {
+		RespondBadRequest(ctx, "invalid_query", "ids must name at most "+strconv.Itoa(MaxEventsBatch)+" events")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	var partial partialResult
+	items := make([]event.Event, 0, len(ids))
+	notFound := make([]string, 0)
+	var failed []string
+	lookup := h.batchLookup(cctx, ids)
+	for _, id := range ids {
+		e, err := lookup(id)
+		if err == nil {
+			// a private event the caller can't see is reported as missing
+			var visible bool
+			if visible, err = h.canView(ctx, cctx, e); err == nil && !visible {
+				err = event.ErrNotFound
+			}
+		}
+		switch {
+		case err == nil:
+			items = append(items, e)
+		case errors.Is(err, event.ErrNotFound):
+			notFound = append(notFound, id)
+		default:
+			slog.Default().WarnContext(cctx, "events.batch.item_failed", "event_id", id, "err", err)
+			failed = append(failed, id)
+		}
+	}
+	if len(failed) > 0 {
+		partial.warn(WarningItemErrors, "Some events could not be read; retry them.", failed...)
+	}
+
+	respondPartial(ctx, gin.H{"items": items, "count": len(items), "notFound": notFound}, &partial)
+}
+
+// EventsBatchReader is implemented by events repos that can load several
+// events in one query.
+type EventsBatchReader interface {
+	GetByIDs(ctx context.Context, ids []string) ([]event.Event, error)
+}
+
+// batchLookup loads ids for GetEventsBatch and returns a lookup of each.
+// With an EventsBatchReader that is one query, and if it fails every id
+// fails with it; otherwise each lookup is a GetByID.
+func (h *EventsHandler) batchLookup(ctx context.Context, ids []string) func(id string) (event.Event, error) {
+	reader, ok := h.repo.(EventsBatchReader)
+	if !ok {
+		return func(id string) (event.Event, error) {
+			return h.repo.GetByID(ctx, id)
+		}
+	}
+
+	found, err := reader.GetByIDs(ctx, ids)
+	byID := make(map[string]event.Event, len(found))
+	for _, e := range found {
+		byID[e.ID] = e
+	}
+	return func(id string) (event.Event, error) {
+		if err != nil {
+			return event.Event{}, err
+		}
+		e, ok := byID[id]
+		if !ok {
+			return event.Event{}, event.ErrNotFound
+		}
+		return e, nil
+	}
+}
+
+// serveStale answers with the expired cache entry for key, if it is still
+// within the cache's max-stale window, rather than failing the whole list
+// while the database is unavailable.
+func (h *EventsHandler) serveStale(ctx *gin.Context, key string, cause error) bool {
+	v, age, ok := h.cache.GetStale(key)
+	if !ok {
+		return false
+	}
+
+	slog.Warn("events.list.served_stale", "key", key, "age", age, "err", cause)
+	h.metrics.IncEventsCacheStaleServed()
+
+	ctx.Header("Warning", `111 - "Revalidation Failed"`)
+	ctx.Header("X-Served-Stale", "true")
+	ctx.Header("Age", strconv.Itoa(int(age.Seconds())))
+	RespondJSONWithETag(ctx, http.StatusOK, v)
+	return true
 }
 
 func (h *EventsHandler) GetEventById(c *gin.Context) {
@@ -214,7 +403,7 @@ func (h *EventsHandler) GetEventById(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	ctx, cancel := DBTimeout(c)
 	defer cancel()
 
 	slog.Default().InfoContext(ctx, "events.get_by_id", "event_id", id)
@@ -230,7 +419,19 @@ func (h *EventsHandler) GetEventById(c *gin.Context) {
 		return
 	}
 
-	RespondJSONWithETag(c, http.StatusOK, e)
+	// private events are reported missing to anyone who may not see them
+	visible, err := h.canView(c, ctx, e)
+	if err != nil {
+		slog.Default().ErrorContext(ctx, "events.get_by_id_visibility_failed", "event_id", id, "err", err)
+		RespondInternal(c, "Could not fetch event")
+		return
+	}
+	if !visible {
+		RespondNotFound(c, "Event not found")
+		return
+	}
+
+	RespondJSONWithETag(c, http.StatusOK, withDescriptionHTML(ctx, e))
 }
 
 func (h *EventsHandler) UpdateEvent(ctx *gin.Context) {
@@ -247,10 +448,14 @@ func (h *EventsHandler) UpdateEvent(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
 
+	if !h.editLocks.allowUpdate(ctx, cctx, id) {
+		return
+	}
+
 	e, err := h.repo.Update(cctx, id, req)
 
 	// checks if the error type is not found, returns a 404
@@ -281,7 +486,7 @@ func (h *EventsHandler) DeleteEvent(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
 
@@ -314,7 +519,7 @@ func (h *EventsHandler) RestoreEvent(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	e, err := h.repo.Restore(cctx, id)
@@ -7,15 +7,19 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/cache"
 	"github.com/geocoder89/eventhub/internal/domain/event"
 	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/geocoder89/eventhub/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 // Make sure Gin does not spam the console during the test
@@ -122,6 +126,7 @@ func setupRouter(method, path string, h gin.HandlerFunc) *gin.Engine {
 
 func TestCreateEventHandler(t *testing.T) {
 	now := time.Now().UTC()
+	startAt := now.Add(24 * time.Hour)
 
 	// basic nomenclature/structure of a test
 	tests := []struct {
@@ -136,7 +141,7 @@ func TestCreateEventHandler(t *testing.T) {
 				"title": "Go Meetup",
 				"description": "Day 10 test",
 				"city": "Toronto",
-				"startAt": "` + now.Format(time.RFC3339) + `",
+				"startAt": "` + startAt.Format(time.RFC3339) + `",
 				"capacity": 50
 			}`,
 
@@ -171,7 +176,7 @@ func TestCreateEventHandler(t *testing.T) {
 				"title": "Go Meetup",
 				"description": "Day 10 test",
 				"City": "Toronto",
-				"startAt": "` + now.Format(time.RFC3339) + `",
+				"startAt": "` + startAt.Format(time.RFC3339) + `",
 				"capacity": 50
 			}`,
 			repoSetUp: func(f *fakeEventsRepo) {
@@ -926,3 +931,166 @@ func TestGetEventByIDHandler_ETagNotModified(t *testing.T) {
 		t.Fatalf("expected repo to be called on each lookup, got %d calls", calls)
 	}
 }
+
+func TestCreateEventHandler_RegistrationWindow(t *testing.T) {
+	startAt := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	at := func(d time.Duration) string { return `"` + startAt.Add(d).Format(time.RFC3339) + `"` }
+
+	tests := []struct {
+		name       string
+		window     string
+		wantStatus int
+	}{
+		{name: "no window", window: ``, wantStatus: http.StatusCreated},
+		{name: "closes at start", window: `,"registrationOpensAt":` + at(-24*time.Hour) + `,"registrationClosesAt":` + at(0), wantStatus: http.StatusCreated},
+		{name: "closes after start", window: `,"registrationClosesAt":` + at(time.Second), wantStatus: http.StatusBadRequest},
+		{name: "opens one second before close", window: `,"registrationOpensAt":` + at(-2*time.Second) + `,"registrationClosesAt":` + at(-time.Second), wantStatus: http.StatusCreated},
+		{name: "opens at close", window: `,"registrationOpensAt":` + at(-time.Hour) + `,"registrationClosesAt":` + at(-time.Hour), wantStatus: http.StatusBadRequest},
+		{name: "opens at start", window: `,"registrationOpensAt":` + at(0), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			repo := &fakeEventsRepo{createFn: func(ctx context.Context, req event.CreateEventRequest) (event.Event, error) {
+				called = true
+				return event.NewFromCreateRequest(req), nil
+			}}
+			r := setupRouter(http.MethodPost, "/events", handlers.NewEventsHandler(repo).CreateEvent)
+
+			body := `{"title":"Go Meetup","startAt":` + at(0) + `,"capacity":50` + tt.window + `}`
+			req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if called != (tt.wantStatus == http.StatusCreated) {
+				t.Fatalf("repo called=%v for status %d", called, w.Code)
+			}
+		})
+	}
+}
+
+func TestGetEventByIdHandler_RegistrationState(t *testing.T) {
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		event event.Event
+		want  event.RegistrationState
+	}{
+		{name: "no window", event: event.Event{Capacity: 10}, want: event.RegistrationOpen},
+		{name: "before opening", event: event.Event{Capacity: 10, RegistrationOpensAt: &future}, want: event.RegistrationNotOpen},
+		{name: "inside window", event: event.Event{Capacity: 10, RegistrationOpensAt: &past, RegistrationClosesAt: &future}, want: event.RegistrationOpen},
+		{name: "after closing", event: event.Event{Capacity: 10, RegistrationClosesAt: &past}, want: event.RegistrationClosed},
+		{name: "full", event: event.Event{Capacity: 10, RegisteredCount: 10}, want: event.RegistrationFull},
+		{name: "closed beats full", event: event.Event{Capacity: 10, RegisteredCount: 10, RegistrationClosesAt: &past}, want: event.RegistrationClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEventsRepo{getFn: func(ctx context.Context, id string) (event.Event, error) {
+				e := tt.event
+				e.ID, e.Title, e.StartAt = id, "Event-1", now.Add(24*time.Hour)
+				return e, nil
+			}}
+			r := setupRouter(http.MethodGet, "/events/:id", handlers.NewEventsHandler(repo).GetEventById)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+newUUID(), nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, body=%s", w.Code, w.Body.String())
+			}
+
+			var got struct {
+				RegistrationState event.RegistrationState `json:"registrationState"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.RegistrationState != tt.want {
+				t.Fatalf("got registrationState %q, want %q", got.RegistrationState, tt.want)
+			}
+		})
+	}
+}
+
+func TestListEventsHandler_ServesStaleCacheWhenRepoFails(t *testing.T) {
+	now := time.Now().UTC()
+
+	failing := false
+	fakeRepo := &fakeEventsRepo{}
+	fakeRepo.listCursorFn = func(ctx context.Context, filters event.ListEventsFilter, afterStartAt time.Time, afterID string) ([]event.Event, *string, bool, error) {
+		if failing {
+			return nil, nil, false, errors.New("connection refused")
+		}
+		return []event.Event{
+			{ID: "id-1", Title: "Event 1", City: "Toronto", StartAt: now, CreatedAt: now, UpdatedAt: now},
+		}, nil, false, nil
+	}
+
+	prom := observability.NewProm(prometheus.NewRegistry())
+	c := cache.New(10 * time.Millisecond).WithMaxStale(time.Minute)
+	h := handlers.NewEventsHandlerWithCache(fakeRepo, c).WithMetrics(prom)
+	r := setupRouter(http.MethodGet, "/events", h.ListEvents)
+
+	primed := httptest.NewRecorder()
+	r.ServeHTTP(primed, httptest.NewRequest(http.MethodGet, "/events?limit=20", nil))
+	if primed.Code != http.StatusOK {
+		t.Fatalf("priming call got %d body=%s", primed.Code, primed.Body.String())
+	}
+
+	// the entry expires, then the database goes away
+	time.Sleep(20 * time.Millisecond)
+	failing = true
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?limit=20", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the stale page, got %d body=%s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != primed.Body.String() {
+		t.Fatalf("expected the cached body, got %s", w.Body.String())
+	}
+	if w.Header().Get("X-Served-Stale") != "true" || !strings.HasPrefix(w.Header().Get("Warning"), "111 ") || w.Header().Get("Age") == "" {
+		t.Fatalf("expected stale headers, got %v", w.Header())
+	}
+	if got := testutil.ToFloat64(prom.EventsCacheStaleServedTotal); got != 1 {
+		t.Fatalf("expected one stale serve counted, got %v", got)
+	}
+
+	// nothing cached for this query: the error stands
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?limit=5", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("X-Served-Stale") != "" {
+		t.Fatalf("expected 500 without a cached page, got %d headers=%v", w.Code, w.Header())
+	}
+}
+
+func TestListEventsHandler_NoStaleServeWithoutMaxStale(t *testing.T) {
+	failing := false
+	fakeRepo := &fakeEventsRepo{}
+	fakeRepo.listCursorFn = func(ctx context.Context, filters event.ListEventsFilter, afterStartAt time.Time, afterID string) ([]event.Event, *string, bool, error) {
+		if failing {
+			return nil, nil, false, errors.New("connection refused")
+		}
+		return []event.Event{}, nil, false, nil
+	}
+
+	h := handlers.NewEventsHandlerWithCache(fakeRepo, cache.New(10*time.Millisecond))
+	r := setupRouter(http.MethodGet, "/events", h.ListEvents)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events?limit=20", nil))
+	time.Sleep(20 * time.Millisecond)
+	failing = true
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?limit=20", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 once the entry expired, got %d", w.Code)
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportJobsReader interface {
+	GetByID(ctx context.Context, id string) (job.Job, error)
+}
+
+// RegistrationCSVExportsLister also lists stored exports for operators.
+type RegistrationCSVExportsLister interface {
+	RegistrationCSVExportsReader
+	List(ctx context.Context, filter registrationexport.ListFilter, retention time.Duration) ([]registrationexport.CSVExport, error)
+}
+
+type ExportsHandler struct {
+	jobs      ExportJobsReader
+	exports   RegistrationCSVExportsLister
+	retention time.Duration
+}
+
+func NewExportsHandler(jobsRepo ExportJobsReader, exportsRepo RegistrationCSVExportsLister) *ExportsHandler {
+	return &ExportsHandler{jobs: jobsRepo, exports: exportsRepo, retention: config.Config{}.ExportRetention()}
+}
+
+// WithRetention sets the retention exports.cleanup applies, so listings show
+// when each export is due for deletion.
+func (h *ExportsHandler) WithRetention(d time.Duration) *ExportsHandler {
+	h.retention = d
+	return h
+}
+
+type exportStatusResponse struct {
+	ID          string     `json:"id"`
+	EventID     string     `json:"eventId"`
+	Status      job.Status `json:"status"`
+	RowCount    *int       `json:"rowCount,omitempty"`
+	FileName    string     `json:"fileName,omitempty"`
+	DownloadURL *string    `json:"downloadUrl"`
+	LastError   *string    `json:"lastError,omitempty"`
+	RequestedAt time.Time  `json:"requestedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Get handles GET /admin/exports/:id. An export is identified by the id of the
+// job producing it; the download link appears once the job is done.
+func (h *ExportsHandler) Get(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !utils.IsUUID(id) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	j, err := h.jobs.GetByID(cctx, id)
+	if err != nil {
+		if errors.Is(err, job.ErrJobNotFound) {
+			RespondNotFound(ctx, "Export not found")
+			return
+		}
+		RespondInternal(ctx, "Could not fetch export")
+		return
+	}
+
+	if j.Type != jobs.TypeRegistrationsExportCSV {
+		RespondNotFound(ctx, "Export not found")
+		return
+	}
+
+	var p jobs.RegistrationsExportCSVPayload
+	_ = json.Unmarshal(j.Payload, &p)
+
+	resp := exportStatusResponse{
+		ID:          j.ID,
+		EventID:     p.EventID,
+		Status:      j.Status,
+		LastError:   j.LastError,
+		RequestedAt: j.CreatedAt,
+	}
+
+	if j.Status == job.StatusDone {
+		exported, err := h.exports.GetByJobID(cctx, j.ID)
+		switch {
+		case err == nil:
+			link := "/admin/jobs/" + j.ID + "/registrations-export.csv"
+			resp.DownloadURL = &link
+			resp.RowCount = &exported.RowCount
+			resp.FileName = exported.FileName
+			resp.CompletedAt = &exported.CreatedAt
+		case errors.Is(err, registrationexport.ErrNotFound):
+			// done but no artifact recorded; report the status without a link
+		default:
+			RespondInternal(ctx, "Could not fetch export")
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type exportSummary struct {
+	ID           string     `json:"id"`
+	EventID      string     `json:"eventId"`
+	RequestedBy  *string    `json:"requestedBy"`
+	FileName     string     `json:"fileName"`
+	RowCount     int        `json:"rowCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	KeepUntil    *time.Time `json:"keepUntil"`
+	DeleteAfter  time.Time  `json:"deleteAfter"`
+	DownloadPath string     `json:"downloadPath"`
+}
+
+// List handles GET /admin/exports: stored registration exports, soonest
+// deletion first. eventId narrows to one event; dueBy (RFC 3339) keeps only
+// exports that cleanup will delete by then.
+func (h *ExportsHandler) List(ctx *gin.Context) {
+	limit := parseIntDefault(ctx.Query("limit"), 50)
+	if limit < 1 || limit > 200 {
+		RespondBadRequest(ctx, "invalid_query", "limit must be between 1 and 200")
+		return
+	}
+
+	filter := registrationexport.ListFilter{Limit: limit}
+	if eventID := ctx.Query("eventId"); eventID != "" {
+		if !utils.IsUUID(eventID) {
+			RespondBadRequest(ctx, "invalid_query", "eventId must be a valid UUID")
+			return
+		}
+		filter.EventID = eventID
+	}
+	if raw := ctx.Query("dueBy"); raw != "" {
+		dueBy, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "dueBy must be an RFC 3339 timestamp")
+			return
+		}
+		filter.DueBy = &dueBy
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	exports, err := h.exports.List(cctx, filter, h.retention)
+	if err != nil {
+		RespondInternal(ctx, "Could not list exports")
+		return
+	}
+
+	items := make([]exportSummary, 0, len(exports))
+	for _, e := range exports {
+		items = append(items, exportSummary{
+			ID:           e.JobID,
+			EventID:      e.EventID,
+			RequestedBy:  e.RequestedBy,
+			FileName:     e.FileName,
+			RowCount:     e.RowCount,
+			CreatedAt:    e.CreatedAt,
+			KeepUntil:    e.KeepUntil,
+			DeleteAfter:  e.DeleteAfter(h.retention),
+			DownloadPath: "/admin/jobs/" + e.JobID + "/registrations-export.csv",
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"items":         items,
+		"retentionDays": int(h.retention / (24 * time.Hour)),
+	})
+}
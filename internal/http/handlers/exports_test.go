@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type capturingJobsRepo struct {
+	created []job.CreateRequest
+}
+
+func (r *capturingJobsRepo) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
+	r.created = append(r.created, req)
+	return job.Job{ID: newUUID(), Type: req.Type, Status: job.StatusPending}, nil
+}
+
+func (r *capturingJobsRepo) CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error) {
+	return r.Create(ctx, req)
+}
+
+func (r *capturingJobsRepo) CreateOrGet(ctx context.Context, req job.CreateRequest) (job.Job, bool, error) {
+	j, err := r.Create(ctx, req)
+	return j, err == nil, err
+}
+
+func (r *capturingJobsRepo) GetByIdempotencyKey(ctx context.Context, key string) (job.Job, error) {
+	return job.Job{}, job.ErrJobNotFound
+}
+
+type fakeExportsLister struct {
+	exports   []registrationexport.CSVExport
+	gotFilter registrationexport.ListFilter
+}
+
+func (f *fakeExportsLister) GetByJobID(ctx context.Context, jobID string) (registrationexport.CSVExport, error) {
+	return registrationexport.CSVExport{}, registrationexport.ErrNotFound
+}
+
+func (f *fakeExportsLister) List(ctx context.Context, filter registrationexport.ListFilter, retention time.Duration) ([]registrationexport.CSVExport, error) {
+	f.gotFilter = filter
+	return f.exports, nil
+}
+
+func TestExportRegistrationsCSV_KeepUntil(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantKeep bool
+	}{
+		{"no body follows retention", "", http.StatusAccepted, false},
+		{"within max", `{"keepUntil":"` + time.Now().UTC().Add(90*24*time.Hour).Format(time.RFC3339) + `"}`, http.StatusAccepted, true},
+		{"past the max", `{"keepUntil":"` + time.Now().UTC().Add(400*24*time.Hour).Format(time.RFC3339) + `"}`, http.StatusBadRequest, false},
+		{"in the past", `{"keepUntil":"` + time.Now().UTC().Add(-time.Hour).Format(time.RFC3339) + `"}`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &capturingJobsRepo{}
+			h := handlers.NewJobsHandler(repo, &fakeExportsLister{}).WithExportKeepMax(365 * 24 * time.Hour)
+
+			r := gin.New()
+			r.POST("/admin/events/:id/registrations/export", withUser(newUUID(), "admin"), h.ExportRegistrationsCSV)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/events/"+newUUID()+"/registrations/export", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d body=%s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusAccepted {
+				if len(repo.created) != 0 {
+					t.Fatalf("no job expected for a rejected keepUntil")
+				}
+				return
+			}
+
+			var p jobs.RegistrationsExportCSVPayload
+			if err := json.Unmarshal(repo.created[0].Payload, &p); err != nil {
+				t.Fatalf("payload: %v", err)
+			}
+			if (p.KeepUntil != nil) != tt.wantKeep {
+				t.Fatalf("keepUntil in payload = %v, want set=%v", p.KeepUntil, tt.wantKeep)
+			}
+		})
+	}
+}
+
+func TestListExports_ShowsDeleteAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	keep := created.Add(90 * 24 * time.Hour)
+	lister := &fakeExportsLister{exports: []registrationexport.CSVExport{
+		{JobID: newUUID(), EventID: newUUID(), FileName: "a.csv", CreatedAt: created},
+		{JobID: newUUID(), EventID: newUUID(), FileName: "b.csv", CreatedAt: created, KeepUntil: &keep},
+	}}
+	h := handlers.NewExportsHandler(nil, lister).WithRetention(30 * 24 * time.Hour)
+
+	r := gin.New()
+	r.GET("/admin/exports", h.List)
+
+	eventID := newUUID()
+	req := httptest.NewRequest(http.MethodGet, "/admin/exports?eventId="+eventID+"&dueBy=2026-02-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []struct {
+			DeleteAfter time.Time `json:"deleteAfter"`
+		} `json:"items"`
+		RetentionDays int `json:"retentionDays"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.RetentionDays != 30 || len(resp.Items) != 2 {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+	if !resp.Items[0].DeleteAfter.Equal(created.Add(30 * 24 * time.Hour)) {
+		t.Fatalf("expected retention-based deleteAfter, got %s", resp.Items[0].DeleteAfter)
+	}
+	if !resp.Items[1].DeleteAfter.Equal(keep) {
+		t.Fatalf("expected keepUntil to push deleteAfter to %s, got %s", keep, resp.Items[1].DeleteAfter)
+	}
+	if lister.gotFilter.EventID != eventID || lister.gotFilter.DueBy == nil || lister.gotFilter.Limit != 50 {
+		t.Fatalf("filter not passed through: %+v", lister.gotFilter)
+	}
+
+	for _, q := range []string{"?limit=0", "?eventId=nope", "?dueBy=tomorrow"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/exports"+q, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/funnel"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FunnelRecorder is the write side used by the events and registration handlers.
+type FunnelRecorder interface {
+	Record(eventID string, stage funnel.Stage, reason string)
+}
+
+type FunnelReader interface {
+	DailySeries(ctx context.Context, eventID string, from, to time.Time) ([]funnel.DailyCount, error)
+}
+
+type FunnelHandler struct {
+	repo       FunnelReader
+	attendance EventAttendanceReader
+}
+
+func NewFunnelHandler(repo FunnelReader) *FunnelHandler {
+	return &FunnelHandler{repo: repo}
+}
+
+// WithAttendance adds the event's finalized attendance to the report.
+func (h *FunnelHandler) WithAttendance(repo EventAttendanceReader) *FunnelHandler {
+	h.attendance = repo
+	return h
+}
+
+// eventFunnelResponse is the funnel report plus attendance, once the event
+// has been finalized.
+type eventFunnelResponse struct {
+	funnel.Report
+	Attendance *event.AttendanceStats `json:"attendance,omitempty"`
+}
+
+const maxFunnelRangeDays = 366
+
+// GET /admin/events/:id/funnel?from=2026-02-01&to=2026-02-28
+func (h *FunnelHandler) GetEventFunnel(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "event id must be a valid UUID")
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	from := today.AddDate(0, 0, -29)
+
+	if s := ctx.Query("to"); s != "" {
+		t, err := time.Parse(funnel.DayLayout, s)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "to must be a YYYY-MM-DD date")
+			return
+		}
+		to = t
+		if ctx.Query("from") == "" {
+			from = to.AddDate(0, 0, -29)
+		}
+	}
+	if s := ctx.Query("from"); s != "" {
+		t, err := time.Parse(funnel.DayLayout, s)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "from must be a YYYY-MM-DD date")
+			return
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		RespondBadRequest(ctx, "invalid_query", "from must be on or before to")
+		return
+	}
+	if to.Sub(from) > maxFunnelRangeDays*24*time.Hour {
+		RespondBadRequest(ctx, "invalid_query", "date range must not exceed 366 days")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	rows, err := h.repo.DailySeries(cctx, eventID, from, to)
+	if err != nil {
+		RespondInternal(ctx, "Could not load event funnel")
+		return
+	}
+
+	resp := eventFunnelResponse{Report: funnel.BuildReport(eventID, from, to, rows)}
+	if h.attendance != nil {
+		stats, err := h.attendance.StatsForEvent(cctx, eventID)
+		switch {
+		case err == nil:
+			resp.Attendance = &stats
+		case !errors.Is(err, event.ErrAttendanceNotFinalized):
+			RespondInternal(ctx, "Could not load event attendance")
+			return
+		}
+	}
+
+	RespondJSONWithETag(ctx, http.StatusOK, resp)
+}
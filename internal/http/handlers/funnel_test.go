@@ -0,0 +1,261 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/funnel"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type recordedStage struct {
+	eventID string
+	stage   funnel.Stage
+	reason  string
+}
+
+type fakeFunnelRecorder struct {
+	mu      sync.Mutex
+	records []recordedStage
+}
+
+func (f *fakeFunnelRecorder) Record(eventID string, stage funnel.Stage, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records = append(f.records, recordedStage{eventID: eventID, stage: stage, reason: reason})
+}
+
+func (f *fakeFunnelRecorder) count(stage funnel.Stage, reason string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.records {
+		if r.stage == stage && r.reason == reason {
+			n++
+		}
+	}
+	return n
+}
+
+func withUser(userID string, role user.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middlewares.CtxUserID, userID)
+		c.Set(middlewares.CtxRole, role)
+		c.Next()
+	}
+}
+
+func TestRegister_RecordsFunnelStages(t *testing.T) {
+	eventID := newUUID()
+
+	tests := []struct {
+		name       string
+		body       string
+		createErr  error
+		wantStatus int
+		wantReason string
+		wantOK     bool
+	}{
+		{
+			name:       "success records attempt and success",
+			body:       `{"name":"Sam Doe","email":"sam@example.com"}`,
+			wantStatus: http.StatusCreated,
+			wantReason: funnel.ReasonOK,
+			wantOK:     true,
+		},
+		{
+			name:       "duplicate is still an attempt",
+			body:       `{"name":"Sam Doe","email":"sam@example.com"}`,
+			createErr:  registration.ErrAlreadyRegistered,
+			wantStatus: http.StatusConflict,
+			wantReason: funnel.ReasonAlreadyRegistered,
+		},
+		{
+			name:       "full event is still an attempt",
+			body:       `{"name":"Sam Doe","email":"sam@example.com"}`,
+			createErr:  registration.ErrEventFull,
+			wantStatus: http.StatusConflict,
+			wantReason: funnel.ReasonEventFull,
+		},
+		{
+			name:       "missing event",
+			body:       `{"name":"Sam Doe","email":"sam@example.com"}`,
+			createErr:  event.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantReason: funnel.ReasonNotFound,
+		},
+		{
+			name:       "invalid body",
+			body:       `{"name":"S"}`,
+			wantStatus: http.StatusBadRequest,
+			wantReason: funnel.ReasonInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRegistrationsRepo{}
+			repo.createTxFn = func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
+				if tt.createErr != nil {
+					return registration.Registration{}, tt.createErr
+				}
+				return registration.Registration{ID: newUUID(), EventID: req.EventID, Email: req.Email}, nil
+			}
+
+			rec := &fakeFunnelRecorder{}
+			h := handlers.NewRegistrationHandler(repo).WithFunnel(rec)
+
+			r := gin.New()
+			r.POST("/events/:id/register", withUser(newUUID(), "user"), h.Register)
+
+			req := httptest.NewRequest(http.MethodPost, "/events/"+eventID+"/register", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := rec.count(funnel.StageAttempt, tt.wantReason); got != 1 {
+				t.Fatalf("expected 1 attempt with reason %q, got %d (%v)", tt.wantReason, got, rec.records)
+			}
+
+			wantSuccess := 0
+			if tt.wantOK {
+				wantSuccess = 1
+			}
+			if got := rec.count(funnel.StageSuccess, funnel.ReasonOK); got != wantSuccess {
+				t.Fatalf("expected %d success records, got %d", wantSuccess, got)
+			}
+		})
+	}
+}
+
+func TestCancel_RecordsFunnelCancellation(t *testing.T) {
+	eventID := newUUID()
+	regID := newUUID()
+	userID := newUUID()
+
+	repo := &fakeRegistrationsRepo{}
+	repo.getByIDFn = func(ctx context.Context, gotEventID, gotRegID string) (registration.Registration, error) {
+		return registration.Registration{ID: gotRegID, EventID: gotEventID, UserID: userID}, nil
+	}
+
+	rec := &fakeFunnelRecorder{}
+	h := handlers.NewRegistrationHandler(repo).WithFunnel(rec)
+
+	r := gin.New()
+	r.DELETE("/events/:id/registrations/:registrationId", withUser(userID, "user"), h.Cancel)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/events/"+eventID+"/registrations/"+regID, nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if got := rec.count(funnel.StageCancel, funnel.ReasonOK); got != 1 {
+		t.Fatalf("expected 1 cancel record, got %d", got)
+	}
+}
+
+func TestGetAvailability_RecordsFunnelView(t *testing.T) {
+	rec := &fakeFunnelRecorder{}
+	h := handlers.NewEventCountersHandler(&fakeCountersRepo{}).WithFunnel(rec)
+	r := setupRouter(http.MethodGet, "/events/:id/availability", h.GetAvailability)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+newUUID()+"/availability", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+missingEventID+"/availability", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	if got := rec.count(funnel.StageView, funnel.ReasonOK); got != 1 {
+		t.Fatalf("expected only the found event to count as a view, got %d", got)
+	}
+}
+
+type fakeFunnelReader struct {
+	dailySeriesFn func(ctx context.Context, eventID string, from, to time.Time) ([]funnel.DailyCount, error)
+}
+
+func (f *fakeFunnelReader) DailySeries(ctx context.Context, eventID string, from, to time.Time) ([]funnel.DailyCount, error) {
+	return f.dailySeriesFn(ctx, eventID, from, to)
+}
+
+func TestGetEventFunnel_ComputesConversion(t *testing.T) {
+	eventID := newUUID()
+	day1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	reader := &fakeFunnelReader{
+		dailySeriesFn: func(ctx context.Context, gotEventID string, from, to time.Time) ([]funnel.DailyCount, error) {
+			if !from.Equal(day1) || !to.Equal(day1.AddDate(0, 0, 2)) {
+				t.Fatalf("unexpected range %s..%s", from, to)
+			}
+			return []funnel.DailyCount{
+				{Day: day1, Stage: funnel.StageView, Reason: funnel.ReasonOK, Count: 10},
+				{Day: day1, Stage: funnel.StageAttempt, Reason: funnel.ReasonOK, Count: 3},
+				{Day: day1, Stage: funnel.StageAttempt, Reason: funnel.ReasonEventFull, Count: 1},
+				{Day: day1, Stage: funnel.StageSuccess, Reason: funnel.ReasonOK, Count: 3},
+				{Day: day2, Stage: funnel.StageView, Reason: funnel.ReasonOK, Count: 10},
+				{Day: day2, Stage: funnel.StageCancel, Reason: funnel.ReasonOK, Count: 1},
+			}, nil
+		},
+	}
+
+	h := handlers.NewFunnelHandler(reader)
+	r := setupRouter(http.MethodGet, "/admin/events/:id/funnel", h.GetEventFunnel)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/events/"+eventID+"/funnel?from=2026-02-01&to=2026-02-03", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var report funnel.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(report.Days) != 3 {
+		t.Fatalf("expected zero-filled series of 3 days, got %d", len(report.Days))
+	}
+	if report.Days[0].Attempts != 4 || report.Days[0].AttemptFailures[funnel.ReasonEventFull] != 1 {
+		t.Fatalf("unexpected day1 stats: %+v", report.Days[0])
+	}
+	if report.Totals.Views != 20 || report.Totals.Successes != 3 || report.Totals.Cancellations != 1 {
+		t.Fatalf("unexpected totals: %+v", report.Totals)
+	}
+	if report.Conversion.ViewToAttempt != 0.2 || report.Conversion.AttemptToSuccess != 0.75 {
+		t.Fatalf("unexpected conversion: %+v", report.Conversion)
+	}
+}
+
+func TestGetEventFunnel_RejectsInvertedRange(t *testing.T) {
+	h := handlers.NewFunnelHandler(&fakeFunnelReader{})
+	r := setupRouter(http.MethodGet, "/admin/events/:id/funnel", h.GetEventFunnel)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/events/"+newUUID()+"/funnel?from=2026-02-10&to=2026-02-01", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
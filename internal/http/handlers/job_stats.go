@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+)
+
+// JobStatsReader reads the daily totals workers have persisted.
+type JobStatsReader interface {
+	ListDaily(ctx context.Context, from, to time.Time) ([]job.DailyStats, error)
+}
+
+// LiveJobCountsSource reports totals workers have not flushed yet.
+type LiveJobCountsSource interface {
+	Snapshot() []observability.LiveJobCount
+}
+
+type JobStatsHandler struct {
+	repo JobStatsReader
+	live LiveJobCountsSource
+	now  func() time.Time
+}
+
+func NewJobStatsHandler(repo JobStatsReader, live LiveJobCountsSource) *JobStatsHandler {
+	return &JobStatsHandler{repo: repo, live: live, now: time.Now}
+}
+
+type jobStatsCounts struct {
+	Claimed      int64 `json:"claimed"`
+	Done         int64 `json:"done"`
+	Failed       int64 `json:"failed"`
+	Retried      int64 `json:"retried"`
+	DeadLettered int64 `json:"deadLettered"`
+	TimedOut     int64 `json:"timedOut"`
+}
+
+func toJobStatsCounts(s job.DailyStats) jobStatsCounts {
+	return jobStatsCounts{
+		Claimed:      s.Claimed,
+		Done:         s.Done,
+		Failed:       s.Failed,
+		Retried:      s.Retried,
+		DeadLettered: s.DeadLettered,
+		TimedOut:     s.TimedOut,
+	}
+}
+
+type jobStatsDay struct {
+	Day     string `json:"day"`
+	Workers int    `json:"workers"`
+	jobStatsCounts
+}
+
+// GET /admin/jobs/stats?days=7: job outcomes per UTC day, newest first.
+// Persisted rows are topped up with the live totals of workers running in
+// this process, which replace that worker's row for the same day.
+func (h *JobStatsHandler) Daily(ctx *gin.Context) {
+	days := parseIntDefault(ctx.Query("days"), 7)
+	if days < 1 || days > 90 {
+		RespondBadRequest(ctx, "invalid_query", "days must be between 1 and 90")
+		return
+	}
+
+	y, m, d := h.now().UTC().Date()
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -(days - 1))
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	rows, err := h.repo.ListDaily(cctx, from, to)
+	if err != nil {
+		RespondInternal(ctx, "Could not load job stats")
+		return
+	}
+
+	type key struct {
+		day    string
+		worker string
+	}
+	merged := make(map[key]job.DailyStats, len(rows))
+	for _, r := range rows {
+		merged[key{r.Day.UTC().Format(time.DateOnly), r.WorkerID}] = r
+	}
+	if h.live != nil {
+		for _, l := range h.live.Snapshot() {
+			if l.Day.Before(from) || l.Day.After(to) {
+				continue
+			}
+			merged[key{l.Day.UTC().Format(time.DateOnly), l.WorkerID}] = job.DailyStats{
+				Day:          l.Day,
+				WorkerID:     l.WorkerID,
+				Claimed:      int64(l.Counts.Claimed),
+				Done:         int64(l.Counts.Done),
+				Failed:       int64(l.Counts.Failed),
+				Retried:      int64(l.Counts.Retried),
+				DeadLettered: int64(l.Counts.DeadLettered),
+				TimedOut:     int64(l.Counts.TimedOut),
+			}
+		}
+	}
+
+	byDay := make(map[string]*job.DailyStats)
+	workers := make(map[string]int)
+	var total job.DailyStats
+	for k, s := range merged {
+		agg, ok := byDay[k.day]
+		if !ok {
+			agg = &job.DailyStats{}
+			byDay[k.day] = agg
+		}
+		agg.Add(s)
+		workers[k.day]++
+		total.Add(s)
+	}
+
+	out := make([]jobStatsDay, 0, len(byDay))
+	for day, agg := range byDay {
+		out = append(out, jobStatsDay{Day: day, Workers: workers[day], jobStatsCounts: toJobStatsCounts(*agg)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day > out[j].Day })
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"from":  from.Format(time.DateOnly),
+		"to":    to.Format(time.DateOnly),
+		"days":  out,
+		"total": toJobStatsCounts(total),
+	})
+}
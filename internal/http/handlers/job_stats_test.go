@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeJobStats struct {
+	rows     []job.DailyStats
+	from, to time.Time
+}
+
+func (f *fakeJobStats) ListDaily(ctx context.Context, from, to time.Time) ([]job.DailyStats, error) {
+	f.from, f.to = from, to
+	return f.rows, nil
+}
+
+type fakeLiveCounts []observability.LiveJobCount
+
+func (f fakeLiveCounts) Snapshot() []observability.LiveJobCount { return f }
+
+func TestJobStatsDaily_MergesLiveCountsOverPersistedRows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	repo := &fakeJobStats{rows: []job.DailyStats{
+		{Day: today, WorkerID: "worker-a", Claimed: 5, Done: 5},
+		{Day: today, WorkerID: "worker-b", Claimed: 4, Done: 3, Failed: 1},
+		{Day: yesterday, WorkerID: "worker-a", Claimed: 10, Done: 10},
+	}}
+	live := fakeLiveCounts{
+		// worker-a kept going after its last flush
+		{WorkerID: "worker-a", Day: today, Counts: observability.JobCounts{Claimed: 8, Done: 7, Retried: 1}},
+		// worker-c has not flushed yet
+		{WorkerID: "worker-c", Day: today, Counts: observability.JobCounts{Claimed: 1, Done: 1}},
+	}
+
+	r := gin.New()
+	r.GET("/admin/jobs/stats", handlers.NewJobStatsHandler(repo, live).Daily)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs/stats?days=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	if !repo.from.Equal(yesterday) || !repo.to.Equal(today) {
+		t.Fatalf("expected range %s..%s, got %s..%s", yesterday, today, repo.from, repo.to)
+	}
+
+	type counts struct {
+		Claimed int64 `json:"claimed"`
+		Done    int64 `json:"done"`
+		Failed  int64 `json:"failed"`
+		Retried int64 `json:"retried"`
+	}
+	var got struct {
+		Days []struct {
+			Day     string `json:"day"`
+			Workers int    `json:"workers"`
+			counts
+		} `json:"days"`
+		Total counts `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(got.Days) != 2 || got.Days[0].Day != today.Format(time.DateOnly) || got.Days[1].Day != yesterday.Format(time.DateOnly) {
+		t.Fatalf("expected today then yesterday, got %s", w.Body.String())
+	}
+	if d := got.Days[0]; d.Workers != 3 || d.Claimed != 13 || d.Done != 11 || d.Failed != 1 || d.Retried != 1 {
+		t.Fatalf("unexpected merged day: %+v", d)
+	}
+	if d := got.Days[1]; d.Workers != 1 || d.Done != 10 {
+		t.Fatalf("yesterday should be persisted rows only: %+v", d)
+	}
+	if got.Total.Done != 21 || got.Total.Claimed != 23 {
+		t.Fatalf("unexpected total: %+v", got.Total)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs/stats?days=91", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for days over 90, got %d", w.Code)
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/idempotency"
 	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
 	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/exportstore"
 	"github.com/geocoder89/eventhub/internal/http/middlewares"
 	"github.com/geocoder89/eventhub/internal/jobs"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
@@ -23,6 +26,9 @@ import (
 
 type JobsCreator interface {
 	Create(ctx context.Context, req job.CreateRequest) (job.Job, error)
+	// CreateOrGet returns the job already holding req's idempotency key
+	// instead of failing, with created false.
+	CreateOrGet(ctx context.Context, req job.CreateRequest) (j job.Job, created bool, err error)
 	CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error)
 	GetByIdempotencyKey(ctx context.Context, key string) (job.Job, error)
 }
@@ -31,13 +37,49 @@ type RegistrationCSVExportsReader interface {
 	GetByJobID(ctx context.Context, jobID string) (registrationexport.CSVExport, error)
 }
 
+// IdempotentResponseStore keeps the first response sent for an idempotency key.
+type IdempotentResponseStore interface {
+	Save(ctx context.Context, resp idempotency.Response) error
+	Get(ctx context.Context, key string) (idempotency.Response, error)
+}
+
 type JobsHandler struct {
-	jobs    JobsCreator
-	exports RegistrationCSVExportsReader
+	jobs        JobsCreator
+	exports     RegistrationCSVExportsReader
+	exportStore exportstore.Store
+	responses   IdempotentResponseStore
+	keepMax     time.Duration
+	guard       *EnqueueGuard
+	batchEvents PublishBatchEvents
 }
 
 func NewJobsHandler(jobsRepo JobsCreator, exportsRepo RegistrationCSVExportsReader) *JobsHandler {
-	return &JobsHandler{jobs: jobsRepo, exports: exportsRepo}
+	return &JobsHandler{jobs: jobsRepo, exports: exportsRepo, keepMax: config.Config{}.ExportKeepMax()}
+}
+
+// WithResponseStore makes duplicate publish requests replay the original 202 body.
+func (h *JobsHandler) WithResponseStore(store IdempotentResponseStore) *JobsHandler {
+	h.responses = store
+	return h
+}
+
+// WithExportKeepMax bounds how far ahead an export request may set keepUntil.
+func (h *JobsHandler) WithExportKeepMax(d time.Duration) *JobsHandler {
+	h.keepMax = d
+	return h
+}
+
+// WithEnqueueGuard refuses publishes and exports while the queue is
+// overloaded; see EnqueueGuard.
+func (h *JobsHandler) WithEnqueueGuard(g *EnqueueGuard) *JobsHandler {
+	h.guard = g
+	return h
+}
+
+// WithExportStore lets downloads stream exports the worker wrote to the store.
+func (h *JobsHandler) WithExportStore(store exportstore.Store) *JobsHandler {
+	h.exportStore = store
+	return h
 }
 
 // POST /events/:id/publish
@@ -73,6 +115,17 @@ func (h *JobsHandler) PublishEvent(ctx *gin.Context) {
 		runAt = t.UTC()
 	}
 
+	priorityStr := ctx.Query("priority")
+	priority := job.PriorityNormal
+	if priorityStr != "" {
+		p, err := job.ParsePriority(priorityStr)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "priority must be high, normal or low")
+			return
+		}
+		priority = p
+	}
+
 	if !ok || userID == "" {
 		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
 		return
@@ -92,56 +145,247 @@ func (h *JobsHandler) PublishEvent(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
+
+	class := EnqueueStandard
+	if runAt.After(time.Now().UTC().Add(farFuturePublish)) {
+		class = EnqueueDeferrable
+	}
+	if !h.guard.Admit(ctx, cctx, class, jobs.TypeEventPublish) {
+		return
+	}
+
 	key := "publish:event:" + eventID
 
-	j, err := h.jobs.Create(cctx, job.CreateRequest{
+	j, created, err := h.jobs.CreateOrGet(cctx, job.CreateRequest{
 		Type:           jobs.TypeEventPublish,
 		Payload:        json.RawMessage(raw),
 		RunAt:          runAt,
 		MaxAttempts:    25,
 		IdempotencyKey: &key,
+		Priority:       priority,
 		UserID:         &userID,
 	})
+	if err != nil {
+		if respondPayloadTooLarge(ctx, err) {
+			return
+		}
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
+
+	if !created {
+		// only an identical request gets the existing job back
+		fields, derr := publishRequestDiff(j, raw, runAtStr != "", runAt, priorityStr != "", priority)
+		if derr != nil {
+			RespondInternal(ctx, "Could not enqueue job")
+			return
+		}
+		if len(fields) > 0 {
+			RespondError(ctx, http.StatusConflict, "idempotency_key_reuse",
+				"This event already has a publish job that does not match this request.",
+				gin.H{"idempotencyKey": key, "jobId": j.ID, "fields": fields})
+			return
+		}
+
+		h.respondAlreadyEnqueued(ctx, cctx, key, j)
+		return
+	}
+
+	h.respondEnqueued(ctx, cctx, &key, j)
+}
 
+// respondEnqueued answers 202 for a job just created. With a key, the body is
+// stored so duplicates replay it.
+func (h *JobsHandler) respondEnqueued(ctx *gin.Context, cctx context.Context, key *string, j job.Job) {
+	body, err := json.Marshal(gin.H{
+		"jobId":  j.ID,
+		"status": j.Status,
+		"type":   j.Type,
+	})
 	if err != nil {
-		if postgres.IsUniqueViolation(err) {
-			existing, gerr := h.jobs.GetByIdempotencyKey(cctx, key)
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
 
-			if gerr != nil {
-				RespondInternal(ctx, "Could not enqueue job")
-				return
-			}
+	// the job exists either way; a lost response record only means a duplicate
+	// is rebuilt from the job instead of replayed
+	if h.responses != nil && key != nil {
+		jobID := j.ID
+		if serr := h.responses.Save(cctx, idempotency.Response{
+			Key:        *key,
+			StatusCode: http.StatusAccepted,
+			Body:       body,
+			JobID:      &jobID,
+		}); serr != nil {
+			slog.Default().WarnContext(cctx, "idempotent_response.save_failed", "key", *key, "err", serr)
+		}
+	}
 
-			ctx.JSON(http.StatusAccepted, gin.H{
-				"jobId":           existing.ID,
-				"status":          existing.Status,
-				"type":            existing.Type,
-				"alreadyEnqueued": true,
-			})
-			ctx.Set(middlewares.CtxJobID, existing.ID)
-			slog.Default().InfoContext(cctx, "job.enqueue",
-				"request_id", requestIDFrom(ctx),
-				"job_id", existing.ID,
-				"job_type", existing.Type,
-				"already_enqueued", true,
-			)
+	ctx.Set(middlewares.CtxJobID, j.ID)
+	ctx.Data(http.StatusAccepted, "application/json; charset=utf-8", body)
+	slog.Default().InfoContext(cctx, "job.enqueue",
+		"request_id", requestIDFrom(ctx),
+		"job_id", j.ID,
+		"job_type", j.Type,
+		"already_enqueued", false,
+	)
+}
 
-			return
+// respondAlreadyEnqueued answers 202 for a duplicate of the request that
+// created existing under key.
+func (h *JobsHandler) respondAlreadyEnqueued(ctx *gin.Context, cctx context.Context, key string, existing job.Job) {
+	body, err := h.replayResponse(cctx, key, existing)
+	if err != nil {
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
+
+	ctx.Set(middlewares.CtxJobID, existing.ID)
+	ctx.Data(http.StatusAccepted, "application/json; charset=utf-8", body)
+	slog.Default().InfoContext(cctx, "job.enqueue",
+		"request_id", requestIDFrom(ctx),
+		"job_id", existing.ID,
+		"job_type", existing.Type,
+		"already_enqueued", true,
+	)
+}
+
+// publishRequestMetadata are the payload fields every publish request fills
+// in afresh; they never make two requests different.
+var publishRequestMetadata = []string{"requestedBy", "requestedAt", "requestId"}
+
+// publishRequestDiff names what a publish request asks for differently from
+// the job already holding its idempotency key: payload fields, and runAt and
+// priority when the caller set them. run_at only says what was asked for
+// until the job has been tried, so later it is not compared.
+func publishRequestDiff(existing job.Job, payload json.RawMessage, runAtGiven bool, runAt time.Time, priorityGiven bool, priority int) ([]string, error) {
+	fields, err := jobs.PayloadDiff(existing.Payload, payload, publishRequestMetadata...)
+	if err != nil {
+		return nil, err
+	}
+
+	untried := existing.Status == job.StatusPending && existing.Attempts == 0
+	if runAtGiven && untried && existing.RunAt.Sub(runAt).Abs() > time.Millisecond {
+		fields = append(fields, "runAt")
+	}
+	if priorityGiven && existing.Priority != priority {
+		fields = append(fields, "priority")
+	}
+	return fields, nil
+}
+
+// replayResponse returns the body for a duplicate submission: the stored
+// original with only status (live job state) and alreadyEnqueued merged in, so
+// every duplicate for the same job state is byte-identical.
+func (h *JobsHandler) replayResponse(ctx context.Context, key string, existing job.Job) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+
+	original, err := h.storedResponse(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if original != nil {
+		if err := json.Unmarshal(original, &fields); err != nil {
+			return nil, err
+		}
+	} else {
+		// enqueued before responses were stored (or the save failed)
+		fields["jobId"], _ = json.Marshal(existing.ID)
+		fields["type"], _ = json.Marshal(existing.Type)
+	}
+
+	fields["status"], _ = json.Marshal(existing.Status)
+	fields["alreadyEnqueued"] = json.RawMessage("true")
 
+	// map keys marshal in sorted order, keeping the output stable
+	return json.Marshal(fields)
+}
+
+func (h *JobsHandler) storedResponse(ctx context.Context, key string) ([]byte, error) {
+	if h.responses == nil {
+		return nil, nil
+	}
+
+	stored, err := h.responses.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, idempotency.ErrNotFound) {
+			return nil, nil
 		}
+		return nil, err
+	}
+	return stored.Body, nil
+}
+
+// respondPayloadTooLarge answers 413 with the measured payload when err is a
+// job payload limit violation, reporting whether it did.
+func respondPayloadTooLarge(ctx *gin.Context, err error) bool {
+	var limitErr *job.PayloadLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	details := gin.H{"size": limitErr.Size, "maxBytes": limitErr.MaxBytes}
+	if limitErr.TooDeep() {
+		details = gin.H{"size": limitErr.Size, "depth": limitErr.Depth, "maxDepth": limitErr.MaxDepth}
+	}
+	RespondError(ctx, http.StatusRequestEntityTooLarge, "payload_too_large", "Job payload exceeds the enqueue limits", details)
+	return true
+}
+
+// PayloadReportRequest is the optional body of the payload report request.
+type PayloadReportRequest struct {
+	MaxBytes int `json:"maxBytes" binding:"omitempty,min=1"`
+	Limit    int `json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// POST /admin/jobs/payload-report
+//
+// Enqueues a read-only jobs.payload_report; the offenders are the job's
+// result, readable from GET /admin/jobs/:id once it is done.
+func (h *JobsHandler) RequestPayloadReport(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	var req PayloadReportRequest
+	if ctx.Request.ContentLength != 0 && !BindJSON(ctx, &req) {
+		return
+	}
 
+	raw, err := jobs.JobsPayloadReportPayload{MaxBytes: req.MaxBytes, Limit: req.Limit}.JSON()
+	if err != nil {
 		RespondInternal(ctx, "Could not enqueue job")
 		return
 	}
 
-	ctx.JSON(http.StatusAccepted, gin.H{
-		"jobId":  j.ID,
-		"status": j.Status,
-		"type":   j.Type,
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if !h.guard.Admit(ctx, cctx, EnqueueDeferrable, jobs.TypeJobsPayloadReport) {
+		return
+	}
+
+	j, err := h.jobs.Create(cctx, job.CreateRequest{
+		Type:        jobs.TypeJobsPayloadReport,
+		Payload:     raw,
+		RunAt:       time.Now().UTC(),
+		MaxAttempts: 3,
+		UserID:      &userID,
 	})
+	if err != nil {
+		if respondPayloadTooLarge(ctx, err) {
+			return
+		}
+		RespondInternal(ctx, "Could not enqueue job")
+		return
+	}
+
 	ctx.Set(middlewares.CtxJobID, j.ID)
 	slog.Default().InfoContext(cctx, "job.enqueue",
 		"request_id", requestIDFrom(ctx),
@@ -150,6 +394,18 @@ func (h *JobsHandler) PublishEvent(ctx *gin.Context) {
 		"already_enqueued", false,
 	)
 
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"jobId":      j.ID,
+		"status":     j.Status,
+		"type":       j.Type,
+		"statusPath": "/admin/jobs/" + j.ID,
+	})
+}
+
+// ExportRegistrationsRequest is the optional body of the export request.
+type ExportRegistrationsRequest struct {
+	KeepUntil       *time.Time `json:"keepUntil"`
+	IncludeActivity bool       `json:"includeActivity"`
 }
 
 // POST /events/:id/registrations/export
@@ -166,11 +422,37 @@ func (h *JobsHandler) ExportRegistrationsCSV(ctx *gin.Context) {
 		return
 	}
 
+	// the body is optional; without one the export follows the retention
+	var req ExportRegistrationsRequest
+	if ctx.Request.ContentLength != 0 && !BindJSON(ctx, &req) {
+		return
+	}
+	if req.KeepUntil != nil {
+		now := time.Now().UTC()
+		if !req.KeepUntil.After(now) || req.KeepUntil.After(now.Add(h.keepMax)) {
+			RespondError(ctx, http.StatusBadRequest, "invalid_keep_until",
+				"keepUntil must be in the future and within the allowed maximum.",
+				gin.H{"maxDays": int(h.keepMax / (24 * time.Hour))})
+			return
+		}
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if !h.guard.Admit(ctx, cctx, EnqueueDeferrable, jobs.TypeRegistrationsExportCSV) {
+		return
+	}
+
 	payload := jobs.RegistrationsExportCSVPayload{
-		EventID:     eventID,
-		RequestedBy: userID,
-		RequestedAt: time.Now().UTC(),
-		RequestID:   requestIDFrom(ctx),
+		EventID:         eventID,
+		RequestedBy:     userID,
+		RequestedAt:     time.Now().UTC(),
+		RequestID:       requestIDFrom(ctx),
+		KeepUntil:       req.KeepUntil,
+		IncludeActivity: req.IncludeActivity,
+		// the route is admin-only, so the export carries every field
+		PIIVisibility: string(registration.PIIFull),
 	}
 
 	raw, err := payload.JSON()
@@ -179,9 +461,11 @@ func (h *JobsHandler) ExportRegistrationsCSV(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
-	defer cancel()
-	key := "registrations:export_csv:event:" + eventID + ":user:" + userID
+	// one export per event per day; asking again the same day returns that job
+	key := "registrations:export_csv:event:" + eventID + ":day:" + time.Now().UTC().Format("2006-01-02")
+	if req.IncludeActivity {
+		key += ":activity"
+	}
 
 	j, err := h.jobs.Create(cctx, job.CreateRequest{
 		Type:           jobs.TypeRegistrationsExportCSV,
@@ -193,6 +477,9 @@ func (h *JobsHandler) ExportRegistrationsCSV(ctx *gin.Context) {
 		Priority:       1,
 	})
 	if err != nil {
+		if respondPayloadTooLarge(ctx, err) {
+			return
+		}
 		if postgres.IsUniqueViolation(err) {
 			existing, gerr := h.jobs.GetByIdempotencyKey(cctx, key)
 			if gerr != nil {
@@ -212,6 +499,7 @@ func (h *JobsHandler) ExportRegistrationsCSV(ctx *gin.Context) {
 				"jobId":           existing.ID,
 				"status":          existing.Status,
 				"type":            existing.Type,
+				"statusPath":      "/admin/exports/" + existing.ID,
 				"downloadPath":    "/admin/jobs/" + existing.ID + "/registrations-export.csv",
 				"alreadyEnqueued": true,
 			})
@@ -234,6 +522,7 @@ func (h *JobsHandler) ExportRegistrationsCSV(ctx *gin.Context) {
 		"jobId":           j.ID,
 		"status":          j.Status,
 		"type":            j.Type,
+		"statusPath":      "/admin/exports/" + j.ID,
 		"downloadPath":    "/admin/jobs/" + j.ID + "/registrations-export.csv",
 		"alreadyEnqueued": false,
 	})
@@ -252,7 +541,7 @@ func (h *JobsHandler) DownloadRegistrationsCSV(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	exported, err := h.exports.GetByJobID(cctx, jobID)
@@ -275,8 +564,33 @@ func (h *JobsHandler) DownloadRegistrationsCSV(ctx *gin.Context) {
 		fileName = "registrations.csv"
 	}
 
-	ctx.Header("Content-Type", contentType)
-	ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
-	ctx.Header("X-Export-Row-Count", strconv.Itoa(exported.RowCount))
-	ctx.Data(http.StatusOK, contentType, exported.Data)
+	// exports written before the store existed still live in the row itself
+	if exported.StoragePath == "" {
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+		ctx.Header("X-Export-Row-Count", strconv.Itoa(exported.RowCount))
+		ctx.Data(http.StatusOK, contentType, exported.Data)
+		return
+	}
+
+	if h.exportStore == nil {
+		RespondInternal(ctx, "Export store not configured")
+		return
+	}
+
+	file, err := h.exportStore.Open(ctx.Request.Context(), exported.StoragePath)
+	if err != nil {
+		if errors.Is(err, exportstore.ErrNotFound) {
+			RespondNotFound(ctx, "Export file not found")
+			return
+		}
+		RespondInternal(ctx, "Could not open export")
+		return
+	}
+	defer file.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, contentType, file, map[string]string{
+		"Content-Disposition": `attachment; filename="` + fileName + `"`,
+		"X-Export-Row-Count":  strconv.Itoa(exported.RowCount),
+	})
 }
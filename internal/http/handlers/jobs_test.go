@@ -0,0 +1,381 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/idempotency"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// publishJobsRepo enforces one job per idempotency key like the unique index does.
+type publishJobsRepo struct {
+	byKey    map[string]job.Job
+	lookupFn func(key string) (job.Job, error)
+}
+
+func (r *publishJobsRepo) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
+	j := job.Job{ID: newUUID(), Type: req.Type, Status: job.StatusPending, Payload: req.Payload, RunAt: req.RunAt,
+		MaxAttempts: req.MaxAttempts, Priority: req.Priority}
+
+	// keyless jobs are kept under their id
+	key := j.ID
+	if req.IdempotencyKey != nil {
+		key = *req.IdempotencyKey
+	}
+	if _, ok := r.byKey[key]; ok {
+		return job.Job{}, &pgconn.PgError{Code: "23505"}
+	}
+	r.byKey[key] = j
+	return j, nil
+}
+
+func (r *publishJobsRepo) CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error) {
+	return r.Create(ctx, req)
+}
+
+func (r *publishJobsRepo) CreateOrGet(ctx context.Context, req job.CreateRequest) (job.Job, bool, error) {
+	j, err := r.Create(ctx, req)
+	var pgErr *pgconn.PgError
+	if req.IdempotencyKey == nil || !errors.As(err, &pgErr) {
+		return j, err == nil, err
+	}
+	existing, err := r.GetByIdempotencyKey(ctx, *req.IdempotencyKey)
+	return existing, false, err
+}
+
+func (r *publishJobsRepo) GetByIdempotencyKey(ctx context.Context, key string) (job.Job, error) {
+	if r.lookupFn != nil {
+		return r.lookupFn(key)
+	}
+	j, ok := r.byKey[key]
+	if !ok {
+		return job.Job{}, job.ErrJobNotFound
+	}
+	return j, nil
+}
+
+type memoryResponseStore struct {
+	saved map[string]idempotency.Response
+}
+
+func (s *memoryResponseStore) Save(ctx context.Context, resp idempotency.Response) error {
+	if _, ok := s.saved[resp.Key]; !ok {
+		s.saved[resp.Key] = resp
+	}
+	return nil
+}
+
+func (s *memoryResponseStore) Get(ctx context.Context, key string) (idempotency.Response, error) {
+	resp, ok := s.saved[key]
+	if !ok {
+		return idempotency.Response{}, idempotency.ErrNotFound
+	}
+	return resp, nil
+}
+
+func newPublishRouter(h *handlers.JobsHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/admin/events/:id/publish", withUser(newUUID(), "admin"), h.PublishEvent)
+	return r
+}
+
+func doPublish(r http.Handler, eventID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/publish", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPublishEvent_DuplicateLookupErrorWritesOnce(t *testing.T) {
+	eventID := newUUID()
+	repo := &publishJobsRepo{
+		byKey:    map[string]job.Job{"publish:event:" + eventID: {ID: newUUID()}},
+		lookupFn: func(string) (job.Job, error) { return job.Job{}, errors.New("db down") },
+	}
+
+	w := doPublish(newPublishRouter(handlers.NewJobsHandler(repo, nil)), eventID)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want 500, body=%s", w.Code, w.Body.String())
+	}
+
+	// exactly one JSON document: an error, never followed by a 202 body
+	dec := json.NewDecoder(w.Body)
+	var first map[string]any
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := first["error"]; !ok {
+		t.Fatalf("expected error body, got %v", first)
+	}
+	if dec.More() {
+		t.Fatalf("expected a single response body, got trailing data")
+	}
+}
+
+func TestPublishEvent_DuplicateReplaysOriginalResponse(t *testing.T) {
+	eventID := newUUID()
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	store := &memoryResponseStore{saved: map[string]idempotency.Response{}}
+	r := newPublishRouter(handlers.NewJobsHandler(repo, nil).WithResponseStore(store))
+
+	w1 := doPublish(r, eventID)
+	if w1.Code != http.StatusAccepted {
+		t.Fatalf("first publish got %d body=%s", w1.Code, w1.Body.String())
+	}
+	if _, ok := store.saved["publish:event:"+eventID]; !ok {
+		t.Fatalf("expected first response to be stored")
+	}
+
+	// the job finishes between the duplicates
+	key := "publish:event:" + eventID
+	done := repo.byKey[key]
+	done.Status = job.StatusDone
+	repo.byKey[key] = done
+
+	w2 := doPublish(r, eventID)
+	w3 := doPublish(r, eventID)
+	if w2.Code != http.StatusAccepted || w3.Code != http.StatusAccepted {
+		t.Fatalf("duplicates got %d/%d", w2.Code, w3.Code)
+	}
+
+	if w2.Body.String() != w3.Body.String() {
+		t.Fatalf("duplicate responses differ:\n%s\n%s", w2.Body.String(), w3.Body.String())
+	}
+
+	var original, replay map[string]any
+	if err := json.Unmarshal(w1.Body.Bytes(), &original); err != nil {
+		t.Fatalf("decode original: %v", err)
+	}
+	if err := json.Unmarshal(w2.Body.Bytes(), &replay); err != nil {
+		t.Fatalf("decode replay: %v", err)
+	}
+
+	if replay["jobId"] != original["jobId"] || replay["type"] != original["type"] {
+		t.Fatalf("replay does not match original: original=%v replay=%v", original, replay)
+	}
+	if replay["status"] != string(job.StatusDone) {
+		t.Fatalf("expected live status done in replay, got %v", replay["status"])
+	}
+	if replay["alreadyEnqueued"] != true {
+		t.Fatalf("expected alreadyEnqueued=true in replay, got %v", replay["alreadyEnqueued"])
+	}
+}
+
+func TestPublishEvent_DuplicateWithoutStoredResponse(t *testing.T) {
+	eventID := newUUID()
+	existing := job.Job{ID: newUUID(), Type: "event.publish", Status: job.StatusProcessing,
+		Payload: json.RawMessage(`{"eventId":"` + eventID + `","requestedBy":"someone-else"}`)}
+	repo := &publishJobsRepo{byKey: map[string]job.Job{"publish:event:" + eventID: existing}}
+	store := &memoryResponseStore{saved: map[string]idempotency.Response{}}
+
+	w := doPublish(newPublishRouter(handlers.NewJobsHandler(repo, nil).WithResponseStore(store)), eventID)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got["jobId"] != existing.ID || got["status"] != string(job.StatusProcessing) || got["alreadyEnqueued"] != true {
+		t.Fatalf("unexpected rebuilt response: %v", got)
+	}
+}
+
+func TestPublishEvent_DuplicateWithDifferentRequestIsConflict(t *testing.T) {
+	eventID := newUUID()
+	runAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	r := newPublishRouter(handlers.NewJobsHandler(repo, nil))
+
+	doAt := func(at time.Time) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/publish?runAt="+at.Format(time.RFC3339), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := doAt(runAt); w.Code != http.StatusAccepted {
+		t.Fatalf("first publish got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// same request, fresh requestedAt/requestId: still a replay
+	if w := doAt(runAt); w.Code != http.StatusAccepted {
+		t.Fatalf("identical duplicate got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doPublish(r, eventID); w.Code != http.StatusAccepted {
+		t.Fatalf("duplicate without runAt got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				JobID  string   `json:"jobId"`
+				Fields []string `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	w := doAt(runAt.Add(time.Hour))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("different runAt got %d body=%s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	key := "publish:event:" + eventID
+	if body.Error.Code != "idempotency_key_reuse" || body.Error.Details.JobID != repo.byKey[key].ID ||
+		len(body.Error.Details.Fields) != 1 || body.Error.Details.Fields[0] != "runAt" {
+		t.Fatalf("unexpected conflict body: %s", w.Body.String())
+	}
+
+	// a key holding some other event's payload
+	stale := repo.byKey[key]
+	stale.Payload = json.RawMessage(`{"eventId":"` + newUUID() + `"}`)
+	repo.byKey[key] = stale
+	w = doPublish(r, eventID)
+	body.Error.Details.Fields = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if w.Code != http.StatusConflict || len(body.Error.Details.Fields) != 1 || body.Error.Details.Fields[0] != "eventId" {
+		t.Fatalf("expected a conflict on eventId, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestPublishEvent_Priority(t *testing.T) {
+	repo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	r := newPublishRouter(handlers.NewJobsHandler(repo, nil))
+
+	do := func(eventID, priority string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/publish?priority="+priority, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		priority string
+		want     int
+	}{
+		{"high", job.PriorityHigh},
+		{"normal", job.PriorityNormal},
+		{"LOW", job.PriorityLow},
+		{"", job.PriorityNormal},
+	}
+	for _, tc := range tests {
+		eventID := newUUID()
+		if w := do(eventID, tc.priority); w.Code != http.StatusAccepted {
+			t.Fatalf("priority %q: got %d body=%s", tc.priority, w.Code, w.Body.String())
+		}
+		if got := repo.byKey["publish:event:"+eventID].Priority; got != tc.want {
+			t.Fatalf("priority %q: job priority = %d, want %d", tc.priority, got, tc.want)
+		}
+	}
+
+	if w := do(newUUID(), "urgent"); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown priority got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// a duplicate asking for another band is a different request
+	eventID := newUUID()
+	do(eventID, "low")
+	if w := do(eventID, "low"); w.Code != http.StatusAccepted {
+		t.Fatalf("identical duplicate got %d body=%s", w.Code, w.Body.String())
+	}
+	w := do(eventID, "high")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"priority"`) {
+		t.Fatalf("expected a conflict on priority, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+// limitedJobsRepo enforces payload limits the way JobsRepo does.
+type limitedJobsRepo struct {
+	limits  job.PayloadLimits
+	created []job.CreateRequest
+}
+
+func (r *limitedJobsRepo) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
+	if err := r.limits.Check(req.Payload); err != nil {
+		return job.Job{}, err
+	}
+	r.created = append(r.created, req)
+	return job.Job{ID: newUUID(), Type: req.Type, Status: job.StatusPending}, nil
+}
+
+func (r *limitedJobsRepo) CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error) {
+	return r.Create(ctx, req)
+}
+
+func (r *limitedJobsRepo) CreateOrGet(ctx context.Context, req job.CreateRequest) (job.Job, bool, error) {
+	j, err := r.Create(ctx, req)
+	return j, err == nil, err
+}
+
+func (r *limitedJobsRepo) GetByIdempotencyKey(ctx context.Context, key string) (job.Job, error) {
+	return job.Job{}, job.ErrJobNotFound
+}
+
+func TestPublishEvent_OversizedPayloadIs413WithMeasuredSize(t *testing.T) {
+	// a publish payload is a little over 200 bytes
+	repo := &limitedJobsRepo{limits: job.PayloadLimits{MaxBytes: 64}}
+
+	w := doPublish(newPublishRouter(handlers.NewJobsHandler(repo, nil)), newUUID())
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Details map[string]int `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Error.Code != "payload_too_large" || body.Error.Details["maxBytes"] != 64 || body.Error.Details["size"] <= 64 {
+		t.Fatalf("unexpected error body: %s", w.Body.String())
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("nothing should be enqueued, got %d", len(repo.created))
+	}
+}
+
+func TestRequestPayloadReport_EnqueuesReadOnlyReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &limitedJobsRepo{limits: job.DefaultPayloadLimits}
+	r := gin.New()
+	r.POST("/admin/jobs/payload-report", withUser(newUUID(), "admin"), handlers.NewJobsHandler(repo, nil).RequestPayloadReport)
+
+	w := postJSON(r, "/admin/jobs/payload-report", `{"maxBytes":1024,"limit":10}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d body=%s", w.Code, w.Body.String())
+	}
+	if len(repo.created) != 1 || repo.created[0].Type != "jobs.payload_report" {
+		t.Fatalf("expected one report job, got %+v", repo.created)
+	}
+	var payload map[string]int
+	if err := json.Unmarshal(repo.created[0].Payload, &payload); err != nil || payload["maxBytes"] != 1024 || payload["limit"] != 10 {
+		t.Fatalf("unexpected report payload %s (err=%v)", repo.created[0].Payload, err)
+	}
+
+	w = postJSON(r, "/admin/jobs/payload-report", `{"limit":5000}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for limit over 1000, got %d", w.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MyRegistrationsRepo lists and claims the caller's own registrations.
+type MyRegistrationsRepo interface {
+	ListByUserCursor(ctx context.Context, userID string, limit int, afterStartAt time.Time, afterID string) ([]registration.WithEvent, *string, bool, error)
+	ClaimByVerifiedEmail(ctx context.Context, userID string) (int64, error)
+}
+
+type MyRegistrationsHandler struct {
+	repo MyRegistrationsRepo
+}
+
+func NewMyRegistrationsHandler(repo MyRegistrationsRepo) *MyRegistrationsHandler {
+	return &MyRegistrationsHandler{repo: repo}
+}
+
+// GET /me/registrations?limit=20&cursor=...: the caller's registrations with
+// their events, soonest event first.
+func (h *MyRegistrationsHandler) List(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	limit := parseIntDefault(ctx.Query("limit"), 20)
+	if limit < 1 || limit > 100 {
+		RespondBadRequest(ctx, "invalid_query", "limit must be between 1 and 100")
+		return
+	}
+
+	afterStartAt := time.Unix(0, 0).UTC()
+	afterID := "00000000-0000-0000-0000-000000000000"
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		cur, err := utils.DecodeEventCursor(cursor)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "cursor is invalid")
+			return
+		}
+		afterStartAt = cur.StartAt
+		afterID = cur.ID
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, next, hasMore, err := h.repo.ListByUserCursor(cctx, userID, limit, afterStartAt, afterID)
+	if err != nil {
+		RespondInternal(ctx, "Could not list registrations")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BuildCursorPageResponse(limit, items, hasMore, next, nil))
+}
+
+// POST /me/registrations/claim: attach anonymous registrations made with the
+// caller's verified email to their account.
+func (h *MyRegistrationsHandler) Claim(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	claimed, err := h.repo.ClaimByVerifiedEmail(cctx, userID)
+	if err != nil {
+		if errors.Is(err, registration.ErrEmailNotVerified) {
+			RespondError(ctx, http.StatusForbidden, "email_not_verified", "Verify your email (POST /me/email-verification) before claiming registrations", nil)
+			return
+		}
+		RespondInternal(ctx, "Could not claim registrations")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"claimed": claimed})
+}
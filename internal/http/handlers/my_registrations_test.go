@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeMyRegistrations struct {
+	gotUser    string
+	gotLimit   int
+	gotAfterID string
+	claimErr   error
+}
+
+func (f *fakeMyRegistrations) ListByUserCursor(ctx context.Context, userID string, limit int, afterStartAt time.Time, afterID string) ([]registration.WithEvent, *string, bool, error) {
+	f.gotUser, f.gotLimit, f.gotAfterID = userID, limit, afterID
+	return []registration.WithEvent{{EventTitle: "Go Meetup", EventCity: "Lagos"}}, nil, false, nil
+}
+
+func (f *fakeMyRegistrations) ClaimByVerifiedEmail(ctx context.Context, userID string) (int64, error) {
+	f.gotUser = userID
+	return 2, f.claimErr
+}
+
+func TestMyRegistrations_ListUsesCallerAndCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeMyRegistrations{}
+	userID := newUUID()
+	r := gin.New()
+	r.GET("/me/registrations", withUser(userID, "user"), handlers.NewMyRegistrationsHandler(repo).List)
+
+	afterID := newUUID()
+	cursor, err := utils.EncodeEventCursor(time.Now().UTC(), afterID)
+	if err != nil {
+		t.Fatalf("cursor: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me/registrations?limit=5&cursor="+cursor, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	if repo.gotUser != userID || repo.gotLimit != 5 || repo.gotAfterID != afterID {
+		t.Fatalf("unexpected call user=%s limit=%d after=%s", repo.gotUser, repo.gotLimit, repo.gotAfterID)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me/registrations?cursor=nope", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad cursor, got %d", w.Code)
+	}
+}
+
+func TestMyRegistrations_ClaimRequiresVerifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeMyRegistrations{claimErr: registration.ErrEmailNotVerified}
+	r := gin.New()
+	r.POST("/me/registrations/claim", withUser(newUUID(), "user"), handlers.NewMyRegistrationsHandler(repo).Claim)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/me/registrations/claim", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	repo.claimErr = nil
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/me/registrations/claim", nil))
+	var got struct {
+		Claimed int `json:"claimed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK || got.Claimed != 2 {
+		t.Fatalf("expected 2 claimed, got %d body=%s", w.Code, w.Body.String())
+	}
+}
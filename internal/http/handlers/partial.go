@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Degraded sections a partial response can report in meta.warnings.
+const (
+	WarningCountUnavailable = "count_unavailable"
+	WarningItemErrors       = "item_errors"
+)
+
+// PartialWarning describes one section of a response that could not be
+// produced; IDs names the items concerned, when there are any.
+type PartialWarning struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	IDs     []string `json:"ids,omitempty"`
+}
+
+// partialResult collects the degraded sections of one multi-part response,
+// so every such endpoint answers them the same way through respondPartial.
+type partialResult struct {
+	warnings []PartialWarning
+}
+
+func (p *partialResult) warn(code, message string, ids ...string) {
+	p.warnings = append(p.warnings, PartialWarning{Code: code, Message: message, IDs: ids})
+}
+
+func (p *partialResult) degraded() bool {
+	return len(p.warnings) > 0
+}
+
+// preferStrict reports whether the client refuses partial results, with
+// Prefer: strict or the RFC 7240 handling=strict.
+func preferStrict(ctx *gin.Context) bool {
+	for _, header := range ctx.Request.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			pref = strings.ToLower(strings.TrimSpace(pref))
+			if pref == "strict" || pref == "handling=strict" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// respondPartial writes body with a 200 and, when p has warnings, adds them
+// as meta.warnings with X-Partial-Result: true, or answers 503
+// partial_result under Prefer: strict. Complete bodies get an ETag; partial
+// ones do not, so a degraded answer is never revalidated as current.
+func respondPartial(ctx *gin.Context, body gin.H, p *partialResult) {
+	ctx.Header("Vary", "Prefer")
+	if !p.degraded() {
+		RespondJSONWithETag(ctx, http.StatusOK, body)
+		return
+	}
+
+	if preferStrict(ctx) {
+		RespondError(ctx, http.StatusServiceUnavailable, "partial_result",
+			"Part of the response is unavailable and the request asked for strict handling.",
+			gin.H{"warnings": p.warnings})
+		return
+	}
+
+	body["meta"] = gin.H{"warnings": p.warnings}
+	ctx.Header("X-Partial-Result", "true")
+	ctx.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,198 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type partialBody struct {
+	Items    []event.Event `json:"items"`
+	Total    *int          `json:"total"`
+	NotFound []string      `json:"notFound"`
+	Meta     struct {
+		Warnings []handlers.PartialWarning `json:"warnings"`
+	} `json:"meta"`
+	Error struct {
+		Code string `json:"code"`
+	} `json:"error"`
+}
+
+func doPartial(t *testing.T, repo *fakeEventsRepo, path string, prefer string) (*httptest.ResponseRecorder, partialBody) {
+	t.Helper()
+
+	h := handlers.NewEventsHandler(repo)
+	r := gin.New()
+	r.GET("/events", h.ListEvents)
+	r.GET("/events/batch", h.GetEventsBatch)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if prefer != "" {
+		req.Header.Set("Prefer", prefer)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var body partialBody
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestPartialResult_ListWithoutTotal(t *testing.T) {
+	repo := &fakeEventsRepo{
+		listCursorFn: func(ctx context.Context, filters event.ListEventsFilter, afterStartAt time.Time, afterID string) ([]event.Event, *string, bool, error) {
+			return []event.Event{{ID: newUUID(), Title: "Go Meetup"}}, nil, false, nil
+		},
+		countFn: func(ctx context.Context, filters event.ListEventsFilter) (int, error) {
+			return 0, context.DeadlineExceeded
+		},
+	}
+
+	w, body := doPartial(t, repo, "/events?includeTotal=true", "")
+	if w.Code != http.StatusOK || w.Header().Get("X-Partial-Result") != "true" {
+		t.Fatalf("expected a 200 partial result, got %d %q: %s", w.Code, w.Header().Get("X-Partial-Result"), w.Body.String())
+	}
+	if len(body.Items) != 1 || body.Total != nil {
+		t.Fatalf("expected the page without its total, got %+v", body)
+	}
+	if len(body.Meta.Warnings) != 1 || body.Meta.Warnings[0].Code != handlers.WarningCountUnavailable {
+		t.Fatalf("expected a count_unavailable warning, got %+v", body.Meta.Warnings)
+	}
+	if w.Header().Get("ETag") != "" {
+		t.Fatalf("expected no ETag on a partial result")
+	}
+
+	w, body = doPartial(t, repo, "/events?includeTotal=true", "strict")
+	if w.Code != http.StatusServiceUnavailable || body.Error.Code != "partial_result" {
+		t.Fatalf("expected 503 partial_result under Prefer: strict, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// a complete answer is the same under either preference
+	repo.countFn = nil
+	for _, prefer := range []string{"", "handling=strict"} {
+		w, body = doPartial(t, repo, "/events?includeTotal=true", prefer)
+		if w.Code != http.StatusOK || w.Header().Get("X-Partial-Result") != "" || body.Total == nil || len(body.Meta.Warnings) != 0 {
+			t.Fatalf("prefer %q: expected a complete result, got %d: %s", prefer, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestPartialResult_BatchItemErrors(t *testing.T) {
+	ok, missing, flaky := newUUID(), newUUID(), newUUID()
+	repo := &fakeEventsRepo{
+		getFn: func(ctx context.Context, id string) (event.Event, error) {
+			switch id {
+			case missing:
+				return event.Event{}, event.ErrNotFound
+			case flaky:
+				return event.Event{}, errors.New("connection reset")
+			}
+			return event.Event{ID: id}, nil
+		},
+	}
+
+	path := "/events/batch?ids=" + strings.Join([]string{ok, missing, flaky}, ",")
+	w, body := doPartial(t, repo, path, "")
+	if w.Code != http.StatusOK || w.Header().Get("X-Partial-Result") != "true" {
+		t.Fatalf("expected a 200 partial result, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(body.Items) != 1 || body.Items[0].ID != ok || len(body.NotFound) != 1 || body.NotFound[0] != missing {
+		t.Fatalf("expected the good event and the missing id, got %+v", body)
+	}
+	if len(body.Meta.Warnings) != 1 || body.Meta.Warnings[0].Code != handlers.WarningItemErrors ||
+		len(body.Meta.Warnings[0].IDs) != 1 || body.Meta.Warnings[0].IDs[0] != flaky {
+		t.Fatalf("expected item_errors naming %s, got %+v", flaky, body.Meta.Warnings)
+	}
+
+	w, _ = doPartial(t, repo, path, "respond-async, strict")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 under Prefer: strict, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// not found is an answer, not a degraded section
+	w, _ = doPartial(t, repo, "/events/batch?ids="+ok+","+missing, "strict")
+	if w.Code != http.StatusOK || w.Header().Get("X-Partial-Result") != "" {
+		t.Fatalf("expected a complete result, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEventsBatch_ValidatesIDs(t *testing.T) {
+	tooMany := make([]string, handlers.MaxEventsBatch+1)
+	for i := range tooMany {
+		tooMany[i] = newUUID()
+	}
+
+	for _, path := range []string{"/events/batch", "/events/batch?ids=nope", "/events/batch?ids=" + strings.Join(tooMany, ",")} {
+		if w, _ := doPartial(t, &fakeEventsRepo{}, path, ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
+
+// batchEventsRepo loads a batch in one call.
+type batchEventsRepo struct {
+	*fakeEventsRepo
+	batches int
+	err     error
+	events  map[string]event.Event
+}
+
+func (r *batchEventsRepo) GetByIDs(ctx context.Context, ids []string) ([]event.Event, error) {
+	r.batches++
+	if r.err != nil {
+		return nil, r.err
+	}
+	var out []event.Event
+	for _, id := range ids {
+		if e, ok := r.events[id]; ok {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func TestEventsBatch_LoadsTheBatchInOneQuery(t *testing.T) {
+	a, b, missing := newUUID(), newUUID(), newUUID()
+	repo := &batchEventsRepo{
+		fakeEventsRepo: &fakeEventsRepo{getFn: func(ctx context.Context, id string) (event.Event, error) {
+			t.Fatalf("unexpected GetByID(%s) for a batch", id)
+			return event.Event{}, nil
+		}},
+		events: map[string]event.Event{a: {ID: a}, b: {ID: b}},
+	}
+	r := gin.New()
+	r.GET("/events/batch", handlers.NewEventsHandler(repo).GetEventsBatch)
+
+	get := func() (*httptest.ResponseRecorder, partialBody) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/batch?ids="+strings.Join([]string{b, missing, a}, ","), nil))
+		var body partialBody
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	w, body := get()
+	if w.Code != http.StatusOK || repo.batches != 1 {
+		t.Fatalf("expected one batch read, got %d reads and %d: %s", repo.batches, w.Code, w.Body.String())
+	}
+	if len(body.Items) != 2 || body.Items[0].ID != b || body.Items[1].ID != a || len(body.NotFound) != 1 || body.NotFound[0] != missing {
+		t.Fatalf("expected the events in the order asked and the missing id, got %+v", body)
+	}
+
+	// a failed read fails every id, as a partial result
+	repo.err = errors.New("connection reset")
+	w, body = get()
+	if w.Code != http.StatusOK || w.Header().Get("X-Partial-Result") != "true" ||
+		len(body.Meta.Warnings) != 1 || len(body.Meta.Warnings[0].IDs) != 3 {
+		t.Fatalf("expected every id reported failed, got %d: %s", w.Code, w.Body.String())
+	}
+}
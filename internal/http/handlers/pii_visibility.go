@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// callerPIIVisibility resolves how much attendee PII the caller may see on
+// eventID's registrations: admins and the event's organizer see it all,
+// everyone else gets the masked projection. Without an organizers reader
+// only admins see it all.
+func callerPIIVisibility(ctx *gin.Context, cctx context.Context, organizers EventOrganizersReader, eventID string) (registration.PIIVisibility, error) {
+	if role, _ := middlewares.RoleFromContext(ctx); role == user.RoleAdmin {
+		return registration.PIIFull, nil
+	}
+
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" || organizers == nil {
+		return registration.PIIMasked, nil
+	}
+
+	byEvent, err := organizers.Organizers(cctx, []string{eventID})
+	if err != nil {
+		return "", err
+	}
+	if organizerID, found := byEvent[eventID]; found && organizerID == userID {
+		return registration.PIIFull, nil
+	}
+	return registration.PIIMasked, nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/geocoder89/eventhub/internal/domain/privacy"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+type UserEraser interface {
+	EraseByEmail(ctx context.Context, req privacy.ErasureRequest) (privacy.ErasureSummary, error)
+}
+
+type PrivacyHandler struct {
+	repo UserEraser
+}
+
+func NewPrivacyHandler(repo UserEraser) *PrivacyHandler {
+	return &PrivacyHandler{repo: repo}
+}
+
+// EraseUser handles DELETE /admin/privacy/users?email=...: the right-to-erasure
+// request. Unknown addresses still succeed with zero counts, so the answer
+// does not reveal whether someone had an account.
+func (h *PrivacyHandler) EraseUser(ctx *gin.Context) {
+	// keep the address out of the admin audit trail too
+	ctx.Set(middlewares.CtxAuditQuery, "email="+privacy.Redacted)
+
+	email := strings.TrimSpace(ctx.Query("email"))
+	if _, err := mail.ParseAddress(email); err != nil || len(email) > 254 || strings.ContainsAny(email, "<> ") {
+		RespondBadRequest(ctx, "invalid_query", "email must be a valid email address")
+		return
+	}
+
+	actorID, _ := middlewares.UserIDFromContext(ctx)
+	actorEmail, _ := middlewares.EmailFromContext(ctx)
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	summary, err := h.repo.EraseByEmail(cctx, privacy.ErasureRequest{
+		Email:             email,
+		RequestedByUserID: actorID,
+		RequestedByEmail:  actorEmail,
+		RequestID:         requestIDFrom(ctx),
+	})
+	if err != nil {
+		slog.Default().ErrorContext(cctx, "privacy.erase_failed",
+			"request_id", requestIDFrom(ctx),
+			"err", err,
+		)
+		RespondInternal(ctx, "Could not erase user data")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"affected": summary,
+	})
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/privacy"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeUserEraser struct {
+	got     *privacy.ErasureRequest
+	summary privacy.ErasureSummary
+	err     error
+}
+
+func (f *fakeUserEraser) EraseByEmail(ctx context.Context, req privacy.ErasureRequest) (privacy.ErasureSummary, error) {
+	f.got = &req
+	return f.summary, f.err
+}
+
+func doErase(h *handlers.PrivacyHandler, email string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.DELETE("/admin/privacy/users", withUser("admin-1", "admin"), h.EraseUser)
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/privacy/users?email="+url.QueryEscape(email), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPrivacyEraseUser_ReturnsAffectedCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeUserEraser{summary: privacy.ErasureSummary{Users: 1, Registrations: 3, RefreshTokens: 2, NotificationDeliveries: 3, Jobs: 4}}
+	w := doErase(handlers.NewPrivacyHandler(repo), "  Sam@Example.com ")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Affected privacy.ErasureSummary `json:"affected"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Affected != repo.summary {
+		t.Fatalf("unexpected summary: %+v", resp.Affected)
+	}
+	if repo.got.Email != "Sam@Example.com" || repo.got.RequestedByUserID != "admin-1" {
+		t.Fatalf("unexpected erasure request: %+v", repo.got)
+	}
+}
+
+func TestPrivacyEraseUser_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, email := range []string{"", "not-an-email", "Sam <sam@example.com>"} {
+		repo := &fakeUserEraser{}
+		w := doErase(handlers.NewPrivacyHandler(repo), email)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("email %q: expected 400, got %d", email, w.Code)
+		}
+		if repo.got != nil {
+			t.Fatalf("email %q: repo should not be called", email)
+		}
+	}
+
+	w := doErase(handlers.NewPrivacyHandler(&fakeUserEraser{err: errors.New("boom")}), "sam@example.com")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestPrivacyEraseUser_KeepsEmailOutOfAdminAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.DELETE("/admin/privacy/users", func(c *gin.Context) {
+		c.Next()
+		if got, _ := c.Get(middlewares.CtxAuditQuery); got != "email=[redacted]" {
+			t.Fatalf("expected audit query override, got %v", got)
+		}
+	}, handlers.NewPrivacyHandler(&fakeUserEraser{}).EraseUser)
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/privacy/users?email=sam@example.com", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
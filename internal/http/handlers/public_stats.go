@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/cache"
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+type PlatformStatsReader interface {
+	PlatformStats(ctx context.Context, monthStart, monthEnd time.Time) (event.PlatformStats, error)
+}
+
+// publicStatsTTL is how long the counts are served from cache, and how long
+// browsers and CDNs may keep them too: they only ever feed a landing page.
+const publicStatsTTL = 10 * time.Minute
+
+const publicStatsCacheKey = "public:stats"
+
+type PublicStatsHandler struct {
+	repo  PlatformStatsReader
+	cache *cache.Cache
+	group singleflight.Group
+	now   func() time.Time
+}
+
+func NewPublicStatsHandler(repo PlatformStatsReader) *PublicStatsHandler {
+	return NewPublicStatsHandlerWithCache(repo, nil)
+}
+
+// NewPublicStatsHandlerWithCache keeps the counts in c for publicStatsTTL.
+// The router passes the events cache, so an event write that clears it drops
+// the counts too. A nil c runs the query for every request.
+func NewPublicStatsHandlerWithCache(repo PlatformStatsReader, c *cache.Cache) *PublicStatsHandler {
+	return &PublicStatsHandler{repo: repo, cache: c, now: time.Now}
+}
+
+// WithClock sets the clock "this month" is taken from; nil keeps time.Now.
+func (h *PublicStatsHandler) WithClock(now func() time.Time) *PublicStatsHandler {
+	if now != nil {
+		h.now = now
+	}
+	return h
+}
+
+// Get handles GET /public/stats: platform-wide event and registration counts
+// for the landing page.
+func (h *PublicStatsHandler) Get(ctx *gin.Context) {
+	stats, err := h.stats(ctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not load stats")
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(publicStatsTTL.Seconds())))
+	RespondJSONWithETag(ctx, http.StatusOK, stats)
+}
+
+// stats serves the counts from cache. On a miss, concurrent requests share one
+// query rather than each running it.
+func (h *PublicStatsHandler) stats(ctx *gin.Context) (event.PlatformStats, error) {
+	if h.cache != nil {
+		if v, ok := h.cache.Get(publicStatsCacheKey); ok {
+			if s, ok := v.(event.PlatformStats); ok {
+				return s, nil
+			}
+		}
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	v, err, _ := h.group.Do(publicStatsCacheKey, func() (any, error) {
+		// the waiters share this query, so the request that started it going
+		// away must not cancel it; its deadline still holds
+		qctx := context.WithoutCancel(cctx)
+		if deadline, ok := cctx.Deadline(); ok {
+			var qcancel context.CancelFunc
+			qctx, qcancel = context.WithDeadline(qctx, deadline)
+			defer qcancel()
+		}
+
+		now := h.now().UTC()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		s, err := h.repo.PlatformStats(qctx, monthStart, monthStart.AddDate(0, 1, 0))
+		if err != nil {
+			return nil, err
+		}
+		if h.cache != nil {
+			h.cache.SetWithTTL(publicStatsCacheKey, s, publicStatsTTL)
+		}
+		return s, nil
+	})
+	if err != nil {
+		return event.PlatformStats{}, err
+	}
+	return v.(event.PlatformStats), nil
+}
@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/cache"
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+)
+
+type fakePlatformStats struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+
+	gotStart, gotEnd time.Time
+}
+
+func (f *fakePlatformStats) PlatformStats(ctx context.Context, monthStart, monthEnd time.Time) (event.PlatformStats, error) {
+	f.calls.Add(1)
+	f.gotStart, f.gotEnd = monthStart, monthEnd
+	if f.release != nil {
+		<-f.release
+	}
+	if f.err != nil {
+		return event.PlatformStats{}, f.err
+	}
+	return event.PlatformStats{EventsHosted: 2431, Registrations: 58102, EventsThisMonth: 12}, nil
+}
+
+func getPublicStats(r http.Handler, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/public/stats", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPublicStats_ColdRequestsShareOneQuery(t *testing.T) {
+	repo := &fakePlatformStats{release: make(chan struct{})}
+	now := time.Date(2026, time.March, 17, 15, 4, 0, 0, time.UTC)
+	h := handlers.NewPublicStatsHandlerWithCache(repo, cache.New(time.Second)).WithClock(func() time.Time { return now })
+	r := setupRouter(http.MethodGet, "/public/stats", h.Get)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = getPublicStats(r, "")
+		}()
+	}
+	// give every caller time to join the flight before the query returns
+	time.Sleep(50 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+
+	if got := repo.calls.Load(); got != 1 {
+		t.Fatalf("expected one query for %d cold requests, got %d", callers, got)
+	}
+	etag := responses[0].Header().Get("ETag")
+	for i, w := range responses {
+		if w.Code != http.StatusOK || w.Header().Get("ETag") != etag {
+			t.Fatalf("response %d: status=%d etag=%q, want 200 and %q", i, w.Code, w.Header().Get("ETag"), etag)
+		}
+	}
+	if !repo.gotStart.Equal(time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)) || !repo.gotEnd.Equal(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected March as this month, got %s to %s", repo.gotStart, repo.gotEnd)
+	}
+
+	var body event.PlatformStats
+	if err := json.Unmarshal(responses[0].Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.EventsHosted != 2431 || body.Registrations != 58102 || body.EventsThisMonth != 12 {
+		t.Fatalf("unexpected stats %+v", body)
+	}
+	if cc := responses[0].Header().Get("Cache-Control"); cc != "public, max-age=600" {
+		t.Fatalf("expected a long public max-age, got %q", cc)
+	}
+
+	// warm: served from cache, and revalidation answers 304
+	if w := getPublicStats(r, etag); w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+	if got := repo.calls.Load(); got != 1 {
+		t.Fatalf("expected the cached stats served, got %d queries", got)
+	}
+}
+
+func TestPublicStats_SharedCacheKeepsThemForTheirOwnTTL(t *testing.T) {
+	repo := &fakePlatformStats{}
+	shared := cache.New(10 * time.Millisecond)
+	r := setupRouter(http.MethodGet, "/public/stats", handlers.NewPublicStatsHandlerWithCache(repo, shared).Get)
+
+	getPublicStats(r, "")
+	// past the shared cache's own TTL, still within the stats'
+	time.Sleep(30 * time.Millisecond)
+	getPublicStats(r, "")
+	if got := repo.calls.Load(); got != 1 {
+		t.Fatalf("expected the stats kept past the shared TTL, got %d queries", got)
+	}
+
+	// an event write clears the shared cache, and the stats with it
+	shared.Clear()
+	getPublicStats(r, "")
+	if got := repo.calls.Load(); got != 2 {
+		t.Fatalf("expected a query after the cache was cleared, got %d", got)
+	}
+}
+
+func TestPublicStats_QueryErrorIsNotCached(t *testing.T) {
+	repo := &fakePlatformStats{err: errors.New("db down")}
+	r := setupRouter(http.MethodGet, "/public/stats", handlers.NewPublicStatsHandlerWithCache(repo, cache.New(time.Minute)).Get)
+
+	if w := getPublicStats(r, ""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+
+	repo.err = nil
+	if w := getPublicStats(r, ""); w.Code != http.StatusOK {
+		t.Fatalf("expected the next request to query again, got %d", w.Code)
+	}
+	if got := repo.calls.Load(); got != 2 {
+		t.Fatalf("expected 2 queries, got %d", got)
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PublishBatchEvents checks a batch's events in the transaction that
+// enqueues their publish jobs.
+type PublishBatchEvents interface {
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	PublishedTx(ctx context.Context, tx pgx.Tx, ids []string) (map[string]bool, error)
+}
+
+// WithPublishBatch enables POST /admin/events/publish-batch.
+func (h *JobsHandler) WithPublishBatch(events PublishBatchEvents) *JobsHandler {
+	h.batchEvents = events
+	return h
+}
+
+// PublishBatchRequest is the body of a batch publish. RunAt defaults to now.
+type PublishBatchRequest struct {
+	EventIDs []string   `json:"eventIds" binding:"required,min=1"`
+	RunAt    *time.Time `json:"runAt"`
+}
+
+// maxBatchKeyLen bounds the client's Idempotency-Key, which becomes part of
+// every job key in the batch.
+const maxBatchKeyLen = 128
+
+// PublishBatch handles POST /admin/events/publish-batch. Every event must
+// exist and be unpublished; the publish jobs are then enqueued in one
+// transaction, all sharing the batch's idempotency key prefix. One bad event
+// rejects the whole batch with a 422 listing each event's status, unless
+// ?partial=true, which enqueues the good ones and reports the rest.
+//
+// The batch id is the client's Idempotency-Key when it sends one, so a retry
+// finds the jobs the first attempt enqueued and reports them instead of
+// enqueuing them again. Without the header every request is a new batch.
+func (h *JobsHandler) PublishBatch(ctx *gin.Context) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok || userID == "" {
+		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
+		return
+	}
+
+	partial, err := parseBoolQuery(ctx, "partial")
+	if err != nil {
+		RespondBadRequest(ctx, "invalid_query", "partial must be true or false")
+		return
+	}
+
+	batchID := strings.TrimSpace(ctx.GetHeader("Idempotency-Key"))
+	if len(batchID) > maxBatchKeyLen {
+		RespondBadRequest(ctx, "invalid_idempotency_key", fmt.Sprintf("Idempotency-Key must be at most %d characters", maxBatchKeyLen))
+		return
+	}
+	retryable := batchID != ""
+	if !retryable {
+		batchID = uuid.NewString()
+	}
+
+	var req PublishBatchRequest
+	if !BindJSONWithLimits(ctx, &req, BindLimits{MaxItems: event.MaxPublishBatch}) {
+		return
+	}
+
+	runAt := time.Now().UTC()
+	if req.RunAt != nil {
+		// same clock drift allowance as a single publish
+		if req.RunAt.Before(time.Now().UTC().Add(-30 * time.Second)) {
+			RespondBadRequest(ctx, "Invalid request body", gin.H{"error": "runAt must be now or in the future"})
+			return
+		}
+		runAt = req.RunAt.UTC()
+	}
+
+	if h.batchEvents == nil {
+		RespondInternal(ctx, "Batch publish not configured")
+		return
+	}
+
+	results := make([]event.PublishItemResult, len(req.EventIDs))
+	candidates := make([]string, 0, len(req.EventIDs))
+	seen := make(map[string]bool, len(req.EventIDs))
+	for i, raw := range req.EventIDs {
+		id := strings.ToLower(strings.TrimSpace(raw))
+		results[i] = event.PublishItemResult{EventID: id}
+
+		switch {
+		case !utils.IsUUID(id):
+			results[i].EventID = raw
+			results[i].Status = event.PublishInvalidID
+		case seen[id]:
+			results[i].Status = event.PublishDuplicate
+		default:
+			seen[id] = true
+			candidates = append(candidates, id)
+		}
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	class := EnqueueStandard
+	if runAt.After(time.Now().UTC().Add(farFuturePublish)) {
+		class = EnqueueDeferrable
+	}
+	if !h.guard.Admit(ctx, cctx, class, jobs.TypeEventPublish) {
+		return
+	}
+
+	// a retry of this batch: events whose job already exists are reported
+	// with it, whatever has happened to the event since
+	var already []job.Job
+	if retryable {
+		remaining := candidates[:0]
+		for i := range results {
+			if results[i].Status != "" {
+				continue
+			}
+			j, err := h.jobs.GetByIdempotencyKey(cctx, publishBatchKey(batchID, results[i].EventID))
+			if errors.Is(err, job.ErrJobNotFound) {
+				remaining = append(remaining, results[i].EventID)
+				continue
+			}
+			if err != nil {
+				RespondInternal(ctx, "Could not enqueue jobs")
+				return
+			}
+			results[i].Status = event.PublishEnqueued
+			results[i].JobID = j.ID
+			already = append(already, j)
+		}
+		candidates = remaining
+	}
+
+	tx, err := h.batchEvents.BeginTx(cctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not enqueue jobs")
+		return
+	}
+	defer func() { _ = tx.Rollback(cctx) }()
+
+	var published map[string]bool
+	if len(candidates) > 0 {
+		published, err = h.batchEvents.PublishedTx(cctx, tx, candidates)
+		if err != nil {
+			RespondInternal(ctx, "Could not enqueue jobs")
+			return
+		}
+	}
+
+	rejected := 0
+	for i := range results {
+		if results[i].Status == event.PublishEnqueued {
+			continue
+		}
+		if results[i].Status != "" {
+			rejected++
+			continue
+		}
+		isPublished, live := published[results[i].EventID]
+		switch {
+		case !live:
+			results[i].Status = event.PublishNotFound
+			rejected++
+		case isPublished:
+			results[i].Status = event.PublishAlreadyPublished
+			rejected++
+		}
+	}
+
+	if rejected == len(results) || (rejected > 0 && (!partial || rejected+len(already) == len(results))) {
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = event.PublishValid
+			}
+		}
+		RespondError(ctx, http.StatusUnprocessableEntity, "publish_batch_rejected",
+			fmt.Sprintf("%d of %d events cannot be published; nothing was enqueued.", rejected, len(results)),
+			gin.H{"results": results})
+		return
+	}
+
+	enqueued := make([]job.Job, 0, len(results)-rejected)
+	for i := range results {
+		if results[i].Status != "" {
+			continue
+		}
+
+		raw, err := jobs.EventPublishPayload{
+			EventID:     results[i].EventID,
+			RequestedBy: userID,
+			RequestedAt: time.Now().UTC(),
+			RequestID:   requestIDFrom(ctx),
+		}.ToJSONRaw()
+		if err != nil {
+			RespondInternal(ctx, "Could not enqueue jobs")
+			return
+		}
+
+		key := publishBatchKey(batchID, results[i].EventID)
+		j, err := h.jobs.CreateTx(cctx, tx, job.CreateRequest{
+			Type:           jobs.TypeEventPublish,
+			Payload:        raw,
+			RunAt:          runAt,
+			MaxAttempts:    25,
+			IdempotencyKey: &key,
+			UserID:         &userID,
+		})
+		if err != nil {
+			if respondPayloadTooLarge(ctx, err) {
+				return
+			}
+			if postgres.IsUniqueViolation(err) {
+				// a concurrent request with the same Idempotency-Key got there first
+				RespondError(ctx, http.StatusConflict, "idempotency_key_in_use",
+					"Another request with this Idempotency-Key is enqueuing the batch; retry it.", nil)
+				return
+			}
+			RespondInternal(ctx, "Could not enqueue jobs")
+			return
+		}
+
+		results[i].Status = event.PublishEnqueued
+		results[i].JobID = j.ID
+		enqueued = append(enqueued, j)
+	}
+
+	if err := tx.Commit(cctx); err != nil {
+		RespondInternal(ctx, "Could not enqueue jobs")
+		return
+	}
+
+	logEnqueued := func(js []job.Job, alreadyEnqueued bool) {
+		for _, j := range js {
+			slog.Default().InfoContext(cctx, "job.enqueue",
+				"request_id", requestIDFrom(ctx),
+				"job_id", j.ID,
+				"job_type", j.Type,
+				"batch_id", batchID,
+				"already_enqueued", alreadyEnqueued,
+			)
+		}
+	}
+	logEnqueued(already, true)
+	logEnqueued(enqueued, false)
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"batchId":  batchID,
+		"enqueued": len(already) + len(enqueued),
+		"rejected": rejected,
+		"results":  results,
+	})
+}
+
+// publishBatchKey is the idempotency key of eventID's job in batch batchID.
+// It never collides with a single publish's "publish:event:<id>", so a batch
+// and a single publish of one event are separate jobs; HandleEventPublish is
+// a no-op for an event that is already published, so the second one to run
+// changes nothing.
+func publishBatchKey(batchID, eventID string) string {
+	return "publish:batch:" + batchID + ":event:" + eventID
+}
@@ -0,0 +1,272 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// batchTx records whether the batch was committed.
+type batchTx struct {
+	pgx.Tx
+	committed *bool
+}
+
+func (t batchTx) Commit(ctx context.Context) error {
+	*t.committed = true
+	return nil
+}
+func (batchTx) Rollback(ctx context.Context) error { return nil }
+
+// fakePublishBatchEvents knows the live events and whether each is published.
+type fakePublishBatchEvents struct {
+	published map[string]bool
+	committed bool
+}
+
+func (f *fakePublishBatchEvents) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return batchTx{committed: &f.committed}, nil
+}
+
+func (f *fakePublishBatchEvents) PublishedTx(ctx context.Context, tx pgx.Tx, ids []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, id := range ids {
+		if p, ok := f.published[id]; ok {
+			out[id] = p
+		}
+	}
+	return out, nil
+}
+
+type publishBatchResponse struct {
+	BatchID  string                    `json:"batchId"`
+	Enqueued int                       `json:"enqueued"`
+	Rejected int                       `json:"rejected"`
+	Results  []event.PublishItemResult `json:"results"`
+	Error    struct {
+		Code    string `json:"code"`
+		Details struct {
+			Results []event.PublishItemResult `json:"results"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func doPublishBatch(t *testing.T, events *fakePublishBatchEvents, jobsRepo *publishJobsRepo, query string, body any) (*httptest.ResponseRecorder, publishBatchResponse) {
+	t.Helper()
+	return doKeyedPublishBatch(t, events, jobsRepo, "", query, body)
+}
+
+// doKeyedPublishBatch sends the batch with idempotencyKey as its
+// Idempotency-Key, if set.
+func doKeyedPublishBatch(t *testing.T, events *fakePublishBatchEvents, jobsRepo *publishJobsRepo, idempotencyKey, query string, body any) (*httptest.ResponseRecorder, publishBatchResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	h := handlers.NewJobsHandler(jobsRepo, nil).WithPublishBatch(events)
+	r := gin.New()
+	r.POST("/admin/events/publish-batch", withUser(newUUID(), "admin"), h.PublishBatch)
+
+	raw, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/admin/events/publish-batch"+query, strings.NewReader(string(raw)))
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp publishBatchResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func statuses(results []event.PublishItemResult) []event.PublishItemStatus {
+	out := make([]event.PublishItemStatus, len(results))
+	for i, r := range results {
+		out[i] = r.Status
+	}
+	return out
+}
+
+func TestPublishBatch_EnqueuesEveryEventTogether(t *testing.T) {
+	a, b := newUUID(), newUUID()
+	events := &fakePublishBatchEvents{published: map[string]bool{a: false, b: false}}
+	jobsRepo := &publishJobsRepo{byKey: map[string]job.Job{}}
+
+	w, resp := doPublishBatch(t, events, jobsRepo, "", gin.H{"eventIds": []string{a, b}})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Enqueued != 2 || resp.Rejected != 0 || !events.committed {
+		t.Fatalf("expected both enqueued and committed, got %+v committed=%v", resp, events.committed)
+	}
+	for _, res := range resp.Results {
+		if res.Status != event.PublishEnqueued || res.JobID == "" {
+			t.Fatalf("expected a job per event, got %+v", resp.Results)
+		}
+	}
+
+	prefix := "publish:batch:" + resp.BatchID + ":event:"
+	for key := range jobsRepo.byKey {
+		if !strings.HasPrefix(key, prefix) {
+			t.Fatalf("expected every key under %q, got %q", prefix, key)
+		}
+	}
+	if len(jobsRepo.byKey) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobsRepo.byKey))
+	}
+}
+
+func TestPublishBatch_RetryWithTheSameKeyReportsTheFirstJobs(t *testing.T) {
+	a, b := newUUID(), newUUID()
+	events := &fakePublishBatchEvents{published: map[string]bool{a: false, b: false}}
+	jobsRepo := &publishJobsRepo{byKey: map[string]job.Job{}}
+
+	w, first := doKeyedPublishBatch(t, events, jobsRepo, "batch-7", "", gin.H{"eventIds": []string{a, b}})
+	if w.Code != http.StatusAccepted || first.BatchID != "batch-7" {
+		t.Fatalf("expected 202 for batch-7, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := jobsRepo.byKey["publish:batch:batch-7:event:"+a]; !ok {
+		t.Fatalf("expected the job keyed by the client key and event, got %v", jobsRepo.byKey)
+	}
+
+	// the first attempt's job for a has run by the time the client retries
+	events.published[a] = true
+	w, retry := doKeyedPublishBatch(t, events, jobsRepo, "batch-7", "", gin.H{"eventIds": []string{a, b}})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected the retry to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(jobsRepo.byKey) != 2 || retry.Enqueued != 2 {
+		t.Fatalf("expected the retry to enqueue nothing new, got %d jobs and %+v", len(jobsRepo.byKey), retry)
+	}
+	for i := range retry.Results {
+		if retry.Results[i] != first.Results[i] {
+			t.Fatalf("expected the first attempt's results, got %+v want %+v", retry.Results, first.Results)
+		}
+	}
+
+	// another key is another batch
+	events.published[a] = false
+	if w, _ := doKeyedPublishBatch(t, events, jobsRepo, "batch-8", "", gin.H{"eventIds": []string{a}}); w.Code != http.StatusAccepted || len(jobsRepo.byKey) != 3 {
+		t.Fatalf("expected a new job under a new key, got %d and %d jobs", w.Code, len(jobsRepo.byKey))
+	}
+
+	if w, _ := doKeyedPublishBatch(t, events, jobsRepo, strings.Repeat("k", 129), "", gin.H{"eventIds": []string{a}}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an overlong key, got %d", w.Code)
+	}
+}
+
+func TestPublishBatch_SeparateFromSinglePublishJobs(t *testing.T) {
+	a := newUUID()
+	events := &fakePublishBatchEvents{published: map[string]bool{a: false}}
+	jobsRepo := &publishJobsRepo{byKey: map[string]job.Job{}}
+	single := newPublishRouter(handlers.NewJobsHandler(jobsRepo, nil))
+
+	// a single publish is pending, so the event is still unpublished
+	if w := doPublish(single, a); w.Code != http.StatusAccepted {
+		t.Fatalf("single publish got %d: %s", w.Code, w.Body.String())
+	}
+	singleJob := jobsRepo.byKey["publish:event:"+a]
+
+	w, resp := doKeyedPublishBatch(t, events, jobsRepo, "batch-9", "", gin.H{"eventIds": []string{a}})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	batchJob, ok := jobsRepo.byKey["publish:batch:batch-9:event:"+a]
+	if !ok || resp.Results[0].JobID != batchJob.ID || batchJob.ID == singleJob.ID {
+		t.Fatalf("expected the batch to enqueue its own job, got %+v and %v", resp.Results, jobsRepo.byKey)
+	}
+
+	// and the batch's job does not count as the single publish's duplicate
+	if w := doPublish(single, a); w.Code != http.StatusAccepted {
+		t.Fatalf("repeat single publish got %d: %s", w.Code, w.Body.String())
+	}
+	if len(jobsRepo.byKey) != 2 || jobsRepo.byKey["publish:event:"+a].ID != singleJob.ID {
+		t.Fatalf("expected one single and one batch job, got %v", jobsRepo.byKey)
+	}
+}
+
+func TestPublishBatch_RejectsTheWholeBatch(t *testing.T) {
+	ok, published, missing := newUUID(), newUUID(), newUUID()
+	events := &fakePublishBatchEvents{published: map[string]bool{ok: false, published: true}}
+	jobsRepo := &publishJobsRepo{byKey: map[string]job.Job{}}
+
+	w, resp := doPublishBatch(t, events, jobsRepo, "", gin.H{"eventIds": []string{ok, published, missing, ok, "nope"}})
+	if w.Code != http.StatusUnprocessableEntity || resp.Error.Code != "publish_batch_rejected" {
+		t.Fatalf("expected 422 publish_batch_rejected, got %d: %s", w.Code, w.Body.String())
+	}
+
+	got := statuses(resp.Error.Details.Results)
+	want := []event.PublishItemStatus{event.PublishValid, event.PublishAlreadyPublished, event.PublishNotFound, event.PublishDuplicate, event.PublishInvalidID}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", got, want)
+		}
+	}
+	if len(jobsRepo.byKey) != 0 || events.committed {
+		t.Fatalf("expected nothing enqueued, got %d jobs committed=%v", len(jobsRepo.byKey), events.committed)
+	}
+}
+
+func TestPublishBatch_PartialEnqueuesTheValidEvents(t *testing.T) {
+	ok, published, missing := newUUID(), newUUID(), newUUID()
+	events := &fakePublishBatchEvents{published: map[string]bool{ok: false, published: true}}
+	jobsRepo := &publishJobsRepo{byKey: map[string]job.Job{}}
+
+	w, resp := doPublishBatch(t, events, jobsRepo, "?partial=true", gin.H{"eventIds": []string{published, ok, missing}})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Enqueued != 1 || resp.Rejected != 2 || len(jobsRepo.byKey) != 1 {
+		t.Fatalf("expected one enqueued and two rejected, got %+v", resp)
+	}
+	got := statuses(resp.Results)
+	want := []event.PublishItemStatus{event.PublishAlreadyPublished, event.PublishEnqueued, event.PublishNotFound}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", got, want)
+		}
+	}
+
+	// nothing valid is still a rejection
+	w, _ = doPublishBatch(t, events, jobsRepo, "?partial=true", gin.H{"eventIds": []string{published, missing}})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 with nothing to enqueue, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPublishBatch_ValidatesTheRequest(t *testing.T) {
+	tooMany := make([]string, event.MaxPublishBatch+1)
+	for i := range tooMany {
+		tooMany[i] = newUUID()
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		body  any
+	}{
+		{name: "no events", body: gin.H{"eventIds": []string{}}},
+		{name: "too many events", body: gin.H{"eventIds": tooMany}},
+		{name: "run at in the past", body: gin.H{"eventIds": []string{newUUID()}, "runAt": "2020-01-01T00:00:00Z"}},
+		{name: "bad partial", query: "?partial=maybe", body: gin.H{"eventIds": []string{newUUID()}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			events := &fakePublishBatchEvents{}
+			w, _ := doPublishBatch(t, events, &publishJobsRepo{byKey: map[string]job.Job{}}, tc.query, tc.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RegistrationEmailChanger interface {
+	ChangeEmail(ctx context.Context, eventID, registrationID, newEmail string) (registration.Registration, job.Job, error)
+}
+
+// RegistrationEmailHandler lets organizers fix an attendee's mistyped email
+// and resend the confirmation to the corrected address only.
+type RegistrationEmailHandler struct {
+	repo   RegistrationEmailChanger
+	events EventOrganizersReader
+}
+
+func NewRegistrationEmailHandler(repo RegistrationEmailChanger, events EventOrganizersReader) *RegistrationEmailHandler {
+	return &RegistrationEmailHandler{repo: repo, events: events}
+}
+
+// ChangeEmail handles PATCH /events/:id/registrations/:registrationId/email.
+func (h *RegistrationEmailHandler) ChangeEmail(ctx *gin.Context) {
+	eventID, ok := authorizeEventOrganizer(ctx, h.events, "You can only change registrations for events you organize")
+	if !ok {
+		return
+	}
+
+	regID := ctx.Param("registrationId")
+	if !utils.IsUUID(regID) {
+		RespondBadRequest(ctx, "invalid_id", "registration id must be a valid UUID")
+		return
+	}
+
+	var req registration.ChangeEmailRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	reg, confirmation, err := h.repo.ChangeEmail(cctx, eventID, regID, req.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, registration.ErrNotFound):
+			RespondNotFound(ctx, "Registration not found")
+		case errors.Is(err, event.ErrNotFound):
+			RespondNotFound(ctx, "Event not found")
+		case errors.Is(err, registration.ErrAlreadyRegistered):
+			RespondError(ctx, http.StatusConflict, "already_registered", "this email is already registered for this event.", gin.H{"field": "email"})
+		case errors.Is(err, registration.ErrAlreadyCancelled):
+			respondAlreadyCancelled(ctx)
+		case errors.Is(err, registration.ErrEmailUnchanged):
+			RespondError(ctx, http.StatusConflict, "email_unchanged", "the registration already has this email.", gin.H{"field": "email"})
+		case errors.Is(err, registration.ErrEmailDomainNotAllowed):
+			RespondError(ctx, http.StatusForbidden, "email_domain_not_allowed", "registration for this event is restricted to specific email domains.", gin.H{"field": "email"})
+		default:
+			RespondInternal(ctx, "Could not change registration email")
+		}
+		return
+	}
+
+	// waitlisted registrations are confirmed on promotion, to the new address
+	if confirmation.ID != "" {
+		ctx.Set(middlewares.CtxJobID, confirmation.ID)
+		slog.Default().InfoContext(cctx, "job.enqueue",
+			"request_id", requestIDFrom(ctx),
+			"job_id", confirmation.ID,
+			"job_type", confirmation.Type,
+			"already_enqueued", false,
+		)
+	}
+
+	ctx.JSON(http.StatusOK, reg)
+}
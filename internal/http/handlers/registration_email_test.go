@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeEmailChanger struct {
+	err      error
+	gotEmail string
+	calls    int
+}
+
+func (f *fakeEmailChanger) ChangeEmail(ctx context.Context, eventID, registrationID, newEmail string) (registration.Registration, job.Job, error) {
+	f.calls++
+	f.gotEmail = newEmail
+	if f.err != nil {
+		return registration.Registration{}, job.Job{}, f.err
+	}
+	reg := registration.Registration{ID: registrationID, EventID: eventID, Email: newEmail, Status: registration.StatusConfirmed}
+	return reg, job.Job{ID: "job-1", Type: "registration.confirmation"}, nil
+}
+
+func TestRegistrationEmail_ChangeEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID, organizerID, regID := newUUID(), newUUID(), newUUID()
+
+	patch := func(repo *fakeEmailChanger, userID string, role user.Role, body string) *httptest.ResponseRecorder {
+		r := gin.New()
+		h := handlers.NewRegistrationEmailHandler(repo, fakeOrganizers{eventID: organizerID})
+		r.PATCH("/events/:id/registrations/:registrationId/email", withUser(userID, role), h.ChangeEmail)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/events/"+eventID+"/registrations/"+regID+"/email", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("stranger is forbidden", func(t *testing.T) {
+		repo := &fakeEmailChanger{}
+		if w := patch(repo, newUUID(), "user", `{"email":"ann@example.com"}`); w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d body=%s", w.Code, w.Body.String())
+		}
+		if repo.calls != 0 {
+			t.Fatalf("repo must not be called for a stranger")
+		}
+	})
+
+	t.Run("invalid email is rejected", func(t *testing.T) {
+		repo := &fakeEmailChanger{}
+		if w := patch(repo, organizerID, "user", `{"email":"not-an-email"}`); w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d body=%s", w.Code, w.Body.String())
+		}
+		if repo.calls != 0 {
+			t.Fatalf("repo must not be called for an invalid email")
+		}
+	})
+
+	t.Run("organizer and admin change it", func(t *testing.T) {
+		for _, caller := range []struct {
+			id   string
+			role user.Role
+		}{{organizerID, "user"}, {newUUID(), "admin"}} {
+			repo := &fakeEmailChanger{}
+			w := patch(repo, caller.id, caller.role, `{"email":"ann@example.com"}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200 for role %s, got %d body=%s", caller.role, w.Code, w.Body.String())
+			}
+			if repo.gotEmail != "ann@example.com" {
+				t.Fatalf("expected the new email passed on, got %q", repo.gotEmail)
+			}
+		}
+	})
+
+	errs := map[string]struct {
+		err  error
+		code int
+		body string
+	}{
+		"taken":     {registration.ErrAlreadyRegistered, http.StatusConflict, "already_registered"},
+		"unchanged": {registration.ErrEmailUnchanged, http.StatusConflict, "email_unchanged"},
+		"cancelled": {registration.ErrAlreadyCancelled, http.StatusConflict, "already_cancelled"},
+		"missing":   {registration.ErrNotFound, http.StatusNotFound, "not_found"},
+		"domain":    {registration.ErrEmailDomainNotAllowed, http.StatusForbidden, "email_domain_not_allowed"},
+	}
+	for name, tc := range errs {
+		t.Run(name, func(t *testing.T) {
+			w := patch(&fakeEmailChanger{err: tc.err}, organizerID, "user", `{"email":"ann@example.com"}`)
+			if w.Code != tc.code || !strings.Contains(w.Body.String(), tc.body) {
+				t.Fatalf("expected %d %s, got %d body=%s", tc.code, tc.body, w.Code, w.Body.String())
+			}
+		})
+	}
+}
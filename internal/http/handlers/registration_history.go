@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RegistrationHistoryReader interface {
+	History(ctx context.Context, eventID, registrationID string) ([]registration.HistoryEntry, error)
+	ActivityByEventCursor(
+		ctx context.Context,
+		eventID string,
+		filter registration.ActivityFilter,
+		limit int,
+		afterAt time.Time,
+		afterID string,
+	) (items []registration.HistoryEntry, nextCursor *string, hasMore bool, err error)
+}
+
+// RegistrationHistoryHandler shows organizers the recorded lifecycle of their
+// events' registrations, so "we lost registrations" claims can be checked.
+type RegistrationHistoryHandler struct {
+	repo   RegistrationHistoryReader
+	events EventOrganizersReader
+}
+
+func NewRegistrationHistoryHandler(repo RegistrationHistoryReader, events EventOrganizersReader) *RegistrationHistoryHandler {
+	return &RegistrationHistoryHandler{repo: repo, events: events}
+}
+
+// History handles GET /events/:id/registrations/:registrationId/history.
+func (h *RegistrationHistoryHandler) History(ctx *gin.Context) {
+	eventID, ok := authorizeEventOrganizer(ctx, h.events, "You can only view registrations for events you organize")
+	if !ok {
+		return
+	}
+
+	regID := ctx.Param("registrationId")
+	if !utils.IsUUID(regID) {
+		RespondBadRequest(ctx, "invalid_id", "registration id must be a valid UUID")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, err := h.repo.History(cctx, eventID, regID)
+	if err != nil {
+		if errors.Is(err, registration.ErrNotFound) {
+			RespondNotFound(ctx, "Registration not found")
+			return
+		}
+		RespondInternal(ctx, "Could not load registration history")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Activity handles GET /events/:id/registration-activity?from&to: every
+// recorded transition for the event, oldest first, cursor paginated.
+func (h *RegistrationHistoryHandler) Activity(ctx *gin.Context) {
+	eventID, ok := authorizeEventOrganizer(ctx, h.events, "You can only view registrations for events you organize")
+	if !ok {
+		return
+	}
+
+	limit := parseIntDefault(ctx.Query("limit"), 50)
+	if limit < 1 || limit > 200 {
+		RespondBadRequest(ctx, "invalid_query", "limit must be between 1 and 200")
+		return
+	}
+
+	var filter registration.ActivityFilter
+	if fromStr := ctx.Query("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "from must be RFC3339 datetime")
+			return
+		}
+		filter.From = &t
+	}
+	if toStr := ctx.Query("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "to must be RFC3339 datetime")
+			return
+		}
+		filter.To = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		RespondBadRequest(ctx, "invalid_query", "from must be before to")
+		return
+	}
+
+	afterAt := time.Unix(0, 0).UTC()
+	afterID := "00000000-0000-0000-0000-000000000000"
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		cur, err := utils.DecodeRegistrationCursor(cursor)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "cursor is invalid")
+			return
+		}
+		afterAt, afterID = cur.CreatedAt, cur.ID
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, next, hasMore, err := h.repo.ActivityByEventCursor(cctx, eventID, filter, limit, afterAt, afterID)
+	if err != nil {
+		RespondInternal(ctx, "Could not list registration activity")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BuildCursorPageResponse(limit, items, hasMore, next, nil))
+}
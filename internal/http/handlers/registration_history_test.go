@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeHistoryRepo struct {
+	gotFilter registration.ActivityFilter
+	gotLimit  int
+}
+
+func (f *fakeHistoryRepo) History(ctx context.Context, eventID, registrationID string) ([]registration.HistoryEntry, error) {
+	return []registration.HistoryEntry{
+		{ID: newUUID(), RegistrationID: registrationID, EventID: eventID, Kind: registration.HistoryCreated, ToStatus: registration.StatusConfirmed},
+		{ID: newUUID(), RegistrationID: registrationID, EventID: eventID, Kind: registration.HistoryCancelled, FromStatus: registration.StatusConfirmed, ToStatus: registration.StatusCancelled},
+	}, nil
+}
+
+func (f *fakeHistoryRepo) ActivityByEventCursor(ctx context.Context, eventID string, filter registration.ActivityFilter, limit int, afterAt time.Time, afterID string) ([]registration.HistoryEntry, *string, bool, error) {
+	f.gotFilter, f.gotLimit = filter, limit
+	return []registration.HistoryEntry{}, nil, false, nil
+}
+
+func TestRegistrationHistory_OrganizerOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID, organizerID, regID := newUUID(), newUUID(), newUUID()
+	h := handlers.NewRegistrationHistoryHandler(&fakeHistoryRepo{}, fakeOrganizers{eventID: organizerID})
+
+	get := func(userID string, role user.Role) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/events/:id/registrations/:registrationId/history", withUser(userID, role), h.History)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+eventID+"/registrations/"+regID+"/history", nil))
+		return w
+	}
+
+	if w := get(newUUID(), "user"); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a stranger, got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := get(newUUID(), "admin"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for an admin, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w := get(organizerID, "user")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the organizer, got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Items []registration.HistoryEntry `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 2 || resp.Items[1].Kind != registration.HistoryCancelled || resp.Items[1].FromStatus != registration.StatusConfirmed {
+		t.Fatalf("unexpected history %+v", resp.Items)
+	}
+}
+
+func TestRegistrationActivity_ValidatesRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID, organizerID := newUUID(), newUUID()
+	repo := &fakeHistoryRepo{}
+	r := gin.New()
+	r.GET("/events/:id/registration-activity", withUser(organizerID, "user"),
+		handlers.NewRegistrationHistoryHandler(repo, fakeOrganizers{eventID: organizerID}).Activity)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+eventID+"/registration-activity"+query, nil))
+		return w
+	}
+
+	for _, q := range []string{"?from=yesterday", "?to=2026-01-01", "?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", "?limit=500", "?cursor=nope"} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+
+	w := get("?from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&limit=10")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	if repo.gotLimit != 10 || repo.gotFilter.From == nil || repo.gotFilter.To == nil || repo.gotFilter.To.Month() != time.February {
+		t.Fatalf("unexpected filter passed to the repo: limit=%d %+v", repo.gotLimit, repo.gotFilter)
+	}
+}
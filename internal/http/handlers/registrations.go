@@ -6,51 +6,106 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/canceltoken"
 	"github.com/geocoder89/eventhub/internal/domain/event"
 	"github.com/geocoder89/eventhub/internal/domain/job"
 	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/funnel"
 	"github.com/geocoder89/eventhub/internal/http/middlewares"
 	"github.com/geocoder89/eventhub/internal/jobs"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
 	"github.com/geocoder89/eventhub/internal/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5"
 )
 
 type RegistrationCreator interface {
-	BeginTx(ctx context.Context) (pgx.Tx, error)
-	CreateTx(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error)
+	CreateWithConfirmation(ctx context.Context, req registration.CreateRegistrationRequest, confirm postgres.EnqueueConfirmation) (registration.Registration, job.Job, error)
 	Create(ctx context.Context, req registration.CreateRegistrationRequest) (registration.Registration, error)
-	ListByEvent(ctx context.Context, eventID string) ([]registration.Registration, error)
+	ListByEvent(ctx context.Context, eventID string, filter registration.ListFilter) ([]registration.Registration, error)
 	ListByEventCursor(
 		ctx context.Context,
 		eventID string,
+		filter registration.ListFilter,
 		limit int,
 		afterCreatedAt time.Time,
 		afterID string,
 	) (items []registration.Registration, nextCursor *string, hasMore bool, err error)
 
-	CountForEvent(ctx context.Context, eventID string) (int, error)
+	CountForEvent(ctx context.Context, eventID string, filter registration.ListFilter) (int, error)
 	GetByID(ctx context.Context, eventID, registrationID string) (registration.Registration, error)
-	Delete(ctx context.Context, eventID, registrationID string) error
+	Cancel(ctx context.Context, eventID, registrationID string, c registration.Cancellation) error
 	CheckInByToken(ctx context.Context, eventID, token string) (registration.Registration, error)
+	CheckIn(ctx context.Context, eventID, registrationID string) (registration.Registration, error)
+}
+
+type CancelTokenVerifier interface {
+	Verify(token string) (canceltoken.Claims, error)
 }
 
 type RegistrationHandler struct {
-	repo     RegistrationCreator
-	jobsRepo JobsCreator
+	repo         RegistrationCreator
+	funnel       FunnelRecorder
+	cancelTokens CancelTokenVerifier
+	branding     BrandingReader
+	organizers   EventOrganizersReader
+}
+
+// CancelRegistrationRequest is the optional body of both cancellation
+// endpoints. The reason is shown to organizers, never to attendees.
+type CancelRegistrationRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// bindCancelReason reads the optional cancellation body; false means a 400
+// has been written.
+func bindCancelReason(ctx *gin.Context) (string, bool) {
+	var req CancelRegistrationRequest
+	if ctx.Request.ContentLength != 0 && !BindJSON(ctx, &req) {
+		return "", false
+	}
+	return strings.TrimSpace(req.Reason), true
 }
 
 type checkInRequest struct {
 	Token string `json:"token" binding:"required,min=10,max=255"`
 }
 
-func NewRegistrationHandler(repo RegistrationCreator, jobsRepo JobsCreator) *RegistrationHandler {
-	return &RegistrationHandler{repo: repo, jobsRepo: jobsRepo}
+func NewRegistrationHandler(repo RegistrationCreator) *RegistrationHandler {
+	return &RegistrationHandler{repo: repo}
+}
+
+func (h *RegistrationHandler) WithFunnel(rec FunnelRecorder) *RegistrationHandler {
+	h.funnel = rec
+	return h
+}
+
+func (h *RegistrationHandler) WithCancelTokens(v CancelTokenVerifier) *RegistrationHandler {
+	h.cancelTokens = v
+	return h
+}
+
+// WithBranding puts the event's organizer branding on confirmation emails.
+func (h *RegistrationHandler) WithBranding(r BrandingReader) *RegistrationHandler {
+	h.branding = r
+	return h
+}
+
+// WithOrganizers lets an event's organizer see its attendees' names and
+// emails in the listing; without it only admins do. See callerPIIVisibility.
+func (h *RegistrationHandler) WithOrganizers(r EventOrganizersReader) *RegistrationHandler {
+	h.organizers = r
+	return h
+}
+
+func (h *RegistrationHandler) recordFunnel(eventID string, stage funnel.Stage, reason string) {
+	if h.funnel != nil {
+		h.funnel.Record(eventID, stage, reason)
+	}
 }
 
 func (h *RegistrationHandler) Register(ctx *gin.Context) {
@@ -61,9 +116,19 @@ func (h *RegistrationHandler) Register(ctx *gin.Context) {
 		return
 	}
 
+	// every attempt counts towards the funnel, including the ones that fail.
+	reason := funnel.ReasonError
+	defer func() {
+		h.recordFunnel(eventID, funnel.StageAttempt, reason)
+		if reason == funnel.ReasonOK {
+			h.recordFunnel(eventID, funnel.StageSuccess, funnel.ReasonOK)
+		}
+	}()
+
 	var req registration.CreateRegistrationRequest
 
 	if !BindJSON(ctx, &req) {
+		reason = funnel.ReasonInvalidRequest
 		return
 	}
 
@@ -71,38 +136,57 @@ func (h *RegistrationHandler) Register(ctx *gin.Context) {
 
 	req.EventID = eventID
 
-	// attach the userId to request.
-	userID, ok := middlewares.UserIDFromContext(ctx)
+	// registration is open to anonymous users unless the event requires auth
+	userID, _ := middlewares.UserIDFromContext(ctx)
+	req.UserID = userID
 
-	if !ok || userID == "" {
-		RespondUnAuthorized(ctx, "unauthorized", "Missing identity")
-		return
+	if userID != "" {
+		req.AuthEmail, _ = middlewares.EmailFromContext(ctx)
 	}
 
-	req.UserID = userID
-
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 
 	defer cancel()
 
-	tx, err := h.repo.BeginTx(cctx)
+	// the confirmation job commits with the registration or not at all
+	reg, createdJob, err := h.repo.CreateWithConfirmation(cctx, req, postgres.EnqueueConfirmationFunc(func(cctx context.Context, reg registration.Registration) (job.CreateRequest, error) {
+		return h.confirmationJob(ctx, cctx, reg, userID)
+	}))
 
 	if err != nil {
-		RespondInternal(ctx, "Could not register for event")
-		return
-	}
-
-	defer func() { _ = tx.Rollback(cctx) }()
+		var fullErr *registration.FullError
+		var quantityErr *registration.QuantityLimitError
 
-	reg, err := h.repo.CreateTx(cctx, tx, req)
-
-	if err != nil {
 		switch {
+		case errors.As(err, &quantityErr):
+			reason = funnel.ReasonInvalidRequest
+			RespondError(ctx, http.StatusBadRequest, "quantity_exceeds_limit", "this event allows at most "+strconv.Itoa(quantityErr.Max)+" seats per registration.", gin.H{"maxQuantity": quantityErr.Max})
+		case errors.As(err, &fullErr):
+			reason = funnel.ReasonEventFull
+			RespondError(ctx, http.StatusConflict, "event_full", "this event does not have enough seats left.", gin.H{"remaining": fullErr.Remaining, "requested": max(req.Quantity, 1)})
 		case errors.Is(err, registration.ErrAlreadyRegistered):
-			RespondConflict(ctx, "already_registered", "this email is already registered for this event.")
+			reason = funnel.ReasonAlreadyRegistered
+			RespondError(ctx, http.StatusConflict, "already_registered", "this email is already registered for this event.", gin.H{"field": "email"})
 		case errors.Is(err, registration.ErrEventFull):
+			reason = funnel.ReasonEventFull
 			RespondConflict(ctx, "event_full", "this event is already at full capacity.")
+		case errors.Is(err, registration.ErrEventEnded):
+			reason = funnel.ReasonEventEnded
+			RespondError(ctx, http.StatusGone, "event_ended", "registration for this event has closed.", nil)
+		case errors.Is(err, registration.ErrRegistrationNotOpen):
+			reason = funnel.ReasonRegistrationNotOpen
+			RespondConflict(ctx, "registration_not_open", "registration for this event has not opened yet.")
+		case errors.Is(err, registration.ErrRegistrationClosed):
+			reason = funnel.ReasonRegistrationClosed
+			RespondConflict(ctx, "registration_closed", "registration for this event has closed.")
+		case errors.Is(err, registration.ErrAuthRequired):
+			reason = funnel.ReasonAuthRequired
+			RespondUnAuthorized(ctx, "auth_required", "this event requires you to be logged in to register.")
+		case errors.Is(err, registration.ErrEmailDomainNotAllowed):
+			reason = funnel.ReasonEmailDomainNotAllowed
+			RespondError(ctx, http.StatusForbidden, "email_domain_not_allowed", "registration for this event is restricted to specific email domains.", nil)
 		case errors.Is(err, event.ErrNotFound):
+			reason = funnel.ReasonNotFound
 			RespondNotFound(ctx, "Event not found")
 		default:
 			RespondInternal(ctx, "Could not register for event")
@@ -111,6 +195,29 @@ func (h *RegistrationHandler) Register(ctx *gin.Context) {
 		return
 	}
 
+	// waitlisted sign-ups are confirmed (and notified) on promotion, not now
+	if reg.IsWaitlisted() {
+		reason = funnel.ReasonWaitlisted
+		ctx.JSON(http.StatusAccepted, reg)
+		return
+	}
+
+	if createdJob.ID != "" {
+		ctx.Set(middlewares.CtxJobID, createdJob.ID)
+		slog.Default().InfoContext(cctx, "job.enqueue",
+			"request_id", requestIDFrom(ctx),
+			"job_id", createdJob.ID,
+			"job_type", createdJob.Type,
+			"already_enqueued", false,
+		)
+	}
+	reason = funnel.ReasonOK
+	ctx.JSON(http.StatusCreated, reg)
+}
+
+// confirmationJob is the registration.confirmation Register enqueues for
+// reg, inside the transaction creating it.
+func (h *RegistrationHandler) confirmationJob(ctx *gin.Context, cctx context.Context, reg registration.Registration, userID string) (job.CreateRequest, error) {
 	payload := jobs.RegistrationConfirmationPayload{
 		RegistrationID: reg.ID,
 		EventID:        reg.EventID,
@@ -119,55 +226,30 @@ func (h *RegistrationHandler) Register(ctx *gin.Context) {
 		RequestedAt:    time.Now().UTC(),
 		RequestID:      requestIDFrom(ctx),
 	}
+	payload = withBranding(cctx, h.branding, reg.EventID, payload)
 
 	raw, err := payload.JSON()
-
 	if err != nil {
-		RespondInternal(ctx, "Could not register for event")
-		fmt.Println(err)
-		return
+		return job.CreateRequest{}, err
 	}
 
 	// idempotency key
 	key := "registration:confirm:" + reg.ID
 
-	//capture userID as a variable so we can take its address
-	uid := userID
+	// anonymous registrations have no owning user
+	var uid *string
+	if userID != "" {
+		uid = &userID
+	}
 
-	createdJob, err := h.jobsRepo.CreateTx(cctx, tx, job.CreateRequest{
+	return job.CreateRequest{
 		Type:           jobs.TypeRegistrationConfirmation,
 		Payload:        raw,
 		RunAt:          time.Now().UTC(),
 		MaxAttempts:    10,
 		IdempotencyKey: &key,
-		UserID:         &uid,
-	})
-	if err != nil {
-		// if duplicate idempotency key inside same tx, treat as OK (rare, but safe)
-		if !postgres.IsUniqueViolation(err) {
-			RespondInternal(ctx, "Could not register for event")
-			fmt.Println(err)
-			return
-		}
-	}
-
-	// Commit once
-	err = tx.Commit(cctx)
-	if err != nil {
-		RespondInternal(ctx, "Could not register for event")
-		fmt.Println(err)
-		return
-	}
-	if createdJob.ID != "" {
-		ctx.Set(middlewares.CtxJobID, createdJob.ID)
-		slog.Default().InfoContext(cctx, "job.enqueue",
-			"request_id", requestIDFrom(ctx),
-			"job_id", createdJob.ID,
-			"job_type", createdJob.Type,
-			"already_enqueued", false,
-		)
-	}
-	ctx.JSON(http.StatusCreated, reg)
+		UserID:         uid,
+	}, nil
 }
 
 func (h *RegistrationHandler) ListForEvent(ctx *gin.Context) {
@@ -190,6 +272,24 @@ func (h *RegistrationHandler) ListForEvent(ctx *gin.Context) {
 	includeTotal := ctx.Query("includeTotal") == "true"
 	cursor := ctx.Query("cursor")
 
+	var filter registration.ListFilter
+	if raw := ctx.Query("checkedIn"); raw != "" {
+		checkedIn, err := strconv.ParseBool(raw)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "checkedIn must be true or false")
+			return
+		}
+		filter.CheckedIn = &checkedIn
+	}
+	if raw := ctx.Query("includeCancelled"); raw != "" {
+		includeCancelled, err := strconv.ParseBool(raw)
+		if err != nil {
+			RespondBadRequest(ctx, "invalid_query", "includeCancelled must be true or false")
+			return
+		}
+		filter.IncludeCancelled = includeCancelled
+	}
+
 	afterCreatedAt := time.Unix(0, 0).UTC()
 	afterID := "00000000-0000-0000-0000-000000000000"
 
@@ -203,10 +303,10 @@ func (h *RegistrationHandler) ListForEvent(ctx *gin.Context) {
 		afterID = cur.ID
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
-	items, next, hasMore, err := h.repo.ListByEventCursor(cctx, eventID, limit, afterCreatedAt, afterID)
+	items, next, hasMore, err := h.repo.ListByEventCursor(cctx, eventID, filter, limit, afterCreatedAt, afterID)
 	if err != nil {
 		RespondInternal(ctx, "Could not list registrations")
 		return
@@ -214,7 +314,7 @@ func (h *RegistrationHandler) ListForEvent(ctx *gin.Context) {
 
 	var total *int
 	if includeTotal {
-		t, err := h.repo.CountForEvent(cctx, eventID)
+		t, err := h.repo.CountForEvent(cctx, eventID, filter)
 		if err != nil {
 			RespondInternal(ctx, "Could not count registrations")
 			return
@@ -222,6 +322,13 @@ func (h *RegistrationHandler) ListForEvent(ctx *gin.Context) {
 		total = &t
 	}
 
+	visibility, err := callerPIIVisibility(ctx, cctx, h.organizers, eventID)
+	if err != nil {
+		RespondInternal(ctx, "Could not verify event ownership")
+		return
+	}
+	items = registration.ProjectAll(items, visibility)
+
 	resp := BuildCursorPageResponse(limit, items, hasMore, next, total)
 
 	RespondJSONWithETag(ctx, http.StatusOK, resp)
@@ -251,7 +358,12 @@ func (h *RegistrationHandler) Cancel(ctx *gin.Context) {
 
 	role, _ := middlewares.RoleFromContext(ctx)
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	reason, ok := bindCancelReason(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	// Load registration to check ownership
@@ -271,7 +383,7 @@ func (h *RegistrationHandler) Cancel(ctx *gin.Context) {
 
 	// Check ownership (admin override)
 
-	if role != "admin" && reg.UserID != userID {
+	if role != user.RoleAdmin && reg.UserID != userID {
 		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 			"error": gin.H{
 				"code":    "forbidden",
@@ -281,21 +393,101 @@ func (h *RegistrationHandler) Cancel(ctx *gin.Context) {
 
 		return
 	}
-	// Else delete
-	err = h.repo.Delete(cctx, eventID, regID)
+
+	if reg.IsCancelled() {
+		respondAlreadyCancelled(ctx)
+		return
+	}
+
+	// an admin cancelling someone else's registration is recorded by id
+	by := registration.CancelledBySelf
+	if reg.UserID != userID {
+		by = userID
+	}
+
+	err = h.repo.Cancel(cctx, eventID, regID, registration.Cancellation{By: by, Reason: reason})
 	if err != nil {
-		if errors.Is(err, registration.ErrNotFound) {
+		switch {
+		case errors.Is(err, registration.ErrNotFound):
 			RespondNotFound(ctx, "Registration not found")
-			return
+		case errors.Is(err, registration.ErrAlreadyCancelled):
+			respondAlreadyCancelled(ctx)
+		default:
+			RespondInternal(ctx, "Could not cancel registration")
 		}
+		return
+	}
 
+	h.recordFunnel(eventID, funnel.StageCancel, funnel.ReasonOK)
+	ctx.Status(http.StatusNoContent)
+}
+
+// CancelByToken lets an attendee cancel using the signed link from their
+// confirmation, without logging in.
+func (h *RegistrationHandler) CancelByToken(ctx *gin.Context) {
+	token := strings.TrimSpace(ctx.Query("token"))
+	if token == "" {
+		RespondBadRequest(ctx, "invalid_request", "token is required")
+		return
+	}
+
+	if h.cancelTokens == nil {
 		RespondInternal(ctx, "Could not cancel registration")
 		return
 	}
 
+	claims, err := h.cancelTokens.Verify(token)
+	if err != nil {
+		if errors.Is(err, canceltoken.ErrExpired) {
+			RespondUnAuthorized(ctx, "token_expired", "cancellation link has expired")
+			return
+		}
+		RespondUnAuthorized(ctx, "invalid_token", "cancellation link is invalid")
+		return
+	}
+
+	reason, ok := bindCancelReason(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	err = h.repo.Cancel(cctx, claims.EventID, claims.RegistrationID, registration.Cancellation{By: registration.CancelledBySelf, Reason: reason})
+	if err != nil {
+		// a link that was already used reads as gone, not as a conflict
+		switch {
+		case errors.Is(err, registration.ErrNotFound), errors.Is(err, registration.ErrAlreadyCancelled):
+			RespondNotFound(ctx, "Registration not found")
+		default:
+			RespondInternal(ctx, "Could not cancel registration")
+		}
+		return
+	}
+
+	h.recordFunnel(claims.EventID, funnel.StageCancel, funnel.ReasonOK)
 	ctx.Status(http.StatusNoContent)
 }
 
+// authorizeOrganizer lets through the :id event's organizer and admins;
+// without an organizers reader only admins.
+func (h *RegistrationHandler) authorizeOrganizer(ctx *gin.Context, forbidden string) bool {
+	if h.organizers != nil {
+		_, ok := authorizeEventOrganizer(ctx, h.organizers, forbidden)
+		return ok
+	}
+	if role, _ := middlewares.RoleFromContext(ctx); role != user.RoleAdmin {
+		RespondError(ctx, http.StatusForbidden, "forbidden", forbidden, nil)
+		return false
+	}
+	return true
+}
+
+func respondAlreadyCancelled(ctx *gin.Context) {
+	RespondConflict(ctx, "already_cancelled", "This registration is already cancelled.")
+}
+
 func (h *RegistrationHandler) CheckIn(ctx *gin.Context) {
 	eventID := ctx.Param("id")
 	if !utils.IsUUID(eventID) {
@@ -314,7 +506,7 @@ func (h *RegistrationHandler) CheckIn(ctx *gin.Context) {
 		return
 	}
 
-	cctx, cancel := config.WithTimeout(2 * time.Second)
+	cctx, cancel := DBTimeout(ctx)
 	defer cancel()
 
 	reg, err := h.repo.CheckInByToken(cctx, eventID, token)
@@ -332,3 +524,42 @@ func (h *RegistrationHandler) CheckIn(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, reg)
 }
+
+// CheckInByID handles POST /events/:id/registrations/:registrationId/checkin
+// for door staff who look attendees up by name instead of scanning a token.
+func (h *RegistrationHandler) CheckInByID(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+	regID := ctx.Param("registrationId")
+
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "event id must be a valid UUID")
+		return
+	}
+
+	if !utils.IsUUID(regID) {
+		RespondBadRequest(ctx, "invalid_id", "registration id must be a valid UUID")
+		return
+	}
+
+	if !h.authorizeOrganizer(ctx, "You can only check in attendees of events you organize") {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	reg, err := h.repo.CheckIn(cctx, eventID, regID)
+	if err != nil {
+		switch {
+		case errors.Is(err, registration.ErrAlreadyCheckedIn):
+			RespondConflict(ctx, "already_checked_in", "registration is already checked in")
+		case errors.Is(err, registration.ErrNotFound):
+			RespondNotFound(ctx, "Registration not found")
+		default:
+			RespondInternal(ctx, "Could not check in registration")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reg)
+}
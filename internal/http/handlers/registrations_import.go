@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jackc/pgx/v5"
+)
+
+type RegistrationImporter interface {
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	ImportTx(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error)
+}
+
+// ImportJobsCreator enqueues one confirmation per imported registration in a
+// single statement.
+type ImportJobsCreator interface {
+	CreateManyTx(ctx context.Context, tx pgx.Tx, reqs []job.CreateRequest) ([]job.Job, error)
+}
+
+type RegistrationImportHandler struct {
+	repo     RegistrationImporter
+	jobsRepo ImportJobsCreator
+	branding BrandingReader
+}
+
+func NewRegistrationImportHandler(repo RegistrationImporter, jobsRepo ImportJobsCreator) *RegistrationImportHandler {
+	return &RegistrationImportHandler{repo: repo, jobsRepo: jobsRepo}
+}
+
+// WithBranding puts the event's organizer branding on imported confirmations.
+func (h *RegistrationImportHandler) WithBranding(r BrandingReader) *RegistrationImportHandler {
+	h.branding = r
+	return h
+}
+
+var errImportHeader = errors.New(`csv header must contain "name" and "email" columns`)
+
+// Import handles POST /admin/events/:id/registrations/import. The body is a
+// JSON array of {name, email} or, with Content-Type text/csv, a CSV file with a
+// name,email header. Valid rows land in one transaction; invalid rows and
+// emails seen earlier in the file or already registered are reported per row.
+func (h *RegistrationImportHandler) Import(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+
+	if !utils.IsUUID(eventID) {
+		RespondBadRequest(ctx, "invalid_id", "event id must be a valid UUID")
+		return
+	}
+
+	overrideCapacity, err := parseBoolQuery(ctx, "overrideCapacity")
+	if err != nil {
+		RespondBadRequest(ctx, "invalid_query", "overrideCapacity must be true or false")
+		return
+	}
+	sendConfirmations, err := parseBoolQuery(ctx, "sendConfirmations")
+	if err != nil {
+		RespondBadRequest(ctx, "invalid_query", "sendConfirmations must be true or false")
+		return
+	}
+
+	var rows []registration.ImportRow
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if mediaType == "text/csv" {
+		rows, err = readImportCSV(ctx.Request.Body)
+	} else {
+		// rows are validated one by one below, so only the row cap is checked here
+		err = decodeJSONWithLimits(ctx.Request.Body, &rows, BindLimits{MaxItems: registration.MaxImportRows})
+	}
+
+	var itemErr *itemLimitError
+	if errors.As(err, &itemErr) && itemErr.Field == "" {
+		respondTooManyImportRows(ctx, itemErr.Items)
+		return
+	}
+	if err != nil {
+		RespondBadRequest(ctx, "Invalid request body", gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(rows) == 0 {
+		RespondBadRequest(ctx, "Invalid request body", gin.H{"error": "no rows to import"})
+		return
+	}
+	if len(rows) > registration.MaxImportRows {
+		respondTooManyImportRows(ctx, len(rows))
+		return
+	}
+
+	results := make([]registration.ImportRowResult, len(rows))
+	accepted := make([]registration.ImportRow, 0, len(rows))
+	acceptedAt := make([]int, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+
+	for i, row := range rows {
+		row.Name = strings.TrimSpace(row.Name)
+		row.Email = strings.TrimSpace(row.Email)
+		results[i] = registration.ImportRowResult{Row: i + 1, Email: row.Email}
+
+		if err := binding.Validator.ValidateStruct(&row); err != nil {
+			results[i].Status = registration.ImportInvalid
+			results[i].Details = parseBindError(err, &row)
+			continue
+		}
+
+		email := registration.NormalizeEmail(row.Email)
+		if seen[email] {
+			results[i].Status = registration.ImportDuplicate
+			continue
+		}
+		seen[email] = true
+
+		accepted = append(accepted, row)
+		acceptedAt = append(acceptedAt, i)
+	}
+
+	// one insert for up to 5000 rows plus their confirmation jobs
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	var created []registration.Registration
+	var tx pgx.Tx
+	if len(accepted) > 0 {
+		tx, err = h.repo.BeginTx(cctx)
+		if err != nil {
+			RespondInternal(ctx, "Could not import registrations")
+			return
+		}
+		defer func() { _ = tx.Rollback(cctx) }()
+
+		created, err = h.repo.ImportTx(cctx, tx, eventID, accepted, overrideCapacity)
+		if err != nil {
+			var fullErr *registration.FullError
+
+			switch {
+			case errors.As(err, &fullErr):
+				RespondError(ctx, http.StatusConflict, "event_full", "this event does not have enough seats left for the import.", gin.H{"remaining": fullErr.Remaining, "requested": len(accepted)})
+			case errors.Is(err, event.ErrNotFound):
+				RespondNotFound(ctx, "Event not found")
+			default:
+				_ = ctx.Error(err)
+				RespondInternal(ctx, "Could not import registrations")
+			}
+			return
+		}
+	}
+
+	// ImportTx skips emails already registered and keeps the input order
+	byEmail := make(map[string]registration.Registration, len(created))
+	for _, reg := range created {
+		byEmail[reg.Email] = reg
+	}
+	for _, i := range acceptedAt {
+		reg, ok := byEmail[registration.NormalizeEmail(rows[i].Email)]
+		if !ok {
+			results[i].Status = registration.ImportDuplicate
+			continue
+		}
+		results[i].Status = registration.ImportCreated
+		results[i].RegistrationID = reg.ID
+	}
+
+	var enqueued []job.Job
+	if sendConfirmations && len(created) > 0 {
+		branded := withBranding(cctx, h.branding, eventID, jobs.RegistrationConfirmationPayload{})
+		reqs := make([]job.CreateRequest, 0, len(created))
+		for _, reg := range created {
+			req, err := importConfirmationJob(ctx, reg, branded)
+			if err != nil {
+				_ = ctx.Error(err)
+				RespondInternal(ctx, "Could not import registrations")
+				return
+			}
+			reqs = append(reqs, req)
+		}
+
+		enqueued, err = h.jobsRepo.CreateManyTx(cctx, tx, reqs)
+		if err != nil {
+			_ = ctx.Error(err)
+			RespondInternal(ctx, "Could not import registrations")
+			return
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit(cctx); err != nil {
+			_ = ctx.Error(err)
+			RespondInternal(ctx, "Could not import registrations")
+			return
+		}
+	}
+
+	for _, j := range enqueued {
+		slog.Default().InfoContext(cctx, "job.enqueue",
+			"request_id", requestIDFrom(ctx),
+			"job_id", j.ID,
+			"job_type", j.Type,
+			"already_enqueued", false,
+		)
+	}
+
+	var counts registration.ImportCounts
+	for _, r := range results {
+		switch r.Status {
+		case registration.ImportCreated:
+			counts.Created++
+		case registration.ImportDuplicate:
+			counts.Duplicate++
+		case registration.ImportInvalid:
+			counts.Invalid++
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"counts":  counts,
+	})
+}
+
+func respondTooManyImportRows(ctx *gin.Context, rows int) {
+	RespondError(ctx, http.StatusBadRequest, "too_many_rows",
+		fmt.Sprintf("an import takes at most %d rows.", registration.MaxImportRows),
+		gin.H{"maxRows": registration.MaxImportRows, "rows": rows})
+}
+
+// importConfirmationJob mirrors the confirmation Register enqueues; imported
+// registrations have no owning user, like anonymous sign-ups. The branding
+// fields come from branded, looked up once per import.
+func importConfirmationJob(ctx *gin.Context, reg registration.Registration, branded jobs.RegistrationConfirmationPayload) (job.CreateRequest, error) {
+	p := branded
+	p.RegistrationID = reg.ID
+	p.EventID = reg.EventID
+	p.Email = reg.Email
+	p.Name = reg.Name
+	p.RequestedAt = time.Now().UTC()
+	p.RequestID = requestIDFrom(ctx)
+
+	raw, err := p.JSON()
+	if err != nil {
+		return job.CreateRequest{}, err
+	}
+
+	key := "registration:confirm:" + reg.ID
+	return job.CreateRequest{
+		Type:           jobs.TypeRegistrationConfirmation,
+		Payload:        raw,
+		RunAt:          time.Now().UTC(),
+		MaxAttempts:    10,
+		IdempotencyKey: &key,
+	}, nil
+}
+
+func parseBoolQuery(ctx *gin.Context, name string) (bool, error) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// readImportCSV reads name,email rows; the header names the columns in any
+// order and extra columns are ignored.
+func readImportCSV(body io.Reader) ([]registration.ImportRow, error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	nameCol, emailCol := -1, -1
+	for i, col := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))) {
+		case "name":
+			nameCol = i
+		case "email":
+			emailCol = i
+		}
+	}
+	if nameCol < 0 || emailCol < 0 {
+		return nil, errImportHeader
+	}
+
+	var rows []registration.ImportRow
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// stop counting early rather than buffering an oversized file
+		if len(rows) > registration.MaxImportRows {
+			break
+		}
+
+		var row registration.ImportRow
+		if nameCol < len(rec) {
+			row.Name = rec[nameCol]
+		}
+		if emailCol < len(rec) {
+			row.Email = rec[emailCol]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
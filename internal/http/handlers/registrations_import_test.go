@@ -0,0 +1,231 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type fakeImportRepo struct {
+	importTxFn func(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error)
+}
+
+func (f *fakeImportRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (f *fakeImportRepo) ImportTx(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error) {
+	return f.importTxFn(ctx, tx, eventID, rows, overrideCapacity)
+}
+
+// importAll creates every row it is given, except the emails in existing.
+func importAll(existing ...string) func(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error) {
+	return func(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error) {
+		var out []registration.Registration
+	rows:
+		for _, row := range rows {
+			for _, e := range existing {
+				if registration.NormalizeEmail(row.Email) == e {
+					continue rows
+				}
+			}
+			out = append(out, registration.NewFromCreateRequest(registration.CreateRegistrationRequest{
+				EventID: eventID,
+				Name:    row.Name,
+				Email:   row.Email,
+			}))
+		}
+		return out, nil
+	}
+}
+
+type fakeImportJobs struct {
+	created []job.CreateRequest
+}
+
+func (f *fakeImportJobs) CreateManyTx(ctx context.Context, tx pgx.Tx, reqs []job.CreateRequest) ([]job.Job, error) {
+	f.created = append(f.created, reqs...)
+	out := make([]job.Job, 0, len(reqs))
+	for _, req := range reqs {
+		out = append(out, job.New(req))
+	}
+	return out, nil
+}
+
+type importResponse struct {
+	Results []registration.ImportRowResult `json:"results"`
+	Counts  registration.ImportCounts      `json:"counts"`
+}
+
+func doImport(t *testing.T, h *handlers.RegistrationImportHandler, eventID, query, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := gin.New()
+	r.POST("/admin/events/:id/registrations/import", h.Import)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/registrations/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestImportRegistrations_JSONReportsEachRow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotOverride bool
+	var gotRows []registration.ImportRow
+	repo := &fakeImportRepo{}
+	repo.importTxFn = func(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error) {
+		gotOverride = overrideCapacity
+		gotRows = rows
+		return importAll("taken@example.com")(ctx, tx, eventID, rows, overrideCapacity)
+	}
+	jobsRepo := &fakeImportJobs{}
+	h := handlers.NewRegistrationImportHandler(repo, jobsRepo)
+
+	body := `[
+		{"name":"Ada Lovelace","email":"ada@example.com"},
+		{"name":"Ada Again","email":" ADA@example.com "},
+		{"name":"X","email":"not-an-email"},
+		{"name":"Already Here","email":"taken@example.com"},
+		{"name":"Grace Hopper","email":"grace@example.com"}
+	]`
+	w := doImport(t, h, newUUID(), "?overrideCapacity=true", "application/json", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var resp importResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	statuses := make([]string, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		statuses = append(statuses, r.Status)
+	}
+	if got, want := strings.Join(statuses, ","), "created,duplicate,invalid,duplicate,created"; got != want {
+		t.Fatalf("statuses = %s, want %s", got, want)
+	}
+	if resp.Results[0].RegistrationID == "" || resp.Results[4].RegistrationID == "" {
+		t.Fatalf("created rows should carry a registration id: %+v", resp.Results)
+	}
+	if resp.Results[2].Details == nil {
+		t.Fatalf("invalid row should carry validation details")
+	}
+	if resp.Counts != (registration.ImportCounts{Created: 2, Duplicate: 2, Invalid: 1}) {
+		t.Fatalf("unexpected counts: %+v", resp.Counts)
+	}
+
+	if !gotOverride {
+		t.Fatalf("overrideCapacity=true was not passed to the repo")
+	}
+	if len(gotRows) != 3 {
+		t.Fatalf("expected 3 rows to reach the repo after in-file dedupe, got %d", len(gotRows))
+	}
+	if len(jobsRepo.created) != 0 {
+		t.Fatalf("no confirmation jobs expected without sendConfirmations, got %d", len(jobsRepo.created))
+	}
+}
+
+func TestImportRegistrations_CSVWithConfirmations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeImportRepo{importTxFn: importAll()}
+	jobsRepo := &fakeImportJobs{}
+	h := handlers.NewRegistrationImportHandler(repo, jobsRepo)
+
+	body := "\ufeffEmail,Name,Ticket\nada@example.com,Ada Lovelace,VIP\ngrace@example.com,Grace Hopper,GA\n"
+	w := doImport(t, h, newUUID(), "?sendConfirmations=true", "text/csv; charset=utf-8", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var resp importResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Counts.Created != 2 {
+		t.Fatalf("expected 2 created, got %+v", resp.Counts)
+	}
+	if len(jobsRepo.created) != 2 {
+		t.Fatalf("expected 2 confirmation jobs, got %d", len(jobsRepo.created))
+	}
+	if key := *jobsRepo.created[0].IdempotencyKey; key != "registration:confirm:"+resp.Results[0].RegistrationID {
+		t.Fatalf("unexpected idempotency key %q", key)
+	}
+}
+
+func TestImportRegistrations_RejectsBadInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := make([]registration.ImportRow, registration.MaxImportRows+1)
+	raw, _ := json.Marshal(tooMany)
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		wantCode    string
+	}{
+		{"empty array", "", "application/json", `[]`, "invalid_request"},
+		{"not an array", "", "application/json", `{"name":"a"}`, "invalid_request"},
+		{"csv without email column", "", "text/csv", "name\nAda\n", "invalid_request"},
+		{"bad flag", "?overrideCapacity=maybe", "application/json", `[{"name":"Ada","email":"ada@example.com"}]`, "invalid_request"},
+		{"too many rows", "", "application/json", string(raw), "too_many_rows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeImportRepo{importTxFn: func(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error) {
+				t.Fatalf("repo should not be called")
+				return nil, nil
+			}}
+			h := handlers.NewRegistrationImportHandler(repo, &fakeImportJobs{})
+
+			w := doImport(t, h, newUUID(), tt.query, tt.contentType, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d body=%s", w.Code, w.Body.String())
+			}
+			var resp struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Fatalf("expected code %s, got %s", tt.wantCode, resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestImportRegistrations_EventFull(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeImportRepo{importTxFn: func(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) ([]registration.Registration, error) {
+		return nil, &registration.FullError{Remaining: 1}
+	}}
+	h := handlers.NewRegistrationImportHandler(repo, &fakeImportJobs{})
+
+	w := doImport(t, h, newUUID(), "", "application/json", `[{"name":"Ada","email":"ada@example.com"},{"name":"Grace","email":"grace@example.com"}]`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"event_full"`) {
+		t.Fatalf("expected event_full, got %s", w.Body.String())
+	}
+}
@@ -1,60 +1,100 @@
 package handlers_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/geocoder89/eventhub/internal/canceltoken"
 	"github.com/geocoder89/eventhub/internal/domain/job"
 	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 )
 
 type fakeRegistrationsRepo struct {
-	listByEventCursorFn func(ctx context.Context, eventID string, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error)
-	countForEventFn     func(ctx context.Context, eventID string) (int, error)
+	listByEventCursorFn func(ctx context.Context, eventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error)
+	countForEventFn     func(ctx context.Context, eventID string, filter registration.ListFilter) (int, error)
+	createTxFn          func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error)
+	getByIDFn           func(ctx context.Context, eventID, registrationID string) (registration.Registration, error)
+	cancelFn            func(ctx context.Context, eventID, registrationID string, c registration.Cancellation) error
+	checkInFn           func(ctx context.Context, eventID, registrationID string) (registration.Registration, error)
+
+	// confirmation jobs enqueued with their registration
+	confirmations []job.CreateRequest
 }
 
-func (f *fakeRegistrationsRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	return nil, nil
+// fakeTx satisfies pgx.Tx for handlers that only commit/rollback.
+type fakeTx struct {
+	pgx.Tx
 }
 
-func (f *fakeRegistrationsRepo) CreateTx(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
-	return registration.Registration{}, nil
+func (fakeTx) Commit(ctx context.Context) error   { return nil }
+func (fakeTx) Rollback(ctx context.Context) error { return nil }
+
+func (f *fakeRegistrationsRepo) CreateWithConfirmation(ctx context.Context, req registration.CreateRegistrationRequest, confirm postgres.EnqueueConfirmation) (registration.Registration, job.Job, error) {
+	var reg registration.Registration
+	if f.createTxFn != nil {
+		var err error
+		if reg, err = f.createTxFn(ctx, fakeTx{}, req); err != nil {
+			return registration.Registration{}, job.Job{}, err
+		}
+	}
+	if reg.IsWaitlisted() {
+		return reg, job.Job{}, nil
+	}
+
+	jobReq, err := confirm.ConfirmationJob(ctx, reg)
+	if err != nil {
+		return registration.Registration{}, job.Job{}, err
+	}
+	f.confirmations = append(f.confirmations, jobReq)
+	return reg, job.Job{ID: newUUID(), Type: jobReq.Type}, nil
 }
 
 func (f *fakeRegistrationsRepo) Create(ctx context.Context, req registration.CreateRegistrationRequest) (registration.Registration, error) {
 	return registration.Registration{}, nil
 }
 
-func (f *fakeRegistrationsRepo) ListByEvent(ctx context.Context, eventID string) ([]registration.Registration, error) {
+func (f *fakeRegistrationsRepo) ListByEvent(ctx context.Context, eventID string, filter registration.ListFilter) ([]registration.Registration, error) {
 	return nil, nil
 }
 
-func (f *fakeRegistrationsRepo) ListByEventCursor(ctx context.Context, eventID string, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
+func (f *fakeRegistrationsRepo) ListByEventCursor(ctx context.Context, eventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
 	if f.listByEventCursorFn != nil {
-		return f.listByEventCursorFn(ctx, eventID, limit, afterCreatedAt, afterID)
+		return f.listByEventCursorFn(ctx, eventID, filter, limit, afterCreatedAt, afterID)
 	}
 	return nil, nil, false, nil
 }
 
-func (f *fakeRegistrationsRepo) CountForEvent(ctx context.Context, eventID string) (int, error) {
+func (f *fakeRegistrationsRepo) CountForEvent(ctx context.Context, eventID string, filter registration.ListFilter) (int, error) {
 	if f.countForEventFn != nil {
-		return f.countForEventFn(ctx, eventID)
+		return f.countForEventFn(ctx, eventID, filter)
 	}
 	return 0, nil
 }
 
 func (f *fakeRegistrationsRepo) GetByID(ctx context.Context, eventID, registrationID string) (registration.Registration, error) {
+	if f.getByIDFn != nil {
+		return f.getByIDFn(ctx, eventID, registrationID)
+	}
 	return registration.Registration{}, nil
 }
 
-func (f *fakeRegistrationsRepo) Delete(ctx context.Context, eventID, registrationID string) error {
+func (f *fakeRegistrationsRepo) Cancel(ctx context.Context, eventID, registrationID string, c registration.Cancellation) error {
+	if f.cancelFn != nil {
+		return f.cancelFn(ctx, eventID, registrationID, c)
+	}
 	return nil
 }
 
@@ -62,18 +102,11 @@ func (f *fakeRegistrationsRepo) CheckInByToken(ctx context.Context, eventID, tok
 	return registration.Registration{}, nil
 }
 
-type fakeJobsCreator struct{}
-
-func (f *fakeJobsCreator) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
-	return job.Job{}, nil
-}
-
-func (f *fakeJobsCreator) CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error) {
-	return job.Job{}, nil
-}
-
-func (f *fakeJobsCreator) GetByIdempotencyKey(ctx context.Context, key string) (job.Job, error) {
-	return job.Job{}, nil
+func (f *fakeRegistrationsRepo) CheckIn(ctx context.Context, eventID, registrationID string) (registration.Registration, error) {
+	if f.checkInFn != nil {
+		return f.checkInFn(ctx, eventID, registrationID)
+	}
+	return registration.Registration{}, nil
 }
 
 func TestRegistrationListForEvent_ETagNotModified(t *testing.T) {
@@ -86,7 +119,7 @@ func TestRegistrationListForEvent_ETagNotModified(t *testing.T) {
 	repoCalls := 0
 	repo := &fakeRegistrationsRepo{}
 
-	repo.listByEventCursorFn = func(ctx context.Context, gotEventID string, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
+	repo.listByEventCursorFn = func(ctx context.Context, gotEventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
 		repoCalls++
 		if gotEventID != eventID {
 			t.Fatalf("unexpected event id: %s", gotEventID)
@@ -114,7 +147,7 @@ func TestRegistrationListForEvent_ETagNotModified(t *testing.T) {
 		}, nil, false, nil
 	}
 
-	h := handlers.NewRegistrationHandler(repo, &fakeJobsCreator{})
+	h := handlers.NewRegistrationHandler(repo)
 	r := gin.New()
 	r.GET("/events/:id/registrations", h.ListForEvent)
 
@@ -153,3 +186,728 @@ func TestRegistrationListForEvent_ETagNotModified(t *testing.T) {
 		t.Fatalf("expected repo calls=2, got %d", repoCalls)
 	}
 }
+
+func TestRegister_EnqueuesConfirmationWithRegistration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	regID, userID := newUUID(), newUUID()
+	repo := &fakeRegistrationsRepo{}
+	repo.createTxFn = func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
+		return registration.Registration{ID: regID, EventID: req.EventID, Email: req.Email, Name: req.Name, Status: registration.StatusConfirmed}, nil
+	}
+	h := handlers.NewRegistrationHandler(repo)
+
+	r := gin.New()
+	r.POST("/events/:id/register", withUser(userID, "user"), h.Register)
+
+	req := httptest.NewRequest(http.MethodPost, "/events/"+newUUID()+"/register", bytes.NewBufferString(`{"name":"Sam Doe","email":"sam@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if len(repo.confirmations) != 1 {
+		t.Fatalf("expected one confirmation enqueued with the registration, got %d", len(repo.confirmations))
+	}
+	got := repo.confirmations[0]
+	if got.Type != "registration.confirmation" || got.IdempotencyKey == nil || *got.IdempotencyKey != "registration:confirm:"+regID ||
+		got.UserID == nil || *got.UserID != userID || !strings.Contains(string(got.Payload), "sam@example.com") {
+		t.Fatalf("unexpected confirmation job: %+v payload=%s", got, got.Payload)
+	}
+}
+
+func TestRegister_WaitlistedReturnsAcceptedWithoutConfirmation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	position := int64(3)
+	repo := &fakeRegistrationsRepo{}
+	repo.createTxFn = func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
+		if !req.JoinWaitlist {
+			t.Fatalf("expected joinWaitlist to be bound from the body")
+		}
+		return registration.Registration{
+			ID:               newUUID(),
+			EventID:          req.EventID,
+			Email:            req.Email,
+			Status:           registration.StatusWaitlisted,
+			WaitlistPosition: &position,
+		}, nil
+	}
+
+	h := handlers.NewRegistrationHandler(repo)
+
+	r := gin.New()
+	r.POST("/events/:id/register", withUser(newUUID(), "user"), h.Register)
+
+	req := httptest.NewRequest(http.MethodPost, "/events/"+newUUID()+"/register", bytes.NewBufferString(`{"name":"Sam Doe","email":"sam@example.com","joinWaitlist":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d, body=%s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+
+	var got registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.WaitlistPosition == nil || *got.WaitlistPosition != position {
+		t.Fatalf("expected waitlist position %d, got %v", position, got.WaitlistPosition)
+	}
+	if len(repo.confirmations) != 0 {
+		t.Fatalf("expected no confirmation job for waitlisted registration, got %d", len(repo.confirmations))
+	}
+}
+
+func TestCancelByToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := newUUID()
+	regID := newUUID()
+	signer := canceltoken.NewSigner("test-secret", time.Hour)
+	valid := signer.Sign(regID, eventID)
+
+	tests := []struct {
+		name       string
+		token      string
+		cancelErr  error
+		wantStatus int
+		wantCancel bool
+	}{
+		{name: "valid token cancels", token: valid, wantStatus: http.StatusNoContent, wantCancel: true},
+		{name: "already cancelled", token: valid, cancelErr: registration.ErrAlreadyCancelled, wantStatus: http.StatusNotFound, wantCancel: true},
+		{name: "unknown registration", token: valid, cancelErr: registration.ErrNotFound, wantStatus: http.StatusNotFound, wantCancel: true},
+		{name: "tampered token", token: valid + "x", wantStatus: http.StatusUnauthorized},
+		{name: "foreign signature", token: canceltoken.NewSigner("other", time.Hour).Sign(regID, eventID), wantStatus: http.StatusUnauthorized},
+		{name: "expired token", token: canceltoken.NewSigner("test-secret", -time.Minute).Sign(regID, eventID), wantStatus: http.StatusUnauthorized},
+		{name: "missing token", token: "", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cancelled := false
+			repo := &fakeRegistrationsRepo{}
+			repo.cancelFn = func(ctx context.Context, gotEventID, gotRegID string, c registration.Cancellation) error {
+				cancelled = true
+				if gotEventID != eventID || gotRegID != regID {
+					t.Fatalf("unexpected cancel target event=%s reg=%s", gotEventID, gotRegID)
+				}
+				if c.By != registration.CancelledBySelf || c.Reason != "" {
+					t.Fatalf("expected a reasonless self cancellation, got %+v", c)
+				}
+				return tc.cancelErr
+			}
+
+			h := handlers.NewRegistrationHandler(repo).WithCancelTokens(signer)
+			r := setupRouter(http.MethodDelete, "/registrations/cancel", h.CancelByToken)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/registrations/cancel?token="+tc.token, nil))
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if cancelled != tc.wantCancel {
+				t.Fatalf("cancel called=%v, want %v", cancelled, tc.wantCancel)
+			}
+		})
+	}
+}
+
+func TestCancelRegistration_AlreadyCancelledIsConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := newUUID()
+	regID := newUUID()
+	userID := newUUID()
+
+	tests := []struct {
+		name       string
+		status     string
+		cancelErr  error
+		wantStatus int
+		wantCancel bool
+	}{
+		{name: "confirmed is cancelled", status: registration.StatusConfirmed, wantStatus: http.StatusNoContent, wantCancel: true},
+		{name: "already cancelled", status: registration.StatusCancelled, wantStatus: http.StatusConflict},
+		// a concurrent cancel won between the read and the transition
+		{name: "lost race", status: registration.StatusConfirmed, cancelErr: registration.ErrAlreadyCancelled, wantStatus: http.StatusConflict, wantCancel: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cancelled := false
+			repo := &fakeRegistrationsRepo{}
+			repo.getByIDFn = func(ctx context.Context, gotEventID, gotRegID string) (registration.Registration, error) {
+				return registration.Registration{ID: gotRegID, EventID: gotEventID, UserID: userID, Status: tc.status}, nil
+			}
+			repo.cancelFn = func(ctx context.Context, gotEventID, gotRegID string, c registration.Cancellation) error {
+				cancelled = true
+				return tc.cancelErr
+			}
+
+			h := handlers.NewRegistrationHandler(repo)
+			r := gin.New()
+			r.DELETE("/events/:id/registrations/:registrationId", withUser(userID, "user"), h.Cancel)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/events/"+eventID+"/registrations/"+regID, nil))
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if cancelled != tc.wantCancel {
+				t.Fatalf("cancel called=%v, want %v", cancelled, tc.wantCancel)
+			}
+			if tc.wantStatus == http.StatusConflict && !strings.Contains(w.Body.String(), `"already_cancelled"`) {
+				t.Fatalf("expected already_cancelled code, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCancelRegistration_RecordsActorAndReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := newUUID()
+	regID := newUUID()
+	ownerID := newUUID()
+	adminID := newUUID()
+
+	tests := []struct {
+		name       string
+		callerID   string
+		role       user.Role
+		body       string
+		wantStatus int
+		want       *registration.Cancellation
+	}{
+		{name: "owner without body", callerID: ownerID, role: user.RoleUser, wantStatus: http.StatusNoContent, want: &registration.Cancellation{By: registration.CancelledBySelf}},
+		{name: "owner with reason", callerID: ownerID, role: user.RoleUser, body: `{"reason":"  cannot make it  "}`, wantStatus: http.StatusNoContent, want: &registration.Cancellation{By: registration.CancelledBySelf, Reason: "cannot make it"}},
+		{name: "admin is recorded by id", callerID: adminID, role: user.RoleAdmin, body: `{"reason":"duplicate sign-up"}`, wantStatus: http.StatusNoContent, want: &registration.Cancellation{By: adminID, Reason: "duplicate sign-up"}},
+		{name: "reason too long", callerID: ownerID, role: user.RoleUser, body: `{"reason":"` + strings.Repeat("x", 501) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed body", callerID: ownerID, role: user.RoleUser, body: `{"reason":`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *registration.Cancellation
+			repo := &fakeRegistrationsRepo{}
+			repo.getByIDFn = func(ctx context.Context, gotEventID, gotRegID string) (registration.Registration, error) {
+				return registration.Registration{ID: gotRegID, EventID: gotEventID, UserID: ownerID, Status: registration.StatusConfirmed}, nil
+			}
+			repo.cancelFn = func(ctx context.Context, gotEventID, gotRegID string, c registration.Cancellation) error {
+				got = &c
+				return nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo)
+			r := gin.New()
+			r.DELETE("/events/:id/registrations/:registrationId", withUser(tc.callerID, tc.role), h.Cancel)
+
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req := httptest.NewRequest(http.MethodDelete, "/events/"+eventID+"/registrations/"+regID, body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			switch {
+			case tc.want == nil && got != nil:
+				t.Fatalf("expected no cancellation, got %+v", *got)
+			case tc.want != nil && (got == nil || *got != *tc.want):
+				t.Fatalf("cancellation = %+v, want %+v", got, *tc.want)
+			}
+		})
+	}
+}
+
+func TestCancelByToken_RecordsReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := newUUID()
+	regID := newUUID()
+	signer := canceltoken.NewSigner("test-secret", time.Hour)
+
+	var got registration.Cancellation
+	repo := &fakeRegistrationsRepo{}
+	repo.cancelFn = func(ctx context.Context, gotEventID, gotRegID string, c registration.Cancellation) error {
+		got = c
+		return nil
+	}
+
+	h := handlers.NewRegistrationHandler(repo).WithCancelTokens(signer)
+	r := setupRouter(http.MethodDelete, "/registrations/cancel", h.CancelByToken)
+
+	req := httptest.NewRequest(http.MethodDelete, "/registrations/cancel?token="+signer.Sign(regID, eventID), strings.NewReader(`{"reason":"plans changed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want 204, body=%s", w.Code, w.Body.String())
+	}
+	if got.By != registration.CancelledBySelf || got.Reason != "plans changed" {
+		t.Fatalf("unexpected cancellation %+v", got)
+	}
+}
+
+func TestRegister_RestrictedEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		userID     string
+		tokenEmail string
+		repoErr    error
+		wantStatus int
+		wantCode   string
+		wantAuth   string
+		wantUserID string
+	}{
+		{name: "anonymous allowed", wantStatus: http.StatusCreated},
+		{name: "auth required", repoErr: registration.ErrAuthRequired, wantStatus: http.StatusUnauthorized, wantCode: "auth_required"},
+		{name: "domain not allowed", repoErr: registration.ErrEmailDomainNotAllowed, wantStatus: http.StatusForbidden, wantCode: "email_domain_not_allowed"},
+		{name: "window not open", repoErr: registration.ErrRegistrationNotOpen, wantStatus: http.StatusConflict, wantCode: "registration_not_open"},
+		{name: "window closed", repoErr: registration.ErrRegistrationClosed, wantStatus: http.StatusConflict, wantCode: "registration_closed"},
+		{name: "token email passed along", userID: "u-1", tokenEmail: "sam@corp.example", wantStatus: http.StatusCreated, wantAuth: "sam@corp.example", wantUserID: "u-1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotReq registration.CreateRegistrationRequest
+			repo := &fakeRegistrationsRepo{}
+			repo.createTxFn = func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
+				gotReq = req
+				if tc.repoErr != nil {
+					return registration.Registration{}, tc.repoErr
+				}
+				return registration.Registration{ID: newUUID(), EventID: req.EventID, Email: req.Email, Status: registration.StatusConfirmed}, nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo)
+
+			r := gin.New()
+			r.POST("/events/:id/register", func(c *gin.Context) {
+				if tc.userID != "" {
+					c.Set(middlewares.CtxUserID, tc.userID)
+					c.Set(middlewares.CtxEmail, tc.tokenEmail)
+				}
+				c.Next()
+			}, h.Register)
+
+			req := httptest.NewRequest(http.MethodPost, "/events/"+newUUID()+"/register", bytes.NewBufferString(`{"name":"Sam Doe","email":"sam@example.com"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if gotReq.Email != "sam@example.com" || gotReq.AuthEmail != tc.wantAuth {
+				t.Fatalf("repo got email=%q authEmail=%q, want authEmail %q", gotReq.Email, gotReq.AuthEmail, tc.wantAuth)
+			}
+			if gotReq.UserID != tc.wantUserID {
+				t.Fatalf("repo got user id %q, want %q", gotReq.UserID, tc.wantUserID)
+			}
+
+			if tc.wantCode != "" {
+				var body struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if body.Error.Code != tc.wantCode {
+					t.Fatalf("got error code %q, want %q", body.Error.Code, tc.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestRegister_Quantity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		body        string
+		repoErr     error
+		wantStatus  int
+		wantCode    string
+		wantDetails map[string]any
+		wantQty     int
+	}{
+		{name: "guest seats", body: `{"name":"Sam Doe","email":"sam@example.com","quantity":3}`, wantStatus: http.StatusCreated, wantQty: 3},
+		{name: "zero means one", body: `{"name":"Sam Doe","email":"sam@example.com"}`, wantStatus: http.StatusCreated, wantQty: 0},
+		{name: "negative rejected", body: `{"name":"Sam Doe","email":"sam@example.com","quantity":-1}`, wantStatus: http.StatusBadRequest},
+		{
+			name:        "over the event limit",
+			body:        `{"name":"Sam Doe","email":"sam@example.com","quantity":4}`,
+			repoErr:     &registration.QuantityLimitError{Max: 2},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "quantity_exceeds_limit",
+			wantDetails: map[string]any{"maxQuantity": float64(2)},
+			wantQty:     4,
+		},
+		{
+			name:        "partial fit is full",
+			body:        `{"name":"Sam Doe","email":"sam@example.com","quantity":3}`,
+			repoErr:     &registration.FullError{Remaining: 1},
+			wantStatus:  http.StatusConflict,
+			wantCode:    "event_full",
+			wantDetails: map[string]any{"remaining": float64(1), "requested": float64(3)},
+			wantQty:     3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotReq registration.CreateRegistrationRequest
+			called := false
+			repo := &fakeRegistrationsRepo{}
+			repo.createTxFn = func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
+				called = true
+				gotReq = req
+				if tc.repoErr != nil {
+					return registration.Registration{}, tc.repoErr
+				}
+				return registration.NewFromCreateRequest(req), nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo)
+			r := gin.New()
+			r.POST("/events/:id/register", h.Register)
+
+			req := httptest.NewRequest(http.MethodPost, "/events/"+newUUID()+"/register", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus == http.StatusBadRequest && tc.wantCode == "" {
+				if called {
+					t.Fatalf("invalid quantity should not reach the repo")
+				}
+				return
+			}
+			if gotReq.Quantity != tc.wantQty {
+				t.Fatalf("repo got quantity %d, want %d", gotReq.Quantity, tc.wantQty)
+			}
+
+			if tc.wantCode == "" {
+				var reg registration.Registration
+				if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if reg.Quantity != max(tc.wantQty, 1) {
+					t.Fatalf("got quantity %d in response, want %d", reg.Quantity, max(tc.wantQty, 1))
+				}
+				return
+			}
+
+			var body struct {
+				Error struct {
+					Code    string         `json:"code"`
+					Details map[string]any `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.Error.Code != tc.wantCode {
+				t.Fatalf("got error code %q, want %q", body.Error.Code, tc.wantCode)
+			}
+			for k, v := range tc.wantDetails {
+				if body.Error.Details[k] != v {
+					t.Fatalf("details[%s] = %v, want %v", k, body.Error.Details[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRegister_NormalizesEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeRegistrationsRepo{}
+	repo.createTxFn = func(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (registration.Registration, error) {
+		return registration.NewFromCreateRequest(req), nil
+	}
+
+	h := handlers.NewRegistrationHandler(repo)
+	r := gin.New()
+	r.POST("/events/:id/register", h.Register)
+
+	req := httptest.NewRequest(http.MethodPost, "/events/"+newUUID()+"/register", bytes.NewBufferString(`{"name":"Sam Doe","email":"Sam@Example.COM"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, body=%s", w.Code, w.Body.String())
+	}
+	var reg registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if reg.Email != "sam@example.com" {
+		t.Fatalf("expected lowercased email, got %q", reg.Email)
+	}
+}
+
+func TestCheckInByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := newUUID()
+	regID := newUUID()
+
+	organizerID := newUUID()
+
+	tests := []struct {
+		name       string
+		callerID   string
+		role       user.Role
+		repoErr    error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "success", callerID: organizerID, role: user.RoleUser, wantStatus: http.StatusOK},
+		{name: "admin", callerID: newUUID(), role: user.RoleAdmin, wantStatus: http.StatusOK},
+		{name: "double check-in", callerID: organizerID, role: user.RoleUser, repoErr: registration.ErrAlreadyCheckedIn, wantStatus: http.StatusConflict, wantCode: "already_checked_in"},
+		{name: "unknown registration", callerID: organizerID, role: user.RoleUser, repoErr: registration.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "someone else's event", callerID: newUUID(), role: user.RoleUser, wantStatus: http.StatusForbidden, wantCode: "forbidden"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeRegistrationsRepo{}
+			repo.checkInFn = func(ctx context.Context, gotEventID, gotRegID string) (registration.Registration, error) {
+				if tc.wantStatus == http.StatusForbidden {
+					t.Fatal("a stranger's check-in reached the repo")
+				}
+				if gotEventID != eventID || gotRegID != regID {
+					t.Fatalf("unexpected ids event=%s registration=%s", gotEventID, gotRegID)
+				}
+				if tc.repoErr != nil {
+					return registration.Registration{}, tc.repoErr
+				}
+				now := time.Now().UTC()
+				return registration.Registration{ID: regID, EventID: eventID, CheckedInAt: &now}, nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo).WithOrganizers(fakeOrganizers{eventID: organizerID})
+
+			r := gin.New()
+			r.POST("/events/:id/registrations/:registrationId/checkin", withUser(tc.callerID, tc.role), h.CheckInByID)
+
+			req := httptest.NewRequest(http.MethodPost, "/events/"+eventID+"/registrations/"+regID+"/checkin", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+
+			if tc.wantCode != "" {
+				var body struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if body.Error.Code != tc.wantCode {
+					t.Fatalf("got error code %q, want %q", body.Error.Code, tc.wantCode)
+				}
+				return
+			}
+
+			var got registration.Registration
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.CheckedInAt == nil {
+				t.Fatalf("expected checkedInAt in response")
+			}
+		})
+	}
+}
+
+func TestRegistrationListForEvent_CheckedInFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query      string
+		want       *bool
+		wantStatus int
+	}{
+		{query: "", want: nil, wantStatus: http.StatusOK},
+		{query: "?checkedIn=true", want: boolPtr(true), wantStatus: http.StatusOK},
+		{query: "?checkedIn=false", want: boolPtr(false), wantStatus: http.StatusOK},
+		{query: "?checkedIn=maybe", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			var got registration.ListFilter
+			repo := &fakeRegistrationsRepo{}
+			repo.listByEventCursorFn = func(ctx context.Context, eventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
+				got = filter
+				return []registration.Registration{}, nil, false, nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo)
+
+			r := gin.New()
+			r.GET("/events/:id/registrations", h.ListForEvent)
+
+			req := httptest.NewRequest(http.MethodGet, "/events/"+newUUID()+"/registrations"+tc.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if (got.CheckedIn == nil) != (tc.want == nil) || (got.CheckedIn != nil && *got.CheckedIn != *tc.want) {
+				t.Fatalf("got filter %v, want %v", got.CheckedIn, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegistrationListForEvent_IncludeCancelled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query      string
+		want       bool
+		wantStatus int
+	}{
+		{query: "", want: false, wantStatus: http.StatusOK},
+		{query: "?includeCancelled=true", want: true, wantStatus: http.StatusOK},
+		{query: "?includeCancelled=nope", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			var got registration.ListFilter
+			var counted registration.ListFilter
+			repo := &fakeRegistrationsRepo{}
+			repo.listByEventCursorFn = func(ctx context.Context, eventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
+				got = filter
+				return []registration.Registration{}, nil, false, nil
+			}
+			repo.countForEventFn = func(ctx context.Context, eventID string, filter registration.ListFilter) (int, error) {
+				counted = filter
+				return 0, nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo)
+
+			r := gin.New()
+			r.GET("/events/:id/registrations", h.ListForEvent)
+
+			sep := "?"
+			if tc.query != "" {
+				sep = "&"
+			}
+			req := httptest.NewRequest(http.MethodGet, "/events/"+newUUID()+"/registrations"+tc.query+sep+"includeTotal=true", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if got.IncludeCancelled != tc.want || counted.IncludeCancelled != tc.want {
+				t.Fatalf("list includeCancelled=%v count includeCancelled=%v, want %v", got.IncludeCancelled, counted.IncludeCancelled, tc.want)
+			}
+		})
+	}
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestRegistrationListForEvent_PIIVisibilityByRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := newUUID()
+	organizerID := newUUID()
+
+	tests := []struct {
+		name       string
+		userID     string
+		role       user.Role
+		wantMasked bool
+	}{
+		{"organizer", organizerID, user.RoleUser, false},
+		{"admin", newUUID(), user.RoleAdmin, false},
+		{"another user", newUUID(), user.RoleUser, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeRegistrationsRepo{}
+			repo.listByEventCursorFn = func(ctx context.Context, gotEventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
+				return []registration.Registration{{
+					ID:           newUUID(),
+					EventID:      eventID,
+					Name:         "Sam Smith",
+					Email:        "sam@example.com",
+					CheckInToken: "check-in-token-123",
+				}}, nil, false, nil
+			}
+
+			h := handlers.NewRegistrationHandler(repo).
+				WithOrganizers(fakeOrganizers{eventID: organizerID})
+			r := gin.New()
+			r.GET("/events/:id/registrations", withUser(tc.userID, tc.role), h.ListForEvent)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+eventID+"/registrations", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got %d body=%s", w.Code, w.Body.String())
+			}
+
+			var resp struct {
+				Items []registration.Registration `json:"items"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(resp.Items) != 1 {
+				t.Fatalf("expected one registration, got %d", len(resp.Items))
+			}
+			got := resp.Items[0]
+
+			if tc.wantMasked {
+				if got.Email != "s***@example.com" || got.Name != "S***" || got.CheckInToken != "" {
+					t.Fatalf("expected a masked registration, got %+v", got)
+				}
+				return
+			}
+			if got.Email != "sam@example.com" || got.Name != "Sam Smith" || got.CheckInToken != "check-in-token-123" {
+				t.Fatalf("expected the full registration, got %+v", got)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/gin-gonic/gin"
 )
 
@@ -37,7 +40,26 @@ func requestIDFrom(ctx *gin.Context) string {
 	return ctx.GetHeader("X-Request-Id")
 }
 
+// StatusClientClosedRequest is the (nginx) status a failure is recorded with
+// when the client disconnected first, so it stays out of 5xx counts.
+const StatusClientClosedRequest = 499
+
 func RespondError(ctx *gin.Context, status int, code, message string, details interface{}) {
+	if status >= http.StatusInternalServerError {
+		if clientDisconnected(ctx) {
+			status = StatusClientClosedRequest
+			ctx.Set(middlewares.CtxClientDisconnected, true)
+			slog.Default().InfoContext(ctx.Request.Context(), "http.client_disconnected",
+				"route", ctx.FullPath(),
+				"code", code,
+				"request_id", requestIDFrom(ctx),
+				"client_disconnected", true,
+			)
+		} else {
+			recordServerError(ctx, status, code, message)
+		}
+	}
+
 	ctx.JSON(status, gin.H{
 		"error": APIError{
 			Code:      code,
@@ -48,6 +70,36 @@ func RespondError(ctx *gin.Context, status int, code, message string, details in
 	})
 }
 
+// clientDisconnected reports whether the request's context has ended, which
+// only happens when the client goes away: a handler failing after that failed
+// because of it, whatever error it got.
+func clientDisconnected(ctx *gin.Context) bool {
+	rctx := ctx.Request.Context()
+	return observability.ClassifyErr(rctx, rctx.Err()) == observability.ErrClassCancelled
+}
+
+// recordServerError keeps the 5xx for GET /admin/debug/recent-errors, with
+// whatever errors the handler attached via ctx.Error.
+func recordServerError(ctx *gin.Context, status int, code, message string) {
+	route := ctx.FullPath()
+	if route == "" {
+		route = ctx.Request.URL.Path
+	}
+
+	text := message
+	if len(ctx.Errors) > 0 {
+		text += ": " + strings.Join(ctx.Errors.Errors(), "; ")
+	}
+
+	observability.RecentHTTPErrors.Add(observability.ErrorEvent{
+		Route:     ctx.Request.Method + " " + route,
+		Status:    status,
+		Code:      code,
+		RequestID: requestIDFrom(ctx),
+		Error:     text,
+	})
+}
+
 func RespondBadRequest(ctx *gin.Context, message string, details interface{}) {
 	RespondError(ctx, http.StatusBadRequest, "invalid_request", message, details)
 }
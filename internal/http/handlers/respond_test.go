@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/geocoder89/eventhub/internal/http/middlewares"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+)
+
+// recordedFor reports whether the recent-errors ring has an entry for the
+// request id.
+func recordedFor(requestID string) bool {
+	for _, e := range observability.RecentHTTPErrors.Snapshot() {
+		if e.RequestID == requestID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRespondError_ClientDisconnectIsNotAServerError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	r := gin.New()
+	r.Use(middlewares.RequestLogger())
+	r.GET("/slow", func(ctx *gin.Context) {
+		// the query fails because the client went away mid-request
+		_ = ctx.Error(context.Canceled)
+		handlers.RespondInternal(ctx, "Could not load event")
+	})
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(reqCtx)
+	req.Header.Set("X-Request-Id", "req-disconnected")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != handlers.StatusClientClosedRequest {
+		t.Fatalf("expected %d, got %d", handlers.StatusClientClosedRequest, w.Code)
+	}
+	if recordedFor("req-disconnected") {
+		t.Fatal("a client disconnect was kept as a recent server error")
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "msg=http.client_disconnected") || !strings.Contains(out, "status=499") {
+		t.Fatalf("expected the disconnect logged and the request logged as 499, got:\n%s", out)
+	}
+	if strings.Count(out, "client_disconnected=true") != 2 {
+		t.Fatalf("expected both log lines to carry client_disconnected=true, got:\n%s", out)
+	}
+}
+
+func TestRespondError_OwnTimeoutIsAServerError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/slow", func(ctx *gin.Context) {
+		// the handler's own budget runs out while the client is still there
+		cctx, cancel := context.WithTimeout(ctx.Request.Context(), time.Millisecond)
+		defer cancel()
+		<-cctx.Done()
+		_ = ctx.Error(cctx.Err())
+		handlers.RespondInternal(ctx, "Could not load event")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	req.Header.Set("X-Request-Id", "req-timeout")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	if !recordedFor("req-timeout") {
+		t.Fatal("expected the timeout kept as a recent server error")
+	}
+	if err := req.Context().Err(); err != nil {
+		t.Fatalf("the request context should still be live, got %v", err)
+	}
+}
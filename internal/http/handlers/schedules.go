@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/schedule"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SchedulesRepository interface {
+	Create(ctx context.Context, s schedule.Schedule) (schedule.Schedule, error)
+	List(ctx context.Context) ([]schedule.Schedule, error)
+	GetByID(ctx context.Context, id string) (schedule.Schedule, error)
+	Update(ctx context.Context, id string, req schedule.UpdateRequest) (schedule.Schedule, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type SchedulesHandler struct {
+	repo SchedulesRepository
+}
+
+func NewSchedulesHandler(repo SchedulesRepository) *SchedulesHandler {
+	return &SchedulesHandler{repo: repo}
+}
+
+// Create handles POST /admin/schedules. The first job is enqueued at the
+// expression's next match.
+func (h *SchedulesHandler) Create(ctx *gin.Context) {
+	var req schedule.CreateRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	s, err := schedule.NewFromCreateRequest(req, time.Now())
+	if err != nil {
+		RespondBadRequest(ctx, "invalid_cron", err.Error())
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	created, err := h.repo.Create(cctx, s)
+	if err != nil {
+		if respondPayloadTooLarge(ctx, err) {
+			return
+		}
+		RespondInternal(ctx, "Could not create schedule")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// List handles GET /admin/schedules.
+func (h *SchedulesHandler) List(ctx *gin.Context) {
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, err := h.repo.List(cctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not list schedules")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Get handles GET /admin/schedules/:id.
+func (h *SchedulesHandler) Get(ctx *gin.Context) {
+	id, ok := scheduleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	s, err := h.repo.GetByID(cctx, id)
+	if err != nil {
+		respondScheduleError(ctx, err, "Could not fetch schedule")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s)
+}
+
+// Update handles PUT /admin/schedules/:id. Disabling takes effect at once:
+// the scheduler only enqueues schedules that are enabled when it runs.
+func (h *SchedulesHandler) Update(ctx *gin.Context) {
+	id, ok := scheduleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req schedule.UpdateRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	s, err := h.repo.Update(cctx, id, req)
+	if err != nil {
+		if respondPayloadTooLarge(ctx, err) {
+			return
+		}
+		respondScheduleError(ctx, err, "Could not update schedule")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s)
+}
+
+// Delete handles DELETE /admin/schedules/:id.
+func (h *SchedulesHandler) Delete(ctx *gin.Context) {
+	id, ok := scheduleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if err := h.repo.Delete(cctx, id); err != nil {
+		respondScheduleError(ctx, err, "Could not delete schedule")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func scheduleIDParam(ctx *gin.Context) (string, bool) {
+	id := ctx.Param("id")
+	if !utils.IsUUID(id) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+func respondScheduleError(ctx *gin.Context, err error, msg string) {
+	if errors.Is(err, schedule.ErrNotFound) {
+		RespondNotFound(ctx, "Schedule not found")
+		return
+	}
+	RespondInternal(ctx, msg)
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/schedule"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeSchedulesRepo struct {
+	created []schedule.Schedule
+	limits  job.PayloadLimits
+}
+
+func (f *fakeSchedulesRepo) Create(ctx context.Context, s schedule.Schedule) (schedule.Schedule, error) {
+	if err := f.limits.Check(s.Payload); err != nil {
+		return schedule.Schedule{}, err
+	}
+	f.created = append(f.created, s)
+	return s, nil
+}
+
+func (f *fakeSchedulesRepo) List(ctx context.Context) ([]schedule.Schedule, error) {
+	return f.created, nil
+}
+
+func (f *fakeSchedulesRepo) GetByID(ctx context.Context, id string) (schedule.Schedule, error) {
+	return schedule.Schedule{}, schedule.ErrNotFound
+}
+
+func (f *fakeSchedulesRepo) Update(ctx context.Context, id string, req schedule.UpdateRequest) (schedule.Schedule, error) {
+	return schedule.Schedule{}, schedule.ErrNotFound
+}
+
+func (f *fakeSchedulesRepo) Delete(ctx context.Context, id string) error {
+	return schedule.ErrNotFound
+}
+
+func TestSchedules_CreateStartsAtNextCronMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeSchedulesRepo{limits: job.DefaultPayloadLimits}
+	r := gin.New()
+	r.POST("/admin/schedules", handlers.NewSchedulesHandler(repo).Create)
+
+	before := time.Now().UTC()
+	w := postJSON(r, "/admin/schedules", `{"type":"events.archive","cron":"*/15 * * * *"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var got schedule.Schedule
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.Enabled || string(got.Payload) != `{}` {
+		t.Fatalf("expected an enabled schedule with an empty payload, got %s", w.Body.String())
+	}
+	if !got.NextRunAt.After(before) || got.NextRunAt.Sub(before) > 15*time.Minute || got.NextRunAt.Minute()%15 != 0 || got.NextRunAt.Second() != 0 {
+		t.Fatalf("expected the next quarter hour after %s, got %s", before, got.NextRunAt)
+	}
+}
+
+func TestSchedules_CreateRejectsBadCronAndOversizedPayload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeSchedulesRepo{limits: job.PayloadLimits{MaxBytes: 32}}
+	r := gin.New()
+	r.POST("/admin/schedules", handlers.NewSchedulesHandler(repo).Create)
+
+	for _, expr := range []string{"61 * * * *", "* * *", "0 0 30 2 *"} {
+		w := postJSON(r, "/admin/schedules", `{"type":"events.archive","cron":"`+expr+`"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d body=%s", expr, w.Code, w.Body.String())
+		}
+		var resp bindErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if fields := resp.Error.Details.Fields; len(fields) != 1 || fields[0].Field != "cron" || fields[0].Rule != "cron" {
+			t.Fatalf("%q: expected a cron field error, got %s", expr, w.Body.String())
+		}
+	}
+
+	w := postJSON(r, "/admin/schedules", `{"type":"events.archive","cron":"@daily","payload":{"note":"this payload is over the limit"}}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d body=%s", w.Code, w.Body.String())
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("nothing should have been stored, got %+v", repo.created)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/mail"
+
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/gin-gonic/gin"
+)
+
+type SuppressionsRepository interface {
+	ListQuarantined(ctx context.Context) ([]notificationsdelivery.Quarantine, error)
+	Lift(ctx context.Context, email string) error
+}
+
+// SuppressionsHandler shows the recipients deliveries are skipped for and
+// lifts them. Workers cache a recipient's status for up to 30 seconds, so a
+// lift reaches them within that; deliveries skipped meanwhile are sent by
+// the next job for them.
+type SuppressionsHandler struct {
+	repo SuppressionsRepository
+}
+
+func NewSuppressionsHandler(repo SuppressionsRepository) *SuppressionsHandler {
+	return &SuppressionsHandler{repo: repo}
+}
+
+// GET /admin/suppressions
+func (h *SuppressionsHandler) List(ctx *gin.Context) {
+	if h.repo == nil {
+		RespondError(ctx, http.StatusServiceUnavailable, "suppressions_unavailable", "Suppressions are not enabled", nil)
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, err := h.repo.ListQuarantined(cctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not list suppressions")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": items, "count": len(items)})
+}
+
+// DELETE /admin/suppressions/:email
+//
+// Lifting also forgets the recipient's failures, so it takes a full
+// threshold of new ones to quarantine it again.
+func (h *SuppressionsHandler) Lift(ctx *gin.Context) {
+	if h.repo == nil {
+		RespondError(ctx, http.StatusServiceUnavailable, "suppressions_unavailable", "Suppressions are not enabled", nil)
+		return
+	}
+
+	email := notificationsdelivery.NormalizeRecipient(ctx.Param("email"))
+	if _, err := mail.ParseAddress(email); err != nil {
+		RespondBadRequest(ctx, "invalid_email", "email must be an email address")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if err := h.repo.Lift(cctx, email); err != nil {
+		if errors.Is(err, notificationsdelivery.ErrQuarantineNotFound) {
+			RespondNotFound(ctx, "Recipient is not suppressed")
+			return
+		}
+		RespondInternal(ctx, "Could not lift suppression")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
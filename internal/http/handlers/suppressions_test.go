@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeSuppressionsRepo struct {
+	items map[string]notificationsdelivery.Quarantine
+}
+
+func (f *fakeSuppressionsRepo) ListQuarantined(ctx context.Context) ([]notificationsdelivery.Quarantine, error) {
+	out := []notificationsdelivery.Quarantine{}
+	for _, q := range f.items {
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+func (f *fakeSuppressionsRepo) Lift(ctx context.Context, email string) error {
+	if _, ok := f.items[email]; !ok {
+		return notificationsdelivery.ErrQuarantineNotFound
+	}
+	delete(f.items, email)
+	return nil
+}
+
+func TestSuppressions_ListAndLift(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeSuppressionsRepo{items: map[string]notificationsdelivery.Quarantine{
+		"bounce@example.com": {Email: "bounce@example.com", Failures: 3, QuarantinedAt: time.Now()},
+	}}
+	h := handlers.NewSuppressionsHandler(repo)
+	r := gin.New()
+	r.Use(withUser(newUUID(), user.RoleAdmin))
+	r.GET("/admin/suppressions", h.List)
+	r.DELETE("/admin/suppressions/:email", h.Lift)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := do(http.MethodGet, "/admin/suppressions")
+	var list struct {
+		Items []notificationsdelivery.Quarantine `json:"items"`
+		Count int                                `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK || list.Count != 1 || list.Items[0].Email != "bounce@example.com" {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+
+	// addresses are matched case-insensitively
+	if w := do(http.MethodDelete, "/admin/suppressions/"+url.PathEscape("Bounce@Example.com")); w.Code != http.StatusNoContent {
+		t.Fatalf("lift: expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/admin/suppressions/bounce@example.com"); w.Code != http.StatusNotFound {
+		t.Fatalf("lift again: expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/admin/suppressions/not-an-email"); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad email: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSuppressions_UnavailableWithoutRepo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/admin/suppressions", handlers.NewSuppressionsHandler(nil).List)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/suppressions", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+}
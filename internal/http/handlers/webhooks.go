@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhooksRepository interface {
+	Create(ctx context.Context, w webhook.Webhook) (webhook.Webhook, error)
+	List(ctx context.Context) ([]webhook.Webhook, error)
+	GetByID(ctx context.Context, id string) (webhook.Webhook, error)
+	Update(ctx context.Context, id string, req webhook.UpdateRequest) (webhook.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]webhook.Delivery, error)
+}
+
+type WebhooksHandler struct {
+	repo WebhooksRepository
+}
+
+func NewWebhooksHandler(repo WebhooksRepository) *WebhooksHandler {
+	return &WebhooksHandler{repo: repo}
+}
+
+type createWebhookResponse struct {
+	// Secret signs every delivery; it is only ever returned here.
+	Secret  string          `json:"secret"`
+	Webhook webhook.Webhook `json:"webhook"`
+}
+
+// Create handles POST /admin/webhooks.
+func (h *WebhooksHandler) Create(ctx *gin.Context) {
+	var req webhook.CreateRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	w, err := webhook.NewFromCreateRequest(req)
+	if err != nil {
+		RespondInternal(ctx, "Could not generate webhook secret")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	created, err := h.repo.Create(cctx, w)
+	if err != nil {
+		RespondInternal(ctx, "Could not create webhook")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createWebhookResponse{Secret: w.Secret, Webhook: created})
+}
+
+// List handles GET /admin/webhooks.
+func (h *WebhooksHandler) List(ctx *gin.Context) {
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	items, err := h.repo.List(cctx)
+	if err != nil {
+		RespondInternal(ctx, "Could not list webhooks")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Get handles GET /admin/webhooks/:id.
+func (h *WebhooksHandler) Get(ctx *gin.Context) {
+	id, ok := webhookIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	w, err := h.repo.GetByID(cctx, id)
+	if err != nil {
+		respondWebhookError(ctx, err, "Could not fetch webhook")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, w)
+}
+
+// Update handles PUT /admin/webhooks/:id. The secret is rotated only when a
+// new one is sent.
+func (h *WebhooksHandler) Update(ctx *gin.Context) {
+	id, ok := webhookIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req webhook.UpdateRequest
+	if !BindJSON(ctx, &req) {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	w, err := h.repo.Update(cctx, id, req)
+	if err != nil {
+		respondWebhookError(ctx, err, "Could not update webhook")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, w)
+}
+
+// Delete handles DELETE /admin/webhooks/:id.
+func (h *WebhooksHandler) Delete(ctx *gin.Context) {
+	id, ok := webhookIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if err := h.repo.Delete(cctx, id); err != nil {
+		respondWebhookError(ctx, err, "Could not delete webhook")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Deliveries handles GET /admin/webhooks/:id/deliveries: the most recent
+// delivery attempts, newest first, limit 1–200 (default 50).
+func (h *WebhooksHandler) Deliveries(ctx *gin.Context) {
+	id, ok := webhookIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	limit := parseIntDefault(ctx.Query("limit"), 50)
+	if limit < 1 || limit > 200 {
+		RespondBadRequest(ctx, "invalid_query", "limit must be between 1 and 200")
+		return
+	}
+
+	cctx, cancel := DBTimeout(ctx)
+	defer cancel()
+
+	if _, err := h.repo.GetByID(cctx, id); err != nil {
+		respondWebhookError(ctx, err, "Could not fetch webhook")
+		return
+	}
+
+	items, err := h.repo.ListDeliveries(cctx, id, limit)
+	if err != nil {
+		RespondInternal(ctx, "Could not list webhook deliveries")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func webhookIDParam(ctx *gin.Context) (string, bool) {
+	id := ctx.Param("id")
+	if !utils.IsUUID(id) {
+		RespondBadRequest(ctx, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+func respondWebhookError(ctx *gin.Context, err error, msg string) {
+	if errors.Is(err, webhook.ErrNotFound) {
+		RespondNotFound(ctx, "Webhook not found")
+		return
+	}
+	RespondInternal(ctx, msg)
+}
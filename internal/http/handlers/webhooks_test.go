@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/http/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeWebhooksRepo struct {
+	created []webhook.Webhook
+}
+
+func (f *fakeWebhooksRepo) Create(ctx context.Context, w webhook.Webhook) (webhook.Webhook, error) {
+	f.created = append(f.created, w)
+	return w, nil
+}
+
+func (f *fakeWebhooksRepo) List(ctx context.Context) ([]webhook.Webhook, error) {
+	return f.created, nil
+}
+
+func (f *fakeWebhooksRepo) GetByID(ctx context.Context, id string) (webhook.Webhook, error) {
+	for _, w := range f.created {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return webhook.Webhook{}, webhook.ErrNotFound
+}
+
+func (f *fakeWebhooksRepo) Update(ctx context.Context, id string, req webhook.UpdateRequest) (webhook.Webhook, error) {
+	return webhook.Webhook{}, webhook.ErrNotFound
+}
+
+func (f *fakeWebhooksRepo) Delete(ctx context.Context, id string) error {
+	return webhook.ErrNotFound
+}
+
+func (f *fakeWebhooksRepo) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]webhook.Delivery, error) {
+	return []webhook.Delivery{}, nil
+}
+
+func TestWebhooks_CreateReturnsSecretOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeWebhooksRepo{}
+	h := handlers.NewWebhooksHandler(repo)
+	r := gin.New()
+	r.POST("/admin/webhooks", h.Create)
+	r.GET("/admin/webhooks/:id", h.Get)
+	r.GET("/admin/webhooks/:id/deliveries", h.Deliveries)
+
+	w := postJSON(r, "/admin/webhooks", `{"url":"https://crm.example.com/hooks","eventKinds":["registration.cancelled","registration.created","registration.created"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body=%s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Secret  string          `json:"secret"`
+		Webhook webhook.Webhook `json:"webhook"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.HasPrefix(created.Secret, "whsec_") || !created.Webhook.Enabled {
+		t.Fatalf("unexpected create response: %s", w.Body.String())
+	}
+	if got := created.Webhook.EventKinds; len(got) != 2 || got[0] != webhook.KindRegistrationCreated {
+		t.Fatalf("expected kinds deduplicated in canonical order, got %v", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/webhooks/"+created.Webhook.ID, nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), created.Secret) {
+		t.Fatalf("expected the webhook without its secret, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/webhooks/"+newUUID()+"/deliveries", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for deliveries of an unknown webhook, got %d", w.Code)
+	}
+}
+
+func TestWebhooks_CreateRejectsUnknownKind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeWebhooksRepo{}
+	r := gin.New()
+	r.POST("/admin/webhooks", handlers.NewWebhooksHandler(repo).Create)
+
+	w := postJSON(r, "/admin/webhooks", `{"url":"https://crm.example.com/hooks","eventKinds":["event.deleted"]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d body=%s", w.Code, w.Body.String())
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("nothing should be stored")
+	}
+}
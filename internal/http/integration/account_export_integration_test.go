@@ -0,0 +1,172 @@
+package integration__test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/exportlink"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+// exportMailbox records account export emails on top of confirmations.
+type exportMailbox struct {
+	recordingNotifier
+	mu      sync.Mutex
+	exports []notifications.SendAccountExportReadyInput
+}
+
+func (m *exportMailbox) SendAccountExportReady(ctx context.Context, input notifications.SendAccountExportReadyInput) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exports = append(m.exports, input)
+	return nil
+}
+
+func TestPipeline_AccountExport_ZipForOrganizer(t *testing.T) {
+	router, pool, cfg := setupPipelineRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	token := signupAndGetToken(t, router, "organizer@example.com")
+	var organizerID string
+	if err := pool.QueryRow(context.Background(), `SELECT id FROM users WHERE email = $1`, "organizer@example.com").Scan(&organizerID); err != nil {
+		t.Fatalf("select organizer: %v", err)
+	}
+
+	eventIDs := []string{seedEvent(t, pool, 10), seedEvent(t, pool, 10)}
+	otherEventID := seedEvent(t, pool, 10)
+	if _, err := pool.Exec(context.Background(), `UPDATE events SET organizer_id = $1 WHERE id = ANY($2)`, organizerID, eventIDs); err != nil {
+		t.Fatalf("assign organizer: %v", err)
+	}
+
+	attendeeID := uuid.NewString()
+	seedUserForExport(t, pool, attendeeID, "attendee@example.com", "Attendee")
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, eventID := range append(eventIDs, otherEventID) {
+		seedRegistrationForExport(t, pool, uuid.NewString(), eventID, attendeeID,
+			"Attendee", "attendee@example.com", "chk_account_"+uuid.NewString(), now.Add(time.Duration(i)*time.Second))
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/me/export", "", token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("request export got %d body=%s", w.Code, w.Body.String())
+	}
+	var enqueued struct {
+		JobID string `json:"jobId"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &enqueued); err != nil {
+		t.Fatalf("decode enqueue: %v", err)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/me/export", "", token)
+	if w.Code != http.StatusAccepted || !bytes.Contains(w.Body.Bytes(), []byte(`"alreadyEnqueued":true`)) {
+		t.Fatalf("second request the same day should return the first job, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	store, err := exportstore.NewDirStore(cfg.ExportsDir)
+	if err != nil {
+		t.Fatalf("export store: %v", err)
+	}
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+	eventsRepo := postgres.NewEventsRepo(pool, nil)
+	mailbox := &exportMailbox{}
+
+	wk := worker.New(worker.Config{
+		PollInterval:  10 * time.Millisecond,
+		WorkerID:      "test-worker-account-export",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+	}, jobsRepo, eventsRepo, mailbox, postgres.NewNotificationsDeliveriesRepo(pool)).
+		WithAccountExporter(worker.AccountExportSources{
+			Users:         postgres.NewUsersRepo(pool),
+			Events:        eventsRepo,
+			Registrations: postgres.NewRegistrationsRepo(pool, nil),
+			Jobs:          jobsRepo,
+		}, store, exportlink.NewSigner(cfg.ExportLinkSigningSecret(), cfg.ExportLinkTTL()))
+
+	processed, err := wk.ProcessOne(context.Background())
+	if err != nil || !processed {
+		t.Fatalf("ProcessOne processed=%t err=%v", processed, err)
+	}
+
+	if len(mailbox.exports) != 1 {
+		t.Fatalf("expected one export email, got %d", len(mailbox.exports))
+	}
+	mail := mailbox.exports[0]
+	if mail.Email != "organizer@example.com" || mail.JobID != enqueued.JobID || mail.DownloadToken == "" {
+		t.Fatalf("unexpected export email: %+v", mail)
+	}
+
+	w = doAnonymousJSONRequest(router, http.MethodGet, "/me/export/download?token="+mail.DownloadToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("download got %d body=%s", w.Code, w.Body.String())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+
+	files := map[string][]byte{}
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if got := strings.Join(names, ","); got != "events.json,jobs.json,profile.json,registrations.json" {
+		t.Fatalf("unexpected zip entries %s", got)
+	}
+
+	var profile struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(files["profile.json"], &profile); err != nil || profile.ID != organizerID {
+		t.Fatalf("unexpected profile.json err=%v body=%s", err, files["profile.json"])
+	}
+
+	var events, regs, jobsList []struct {
+		ID      string `json:"id"`
+		EventID string `json:"eventId"`
+		Type    string `json:"type"`
+	}
+	for name, out := range map[string]any{"events.json": &events, "registrations.json": &regs, "jobs.json": &jobsList} {
+		if err := json.Unmarshal(files[name], out); err != nil {
+			t.Fatalf("decode %s: %v", name, err)
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected the organizer's 2 events, got %d", len(events))
+	}
+	if len(regs) != 2 {
+		t.Fatalf("expected registrations for the organizer's events only, got %d", len(regs))
+	}
+	for _, r := range regs {
+		if r.EventID == otherEventID {
+			t.Fatalf("registration for another organizer's event leaked into the export")
+		}
+	}
+	if len(jobsList) != 1 || jobsList[0].ID != enqueued.JobID {
+		t.Fatalf("expected the export job itself in jobs.json, got %+v", jobsList)
+	}
+}
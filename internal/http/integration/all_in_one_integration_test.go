@@ -0,0 +1,122 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestAllInOne_RegisterThenEmbeddedWorkerDelivers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dsn := requiredTestDBDSN(t)
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("pg pool: %v", err)
+	}
+	defer pool.Close()
+
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	cfg := config.Config{
+		Env:                 "test",
+		DBURL:               dsn,
+		JWTSecret:           "test-secret-key",
+		JWTAccessTTLMinutes: 60,
+		JWTRefreshTTLDays:   7,
+		RedisAddr:           "127.0.0.1:6379",
+	}
+
+	reg := prometheus.NewRegistry()
+	prom := observability.NewProm(reg)
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	router, stopRouter := apphttp.NewRouterWithMetrics(logger, pool, cfg, reg, prom)
+	t.Cleanup(stopRouter)
+
+	rec := &recordingNotifier{}
+	wk := worker.New(worker.Config{
+		PollInterval:  20 * time.Millisecond,
+		WorkerID:      "all-in-one-test",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+		LockTTL:       30 * time.Second,
+	}, postgres.NewJobsRepo(pool, prom), postgres.NewEventsRepo(pool, prom), rec, postgres.NewNotificationsDeliveriesRepo(pool))
+	wk.Register(jobs.TypeRegistrationConfirmation, wk.HandleRegistrationConfirmation)
+	wk.PromRegistry = reg
+	wk.MountHealth(router, "/worker", reg)
+
+	eventID := seedEvent(t, pool, 10)
+	userEmail := "all-in-one@example.com"
+	token := signupAndGetToken(t, router, userEmail)
+
+	req := httptest.NewRequest(http.MethodPost, "/events/"+eventID+"/register", bytes.NewBufferString(`{"name":"All In One","email":"`+userEmail+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/worker/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected merged /worker/readyz=200, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = wk.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var status string
+	for time.Now().Before(deadline) {
+		err := pool.QueryRow(context.Background(), `
+			SELECT status FROM notification_deliveries
+			WHERE kind = 'registration.confirmation'
+		`).Scan(&status)
+		if err == nil && status == "sent" {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("embedded worker did not stop after cancel")
+	}
+
+	if status != "sent" {
+		t.Fatalf("expected confirmation delivery to be sent, got %q", status)
+	}
+	if rec.Count() != 1 {
+		t.Fatalf("expected notifier called once, got %d", rec.Count())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/worker/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected /worker/readyz=503 after worker stop, got %d", w.Code)
+	}
+}
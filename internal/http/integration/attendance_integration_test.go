@@ -0,0 +1,118 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestAttendance_FinalizeMarksNoShowsAndSkipsUnusedCheckIn(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	adminToken := createAdminAuthToken(t, router, pool, "organizer-attendance@example.com")
+
+	tracked := seedEvent(t, pool, 10)
+	untracked := seedEvent(t, pool, 10)
+	for i, eventID := range []string{tracked, untracked} {
+		for j := 1; j <= 3; j++ {
+			registerFrom(t, router, eventID, i*10+j)
+		}
+	}
+
+	// the event happened; two of three attendees at the tracked one checked in
+	_, err := pool.Exec(ctx, `
+		UPDATE events
+		SET start_at = NOW() - INTERVAL '1 day',
+		    organizer_id = (SELECT id FROM users WHERE email = 'organizer-attendance@example.com')
+		WHERE id = ANY($1)
+	`, []string{tracked, untracked})
+	if err != nil {
+		t.Fatalf("move events: %v", err)
+	}
+	_, err = pool.Exec(ctx, `
+		UPDATE registrations SET checked_in_at = NOW()
+		WHERE event_id = $1 AND email IN ('guest1@example.com', 'guest2@example.com')
+	`, tracked)
+	if err != nil {
+		t.Fatalf("check in: %v", err)
+	}
+
+	repo := postgres.NewAttendanceRepo(pool, nil)
+
+	stats, err := repo.FinalizeEvent(ctx, tracked)
+	if err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if stats.Registrations != 3 || stats.CheckedIn != 2 || stats.NoShows != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	var noShowStatus string
+	var registeredCount int
+	err = pool.QueryRow(ctx, `
+		SELECT r.status, e.registered_count
+		FROM registrations r JOIN events e ON e.id = r.event_id
+		WHERE r.event_id = $1 AND r.email = 'guest3@example.com'
+	`, tracked).Scan(&noShowStatus, &registeredCount)
+	if err != nil {
+		t.Fatalf("read no-show: %v", err)
+	}
+	if noShowStatus != "no_show" || registeredCount != 2 {
+		t.Fatalf("expected no_show and 2 seats counted, got status=%s registered_count=%d", noShowStatus, registeredCount)
+	}
+
+	// running again changes nothing
+	again, err := repo.FinalizeEvent(ctx, tracked)
+	if err != nil || again.NoShows != 1 || !again.FinalizedAt.Equal(stats.FinalizedAt) {
+		t.Fatalf("expected the recorded stats back, got %+v err=%v", again, err)
+	}
+
+	// nobody checked in: leave everyone confirmed
+	if _, err := repo.FinalizeEvent(ctx, untracked); !errors.Is(err, event.ErrCheckInNotUsed) {
+		t.Fatalf("expected ErrCheckInNotUsed, got %v", err)
+	}
+	var confirmed int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM registrations WHERE event_id = $1 AND status = 'confirmed'`, untracked).Scan(&confirmed); err != nil {
+		t.Fatalf("count confirmed: %v", err)
+	}
+	if confirmed != 3 {
+		t.Fatalf("expected the untracked event untouched, got %d confirmed", confirmed)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/events/"+tracked+"/funnel", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("funnel got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var report struct {
+		Attendance *event.AttendanceStats `json:"attendance"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode funnel: %v", err)
+	}
+	if report.Attendance == nil || report.Attendance.NoShows != 1 {
+		t.Fatalf("expected attendance in the event stats, got %s", w.Body.String())
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/me/attendance-stats", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollup got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var rollup event.AttendanceRollup
+	if err := json.Unmarshal(w.Body.Bytes(), &rollup); err != nil {
+		t.Fatalf("decode rollup: %v", err)
+	}
+	if rollup.Events != 1 || rollup.Registrations != 3 || rollup.NoShows != 1 || len(rollup.Recent) != 1 {
+		t.Fatalf("unexpected rollup: %+v", rollup)
+	}
+	if rollup.NoShowRate < 0.33 || rollup.NoShowRate > 0.34 {
+		t.Fatalf("expected a no-show rate of 1/3, got %v", rollup.NoShowRate)
+	}
+}
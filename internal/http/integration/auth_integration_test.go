@@ -10,11 +10,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/geocoder89/eventhub/internal/config"
 	apphttp "github.com/geocoder89/eventhub/internal/http"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func testConfigAuth() config.Config {
@@ -49,7 +51,8 @@ func setupAuthTestRouter(t *testing.T) (*gin.Engine, *pgxpool.Pool) {
 
 	cfg := testConfigAuth()
 
-	router := apphttp.NewRouter(logger, pool, cfg)
+	router, stopRouter := apphttp.NewRouter(logger, pool, cfg)
+	t.Cleanup(stopRouter)
 
 	return router, pool
 }
@@ -228,3 +231,94 @@ func TestAuthIntegration_Login_InvalidCredentials(t *testing.T) {
 		t.Fatalf("login(invalid creds) got status %d, want %d, body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
 	}
 }
+
+func TestAuthIntegration_Login_RehashesLegacyBcrypt(t *testing.T) {
+	router, pool := setupAuthTestRouter(t)
+	resetAuthDB(t, pool)
+	defer resetAuthDB(t, pool)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	if _, err := pool.Exec(context.Background(), `
+		INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at)
+		VALUES (gen_random_uuid(), 'legacy@example.com', $1, 'Legacy User', 'user', NOW(), NOW())
+	`, string(legacy)); err != nil {
+		t.Fatalf("seed legacy user: %v", err)
+	}
+
+	body := `{"email":"legacy@example.com","password":"password123"}`
+	w, _ := doRequest(router, http.MethodPost, "/login", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login got status %d, body=%s", w.Code, w.Body.String())
+	}
+
+	// the upgrade happens after the response, so poll for it
+	var hash string
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if err := pool.QueryRow(context.Background(), `SELECT password_hash FROM users WHERE email = 'legacy@example.com'`).Scan(&hash); err != nil {
+			t.Fatalf("select hash: %v", err)
+		}
+		if strings.HasPrefix(hash, "$argon2id$") || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		t.Fatalf("expected hash upgraded to argon2id, got %q", hash[:7])
+	}
+
+	// the new hash still logs in
+	w, _ = doRequest(router, http.MethodPost, "/login", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login after rehash got status %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthIntegration_MetricsCountLoginsRefreshesAndReuse(t *testing.T) {
+	router, pool := setupAuthTestRouter(t)
+	resetAuthDB(t, pool)
+
+	defer resetAuthDB(t, pool)
+
+	w, _ := doRequest(router, http.MethodPost, "/signup", `{"email":"metrics@example.com","password":"password123","name":"Sam Doe"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup got status %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w, response := doRequest(router, http.MethodPost, "/login", `{"email":"metrics@example.com","password":"password123"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login got status %d, body=%s", w.Code, w.Body.String())
+	}
+	loginRefresh := extraRefreshCookie(t, response)
+
+	if w, _ := doRequest(router, http.MethodPost, "/auth/refresh", "", loginRefresh); w.Code != http.StatusOK {
+		t.Fatalf("refresh got status %d, body=%s", w.Code, w.Body.String())
+	}
+	// the rotated-out token again
+	if w, _ := doRequest(router, http.MethodPost, "/auth/refresh", "", loginRefresh); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh got status %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w, _ = doRequest(router, http.MethodGet, "/metrics", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("metrics got status %d", w.Code)
+	}
+	scrape := w.Body.String()
+	for _, want := range []string{
+		`eventhub_auth_logins_total{result="success"} 1`,
+		`eventhub_auth_refreshes_total{result="success"} 1`,
+		`eventhub_auth_refreshes_total{result="reused"} 1`,
+		`eventhub_auth_token_reuse_total 1`,
+		`eventhub_auth_login_duration_seconds_count{result="success"} 1`,
+	} {
+		if !strings.Contains(scrape, want) {
+			t.Fatalf("metrics scrape missing %q", want)
+		}
+	}
+	if strings.Contains(scrape, "metrics@example.com") {
+		t.Fatalf("metrics must not carry user identifiers")
+	}
+}
@@ -0,0 +1,75 @@
+package integration__test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+)
+
+func TestAvailabilityStream_UpdateArrivesAfterRegistration(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := seedEvent(t, pool, 5)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/"+eventID+"/availability/stream", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("unexpected stream response status=%d content-type=%s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	nextAvailability := func() event.Availability {
+		t.Helper()
+		for sc.Scan() {
+			if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+				var a event.Availability
+				if err := json.Unmarshal([]byte(data), &a); err != nil {
+					t.Fatalf("decode %q: %v", data, err)
+				}
+				return a
+			}
+		}
+		t.Fatalf("stream ended: %v", sc.Err())
+		return event.Availability{}
+	}
+
+	if a := nextAvailability(); a.Remaining != 5 {
+		t.Fatalf("expected 5 seats at first, got %+v", a)
+	}
+
+	// the listener starts with the first subscriber; give LISTEN a moment
+	time.Sleep(200 * time.Millisecond)
+
+	w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Live","email":"live@example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	if a := nextAvailability(); a.Remaining != 4 || a.RegisteredCount != 1 {
+		t.Fatalf("expected one seat taken, got %+v", a)
+	}
+}
@@ -0,0 +1,148 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+func TestCapacityAlerts_ConcurrentRegistrationsEnqueueOneJobPerThreshold(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	// created through the API so the event has an organizer to alert
+	adminToken := createAdminAuthToken(t, router, pool, "admin-alerts@example.com")
+	body := `{
+		"title": "Sold Out Show",
+		"city": "Toronto",
+		"startAt": "` + time.Now().UTC().Add(48*time.Hour).Format(time.RFC3339) + `",
+		"capacity": 10
+	}`
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events", body, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	var created struct {
+		ID                      string `json:"id"`
+		OrganizerID             string `json:"organizerId"`
+		CapacityAlertThresholds []int  `json:"capacityAlertThresholds"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode created event: %v", err)
+	}
+	if fmt.Sprint(created.CapacityAlertThresholds) != "[90 100]" {
+		t.Fatalf("expected default thresholds, got %v", created.CapacityAlertThresholds)
+	}
+
+	// more sign-ups than seats, all at once, each from its own address so the
+	// per-IP register limiter stays out of the way
+	const attempts = 14
+	var wg sync.WaitGroup
+	codes := make([]int, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			reqBody := fmt.Sprintf(`{"name":"Fan %d","email":"fan%d@example.com"}`, i, i)
+			req := httptest.NewRequest(http.MethodPost, "/events/"+created.ID+"/register", bytes.NewBufferString(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.RemoteAddr = fmt.Sprintf("10.0.0.%d:4000", i+1)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			codes[i] = rec.Code
+		}(i)
+	}
+	wg.Wait()
+
+	confirmed := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			confirmed++
+		}
+	}
+	if confirmed < 10 {
+		t.Fatalf("expected the event to sell out, got %d confirmed (codes=%v)", confirmed, codes)
+	}
+
+	rows, err := pool.Query(context.Background(), `
+		SELECT idempotency_key, user_id::text, COUNT(*) OVER ()
+		FROM jobs
+		WHERE type = $1
+		ORDER BY idempotency_key
+	`, jobs.TypeOrganizerCapacityAlert)
+	if err != nil {
+		t.Fatalf("select alert jobs: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key, userID string
+		var total int
+		if err := rows.Scan(&key, &userID, &total); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if userID != created.OrganizerID {
+			t.Fatalf("alert job should belong to the organizer, got user_id=%s", userID)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+
+	want := []string{jobs.CapacityAlertKey(created.ID, 100), jobs.CapacityAlertKey(created.ID, 90)}
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Fatalf("expected exactly one alert per threshold %v, got %v", want, keys)
+	}
+}
+
+func TestCapacityAlerts_EmptyThresholdsDisableAlerts(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	adminToken := createAdminAuthToken(t, router, pool, "admin-noalerts@example.com")
+	body := `{
+		"title": "Quiet Show",
+		"city": "Toronto",
+		"startAt": "` + time.Now().UTC().Add(48*time.Hour).Format(time.RFC3339) + `",
+		"capacity": 1,
+		"capacityAlertThresholds": []
+	}`
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events", body, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode created event: %v", err)
+	}
+
+	w = doAnonymousJSONRequest(router, http.MethodPost, "/events/"+created.ID+"/register", `{"name":"Solo","email":"solo@example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	var n int
+	if err := pool.QueryRow(context.Background(), `SELECT COUNT(*) FROM jobs WHERE type = $1`, jobs.TypeOrganizerCapacityAlert).Scan(&n); err != nil {
+		t.Fatalf("count alert jobs: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no alerts when thresholds are empty, got %d", n)
+	}
+}
@@ -0,0 +1,158 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const tiedRows = 200
+
+// pageAllIDs follows nextCursor from path until hasMore is false and returns
+// every item id in the order served.
+func pageAllIDs(t *testing.T, router *gin.Engine, path, token string) []string {
+	t.Helper()
+
+	var ids []string
+	cursor := ""
+	for page := 0; page <= tiedRows; page++ {
+		target := path
+		if cursor != "" {
+			target += "&cursor=" + url.QueryEscape(cursor)
+		}
+
+		var rec *httptest.ResponseRecorder
+		if token == "" {
+			rec = doAnonymousJSONRequest(router, http.MethodGet, target, "")
+		} else {
+			rec = doAuthedJSONRequest(router, http.MethodGet, target, "", token)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+
+		var body struct {
+			Items []struct {
+				ID string `json:"id"`
+			} `json:"items"`
+			HasMore    bool    `json:"hasMore"`
+			NextCursor *string `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode page: %v", err)
+		}
+
+		for _, it := range body.Items {
+			ids = append(ids, it.ID)
+		}
+		if !body.HasMore {
+			return ids
+		}
+		if body.NextCursor == nil {
+			t.Fatalf("hasMore without nextCursor on page %d", page)
+		}
+		cursor = *body.NextCursor
+	}
+
+	t.Fatalf("paging did not terminate")
+	return nil
+}
+
+func assertEachOnce(t *testing.T, got []string, want map[string]bool) {
+	t.Helper()
+
+	seen := make(map[string]bool, len(got))
+	for _, id := range got {
+		if seen[id] {
+			t.Fatalf("id %s served twice", id)
+		}
+		if !want[id] {
+			t.Fatalf("unexpected id %s", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("served %d of %d rows", len(seen), len(want))
+	}
+}
+
+func seedTiedEvents(t *testing.T, pool *pgxpool.Pool, startAt time.Time) map[string]bool {
+	t.Helper()
+
+	want := make(map[string]bool, tiedRows)
+	for i := 0; i < tiedRows; i++ {
+		want[seedEventStartingAt(t, pool, 10, startAt)] = true
+	}
+	return want
+}
+
+func TestCursorTies_EventsSameStartAt(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	want := seedTiedEvents(t, pool, time.Now().UTC().Add(72*time.Hour).Truncate(time.Second))
+
+	got := pageAllIDs(t, router, "/events?limit=20", "")
+	assertEachOnce(t, got, want)
+}
+
+func TestCursorTies_RegistrationsSameCreatedAt(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := seedEvent(t, pool, tiedRows)
+	userID := uuid.NewString()
+	seedUserForExport(t, pool, userID, "bulk-owner@example.com", "Bulk Owner")
+
+	createdAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	want := make(map[string]bool, tiedRows)
+	for i := 0; i < tiedRows; i++ {
+		id := uuid.NewString()
+		seedRegistrationForExport(t, pool, id, eventID, userID, "Bulk", "bulk"+uuid.NewString()[:8]+"@example.com", uuid.NewString(), createdAt)
+		want[id] = true
+	}
+
+	token := signupAndGetToken(t, router, "pager@example.com")
+	got := pageAllIDs(t, router, "/events/"+eventID+"/registrations?limit=20", token)
+	assertEachOnce(t, got, want)
+}
+
+func TestCursorTies_JobsSameUpdatedAt(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `TRUNCATE jobs CASCADE`); err != nil {
+		t.Fatalf("truncate jobs: %v", err)
+	}
+	defer func() { _, _ = pool.Exec(ctx, `TRUNCATE jobs CASCADE`) }()
+
+	updatedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	want := make(map[string]bool, tiedRows)
+	for i := 0; i < tiedRows; i++ {
+		id := uuid.NewString()
+		_, err := pool.Exec(ctx, `
+			INSERT INTO jobs (id, type, payload, status, run_at, created_at, updated_at)
+			VALUES ($1, 'test.noop', '{}'::jsonb, 'done', $2, $2, $2)
+		`, id, updatedAt)
+		if err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		want[id] = true
+	}
+
+	token := createAdminAuthToken(t, router, pool, "jobs-pager@example.com")
+	got := pageAllIDs(t, router, "/admin/jobs?limit=20&status=done", token)
+	assertEachOnce(t, got, want)
+}
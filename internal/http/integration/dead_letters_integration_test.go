@@ -0,0 +1,144 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestDeadLetters_MarkFailedCopiesAndReplayEnqueuesOnce(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	adminToken := createAdminAuthToken(t, router, pool, "admin-dead-letters@example.com")
+
+	failed, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{"n":1}`), MaxAttempts: 3, Priority: 2})
+	if err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+	if err := repo.MarkFailed(ctx, failed.ID, "boom"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	// a second write for the same failure keeps one dead letter
+	if err := repo.MarkFailed(ctx, failed.ID, "boom again"); err != nil {
+		t.Fatalf("mark failed twice: %v", err)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/dead-letters?limit=10", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: got %d body=%s", w.Code, w.Body.String())
+	}
+	var page struct {
+		Items []job.DeadLetter `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].JobID != failed.ID || page.Items[0].Priority != 2 {
+		t.Fatalf("expected one dead letter for the failed job, got %+v", page.Items)
+	}
+	dl := page.Items[0]
+	if dl.FinalError == nil || *dl.FinalError != "boom" {
+		t.Fatalf("expected the first final error kept, got %v", dl.FinalError)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/dead-letters/"+dl.ID+"/replay", "", adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("replay: got %d body=%s", w.Code, w.Body.String())
+	}
+	var replay struct {
+		JobID string `json:"jobId"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &replay); err != nil {
+		t.Fatalf("decode replay: %v", err)
+	}
+
+	fresh, err := repo.GetByID(ctx, replay.JobID)
+	if err != nil {
+		t.Fatalf("get replayed job: %v", err)
+	}
+	if fresh.ID == failed.ID || fresh.Status != job.StatusPending || fresh.Attempts != 0 ||
+		fresh.MaxAttempts != 3 || string(fresh.Payload) != `{"n": 1}` {
+		t.Fatalf("unexpected replayed job %+v payload=%s", fresh, fresh.Payload)
+	}
+
+	// the work is queued once: no second replay, no in-place retry
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/dead-letters/"+dl.ID+"/replay", "", adminToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second replay: got %d body=%s", w.Code, w.Body.String())
+	}
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/jobs/"+failed.ID+"/retry", "", adminToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("retry after replay: got %d body=%s", w.Code, w.Body.String())
+	}
+	if n, err := repo.RetryManyFailed(ctx, 10); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to reprocess, got n=%d err=%v", n, err)
+	}
+}
+
+func TestDeadLetters_RetryInPlaceAndBulkReplay(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	adminToken := createAdminAuthToken(t, router, pool, "admin-dead-letters-bulk@example.com")
+
+	seedFailed := func() string {
+		t.Helper()
+		j, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`)})
+		if err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		if err := repo.MarkFailed(ctx, j.ID, "boom"); err != nil {
+			t.Fatalf("mark failed: %v", err)
+		}
+		return j.ID
+	}
+	retried := seedFailed()
+	seedFailed()
+	seedFailed()
+
+	// retrying in place consumes the job's dead letter
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/jobs/"+retried+"/retry", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("retry: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var open int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM dead_letters WHERE replayed_at IS NULL`).Scan(&open); err != nil {
+		t.Fatalf("count open dead letters: %v", err)
+	}
+	if open != 2 {
+		t.Fatalf("expected 2 unreplayed dead letters, got %d", open)
+	}
+
+	n, err := repo.RetryManyFailed(ctx, 10)
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 replays, got n=%d err=%v", n, err)
+	}
+
+	// one in-place retry plus two fresh jobs, each dead letter pointing at its job
+	var pending, dangling int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE status = 'pending'`).Scan(&pending); err != nil {
+		t.Fatalf("count pending: %v", err)
+	}
+	if err := pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM dead_letters d
+		WHERE d.replayed_at IS NULL
+		   OR NOT EXISTS (SELECT 1 FROM jobs j WHERE j.id = d.replayed_job_id AND j.status = 'pending')
+	`).Scan(&dangling); err != nil {
+		t.Fatalf("count dangling: %v", err)
+	}
+	if pending != 3 || dangling != 0 {
+		t.Fatalf("expected 3 pending jobs and every dead letter replayed, got pending=%d dangling=%d", pending, dangling)
+	}
+}
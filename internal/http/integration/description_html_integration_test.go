@@ -0,0 +1,73 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestEventsRepo_DescriptionHTMLStoredCompressed(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewEventsRepo(pool, nil)
+
+	created, err := repo.Create(ctx, event.CreateEventRequest{
+		Title:       "Compressed description",
+		Description: "Line one\nline <two>",
+		StartAt:     time.Now().UTC().Add(48 * time.Hour),
+		Capacity:    10,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var stored []byte
+	if err := pool.QueryRow(ctx, `SELECT description_html_gz FROM events WHERE id = $1`, created.ID).Scan(&stored); err != nil {
+		t.Fatalf("read column: %v", err)
+	}
+	if !bytes.HasPrefix(stored, []byte{0x1f, 0x8b}) {
+		t.Fatalf("expected gzip bytes stored, got %q", stored)
+	}
+
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	got, err = got.WithDescriptionHTML()
+	if err != nil || *got.DescriptionHTML != "<p>Line one<br>line &lt;two&gt;</p>" {
+		t.Fatalf("expected the stored HTML back, got %v err=%v", got.DescriptionHTML, err)
+	}
+
+	// an update re-renders it
+	updated, err := repo.Update(ctx, created.ID, event.UpdateEventRequest{
+		Title:       created.Title,
+		Description: "Rewritten",
+		StartAt:     created.StartAt,
+		Capacity:    created.Capacity,
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, _ = repo.GetByID(ctx, updated.ID)
+	if got, _ = got.WithDescriptionHTML(); *got.DescriptionHTML != "<p>Rewritten</p>" {
+		t.Fatalf("expected the re-rendered HTML, got %q", *got.DescriptionHTML)
+	}
+
+	zero := "00000000-0000-0000-0000-000000000000"
+	for _, include := range []bool{false, true} {
+		items, _, _, err := repo.ListCursor(ctx, event.ListEventsFilter{Limit: 10, IncludeDescriptionHTML: include}, time.Unix(0, 0).UTC(), zero)
+		if err != nil || len(items) != 1 {
+			t.Fatalf("list include=%v: %d items err=%v", include, len(items), err)
+		}
+		if (items[0].DescriptionHTMLGzip != nil) != include {
+			t.Fatalf("list include=%v read the compressed HTML: %v", include, items[0].DescriptionHTMLGzip != nil)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package integration__test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestEditLocks_AcquireRenewExpire(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewEditLocksRepo(pool, nil)
+
+	eventID := seedEvent(t, pool, 10)
+	alice, bob := uuid.NewString(), uuid.NewString()
+	seedUserForExport(t, pool, alice, "alice-lock@example.com", "Alice")
+	seedUserForExport(t, pool, bob, "bob-lock@example.com", "Bob")
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first, err := repo.Acquire(ctx, eventID, alice, now, event.EditLockTTL)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if first.HolderEmail != "alice-lock@example.com" || first.Token == "" {
+		t.Fatalf("unexpected lock %+v", first)
+	}
+
+	renewed, err := repo.Acquire(ctx, eventID, alice, now.Add(time.Minute), event.EditLockTTL)
+	if err != nil || renewed.Token != first.Token || !renewed.AcquiredAt.Equal(first.AcquiredAt) ||
+		!renewed.ExpiresAt.Equal(now.Add(time.Minute+event.EditLockTTL)) {
+		t.Fatalf("expected a renewal of the same lock, got %+v err=%v", renewed, err)
+	}
+
+	held, err := repo.Acquire(ctx, eventID, bob, now.Add(2*time.Minute), event.EditLockTTL)
+	if !errors.Is(err, event.ErrEditLockHeld) || held.HolderID != alice {
+		t.Fatalf("expected alice's lock held, got %+v err=%v", held, err)
+	}
+	if _, err := repo.Release(ctx, eventID, bob, false, now.Add(2*time.Minute)); !errors.Is(err, event.ErrEditLockHeld) {
+		t.Fatalf("expected bob's release refused, got %v", err)
+	}
+
+	// past the renewed expiry the lock is gone for readers and up for grabs
+	later := now.Add(time.Minute + event.EditLockTTL)
+	if _, err := repo.Get(ctx, eventID, later); !errors.Is(err, event.ErrEditLockNotFound) {
+		t.Fatalf("expected the expired lock ignored, got %v", err)
+	}
+	taken, err := repo.Acquire(ctx, eventID, bob, later, event.EditLockTTL)
+	if err != nil || taken.HolderID != bob || taken.Token == first.Token {
+		t.Fatalf("expected bob to take the lock over, got %+v err=%v", taken, err)
+	}
+
+	if _, err := repo.Release(ctx, eventID, alice, true, later); err != nil {
+		t.Fatalf("forced release: %v", err)
+	}
+	var n int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM edit_locks`).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("expected no locks left, got %d err=%v", n, err)
+	}
+
+	if _, err := repo.Acquire(ctx, uuid.NewString(), alice, later, event.EditLockTTL); !errors.Is(err, event.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown event, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+func TestEventBranding_ThreadedIntoConfirmationPayload(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	adminToken := createAdminAuthToken(t, router, pool, "organizer-branding@example.com")
+
+	branded := seedEvent(t, pool, 10)
+	plain := seedEvent(t, pool, 10)
+
+	w := doAuthedJSONRequest(router, http.MethodPut, "/events/"+branded+"/branding",
+		`{"replyTo":"hello@jazzclub.example","displayName":"Jazz Club","logoUrl":"https://cdn.example/logo.png"}`, adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("put branding got status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	registerFrom(t, router, branded, 1)
+	registerFrom(t, router, plain, 2)
+
+	payloadFor := func(email string) jobs.RegistrationConfirmationPayload {
+		t.Helper()
+		var raw []byte
+		err := pool.QueryRow(ctx, `
+			SELECT payload FROM jobs
+			WHERE type = $1 AND payload->>'email' = $2
+		`, jobs.TypeRegistrationConfirmation, email).Scan(&raw)
+		if err != nil {
+			t.Fatalf("load confirmation for %s: %v", email, err)
+		}
+		var p jobs.RegistrationConfirmationPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		return p
+	}
+
+	if p := payloadFor("guest1@example.com"); p.ReplyTo != "hello@jazzclub.example" || p.OrganizerName != "Jazz Club" || p.LogoURL != "https://cdn.example/logo.png" {
+		t.Fatalf("expected branding in the payload, got %+v", p)
+	}
+	if p := payloadFor("guest2@example.com"); p.ReplyTo != "" || p.OrganizerName != "" {
+		t.Fatalf("expected no branding for the plain event, got %+v", p)
+	}
+}
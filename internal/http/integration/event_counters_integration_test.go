@@ -0,0 +1,87 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestEventCountersIntegration_VerifyRepairsDrift(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+
+	drifted := seedEvent(t, pool, 10)
+	accurate := seedEvent(t, pool, 10)
+	underCounted := seedEvent(t, pool, 10)
+
+	for i, email := range []string{"a@example.com", "b@example.com"} {
+		token := signupAndGetToken(t, router, email)
+		for _, id := range []string{drifted, accurate, underCounted} {
+			w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+id+"/register", `{"name":"Counter User","email":"`+email+`"}`, token)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("register %d on %s got %d body=%s", i, id, w.Code, w.Body.String())
+			}
+		}
+	}
+
+	// the live path keeps the counter in step
+	var count int
+	if err := pool.QueryRow(ctx, `SELECT registered_count FROM events WHERE id = $1`, accurate).Scan(&count); err != nil {
+		t.Fatalf("select count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected registered_count=2 after two sign-ups, got %d", count)
+	}
+
+	// deliberate drift, as a bad manual fix would leave it
+	if _, err := pool.Exec(ctx, `UPDATE events SET registered_count = 9 WHERE id = $1`, drifted); err != nil {
+		t.Fatalf("seed drift: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE events SET registered_count = 0 WHERE id = $1`, underCounted); err != nil {
+		t.Fatalf("seed drift: %v", err)
+	}
+
+	repo := postgres.NewEventCountersRepo(pool, nil)
+	stats, err := repo.VerifyActive(ctx, time.Now().UTC().AddDate(0, 0, -90), 2)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if stats.Checked != 3 || stats.Drifted != 2 || stats.MaxDelta != 7 {
+		t.Fatalf("unexpected drift stats: %+v", stats)
+	}
+
+	for _, id := range []string{drifted, underCounted} {
+		if err := pool.QueryRow(ctx, `SELECT registered_count FROM events WHERE id = $1`, id).Scan(&count); err != nil {
+			t.Fatalf("select count: %v", err)
+		}
+		if count != 2 {
+			t.Fatalf("expected repaired count=2 for %s, got %d", id, count)
+		}
+	}
+
+	// targeted repair through the admin endpoint
+	if _, err := pool.Exec(ctx, `UPDATE events SET registered_count = 5 WHERE id = $1`, accurate); err != nil {
+		t.Fatalf("seed drift: %v", err)
+	}
+	adminToken := createAdminAuthToken(t, router, pool, "recount-admin@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events/"+accurate+"/recount", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("recount got %d body=%s", w.Code, w.Body.String())
+	}
+	var repair event.CounterRepair
+	if err := json.Unmarshal(w.Body.Bytes(), &repair); err != nil {
+		t.Fatalf("decode recount: %v", err)
+	}
+	if !repair.Repaired || repair.Stored != 5 || repair.Actual != 2 || repair.Delta != -3 {
+		t.Fatalf("unexpected repair: %+v", repair)
+	}
+}
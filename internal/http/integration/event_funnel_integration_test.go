@@ -0,0 +1,86 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/funnel"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestEventFunnelIntegration_AggregatesSeededCounters(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `DELETE FROM event_funnel_daily`); err != nil {
+		t.Fatalf("failed to clear funnel table: %v", err)
+	}
+	defer func() { _, _ = pool.Exec(ctx, `DELETE FROM event_funnel_daily`) }()
+
+	eventID := seedEvent(t, pool, 10)
+	otherEventID := uuid.NewString()
+
+	day1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	outside := day1.AddDate(0, 0, -1)
+
+	repo := postgres.NewEventFunnelRepo(pool, nil)
+
+	// two flushes for the same bucket must accumulate rather than overwrite
+	batches := [][]funnel.Increment{
+		{
+			{EventID: eventID, Day: day1, Stage: funnel.StageView, Reason: funnel.ReasonOK, Count: 6},
+			{EventID: eventID, Day: day1, Stage: funnel.StageAttempt, Reason: funnel.ReasonOK, Count: 2},
+			{EventID: eventID, Day: day1, Stage: funnel.StageAttempt, Reason: funnel.ReasonAlreadyRegistered, Count: 1},
+			{EventID: eventID, Day: day1, Stage: funnel.StageSuccess, Reason: funnel.ReasonOK, Count: 2},
+			{EventID: eventID, Day: outside, Stage: funnel.StageView, Reason: funnel.ReasonOK, Count: 100},
+			{EventID: otherEventID, Day: day1, Stage: funnel.StageView, Reason: funnel.ReasonOK, Count: 50},
+		},
+		{
+			{EventID: eventID, Day: day1, Stage: funnel.StageView, Reason: funnel.ReasonOK, Count: 4},
+			{EventID: eventID, Day: day2, Stage: funnel.StageCancel, Reason: funnel.ReasonOK, Count: 1},
+		},
+	}
+	for _, b := range batches {
+		if err := repo.ApplyIncrements(ctx, b); err != nil {
+			t.Fatalf("apply increments: %v", err)
+		}
+	}
+
+	adminToken := createAdminAuthToken(t, router, pool, "funnel-admin@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/events/"+eventID+"/funnel?from=2026-02-01&to=2026-02-02", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("funnel request failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	var report funnel.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode funnel response: %v", err)
+	}
+
+	if len(report.Days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(report.Days))
+	}
+	if report.Days[0].Views != 10 {
+		t.Fatalf("expected accumulated views=10 on day1, got %d", report.Days[0].Views)
+	}
+	if report.Days[0].AttemptFailures[funnel.ReasonAlreadyRegistered] != 1 {
+		t.Fatalf("expected bucketed already_registered failure, got %v", report.Days[0].AttemptFailures)
+	}
+	if report.Days[1].Cancellations != 1 {
+		t.Fatalf("expected 1 cancellation on day2, got %d", report.Days[1].Cancellations)
+	}
+	if report.Totals.Views != 10 || report.Totals.Attempts != 3 || report.Totals.Successes != 2 {
+		t.Fatalf("unexpected totals: %+v", report.Totals)
+	}
+	if report.Conversion.ViewToAttempt != 0.3 || report.Conversion.CancellationRate != 0.5 {
+		t.Fatalf("unexpected conversion: %+v", report.Conversion)
+	}
+}
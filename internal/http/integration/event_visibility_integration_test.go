@@ -0,0 +1,137 @@
+package integration__test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestEventVisibility_ListDetailAndRegister(t *testing.T) {
+	router, pool, cfg := setupPipelineTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	tokens := apphttp.NewTokenManager(cfg)
+	newUser := func(email string, role user.Role) (string, string) {
+		t.Helper()
+		id := uuid.NewString()
+		seedUserForExport(t, pool, id, email, "Visibility "+string(role))
+		token, err := tokens.GenerateAccessToken(id, email, role)
+		if err != nil {
+			t.Fatalf("token: %v", err)
+		}
+		return id, token
+	}
+	organizerID, organizerToken := newUser("vis-organizer@example.com", user.RoleUser)
+	registrantID, registrantToken := newUser("vis-registrant@example.com", user.RoleUser)
+	_, strangerToken := newUser("vis-stranger@example.com", user.RoleUser)
+	_, adminToken := newUser("vis-admin@example.com", user.RoleAdmin)
+
+	repo := postgres.NewEventsRepo(pool, nil)
+	ids := map[event.Visibility]string{}
+	for _, v := range []event.Visibility{event.VisibilityPublic, event.VisibilityUnlisted, event.VisibilityPrivate} {
+		e, err := repo.Create(ctx, event.CreateEventRequest{
+			Title:       "Visibility " + string(v),
+			StartAt:     time.Now().UTC().Add(48 * time.Hour),
+			Capacity:    10,
+			OrganizerID: organizerID,
+			Visibility:  v,
+		})
+		if err != nil {
+			t.Fatalf("create %s: %v", v, err)
+		}
+		ids[v] = e.ID
+	}
+
+	// listing and search only show the public one
+	for _, path := range []string{"/events?limit=100", "/events?limit=100&q=visibility"} {
+		w := doAnonymousJSONRequest(router, http.MethodGet, path, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s got %d body=%s", path, w.Code, w.Body.String())
+		}
+		for v, id := range ids {
+			if listed := strings.Contains(w.Body.String(), id); listed != (v == event.VisibilityPublic) {
+				t.Fatalf("%s: %s event listed=%v", path, v, listed)
+			}
+		}
+	}
+
+	// anyone with the link registers for public and unlisted; a private event
+	// is missing to anyone who may not open it, signed in or not
+	for v, want := range map[event.Visibility]int{
+		event.VisibilityPublic:   http.StatusCreated,
+		event.VisibilityUnlisted: http.StatusCreated,
+		event.VisibilityPrivate:  http.StatusNotFound,
+	} {
+		w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+ids[v]+"/register", `{"name":"Anon","email":"anon-`+string(v)+`@example.com"}`)
+		if w.Code != want {
+			t.Fatalf("anonymous register on %s: got %d want %d body=%s", v, w.Code, want, w.Body.String())
+		}
+	}
+	private := ids[event.VisibilityPrivate]
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+private+"/register", `{"name":"Stranger","email":"vis-stranger@example.com"}`, strangerToken)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("stranger register on private: got %d body=%s", w.Code, w.Body.String())
+	}
+	w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+private+"/register", `{"name":"Organizer","email":"vis-organizer@example.com"}`, organizerToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("organizer register on private: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// the organizer's guest list is how a registrant gets onto a private event
+	if _, err := postgres.NewRegistrationsRepo(pool, nil).Create(ctx, registration.CreateRegistrationRequest{
+		EventID: private,
+		UserID:  registrantID,
+		Name:    "Registrant",
+		Email:   "vis-registrant@example.com",
+	}); err != nil {
+		t.Fatalf("seed registrant: %v", err)
+	}
+
+	request := func(method, path, token string) int {
+		if token == "" {
+			return doAnonymousJSONRequest(router, method, path, "").Code
+		}
+		return doAuthedJSONRequest(router, method, path, "", token).Code
+	}
+	for name, token := range map[string]string{
+		"anonymous":  "",
+		"stranger":   strangerToken,
+		"registrant": registrantToken,
+		"organizer":  organizerToken,
+		"admin":      adminToken,
+	} {
+		for v, id := range ids {
+			want := http.StatusOK
+			if v == event.VisibilityPrivate && (name == "anonymous" || name == "stranger") {
+				want = http.StatusNotFound
+			}
+			if got := request(http.MethodGet, "/events/"+id, token); got != want {
+				t.Fatalf("%s viewing %s: got %d want %d", name, v, got, want)
+			}
+			if got := request(http.MethodGet, "/events/"+id+"/availability", token); got != want {
+				t.Fatalf("%s reading %s availability: got %d want %d", name, v, got, want)
+			}
+		}
+	}
+
+	// the organizer-only routes don't tell a stranger the event exists either
+	for _, path := range []string{"/events/" + private + "/registrations", "/events/" + private + "/branding"} {
+		if got := request(http.MethodGet, path, strangerToken); got != http.StatusNotFound {
+			t.Fatalf("stranger on %s: got %d want 404", path, got)
+		}
+		if got := request(http.MethodGet, path, organizerToken); got != http.StatusOK {
+			t.Fatalf("organizer on %s: got %d want 200", path, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestEventsRepo_GetByIDsSkipsMissingAndDeleted(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewEventsRepo(pool, nil)
+	create := func(title string) event.Event {
+		t.Helper()
+		e, err := repo.Create(ctx, event.CreateEventRequest{Title: title, StartAt: time.Now().UTC().Add(48 * time.Hour), Capacity: 10})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return e
+	}
+	live, deleted := create("Batch live"), create("Batch deleted")
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	got, err := repo.GetByIDs(ctx, []string{live.ID, deleted.ID, uuid.NewString()})
+	if err != nil {
+		t.Fatalf("get by ids: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != live.ID || got[0].Title != "Batch live" {
+		t.Fatalf("expected only the live event, got %+v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestExportCleanup_DeletesExpiredExportsAndHonoursKeepUntil(t *testing.T) {
+	cfg := testConfig()
+	cfg.ExportsDir = t.TempDir()
+	router, pool := setupTestRouterWithConfig(t, cfg)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	adminToken := createAdminAuthToken(t, router, pool, "admin-cleanup@example.com")
+	eventID := seedEvent(t, pool, 10)
+
+	store, err := exportstore.NewDirStore(cfg.ExportsDir)
+	if err != nil {
+		t.Fatalf("export store: %v", err)
+	}
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+	exportsRepo := postgres.NewRegistrationCSVExportsRepo(pool)
+
+	// finished export jobs, written 40 days ago
+	old := time.Now().UTC().Add(-40 * 24 * time.Hour)
+	keep := time.Now().UTC().Add(10 * 24 * time.Hour)
+	saveExport := func(name string, keepUntil *time.Time, writeBlob bool) string {
+		t.Helper()
+
+		j, err := jobsRepo.Create(ctx, job.CreateRequest{Type: jobs.TypeRegistrationsExportCSV, Payload: json.RawMessage(`{}`), RunAt: old, MaxAttempts: 1})
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE jobs SET status = 'done' WHERE id = $1`, j.ID); err != nil {
+			t.Fatalf("finish job: %v", err)
+		}
+
+		location := name
+		if writeBlob {
+			w, loc, err := store.Create(ctx, name)
+			if err != nil {
+				t.Fatalf("create blob: %v", err)
+			}
+			_, _ = io.WriteString(w, "registration_id\n")
+			if err := w.Close(); err != nil {
+				t.Fatalf("close blob: %v", err)
+			}
+			location = loc
+		}
+
+		err = exportsRepo.Save(ctx, registrationexport.CSVExport{
+			JobID:       j.ID,
+			EventID:     eventID,
+			FileName:    name,
+			ContentType: "text/csv",
+			StoragePath: location,
+			CreatedAt:   old,
+			KeepUntil:   keepUntil,
+		})
+		if err != nil {
+			t.Fatalf("save export: %v", err)
+		}
+		return j.ID
+	}
+
+	expiredID := saveExport("expired.csv", nil, true)
+	missingID := saveExport("missing.csv", nil, false)
+	keptID := saveExport("kept.csv", &keep, true)
+
+	// operators see what is due before cleanup runs
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/exports?eventId="+eventID+"&dueBy="+time.Now().UTC().Format(time.RFC3339), "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list exports got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var listed struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(listed.Items) != 2 {
+		t.Fatalf("expected 2 exports due, got %s", w.Body.String())
+	}
+
+	if _, err := jobsRepo.Create(ctx, job.CreateRequest{Type: jobs.TypeExportsCleanup, Payload: json.RawMessage(`{}`), RunAt: time.Now().UTC(), MaxAttempts: 3}); err != nil {
+		t.Fatalf("enqueue cleanup: %v", err)
+	}
+
+	wk := worker.New(worker.Config{
+		PollInterval:  10 * time.Millisecond,
+		WorkerID:      "test-worker-export-cleanup",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+	}, jobsRepo, postgres.NewEventsRepo(pool, nil), notifications.NewLogNotifier(), postgres.NewNotificationsDeliveriesRepo(pool)).
+		WithExportCleanup(exportsRepo, store, 30*24*time.Hour, jobsRepo)
+
+	processed, err := wk.ProcessOne(ctx)
+	if err != nil || !processed {
+		t.Fatalf("ProcessOne: processed=%v err=%v", processed, err)
+	}
+
+	for _, id := range []string{expiredID, missingID} {
+		if _, err := exportsRepo.GetByJobID(ctx, id); !errors.Is(err, registrationexport.ErrNotFound) {
+			t.Fatalf("expected export %s to be deleted, got %v", id, err)
+		}
+	}
+	if _, err := store.Open(ctx, "expired.csv"); !errors.Is(err, exportstore.ErrNotFound) {
+		t.Fatalf("expected expired blob to be deleted, got %v", err)
+	}
+
+	kept, err := exportsRepo.GetByJobID(ctx, keptID)
+	if err != nil {
+		t.Fatalf("expected keepUntil export to remain: %v", err)
+	}
+	if kept.KeepUntil == nil {
+		t.Fatalf("expected keep_until to round-trip")
+	}
+	if r, err := store.Open(ctx, kept.StoragePath); err != nil {
+		t.Fatalf("expected kept blob to remain: %v", err)
+	} else {
+		_ = r.Close()
+	}
+}
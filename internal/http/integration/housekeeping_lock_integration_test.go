@@ -0,0 +1,49 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestHousekeepingLock_SingleHolderAndTakeover(t *testing.T) {
+	_, pool := setupTestRouter(t)
+
+	ctx := context.Background()
+	a := postgres.NewHousekeepingLock(pool)
+	b := postgres.NewHousekeepingLock(pool)
+	defer func() {
+		_ = a.Release(ctx)
+		_ = b.Release(ctx)
+	}()
+
+	got, err := a.TryAcquire(ctx)
+	if err != nil || !got {
+		t.Fatalf("expected the first instance to take the lock, got %v err=%v", got, err)
+	}
+
+	got, err = b.TryAcquire(ctx)
+	if err != nil || got {
+		t.Fatalf("expected the second instance refused while the lock is held, got %v err=%v", got, err)
+	}
+
+	got, err = a.TryAcquire(ctx)
+	if err != nil || !got {
+		t.Fatalf("expected the holder to keep the lock, got %v err=%v", got, err)
+	}
+
+	if err := a.Release(ctx); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+
+	got, err = b.TryAcquire(ctx)
+	if err != nil || !got {
+		t.Fatalf("expected the second instance to take over after release, got %v err=%v", got, err)
+	}
+
+	got, err = a.TryAcquire(ctx)
+	if err != nil || got {
+		t.Fatalf("expected the first instance refused after handing over, got %v err=%v", got, err)
+	}
+}
@@ -0,0 +1,71 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobAttempts_HistoryOrderedByAttempt(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+	attemptsRepo := postgres.NewJobAttemptsRepo(pool, nil)
+	adminToken := createAdminAuthToken(t, router, pool, "admin-attempts@example.com")
+
+	j, err := jobsRepo.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`), MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+
+	started := time.Now().UTC().Truncate(time.Millisecond)
+	errMsg := "boom"
+	// flushed out of order, and one row for a job that no longer exists
+	err = attemptsRepo.InsertBatch(ctx, []job.Attempt{
+		{JobID: j.ID, Attempt: 2, WorkerID: "w-2", StartedAt: started.Add(time.Minute), FinishedAt: started.Add(time.Minute + 20*time.Millisecond), Outcome: job.AttemptDone, DurationMs: 20},
+		{JobID: j.ID, Attempt: 1, WorkerID: "w-1", StartedAt: started, FinishedAt: started.Add(50 * time.Millisecond), Outcome: job.AttemptRetried, Error: &errMsg, DurationMs: 50,
+			TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"},
+		{JobID: "00000000-0000-0000-0000-000000000000", Attempt: 1, WorkerID: "w-1", StartedAt: started, FinishedAt: started, Outcome: job.AttemptDone},
+	})
+	if err != nil {
+		t.Fatalf("insert batch: %v", err)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/jobs/"+j.ID+"/attempts", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("attempts: got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		JobID string        `json:"jobId"`
+		Items []job.Attempt `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.JobID != j.ID || len(resp.Items) != 2 {
+		t.Fatalf("expected two attempts for %s, got %+v", j.ID, resp)
+	}
+	first, second := resp.Items[0], resp.Items[1]
+	if first.Attempt != 1 || first.Outcome != job.AttemptRetried || first.Error == nil || *first.Error != "boom" || first.DurationMs != 50 {
+		t.Fatalf("unexpected first attempt %+v", first)
+	}
+	if first.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || first.SpanID != "00f067aa0ba902b7" {
+		t.Fatalf("expected the first attempt's span kept, got trace=%q span=%q", first.TraceID, first.SpanID)
+	}
+	if second.Attempt != 2 || second.Outcome != job.AttemptDone || second.WorkerID != "w-2" || second.Error != nil || second.TraceID != "" {
+		t.Fatalf("unexpected second attempt %+v", second)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/admin/jobs/00000000-0000-0000-0000-000000000000/attempts", "", adminToken)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing job: got %d body=%s", w.Code, w.Body.String())
+	}
+}
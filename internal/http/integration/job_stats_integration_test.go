@@ -0,0 +1,65 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobStats_UpsertReplacesDayAndStatsEndpointReadsIt(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `TRUNCATE job_stats_daily`); err != nil {
+		t.Fatalf("truncate job_stats_daily: %v", err)
+	}
+	defer func() { _, _ = pool.Exec(ctx, `TRUNCATE job_stats_daily`) }()
+
+	adminToken := createAdminAuthToken(t, router, pool, "admin-job-stats@example.com")
+	repo := postgres.NewJobStatsRepo(pool, nil)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	// flushes carry the day's running totals, so a later one replaces the row
+	for _, done := range []int64{2, 5} {
+		if err := repo.UpsertDaily(ctx, job.DailyStats{Day: today, WorkerID: "worker-a", Claimed: done, Done: done}); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+	if err := repo.UpsertDaily(ctx, job.DailyStats{Day: today.AddDate(0, 0, -1), WorkerID: "worker-a", Claimed: 4, Done: 3, Failed: 1}); err != nil {
+		t.Fatalf("upsert yesterday: %v", err)
+	}
+
+	rows, err := repo.ListDaily(ctx, today.AddDate(0, 0, -1), today)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(rows) != 2 || !rows[0].Day.Equal(today) || rows[0].Done != 5 || rows[1].Failed != 1 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/jobs/stats?days=2", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("stats got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Total struct {
+			Done   int64 `json:"done"`
+			Failed int64 `json:"failed"`
+		} `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Total.Done != 8 || got.Total.Failed != 1 {
+		t.Fatalf("unexpected totals: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,109 @@
+package integration__test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobsCancel_PendingAndProcessing(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	token := createAdminAuthToken(t, router, pool, "admin-cancel@example.com")
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	pending, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: time.Now().UTC().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("seed pending: %v", err)
+	}
+	running, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: time.Now().UTC().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("seed running: %v", err)
+	}
+	if claimed, err := repo.ClaimNext(ctx, "worker-a"); err != nil || claimed.ID != running.ID {
+		t.Fatalf("claim: got %s err=%v", claimed.ID, err)
+	}
+
+	// pending: cancelled outright and never claimed
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/jobs/"+pending.ID+"/cancel", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel pending: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET run_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, pending.ID); err != nil {
+		t.Fatalf("make due: %v", err)
+	}
+	if _, err := repo.ClaimNext(ctx, "worker-b"); !errors.Is(err, job.ErrJobNotFound) {
+		t.Fatalf("a cancelled job must not be claimed, got err=%v", err)
+	}
+
+	// processing: flagged, and the holder hears about it on its next renewal
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/jobs/"+running.ID+"/cancel", "", token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("cancel processing: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if err := repo.ExtendLock(ctx, running.ID, "worker-a"); !errors.Is(err, job.ErrCancelRequested) {
+		t.Fatalf("expected ErrCancelRequested on renewal, got %v", err)
+	}
+	// a worker that no longer holds the job can't record its outcome
+	if err := repo.MarkCancelled(ctx, running.ID, "worker-b", job.ErrCancelRequested.Error()); !errors.Is(err, job.ErrLockLost) {
+		t.Fatalf("expected ErrLockLost for another worker, got %v", err)
+	}
+	if err := repo.MarkCancelled(ctx, running.ID, "worker-a", job.ErrCancelRequested.Error()); err != nil {
+		t.Fatalf("mark cancelled: %v", err)
+	}
+
+	// finished jobs can't be cancelled again
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/jobs/"+running.ID+"/cancel", "", token)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("cancel finished: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/admin/jobs?status=cancelled&includeTotal=true", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list cancelled: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var n int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE status = 'cancelled'`).Scan(&n); err != nil || n != 2 {
+		t.Fatalf("expected both jobs cancelled, got %d err=%v", n, err)
+	}
+}
+
+func TestJobsCancel_StaleFlaggedJobIsNotRequeued(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	created, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: time.Now().UTC().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+	if _, err := repo.ClaimNext(ctx, "worker-a"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if status, err := repo.Cancel(ctx, created.ID); err != nil || status != job.StatusProcessing {
+		t.Fatalf("cancel: status=%s err=%v", status, err)
+	}
+
+	// the worker died before it saw the flag
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET locked_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, created.ID); err != nil {
+		t.Fatalf("age lock: %v", err)
+	}
+	if res, err := repo.RequeueStaleProcessing(ctx, 30*time.Second); err != nil || res.Cancelled != 1 {
+		t.Fatalf("requeue: %+v err=%v", res, err)
+	}
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil || got.Status != job.StatusCancelled {
+		t.Fatalf("expected the flagged job cancelled, got status=%s err=%v", got.Status, err)
+	}
+}
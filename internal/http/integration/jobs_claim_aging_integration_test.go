@@ -0,0 +1,119 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestClaimNext_AgingLetsStarvedJobWin(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	plain := postgres.NewJobsRepo(pool, nil)
+
+	// waiting three minutes at priority 0, behind a steady trickle of priority 5
+	starved, err := plain.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: now.Add(-3 * time.Minute)})
+	if err != nil {
+		t.Fatalf("seed starved job: %v", err)
+	}
+	seedUrgent := func() {
+		t.Helper()
+		if _, err := plain.Create(ctx, job.CreateRequest{Type: "test.noop", Priority: 5, RunAt: now}); err != nil {
+			t.Fatalf("seed urgent job: %v", err)
+		}
+	}
+
+	// strict priority order: the trickle always wins
+	for i := 0; i < 3; i++ {
+		seedUrgent()
+		claimed, err := plain.ClaimNext(ctx, "worker-plain")
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if claimed.ID == starved.ID {
+			t.Fatalf("without aging the priority-0 job should not be claimed first")
+		}
+	}
+
+	// one point per 30s of waiting: 0 + 6 beats 5
+	aged := postgres.NewJobsRepo(pool, nil).WithAging(job.Aging{Interval: 30 * time.Second, MaxBoost: 10})
+
+	items, _, _, err := aged.ListCursor(ctx, nil, 50, time.Now().Add(time.Hour), "ffffffff-ffff-ffff-ffff-ffffffffffff")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, j := range items {
+		if j.ID == starved.ID && (j.EffectivePriority == nil || *j.EffectivePriority != 6) {
+			t.Fatalf("expected the listing to show effective priority 6, got %v", j.EffectivePriority)
+		}
+		if j.Status != job.StatusPending && j.EffectivePriority != nil {
+			t.Fatalf("effective priority is only shown for pending jobs: %+v", j)
+		}
+	}
+
+	seedUrgent()
+	claimed, err := aged.ClaimNext(ctx, "worker-aged")
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if claimed.ID != starved.ID {
+		t.Fatalf("expected the aged job to win, got %s priority=%d", claimed.ID, claimed.Priority)
+	}
+}
+
+func TestClaimBatch_OneStatementClaimsInPriorityOrder(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	seed := func(priority int, runAt time.Time) string {
+		t.Helper()
+		j, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Priority: priority, RunAt: runAt})
+		if err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		return j.ID
+	}
+	low := seed(0, now.Add(-time.Minute))
+	urgentLater := seed(5, now.Add(-time.Second))
+	urgentFirst := seed(5, now.Add(-time.Minute))
+	mid := seed(2, now.Add(-time.Minute))
+	seed(9, now.Add(time.Hour)) // not due yet
+
+	batch, err := repo.ClaimBatch(ctx, "worker-batch", 3)
+	if err != nil {
+		t.Fatalf("claim batch: %v", err)
+	}
+
+	want := []string{urgentFirst, urgentLater, mid}
+	if len(batch) != len(want) {
+		t.Fatalf("expected %d jobs from one call, got %d", len(want), len(batch))
+	}
+	for i, j := range batch {
+		if j.ID != want[i] {
+			t.Fatalf("position %d: expected %s, got %s (priority %d)", i, want[i], j.ID, j.Priority)
+		}
+		if j.Status != job.StatusProcessing || j.LockedBy == nil || *j.LockedBy != "worker-batch" || j.LockedAt == nil {
+			t.Fatalf("expected lock metadata on %s, got %+v", j.ID, j)
+		}
+	}
+
+	rest, err := repo.ClaimBatch(ctx, "worker-batch", 3)
+	if err != nil {
+		t.Fatalf("claim rest: %v", err)
+	}
+	if len(rest) != 1 || rest[0].ID != low {
+		t.Fatalf("expected only the low priority job left, got %+v", rest)
+	}
+}
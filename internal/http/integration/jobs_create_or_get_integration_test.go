@@ -0,0 +1,101 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestJobsRepo_CreateOrGetConcurrentCallersShareOneJob(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	key := "create-or-get:" + uuid.NewString()
+
+	const callers = 8
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		ids     = map[string]int{}
+		created int
+		start   = make(chan struct{})
+	)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			j, isNew, err := repo.CreateOrGet(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`), IdempotencyKey: &key})
+			if err != nil {
+				t.Errorf("create or get: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ids[j.ID]++
+			if isNew {
+				created++
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if len(ids) != 1 || created != 1 {
+		t.Fatalf("expected every caller to get the one job and exactly one to create it, got ids=%v created=%d", ids, created)
+	}
+	var rows int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE idempotency_key = $1`, key).Scan(&rows); err != nil || rows != 1 {
+		t.Fatalf("expected one row for the key, got %d err=%v", rows, err)
+	}
+}
+
+func TestPublishEvent_ConcurrentDuplicatesGetTheSameJob(t *testing.T) {
+	router, pool, cfg := setupPipelineTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	token, err := apphttp.NewTokenManager(cfg).GenerateAccessToken(uuid.NewString(), "publish-race@example.com", user.RoleAdmin)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	eventID := seedEvent(t, pool, 10)
+
+	var (
+		wg     sync.WaitGroup
+		jobIDs [2]string
+		codes  [2]int
+	)
+	for i := range jobIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events/"+eventID+"/publish", `{}`, token)
+			codes[i] = w.Code
+			var body struct {
+				JobID string `json:"jobId"`
+			}
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			jobIDs[i] = body.JobID
+		}()
+	}
+	wg.Wait()
+
+	if codes[0] != http.StatusAccepted || codes[1] != http.StatusAccepted {
+		t.Fatalf("expected both publishes accepted, got %v", codes)
+	}
+	if jobIDs[0] == "" || jobIDs[0] != jobIDs[1] {
+		t.Fatalf("expected both callers to get the same job, got %v", jobIDs)
+	}
+}
@@ -0,0 +1,111 @@
+package integration__test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestExtendLock_OnlyTheHolderRenews(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	created, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: time.Now().UTC().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+	claimed, err := repo.ClaimNext(ctx, "worker-a")
+	if err != nil || claimed.ID != created.ID {
+		t.Fatalf("claim: got %s err=%v", claimed.ID, err)
+	}
+
+	// age the lock past a 30s TTL, then renew it
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET locked_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, created.ID); err != nil {
+		t.Fatalf("age lock: %v", err)
+	}
+	if err := repo.ExtendLock(ctx, created.ID, "worker-a"); err != nil {
+		t.Fatalf("extend by holder: %v", err)
+	}
+	if res, err := repo.RequeueStaleProcessing(ctx, 30*time.Second); err != nil || res.Total() != 0 {
+		t.Fatalf("renewed lock must not be requeued, got %+v err=%v", res, err)
+	}
+
+	if err := repo.ExtendLock(ctx, created.ID, "worker-b"); !errors.Is(err, job.ErrLockLost) {
+		t.Fatalf("extend by another worker: expected ErrLockLost, got %v", err)
+	}
+
+	// once requeued the old holder has lost it too
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET locked_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, created.ID); err != nil {
+		t.Fatalf("age lock: %v", err)
+	}
+	if res, err := repo.RequeueStaleProcessing(ctx, 30*time.Second); err != nil || res.Requeued != 1 {
+		t.Fatalf("expected the stale job requeued, got %+v err=%v", res, err)
+	}
+	if err := repo.ExtendLock(ctx, created.ID, "worker-a"); !errors.Is(err, job.ErrLockLost) {
+		t.Fatalf("extend after requeue: expected ErrLockLost, got %v", err)
+	}
+}
+
+func TestRequeueStale_DeadLettersAtMaxAttempts(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	created, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", MaxAttempts: 3, RunAt: time.Now().UTC().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+
+	// every run kills its worker: claim, let the lock expire, take it back
+	for attempt := 1; attempt <= 3; attempt++ {
+		claimed, err := repo.ClaimNext(ctx, "worker-a")
+		if err != nil || claimed.ID != created.ID {
+			t.Fatalf("attempt %d: claim got %s err=%v", attempt, claimed.ID, err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE jobs SET locked_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, created.ID); err != nil {
+			t.Fatalf("age lock: %v", err)
+		}
+
+		res, err := repo.RequeueStaleProcessing(ctx, 30*time.Second)
+		if err != nil {
+			t.Fatalf("attempt %d: requeue: %v", attempt, err)
+		}
+		want := job.StaleRequeue{Requeued: 1}
+		if attempt == 3 {
+			want = job.StaleRequeue{DeadLettered: 1}
+		}
+		if res != want {
+			t.Fatalf("attempt %d: got %+v, want %+v", attempt, res, want)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != job.StatusFailed || got.Attempts != 3 || got.LastError == nil || *got.LastError != job.StaleLockError {
+		t.Fatalf("expected failed after 3 attempts with the lock error, got status=%s attempts=%d lastError=%v", got.Status, got.Attempts, got.LastError)
+	}
+	if _, err := repo.ClaimNext(ctx, "worker-a"); !errors.Is(err, job.ErrJobNotFound) {
+		t.Fatalf("expected nothing left to claim, got %v", err)
+	}
+
+	var finalError string
+	if err := pool.QueryRow(ctx, `SELECT final_error FROM dead_letters WHERE job_id = $1`, created.ID).Scan(&finalError); err != nil {
+		t.Fatalf("expected a dead letter: %v", err)
+	}
+	if finalError != job.StaleLockError {
+		t.Fatalf("dead letter final_error = %q", finalError)
+	}
+}
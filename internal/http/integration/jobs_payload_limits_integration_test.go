@@ -0,0 +1,74 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobPayloadLimits_RejectAtEnqueueAndReportExisting(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	adminToken := createAdminAuthToken(t, router, pool, "admin-payloads@example.com")
+
+	big := json.RawMessage(`{"blob":"` + strings.Repeat("x", 2000) + `"}`)
+	small := json.RawMessage(`{"ok":true}`)
+
+	// rows from before the limit existed
+	unlimited := postgres.NewJobsRepo(pool, nil).WithPayloadLimits(job.PayloadLimits{})
+	legacy, err := unlimited.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: big})
+	if err != nil {
+		t.Fatalf("seed oversized job: %v", err)
+	}
+	if _, err := unlimited.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: small}); err != nil {
+		t.Fatalf("seed small job: %v", err)
+	}
+
+	limited := postgres.NewJobsRepo(pool, nil).WithPayloadLimits(job.PayloadLimits{MaxBytes: 1024, MaxDepth: 8})
+	if _, err := limited.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: big}); !errors.Is(err, job.ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge at enqueue, got %v", err)
+	}
+
+	offenders, err := limited.OversizedPayloads(ctx, 1024, 10)
+	if err != nil {
+		t.Fatalf("oversized payloads: %v", err)
+	}
+	if len(offenders) != 1 || offenders[0].ID != legacy.ID || offenders[0].Size <= 2000 {
+		t.Fatalf("expected only the legacy job reported, got %+v", offenders)
+	}
+
+	// reporting leaves the row alone
+	var status string
+	var size int
+	err = pool.QueryRow(ctx, `SELECT status, octet_length(payload::text) FROM jobs WHERE id = $1`, legacy.ID).Scan(&status, &size)
+	if err != nil {
+		t.Fatalf("read legacy job: %v", err)
+	}
+	if status != string(job.StatusPending) || size != offenders[0].Size {
+		t.Fatalf("legacy job changed: status=%s size=%d", status, size)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/jobs/payload-report", `{"maxBytes":1024}`, adminToken)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("payload report got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var accepted struct {
+		JobID string `json:"jobId"`
+		Type  string `json:"type"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &accepted); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if accepted.JobID == "" || accepted.Type != "jobs.payload_report" {
+		t.Fatalf("unexpected accepted body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,80 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestClaimNext_HigherPriorityFirst(t *testing.T) {
+	router, pool, cfg := setupPipelineTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	token, err := apphttp.NewTokenManager(cfg).GenerateAccessToken(uuid.NewString(), "priority-admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	publish := func(eventID, priority string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/publish?priority="+priority, bytes.NewBufferString(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("publish %s got %d body=%s", priority, w.Code, w.Body.String())
+		}
+	}
+
+	// the low one is enqueued first and due earlier; high still goes first
+	low, high := seedEvent(t, pool, 10), seedEvent(t, pool, 10)
+	publish(low, "low")
+	time.Sleep(10 * time.Millisecond)
+	publish(high, "high")
+
+	repo := postgres.NewJobsRepo(pool, nil)
+	for _, want := range []int{job.PriorityHigh, job.PriorityLow} {
+		claimed, err := repo.ClaimNext(ctx, "priority-worker")
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if claimed.Priority != want {
+			t.Fatalf("claimed priority %d, want %d", claimed.Priority, want)
+		}
+	}
+
+	// a failed job retried with a bump overtakes a ready normal one
+	normal, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop"})
+	if err != nil {
+		t.Fatalf("seed normal job: %v", err)
+	}
+	failed, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Priority: job.PriorityLow})
+	if err != nil {
+		t.Fatalf("seed failed job: %v", err)
+	}
+	if err := repo.MarkFailed(ctx, failed.ID, "boom"); err != nil {
+		t.Fatalf("fail job: %v", err)
+	}
+	bump := job.PriorityHigh
+	if err := repo.Retry(ctx, failed.ID, &bump); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	claimed, err := repo.ClaimNext(ctx, "priority-worker")
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if claimed.ID != failed.ID || claimed.Priority != job.PriorityHigh {
+		t.Fatalf("expected the bumped retry claimed before %s, got %+v", normal.ID, claimed)
+	}
+}
@@ -0,0 +1,72 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobsProgress_ShownOnAdminDetail(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	token := createAdminAuthToken(t, router, pool, "admin-progress@example.com")
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	running, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: time.Now().UTC().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if claimed, err := repo.ClaimNext(ctx, "worker-a"); err != nil || claimed.ID != running.ID {
+		t.Fatalf("claim: got %s err=%v", claimed.ID, err)
+	}
+
+	if err := repo.UpdateProgress(ctx, running.ID, job.Progress{Done: 1200, Total: 54000, Message: "exporting rows"}); err != nil {
+		t.Fatalf("update progress: %v", err)
+	}
+	// inside the one-second interval: dropped
+	if err := repo.UpdateProgress(ctx, running.ID, job.Progress{Done: 1300, Total: 54000}); err != nil {
+		t.Fatalf("update progress: %v", err)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodGet, "/admin/jobs/"+running.ID, "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var got job.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Progress == nil || got.Progress.Done != 1200 || got.Progress.Total != 54000 || got.Progress.Message != "exporting rows" {
+		t.Fatalf("expected the first report shown, got %+v", got.Progress)
+	}
+
+	// the finishing report is written even inside the interval
+	if err := repo.UpdateProgress(ctx, running.ID, job.Progress{Done: 54000, Total: 54000}); err != nil {
+		t.Fatalf("update progress: %v", err)
+	}
+	stored, err := repo.GetByID(ctx, running.ID)
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	if stored.Progress == nil || !stored.Progress.Finished() {
+		t.Fatalf("expected the finishing report stored, got %+v", stored.Progress)
+	}
+
+	// jobs that never report have no progress
+	idle, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", RunAt: time.Now().UTC().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("seed idle: %v", err)
+	}
+	stored, err = repo.GetByID(ctx, idle.ID)
+	if err != nil || stored.Progress != nil {
+		t.Fatalf("expected no progress on an idle job, got %+v err=%v", stored.Progress, err)
+	}
+}
@@ -0,0 +1,74 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobsPurge_DeletesOnlyOldTerminalJobs(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	adminToken := createAdminAuthToken(t, router, pool, "admin-purge@example.com")
+
+	seed := func(status job.Status, ageDays int) string {
+		t.Helper()
+		j, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`), MaxAttempts: 3})
+		if err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		_, err = pool.Exec(ctx, `
+			UPDATE jobs SET status = $2, updated_at = NOW() - make_interval(days => $3) WHERE id = $1
+		`, j.ID, string(status), ageDays)
+		if err != nil {
+			t.Fatalf("age job: %v", err)
+		}
+		return j.ID
+	}
+
+	oldDone := seed(job.StatusDone, 40)
+	recentDone := seed(job.StatusDone, 5)
+	oldFailed := seed(job.StatusFailed, 40)
+	oldPending := seed(job.StatusPending, 40)
+	oldProcessing := seed(job.StatusProcessing, 40)
+
+	w := doAuthedJSONRequest(router, http.MethodDelete, "/admin/jobs/purge?status=done&olderThanDays=30", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("purge: got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Deleted int64 `json:"deleted"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Deleted != 1 {
+		t.Fatalf("expected one old done job purged, got %d", resp.Deleted)
+	}
+
+	if _, err := repo.GetByID(ctx, oldDone); !errors.Is(err, job.ErrJobNotFound) {
+		t.Fatalf("expected the old done job gone, got %v", err)
+	}
+	for _, id := range []string{recentDone, oldFailed, oldPending, oldProcessing} {
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			t.Fatalf("expected job %s kept, got %v", id, err)
+		}
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodDelete, "/admin/jobs/purge?status=pending,processing", "", adminToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected live jobs refused, got %d body=%s", w.Code, w.Body.String())
+	}
+	if _, err := repo.PurgeTerminal(ctx, 0, []string{"failed"}, 10); err == nil {
+		t.Fatalf("expected a zero age to be rejected")
+	}
+}
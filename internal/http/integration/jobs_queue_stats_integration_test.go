@@ -0,0 +1,97 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobsQueueStats_CountsDuePendingOnly(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	seed := func(runAt time.Time, status job.Status) {
+		t.Helper()
+		j, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`), RunAt: runAt, MaxAttempts: 3})
+		if err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE jobs SET status = $2 WHERE id = $1`, j.ID, string(status)); err != nil {
+			t.Fatalf("set status: %v", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	seed(now.Add(-45*time.Minute), job.StatusPending)
+	seed(now.Add(-time.Minute), job.StatusPending)
+	seed(now.Add(time.Hour), job.StatusPending)       // not due yet
+	seed(now.Add(-3*time.Hour), job.StatusDone)       // not backlog
+	seed(now.Add(-2*time.Hour), job.StatusProcessing) // being worked on
+
+	stats, err := repo.QueueStats(ctx)
+	if err != nil {
+		t.Fatalf("queue stats: %v", err)
+	}
+	if stats.DuePending != 2 {
+		t.Fatalf("expected 2 due pending jobs, got %d", stats.DuePending)
+	}
+	if stats.OldestPendingAge < 44*time.Minute || stats.OldestPendingAge > 50*time.Minute {
+		t.Fatalf("expected the oldest due job to be ~45m old, got %s", stats.OldestPendingAge)
+	}
+}
+
+func TestJobsStats_CountsEveryStatusAndOldestDuePending(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	seed := func(runAt time.Time, status job.Status) {
+		t.Helper()
+		j, err := repo.Create(ctx, job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`), RunAt: runAt, MaxAttempts: 3})
+		if err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE jobs SET status = $2 WHERE id = $1`, j.ID, string(status)); err != nil {
+			t.Fatalf("set status: %v", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	seed(now.Add(-20*time.Minute), job.StatusPending)
+	seed(now.Add(time.Hour), job.StatusPending)       // counted, but not waiting
+	seed(now.Add(-3*time.Hour), job.StatusProcessing) // older, but not pending
+	seed(now.Add(-3*time.Hour), job.StatusDone)
+	seed(now.Add(-3*time.Hour), job.StatusDone)
+
+	stats, err := repo.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	want := map[job.Status]int64{
+		job.StatusPending:    2,
+		job.StatusProcessing: 1,
+		job.StatusDone:       2,
+		job.StatusFailed:     0,
+		job.StatusCancelled:  0,
+	}
+	for status, n := range want {
+		if got, ok := stats.Counts[status]; !ok || got != n {
+			t.Fatalf("%s: got %d (present=%t), want %d", status, got, ok, n)
+		}
+	}
+	if stats.OldestPendingAge < 19*time.Minute || stats.OldestPendingAge > 25*time.Minute {
+		t.Fatalf("expected the oldest due pending job to be ~20m old, got %s", stats.OldestPendingAge)
+	}
+}
@@ -0,0 +1,61 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobsRelease_HandsBackWithoutSpendingAnAttempt(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+
+	export, err := repo.Create(ctx, job.CreateRequest{Type: "test.export", Payload: json.RawMessage(`{}`), RunAt: time.Now().UTC().Add(-time.Minute), MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("seed export: %v", err)
+	}
+	confirm, err := repo.Create(ctx, job.CreateRequest{Type: "test.confirm", Payload: json.RawMessage(`{}`), RunAt: time.Now().UTC().Add(-time.Minute), MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("seed confirm: %v", err)
+	}
+
+	batch, err := repo.ClaimBatchExcluding(ctx, "worker-1", 5, []string{"test.export"})
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(batch) != 1 || batch[0].ID != confirm.ID {
+		t.Fatalf("expected only the confirmation claimed, got %+v", batch)
+	}
+
+	batch, err = repo.ClaimBatch(ctx, "worker-1", 5)
+	if err != nil || len(batch) != 1 || batch[0].ID != export.ID {
+		t.Fatalf("expected the export claimed without exclusions, got %+v err=%v", batch, err)
+	}
+
+	if err := repo.Release(ctx, export.ID, "worker-2"); !errors.Is(err, job.ErrLockLost) {
+		t.Fatalf("expected another worker's release to be refused, got %v", err)
+	}
+	if err := repo.Release(ctx, export.ID, "worker-1"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, export.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != job.StatusPending || got.Attempts != 0 || got.LockedBy != nil {
+		t.Fatalf("expected a pending, unlocked job with no attempt spent, got %+v", got)
+	}
+	if got.RunAt.After(time.Now().UTC().Add(time.Second)) {
+		t.Fatalf("expected the released job due now, got run_at %s", got.RunAt)
+	}
+}
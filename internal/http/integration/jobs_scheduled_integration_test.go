@@ -0,0 +1,89 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/geocoder89/eventhub/internal/utils"
+)
+
+func TestJobsListScheduledCursor_OrdersByRunAtAndPages(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	seed := func(runAt time.Time) string {
+		t.Helper()
+		j, err := repo.Create(ctx, job.CreateRequest{Type: "test.scheduled", Payload: json.RawMessage(`{}`), RunAt: runAt})
+		if err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		return j.ID
+	}
+
+	seed(now.Add(-time.Minute)) // already due: not scheduled
+	later := seed(now.Add(3 * time.Hour))
+	soon := seed(now.Add(time.Hour))
+	tied := []string{seed(now.Add(2 * time.Hour)), seed(now.Add(2 * time.Hour))}
+	farOff := seed(now.Add(72 * time.Hour))
+
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET status = 'cancelled' WHERE id = $1`, farOff); err != nil {
+		t.Fatalf("cancel far-off job: %v", err)
+	}
+
+	// the two tied run_ats come back in id order
+	if tied[0] > tied[1] {
+		tied[0], tied[1] = tied[1], tied[0]
+	}
+	want := []string{soon, tied[0], tied[1], later}
+
+	var got []string
+	afterRunAt, afterID := time.Unix(0, 0).UTC(), "00000000-0000-0000-0000-000000000000"
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatalf("paging did not end")
+		}
+		items, next, hasMore, err := repo.ListScheduledCursor(ctx, job.ScheduledFilter{}, 2, afterRunAt, afterID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, j := range items {
+			got = append(got, j.ID)
+		}
+		if !hasMore {
+			break
+		}
+		cur, err := utils.DecodeScheduledJobCursor(*next)
+		if err != nil {
+			t.Fatalf("decode cursor: %v", err)
+		}
+		afterRunAt, afterID = cur.RunAt, cur.ID
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	// runAfter/runBefore narrow the window
+	after, before := now.Add(90*time.Minute), now.Add(150*time.Minute)
+	items, _, _, err := repo.ListScheduledCursor(ctx, job.ScheduledFilter{RunAfter: &after, RunBefore: &before}, 10, time.Unix(0, 0).UTC(), "00000000-0000-0000-0000-000000000000")
+	if err != nil {
+		t.Fatalf("list window: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != tied[0] || items[1].ID != tied[1] {
+		t.Fatalf("expected only the tied jobs in the window, got %+v", items)
+	}
+}
@@ -0,0 +1,83 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestJobsWakeup_ClaimsWellUnderPollInterval(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const pollInterval = 10 * time.Second
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+	claimed := make(chan time.Time, 1)
+
+	wk := worker.New(worker.Config{
+		PollInterval:  pollInterval,
+		WorkerID:      "wakeup-worker",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+	}, jobsRepo, postgres.NewEventsRepo(pool, nil), nil, nil).
+		WithWakeups(postgres.ListenNewJobs(pool)).
+		Register("test.wakeup", func(ctx context.Context, j job.Job) error {
+			claimed <- time.Now()
+			return nil
+		})
+
+	done := make(chan error, 1)
+	go func() { done <- wk.Run(ctx) }()
+
+	// the first poll tick is a full interval away; wait for the listener instead
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var listening bool
+		err := pool.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM pg_stat_activity WHERE query = 'LISTEN ' || $1)
+		`, jobs.NewJobsChannel).Scan(&listening)
+		if err != nil {
+			t.Fatalf("check listener: %v", err)
+		}
+		if listening {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("worker never started listening")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	enqueuedAt := time.Now()
+	if _, err := jobsRepo.Create(ctx, job.CreateRequest{
+		Type:        "test.wakeup",
+		Payload:     []byte(`{}`),
+		RunAt:       time.Now().UTC(),
+		MaxAttempts: 1,
+	}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	select {
+	case at := <-claimed:
+		if latency := at.Sub(enqueuedAt); latency > time.Second {
+			t.Fatalf("enqueue-to-claim took %s with a %s poll interval", latency, pollInterval)
+		}
+	case <-time.After(pollInterval / 2):
+		t.Fatalf("job not claimed before half the poll interval")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("worker run: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type myRegistrationsPage struct {
+	Items      []registration.WithEvent `json:"items"`
+	HasMore    bool                     `json:"hasMore"`
+	NextCursor *string                  `json:"nextCursor"`
+}
+
+func TestMyRegistrations_ListsByEventStartAndClaimsVerifiedEmail(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	later := seedEventStartingAt(t, pool, 10, now.Add(72*time.Hour))
+	sooner := seedEventStartingAt(t, pool, 10, now.Add(24*time.Hour))
+	anonymous := seedEventStartingAt(t, pool, 10, now.Add(48*time.Hour))
+
+	token := signupAndGetToken(t, router, "me-regs@example.com")
+	for _, eventID := range []string{later, sooner} {
+		w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Me","email":"me-regs@example.com"}`, token)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("register failed: status=%d body=%s", w.Code, w.Body.String())
+		}
+	}
+	w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+anonymous+"/register", `{"name":"Me","email":"ME-REGS@example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("anonymous register failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// one per page, soonest event first
+	w = doAuthedJSONRequest(router, http.MethodGet, "/me/registrations?limit=1", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var page myRegistrationsPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].EventID != sooner || !page.HasMore || page.NextCursor == nil {
+		t.Fatalf("unexpected first page: %s", w.Body.String())
+	}
+	if page.Items[0].EventTitle != "Test Event" || page.Items[0].EventCity != "Toronto" {
+		t.Fatalf("expected event details on the item, got %+v", page.Items[0])
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/me/registrations?limit=1&cursor="+*page.NextCursor, "", token)
+	page = myRegistrationsPage{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].EventID != later || page.HasMore {
+		t.Fatalf("unexpected second page: %s", w.Body.String())
+	}
+
+	// claiming needs a verified email
+	w = doAuthedJSONRequest(router, http.MethodPost, "/me/registrations/claim", "", token)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 before verification, got status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/me/email-verification", "", token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("request verification got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var userID string
+	if err := pool.QueryRow(ctx, `
+		SELECT u.id FROM users u
+		JOIN jobs j ON j.user_id = u.id AND j.type = $1
+		WHERE u.email = 'me-regs@example.com'
+	`, jobs.TypeUserEmailVerification).Scan(&userID); err != nil {
+		t.Fatalf("expected a verification job for the user: %v", err)
+	}
+
+	// the link the worker would email
+	cfg := testConfig()
+	verifyToken, _ := emailverify.NewSigner(cfg.EmailVerifySigningSecret(), cfg.EmailVerifyTTL()).Sign(userID, "me-regs@example.com")
+	w = doAnonymousJSONRequest(router, http.MethodPost, "/auth/verify-email", `{"token":"`+verifyToken+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify email got status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// verifying links the earlier anonymous registration straight away
+	w = doAuthedJSONRequest(router, http.MethodGet, "/me/registrations", "", token)
+	page = myRegistrationsPage{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	got := []string{}
+	for _, item := range page.Items {
+		got = append(got, item.EventID)
+	}
+	if len(got) != 3 || got[0] != sooner || got[1] != anonymous || got[2] != later {
+		t.Fatalf("expected the linked registration in start order, got %v", got)
+	}
+
+	// one made anonymously after verifying is left for claim
+	afterVerify := seedEventStartingAt(t, pool, 10, now.Add(96*time.Hour))
+	w = doAnonymousJSONRequest(router, http.MethodPost, "/events/"+afterVerify+"/register", `{"name":"Me","email":"me-regs@example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("anonymous register failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+	w = doAuthedJSONRequest(router, http.MethodPost, "/me/registrations/claim", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("claim got status=%d body=%s", w.Code, w.Body.String())
+	}
+	var claim struct {
+		Claimed int `json:"claimed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &claim); err != nil || claim.Claimed != 1 {
+		t.Fatalf("expected one claimed registration, got %s", w.Body.String())
+	}
+
+	// nothing left to claim
+	w = doAuthedJSONRequest(router, http.MethodPost, "/me/registrations/claim", "", token)
+	if err := json.Unmarshal(w.Body.Bytes(), &claim); err != nil || claim.Claimed != 0 {
+		t.Fatalf("expected nothing claimed the second time, got %s", w.Body.String())
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/geocoder89/eventhub/internal/config"
 	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/jobs"
 	"github.com/geocoder89/eventhub/internal/notifications"
 	"github.com/geocoder89/eventhub/internal/queue/worker"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
@@ -27,9 +28,18 @@ type recordingNotifier struct {
 }
 
 func (n *recordingNotifier) SendRegistrationConfirmation(ctx context.Context, input notifications.SendRegistrationConfirmationInput) error {
+	_, err := n.SendRegistrationConfirmationWithID(ctx, input)
+	return err
+}
+
+func (n *recordingNotifier) SendRegistrationConfirmationWithID(ctx context.Context, input notifications.SendRegistrationConfirmationInput) (string, error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.calls = append(n.calls, input)
+	return "msg-" + input.RegistrationID, nil
+}
+
+func (n *recordingNotifier) SendEventPublished(ctx context.Context, input notifications.SendEventPublishedInput) error {
 	return nil
 }
 
@@ -66,10 +76,12 @@ func setupPipelineRouter(t *testing.T) (*gin.Engine, *pgxpool.Pool, config.Confi
 		JWTAccessTTLMinutes: 60,
 		JWTRefreshTTLDays:   7,
 		RedisAddr:           "127.0.0.1:6379",
+		ExportsDir:          t.TempDir(),
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
-	router := apphttp.NewRouter(logger, pool, cfg)
+	router, stopRouter := apphttp.NewRouter(logger, pool, cfg)
+	t.Cleanup(stopRouter)
 	return router, pool, cfg
 }
 
@@ -81,8 +93,13 @@ func resetPipelineDB(t *testing.T, pool *pgxpool.Pool) {
 			refresh_tokens,
 			registrations,
 			jobs,
+			dead_letters,
 			events,
-			users
+			users,
+			privacy_audit,
+			webhooks,
+			paused_job_types,
+			recipient_quarantines
 		RESTART IDENTITY CASCADE
 	`)
 	if err != nil {
@@ -168,6 +185,7 @@ func TestPipeline_Register_EnqueuesJob_Worker_SendsOnce(t *testing.T) {
 		Concurrency:   1,
 		ShutdownGrace: 1 * time.Second,
 	}, jobsRepo, eventsRepo, rec, deliveriesRepo)
+	wk.Register(jobs.TypeRegistrationConfirmation, wk.HandleRegistrationConfirmation)
 
 	processed, err := wk.ProcessOne(context.Background())
 	if err != nil {
@@ -180,17 +198,21 @@ func TestPipeline_Register_EnqueuesJob_Worker_SendsOnce(t *testing.T) {
 	// 5) Assert side-effect (notification_deliveries sent + notifier called exactly once)
 	var deliveryStatus string
 	var sentAt *time.Time
+	var providerMessageID *string
 	err = pool.QueryRow(context.Background(), `
-		SELECT status, sent_at
+		SELECT status, sent_at, provider_message_id
 		FROM notification_deliveries
 		WHERE kind = 'registration.confirmation' AND registration_id = $1
-	`, reg.ID).Scan(&deliveryStatus, &sentAt)
+	`, reg.ID).Scan(&deliveryStatus, &sentAt, &providerMessageID)
 	if err != nil {
 		t.Fatalf("select notification delivery: %v", err)
 	}
 	if deliveryStatus != "sent" || sentAt == nil {
 		t.Fatalf("expected delivery sent, got status=%s sentAt=%v", deliveryStatus, sentAt)
 	}
+	if providerMessageID == nil || *providerMessageID != "msg-"+reg.ID {
+		t.Fatalf("expected the notifier's message id on the delivery, got %v", providerMessageID)
+	}
 
 	if rec.Count() != 1 {
 		t.Fatalf("expected notifier to be called once, got %d", rec.Count())
@@ -199,7 +221,7 @@ func TestPipeline_Register_EnqueuesJob_Worker_SendsOnce(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected last notifier call")
 	}
-	if last.Email != userEmail || last.RegistrationID != reg.ID || last.EventID != eventID {
+	if last.Email != userEmail || last.RegistrationID != reg.ID || last.EventID != eventID || last.EventTitle != "Test Event" {
 		t.Fatalf("unexpected notifier payload: %+v", last)
 	}
 
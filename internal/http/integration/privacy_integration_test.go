@@ -0,0 +1,103 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/privacy"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestPrivacyIntegration_EraseByEmail(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+	ctx := context.Background()
+
+	eventID := seedEvent(t, pool, 10)
+	adminToken := createAdminAuthToken(t, router, pool, "dpo@example.com")
+	userToken := signupAndGetToken(t, router, "leaving@example.com")
+	_ = signupAndGetToken(t, router, "staying@example.com")
+
+	registerPath := "/events/" + eventID + "/register"
+	for _, body := range []string{
+		`{"name":"Leaving User","email":"Leaving@Example.com"}`,
+		`{"name":"Staying User","email":"staying@example.com"}`,
+	} {
+		if w := doAuthedJSONRequest(router, http.MethodPost, registerPath, body, userToken); w.Code != http.StatusCreated {
+			t.Fatalf("register got %d body=%s", w.Code, w.Body.String())
+		}
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO notification_deliveries (kind, registration_id, job_id, recipient)
+		VALUES ('registration.confirmation', gen_random_uuid(), gen_random_uuid(), 'leaving@example.com')
+	`); err != nil {
+		t.Fatalf("seed delivery: %v", err)
+	}
+	var failedJobID string
+	if err := pool.QueryRow(ctx, `SELECT id FROM jobs WHERE payload::text ILIKE '%leaving@%' LIMIT 1`).Scan(&failedJobID); err != nil {
+		t.Fatalf("find job: %v", err)
+	}
+	if err := postgres.NewJobsRepo(pool, nil).MarkFailed(ctx, failedJobID, "smtp rejected leaving@example.com"); err != nil {
+		t.Fatalf("dead-letter job: %v", err)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodDelete, "/admin/privacy/users?email=LEAVING@example.com", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("erase got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Affected privacy.ErasureSummary `json:"affected"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// the staying registration was made from the leaving account, so it goes too
+	want := privacy.ErasureSummary{Users: 1, Registrations: 2, RefreshTokens: 1, NotificationDeliveries: 1, Jobs: 2, DeadLetters: 1}
+	if resp.Affected != want {
+		t.Fatalf("affected = %+v, want %+v", resp.Affected, want)
+	}
+
+	var leftovers, jobCount, registered int
+	err := pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE LOWER(email) = 'leaving@example.com') +
+			(SELECT COUNT(*) FROM registrations WHERE LOWER(email) = 'leaving@example.com') +
+			(SELECT COUNT(*) FROM notification_deliveries WHERE recipient ILIKE '%leaving@%') +
+			(SELECT COUNT(*) FROM jobs WHERE payload::text ILIKE '%leaving@%') +
+			(SELECT COUNT(*) FROM dead_letters WHERE payload::text ILIKE '%leaving@%' OR final_error ILIKE '%leaving@%') +
+			(SELECT COUNT(*) FROM admin_action_audits WHERE details::text ILIKE '%leaving@%'),
+			(SELECT COUNT(*) FROM jobs),
+			(SELECT registered_count FROM events WHERE id = $1)
+	`, eventID).Scan(&leftovers, &jobCount, &registered)
+	if err != nil {
+		t.Fatalf("check leftovers: %v", err)
+	}
+	if leftovers != 0 {
+		t.Fatalf("expected no trace of the address, found %d rows", leftovers)
+	}
+	if jobCount != 2 || registered != 0 {
+		t.Fatalf("expected jobs kept (2) and seats released (0), got jobs=%d registered=%d", jobCount, registered)
+	}
+
+	var digest, requestedBy string
+	if err := pool.QueryRow(ctx, `
+		SELECT subject_email_sha256, requested_by_email FROM privacy_audit
+	`).Scan(&digest, &requestedBy); err != nil {
+		t.Fatalf("select privacy_audit: %v", err)
+	}
+	if digest != privacy.EmailDigest("leaving@example.com") || requestedBy != "dpo@example.com" {
+		t.Fatalf("unexpected audit row digest=%s requestedBy=%s", digest, requestedBy)
+	}
+	if strings.Contains(digest, "@") {
+		t.Fatalf("audit must not store the address")
+	}
+
+	var stayingUsers int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email = 'staying@example.com'`).Scan(&stayingUsers); err != nil || stayingUsers != 1 {
+		t.Fatalf("other users must be untouched: count=%d err=%v", stayingUsers, err)
+	}
+}
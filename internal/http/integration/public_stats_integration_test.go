@@ -0,0 +1,69 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestEventCountersRepo_PlatformStatsExclusions(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	publish := func(id string) {
+		t.Helper()
+		if _, err := pool.Exec(ctx, `UPDATE events SET published_at = NOW() WHERE id = $1`, id); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	register := func(eventID, email, status string, duplicateOf *string) string {
+		t.Helper()
+		id := uuid.NewString()
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO registrations (id, event_id, name, email, status, duplicate_of, created_at, updated_at)
+			VALUES ($1, $2, 'Sam', $3, $4, $5, NOW(), NOW())
+		`, id, eventID, email, status, duplicateOf); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		return id
+	}
+
+	// counted: published this month and published next month
+	thisMonth := seedEventStartingAt(t, pool, 10, monthStart.Add(36*time.Hour))
+	publish(thisMonth)
+	nextMonth := seedEventStartingAt(t, pool, 10, monthEnd.Add(36*time.Hour))
+	publish(nextMonth)
+
+	kept := register(thisMonth, "a@example.com", "confirmed", nil)
+	register(thisMonth, "b@example.com", "waitlisted", nil)
+	register(nextMonth, "c@example.com", "confirmed", nil)
+	register(thisMonth, "d@example.com", "cancelled", nil)
+	register(thisMonth, "A@example.com", "confirmed", &kept)
+
+	// excluded outright, registrations and all
+	draft := seedEventStartingAt(t, pool, 10, monthStart.Add(36*time.Hour))
+	register(draft, "e@example.com", "confirmed", nil)
+	deleted := seedEventStartingAt(t, pool, 10, monthStart.Add(36*time.Hour))
+	publish(deleted)
+	register(deleted, "f@example.com", "confirmed", nil)
+	if _, err := pool.Exec(ctx, `UPDATE events SET deleted_at = NOW() WHERE id = $1`, deleted); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	stats, err := postgres.NewEventCountersRepo(pool, nil).PlatformStats(ctx, monthStart, monthEnd)
+	if err != nil {
+		t.Fatalf("platform stats: %v", err)
+	}
+	if stats.EventsHosted != 2 || stats.Registrations != 3 || stats.EventsThisMonth != 1 {
+		t.Fatalf("expected 2 events, 3 registrations, 1 this month; got %+v", stats)
+	}
+}
@@ -0,0 +1,113 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/google/uuid"
+)
+
+type publishBatchBody struct {
+	BatchID  string                    `json:"batchId"`
+	Enqueued int                       `json:"enqueued"`
+	Results  []event.PublishItemResult `json:"results"`
+	Error    struct {
+		Code string `json:"code"`
+	} `json:"error"`
+}
+
+func TestPublishBatch_AllOrNothing(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	token := createAdminAuthToken(t, router, pool, "batch-admin@example.com")
+
+	first, second, published := seedEvent(t, pool, 10), seedEvent(t, pool, 10), seedEvent(t, pool, 10)
+	if _, err := pool.Exec(ctx, `UPDATE events SET published_at = NOW() WHERE id = $1`, published); err != nil {
+		t.Fatalf("publish seed: %v", err)
+	}
+
+	countJobs := func() int {
+		var n int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE type = 'event.publish'`).Scan(&n); err != nil {
+			t.Fatalf("count jobs: %v", err)
+		}
+		return n
+	}
+
+	// one published and one unknown event sink the batch
+	body, _ := json.Marshal(map[string]any{"eventIds": []string{first, published, uuid.NewString()}})
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events/publish-batch", string(body), token)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if n := countJobs(); n != 0 {
+		t.Fatalf("expected nothing enqueued, got %d jobs", n)
+	}
+
+	body, _ = json.Marshal(map[string]any{"eventIds": []string{first, second}})
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/events/publish-batch", string(body), token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp publishBatchBody
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Enqueued != 2 {
+		t.Fatalf("expected 2 enqueued, got %+v", resp)
+	}
+
+	for _, res := range resp.Results {
+		var key string
+		if err := pool.QueryRow(ctx, `SELECT idempotency_key FROM jobs WHERE id = $1`, res.JobID).Scan(&key); err != nil {
+			t.Fatalf("job for %s: %v", res.EventID, err)
+		}
+		if want := "publish:batch:" + resp.BatchID + ":event:" + res.EventID; key != want {
+			t.Fatalf("idempotency key = %q, want %q", key, want)
+		}
+	}
+}
+
+func TestPublishBatch_Partial(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	token := createAdminAuthToken(t, router, pool, "batch-partial@example.com")
+
+	live := seedEvent(t, pool, 10)
+	deleted := seedEvent(t, pool, 10)
+	if _, err := pool.Exec(context.Background(), `UPDATE events SET deleted_at = NOW() WHERE id = $1`, deleted); err != nil {
+		t.Fatalf("delete seed: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]any{"eventIds": []string{deleted, live}})
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events/publish-batch?partial=true", string(body), token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp publishBatchBody
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Enqueued != 1 || len(resp.Results) != 2 ||
+		resp.Results[0].Status != event.PublishNotFound || resp.Results[1].Status != event.PublishEnqueued {
+		t.Fatalf("expected the deleted event skipped and the live one enqueued, got %+v", resp)
+	}
+
+	var payload string
+	if err := pool.QueryRow(context.Background(), `SELECT payload::text FROM jobs WHERE id = $1`, resp.Results[1].JobID).Scan(&payload); err != nil {
+		t.Fatalf("job: %v", err)
+	}
+	if !strings.Contains(payload, live) {
+		t.Fatalf("expected the job to publish %s, got %s", live, payload)
+	}
+}
@@ -11,9 +11,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/geocoder89/eventhub/internal/auth"
 	"github.com/geocoder89/eventhub/internal/config"
 	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/jobs"
 	"github.com/geocoder89/eventhub/internal/notifications"
 	"github.com/geocoder89/eventhub/internal/queue/worker"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
@@ -43,7 +43,8 @@ func setupPipelineTestRouter(t *testing.T) (*gin.Engine, *pgxpool.Pool, config.C
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
-	router := apphttp.NewRouter(logger, pool, cfg)
+	router, stopRouter := apphttp.NewRouter(logger, pool, cfg)
+	t.Cleanup(stopRouter)
 
 	return router, pool, cfg
 
@@ -68,7 +69,7 @@ func TestPublishPipeline_EndToEnd(t *testing.T) {
 
 	eventID := seedEvent(t, pool, 2)
 
-	jwtManager := auth.NewManager(cfg.JWTSecret, 60*time.Minute, 7*24*time.Hour)
+	jwtManager := apphttp.NewTokenManager(cfg)
 	adminID := uuid.NewString()
 	token, err := jwtManager.GenerateAccessToken(adminID, "admin@example.com", "admin")
 
@@ -100,6 +101,7 @@ func TestPublishPipeline_EndToEnd(t *testing.T) {
 		Concurrency:   1,
 		ShutdownGrace: 1 * time.Second,
 	}, jobsRepo, eventsRepo, notifier, deliveriesRepo)
+	wk.Register(jobs.TypeEventPublish, wk.HandleEventPublish)
 
 	processed, err := wk.ProcessOne(context.Background())
 
@@ -129,7 +131,7 @@ func TestPublishPipeline_IdempotentEnqueue(t *testing.T) {
 
 	eventID := seedEvent(t, pool, 2)
 
-	jwtManager := auth.NewManager(cfg.JWTSecret, 60*time.Minute, 7*24*time.Hour)
+	jwtManager := apphttp.NewTokenManager(cfg)
 	adminID := uuid.NewString()
 	token, err := jwtManager.GenerateAccessToken(adminID, "admin-idempotent@example.com", "admin")
 	if err != nil {
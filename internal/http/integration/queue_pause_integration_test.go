@@ -0,0 +1,120 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestQueuePause_SwitchIsSharedThroughTheRepo(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	adminToken := createAdminAuthToken(t, router, pool, "admin-queue-pause@example.com")
+	defer func() { _, _ = repo.SetPaused(ctx, false, "") }()
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/queue/pause", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("pause: got %d body=%s", w.Code, w.Body.String())
+	}
+	var first job.QueueState
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !first.Paused || first.PausedAt == nil || first.PausedBy == nil {
+		t.Fatalf("expected a paused state with who and when, got %+v", first)
+	}
+	if paused, err := repo.IsPaused(ctx); err != nil || !paused {
+		t.Fatalf("expected workers to see the pause, got paused=%t err=%v", paused, err)
+	}
+
+	// pausing twice keeps the original pause time
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/queue/pause", "", adminToken)
+	var again job.QueueState
+	if err := json.Unmarshal(w.Body.Bytes(), &again); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if again.PausedAt == nil || !again.PausedAt.Equal(*first.PausedAt) {
+		t.Fatalf("expected pausedAt kept, got %v then %v", first.PausedAt, again.PausedAt)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/queue/resume", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("resume: got %d body=%s", w.Code, w.Body.String())
+	}
+	if paused, err := repo.IsPaused(ctx); err != nil || paused {
+		t.Fatalf("expected workers to see the resume, got paused=%t err=%v", paused, err)
+	}
+}
+
+func TestQueuePause_PausedTypeStaysPendingUntilResumed(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewJobsRepo(pool, nil)
+	adminToken := createAdminAuthToken(t, router, pool, "admin-type-pause@example.com")
+
+	due := time.Now().UTC().Add(-time.Second)
+	confirmation, err := repo.Create(ctx, job.CreateRequest{Type: "registration.confirmation", RunAt: due, Priority: 5})
+	if err != nil {
+		t.Fatalf("seed confirmation: %v", err)
+	}
+	if _, err := repo.Create(ctx, job.CreateRequest{Type: "event.publish", RunAt: due}); err != nil {
+		t.Fatalf("seed publish: %v", err)
+	}
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/queue/types/registration.confirmation/pause", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("pause type: got %d body=%s", w.Code, w.Body.String())
+	}
+	var st job.QueueState
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if st.Paused || len(st.PausedTypes) != 1 || st.PausedTypes[0].Type != "registration.confirmation" || st.PausedTypes[0].PausedBy == nil {
+		t.Fatalf("expected only the type paused, got %+v", st)
+	}
+
+	// what a worker does: exclude the paused set it read
+	paused, err := repo.PausedTypes(ctx)
+	if err != nil || len(paused) != 1 {
+		t.Fatalf("paused types: %+v err=%v", paused, err)
+	}
+	batch, err := repo.ClaimBatchExcluding(ctx, "worker-a", 10, []string{paused[0].Type})
+	if err != nil || len(batch) != 1 || batch[0].Type != "event.publish" {
+		t.Fatalf("expected only the publish claimed despite its lower priority, got %+v err=%v", batch, err)
+	}
+	if _, err := repo.ClaimNext(ctx, "worker-a"); !errors.Is(err, job.ErrJobNotFound) {
+		t.Fatalf("expected ClaimNext to skip the paused type, got %v", err)
+	}
+
+	untouched, err := repo.GetByID(ctx, confirmation.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	// Postgres keeps microseconds, so compare at that precision
+	if untouched.Status != job.StatusPending || untouched.Attempts != 0 || untouched.LockedBy != nil ||
+		untouched.UpdatedAt.Sub(confirmation.UpdatedAt).Abs() > time.Microsecond {
+		t.Fatalf("expected the paused job untouched, got %+v", untouched)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/queue/types/registration.confirmation/resume", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("resume type: got %d body=%s", w.Code, w.Body.String())
+	}
+	claimed, err := repo.ClaimNext(ctx, "worker-a")
+	if err != nil || claimed.ID != confirmation.ID {
+		t.Fatalf("expected the confirmation claimed after resume, got %s err=%v", claimed.ID, err)
+	}
+}
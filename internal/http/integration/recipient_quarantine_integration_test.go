@@ -0,0 +1,73 @@
+package integration__test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestRecipientQuarantines_ThresholdWindowAndLift(t *testing.T) {
+	_, pool := setupTestRouter(t)
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `DELETE FROM recipient_quarantines`); err != nil {
+		t.Fatalf("reset recipient_quarantines: %v", err)
+	}
+	defer func() { _, _ = pool.Exec(ctx, `DELETE FROM recipient_quarantines`) }()
+
+	repo := postgres.NewRecipientQuarantinesRepo(pool, nil)
+	policy := notificationsdelivery.QuarantinePolicy{Threshold: 3, Window: time.Hour}
+
+	record := func(email string) bool {
+		t.Helper()
+		quarantined, err := repo.RecordPermanentFailure(ctx, email, "mailbox does not exist", policy)
+		if err != nil {
+			t.Fatalf("record %s: %v", email, err)
+		}
+		return quarantined
+	}
+
+	// failures outside the window start the count over
+	if record("Bounce@Example.com") || record("bounce@example.com") {
+		t.Fatalf("expected no quarantine below the threshold")
+	}
+	if _, err := pool.Exec(ctx, `UPDATE recipient_quarantines SET window_started_at = NOW() - INTERVAL '2 hours'`); err != nil {
+		t.Fatalf("age window: %v", err)
+	}
+	if record("bounce@example.com") || record("bounce@example.com") {
+		t.Fatalf("expected the aged window to reset the count")
+	}
+
+	if !record(" bounce@example.com ") {
+		t.Fatalf("expected the third failure in the window to quarantine")
+	}
+	if record("bounce@example.com") {
+		t.Fatalf("expected only the quarantining failure to report it")
+	}
+
+	quarantined, err := repo.IsQuarantined(ctx, "BOUNCE@example.com")
+	if err != nil || !quarantined {
+		t.Fatalf("IsQuarantined = %t, %v; want true", quarantined, err)
+	}
+	list, err := repo.ListQuarantined(ctx)
+	if err != nil || len(list) != 1 || list[0].Email != "bounce@example.com" || list[0].Failures != 4 {
+		t.Fatalf("ListQuarantined = %+v, %v", list, err)
+	}
+
+	if err := repo.Lift(ctx, "bounce@example.com"); err != nil {
+		t.Fatalf("lift: %v", err)
+	}
+	if err := repo.Lift(ctx, "bounce@example.com"); !errors.Is(err, notificationsdelivery.ErrQuarantineNotFound) {
+		t.Fatalf("expected ErrQuarantineNotFound lifting twice, got %v", err)
+	}
+	if quarantined, _ := repo.IsQuarantined(ctx, "bounce@example.com"); quarantined {
+		t.Fatalf("expected the lifted recipient to be deliverable")
+	}
+	if record("bounce@example.com") {
+		t.Fatalf("expected a lift to forget earlier failures")
+	}
+}
@@ -0,0 +1,153 @@
+package integration__test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/canceltoken"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+)
+
+func doCancelByToken(router http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/registrations/cancel?token="+url.QueryEscape(token), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCancelByTokenIntegration(t *testing.T) {
+	cfg := testConfig()
+	router, pool := setupTestRouterWithConfig(t, cfg)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 1)
+	token := signupAndGetToken(t, router, "attendee@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Attendee","email":"attendee@example.com"}`, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register got %d body=%s", w.Code, w.Body.String())
+	}
+	var reg registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+		t.Fatalf("decode register: %v", err)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Next Up","email":"next@example.com","joinWaitlist":true}`, token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("waitlist register got %d body=%s", w.Code, w.Body.String())
+	}
+	var waitlisted registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &waitlisted); err != nil {
+		t.Fatalf("decode waitlisted: %v", err)
+	}
+
+	signer := canceltoken.NewSigner(cfg.CancelTokenSigningSecret(), time.Hour)
+
+	if w := doCancelByToken(router, canceltoken.NewSigner("not-the-secret", time.Hour).Sign(reg.ID, eventID)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered token: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doCancelByToken(router, canceltoken.NewSigner(cfg.CancelTokenSigningSecret(), -time.Minute).Sign(reg.ID, eventID)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expired token: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	cancelToken := signer.Sign(reg.ID, eventID)
+	if w := doCancelByToken(router, cancelToken); w.Code != http.StatusNoContent {
+		t.Fatalf("cancel got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doCancelByToken(router, cancelToken); w.Code != http.StatusNotFound {
+		t.Fatalf("second cancel got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var status string
+	if err := pool.QueryRow(t.Context(), `SELECT status FROM registrations WHERE id = $1`, waitlisted.ID).Scan(&status); err != nil {
+		t.Fatalf("select waitlisted: %v", err)
+	}
+	if status != registration.StatusConfirmed {
+		t.Fatalf("expected waitlisted registration to be promoted, got %s", status)
+	}
+}
+
+func TestCancelRegistrationIntegration_KeepsHistory(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 5)
+	token := signupAndGetToken(t, router, "keeper@example.com")
+
+	register := func() registration.Registration {
+		t.Helper()
+		w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Keeper","email":"keeper@example.com"}`, token)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("register got %d body=%s", w.Code, w.Body.String())
+		}
+		var reg registration.Registration
+		if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+			t.Fatalf("decode register: %v", err)
+		}
+		return reg
+	}
+
+	first := register()
+
+	path := "/events/" + eventID + "/registrations/" + first.ID
+	if w := doAuthedJSONRequest(router, http.MethodDelete, path, "", token); w.Code != http.StatusNoContent {
+		t.Fatalf("cancel got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doAuthedJSONRequest(router, http.MethodDelete, path, "", token); w.Code != http.StatusConflict {
+		t.Fatalf("second cancel got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var status string
+	var cancelledAt *time.Time
+	var registered int
+	err := pool.QueryRow(t.Context(), `
+		SELECT r.status, r.cancelled_at, e.registered_count
+		FROM registrations r
+		JOIN events e ON e.id = r.event_id
+		WHERE r.id = $1
+	`, first.ID).Scan(&status, &cancelledAt, &registered)
+	if err != nil {
+		t.Fatalf("select cancelled registration: %v", err)
+	}
+	if status != registration.StatusCancelled || cancelledAt == nil {
+		t.Fatalf("expected row kept as cancelled, got status=%s cancelledAt=%v", status, cancelledAt)
+	}
+	if registered != 0 {
+		t.Fatalf("expected the seat to be released, registered_count=%d", registered)
+	}
+
+	// the same email may sign up again once cancelled
+	second := register()
+	if second.ID == first.ID {
+		t.Fatalf("expected a new registration")
+	}
+
+	list := func(query string) []registration.Registration {
+		t.Helper()
+		w := doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registrations"+query, "", token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("list got %d body=%s", w.Code, w.Body.String())
+		}
+		var page struct {
+			Items []registration.Registration `json:"items"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("decode list: %v", err)
+		}
+		return page.Items
+	}
+
+	if items := list(""); len(items) != 1 || items[0].ID != second.ID {
+		t.Fatalf("expected only the active registration by default, got %+v", items)
+	}
+	items := list("?includeCancelled=true")
+	if len(items) != 2 || items[0].Status != registration.StatusCancelled || items[0].CancelledAt == nil {
+		t.Fatalf("expected cancelled history with includeCancelled=true, got %+v", items)
+	}
+}
@@ -0,0 +1,114 @@
+package integration__test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/canceltoken"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+)
+
+func TestCancelRegistrationIntegration_RecordsActorAndReason(t *testing.T) {
+	cfg := testConfig()
+	router, pool := setupTestRouterWithConfig(t, cfg)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 5)
+	token := signupAndGetToken(t, router, "reasons@example.com")
+	adminToken := createAdminAuthToken(t, router, pool, "admin-reasons@example.com")
+
+	register := func(email string) registration.Registration {
+		t.Helper()
+		w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Attendee","email":"`+email+`"}`, token)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("register got %d body=%s", w.Code, w.Body.String())
+		}
+		var reg registration.Registration
+		if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+			t.Fatalf("decode register: %v", err)
+		}
+		return reg
+	}
+
+	own := register("own@example.com")
+	byAdmin := register("by-admin@example.com")
+	byLink := register("by-link@example.com")
+
+	if w := doAuthedJSONRequest(router, http.MethodDelete, "/events/"+eventID+"/registrations/"+own.ID, `{"reason":"cannot make it"}`, token); w.Code != http.StatusNoContent {
+		t.Fatalf("owner cancel got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doAuthedJSONRequest(router, http.MethodDelete, "/events/"+eventID+"/registrations/"+byAdmin.ID, `{"reason":"duplicate sign-up"}`, adminToken); w.Code != http.StatusNoContent {
+		t.Fatalf("admin cancel got %d body=%s", w.Code, w.Body.String())
+	}
+
+	signer := canceltoken.NewSigner(cfg.CancelTokenSigningSecret(), time.Hour)
+	req := httptest.NewRequest(http.MethodDelete, "/registrations/cancel?token="+url.QueryEscape(signer.Sign(byLink.ID, eventID)), strings.NewReader(`{"reason":"plans changed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("token cancel got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var adminID string
+	if err := pool.QueryRow(t.Context(), `SELECT id FROM users WHERE email = $1`, "admin-reasons@example.com").Scan(&adminID); err != nil {
+		t.Fatalf("read admin id: %v", err)
+	}
+	want := map[string]registration.Cancellation{
+		own.ID:     {By: registration.CancelledBySelf, Reason: "cannot make it"},
+		byAdmin.ID: {By: adminID, Reason: "duplicate sign-up"},
+		byLink.ID:  {By: registration.CancelledBySelf, Reason: "plans changed"},
+	}
+
+	// the organizer view carries them
+	w = doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registrations?includeCancelled=true", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list got %d body=%s", w.Code, w.Body.String())
+	}
+	var page struct {
+		Items []registration.Registration `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(page.Items) != len(want) {
+		t.Fatalf("expected %d registrations, got %d", len(want), len(page.Items))
+	}
+	for _, r := range page.Items {
+		got := registration.Cancellation{By: r.CancelledBy, Reason: r.CancellationReason}
+		if got != want[r.ID] {
+			t.Fatalf("registration %s: got %+v, want %+v", r.ID, got, want[r.ID])
+		}
+	}
+
+	// so does the cancelled history entry
+	w = doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registrations/"+byAdmin.ID+"/history", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("history got %d body=%s", w.Code, w.Body.String())
+	}
+	var history struct {
+		Items []registration.HistoryEntry `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	last := history.Items[len(history.Items)-1]
+	if last.Kind != registration.HistoryCancelled || last.CancelledBy != adminID || last.CancellationReason != "duplicate sign-up" {
+		t.Fatalf("unexpected cancelled history entry %+v", last)
+	}
+
+	// the attendee's own listing does not
+	w = doAuthedJSONRequest(router, http.MethodGet, "/me/registrations", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("me/registrations got %d body=%s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "cancelledBy") || strings.Contains(w.Body.String(), "cancellationReason") {
+		t.Fatalf("expected no cancellation details for the attendee, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package integration__test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestRegisterIntegration_ConcurrentSignupsDoNotOverbook(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	const capacity = 10
+	const attempts = 50
+
+	ctx := context.Background()
+	eventID := seedEvent(t, pool, capacity)
+	repo := postgres.NewRegistrationsRepo(pool, nil)
+
+	var confirmed, full atomic.Int64
+	var g errgroup.Group
+	for i := 0; i < attempts; i++ {
+		g.Go(func() error {
+			_, err := repo.Create(ctx, registration.CreateRegistrationRequest{
+				EventID: eventID,
+				Name:    fmt.Sprintf("Racer %d", i),
+				Email:   fmt.Sprintf("racer%d@example.com", i),
+			})
+			switch {
+			case err == nil:
+				confirmed.Add(1)
+			case errors.Is(err, registration.ErrEventFull):
+				full.Add(1)
+			default:
+				return fmt.Errorf("register %d: %w", i, err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if confirmed.Load() != capacity || full.Load() != attempts-capacity {
+		t.Fatalf("expected %d confirmed and %d full, got %d and %d", capacity, attempts-capacity, confirmed.Load(), full.Load())
+	}
+
+	var seats, registeredCount int
+	err := pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(quantity), 0) FROM registrations WHERE event_id = $1 AND status = 'confirmed'),
+			registered_count
+		FROM events WHERE id = $1
+	`, eventID).Scan(&seats, &registeredCount)
+	if err != nil {
+		t.Fatalf("count seats: %v", err)
+	}
+	if seats != capacity || registeredCount != capacity {
+		t.Fatalf("expected %d seats taken and counted, got seats=%d registered_count=%d", capacity, seats, registeredCount)
+	}
+}
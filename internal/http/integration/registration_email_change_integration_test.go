@@ -0,0 +1,131 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// drainConfirmations runs every due confirmation through a worker sending to rec.
+func drainConfirmations(t *testing.T, pool *pgxpool.Pool, rec *recordingNotifier) {
+	t.Helper()
+
+	wk := worker.New(worker.Config{
+		PollInterval:  10 * time.Millisecond,
+		WorkerID:      "email-change-worker",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+	}, postgres.NewJobsRepo(pool, nil), postgres.NewEventsRepo(pool, nil), rec, postgres.NewNotificationsDeliveriesRepo(pool))
+	wk.Register(jobs.TypeRegistrationConfirmation, wk.HandleRegistrationConfirmation)
+
+	for i := 0; i < 20; i++ {
+		processed, err := wk.ProcessOne(context.Background())
+		if err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		if !processed {
+			return
+		}
+	}
+	t.Fatalf("confirmations did not drain")
+}
+
+func registrationIDByEmail(t *testing.T, pool *pgxpool.Pool, eventID, email string) string {
+	t.Helper()
+
+	var id string
+	if err := pool.QueryRow(context.Background(), `
+		SELECT id FROM registrations WHERE event_id = $1 AND email = $2
+	`, eventID, email).Scan(&id); err != nil {
+		t.Fatalf("find registration %s: %v", email, err)
+	}
+	return id
+}
+
+func TestRegistrationEmailChange_ConflictAndResend(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	adminToken := createAdminAuthToken(t, router, pool, "email-change-admin@example.com")
+	eventID := seedEvent(t, pool, 10)
+
+	registerFrom(t, router, eventID, 1)
+	registerFrom(t, router, eventID, 2)
+	sentID := registrationIDByEmail(t, pool, eventID, "guest1@example.com")
+
+	rec := &recordingNotifier{}
+	drainConfirmations(t, pool, rec)
+	if rec.Count() != 2 {
+		t.Fatalf("expected both original confirmations sent, got %d", rec.Count())
+	}
+
+	patch := func(regID, email string) *httptest.ResponseRecorder {
+		return doAuthedJSONRequest(router, http.MethodPatch,
+			"/events/"+eventID+"/registrations/"+regID+"/email", `{"email":"`+email+`"}`, adminToken)
+	}
+
+	// another registration of the event already holds the address
+	if w := patch(sentID, "GUEST2@example.com"); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a taken email, got %d body=%s", w.Code, w.Body.String())
+	}
+	if got := registrationIDByEmail(t, pool, eventID, "guest1@example.com"); got != sentID {
+		t.Fatalf("the conflicting change must leave the registration alone")
+	}
+
+	w := patch(sentID, "Fixed1@Example.com")
+	if w.Code != http.StatusOK {
+		t.Fatalf("change email got %d body=%s", w.Code, w.Body.String())
+	}
+	var reg registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if reg.Email != "fixed1@example.com" {
+		t.Fatalf("expected the normalized new email, got %q", reg.Email)
+	}
+	assertHistory(t, pool, sentID, registration.HistoryCreated, registration.HistoryEmailChanged)
+
+	// the already-sent delivery is reset, so the corrected address gets one
+	drainConfirmations(t, pool, rec)
+	if rec.Count() != 3 {
+		t.Fatalf("expected one resend, got %d sends in total", rec.Count())
+	}
+	if last, _ := rec.Last(); last.Email != "fixed1@example.com" || last.RegistrationID != sentID {
+		t.Fatalf("expected the resend to the corrected address, got %+v", last)
+	}
+
+	// a confirmation still queued for the old address is cancelled instead
+	registerFrom(t, router, eventID, 3)
+	queuedID := registrationIDByEmail(t, pool, eventID, "guest3@example.com")
+	if w := patch(queuedID, "fixed3@example.com"); w.Code != http.StatusOK {
+		t.Fatalf("change queued email got %d body=%s", w.Code, w.Body.String())
+	}
+	drainConfirmations(t, pool, rec)
+	if rec.Count() != 4 {
+		t.Fatalf("expected exactly one send for the queued registration, got %d sends in total", rec.Count())
+	}
+	if last, _ := rec.Last(); last.Email != "fixed3@example.com" {
+		t.Fatalf("the old address must receive nothing, last send went to %s", last.Email)
+	}
+
+	var cancelled int
+	if err := pool.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM jobs
+		WHERE type = $1 AND status = 'cancelled' AND payload->>'registrationId' = $2
+	`, jobs.TypeRegistrationConfirmation, queuedID).Scan(&cancelled); err != nil {
+		t.Fatalf("count cancelled: %v", err)
+	}
+	if cancelled != 1 {
+		t.Fatalf("expected the old confirmation cancelled, got %d", cancelled)
+	}
+}
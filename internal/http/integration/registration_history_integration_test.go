@@ -0,0 +1,169 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// historyKinds returns the recorded transitions of registrationID, oldest first.
+func historyKinds(t *testing.T, pool *pgxpool.Pool, registrationID string) []string {
+	t.Helper()
+
+	rows, err := pool.Query(context.Background(), `
+		SELECT kind FROM registration_events WHERE registration_id = $1 ORDER BY occurred_at, id
+	`, registrationID)
+	if err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	defer rows.Close()
+
+	var kinds []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			t.Fatalf("scan history: %v", err)
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds
+}
+
+func assertHistory(t *testing.T, pool *pgxpool.Pool, registrationID string, want ...string) {
+	t.Helper()
+
+	got := historyKinds(t, pool, registrationID)
+	if len(got) != len(want) {
+		t.Fatalf("registration %s: expected history %v, got %v", registrationID, want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("registration %s: expected history %v, got %v", registrationID, want, got)
+		}
+	}
+}
+
+func TestRegistrationHistory_OneRowPerTransition(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	repo := postgres.NewRegistrationsRepo(pool, nil)
+	eventID := seedEvent(t, pool, 1)
+
+	// Create: confirmed, then a waitlisted sign-up
+	first, err := repo.Create(ctx, registration.CreateRegistrationRequest{EventID: eventID, Name: "First", Email: "first@example.com"})
+	if err != nil {
+		t.Fatalf("create first: %v", err)
+	}
+	second, err := repo.Create(ctx, registration.CreateRegistrationRequest{EventID: eventID, Name: "Second", Email: "second@example.com", JoinWaitlist: true})
+	if err != nil || second.Status != registration.StatusWaitlisted {
+		t.Fatalf("create second: status=%s err=%v", second.Status, err)
+	}
+	assertHistory(t, pool, first.ID, registration.HistoryCreated)
+	assertHistory(t, pool, second.ID, registration.HistoryWaitlisted)
+
+	// a rejected sign-up records nothing
+	if _, err := repo.Create(ctx, registration.CreateRegistrationRequest{EventID: eventID, Name: "First", Email: "first@example.com"}); err == nil {
+		t.Fatalf("expected the duplicate sign-up to fail")
+	}
+
+	// Cancel: the freed seat promotes the waitlisted registration in the same tx
+	if err := repo.Cancel(ctx, eventID, first.ID, registration.Cancellation{By: registration.CancelledBySelf}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if err := repo.Cancel(ctx, eventID, first.ID, registration.Cancellation{By: registration.CancelledBySelf}); err == nil {
+		t.Fatalf("expected the second cancel to fail")
+	}
+	assertHistory(t, pool, first.ID, registration.HistoryCreated, registration.HistoryCancelled)
+	assertHistory(t, pool, second.ID, registration.HistoryWaitlisted, registration.HistoryPromoted)
+
+	// CheckIn and CheckInByToken, each only once
+	if _, err := repo.CheckIn(ctx, eventID, second.ID); err != nil {
+		t.Fatalf("check in: %v", err)
+	}
+	if _, err := repo.CheckIn(ctx, eventID, second.ID); err == nil {
+		t.Fatalf("expected the second check-in to fail")
+	}
+	assertHistory(t, pool, second.ID, registration.HistoryWaitlisted, registration.HistoryPromoted, registration.HistoryCheckedIn)
+
+	other := seedEvent(t, pool, 10)
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	imported, err := repo.ImportTx(ctx, tx, other, []registration.ImportRow{
+		{Name: "Imported One", Email: "one@example.com"},
+		{Name: "Imported Two", Email: "two@example.com"},
+	}, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit import: %v", err)
+	}
+	for _, r := range imported {
+		assertHistory(t, pool, r.ID, registration.HistoryCreated)
+	}
+
+	if _, err := repo.CheckInByToken(ctx, other, imported[0].CheckInToken); err != nil {
+		t.Fatalf("check in by token: %v", err)
+	}
+	assertHistory(t, pool, imported[0].ID, registration.HistoryCreated, registration.HistoryCheckedIn)
+
+	// FinalizeEvent: the one who never showed up
+	if _, err := pool.Exec(ctx, `UPDATE events SET start_at = NOW() - INTERVAL '1 day' WHERE id = $1`, other); err != nil {
+		t.Fatalf("move event: %v", err)
+	}
+	if _, err := postgres.NewAttendanceRepo(pool, nil).FinalizeEvent(ctx, other); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	assertHistory(t, pool, imported[1].ID, registration.HistoryCreated, registration.HistoryNoShow)
+	assertHistory(t, pool, imported[0].ID, registration.HistoryCreated, registration.HistoryCheckedIn)
+
+	// the organizer reads it back through the API
+	adminToken := createAdminAuthToken(t, router, pool, "admin-history@example.com")
+	w := doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registrations/"+second.ID+"/history", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("history: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var history struct {
+		Items []registration.HistoryEntry `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(history.Items) != 3 || history.Items[1].FromStatus != registration.StatusWaitlisted || history.Items[1].ToStatus != registration.StatusConfirmed {
+		t.Fatalf("unexpected history: %s", w.Body.String())
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registration-activity?limit=2", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("activity: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var page struct {
+		Items      []registration.HistoryEntry `json:"items"`
+		HasMore    bool                        `json:"hasMore"`
+		NextCursor *string                     `json:"nextCursor"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode activity: %v", err)
+	}
+	if len(page.Items) != 2 || !page.HasMore || page.NextCursor == nil {
+		t.Fatalf("expected a first page of 2 with more, got %s", w.Body.String())
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registration-activity?limit=10&cursor="+*page.NextCursor, "", adminToken)
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode activity page 2: %v", err)
+	}
+	if w.Code != http.StatusOK || len(page.Items) != 3 || page.HasMore {
+		t.Fatalf("expected the remaining 3 transitions, got status=%d body=%s", w.Code, w.Body.String())
+	}
+}
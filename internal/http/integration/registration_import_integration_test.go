@@ -0,0 +1,166 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type importResponse struct {
+	Results []registration.ImportRowResult `json:"results"`
+	Counts  registration.ImportCounts      `json:"counts"`
+}
+
+func TestImportRegistrationsIntegration_CSVOverCapacity(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 2)
+	adminToken := createAdminAuthToken(t, router, pool, "importer@example.com")
+	importPath := "/admin/events/" + eventID + "/registrations/import"
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Early Bird","email":"early@example.com"}`, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register got %d body=%s", w.Code, w.Body.String())
+	}
+
+	csvBody := "name,email\nAda Lovelace,ada@example.com\nEarly Again,EARLY@example.com\nGrace Hopper,grace@example.com\nAda Twice,ada@example.com\n"
+
+	// one seat left and two new attendees: refused without the override
+	w = doAuthedCSVRequest(router, importPath, csvBody, adminToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without overrideCapacity, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doAuthedCSVRequest(router, importPath+"?overrideCapacity=true", csvBody, adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp importResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode import: %v", err)
+	}
+	if resp.Counts != (registration.ImportCounts{Created: 2, Duplicate: 2}) {
+		t.Fatalf("unexpected counts: %+v", resp.Counts)
+	}
+
+	var registered, confirmations int
+	if err := pool.QueryRow(context.Background(), `SELECT registered_count FROM events WHERE id = $1`, eventID).Scan(&registered); err != nil {
+		t.Fatalf("select registered_count: %v", err)
+	}
+	if registered != 3 {
+		t.Fatalf("expected registered_count 3 past capacity, got %d", registered)
+	}
+	if err := pool.QueryRow(context.Background(), `SELECT COUNT(*) FROM jobs WHERE type = $1`, jobs.TypeRegistrationConfirmation).Scan(&confirmations); err != nil {
+		t.Fatalf("count jobs: %v", err)
+	}
+	if confirmations != 1 {
+		t.Fatalf("expected only the direct sign-up to be confirmed, got %d jobs", confirmations)
+	}
+}
+
+func TestImportRegistrationsIntegration_SendConfirmations(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 10)
+	adminToken := createAdminAuthToken(t, router, pool, "importer@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events/"+eventID+"/registrations/import?sendConfirmations=true",
+		`[{"name":"Ada Lovelace","email":"ada@example.com"},{"name":"Grace Hopper","email":"grace@example.com"},{"name":"?","email":"nope"}]`, adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp importResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode import: %v", err)
+	}
+	if resp.Counts != (registration.ImportCounts{Created: 2, Invalid: 1}) {
+		t.Fatalf("unexpected counts: %+v", resp.Counts)
+	}
+
+	var confirmations int
+	err := pool.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM jobs
+		WHERE type = $1 AND idempotency_key = ANY($2)
+	`, jobs.TypeRegistrationConfirmation, []string{
+		"registration:confirm:" + resp.Results[0].RegistrationID,
+		"registration:confirm:" + resp.Results[1].RegistrationID,
+	}).Scan(&confirmations)
+	if err != nil {
+		t.Fatalf("count jobs: %v", err)
+	}
+	if confirmations != 2 {
+		t.Fatalf("expected 2 confirmation jobs, got %d", confirmations)
+	}
+}
+
+func TestImportRegistrationsIntegration_QueuesCreatedWebhooks(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	eventID := seedEvent(t, pool, 10)
+	adminToken := createAdminAuthToken(t, router, pool, "importer@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/webhooks",
+		`{"url":"https://crm.example.com/hooks","eventKinds":["registration.created"]}`, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create webhook: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var hook struct {
+		Webhook webhook.Webhook `json:"webhook"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &hook); err != nil {
+		t.Fatalf("decode webhook: %v", err)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/events/"+eventID+"/registrations/import",
+		`[{"name":"Ada Lovelace","email":"ada@example.com"},{"name":"Grace Hopper","email":"grace@example.com"}]`, adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp importResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode import: %v", err)
+	}
+	if resp.Counts.Created != 2 {
+		t.Fatalf("unexpected counts: %+v", resp.Counts)
+	}
+
+	// one delivery per imported registration, as a sign-up would queue
+	var deliveries int
+	err := pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM jobs
+		WHERE type = $1 AND idempotency_key = ANY($2)
+	`, jobs.TypeWebhookDeliver, []string{
+		jobs.WebhookDeliverKey(hook.Webhook.ID, webhook.KindRegistrationCreated, resp.Results[0].RegistrationID),
+		jobs.WebhookDeliverKey(hook.Webhook.ID, webhook.KindRegistrationCreated, resp.Results[1].RegistrationID),
+	}).Scan(&deliveries)
+	if err != nil {
+		t.Fatalf("count jobs: %v", err)
+	}
+	if deliveries != 2 {
+		t.Fatalf("expected 2 registration.created deliveries, got %d", deliveries)
+	}
+}
+
+func doAuthedCSVRequest(router http.Handler, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
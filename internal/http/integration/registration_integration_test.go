@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/db"
 	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -29,6 +31,8 @@ func testConfig() config.Config {
 		AdminRole:           "admin",
 		JWTSecret:           "test-secret-key", // deterministic test secret
 		JWTAccessTTLMinutes: 60,
+		QueryBudget:         10,
+		QueryBudgetHardFail: true,
 	}
 }
 
@@ -43,13 +47,26 @@ type apiErrorResponse struct {
 
 func setupTestRouter(t *testing.T) (*gin.Engine, *pgxpool.Pool) {
 	t.Helper()
+
+	return setupTestRouterWithConfig(t, testConfig())
+}
+
+func setupTestRouterWithConfig(t *testing.T, cfg config.Config) (*gin.Engine, *pgxpool.Pool) {
+	t.Helper()
 	gin.SetMode(gin.TestMode)
 
 	dsn := requiredTestDBDSN(t)
 
 	ctx := context.Background()
 
-	pool, err := pgxpool.New(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("Failed to parse DSN: %v", err)
+	}
+	// count queries so the hard-fail query budget catches N+1 regressions
+	poolCfg.ConnConfig.Tracer = db.QueryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 
 	if err != nil {
 		t.Fatalf("Failed to create pgx pool: %v", err)
@@ -60,9 +77,8 @@ func setupTestRouter(t *testing.T) (*gin.Engine, *pgxpool.Pool) {
 		Level: slog.LevelDebug,
 	}))
 
-	cfg := testConfig()
-
-	router := apphttp.NewRouter(logger, pool, cfg)
+	router, stopRouter := apphttp.NewRouter(logger, pool, cfg)
+	t.Cleanup(stopRouter)
 
 	return router, pool
 }
@@ -124,9 +140,14 @@ func resetDB(t *testing.T, pool *pgxpool.Pool) {
 
 func seedEvent(t *testing.T, pool *pgxpool.Pool, capacity int) string {
 	t.Helper()
+
+	return seedEventStartingAt(t, pool, capacity, time.Now().UTC().Add(24*time.Hour)) // start at is 24 hours from our current time.
+}
+
+func seedEventStartingAt(t *testing.T, pool *pgxpool.Pool, capacity int, startAt time.Time) string {
+	t.Helper()
 	id := uuid.NewString()
 	now := time.Now().UTC()
-	startAt := now.Add(24 * time.Hour) // start at is 24 hours from our current time.
 
 	_, err := pool.Exec(
 		context.Background(),
@@ -247,6 +268,49 @@ func TestRegisterIntegration_DuplicateEmail(t *testing.T) {
 	}
 }
 
+func TestRegisterIntegration_DuplicateEmailDifferentCase(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := seedEvent(t, pool, 5)
+
+	w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Sam Doe","email":"Sam@Example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("[first call] got status %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var stored string
+	if err := pool.QueryRow(context.Background(), `SELECT email FROM registrations WHERE event_id = $1`, eventID).Scan(&stored); err != nil {
+		t.Fatalf("select email: %v", err)
+	}
+	if stored != "sam@example.com" {
+		t.Fatalf("expected email stored lowercased, got %q", stored)
+	}
+
+	w = doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Sam Doe","email":"sam@example.com"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("[second call] got status %d, want %d, body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+
+	var response apiErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal error response: %v", err)
+	}
+	if response.Error.Code != "already_registered" {
+		t.Fatalf("expected error code 'already_registered' got '%s'", response.Error.Code)
+	}
+
+	// the index itself rejects a mixed-case row that skips the application check
+	_, err := pool.Exec(context.Background(), `
+		INSERT INTO registrations (id, event_id, name, email, check_in_token, created_at, updated_at)
+		VALUES ($1, $2, 'Sam Doe', 'SAM@EXAMPLE.COM', $3, NOW(), NOW())
+	`, uuid.NewString(), eventID, "chk_"+uuid.NewString())
+	if !postgres.IsUniqueViolation(err) {
+		t.Fatalf("expected unique violation for mixed-case duplicate insert, got %v", err)
+	}
+}
+
 func TestRegisterIntegration_EventFull(t *testing.T) {
 	router, pool := setupTestRouter(t)
 
@@ -311,3 +375,45 @@ func TestRegisterIntegration_EventNotFound(t *testing.T) {
 	}
 
 }
+
+func TestRegisterIntegration_PastEventReturnsGone(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := seedEventStartingAt(t, pool, 10, time.Now().UTC().Add(-2*time.Hour))
+
+	token := signupAndGetToken(t, router, "late@example.com")
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Late Comer","email":"late@example.com"}`, token)
+
+	if w.Code != http.StatusGone {
+		t.Fatalf("got status %d, want %d, body=%s", w.Code, http.StatusGone, w.Body.String())
+	}
+
+	var resp apiErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal error response: %v", err)
+	}
+	if resp.Error.Code != "event_ended" {
+		t.Fatalf("expected error code 'event_ended', got '%s'", resp.Error.Code)
+	}
+}
+
+func TestRegisterIntegration_WithinGracePeriodSucceeds(t *testing.T) {
+	cfg := testConfig()
+	cfg.RegistrationGraceMinutes = 30
+
+	router, pool := setupTestRouterWithConfig(t, cfg)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	// started 10 minutes ago, still inside the 30 minute door window
+	eventID := seedEventStartingAt(t, pool, 10, time.Now().UTC().Add(-10*time.Minute))
+
+	token := signupAndGetToken(t, router, "door@example.com")
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Door Signup","email":"door@example.com"}`, token)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+}
@@ -0,0 +1,149 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// countOrphanRegistrations counts confirmed registrations with no
+// confirmation job, and all registrations.
+func countOrphanRegistrations(t *testing.T, pool *pgxpool.Pool) (orphans, total int) {
+	t.Helper()
+
+	err := pool.QueryRow(context.Background(), `
+		SELECT
+			COUNT(*) FILTER (WHERE r.status = 'confirmed' AND NOT EXISTS (
+				SELECT 1 FROM jobs j
+				WHERE j.type = 'registration.confirmation'
+				  AND j.idempotency_key = 'registration:confirm:' || r.id::text
+			)),
+			COUNT(*)
+		FROM registrations r
+	`).Scan(&orphans, &total)
+	if err != nil {
+		t.Fatalf("count registrations: %v", err)
+	}
+	return orphans, total
+}
+
+func testConfirmationJob(ctx context.Context, reg registration.Registration) (job.CreateRequest, error) {
+	raw, err := jobs.RegistrationConfirmationPayload{RegistrationID: reg.ID, EventID: reg.EventID, Email: reg.Email, Name: reg.Name}.JSON()
+	if err != nil {
+		return job.CreateRequest{}, err
+	}
+	key := "registration:confirm:" + reg.ID
+	return job.CreateRequest{Type: jobs.TypeRegistrationConfirmation, Payload: raw, RunAt: time.Now().UTC(), IdempotencyKey: &key}, nil
+}
+
+func TestRegistrationOutbox_CrashBetweenInsertAndEnqueue(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 10)
+	req := registration.CreateRegistrationRequest{EventID: eventID, Name: "Sam Doe", Email: "sam@example.com"}
+	crash := errors.New("simulated crash")
+
+	// the process dies with the registration inserted and nothing enqueued
+	repo := postgres.NewRegistrationsRepo(pool, nil).
+		WithFaultAfterInsert(func(ctx context.Context, reg registration.Registration) error {
+			return crash
+		})
+	if _, _, err := repo.CreateWithConfirmation(context.Background(), req, postgres.EnqueueConfirmationFunc(testConfirmationJob)); !errors.Is(err, crash) {
+		t.Fatalf("expected the simulated crash, got %v", err)
+	}
+	if orphans, total := countOrphanRegistrations(t, pool); orphans != 0 || total != 0 {
+		t.Fatalf("expected the registration rolled back, got %d registrations (%d without a job)", total, orphans)
+	}
+
+	// the request is abandoned mid-flight, so the job insert never runs
+	ctx, cancel := context.WithCancel(context.Background())
+	repo = postgres.NewRegistrationsRepo(pool, nil).
+		WithFaultAfterInsert(func(context.Context, registration.Registration) error {
+			cancel()
+			return nil
+		})
+	if _, _, err := repo.CreateWithConfirmation(ctx, req, postgres.EnqueueConfirmationFunc(testConfirmationJob)); err == nil {
+		t.Fatalf("expected the cancelled flow to fail")
+	}
+	if orphans, total := countOrphanRegistrations(t, pool); orphans != 0 || total != 0 {
+		t.Fatalf("expected the registration rolled back, got %d registrations (%d without a job)", total, orphans)
+	}
+
+	reg, created, err := postgres.NewRegistrationsRepo(pool, nil).
+		CreateWithConfirmation(context.Background(), req, postgres.EnqueueConfirmationFunc(testConfirmationJob))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == "" || created.Type != jobs.TypeRegistrationConfirmation {
+		t.Fatalf("expected a confirmation job for %s, got %+v", reg.ID, created)
+	}
+	if orphans, total := countOrphanRegistrations(t, pool); orphans != 0 || total != 1 {
+		t.Fatalf("expected one registration with its job, got %d registrations (%d without a job)", total, orphans)
+	}
+}
+
+func TestRegistrationOutbox_RegisterEndpointRollsBackOnCrash(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 10)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reg := prometheus.NewRegistry()
+	deps := apphttp.PostgresDependencies(logger, pool, testConfig(), reg, observability.NewProm(reg))
+	deps.Registrations = postgres.NewRegistrationsRepo(pool, nil).
+		WithFaultAfterInsert(func(context.Context, registration.Registration) error {
+			return errors.New("simulated crash")
+		})
+	router, stopRouter := apphttp.NewRouterWithDeps(deps)
+	t.Cleanup(stopRouter)
+
+	body := `{"name": "Sam Doe", "email": "sam@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/events/"+eventID+"/register", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if orphans, total := countOrphanRegistrations(t, pool); orphans != 0 || total != 0 {
+		t.Fatalf("expected no registration without its job, got %d registrations (%d without a job)", total, orphans)
+	}
+}
+
+func TestRegistrationOutbox_ConfirmationHonoursTheJobsRepoLimits(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 10)
+	req := registration.CreateRegistrationRequest{EventID: eventID, Name: "Sam Doe", Email: "sam@example.com"}
+
+	// the confirmation payload is larger than the configured limit
+	limited := postgres.NewJobsRepo(pool, nil).WithPayloadLimits(job.PayloadLimits{MaxBytes: 16})
+	repo := postgres.NewRegistrationsRepo(pool, nil).WithJobs(limited)
+	if _, _, err := repo.CreateWithConfirmation(context.Background(), req, postgres.EnqueueConfirmationFunc(testConfirmationJob)); !errors.Is(err, job.ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge from the injected jobs repo, got %v", err)
+	}
+	if orphans, total := countOrphanRegistrations(t, pool); orphans != 0 || total != 0 {
+		t.Fatalf("expected the registration rolled back, got %d registrations (%d without a job)", total, orphans)
+	}
+}
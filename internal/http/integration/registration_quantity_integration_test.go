@@ -0,0 +1,93 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+)
+
+func TestRegisterIntegration_QuantityCountsSeats(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 3)
+	if _, err := pool.Exec(context.Background(), `UPDATE events SET max_quantity = 2 WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("set max_quantity: %v", err)
+	}
+	token := signupAndGetToken(t, router, "host@example.com")
+	registerPath := "/events/" + eventID + "/register"
+
+	w := doAuthedJSONRequest(router, http.MethodPost, registerPath, `{"name":"Over Limit","email":"over@example.com","quantity":3}`, token)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 above maxQuantity, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, registerPath, `{"name":"Plus One","email":"plusone@example.com","quantity":2}`, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register with guest got %d body=%s", w.Code, w.Body.String())
+	}
+	var first registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+		t.Fatalf("decode first: %v", err)
+	}
+	if first.Quantity != 2 {
+		t.Fatalf("expected quantity 2, got %d", first.Quantity)
+	}
+
+	// one seat left: a pair must not squeeze in
+	w = doAuthedJSONRequest(router, http.MethodPost, registerPath, `{"name":"Pair","email":"pair@example.com","quantity":2}`, token)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when the pair does not fit, got %d body=%s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode conflict: %v", err)
+	}
+	if body.Error.Code != "event_full" || body.Error.Details["remaining"] != float64(1) {
+		t.Fatalf("expected event_full with remaining=1, got %+v", body.Error)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodPost, registerPath, `{"name":"Pair","email":"pair@example.com","quantity":2,"joinWaitlist":true}`, token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("waitlist pair got %d body=%s", w.Code, w.Body.String())
+	}
+	var pair registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &pair); err != nil {
+		t.Fatalf("decode pair: %v", err)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodDelete, "/events/"+eventID+"/registrations/"+first.ID, "", token)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("cancel got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var status string
+	if err := pool.QueryRow(context.Background(), `SELECT status FROM registrations WHERE id = $1`, pair.ID).Scan(&status); err != nil {
+		t.Fatalf("select pair: %v", err)
+	}
+	if status != registration.StatusConfirmed {
+		t.Fatalf("expected waitlisted pair to be promoted, got %s", status)
+	}
+
+	w = doAnonymousJSONRequest(router, http.MethodGet, "/events/"+eventID+"/availability", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("availability got %d body=%s", w.Code, w.Body.String())
+	}
+	var availability event.Availability
+	if err := json.Unmarshal(w.Body.Bytes(), &availability); err != nil {
+		t.Fatalf("decode availability: %v", err)
+	}
+	if availability.RegisteredCount != 2 || availability.Remaining != 1 || availability.MaxQuantity != 2 {
+		t.Fatalf("unexpected availability: %+v", availability)
+	}
+}
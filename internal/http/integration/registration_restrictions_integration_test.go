@@ -0,0 +1,175 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func doAnonymousJSONRequest(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// createRestrictedEvent goes through the admin API so the restriction fields
+// are exercised end to end.
+func createRestrictedEvent(t *testing.T, router *gin.Engine, pool *pgxpool.Pool, requiresAuth bool, domains string) string {
+	t.Helper()
+
+	adminToken := createAdminAuthToken(t, router, pool, "admin-restrict@example.com")
+	body := `{
+		"title": "Internal Offsite",
+		"city": "Toronto",
+		"startAt": "` + time.Now().UTC().Add(48*time.Hour).Format(time.RFC3339) + `",
+		"capacity": 10,
+		"requiresAuth": ` + strconv.FormatBool(requiresAuth) + `,
+		"allowedEmailDomains": ` + domains + `
+	}`
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/events", body, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event failed: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	var created struct {
+		ID                  string   `json:"id"`
+		RequiresAuth        bool     `json:"requiresAuth"`
+		AllowedEmailDomains []string `json:"allowedEmailDomains"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode created event: %v", err)
+	}
+	if created.RequiresAuth != requiresAuth {
+		t.Fatalf("expected requiresAuth=%v, got %v", requiresAuth, created.RequiresAuth)
+	}
+
+	return created.ID
+}
+
+func TestRegisterIntegration_AnonymousAllowedOnOpenEvent(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := seedEvent(t, pool, 5)
+
+	w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Walk In","email":"walkin@example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for anonymous registration, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var userID *string
+	if err := pool.QueryRow(context.Background(), `SELECT user_id::text FROM registrations WHERE event_id = $1`, eventID).Scan(&userID); err != nil {
+		t.Fatalf("select registration: %v", err)
+	}
+	if userID != nil {
+		t.Fatalf("expected anonymous registration to have no user, got %s", *userID)
+	}
+}
+
+func TestRegisterIntegration_RequiresAuthRejectsAnonymous(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := createRestrictedEvent(t, router, pool, true, `[]`)
+
+	w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Walk In","email":"walkin@example.com"}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for anonymous registration, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	token := signupAndGetToken(t, router, "member@example.com")
+	w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Member","email":"member@example.com"}`, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for authenticated registration, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// a bad token is never treated as anonymous
+	w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Member","email":"other@example.com"}`, "not-a-token")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for invalid token, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRegisterIntegration_EmailDomainNotAllowed(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := createRestrictedEvent(t, router, pool, false, `["@Corp.Example"]`)
+
+	w := doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Outsider","email":"outsider@gmail.com"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d body=%s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("email_domain_not_allowed")) {
+		t.Fatalf("expected email_domain_not_allowed code, body=%s", w.Body.String())
+	}
+
+	w = doAnonymousJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Insider","email":"insider@corp.example"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for allowed domain, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRegisterIntegration_TokenEmailTakesPrecedence(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetDB(t, pool)
+	defer resetDB(t, pool)
+
+	eventID := createRestrictedEvent(t, router, pool, true, `["corp.example"]`)
+
+	outsider := signupAndGetToken(t, router, "outsider@gmail.com")
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Outsider","email":"spoofed@corp.example"}`, outsider)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 when token email is outside the domain, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	insider := signupAndGetToken(t, router, "insider@corp.example")
+	w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Insider","email":"personal@gmail.com"}`, insider)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for insider, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var email string
+	if err := pool.QueryRow(context.Background(), `SELECT email FROM registrations WHERE event_id = $1`, eventID).Scan(&email); err != nil {
+		t.Fatalf("select registration: %v", err)
+	}
+	if email != "insider@corp.example" {
+		t.Fatalf("expected token email to be registered, got %s", email)
+	}
+
+	w = doAnonymousJSONRequest(router, http.MethodGet, "/events/"+eventID+"/availability", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("availability got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var availability struct {
+		RegisteredCount     int      `json:"registeredCount"`
+		Remaining           int      `json:"remaining"`
+		RequiresAuth        bool     `json:"requiresAuth"`
+		AllowedEmailDomains []string `json:"allowedEmailDomains"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &availability); err != nil {
+		t.Fatalf("decode availability: %v", err)
+	}
+	if !availability.RequiresAuth || len(availability.AllowedEmailDomains) != 1 || availability.AllowedEmailDomains[0] != "corp.example" {
+		t.Fatalf("unexpected restriction flags: %+v", availability)
+	}
+	if availability.RegisteredCount != 1 || availability.Remaining != 9 {
+		t.Fatalf("unexpected counts: %+v", availability)
+	}
+}
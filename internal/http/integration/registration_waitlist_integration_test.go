@@ -0,0 +1,99 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+)
+
+func TestRegisterIntegration_WaitlistPromotedOnCancel(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 1)
+	token := signupAndGetToken(t, router, "first@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"First In","email":"first@example.com"}`, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first register got %d body=%s", w.Code, w.Body.String())
+	}
+	var first registration.Registration
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+		t.Fatalf("decode first: %v", err)
+	}
+
+	// without opting in, a full event still rejects
+	w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"No Wait","email":"nowait@example.com"}`, token)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without joinWaitlist, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	waitlisted := make([]registration.Registration, 0, 2)
+	for _, email := range []string{"second@example.com", "third@example.com"} {
+		w = doAuthedJSONRequest(router, http.MethodPost, "/events/"+eventID+"/register", `{"name":"Wait Listed","email":"`+email+`","joinWaitlist":true}`, token)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("waitlist register got %d body=%s", w.Code, w.Body.String())
+		}
+		var reg registration.Registration
+		if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+			t.Fatalf("decode waitlisted: %v", err)
+		}
+		waitlisted = append(waitlisted, reg)
+	}
+
+	if waitlisted[0].Status != registration.StatusWaitlisted || waitlisted[0].WaitlistPosition == nil || *waitlisted[0].WaitlistPosition != 1 {
+		t.Fatalf("unexpected first waitlist entry: %+v", waitlisted[0])
+	}
+	if waitlisted[1].WaitlistPosition == nil || *waitlisted[1].WaitlistPosition != 2 {
+		t.Fatalf("unexpected second waitlist entry: %+v", waitlisted[1])
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodDelete, "/events/"+eventID+"/registrations/"+first.ID, "", token)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("cancel got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var status string
+	var position *int64
+	err := pool.QueryRow(context.Background(), `
+		SELECT status, waitlist_position FROM registrations WHERE id = $1
+	`, waitlisted[0].ID).Scan(&status, &position)
+	if err != nil {
+		t.Fatalf("select promoted: %v", err)
+	}
+	if status != registration.StatusConfirmed || position != nil {
+		t.Fatalf("expected earliest waitlisted to be confirmed, got status=%s position=%v", status, position)
+	}
+
+	var jobs int
+	err = pool.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM jobs WHERE idempotency_key = $1
+	`, "registration:confirm:"+waitlisted[0].ID).Scan(&jobs)
+	if err != nil {
+		t.Fatalf("select promotion job: %v", err)
+	}
+	if jobs != 1 {
+		t.Fatalf("expected 1 confirmation job for promoted registration, got %d", jobs)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/events/"+eventID+"/registrations", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list got %d body=%s", w.Code, w.Body.String())
+	}
+	var page struct {
+		Items []registration.Registration `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("expected 2 registrations left, got %d", len(page.Items))
+	}
+	if page.Items[1].Status != registration.StatusWaitlisted || page.Items[1].WaitlistPosition == nil {
+		t.Fatalf("expected list to expose waitlist status and position, got %+v", page.Items[1])
+	}
+}
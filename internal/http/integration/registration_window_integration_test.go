@@ -0,0 +1,78 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestRegistrationWindow_BoundariesEnforcedByRepo(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	opensAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	closesAt := opensAt.Add(time.Hour)
+
+	eventID := seedEvent(t, pool, 10)
+	if _, err := pool.Exec(ctx, `
+		UPDATE events SET registration_opens_at = $2, registration_closes_at = $3 WHERE id = $1
+	`, eventID, opensAt, closesAt); err != nil {
+		t.Fatalf("set window: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "just before opening", now: opensAt.Add(-time.Millisecond), wantErr: registration.ErrRegistrationNotOpen},
+		{name: "at opening", now: opensAt},
+		{name: "just before closing", now: closesAt.Add(-time.Millisecond)},
+		{name: "at closing", now: closesAt, wantErr: registration.ErrRegistrationClosed},
+	}
+
+	for i, tt := range tests {
+		repo := postgres.NewRegistrationsRepo(pool, nil).WithClock(func() time.Time { return tt.now })
+
+		_, err := repo.Create(ctx, registration.CreateRegistrationRequest{
+			EventID: eventID,
+			Name:    "Window Guest",
+			Email:   fmt.Sprintf("window%d@example.com", i),
+		})
+		if tt.wantErr == nil && err != nil {
+			t.Fatalf("%s: expected registration to succeed, got %v", tt.name, err)
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	// the real clock is before the window, over HTTP too
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/"+eventID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("get event: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var got struct {
+		RegistrationState    event.RegistrationState `json:"registrationState"`
+		RegistrationOpensAt  *time.Time              `json:"registrationOpensAt"`
+		RegistrationClosesAt *time.Time              `json:"registrationClosesAt"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.RegistrationState != event.RegistrationNotOpen || got.RegistrationOpensAt == nil || !got.RegistrationOpensAt.Equal(opensAt) {
+		t.Fatalf("unexpected window in event JSON: %s", w.Body.String())
+	}
+}
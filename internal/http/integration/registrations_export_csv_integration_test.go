@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/geocoder89/eventhub/internal/exportstore"
 	"github.com/geocoder89/eventhub/internal/notifications"
 	"github.com/geocoder89/eventhub/internal/queue/worker"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
@@ -54,8 +55,16 @@ func seedRegistrationForExport(
 	}
 }
 
+type exportStatus struct {
+	ID          string  `json:"id"`
+	EventID     string  `json:"eventId"`
+	Status      string  `json:"status"`
+	RowCount    *int    `json:"rowCount"`
+	DownloadURL *string `json:"downloadUrl"`
+}
+
 func TestPipeline_RegistrationsCSVExport_EnqueueProcessDownload(t *testing.T) {
-	router, pool, _ := setupPipelineRouter(t)
+	router, pool, cfg := setupPipelineRouter(t)
 	resetPipelineDB(t, pool)
 	defer resetPipelineDB(t, pool)
 
@@ -115,6 +124,23 @@ func TestPipeline_RegistrationsCSVExport_EnqueueProcessDownload(t *testing.T) {
 		t.Fatalf("expected alreadyEnqueued=false for fresh export job")
 	}
 
+	statusW := doAuthedJSONRequest(router, http.MethodGet, "/admin/exports/"+enqueueResp.JobID, "", adminToken)
+	if statusW.Code != http.StatusOK {
+		t.Fatalf("export status got status=%d body=%s", statusW.Code, statusW.Body.String())
+	}
+	var pendingStatus exportStatus
+	if err := json.Unmarshal(statusW.Body.Bytes(), &pendingStatus); err != nil {
+		t.Fatalf("decode export status: %v", err)
+	}
+	if pendingStatus.Status != "pending" || pendingStatus.DownloadURL != nil {
+		t.Fatalf("expected pending export without link, got %+v", pendingStatus)
+	}
+
+	exportStore, err := exportstore.NewDirStore(cfg.ExportsDir)
+	if err != nil {
+		t.Fatalf("export store: %v", err)
+	}
+
 	jobsRepo := postgres.NewJobsRepo(pool, nil)
 	eventsRepo := postgres.NewEventsRepo(pool, nil)
 	regsRepo := postgres.NewRegistrationsRepo(pool, nil)
@@ -127,7 +153,7 @@ func TestPipeline_RegistrationsCSVExport_EnqueueProcessDownload(t *testing.T) {
 		Concurrency:   1,
 		ShutdownGrace: 1 * time.Second,
 	}, jobsRepo, eventsRepo, notifications.NewLogNotifier(), deliveriesRepo).
-		WithRegistrationCSVExporter(regsRepo, exportsRepo)
+		WithRegistrationCSVExporter(regsRepo, exportsRepo, exportStore)
 
 	processed, err := wk.ProcessOne(context.Background())
 	if err != nil {
@@ -140,12 +166,12 @@ func TestPipeline_RegistrationsCSVExport_EnqueueProcessDownload(t *testing.T) {
 	var dbCount int
 	var dbContentType string
 	var dbFileName string
-	var dbCSV []byte
+	var dbStoragePath *string
 	err = pool.QueryRow(context.Background(), `
-		SELECT row_count, content_type, file_name, csv_data
+		SELECT row_count, content_type, file_name, storage_path
 		FROM registration_csv_exports
 		WHERE job_id = $1
-	`, enqueueResp.JobID).Scan(&dbCount, &dbContentType, &dbFileName, &dbCSV)
+	`, enqueueResp.JobID).Scan(&dbCount, &dbContentType, &dbFileName, &dbStoragePath)
 	if err != nil {
 		t.Fatalf("select export row: %v", err)
 	}
@@ -158,8 +184,23 @@ func TestPipeline_RegistrationsCSVExport_EnqueueProcessDownload(t *testing.T) {
 	if dbFileName == "" {
 		t.Fatalf("expected file_name to be set")
 	}
-	if len(dbCSV) == 0 {
-		t.Fatalf("expected non-empty csv_data")
+	if dbStoragePath == nil || *dbStoragePath == "" {
+		t.Fatalf("expected storage_path to record where the file was written")
+	}
+
+	statusW = doAuthedJSONRequest(router, http.MethodGet, "/admin/exports/"+enqueueResp.JobID, "", adminToken)
+	if statusW.Code != http.StatusOK {
+		t.Fatalf("export status got status=%d body=%s", statusW.Code, statusW.Body.String())
+	}
+	var doneStatus exportStatus
+	if err := json.Unmarshal(statusW.Body.Bytes(), &doneStatus); err != nil {
+		t.Fatalf("decode export status: %v", err)
+	}
+	if doneStatus.Status != "done" || doneStatus.DownloadURL == nil || doneStatus.RowCount == nil || *doneStatus.RowCount != 2 {
+		t.Fatalf("expected done export with link and 2 rows, got %+v", doneStatus)
+	}
+	if doneStatus.EventID != eventID {
+		t.Fatalf("expected eventId %s, got %s", eventID, doneStatus.EventID)
 	}
 
 	downloadW := doAuthedJSONRequest(
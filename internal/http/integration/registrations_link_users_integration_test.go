@@ -0,0 +1,149 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func seedLegacyRegistration(t *testing.T, pool *pgxpool.Pool, eventID, email string) string {
+	t.Helper()
+	id := uuid.NewString()
+	if _, err := pool.Exec(context.Background(), `
+		INSERT INTO registrations (id, event_id, name, email, created_at, updated_at)
+		VALUES ($1, $2, 'Legacy', $3, NOW(), NOW())
+	`, id, eventID, email); err != nil {
+		t.Fatalf("seed registration: %v", err)
+	}
+	return id
+}
+
+func registrationUserID(t *testing.T, pool *pgxpool.Pool, id string) string {
+	t.Helper()
+	var userID *string
+	if err := pool.QueryRow(context.Background(), `SELECT user_id::text FROM registrations WHERE id = $1`, id).Scan(&userID); err != nil {
+		t.Fatalf("read registration: %v", err)
+	}
+	if userID == nil {
+		return ""
+	}
+	return *userID
+}
+
+func TestRegistrationsLinkUsers_BackfillsAndSkipsConflicts(t *testing.T) {
+	router, pool, cfg := setupPipelineTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	verify := func(id string) {
+		t.Helper()
+		if _, err := pool.Exec(ctx, `UPDATE users SET email_verified_at = NOW() WHERE id = $1`, id); err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+	}
+
+	// Alice under two casings, an email two verified accounts share, and an
+	// unverified account
+	eventA, eventB := seedEvent(t, pool, 10), seedEvent(t, pool, 10)
+	aliceID, dupLower, dupUpper, unverifiedID := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	seedUserForExport(t, pool, aliceID, "alice@example.com", "Alice")
+	seedUserForExport(t, pool, dupLower, "dup@example.com", "Dup")
+	seedUserForExport(t, pool, dupUpper, "DUP@example.com", "Dup Too")
+	seedUserForExport(t, pool, unverifiedID, "nobody@example.com", "Nobody")
+
+	aliceRegs := []string{
+		seedLegacyRegistration(t, pool, eventA, "Alice@Example.com"),
+		seedLegacyRegistration(t, pool, eventB, "alice@example.com"),
+	}
+	dupReg := seedLegacyRegistration(t, pool, eventA, "dup@example.com")
+	unverifiedReg := seedLegacyRegistration(t, pool, eventA, "nobody@example.com")
+	verify(aliceID)
+	verify(dupLower)
+	verify(dupUpper)
+
+	// the trigger already linked Alice's on verification; unlink as if they predate it
+	if _, err := pool.Exec(ctx, `UPDATE registrations SET user_id = NULL`); err != nil {
+		t.Fatalf("unlink: %v", err)
+	}
+
+	// until the backfill runs, Alice's own list finds them by verified email
+	token, err := apphttp.NewTokenManager(cfg).GenerateAccessToken(aliceID, "alice@example.com", user.RoleUser)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	w := doAuthedJSONRequest(router, http.MethodGet, "/me/registrations", "", token)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), aliceRegs[0]) || !strings.Contains(w.Body.String(), aliceRegs[1]) {
+		t.Fatalf("expected both of Alice's legacy rows listed, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+	created, err := jobsRepo.Create(ctx, job.CreateRequest{Type: jobs.TypeRegistrationsLinkUsers, Payload: json.RawMessage(`{"batchSize":1}`), RunAt: time.Now().UTC(), MaxAttempts: 1})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	wk := worker.New(worker.Config{
+		PollInterval:  10 * time.Millisecond,
+		WorkerID:      "test-worker-link-users",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+	}, jobsRepo, postgres.NewEventsRepo(pool, nil), nil, nil).
+		WithRegistrationLinking(postgres.NewRegistrationsRepo(pool, nil))
+	if processed, err := wk.ProcessOne(ctx); err != nil || !processed {
+		t.Fatalf("ProcessOne: processed=%v err=%v", processed, err)
+	}
+
+	for _, id := range aliceRegs {
+		if got := registrationUserID(t, pool, id); got != aliceID {
+			t.Fatalf("expected %s linked to Alice, got %q", id, got)
+		}
+	}
+	if got := registrationUserID(t, pool, dupReg); got != "" {
+		t.Fatalf("expected the shared email left unlinked, got %q", got)
+	}
+	if got := registrationUserID(t, pool, unverifiedReg); got != "" {
+		t.Fatalf("expected the unverified email left unlinked, got %q", got)
+	}
+
+	done, err := jobsRepo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	var res jobs.RegistrationsLinkUsersResult
+	if err := json.Unmarshal(done.Result, &res); err != nil {
+		t.Fatalf("decode result %s: %v", done.Result, err)
+	}
+	if res.Scanned != 4 || res.Linked != 2 || res.Conflicts != 1 || res.Batches < 4 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestRegistrationsLinkUsers_VerificationLinksStraightAway(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	eventID := seedEvent(t, pool, 10)
+	userID := uuid.NewString()
+	seedUserForExport(t, pool, userID, "verify-me@example.com", "Verify Me")
+	regID := seedLegacyRegistration(t, pool, eventID, "Verify-Me@example.com")
+
+	if _, err := pool.Exec(context.Background(), `UPDATE users SET email_verified_at = NOW() WHERE id = $1`, userID); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if got := registrationUserID(t, pool, regID); got != userID {
+		t.Fatalf("expected the registration linked on verification, got %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+func registerFrom(t *testing.T, router *gin.Engine, eventID string, i int) {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"name":"Guest %d","email":"guest%d@example.com"}`, i, i)
+	req := httptest.NewRequest(http.MethodPost, "/events/"+eventID+"/register", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = fmt.Sprintf("10.1.0.%d:4000", i)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register %d failed: status=%d body=%s", i, w.Code, w.Body.String())
+	}
+}
+
+func TestReminders_ScheduledOnPublishAndForLaterRegistrations(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	startAt := time.Now().UTC().Add(72 * time.Hour).Truncate(time.Second)
+	eventID := seedEventStartingAt(t, pool, 10, startAt)
+
+	// seeded events are drafts, so these wait for the publish
+	registerFrom(t, router, eventID, 1)
+	registerFrom(t, router, eventID, 2)
+
+	countReminders := func() int {
+		t.Helper()
+		var n int
+		err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM jobs
+			WHERE type = $1 AND payload->>'eventId' = $2
+		`, jobs.TypeRegistrationReminder, eventID).Scan(&n)
+		if err != nil {
+			t.Fatalf("count reminders: %v", err)
+		}
+		return n
+	}
+	if n := countReminders(); n != 0 {
+		t.Fatalf("expected no reminders before publish, got %d", n)
+	}
+
+	// what the event.publish job does
+	if _, err := pool.Exec(ctx, `UPDATE events SET published_at = NOW() WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	regsRepo := postgres.NewRegistrationsRepo(pool, nil)
+	n, err := regsRepo.EnqueueReminders(ctx, eventID)
+	if err != nil {
+		t.Fatalf("enqueue reminders: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 reminders scheduled on publish, got %d", n)
+	}
+
+	// a retried publish schedules nothing new
+	if n, err := regsRepo.EnqueueReminders(ctx, eventID); err != nil || n != 0 {
+		t.Fatalf("expected repeat publish to be a no-op, got n=%d err=%v", n, err)
+	}
+
+	registerFrom(t, router, eventID, 3)
+	if n := countReminders(); n != 3 {
+		t.Fatalf("expected the post-publish registration to get a reminder, got %d", n)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT run_at, idempotency_key, payload->>'registrationId'
+		FROM jobs
+		WHERE type = $1 AND payload->>'eventId' = $2
+	`, jobs.TypeRegistrationReminder, eventID)
+	if err != nil {
+		t.Fatalf("select reminders: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var runAt time.Time
+		var key, regID string
+		if err := rows.Scan(&runAt, &key, &regID); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if !runAt.Equal(startAt.Add(-jobs.ReminderLead)) {
+			t.Fatalf("expected run_at %s, got %s", startAt.Add(-jobs.ReminderLead), runAt)
+		}
+		if key != jobs.ReminderKey(regID) {
+			t.Fatalf("expected key %s, got %s", jobs.ReminderKey(regID), key)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+}
+
+func TestReminders_SkippedForEventsStartingWithinLead(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	eventID := seedEventStartingAt(t, pool, 10, time.Now().UTC().Add(12*time.Hour))
+	if _, err := pool.Exec(ctx, `UPDATE events SET published_at = NOW() WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	registerFrom(t, router, eventID, 1)
+
+	var n int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE type = $1`, jobs.TypeRegistrationReminder).Scan(&n); err != nil {
+		t.Fatalf("count reminders: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no reminder for an event starting within %s, got %d", jobs.ReminderLead, n)
+	}
+}
@@ -0,0 +1,100 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/schedule"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestSchedules_EnqueueDueOncePerOccurrenceAndSkipsDisabled(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `TRUNCATE job_schedules`); err != nil {
+		t.Fatalf("truncate job_schedules: %v", err)
+	}
+	defer func() { _, _ = pool.Exec(ctx, `TRUNCATE job_schedules`) }()
+
+	adminToken := createAdminAuthToken(t, router, pool, "admin-schedules@example.com")
+
+	create := func(cron string) schedule.Schedule {
+		t.Helper()
+		w := doAuthedJSONRequest(router, http.MethodPost, "/admin/schedules", `{"type":"events.archive","cron":"`+cron+`","payload":{"olderThanDays":30}}`, adminToken)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create got status=%d body=%s", w.Code, w.Body.String())
+		}
+		var s schedule.Schedule
+		if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return s
+	}
+	hourly := create("0 * * * *")
+	disabled := create("0 * * * *")
+
+	w := doAuthedJSONRequest(router, http.MethodPut, "/admin/schedules/"+disabled.ID, `{"type":"events.archive","cron":"0 * * * *","enabled":false}`, adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("disable got status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	repo := postgres.NewSchedulesRepo(pool, nil)
+	due := hourly.NextRunAt.Add(time.Second)
+
+	n, err := repo.EnqueueDue(ctx, due, 100)
+	if err != nil || n != 1 {
+		t.Fatalf("expected one job enqueued, got n=%d err=%v", n, err)
+	}
+
+	var jobType, key string
+	var runAt time.Time
+	err = pool.QueryRow(ctx, `SELECT type, idempotency_key, run_at FROM jobs`).Scan(&jobType, &key, &runAt)
+	if err != nil {
+		t.Fatalf("read job: %v", err)
+	}
+	if jobType != "events.archive" || key != schedule.IdempotencyKey(hourly.ID, hourly.NextRunAt) || !runAt.Equal(hourly.NextRunAt) {
+		t.Fatalf("unexpected job type=%s key=%s run_at=%s", jobType, key, runAt)
+	}
+
+	got, err := repo.GetByID(ctx, hourly.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !got.NextRunAt.Equal(hourly.NextRunAt.Add(time.Hour)) || got.LastRunAt == nil || !got.LastRunAt.Equal(hourly.NextRunAt) {
+		t.Fatalf("expected the schedule moved an hour on, got %+v", got)
+	}
+
+	// nothing is due again until the next hour
+	if n, err := repo.EnqueueDue(ctx, due, 100); err != nil || n != 0 {
+		t.Fatalf("expected nothing due, got n=%d err=%v", n, err)
+	}
+
+	// another instance holding the scheduler lock keeps this one out
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, int64(0x6a6f625f73636864)).Scan(&locked); err != nil || !locked {
+		t.Fatalf("take scheduler lock: locked=%v err=%v", locked, err)
+	}
+	if n, err := repo.EnqueueDue(ctx, got.NextRunAt.Add(time.Second), 100); err != nil || n != 0 {
+		t.Fatalf("expected no scheduling without the lock, got n=%d err=%v", n, err)
+	}
+	_ = tx.Rollback(ctx)
+
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs`).Scan(&total); err != nil {
+		t.Fatalf("count jobs: %v", err)
+	}
+	if total != 1 {
+		t.Fatalf("expected the disabled schedule to enqueue nothing, got %d jobs", total)
+	}
+}
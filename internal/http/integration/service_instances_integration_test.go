@@ -0,0 +1,60 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/configdrift"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestServiceInstances_AnnounceListRemove(t *testing.T) {
+	_, pool := setupTestRouter(t)
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `DELETE FROM service_instances`); err != nil {
+		t.Fatalf("reset service_instances: %v", err)
+	}
+	defer func() { _, _ = pool.Exec(ctx, `DELETE FROM service_instances`) }()
+
+	repo := postgres.NewServiceInstancesRepo(pool, nil)
+	started := time.Now().UTC().Truncate(time.Second)
+
+	api := configdrift.Instance{ID: "api-1", Service: "api", Fingerprint: "f1", Parts: map[string]string{"db": "d1", "secrets": "s1"}, StartedAt: started}
+	worker := configdrift.Instance{ID: "worker-1", Service: "worker", Fingerprint: "f2", Parts: map[string]string{"db": "d1", "secrets": "s2"}, StartedAt: started.Add(time.Second)}
+	for _, in := range []configdrift.Instance{api, worker, api} {
+		if err := repo.Announce(ctx, in); err != nil {
+			t.Fatalf("announce %s: %v", in.ID, err)
+		}
+	}
+
+	// a crashed process that stopped announcing long ago
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO service_instances (id, service, fingerprint, started_at, last_seen_at)
+		VALUES ('gone-1', 'worker', 'f3', NOW() - INTERVAL '3 hours', NOW() - INTERVAL '3 hours')
+	`); err != nil {
+		t.Fatalf("seed stale instance: %v", err)
+	}
+
+	live, err := repo.ListLive(ctx, time.Now().Add(-configdrift.LiveWindow))
+	if err != nil {
+		t.Fatalf("list live: %v", err)
+	}
+	if len(live) != 2 || live[0].ID != "api-1" || live[1].ID != "worker-1" || live[1].Parts["secrets"] != "s2" {
+		t.Fatalf("unexpected live instances %+v", live)
+	}
+
+	rep := configdrift.Check(live)
+	if !rep.Drifted || len(rep.Parts) != 1 || rep.Parts[0] != "secrets" {
+		t.Fatalf("expected drift in secrets, got %+v", rep)
+	}
+
+	if err := repo.Remove(ctx, "worker-1"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	live, err = repo.ListLive(ctx, time.Now().Add(-configdrift.LiveWindow))
+	if err != nil || len(live) != 1 || configdrift.Check(live).Drifted {
+		t.Fatalf("expected only api-1 left without drift, got %+v err=%v", live, err)
+	}
+}
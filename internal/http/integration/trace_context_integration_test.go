@@ -0,0 +1,119 @@
+package integration__test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestTraceContext_PublishCarriesTheRequestTrace(t *testing.T) {
+	router, pool, cfg := setupPipelineTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	// what cmd/api installs with the tracer
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	eventID := seedEvent(t, pool, 2)
+	token, err := apphttp.NewTokenManager(cfg).GenerateAccessToken(uuid.NewString(), "trace-admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/publish", bytes.NewBufferString(`{}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("publish got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var stored *string
+	if err := pool.QueryRow(context.Background(),
+		`SELECT trace_parent FROM jobs WHERE type = 'event.publish'`).Scan(&stored); err != nil {
+		t.Fatalf("job: %v", err)
+	}
+	if stored == nil || !strings.HasPrefix(*stored, "00-"+traceID+"-") {
+		t.Fatalf("expected the job stored in trace %s, got %v", traceID, stored)
+	}
+
+	j, err := postgres.NewJobsRepo(pool, nil).ClaimNext(context.Background(), "trace-worker")
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if j.TraceParent == nil || *j.TraceParent != *stored {
+		t.Fatalf("expected the claim to return the trace context, got %v", j.TraceParent)
+	}
+}
+
+func TestTraceContext_BulkEnqueuesCarryTheCallersTrace(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := observability.ContextWithTraceParent(context.Background(), "00-"+traceID+"-00f067aa0ba902b7-01", "")
+
+	eventID := seedEventStartingAt(t, pool, 10, time.Now().UTC().Add(72*time.Hour))
+	registerFrom(t, router, eventID, 1)
+	if _, err := pool.Exec(ctx, `UPDATE events SET published_at = NOW() WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if n, err := postgres.NewRegistrationsRepo(pool, nil).EnqueueReminders(ctx, eventID); err != nil || n != 1 {
+		t.Fatalf("expected one reminder, got n=%d err=%v", n, err)
+	}
+
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+	failed, err := jobsRepo.Create(context.Background(), job.CreateRequest{Type: "test.noop", Payload: json.RawMessage(`{}`)})
+	if err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+	if err := jobsRepo.MarkFailed(context.Background(), failed.ID, "boom"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if n, err := jobsRepo.RetryManyFailed(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("expected one replay, got n=%d err=%v", n, err)
+	}
+
+	rows, err := pool.Query(context.Background(), `
+		SELECT type, trace_parent FROM jobs
+		WHERE type = $1 OR (type = 'test.noop' AND id <> $2)
+	`, jobs.TypeRegistrationReminder, failed.ID)
+	if err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	defer rows.Close()
+
+	seen := 0
+	for rows.Next() {
+		var typ string
+		var stored *string
+		if err := rows.Scan(&typ, &stored); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if stored == nil || !strings.HasPrefix(*stored, "00-"+traceID+"-") {
+			t.Fatalf("expected the %s job in trace %s, got %v", typ, traceID, stored)
+		}
+		seen++
+	}
+	if err := rows.Err(); err != nil || seen != 2 {
+		t.Fatalf("expected the reminder and the replay, saw %d (%v)", seen, err)
+	}
+}
@@ -0,0 +1,113 @@
+package integration__test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+func TestWebhooks_EnqueuedWithRegistrationChanges(t *testing.T) {
+	router, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	adminToken := createAdminAuthToken(t, router, pool, "admin-webhooks@example.com")
+
+	w := doAuthedJSONRequest(router, http.MethodPost, "/admin/webhooks",
+		`{"url":"https://crm.example.com/hooks","eventKinds":["registration.created","registration.cancelled"]}`, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create webhook: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Webhook webhook.Webhook `json:"webhook"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	hookID := created.Webhook.ID
+
+	// a disabled webhook hears nothing
+	w = doAuthedJSONRequest(router, http.MethodPost, "/admin/webhooks",
+		`{"url":"https://other.example.com/hooks","eventKinds":["registration.created"],"enabled":false}`, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create disabled webhook: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	eventID := seedEvent(t, pool, 10)
+	registerFrom(t, router, eventID, 1)
+
+	var regID string
+	if err := pool.QueryRow(ctx, `SELECT id FROM registrations WHERE event_id = $1`, eventID).Scan(&regID); err != nil {
+		t.Fatalf("read registration: %v", err)
+	}
+	if err := postgres.NewRegistrationsRepo(pool, nil).Cancel(ctx, eventID, regID, registration.Cancellation{By: registration.CancelledBySystem}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT idempotency_key, payload
+		FROM jobs
+		WHERE type = $1
+		ORDER BY created_at
+	`, jobs.TypeWebhookDeliver)
+	if err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	var kinds []string
+	for rows.Next() {
+		var key string
+		var raw []byte
+		if err := rows.Scan(&key, &raw); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		var p jobs.WebhookDeliverPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			t.Fatalf("payload: %v", err)
+		}
+		if p.WebhookID != hookID {
+			t.Fatalf("delivery queued for the wrong webhook: %+v", p)
+		}
+		keys = append(keys, key)
+		kinds = append(kinds, p.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != webhook.KindRegistrationCreated || kinds[1] != webhook.KindRegistrationCancelled {
+		t.Fatalf("expected created then cancelled deliveries, got %v", kinds)
+	}
+	if keys[0] != jobs.WebhookDeliverKey(hookID, webhook.KindRegistrationCreated, regID) {
+		t.Fatalf("unexpected idempotency key %s", keys[0])
+	}
+
+	code := http.StatusBadGateway
+	msg := "webhook answered 502"
+	err = postgres.NewWebhooksRepo(pool, nil).RecordDelivery(ctx, webhook.Delivery{
+		WebhookID: hookID, JobID: uuid.NewString(), Kind: webhook.KindRegistrationCreated, Attempt: 1, StatusCode: &code, Error: &msg,
+	})
+	if err != nil {
+		t.Fatalf("record delivery: %v", err)
+	}
+
+	w = doAuthedJSONRequest(router, http.MethodGet, "/admin/webhooks/"+hookID+"/deliveries", "", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("deliveries: status=%d body=%s", w.Code, w.Body.String())
+	}
+	var list struct {
+		Items []webhook.Delivery `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode deliveries: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].StatusCode == nil || *list.Items[0].StatusCode != code {
+		t.Fatalf("unexpected deliveries: %s", w.Body.String())
+	}
+}
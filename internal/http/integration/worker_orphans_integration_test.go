@@ -0,0 +1,71 @@
+package integration__test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/queue/worker"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+func TestWorkerRun_ReleasesJobsLockedUnderItsOwnID(t *testing.T) {
+	_, pool := setupTestRouter(t)
+	resetPipelineDB(t, pool)
+	defer resetPipelineDB(t, pool)
+
+	ctx := context.Background()
+	jobsRepo := postgres.NewJobsRepo(pool, nil)
+
+	// due in an hour, so the restarted worker releases them without claiming
+	seedProcessing := func(lockedBy string) string {
+		t.Helper()
+		j, err := jobsRepo.Create(ctx, job.CreateRequest{Type: "test.orphan", Payload: []byte(`{}`), RunAt: time.Now().UTC().Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `
+			UPDATE jobs SET status = 'processing', attempts = 1, locked_by = $2, locked_at = NOW() WHERE id = $1
+		`, j.ID, lockedBy); err != nil {
+			t.Fatalf("lock: %v", err)
+		}
+		return j.ID
+	}
+	orphan := seedProcessing("restarted-worker")
+	other := seedProcessing("other-worker")
+
+	runCtx, cancel := context.WithCancel(ctx)
+	wk := worker.New(worker.Config{
+		PollInterval:  50 * time.Millisecond,
+		WorkerID:      "restarted-worker",
+		Concurrency:   1,
+		ShutdownGrace: time.Second,
+		LockTTL:       time.Minute,
+	}, jobsRepo, postgres.NewEventsRepo(pool, nil), nil, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- wk.Run(runCtx) }()
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var status string
+	var attempts int
+	var lockedBy *string
+	if err := pool.QueryRow(ctx, `SELECT status, attempts, locked_by FROM jobs WHERE id = $1`, orphan).Scan(&status, &attempts, &lockedBy); err != nil {
+		t.Fatalf("read orphan: %v", err)
+	}
+	if status != "pending" || attempts != 1 || lockedBy != nil {
+		t.Fatalf("expected the orphan pending with its attempt kept, got status=%s attempts=%d locked_by=%v", status, attempts, lockedBy)
+	}
+
+	if err := pool.QueryRow(ctx, `SELECT status FROM jobs WHERE id = $1`, other).Scan(&status); err != nil {
+		t.Fatalf("read other: %v", err)
+	}
+	if status != "processing" {
+		t.Fatalf("expected another worker's job left processing, got %s", status)
+	}
+}
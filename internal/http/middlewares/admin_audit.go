@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,9 +36,9 @@ func AdminAudit(writer AdminAuditWriter) gin.HandlerFunc {
 			return
 		}
 
-		actorRole, _ := getContextString(c, CtxRole)
-		if actorRole == "" {
-			actorRole = "admin"
+		actorRole := string(user.RoleAdmin)
+		if role, ok := RoleFromContext(c); ok && role != "" {
+			actorRole = string(role)
 		}
 
 		actorUserID, _ := getContextString(c, CtxUserID)
@@ -53,6 +54,10 @@ func AdminAudit(writer AdminAuditWriter) gin.HandlerFunc {
 			"query":  c.Request.URL.RawQuery,
 		}
 
+		if q, ok := getContextString(c, CtxAuditQuery); ok {
+			details["query"] = q
+		}
+
 		if jobID, ok := getContextString(c, CtxJobID); ok && jobID != "" {
 			details["jobId"] = jobID
 		}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/gin-gonic/gin"
 )
 
@@ -57,7 +58,7 @@ func TestAdminAudit_WritesForMutatingAdminRoute(t *testing.T) {
 	r.Use(func(c *gin.Context) {
 		c.Set(CtxUserID, "admin-user-1")
 		c.Set(CtxEmail, "admin@example.com")
-		c.Set(CtxRole, "admin")
+		c.Set(CtxRole, user.RoleAdmin)
 		c.Set(CtxRequestID, "req-123")
 		c.Next()
 	})
@@ -119,7 +120,7 @@ func TestAdminAudit_SkipsGetRoutes(t *testing.T) {
 	r.Use(func(c *gin.Context) {
 		c.Set(CtxUserID, "admin-user-1")
 		c.Set(CtxEmail, "admin@example.com")
-		c.Set(CtxRole, "admin")
+		c.Set(CtxRole, user.RoleAdmin)
 		c.Set(CtxRequestID, "req-123")
 		c.Next()
 	})
@@ -151,7 +152,7 @@ func TestAdminAudit_WriteErrorDoesNotBreakResponse(t *testing.T) {
 	r.Use(func(c *gin.Context) {
 		c.Set(CtxUserID, "admin-user-1")
 		c.Set(CtxEmail, "admin@example.com")
-		c.Set(CtxRole, "admin")
+		c.Set(CtxRole, user.RoleAdmin)
 		c.Set(CtxRequestID, "req-123")
 		c.Next()
 	})
@@ -173,3 +174,26 @@ func TestAdminAudit_WriteErrorDoesNotBreakResponse(t *testing.T) {
 		t.Fatalf("expected attempted audit write, got %d entries", len(writer.entries))
 	}
 }
+
+func TestAdminAudit_HandlerCanReplaceQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	writer := &fakeAdminAuditWriter{}
+	r := gin.New()
+	r.Use(AdminAudit(writer))
+
+	r.DELETE("/admin/privacy/users", func(c *gin.Context) {
+		c.Set(CtxAuditQuery, "email=[redacted]")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/privacy/users?email=someone@example.com", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(writer.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(writer.entries))
+	}
+	if got := writer.entries[0].details["query"]; got != "email=[redacted]" {
+		t.Fatalf("expected redacted query, got %v", got)
+	}
+}
@@ -0,0 +1,164 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/apikey"
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderAPIKey carries an organizer API key.
+const HeaderAPIKey = "X-API-Key"
+
+type APIKeyStore interface {
+	GetByHash(ctx context.Context, hash string) (apikey.Key, error)
+}
+
+type EventOrganizers interface {
+	Organizers(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+// RouteScopes maps "METHOD /route/:param" to the action an API key needs for
+// it. Routes missing from the map are closed to API keys.
+type RouteScopes map[string]apikey.Action
+
+type APIKeyMiddleware struct {
+	keys   APIKeyStore
+	events EventOrganizers
+	routes RouteScopes
+}
+
+func NewAPIKeyMiddleware(keys APIKeyStore, events EventOrganizers, routes RouteScopes) *APIKeyMiddleware {
+	return &APIKeyMiddleware{keys: keys, events: events, routes: routes}
+}
+
+// Authenticate resolves the X-API-Key header. Requests without one pass
+// through untouched; an unknown or revoked key is a 401.
+func (m *APIKeyMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.GetHeader(HeaderAPIKey))
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+
+		k, err := m.keys.GetByHash(ctx, apikey.Hash(raw))
+		if err != nil {
+			if errors.Is(err, apikey.ErrNotFound) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": gin.H{
+						"code":    "invalid_api_key",
+						"message": "Invalid or revoked API key",
+					},
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "internal_error",
+					"message": "Could not verify API key",
+				},
+			})
+			return
+		}
+
+		c.Set(CtxAPIKey, k)
+		c.Next()
+	}
+}
+
+// RequireScope checks a key-authenticated request against the route's
+// action and, for /events/:id routes, the key's events. It runs after
+// Authenticate and ignores requests that did not use a key.
+func (m *APIKeyMiddleware) RequireScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k, ok := APIKeyFromContext(c)
+		// unmatched routes fall through to the 404 handler
+		if !ok || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+		action, ok := m.routes[route]
+		if !ok {
+			abortInsufficientScope(c, "API keys cannot access this route", gin.H{"route": route})
+			return
+		}
+
+		if !k.Allows(action) {
+			abortInsufficientScope(c, "API key is missing the required action", gin.H{"missingAction": action})
+			return
+		}
+
+		eventID := strings.ToLower(c.Param("id"))
+		if eventID == "" {
+			c.Next()
+			return
+		}
+
+		covered, err := m.coversEvent(c.Request.Context(), k, eventID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "internal_error",
+					"message": "Could not verify API key scope",
+				},
+			})
+			return
+		}
+		if !covered {
+			abortInsufficientScope(c, "API key is not scoped to this event", gin.H{"eventId": eventID})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (m *APIKeyMiddleware) coversEvent(ctx context.Context, k apikey.Key, eventID string) (bool, error) {
+	if slices.Contains(k.EventIDs, eventID) {
+		return true, nil
+	}
+	if k.OrganizerID == "" {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	organizers, err := m.events.Organizers(ctx, []string{eventID})
+	if err != nil {
+		return false, err
+	}
+
+	organizerID, ok := organizers[eventID]
+	return ok && k.CoversEvent(eventID, organizerID), nil
+}
+
+func abortInsufficientScope(c *gin.Context, message string, details gin.H) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error": gin.H{
+			"code":    "insufficient_scope",
+			"message": message,
+			"details": details,
+		},
+	})
+}
+
+func APIKeyFromContext(c *gin.Context) (apikey.Key, bool) {
+	v, ok := c.Get(CtxAPIKey)
+	if !ok {
+		return apikey.Key{}, false
+	}
+	k, ok := v.(apikey.Key)
+	return k, ok
+}
@@ -0,0 +1,143 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/apikey"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	scopeEventA   = "8a4c9f5e-4a4e-4f3c-9d6e-1f1f1f1f1f1a"
+	scopeEventB   = "8a4c9f5e-4a4e-4f3c-9d6e-1f1f1f1f1f1b"
+	scopeOrgOwner = "organizer-1"
+)
+
+type fakeAPIKeyStore map[string]apikey.Key
+
+func (f fakeAPIKeyStore) GetByHash(ctx context.Context, hash string) (apikey.Key, error) {
+	k, ok := f[hash]
+	if !ok {
+		return apikey.Key{}, apikey.ErrNotFound
+	}
+	return k, nil
+}
+
+type fakeEventOrganizers map[string]string
+
+func (f fakeEventOrganizers) Organizers(ctx context.Context, ids []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, id := range ids {
+		if org, ok := f[id]; ok {
+			out[id] = org
+		}
+	}
+	return out, nil
+}
+
+func newScopedRouter(keys fakeAPIKeyStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	m := NewAPIKeyMiddleware(keys, fakeEventOrganizers{
+		scopeEventA: scopeOrgOwner,
+		scopeEventB: "someone-else",
+	}, RouteScopes{
+		"POST /events/:id/register":    apikey.ActionRegister,
+		"GET /events/:id/availability": apikey.ActionReadAvailability,
+	})
+
+	r := gin.New()
+	r.Use(m.Authenticate())
+	r.Use(m.RequireScope())
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.POST("/events/:id/register", ok)
+	r.GET("/events/:id/availability", ok)
+	r.POST("/admin/events", ok)
+	return r
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	keys := fakeAPIKeyStore{
+		apikey.Hash("ehk_register_a"): {
+			ID: "k1", EventIDs: []string{scopeEventA}, Actions: []apikey.Action{apikey.ActionRegister},
+		},
+		apikey.Hash("ehk_availability_a"): {
+			ID: "k2", EventIDs: []string{scopeEventA}, Actions: []apikey.Action{apikey.ActionReadAvailability},
+		},
+		apikey.Hash("ehk_organizer"): {
+			ID: "k3", OrganizerID: scopeOrgOwner, Actions: []apikey.Action{apikey.ActionRegister, apikey.ActionReadAvailability},
+		},
+	}
+	r := newScopedRouter(keys)
+
+	register := func(eventID string) (string, string) { return http.MethodPost, "/events/" + eventID + "/register" }
+	availability := func(eventID string) (string, string) { return http.MethodGet, "/events/" + eventID + "/availability" }
+	admin := func(string) (string, string) { return http.MethodPost, "/admin/events" }
+
+	tests := []struct {
+		name        string
+		key         string
+		route       func(string) (string, string)
+		eventID     string
+		wantStatus  int
+		wantDetails map[string]any
+	}{
+		{name: "no key passes through", route: admin, wantStatus: http.StatusOK},
+		{name: "unknown key", key: "ehk_nope", route: register, eventID: scopeEventA, wantStatus: http.StatusUnauthorized},
+
+		{name: "register key registers for its event", key: "ehk_register_a", route: register, eventID: scopeEventA, wantStatus: http.StatusOK},
+		{name: "register key on another event", key: "ehk_register_a", route: register, eventID: scopeEventB, wantStatus: http.StatusForbidden, wantDetails: map[string]any{"eventId": scopeEventB}},
+		{name: "register key reading availability", key: "ehk_register_a", route: availability, eventID: scopeEventA, wantStatus: http.StatusForbidden, wantDetails: map[string]any{"missingAction": "read_availability"}},
+		{name: "register key on admin route", key: "ehk_register_a", route: admin, wantStatus: http.StatusForbidden, wantDetails: map[string]any{"route": "POST /admin/events"}},
+
+		{name: "availability key reads availability", key: "ehk_availability_a", route: availability, eventID: scopeEventA, wantStatus: http.StatusOK},
+		{name: "availability key registering", key: "ehk_availability_a", route: register, eventID: scopeEventA, wantStatus: http.StatusForbidden, wantDetails: map[string]any{"missingAction": "register"}},
+
+		{name: "organizer key on own event", key: "ehk_organizer", route: register, eventID: scopeEventA, wantStatus: http.StatusOK},
+		{name: "organizer key availability on own event", key: "ehk_organizer", route: availability, eventID: scopeEventA, wantStatus: http.StatusOK},
+		{name: "organizer key on foreign event", key: "ehk_organizer", route: availability, eventID: scopeEventB, wantStatus: http.StatusForbidden, wantDetails: map[string]any{"eventId": scopeEventB}},
+		{name: "organizer key on admin route", key: "ehk_organizer", route: admin, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			method, path := tc.route(tc.eventID)
+			req := httptest.NewRequest(method, path, nil)
+			if tc.key != "" {
+				req.Header.Set(HeaderAPIKey, tc.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d, body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus != http.StatusForbidden {
+				return
+			}
+
+			var body struct {
+				Error struct {
+					Code    string         `json:"code"`
+					Details map[string]any `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Error.Code != "insufficient_scope" {
+				t.Fatalf("got code %q, want insufficient_scope", body.Error.Code)
+			}
+			for k, v := range tc.wantDetails {
+				if body.Error.Details[k] != v {
+					t.Fatalf("details[%s] = %v, want %v", k, body.Error.Details[k], v)
+				}
+			}
+		})
+	}
+}
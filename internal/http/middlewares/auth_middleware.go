@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/geocoder89/eventhub/internal/auth"
+	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/gin-gonic/gin"
 )
 
@@ -65,6 +66,21 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth identifies the caller when a bearer token is sent and lets
+// anonymous requests through. A token that is sent but invalid is still a 401.
+func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
+	requireAuth := m.RequireAuth()
+
+	return func(c *gin.Context) {
+		if strings.TrimSpace(c.GetHeader("Authorization")) == "" {
+			c.Next()
+			return
+		}
+
+		requireAuth(c)
+	}
+}
+
 // Optional helpers so handlers don’t need to know the magic keys.
 
 func UserIDFromContext(c *gin.Context) (string, bool) {
@@ -76,11 +92,20 @@ func UserIDFromContext(c *gin.Context) (string, bool) {
 	return id, ok
 }
 
-func RoleFromContext(c *gin.Context) (string, bool) {
+func EmailFromContext(c *gin.Context) (string, bool) {
+	v, ok := c.Get(CtxEmail)
+	if !ok {
+		return "", false
+	}
+	email, ok := v.(string)
+	return email, ok
+}
+
+func RoleFromContext(c *gin.Context) (user.Role, bool) {
 	v, ok := c.Get(CtxRole)
 	if !ok {
 		return "", false
 	}
-	role, ok := v.(string)
+	role, ok := v.(user.Role)
 	return role, ok
 }
@@ -9,4 +9,17 @@ const (
 	CtxRequestID ctxKey = "request_id"
 	CtxJobID     ctxKey = "job_id"
 	KeyUserID    ctxKey = "user_id"
+	CtxAPIKey    ctxKey = "api_key"
+
+	// CtxAuditQuery, when set, is recorded by AdminAudit instead of the raw
+	// query string, for routes whose query carries personal data.
+	CtxAuditQuery ctxKey = "audit_query"
+
+	// CtxRouteClass and CtxDBTimeouts pick the DB budget handlers.DBTimeout hands out.
+	CtxRouteClass ctxKey = "route_class"
+	CtxDBTimeouts ctxKey = "db_timeouts"
+
+	// CtxClientDisconnected is set when a handler failed because the client
+	// went away; RequestLogger marks the request with it.
+	CtxClientDisconnected ctxKey = "client_disconnected"
 )
@@ -22,7 +22,7 @@ func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
 				ctx.Header("Access-Control-Allow-Origin", origin)
 				ctx.Header("Access-Control-Allow-Credentials", "true")
 				ctx.Header("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-				ctx.Header("Access-Control-Allow-Headers", "Authorization,Content-Type")
+				ctx.Header("Access-Control-Allow-Headers", "Authorization,Content-Type,X-API-Key")
 			}
 
 		}
@@ -0,0 +1,133 @@
+package middlewares
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/geocoder89/eventhub/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const queryCountHeader = "X-DB-Queries"
+
+type QueryBudgetConfig struct {
+	// warn when a request runs more than this many queries
+	Budget int
+	// replace the response with a 500 when the budget is exceeded (tests)
+	HardFail bool
+}
+
+// QueryBudget counts the DB queries each request runs (via db.QueryTracer),
+// reports the count in X-DB-Queries and logs a warning with the per-statement
+// breakdown when the budget is exceeded. Dev/test only: the response is
+// buffered so the header can carry the final count.
+func QueryBudget(cfg QueryBudgetConfig) gin.HandlerFunc {
+	if cfg.Budget <= 0 {
+		cfg.Budget = 10
+	}
+
+	return func(c *gin.Context) {
+		// a buffered event stream would never reach the client
+		if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+
+		ctx, counter := db.WithQueryCounter(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		orig := c.Writer
+		buf := &bufferedWriter{ResponseWriter: orig, status: http.StatusOK}
+		c.Writer = buf
+
+		c.Next()
+
+		c.Writer = orig
+
+		total := counter.Total()
+		if total > cfg.Budget {
+			route := c.FullPath()
+			if route == "" {
+				route = c.Request.URL.Path
+			}
+
+			reqID, _ := c.Get(CtxRequestID)
+			slog.Default().WarnContext(ctx, "db.query_budget_exceeded",
+				"method", c.Request.Method,
+				"route", route,
+				"queries", total,
+				"budget", cfg.Budget,
+				"breakdown", counter.Breakdown(),
+				"request_id", reqID,
+			)
+
+			if cfg.HardFail {
+				orig.Header().Set(queryCountHeader, strconv.Itoa(total))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": gin.H{
+						"code":    "query_budget_exceeded",
+						"message": "request ran " + strconv.Itoa(total) + " queries, budget is " + strconv.Itoa(cfg.Budget),
+						"details": counter.Breakdown(),
+					},
+				})
+				return
+			}
+		}
+
+		orig.Header().Set(queryCountHeader, strconv.Itoa(total))
+		buf.flush()
+	}
+}
+
+// bufferedWriter holds the status and body until QueryBudget decides what to send.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.wroteHeader {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.wroteHeader = true
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.wroteHeader = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int { return w.status }
+
+func (w *bufferedWriter) Size() int {
+	if !w.wroteHeader {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool { return w.wroteHeader }
+
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	} else {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
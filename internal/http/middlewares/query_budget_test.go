@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// chattyHandler runs one list query and then one lookup per item: a classic N+1.
+func chattyHandler(items int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tracer := db.QueryTracer{}
+		ctx := c.Request.Context()
+
+		tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT id FROM events LIMIT 20"})
+		for i := 0; i < items; i++ {
+			tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT COUNT(*)\n\t\tFROM registrations WHERE event_id = $1"})
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestQueryBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		items       int
+		hardFail    bool
+		wantStatus  int
+		wantHeader  string
+		wantWarning bool
+	}{
+		{name: "within budget", items: 3, wantStatus: http.StatusOK, wantHeader: "4"},
+		{name: "over budget warns", items: 12, wantStatus: http.StatusOK, wantHeader: "13", wantWarning: true},
+		{name: "over budget hard fails", items: 12, hardFail: true, wantStatus: http.StatusInternalServerError, wantHeader: "13", wantWarning: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			r := gin.New()
+			r.Use(QueryBudget(QueryBudgetConfig{Budget: 10, HardFail: tc.hardFail}))
+			r.GET("/chatty", chattyHandler(tc.items))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/chatty", nil))
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status=%d want=%d body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("X-DB-Queries"); got != tc.wantHeader {
+				t.Fatalf("X-DB-Queries=%q want %q", got, tc.wantHeader)
+			}
+
+			warned := strings.Contains(logs.String(), "db.query_budget_exceeded")
+			if warned != tc.wantWarning {
+				t.Fatalf("warning logged=%v want %v; logs=%s", warned, tc.wantWarning, logs.String())
+			}
+			if tc.wantWarning && !strings.Contains(logs.String(), "SELECT COUNT(*) FROM registrations WHERE event_id = $1") {
+				t.Fatalf("expected breakdown to name the repeated statement; logs=%s", logs.String())
+			}
+
+			if tc.hardFail {
+				if !strings.Contains(w.Body.String(), "query_budget_exceeded") {
+					t.Fatalf("expected query_budget_exceeded error body, got %s", w.Body.String())
+				}
+			} else if w.Body.String() != `{"ok":true}` {
+				t.Fatalf("expected handler body to pass through, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -15,6 +15,9 @@ type RateLimiter struct {
 	window  time.Duration
 	limit   int
 	clients map[string]*clientBucket
+
+	onLimited func()
+	now       func() time.Time
 }
 
 type clientBucket struct {
@@ -27,7 +30,23 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 		limit:   limit,
 		window:  window,
 		clients: make(map[string]*clientBucket),
+		now:     time.Now,
+	}
+}
+
+// WithOnLimited calls fn each time a request is turned away, e.g. to count
+// login lockouts.
+func (rl *RateLimiter) WithOnLimited(fn func()) *RateLimiter {
+	rl.onLimited = fn
+	return rl
+}
+
+// WithClock replaces time.Now for window bookkeeping, so tests can move time.
+func (rl *RateLimiter) WithClock(now func() time.Time) *RateLimiter {
+	if now != nil {
+		rl.now = now
 	}
+	return rl
 }
 
 // Middleware returns a gin.HandlerFunc that enforces rate limit for a derived key
@@ -42,7 +61,7 @@ func (rl *RateLimiter) RateLimiterMiddleware(keyFn func(*gin.Context) string) gi
 			key = clientIP(c)
 		}
 
-		now := time.Now()
+		now := rl.now()
 
 		rl.mu.Lock()
 
@@ -60,7 +79,7 @@ func (rl *RateLimiter) RateLimiterMiddleware(keyFn func(*gin.Context) string) gi
 		}
 
 		if b.count >= rl.limit {
-			retryAfter := int(time.Until(b.windowEnd).Seconds())
+			retryAfter := int(b.windowEnd.Sub(now).Seconds())
 
 			if retryAfter < 0 {
 				retryAfter = 0
@@ -68,6 +87,10 @@ func (rl *RateLimiter) RateLimiterMiddleware(keyFn func(*gin.Context) string) gi
 
 			rl.mu.Unlock()
 
+			if rl.onLimited != nil {
+				rl.onLimited()
+			}
+
 			c.Header("Retry-After", itoa(retryAfter))
 
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
@@ -3,10 +3,13 @@ package middlewares
 import (
 	"net/http"
 
+	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/gin-gonic/gin"
 )
 
-func (m *AuthMiddleware) RequireRole(required string) gin.HandlerFunc {
+// RequireRole lets through only callers whose token carries required. Roles
+// compare exactly, so a token minted with "Admin" is not an admin.
+func (m *AuthMiddleware) RequireRole(required user.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, ok := RoleFromContext(c)
 
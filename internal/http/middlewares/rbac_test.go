@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireRole_ComparesTypedRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name string
+		role any
+		want int
+	}{
+		{"admin", user.RoleAdmin, http.StatusOK},
+		{"user", user.RoleUser, http.StatusForbidden},
+		{"near miss from an old token", user.Role("Admin"), http.StatusForbidden},
+		{"untyped string", "admin", http.StatusUnauthorized},
+		{"missing", nil, http.StatusUnauthorized},
+	}
+
+	m := &AuthMiddleware{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/admin", func(c *gin.Context) {
+				if tc.role != nil {
+					c.Set(CtxRole, tc.role)
+				}
+				c.Next()
+			}, m.RequireRole(user.RoleAdmin), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+			if w.Code != tc.want {
+				t.Fatalf("got status %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
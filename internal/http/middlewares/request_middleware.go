@@ -56,6 +56,10 @@ func RequestLogger() gin.HandlerFunc {
 			"request_id", reqID,
 		}
 
+		if ctx.GetBool(CtxClientDisconnected) {
+			logAttrs = append(logAttrs, "client_disconnected", true)
+		}
+
 		if jobID, ok := ctx.Get(CtxJobID); ok {
 			if jobIDStr, ok := jobID.(string); ok && jobIDStr != "" {
 				logAttrs = append(logAttrs, "job_id", jobIDStr)
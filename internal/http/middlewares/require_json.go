@@ -7,7 +7,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// BodyContentTypes maps "METHOD /route/:param" to extra media types, besides
+// JSON, that the route accepts as a request body.
+type BodyContentTypes map[string][]string
+
 func RequireJSON() gin.HandlerFunc {
+	return RequireJSONOr(nil)
+}
+
+// RequireJSONOr is RequireJSON with per-route exceptions, e.g. a CSV upload.
+func RequireJSONOr(alt BodyContentTypes) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		switch c.Request.Method {
 		case http.MethodPost, http.MethodPut, http.MethodPatch:
@@ -16,9 +25,9 @@ func RequireJSON() gin.HandlerFunc {
 				return
 			}
 
-			ct := c.GetHeader("Content-Type")
+			ct := strings.ToLower(c.GetHeader("Content-Type"))
 			// allow "application/json; charset=utf-8"
-			if ct == "" || !strings.HasPrefix(strings.ToLower(ct), "application/json") {
+			if ct == "" || !(strings.HasPrefix(ct, "application/json") || acceptsAlt(alt[c.Request.Method+" "+c.FullPath()], ct)) {
 				c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
 					"error": gin.H{
 						"code":    "unsupported_media_type",
@@ -32,6 +41,15 @@ func RequireJSON() gin.HandlerFunc {
 	}
 }
 
+func acceptsAlt(types []string, ct string) bool {
+	for _, t := range types {
+		if strings.HasPrefix(ct, t) {
+			return true
+		}
+	}
+	return false
+}
+
 func hasRequestBody(r *http.Request) bool {
 	if r.ContentLength > 0 {
 		return true
@@ -73,3 +73,34 @@ func TestRequireJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireJSONOr_RouteExceptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequireJSONOr(BodyContentTypes{"POST /upload/:id": {"text/csv"}}))
+	r.POST("/upload/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/other", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		path        string
+		contentType string
+		wantStatus  int
+	}{
+		{path: "/upload/1", contentType: "text/csv; charset=utf-8", wantStatus: http.StatusOK},
+		{path: "/upload/1", contentType: "application/json", wantStatus: http.StatusOK},
+		{path: "/upload/1", contentType: "text/plain", wantStatus: http.StatusUnsupportedMediaType},
+		{path: "/other", contentType: "text/csv", wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("name,email\n"))
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Fatalf("%s %s: got %d want %d", tt.path, tt.contentType, w.Code, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// DBTimeouts makes the configured per-class DB budgets available to handlers.
+func DBTimeouts(t config.DBTimeouts) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(CtxDBTimeouts, t)
+		c.Next()
+	}
+}
+
+// RouteClass tags the routes it guards with a DB budget class. Groups can be
+// nested; the innermost class wins.
+func RouteClass(class config.RouteClass) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(CtxRouteClass, class)
+		c.Next()
+	}
+}
+
+// RouteClassFromContext returns the route's class, RouteClassDefault when unset.
+func RouteClassFromContext(c *gin.Context) config.RouteClass {
+	if v, ok := c.Get(CtxRouteClass); ok {
+		if class, ok := v.(config.RouteClass); ok {
+			return class
+		}
+	}
+	return config.RouteClassDefault
+}
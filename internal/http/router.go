@@ -5,39 +5,72 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/geocoder89/eventhub/internal/auth"
-	"github.com/geocoder89/eventhub/internal/cache"
+	"github.com/geocoder89/eventhub/internal/canceltoken"
 	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/apikey"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/eventchanges"
+	"github.com/geocoder89/eventhub/internal/exportlink"
+	"github.com/geocoder89/eventhub/internal/funnel"
 	"github.com/geocoder89/eventhub/internal/http/handlers"
 	"github.com/geocoder89/eventhub/internal/http/middlewares"
 	"github.com/geocoder89/eventhub/internal/observability"
-	"github.com/geocoder89/eventhub/internal/queue/redisclient"
+	"github.com/geocoder89/eventhub/internal/security"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
-	// "github.com/geocoder89/eventhub/internal/repo/memory"
-	"github.com/geocoder89/eventhub/internal/repo/postgres"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewRouter(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config) *gin.Engine {
+// NewRouter builds the production API on pool with its own metrics registry.
+// stop ends the router's background work; see NewRouterWithDeps.
+func NewRouter(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config) (r *gin.Engine, stop func()) {
+	reg := prometheus.NewRegistry()
+
+	return NewRouterWithMetrics(log, pool, cfg, reg, observability.NewProm(reg))
+}
+
+// NewRouterWithMetrics builds the API router on a caller-owned Prometheus
+// registry, so an embedded worker can report into the same /metrics.
+func NewRouterWithMetrics(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config, reg *prometheus.Registry, prom *observability.Prom) (r *gin.Engine, stop func()) {
+	// gin's mode is process-wide, so only the production wiring sets it
 	if cfg.Env != "dev" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	redis := redisclient.New(redisclient.Config{
-		Addr:     cfg.RedisAddr,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisDB,
-	})
-	r := gin.New()
+	return NewRouterWithDeps(PostgresDependencies(log, pool, cfg, reg, prom))
+}
 
-	// Prometheus set up
+// NewRouterWithDeps builds the API router from deps alone; see Dependencies.
+// stop flushes what the router still buffers (funnel counts) and ends its
+// background goroutines; call it once the server has stopped taking
+// requests. It is safe to call more than once.
+func NewRouterWithDeps(deps Dependencies) (r *gin.Engine, stop func()) {
+	cfg := deps.Config
+	log := deps.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+	reg, prom := deps.Registry, deps.Metrics
+	if reg == nil || prom == nil {
+		reg = prometheus.NewRegistry()
+		prom = observability.NewProm(reg)
+	}
+	readyCheck := deps.ReadyCheck
+	if readyCheck == nil {
+		readyCheck = func() error { return nil }
+	}
+	newRateLimiter := deps.RateLimiter
+	if newRateLimiter == nil {
+		newRateLimiter = func(limit int, window time.Duration) *middlewares.RateLimiter {
+			return middlewares.NewRateLimiter(limit, window).WithClock(deps.Clock)
+		}
+	}
 
-	reg := prometheus.NewRegistry()
-	prom := observability.NewProm(reg)
+	r = gin.New()
 
 	// middleware
 
@@ -47,82 +80,149 @@ func NewRouter(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config) *gin.Eng
 	r.Use(middlewares.TraceEnrichment())
 	r.Use(prom.GinHandleMiddleware())
 	r.Use(middlewares.RequestLogger())
+	if cfg.QueryBudgetEnabled() {
+		r.Use(middlewares.QueryBudget(middlewares.QueryBudgetConfig{
+			Budget:   cfg.QueryBudget,
+			HardFail: cfg.QueryBudgetHardFail,
+		}))
+	}
 	r.Use(middlewares.CORSMiddleware([]string{
 		"http://localhost:3000",
 	}))
 	r.Use(middlewares.SecurityHeaders())
 	r.Use(middlewares.MaxBodyBytes(1 << 20)) //1MB max body
-	r.Use(middlewares.RequireJSON())         // Require JSON content type for post and put requests.
-
-	readyCheck := func() error {
-		// postgres ping
-		if pool != nil {
-
-			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-			defer cancel()
-			err := pool.Ping(ctx)
-
-			if err != nil {
-				return err
-			}
-		}
-
-		// Redis ping
-
-		{
-			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-			defer cancel()
+	r.Use(middlewares.DBTimeouts(cfg.DBTimeouts()))
+	// Require JSON content type for post and put requests, CSV allowed where listed.
+	r.Use(middlewares.RequireJSONOr(middlewares.BodyContentTypes{
+		"POST /admin/events/:id/registrations/import": {"text/csv"},
+	}))
 
-			err := redis.Ping(ctx)
+	// health
+	h := handlers.NewHealthHandler(readyCheck)
 
-			if err != nil {
-				return err
-			}
+	eventsRepo := deps.Events
+	registrationRepo := deps.Registrations
+	jobsRepo := deps.Jobs
+	registrationCSVExportsRepo := deps.RegistrationExports
+	jwtManager := deps.Tokens
+
+	// funnel counters are buffered in memory and flushed in batches
+	var funnelRecorder handlers.FunnelRecorder
+	stop = func() {}
+	if deps.Funnel != nil {
+		recorder := funnel.NewRecorder(deps.Funnel, 10*time.Second)
+		bgCtx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			recorder.Run(bgCtx)
+		}()
+		stop = func() {
+			cancel()
+			<-done
 		}
-
-		return nil
+		funnelRecorder = recorder
 	}
 
-	// health
-	h := handlers.NewHealthHandler(readyCheck)
-
-	// events stored in memory for now
-
-	// eventsRepo := memory.NewEventsRepo()
-	// change to postgres
-
-	// wire up repositories
-	eventsRepo := postgres.NewEventsRepo(pool, prom)
-	registrationRepo := postgres.NewRegistrationsRepo(pool, prom)
-	usersRepo := postgres.NewUsersRepo(pool)
-	refreshTokensRepo := postgres.NewRefreshTokensRepo(pool)
-	jobsRepo := postgres.NewJobsRepo(pool, prom)
-	adminActionAuditsRepo := postgres.NewAdminActionAuditsRepo(pool)
-	registrationCSVExportsRepo := postgres.NewRegistrationCSVExportsRepo(pool)
-
-	// events cache
-	eventsCache := cache.New(10 * time.Second)
-
-	// JWT Manager
-	jwtManager := auth.NewManager(
-		cfg.JWTSecret,
-		time.Duration(cfg.JWTAccessTTLMinutes)*time.Minute, // 60mins
-		time.Duration(cfg.JWTRefreshTTLDays)*24*time.Hour,
-	)
 	// Wire up more handler
-	eventsHandler := handlers.NewEventsHandlerWithCache(eventsRepo, eventsCache)
-	registrationHandler := handlers.NewRegistrationHandler(registrationRepo, jobsRepo)
-	jobsHandler := handlers.NewJobsHandler(jobsRepo, registrationCSVExportsRepo)
-	authHandler := handlers.NewAuthHandler(usersRepo, usersRepo, jwtManager, refreshTokensRepo, cfg)
-	adminJobsHandler := handlers.NewAdminJobsHandler(jobsRepo)
+	editLocksHandler := handlers.NewEditLocksHandler(deps.EditLocks)
+	eventsHandler := handlers.NewEventsHandlerWithCache(eventsRepo, deps.EventsCache).WithMetrics(prom).WithEditLocks(editLocksHandler).WithRegistrants(registrationRepo)
+	registrationHandler := handlers.NewRegistrationHandler(registrationRepo).
+		WithFunnel(funnelRecorder).
+		WithCancelTokens(canceltoken.NewSigner(cfg.CancelTokenSigningSecret(), cfg.CancelTokenTTL())).
+		WithBranding(eventsRepo).
+		WithOrganizers(eventsRepo)
+	eventBrandingHandler := handlers.NewEventBrandingHandler(eventsRepo)
+	registrationHistoryHandler := handlers.NewRegistrationHistoryHandler(registrationRepo, eventsRepo)
+	registrationEmailHandler := handlers.NewRegistrationEmailHandler(registrationRepo, eventsRepo)
+	enqueueGuard := handlers.NewEnqueueGuard(jobsRepo, map[handlers.EnqueueClass]handlers.BackpressureLimits{
+		handlers.EnqueueStandard:   {MaxPending: int64(cfg.EnqueueGuardStandardMaxPending), MaxAge: cfg.EnqueueGuardStandardMaxAge},
+		handlers.EnqueueDeferrable: {MaxPending: int64(cfg.EnqueueGuardDeferrableMaxPending), MaxAge: cfg.EnqueueGuardDeferrableMaxAge},
+	}).WithClock(deps.Clock).WithMetrics(prom)
+	jobsHandler := handlers.NewJobsHandler(jobsRepo, registrationCSVExportsRepo).
+		WithResponseStore(deps.IdempotentResponses).
+		WithExportKeepMax(cfg.ExportKeepMax()).
+		WithEnqueueGuard(enqueueGuard).
+		WithPublishBatch(deps.PublishBatch)
+	if deps.ExportStore != nil {
+		jobsHandler.WithExportStore(deps.ExportStore)
+	}
+	exportsHandler := handlers.NewExportsHandler(jobsRepo, registrationCSVExportsRepo).
+		WithRetention(cfg.ExportRetention())
+	accountExportHandler := handlers.NewAccountExportHandler(jobsRepo, deps.ExportStore,
+		exportlink.NewSigner(cfg.ExportLinkSigningSecret(), cfg.ExportLinkTTL()))
+	registrationImportHandler := handlers.NewRegistrationImportHandler(registrationRepo, jobsRepo).WithBranding(eventsRepo)
+	adminRegistrationsHandler := handlers.NewAdminRegistrationsHandler(registrationRepo)
+	emailVerifySigner := emailverify.NewSigner(cfg.EmailVerifySigningSecret(), cfg.EmailVerifyTTL())
+	emailVerificationHandler := handlers.NewEmailVerificationHandler(deps.Users, jobsRepo, emailVerifySigner)
+	authHandler := handlers.NewAuthHandler(deps.Users, deps.Users, jwtManager, deps.RefreshTokens, cfg).
+		WithMetrics(prom)
+	if passwords, err := security.NewPasswords(cfg.PasswordParams()); err != nil {
+		// config validation should have caught this; keep the default scheme
+		log.Error("password hashing config invalid", "err", err)
+	} else {
+		authHandler.WithPasswords(passwords)
+	}
+	bulkDefault, bulkMax := cfg.AdminBulkLimits()
+	adminJobsHandler := handlers.NewAdminJobsHandler(jobsRepo).
+		WithBulkLimits(handlers.BulkLimits{Default: bulkDefault, Max: bulkMax}).
+		WithClock(deps.Clock)
+	if deps.JobAttempts != nil {
+		adminJobsHandler.WithAttempts(deps.JobAttempts)
+	}
+	adminDeadLettersHandler := handlers.NewAdminDeadLettersHandler(jobsRepo)
+	adminQueueHandler := handlers.NewAdminQueueHandler(jobsRepo)
+	funnelHandler := handlers.NewFunnelHandler(deps.Funnel).WithAttendance(deps.Attendance)
+	attendanceHandler := handlers.NewAttendanceHandler(deps.Attendance)
+	myRegistrationsHandler := handlers.NewMyRegistrationsHandler(registrationRepo)
+	eventCountersHandler := handlers.NewEventCountersHandler(deps.EventCounters).WithFunnel(funnelRecorder)
+	publicStatsHandler := handlers.NewPublicStatsHandlerWithCache(deps.PlatformStats, deps.EventsCache).WithClock(deps.Clock)
+	if deps.EventChanges != nil {
+		eventCountersHandler.WithLiveAvailability(eventchanges.NewHub(deps.EventChanges))
+	}
+	apiKeysHandler := handlers.NewAPIKeysHandler(deps.APIKeys, eventsRepo)
+	privacyHandler := handlers.NewPrivacyHandler(deps.Privacy)
+	webhooksHandler := handlers.NewWebhooksHandler(deps.Webhooks)
+	suppressionsHandler := handlers.NewSuppressionsHandler(deps.Suppressions)
+	schedulesHandler := handlers.NewSchedulesHandler(deps.Schedules)
+	jobStatsHandler := handlers.NewJobStatsHandler(deps.JobStats, observability.LiveJobCounts)
+	debugHandler := handlers.NewDebugHandler(observability.RecentHTTPErrors, observability.RecentJobErrors, observability.InstanceID())
+	diagnosticsHandler := handlers.NewDiagnosticsHandler(cfg, observability.InstanceID()).
+		WithPoolStats(deps.PoolStats).
+		WithConfigDrift(deps.ServiceInstances).
+		WithCache("events", deps.EventsCache.Len).
+		WithFeatures(map[string]bool{
+			"eventsCache":      deps.EventsCache != nil,
+			"funnelRecording":  deps.Funnel != nil,
+			"liveAvailability": deps.EventChanges != nil,
+			"jobAttempts":      deps.JobAttempts != nil,
+			"adminAudit":       deps.AdminAudits != nil,
+			"exportStore":      deps.ExportStore != nil,
+			"queryBudget":      cfg.QueryBudgetEnabled(),
+			"jobAging":         cfg.JobAgingInterval > 0,
+			"slackAlerts":      cfg.AlertSlackWebhookURL != "",
+		})
 	authMiddleware := middlewares.NewAuthMiddleware(jwtManager)
 
+	// organizer API keys only reach the routes listed here, for the events they cover
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(deps.APIKeys, eventsRepo, middlewares.RouteScopes{
+		"POST /events/:id/register":           apikey.ActionRegister,
+		"GET /events/:id/availability":        apikey.ActionReadAvailability,
+		"GET /events/:id/availability/stream": apikey.ActionReadAvailability,
+	})
+	r.Use(apiKeyMiddleware.Authenticate())
+	r.Use(apiKeyMiddleware.RequireScope())
+
 	// rate limiter middleware
 
-	loginLimiter := middlewares.NewRateLimiter(5, 1*time.Minute)
-	signupLimiter := middlewares.NewRateLimiter(3, 1*time.Minute)
-	refreshLimiter := middlewares.NewRateLimiter(10, 1*time.Minute)
-	registerLimiter := middlewares.NewRateLimiter(5, 1*time.Minute)
+	loginLimiter := newRateLimiter(5, 1*time.Minute).WithOnLimited(prom.IncAuthLockout)
+	signupLimiter := newRateLimiter(3, 1*time.Minute)
+	refreshLimiter := newRateLimiter(10, 1*time.Minute)
+	registerLimiter := newRateLimiter(5, 1*time.Minute)
+	cancelLimiter := newRateLimiter(10, 1*time.Minute)
+	downloadLimiter := newRateLimiter(10, 1*time.Minute)
+	verifyEmailLimiter := newRateLimiter(10, 1*time.Minute)
+	streamLimiter := newRateLimiter(10, 1*time.Minute)
 
 	// public routes
 	r.GET("/healthz", h.Healthz)
@@ -137,10 +237,34 @@ func NewRouter(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config) *gin.Eng
 	r.POST("/login", loginLimiter.RateLimiterMiddleware(middlewares.KeyByIP), authHandler.Login)
 	r.POST("/auth/refresh", refreshLimiter.RateLimiterMiddleware(middlewares.KeyByIP), authHandler.Refresh)
 	r.POST("/auth/logout", authHandler.Logout)
+	// the link from the verification email, so no bearer token
+	r.POST("/auth/verify-email", verifyEmailLimiter.RateLimiterMiddleware(middlewares.KeyByIP), emailVerificationHandler.Verify)
 
 	// public events browsing.
 	r.GET("/events", eventsHandler.ListEvents)
-	r.GET("/events/:id", eventsHandler.GetEventById)
+	r.GET("/events/batch", authMiddleware.OptionalAuth(), eventsHandler.GetEventsBatch)
+	r.GET("/events/:id", authMiddleware.OptionalAuth(), eventsHandler.GetEventById)
+
+	// every other route under /events/:id answers a private event's
+	// outsiders exactly as GET /events/:id does: 404
+	eventVisible := handlers.RequireEventVisible(eventsRepo, registrationRepo)
+
+	r.GET("/events/:id/availability", authMiddleware.OptionalAuth(), eventVisible, eventCountersHandler.GetAvailability)
+	// live seats for the registration page; connections are capped at 5 minutes
+	r.GET("/events/:id/availability/stream", streamLimiter.RateLimiterMiddleware(middlewares.KeyByIP), authMiddleware.OptionalAuth(), eventVisible, eventCountersHandler.StreamAvailability)
+
+	// landing page counters: deliberately without a rate limiter, since it is
+	// answered from a 10 minute cache that one query refills
+	r.GET("/public/stats", publicStatsHandler.Get)
+
+	// open to anonymous users unless the event requires auth; a token, when sent, identifies the registrant
+	r.POST("/events/:id/register", authMiddleware.OptionalAuth(), registerLimiter.RateLimiterMiddleware(middlewares.KeyByUserOrIP), eventVisible, registrationHandler.Register)
+
+	// self-service cancellation via the signed link in the confirmation
+	r.DELETE("/registrations/cancel", cancelLimiter.RateLimiterMiddleware(middlewares.KeyByIP), registrationHandler.CancelByToken)
+
+	// account export download via the signed link in the completion email
+	r.GET("/me/export/download", downloadLimiter.RateLimiterMiddleware(middlewares.KeyByIP), middlewares.RouteClass(config.RouteClassExport), accountExportHandler.Download)
 
 	// authenticated routes only authenticated users, can access this route.
 
@@ -149,38 +273,98 @@ func NewRouter(log *slog.Logger, pool *pgxpool.Pool, cfg config.Config) *gin.Eng
 	authed.Use(authMiddleware.RequireAuth())
 
 	{
-		authed.POST("/events/:id/register", registerLimiter.RateLimiterMiddleware(middlewares.KeyByUserOrIP), registrationHandler.Register)
-		authed.GET("/events/:id/registrations", registrationHandler.ListForEvent)
-		authed.DELETE("/events/:id/registrations/:registrationId", registrationHandler.Cancel)
+		authed.GET("/events/:id/registrations", eventVisible, registrationHandler.ListForEvent)
+		authed.DELETE("/events/:id/registrations/:registrationId", eventVisible, registrationHandler.Cancel)
+		authed.POST("/events/:id/registrations/:registrationId/checkin", eventVisible, registrationHandler.CheckInByID)
+		authed.GET("/events/:id/registrations/:registrationId/history", eventVisible, registrationHistoryHandler.History)
+		authed.PATCH("/events/:id/registrations/:registrationId/email", eventVisible, registrationEmailHandler.ChangeEmail)
+		authed.GET("/events/:id/registration-activity", eventVisible, registrationHistoryHandler.Activity)
+		authed.GET("/events/:id/branding", eventVisible, eventBrandingHandler.Get)
+		authed.PUT("/events/:id/branding", eventVisible, eventBrandingHandler.Update)
+
+		authed.POST("/api-keys", apiKeysHandler.Create)
+		authed.GET("/api-keys", apiKeysHandler.List)
+		authed.DELETE("/api-keys/:id", apiKeysHandler.Revoke)
+
+		authed.POST("/me/export", accountExportHandler.Request)
+		authed.POST("/me/email-verification", emailVerificationHandler.Request)
+		authed.GET("/me/attendance-stats", attendanceHandler.MyStats)
+		authed.GET("/me/registrations", myRegistrationsHandler.List)
+		authed.POST("/me/registrations/claim", myRegistrationsHandler.Claim)
 
 	}
 
 	// admin authorized route set up.
 
 	admin := authed.Group("/admin")
-	admin.Use(authMiddleware.RequireRole("admin"))
-	admin.Use(middlewares.AdminAudit(adminActionAuditsRepo))
+	admin.Use(authMiddleware.RequireRole(user.RoleAdmin))
+	admin.Use(middlewares.AdminAudit(deps.AdminAudits))
+	admin.Use(middlewares.RouteClass(config.RouteClassAdmin))
+
+	// bulk reads and writes get the longer export budget
+	exportClass := middlewares.RouteClass(config.RouteClassExport)
 
 	{
 		// admin ops endpoints
 		admin.GET("/jobs", adminJobsHandler.List)
+		admin.GET("/jobs/stats", jobStatsHandler.Daily)
+		admin.GET("/jobs/scheduled", adminJobsHandler.Scheduled)
 		admin.GET("/jobs/:id", adminJobsHandler.GetByID)
+		admin.GET("/jobs/:id/attempts", adminJobsHandler.Attempts)
 		admin.POST("/jobs/:id/retry", adminJobsHandler.Retry)
+		admin.POST("/jobs/:id/cancel", adminJobsHandler.Cancel)
 		admin.POST("/jobs/reprocess-dead", adminJobsHandler.ReprocessDead)
+		admin.DELETE("/jobs/purge", adminJobsHandler.Purge)
+		admin.POST("/jobs", jobsHandler.EnqueueJob)
+		admin.POST("/jobs/payload-report", jobsHandler.RequestPayloadReport)
+		admin.GET("/dead-letters", adminDeadLettersHandler.List)
+		admin.GET("/dead-letters/:id", adminDeadLettersHandler.GetByID)
+		admin.POST("/dead-letters/:id/replay", adminDeadLettersHandler.Replay)
+		admin.GET("/queue", adminQueueHandler.State)
+		admin.POST("/queue/pause", adminQueueHandler.Pause)
+		admin.POST("/queue/resume", adminQueueHandler.Resume)
+		admin.POST("/queue/types/:type/pause", adminQueueHandler.PauseType)
+		admin.POST("/queue/types/:type/resume", adminQueueHandler.ResumeType)
 
 		// admin events crud
 		admin.POST("/events", eventsHandler.CreateEvent)
 		admin.PUT("/events/:id", eventsHandler.UpdateEvent)
 		admin.DELETE("/events/:id", eventsHandler.DeleteEvent)
 		admin.POST("/events/:id/restore", eventsHandler.RestoreEvent)
+		admin.POST("/events/:id/edit-lock", editLocksHandler.Acquire)
+		admin.GET("/events/:id/edit-lock", editLocksHandler.Get)
+		admin.DELETE("/events/:id/edit-lock", editLocksHandler.Release)
+		admin.GET("/events/:id/funnel", funnelHandler.GetEventFunnel)
+		admin.POST("/events/:id/recount", eventCountersHandler.Recount)
 		admin.POST("/events/:id/registrations/check-in", registrationHandler.CheckIn)
 		admin.POST("/events/:id/registrations/export", jobsHandler.ExportRegistrationsCSV)
+		admin.POST("/events/:id/registrations/import", exportClass, registrationImportHandler.Import)
 		admin.POST("/events/:id/publish", jobsHandler.PublishEvent)
-		admin.GET("/jobs/:id/registrations-export.csv", jobsHandler.DownloadRegistrationsCSV)
+		admin.POST("/events/publish-batch", jobsHandler.PublishBatch)
+		admin.GET("/jobs/:id/registrations-export.csv", exportClass, jobsHandler.DownloadRegistrationsCSV)
+		admin.GET("/exports", exportsHandler.List)
+		admin.GET("/exports/:id", exportsHandler.Get)
+		admin.GET("/registrations", adminRegistrationsHandler.Search)
+		admin.DELETE("/privacy/users", exportClass, privacyHandler.EraseUser)
+		admin.POST("/webhooks", webhooksHandler.Create)
+		admin.GET("/webhooks", webhooksHandler.List)
+		admin.GET("/webhooks/:id", webhooksHandler.Get)
+		admin.PUT("/webhooks/:id", webhooksHandler.Update)
+		admin.DELETE("/webhooks/:id", webhooksHandler.Delete)
+		admin.GET("/webhooks/:id/deliveries", webhooksHandler.Deliveries)
+		admin.GET("/suppressions", suppressionsHandler.List)
+		admin.DELETE("/suppressions/:email", suppressionsHandler.Lift)
+		admin.POST("/schedules", schedulesHandler.Create)
+		admin.GET("/schedules", schedulesHandler.List)
+		admin.GET("/schedules/:id", schedulesHandler.Get)
+		admin.PUT("/schedules/:id", schedulesHandler.Update)
+		admin.DELETE("/schedules/:id", schedulesHandler.Delete)
+		admin.GET("/debug/recent-errors", debugHandler.RecentErrors)
+		admin.GET("/diagnostics", diagnosticsHandler.Get)
 	}
 
 	// prometheus endpoint
 	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
 
-	return r
+	return r, stop
 }
@@ -0,0 +1,235 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/funnel"
+	apphttp "github.com/geocoder89/eventhub/internal/http"
+	"github.com/geocoder89/eventhub/internal/repo/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type recordingAudits struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (a *recordingAudits) Write(ctx context.Context, actorUserID, actorEmail, actorRole, action, resourceType, resourceID, requestID string, statusCode int, details map[string]any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func newMemoryRouter(t *testing.T) (*gin.Engine, apphttp.Dependencies, *recordingAudits) {
+	t.Helper()
+
+	cfg := config.Config{
+		Env:                 "dev",
+		JWTSecret:           "router-test-secret",
+		JWTAccessTTLMinutes: 15,
+		JWTRefreshTTLDays:   1,
+	}
+	audits := &recordingAudits{}
+	deps := apphttp.Dependencies{
+		Config:      cfg,
+		Events:      memory.NewEventsRepo(),
+		Tokens:      apphttp.NewTokenManager(cfg),
+		AdminAudits: audits,
+	}
+	r, stop := apphttp.NewRouterWithDeps(deps)
+	t.Cleanup(stop)
+	return r, deps, audits
+}
+
+func serve(router *gin.Engine, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouterWithDeps_AuthenticatedCreateThenGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router, deps, audits := newMemoryRouter(t)
+	body := `{"title":"Router Meetup","city":"Lagos","startAt":"2030-01-02T15:04:05Z","capacity":40}`
+
+	if w := serve(router, http.MethodPost, "/admin/events", body, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	userToken, err := deps.Tokens.GenerateAccessToken(uuid.NewString(), "user@example.com", "user")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	if w := serve(router, http.MethodPost, "/admin/events", body, userToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a non-admin, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	adminID := uuid.NewString()
+	adminToken, err := deps.Tokens.GenerateAccessToken(adminID, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	w := serve(router, http.MethodPost, "/admin/events", body, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body=%s", w.Code, w.Body.String())
+	}
+	var created event.Event
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if created.OrganizerID != adminID {
+		t.Fatalf("expected the caller to own the event, got organizer %q", created.OrganizerID)
+	}
+
+	w = serve(router, http.MethodGet, "/events/"+created.ID, "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	var got event.Event
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode get: %v", err)
+	}
+	if got.ID != created.ID || got.Title != "Router Meetup" || got.Capacity != 40 {
+		t.Fatalf("unexpected event %+v", got)
+	}
+
+	if w := serve(router, http.MethodGet, "/events/"+uuid.NewString(), "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown event, got %d", w.Code)
+	}
+
+	audits.mu.Lock()
+	defer audits.mu.Unlock()
+	if len(audits.actions) != 1 {
+		t.Fatalf("expected the admin create to be audited once, got %v", audits.actions)
+	}
+}
+
+func TestRouterWithDeps_RoutersBuiltConcurrentlyAreIndependent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const n = 4
+	routers := make([]*gin.Engine, n)
+	tokens := make([]string, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			router, deps, _ := newMemoryRouter(t)
+			token, err := deps.Tokens.GenerateAccessToken(uuid.NewString(), "admin@example.com", "admin")
+			if err != nil {
+				t.Errorf("token: %v", err)
+				return
+			}
+			routers[i], tokens[i] = router, token
+		}()
+	}
+	wg.Wait()
+
+	w := serve(routers[0], http.MethodPost, "/admin/events", `{"title":"Only Here","startAt":"2030-01-02T15:04:05Z","capacity":5}`, tokens[0])
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body=%s", w.Code, w.Body.String())
+	}
+	var created event.Event
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+
+	for i := 1; i < n; i++ {
+		if w := serve(routers[i], http.MethodGet, "/events/"+created.ID, "", ""); w.Code != http.StatusNotFound {
+			t.Fatalf("router %d sees another router's event: status %d", i, w.Code)
+		}
+	}
+}
+
+// funnelCounts keeps what the router's funnel recorder flushes.
+type funnelCounts struct {
+	mu   sync.Mutex
+	incs []funnel.Increment
+}
+
+func (f *funnelCounts) ApplyIncrements(ctx context.Context, incs []funnel.Increment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.incs = append(f.incs, incs...)
+	return nil
+}
+
+func (f *funnelCounts) DailySeries(ctx context.Context, eventID string, from, to time.Time) ([]funnel.DailyCount, error) {
+	return nil, nil
+}
+
+// availabilityCounts answers availability for any event with an empty count.
+type availabilityCounts struct{}
+
+func (availabilityCounts) RecountEvent(ctx context.Context, eventID string) (event.CounterRepair, error) {
+	return event.CounterRepair{}, nil
+}
+
+func (availabilityCounts) Availability(ctx context.Context, eventID string) (event.Availability, error) {
+	return event.Availability{EventID: eventID}, nil
+}
+
+func TestRouterWithDeps_StopFlushesBufferedFunnelCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{Env: "dev", JWTSecret: "router-test-secret", JWTAccessTTLMinutes: 15, JWTRefreshTTLDays: 1}
+	counts := &funnelCounts{}
+	deps := apphttp.Dependencies{
+		Config:        cfg,
+		Events:        memory.NewEventsRepo(),
+		Tokens:        apphttp.NewTokenManager(cfg),
+		Funnel:        counts,
+		EventCounters: availabilityCounts{},
+	}
+	router, stop := apphttp.NewRouterWithDeps(deps)
+	t.Cleanup(stop)
+
+	token, err := deps.Tokens.GenerateAccessToken(uuid.NewString(), "admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	w := serve(router, http.MethodPost, "/admin/events", `{"title":"Funnel Night","startAt":"2030-01-02T15:04:05Z","capacity":5}`, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body=%s", w.Code, w.Body.String())
+	}
+	var created event.Event
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if w := serve(router, http.MethodGet, "/events/"+created.ID, "", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/events/"+created.ID+"/availability", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// well inside the flush interval, so only stop writes the view
+	stop()
+
+	counts.mu.Lock()
+	defer counts.mu.Unlock()
+	if len(counts.incs) != 1 || counts.incs[0].EventID != created.ID || counts.incs[0].Stage != funnel.StageView {
+		t.Fatalf("expected the view flushed on stop, got %+v", counts.incs)
+	}
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const TypeAccountExport = "account.export"
+
+type AccountExportPayload struct {
+	UserID      string    `json:"userId"`
+	RequestedAt time.Time `json:"requestedAt"`
+	RequestID   string    `json:"requestId,omitempty"`
+}
+
+func (p AccountExportPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// AccountExportResult is stored as the job result: progress while the archive
+// is being written, then where it landed once Stage is "done".
+type AccountExportResult struct {
+	Stage         string     `json:"stage"`
+	Events        int        `json:"events"`
+	Registrations int        `json:"registrations"`
+	Jobs          int        `json:"jobs"`
+	FileName      string     `json:"fileName,omitempty"`
+	Location      string     `json:"location,omitempty"`
+	LinkExpiresAt *time.Time `json:"linkExpiresAt,omitempty"`
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// JitterMode picks how a retry delay is randomized.
+type JitterMode string
+
+const (
+	// JitterFull spreads each retry uniformly over [0, delay], so jobs that
+	// failed together don't all come back at the same instant.
+	JitterFull JitterMode = "full"
+	// JitterNone uses the computed delay as is; tests use it to be exact.
+	JitterNone JitterMode = "none"
+)
+
+// Backoff computes retry delays: Base * Multiplier^attempt, capped at Max,
+// then jittered. Zero fields take DefaultBackoff's values.
+type Backoff struct {
+	Base       time.Duration
+	Multiplier float64
+	Max        time.Duration
+	Jitter     JitterMode
+}
+
+var DefaultBackoff = Backoff{
+	Base:       2 * time.Second,
+	Multiplier: 2,
+	Max:        15 * time.Minute,
+	Jitter:     JitterFull,
+}
+
+// WithDefaults fills b's zero fields from DefaultBackoff.
+func (b Backoff) WithDefaults() Backoff {
+	if b.Base <= 0 {
+		b.Base = DefaultBackoff.Base
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = DefaultBackoff.Multiplier
+	}
+	if b.Max <= 0 {
+		b.Max = DefaultBackoff.Max
+	}
+	if b.Max < b.Base {
+		b.Max = b.Base
+	}
+	if b.Jitter == "" {
+		b.Jitter = DefaultBackoff.Jitter
+	}
+	return b
+}
+
+// Delay is how long to wait before retrying after attempt (0-based) failed.
+func (b Backoff) Delay(attempt int) time.Duration {
+	b = b.WithDefaults()
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	// in float so large attempts saturate at Max instead of overflowing
+	delay := b.Max
+	if d := float64(b.Base) * math.Pow(b.Multiplier, float64(attempt)); d < float64(b.Max) {
+		delay = time.Duration(d)
+	}
+
+	if b.Jitter == JitterFull {
+		// the top-level math/rand/v2 source is safe for concurrent use
+		delay = rand.N(delay + 1)
+	}
+	return delay
+}
+
+// ExponentialBackoff is DefaultBackoff.Delay.
+func ExponentialBackoff(attempt int) time.Duration {
+	return DefaultBackoff.Delay(attempt)
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_CapsDelayAcrossAttempts(t *testing.T) {
+	b := Backoff{Base: time.Second, Multiplier: 3, Max: 10 * time.Minute, Jitter: JitterNone}
+
+	prev := time.Duration(0)
+	for attempt := 0; attempt <= 30; attempt++ {
+		d := b.Delay(attempt)
+		if d > b.Max {
+			t.Fatalf("attempt %d: delay %s over the cap %s", attempt, d, b.Max)
+		}
+		if d < prev {
+			t.Fatalf("attempt %d: delay %s shrank from %s", attempt, d, prev)
+		}
+		prev = d
+	}
+
+	if got := b.Delay(2); got != 9*time.Second {
+		t.Fatalf("expected base*multiplier^2 = 9s, got %s", got)
+	}
+	if got := b.Delay(30); got != b.Max {
+		t.Fatalf("expected attempt 30 at the cap, got %s", got)
+	}
+}
+
+func TestBackoff_FullJitterStaysWithinBounds(t *testing.T) {
+	b := Backoff{Base: 2 * time.Second, Multiplier: 2, Max: 15 * time.Minute, Jitter: JitterFull}
+	exact := b
+	exact.Jitter = JitterNone
+
+	for attempt := 0; attempt <= 30; attempt++ {
+		ceiling := exact.Delay(attempt)
+		distinct := map[time.Duration]bool{}
+		for i := 0; i < 50; i++ {
+			d := b.Delay(attempt)
+			if d < 0 || d > ceiling {
+				t.Fatalf("attempt %d: jittered delay %s outside [0, %s]", attempt, d, ceiling)
+			}
+			distinct[d] = true
+		}
+		// a burst of failures must not all retry at the same instant
+		if len(distinct) < 2 {
+			t.Fatalf("attempt %d: 50 jittered delays were all %v", attempt, distinct)
+		}
+	}
+}
+
+func TestBackoff_ZeroValueUsesDefaults(t *testing.T) {
+	b := Backoff{Jitter: JitterNone}
+
+	if got := b.Delay(0); got != DefaultBackoff.Base {
+		t.Fatalf("expected the default base, got %s", got)
+	}
+	if got := b.Delay(30); got != DefaultBackoff.Max {
+		t.Fatalf("expected the default 15m cap, got %s", got)
+	}
+}
+
+func TestExponentialBackoff_StaysWithinExpectedBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		base    time.Duration
+	}{
+		{name: "attempt 0", attempt: 0, base: 2 * time.Second},
+		{name: "attempt 3", attempt: 3, base: 16 * time.Second},
+		{name: "capped", attempt: 20, base: 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// full jitter: anywhere from no wait up to the computed delay
+			delay := ExponentialBackoff(tt.attempt)
+
+			if delay < 0 {
+				t.Fatalf("negative delay: got=%s", delay)
+			}
+			if delay > tt.base {
+				t.Fatalf("delay above computed delay: got=%s max=%s", delay, tt.base)
+			}
+		})
+	}
+}
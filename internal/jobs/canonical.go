@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// CanonicalJSON re-encodes raw with object keys sorted and no insignificant
+// whitespace, so two encodings of the same value compare equal byte for
+// byte. Numbers keep their literal text.
+func CanonicalJSON(raw []byte) ([]byte, error) {
+	v, err := decodeCanonical(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// PayloadDiff compares two JSON object payloads after canonicalizing them and
+// returns the top-level keys whose values differ, sorted; a key present in
+// only one of them differs too. Keys in ignore are left out, for per-request
+// fields such as requestedAt. A payload that is not an object is compared
+// whole and reported as "".
+func PayloadDiff(a, b []byte, ignore ...string) ([]string, error) {
+	va, err := decodeCanonical(a)
+	if err != nil {
+		return nil, err
+	}
+	vb, err := decodeCanonical(b)
+	if err != nil {
+		return nil, err
+	}
+
+	oa, aObj := va.(map[string]any)
+	ob, bObj := vb.(map[string]any)
+	if !aObj || !bObj {
+		same, err := sameJSON(va, vb)
+		if err != nil || same {
+			return nil, err
+		}
+		return []string{""}, nil
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, k := range ignore {
+		skip[k] = true
+	}
+
+	var diff []string
+	for k, av := range oa {
+		if skip[k] {
+			continue
+		}
+		bv, ok := ob[k]
+		if !ok {
+			diff = append(diff, k)
+			continue
+		}
+		same, err := sameJSON(av, bv)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			diff = append(diff, k)
+		}
+	}
+	for k := range ob {
+		if _, ok := oa[k]; !ok && !skip[k] {
+			diff = append(diff, k)
+		}
+	}
+
+	sort.Strings(diff)
+	return diff, nil
+}
+
+func decodeCanonical(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// sameJSON compares decoded values by their canonical encoding; maps marshal
+// with sorted keys.
+func sameJSON(a, b any) (bool, error) {
+	ea, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	eb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ea, eb), nil
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalJSON_IgnoresKeyOrderAndWhitespace(t *testing.T) {
+	a, err := CanonicalJSON([]byte(`{"b": 1, "a": {"y": [1, 2], "x": "s"}}`))
+	if err != nil {
+		t.Fatalf("canonical a: %v", err)
+	}
+	b, err := CanonicalJSON([]byte("{\n\t\"a\":{\"x\":\"s\",\"y\":[1,2]},\n\t\"b\":1\n}"))
+	if err != nil {
+		t.Fatalf("canonical b: %v", err)
+	}
+
+	want := `{"a":{"x":"s","y":[1,2]},"b":1}`
+	if string(a) != want || string(b) != want {
+		t.Fatalf("canonical forms = %s and %s, want %s", a, b, want)
+	}
+
+	// big numbers keep their digits rather than going through float64
+	n, err := CanonicalJSON([]byte(`{"id": 12345678901234567890}`))
+	if err != nil || string(n) != `{"id":12345678901234567890}` {
+		t.Fatalf("number = %s err=%v", n, err)
+	}
+
+	if _, err := CanonicalJSON([]byte(`{"a":`)); err == nil {
+		t.Fatalf("expected invalid JSON to fail")
+	}
+}
+
+func TestPayloadDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		ignore []string
+		want   []string
+	}{
+		{name: "same", a: `{"eventId":"e1","n":1}`, b: `{ "n": 1, "eventId": "e1" }`},
+		{name: "nested order", a: `{"m":{"a":1,"b":2}}`, b: `{"m":{"b":2,"a":1}}`},
+		{name: "changed value", a: `{"eventId":"e1","n":1}`, b: `{"eventId":"e2","n":1}`, want: []string{"eventId"}},
+		{name: "array order matters", a: `{"ids":[1,2]}`, b: `{"ids":[2,1]}`, want: []string{"ids"}},
+		{name: "added and removed keys", a: `{"a":1,"b":2}`, b: `{"b":2,"c":3}`, want: []string{"a", "c"}},
+		{name: "ignored fields", a: `{"eventId":"e1","requestedAt":"t1"}`, b: `{"eventId":"e1","requestedAt":"t2","requestId":"r"}`, ignore: []string{"requestedAt", "requestId"}},
+		{name: "not objects", a: `[1,2]`, b: `[1, 3]`, want: []string{""}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PayloadDiff([]byte(tc.a), []byte(tc.b), tc.ignore...)
+			if err != nil {
+				t.Fatalf("diff: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("diff = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const TypeOrganizerCapacityAlert = "organizer.capacity_alert"
+
+// OrganizerCapacityAlertPayload is enqueued in the registration transaction
+// the first time an event's confirmed seats reach Threshold percent of Capacity.
+type OrganizerCapacityAlertPayload struct {
+	EventID     string    `json:"eventId"`
+	OrganizerID string    `json:"organizerId"`
+	Threshold   int       `json:"threshold"`
+	Capacity    int       `json:"capacity"`
+	Registered  int       `json:"registered"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+func (p OrganizerCapacityAlertPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// CapacityAlertKey is both the job idempotency key and the delivery dedupe
+// key, so each threshold alerts once per event however often it is crossed.
+func CapacityAlertKey(eventID string, threshold int) string {
+	return fmt.Sprintf("capacity_alert:%s:%d", eventID, threshold)
+}
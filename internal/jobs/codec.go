@@ -1,8 +1,14 @@
 package jobs
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/validation"
 )
 
 func EncodePayload(t JobType, payload any) ([]byte, error) {
@@ -86,7 +92,75 @@ func DecodePayload(j Job) (any, error) {
 		}
 		return p, nil
 
+	// worker types decode strictly, since they may have been typed by hand
+	case TypeAccountExport:
+		return decodeStrict[AccountExportPayload](j.Payload)
+	case TypeEventFinalizeAttendance:
+		return decodeStrict[EventFinalizeAttendancePayload](j.Payload)
+	case TypeEventPublish:
+		return decodeStrict[EventPublishPayload](j.Payload)
+	case TypeEventsVerifyCounters:
+		return decodeStrict[EventsVerifyCountersPayload](j.Payload)
+	case TypeExportsCleanup:
+		return decodeStrict[ExportsCleanupPayload](j.Payload)
+	case TypeJobsPayloadReport:
+		return decodeStrict[JobsPayloadReportPayload](j.Payload)
+	case TypeJobsPurge:
+		return decodeStrict[JobsPurgePayload](j.Payload)
+	case TypeOrganizerCapacityAlert:
+		return decodeStrict[OrganizerCapacityAlertPayload](j.Payload)
+	case TypeRegistrationConfirmation:
+		return decodeStrict[RegistrationConfirmationPayload](j.Payload)
+	case TypeRegistrationEventPublished:
+		return decodeStrict[RegistrationEventPublishedPayload](j.Payload)
+	case TypeRegistrationReminder:
+		return decodeStrict[RegistrationReminderPayload](j.Payload)
+	case TypeRegistrationsExportCSV:
+		return decodeStrict[RegistrationsExportCSVPayload](j.Payload)
+	case TypeRegistrationsLinkUsers:
+		return decodeStrict[RegistrationsLinkUsersPayload](j.Payload)
+	case TypeUserEmailVerification:
+		return decodeStrict[UserEmailVerificationPayload](j.Payload)
+	case TypeWebhookDeliver:
+		return decodeStrict[WebhookDeliverPayload](j.Payload)
+
 	default:
 		return nil, ErrInvalidJobType
 	}
 }
+
+// decodeStrict decodes raw into a T, refusing fields T does not have and
+// anything after the object. Failures are a *PayloadError naming the field.
+func decodeStrict[T any](raw []byte) (any, error) {
+	var p T
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, &PayloadError{Fields: []validation.FieldError{decodeFieldError(err)}}
+	}
+	if dec.More() {
+		return nil, &PayloadError{Fields: []validation.FieldError{{Rule: "json", Message: "must be a single JSON object"}}}
+	}
+	return p, nil
+}
+
+func decodeFieldError(err error) validation.FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return validation.FieldError{Rule: "type", Message: "must be a JSON object"}
+		}
+		return validation.FieldError{Field: typeErr.Field, Rule: "type", Message: "must be of type " + typeErr.Type.String()}
+	}
+
+	// encoding/json has no type for this one: `json: unknown field "x"`
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return validation.FieldError{Field: strings.Trim(name, `"`), Rule: "unknown", Message: "is not a field of this job type"}
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return validation.FieldError{Rule: "type", Message: "times must be RFC 3339"}
+	}
+	return validation.FieldError{Rule: "json", Message: err.Error()}
+}
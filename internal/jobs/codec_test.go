@@ -1,6 +1,8 @@
 package jobs
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -56,3 +58,68 @@ func TestValidatePayload_RequiredIDs(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestDecodePayload_WorkerTypesAreStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		field   string
+		rule    string
+	}{
+		{name: "unknown field", payload: `{"eventId":"x","evnetId":"y"}`, field: "evnetId", rule: "unknown"},
+		{name: "wrong type", payload: `{"eventId":42}`, field: "eventId", rule: "type"},
+		{name: "not an object", payload: `["x"]`, rule: "type"},
+		{name: "trailing data", payload: `{"eventId":"x"} {}`, rule: "json"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodePayload(Job{Type: TypeEventPublish, Payload: []byte(tc.payload)})
+			var perr *PayloadError
+			if !errors.As(err, &perr) || !errors.Is(err, ErrInvalidJobPayload) {
+				t.Fatalf("expected a PayloadError, got %v", err)
+			}
+			if got := perr.Fields[0]; got.Field != tc.field || got.Rule != tc.rule {
+				t.Fatalf("got %+v, want field %q rule %q", got, tc.field, tc.rule)
+			}
+		})
+	}
+
+	decoded, err := DecodePayload(Job{Type: TypeEventPublish, Payload: []byte(`{"eventId":"e-1"}`)})
+	if err != nil {
+		t.Fatalf("DecodePayload error: %v", err)
+	}
+	if p, ok := decoded.(EventPublishPayload); !ok || p.EventID != "e-1" {
+		t.Fatalf("expected EventPublishPayload, got %#v", decoded)
+	}
+}
+
+func TestValidatePayload_WorkerTypesReportFields(t *testing.T) {
+	if !JobType(TypeJobsPurge).IsValid() || JobType("test.crash").IsValid() {
+		t.Fatalf("expected worker types valid and test types not")
+	}
+
+	err := ValidatePayload(TypeRegistrationConfirmation, RegistrationConfirmationPayload{EventID: "not-a-uuid"})
+	var perr *PayloadError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a PayloadError, got %v", err)
+	}
+	var got []string
+	for _, f := range perr.Fields {
+		got = append(got, f.Field+":"+f.Rule)
+	}
+	if want := []string{"registrationId:required", "eventId:uuid", "email:required"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+
+	err = ValidatePayload(TypeJobsPurge, &JobsPurgePayload{Statuses: []string{"done", "pending"}})
+	if !errors.As(err, &perr) || len(perr.Fields) != 1 || perr.Fields[0].Field != "statuses[1]" {
+		t.Fatalf("expected statuses[1] rejected, got %v", err)
+	}
+
+	if err := ValidatePayload(TypeJobsPurge, JobsPurgePayload{}); err != nil {
+		t.Fatalf("an empty purge payload takes the defaults, got %v", err)
+	}
+	if err := ValidatePayload(TypeEventPublish, JobsPurgePayload{}); !errors.Is(err, ErrPayloadTypeMismatch) {
+		t.Fatalf("expected ErrPayloadTypeMismatch, got %v", err)
+	}
+}
@@ -1,6 +1,12 @@
 package jobs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/geocoder89/eventhub/internal/validation"
+)
 
 var (
 	ErrInvalidJobType      = errors.New("invalid job type")
@@ -8,3 +14,29 @@ var (
 	ErrInvalidJobPayload   = errors.New("invalid job payload")
 	ErrPayloadTypeMismatch = errors.New("payload type mismatch for job type")
 )
+
+// ErrNonRetryable marks a handler failure that another attempt cannot fix
+// (bad input, a permanent rejection). The worker dead-letters such jobs at
+// once, whatever their retry policy.
+var ErrNonRetryable = errors.New("non-retryable job error")
+
+// NonRetryable wraps err with ErrNonRetryable, keeping err matchable.
+func NonRetryable(err error) error {
+	return fmt.Errorf("%w: %w", ErrNonRetryable, err)
+}
+
+// PayloadError is a payload that decoded badly or failed validation, with a
+// problem per field. It matches ErrInvalidJobPayload.
+type PayloadError struct {
+	Fields []validation.FieldError
+}
+
+func (e *PayloadError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, strings.TrimSpace(f.Field+" "+f.Message))
+	}
+	return ErrInvalidJobPayload.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *PayloadError) Unwrap() error { return ErrInvalidJobPayload }
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const TypeEventFinalizeAttendance = "event.finalize_attendance"
+
+type EventFinalizeAttendancePayload struct {
+	EventID string    `json:"eventId"`
+	StartAt time.Time `json:"startAt"`
+}
+
+func (p EventFinalizeAttendancePayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// FinalizeAttendanceKey includes the start time, so moving an event schedules
+// a fresh finalization instead of colliding with the stale one.
+func FinalizeAttendanceKey(eventID string, startAt time.Time) string {
+	return fmt.Sprintf("attendance:finalize:%s:%d", eventID, startAt.UTC().Unix())
+}
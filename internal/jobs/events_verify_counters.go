@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const TypeEventsVerifyCounters = "events.verify_counters"
+
+// VerifyCountersEvery is how often the counter verification re-enqueues itself.
+const VerifyCountersEvery = 7 * 24 * time.Hour
+
+type EventsVerifyCountersPayload struct {
+	LookbackDays int `json:"lookbackDays,omitempty"` // default 90
+	BatchSize    int `json:"batchSize,omitempty"`    // default 200
+}
+
+func (p EventsVerifyCountersPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// VerifyCountersKey is the idempotency key for the run scheduled at runAt: one
+// run per ISO week, however many workers try to schedule it.
+func VerifyCountersKey(runAt time.Time) string {
+	year, week := runAt.UTC().ISOWeek()
+	return fmt.Sprintf("events:verify_counters:%d-W%02d", year, week)
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const TypeExportsCleanup = "exports.cleanup"
+
+// ExportsCleanupEvery is how often the export cleanup re-enqueues itself.
+const ExportsCleanupEvery = 24 * time.Hour
+
+type ExportsCleanupPayload struct {
+	BatchSize int `json:"batchSize,omitempty"` // default 100
+}
+
+func (p ExportsCleanupPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// ExportsCleanupResult is stored on the job once a run finishes.
+type ExportsCleanupResult struct {
+	Deleted      int `json:"deleted"`
+	MissingBlobs int `json:"missingBlobs"`
+	Failed       int `json:"failed"`
+	Batches      int `json:"batches"`
+}
+
+// ExportsCleanupKey is the idempotency key for the run scheduled at runAt: one
+// run per UTC day.
+func ExportsCleanupKey(runAt time.Time) string {
+	return fmt.Sprintf("exports:cleanup:%s", runAt.UTC().Format("2006-01-02"))
+}
@@ -0,0 +1,21 @@
+package jobs
+
+import "encoding/json"
+
+const TypeJobsPayloadReport = "jobs.payload_report"
+
+// JobsPayloadReportPayload asks for a list of stored jobs whose payload is
+// over MaxBytes. Nothing is changed; the list is the job's result.
+type JobsPayloadReportPayload struct {
+	MaxBytes int `json:"maxBytes,omitempty"` // default: the worker's enqueue limit
+	Limit    int `json:"limit,omitempty"`    // default 100, at most 1000
+}
+
+func (p JobsPayloadReportPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
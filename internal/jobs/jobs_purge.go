@@ -0,0 +1,33 @@
+package jobs
+
+import "encoding/json"
+
+const TypeJobsPurge = "jobs.purge"
+
+const (
+	DefaultPurgeOlderThanDays = 30
+	DefaultPurgeBatchSize     = 1000
+)
+
+// JobsPurgePayload deletes terminal jobs last updated more than OlderThanDays
+// ago. Meant for a nightly schedule, e.g. {"cron": "0 3 * * *"}.
+type JobsPurgePayload struct {
+	Statuses      []string `json:"statuses,omitempty"`      // default ["done"]
+	OlderThanDays int      `json:"olderThanDays,omitempty"` // default 30
+	BatchSize     int      `json:"batchSize,omitempty"`     // default 1000
+}
+
+func (p JobsPurgePayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// JobsPurgeResult is stored on the job once a run finishes.
+type JobsPurgeResult struct {
+	Deleted int64 `json:"deleted"`
+	Batches int   `json:"batches"`
+}
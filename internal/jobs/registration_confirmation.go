@@ -14,6 +14,11 @@ type RegistrationConfirmationPayload struct {
 	Name           string    `json:"name"`
 	RequestedAt    time.Time `json:"requestedAt"`
 	RequestID      string    `json:"requestId,omitempty"`
+
+	// organizer branding captured at enqueue time; empty uses the platform sender
+	ReplyTo       string `json:"replyTo,omitempty"`
+	OrganizerName string `json:"organizerName,omitempty"`
+	LogoURL       string `json:"logoUrl,omitempty"`
 }
 
 func (p RegistrationConfirmationPayload) JSON() (json.RawMessage, error) {
@@ -0,0 +1,27 @@
+package jobs
+
+import "encoding/json"
+
+// TypeRegistrationEventPublished tells one confirmed attendee that the event
+// they registered for was published.
+const TypeRegistrationEventPublished = "registration.event_published"
+
+type RegistrationEventPublishedPayload struct {
+	RegistrationID string `json:"registrationId"`
+	EventID        string `json:"eventId"`
+}
+
+func (p RegistrationEventPublishedPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// EventPublishedKey keeps a registration to one published notification
+// however often its event's publish runs.
+func EventPublishedKey(registrationID string) string {
+	return "event_published:" + registrationID
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const TypeRegistrationReminder = "registration.reminder"
+
+// ReminderLead is how long before an event starts its attendees are reminded.
+const ReminderLead = 24 * time.Hour
+
+// RegistrationReminderPayload is what a registration.reminder job carries.
+type RegistrationReminderPayload struct {
+	RegistrationID string    `json:"registrationId"`
+	EventID        string    `json:"eventId"`
+	Email          string    `json:"email"`
+	Name           string    `json:"name"`
+	StartAt        time.Time `json:"startAt"`
+	RequestedAt    time.Time `json:"requestedAt"`
+}
+
+func (p RegistrationReminderPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// ReminderKey keeps a registration to one reminder job however often it is scheduled.
+func ReminderKey(registrationID string) string {
+	return "reminder:" + registrationID
+}
@@ -12,6 +12,19 @@ type RegistrationsExportCSVPayload struct {
 	RequestedBy string    `json:"requestedBy"`
 	RequestedAt time.Time `json:"requestedAt"`
 	RequestID   string    `json:"requestId,omitempty"`
+
+	// KeepUntil holds the export past the retention; see CSVExport.KeepUntil
+	KeepUntil *time.Time `json:"keepUntil,omitempty"`
+
+	// IncludeActivity adds the registration lifecycle history; the export is
+	// then a zip of registrations.csv and activity.csv
+	IncludeActivity bool `json:"includeActivity,omitempty"`
+
+	// PIIVisibility snapshots what the requester could see when asking
+	// (registration.PIIVisibility); "masked" masks names and emails and
+	// drops check-in tokens. Empty is "full": payloads from before the
+	// field only came from the admin-only route.
+	PIIVisibility string `json:"piiVisibility,omitempty"`
 }
 
 func (p RegistrationsExportCSVPayload) JSON() (json.RawMessage, error) {
@@ -0,0 +1,33 @@
+package jobs
+
+import "encoding/json"
+
+const TypeRegistrationsLinkUsers = "registrations.link_users"
+
+const DefaultLinkUsersBatchSize = 500
+
+// RegistrationsLinkUsersPayload backfills registrations.user_id for rows made
+// before registrations were tied to accounts, matching each row's email to a
+// verified account. Safe to run again; linked rows are skipped.
+type RegistrationsLinkUsersPayload struct {
+	BatchSize int `json:"batchSize,omitempty"` // default 500
+}
+
+func (p RegistrationsLinkUsersPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// RegistrationsLinkUsersResult is stored on the job once a run finishes.
+// Conflicts are rows whose email more than one verified account holds; they
+// are left unlinked.
+type RegistrationsLinkUsersResult struct {
+	Scanned   int   `json:"scanned"`
+	Linked    int64 `json:"linked"`
+	Conflicts int   `json:"conflicts"`
+	Batches   int   `json:"batches"`
+}
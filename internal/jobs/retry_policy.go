@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryPolicy is how one job type is retried. Zero fields fall through to
+// the next source, so precedence is field by field:
+//
+//	MaxAttempts: policy > the job row's max_attempts > job.DefaultMaxAttempts
+//	BaseDelay, MaxDelay: policy > the worker's Config.Backoff > DefaultBackoff
+//
+// NonRetryableErrors adds errors that dead-letter at once; ErrNonRetryable
+// (carried by undecodable payloads) and the worker's ErrUnknownJobType always
+// do. A send refused by an open circuit is retried no sooner than the
+// circuit's cooldown.
+type RetryPolicy struct {
+	MaxAttempts        int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	NonRetryableErrors func(err error) bool
+}
+
+// MatchErrors is a NonRetryableErrors matcher for errors.Is against targets.
+func MatchErrors(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, t := range targets {
+			if errors.Is(err, t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DefaultRetryPolicies are the policies the binaries run with: confirmations
+// are retried quickly while the registrant is still waiting for the email,
+// webhook receivers get hours to come back.
+var DefaultRetryPolicies = map[string]RetryPolicy{
+	TypeRegistrationConfirmation: {MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Minute},
+	TypeWebhookDeliver:           {MaxAttempts: 12, BaseDelay: time.Minute, MaxDelay: 6 * time.Hour},
+}
+
+// Over is b with p's delays laid over it; the zero policy leaves b as is.
+func (p RetryPolicy) Over(b Backoff) Backoff {
+	if p.BaseDelay > 0 {
+		b.Base = p.BaseDelay
+	}
+	if p.MaxDelay > 0 {
+		b.Max = p.MaxDelay
+	}
+	return b
+}
+
+// RetryDelays returns the delay a failed attempt of a job type gets under b
+// and policies, without jitter: with full jitter that is the latest the retry
+// can land. The admin API uses it to preview a job's remaining retries.
+func RetryDelays(b Backoff, policies map[string]RetryPolicy) func(jobType string, attempt int) time.Duration {
+	return func(jobType string, attempt int) time.Duration {
+		exact := policies[jobType].Over(b)
+		exact.Jitter = JitterNone
+		return exact.Delay(attempt)
+	}
+}
@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelays_AppliesPoliciesWithoutJitter(t *testing.T) {
+	delay := RetryDelays(DefaultBackoff, map[string]RetryPolicy{
+		"webhook.deliver": {BaseDelay: time.Minute, MaxDelay: time.Hour},
+	})
+
+	if got := delay("event.publish", 3); got != 16*time.Second {
+		t.Fatalf("default backoff at attempt 3 = %s, want 16s", got)
+	}
+	if got := delay("webhook.deliver", 1); got != 2*time.Minute {
+		t.Fatalf("policy backoff at attempt 1 = %s, want 2m", got)
+	}
+	if got := delay("webhook.deliver", 20); got != time.Hour {
+		t.Fatalf("policy backoff at attempt 20 = %s, want the 1h cap", got)
+	}
+}
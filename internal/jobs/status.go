@@ -17,3 +17,7 @@ func (s JobStatus) IsValid() bool {
 		return false
 	}
 }
+
+// NewJobsChannel is the Postgres NOTIFY channel a job's type is published on
+// when it becomes claimable.
+const NewJobsChannel = "jobs_new"
@@ -1,5 +1,7 @@
 package jobs
 
+import "slices"
+
 type JobType string
 
 const (
@@ -11,13 +13,42 @@ const (
 	JobExportRegistrationsCSV       JobType = "export_registrations_csv"
 )
 
+// workerTypes are the job types the worker registers handlers for, the ones
+// worth enqueueing by hand. Kept sorted.
+var workerTypes = []JobType{
+	TypeAccountExport,
+	TypeEventFinalizeAttendance,
+	TypeEventPublish,
+	TypeEventsVerifyCounters,
+	TypeExportsCleanup,
+	TypeJobsPayloadReport,
+	TypeJobsPurge,
+	TypeOrganizerCapacityAlert,
+	TypeRegistrationConfirmation,
+	TypeRegistrationEventPublished,
+	TypeRegistrationReminder,
+	TypeRegistrationsExportCSV,
+	TypeRegistrationsLinkUsers,
+	TypeUserEmailVerification,
+	TypeWebhookDeliver,
+}
+
 // check to see if the job type is a known constant
 
 func (t JobType) IsValid() bool {
 	switch t {
 	case JobPublishEvent, JobSendRegistrationConfirmation, JobExportRegistrationsCSV:
 		return true
-	default:
-		return false
 	}
+	return t.IsWorkerType()
+}
+
+// IsWorkerType reports whether a worker runs jobs of type t.
+func (t JobType) IsWorkerType() bool {
+	return slices.Contains(workerTypes, t)
+}
+
+// WorkerTypes returns the job types a worker runs, sorted.
+func WorkerTypes() []JobType {
+	return slices.Clone(workerTypes)
 }
@@ -0,0 +1,20 @@
+package jobs
+
+import "encoding/json"
+
+const TypeUserEmailVerification = "user.email_verification"
+
+// UserEmailVerificationPayload asks for a verification link to be emailed to
+// the user's current address. Enqueued at signup and on request.
+type UserEmailVerificationPayload struct {
+	UserID string `json:"userId"`
+}
+
+func (p UserEmailVerificationPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
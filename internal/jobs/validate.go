@@ -1,6 +1,14 @@
 package jobs
 
-import "strings"
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/geocoder89/eventhub/internal/validation"
+)
 
 // ValidatePayload performs minimal validation on decoded payloads.
 // (Full validation can be extended later, but this is enough for Day 30.)
@@ -57,7 +65,215 @@ func ValidatePayload(t JobType, payload any) error {
 		}
 		return nil
 
+	default:
+		return validateWorkerPayload(t, payload)
+	}
+}
+
+// validateWorkerPayload checks what the worker's handler for t needs from
+// its payload; problems come back as a *PayloadError, field by field.
+func validateWorkerPayload(t JobType, payload any) error {
+	var r fieldRules
+
+	switch t {
+	case TypeAccountExport:
+		p, err := payloadAs[AccountExportPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("userId", p.UserID)
+
+	case TypeEventFinalizeAttendance:
+		p, err := payloadAs[EventFinalizeAttendancePayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("eventId", p.EventID)
+		r.time("startAt", p.StartAt)
+
+	case TypeEventPublish:
+		p, err := payloadAs[EventPublishPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("eventId", p.EventID)
+
+	case TypeEventsVerifyCounters:
+		p, err := payloadAs[EventsVerifyCountersPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.min("lookbackDays", p.LookbackDays, 0)
+		r.min("batchSize", p.BatchSize, 0)
+
+	case TypeExportsCleanup:
+		p, err := payloadAs[ExportsCleanupPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.min("batchSize", p.BatchSize, 0)
+
+	case TypeJobsPayloadReport:
+		p, err := payloadAs[JobsPayloadReportPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.min("maxBytes", p.MaxBytes, 0)
+		r.min("limit", p.Limit, 0)
+		r.max("limit", p.Limit, 1000)
+
+	case TypeJobsPurge:
+		p, err := payloadAs[JobsPurgePayload](payload)
+		if err != nil {
+			return err
+		}
+		for i, s := range p.Statuses {
+			r.oneOf("statuses["+strconv.Itoa(i)+"]", s, "done", "failed", "cancelled")
+		}
+		r.min("olderThanDays", p.OlderThanDays, 0)
+		r.min("batchSize", p.BatchSize, 0)
+
+	case TypeOrganizerCapacityAlert:
+		p, err := payloadAs[OrganizerCapacityAlertPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("eventId", p.EventID)
+		r.uuid("organizerId", p.OrganizerID)
+		r.min("threshold", p.Threshold, 1)
+
+	case TypeRegistrationConfirmation:
+		p, err := payloadAs[RegistrationConfirmationPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("registrationId", p.RegistrationID)
+		r.uuid("eventId", p.EventID)
+		r.required("email", p.Email)
+
+	case TypeRegistrationEventPublished:
+		p, err := payloadAs[RegistrationEventPublishedPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("registrationId", p.RegistrationID)
+		r.uuid("eventId", p.EventID)
+
+	case TypeRegistrationReminder:
+		p, err := payloadAs[RegistrationReminderPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("registrationId", p.RegistrationID)
+		r.uuid("eventId", p.EventID)
+		r.required("email", p.Email)
+		r.time("startAt", p.StartAt)
+
+	case TypeRegistrationsExportCSV:
+		p, err := payloadAs[RegistrationsExportCSVPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("eventId", p.EventID)
+		if p.PIIVisibility != "" {
+			r.oneOf("piiVisibility", p.PIIVisibility, "full", "masked")
+		}
+
+	case TypeRegistrationsLinkUsers:
+		p, err := payloadAs[RegistrationsLinkUsersPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.min("batchSize", p.BatchSize, 0)
+		r.max("batchSize", p.BatchSize, 5000)
+
+	case TypeUserEmailVerification:
+		p, err := payloadAs[UserEmailVerificationPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("userId", p.UserID)
+
+	case TypeWebhookDeliver:
+		p, err := payloadAs[WebhookDeliverPayload](payload)
+		if err != nil {
+			return err
+		}
+		r.uuid("webhookId", p.WebhookID)
+		r.required("kind", p.Kind)
+
 	default:
 		return ErrInvalidJobType
 	}
+
+	return r.err()
+}
+
+// payloadAs takes a payload passed as T or *T.
+func payloadAs[T any](payload any) (T, error) {
+	switch v := payload.(type) {
+	case T:
+		return v, nil
+	case *T:
+		if v != nil {
+			return *v, nil
+		}
+	}
+	var zero T
+	return zero, ErrPayloadTypeMismatch
+}
+
+// fieldRules collects field problems in the shape request bodies report them.
+type fieldRules []validation.FieldError
+
+func (r *fieldRules) add(field, rule, param, message string) {
+	*r = append(*r, validation.FieldError{Field: field, Rule: rule, Param: param, Message: message})
+}
+
+func (r *fieldRules) required(field, v string) {
+	if strings.TrimSpace(v) == "" {
+		r.add(field, "required", "", "is required")
+	}
+}
+
+func (r *fieldRules) uuid(field, v string) {
+	if strings.TrimSpace(v) == "" {
+		r.add(field, "required", "", "is required")
+		return
+	}
+	if !utils.IsUUID(v) {
+		r.add(field, "uuid", "", "must be a UUID")
+	}
+}
+
+func (r *fieldRules) time(field string, v time.Time) {
+	if v.IsZero() {
+		r.add(field, "required", "", "is required")
+	}
+}
+
+func (r *fieldRules) min(field string, v, limit int) {
+	if v < limit {
+		r.add(field, "min", strconv.Itoa(limit), "must be at least "+strconv.Itoa(limit))
+	}
+}
+
+func (r *fieldRules) max(field string, v, limit int) {
+	if v > limit {
+		r.add(field, "max", strconv.Itoa(limit), "must be at most "+strconv.Itoa(limit))
+	}
+}
+
+func (r *fieldRules) oneOf(field, v string, allowed ...string) {
+	if slices.Contains(allowed, v) {
+		return
+	}
+	r.add(field, "oneof", strings.Join(allowed, " "), "must be one of "+strings.Join(allowed, ", "))
+}
+
+func (r fieldRules) err() error {
+	if len(r) == 0 {
+		return nil
+	}
+	return &PayloadError{Fields: r}
 }
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const TypeWebhookDeliver = "webhook.deliver"
+
+// WebhookDeliverPayload is enqueued in the registration transaction, one per
+// subscribed webhook. Data is posted as-is.
+type WebhookDeliverPayload struct {
+	WebhookID  string          `json:"webhookId"`
+	Kind       string          `json:"kind"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func (p WebhookDeliverPayload) JSON() (json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// WebhookDeliverKey makes each webhook hear about a registration change once.
+func WebhookDeliverKey(webhookID, kind, registrationID string) string {
+	return fmt.Sprintf("webhook:%s:%s:%s", webhookID, kind, registrationID)
+}
@@ -0,0 +1,236 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"net/mail"
+	"net/url"
+	"text/template"
+)
+
+// Branding is the organizer's presentation for an event's emails; empty
+// fields fall back to the platform sender.
+type Branding struct {
+	ReplyTo     string
+	DisplayName string
+	LogoURL     string
+}
+
+// Sender is the platform identity emails go out as. The From address always
+// stays Address so SPF/DKIM keep passing; OrganizerName decides whether an
+// organizer's display name replaces Name in the From header.
+type Sender struct {
+	Address       string
+	Name          string
+	ReplyTo       string
+	OrganizerName bool
+
+	// CancelPageURL is the page the confirmation's cancel link opens, with
+	// ?token= added; it calls DELETE /registrations/cancel. Empty leaves the
+	// link out.
+	CancelPageURL string
+
+	// VerifyPageURL is the page the verification email links to, with
+	// ?token= added; it calls POST /auth/verify-email. Empty sends the token
+	// instead.
+	VerifyPageURL string
+}
+
+// DefaultSender is used when no sender is configured.
+var DefaultSender = Sender{Address: "no-reply@eventhub.local", Name: "EventHub"}
+
+// Headers are the addressing headers of an outgoing email, already encoded.
+type Headers struct {
+	From    string
+	ReplyTo string
+}
+
+// Headers builds From and Reply-To for an email sent on behalf of b.
+func (s Sender) Headers(b Branding) Headers {
+	name := s.Name
+	if s.OrganizerName && b.DisplayName != "" {
+		name = b.DisplayName
+	}
+
+	h := Headers{From: (&mail.Address{Name: name, Address: s.Address}).String()}
+
+	replyTo := s.ReplyTo
+	if b.ReplyTo != "" {
+		replyTo = b.ReplyTo
+	}
+	if replyTo != "" {
+		h.ReplyTo = (&mail.Address{Address: replyTo}).String()
+	}
+	return h
+}
+
+// ConfirmationTemplateData is what the confirmation templates can use.
+// OrganizerName and ReplyTo are already resolved against the platform defaults.
+type ConfirmationTemplateData struct {
+	Name           string
+	EventID        string
+	EventTitle     string
+	RegistrationID string
+	CancelToken    string
+	CancelURL      string
+
+	OrganizerName string
+	ReplyTo       string
+	LogoURL       string
+}
+
+func (s Sender) confirmationData(in SendRegistrationConfirmationInput) ConfirmationTemplateData {
+	d := ConfirmationTemplateData{
+		Name:           in.Name,
+		EventID:        in.EventID,
+		EventTitle:     in.EventTitle,
+		RegistrationID: in.RegistrationID,
+		CancelToken:    in.CancelToken,
+		CancelURL:      s.cancelURL(in.CancelToken),
+		OrganizerName:  s.Name,
+		ReplyTo:        s.ReplyTo,
+		LogoURL:        in.Branding.LogoURL,
+	}
+	if in.Branding.DisplayName != "" {
+		d.OrganizerName = in.Branding.DisplayName
+	}
+	if in.Branding.ReplyTo != "" {
+		d.ReplyTo = in.Branding.ReplyTo
+	}
+	return d
+}
+
+// cancelURL is the cancel page link carrying token, or empty without either.
+func (s Sender) cancelURL(token string) string {
+	return pageURL(s.CancelPageURL, token)
+}
+
+// pageURL is page with ?token= added, or empty without either.
+func pageURL(page, token string) string {
+	if page == "" || token == "" {
+		return ""
+	}
+	u, err := url.Parse(page)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// templates holds each email as name.txt and name.html; the HTML is parsed
+// with html/template so everything the templates print is escaped.
+//
+//go:embed templates
+var templates embed.FS
+
+var (
+	textTemplates = template.Must(template.ParseFS(templates, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/*.html"))
+)
+
+// Email is a rendered message, ready for a transport.
+type Email struct {
+	Headers
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// RegistrationConfirmation renders the confirmation email for in.
+func (s Sender) RegistrationConfirmation(in SendRegistrationConfirmationInput) (Email, error) {
+	data := s.confirmationData(in)
+
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, "registration_confirmation.txt", data); err != nil {
+		return Email{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, "registration_confirmation.html", data); err != nil {
+		return Email{}, err
+	}
+
+	return Email{
+		Headers: s.Headers(in.Branding),
+		To:      (&mail.Address{Name: in.Name, Address: in.Email}).String(),
+		Subject: "Your registration with " + data.OrganizerName,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// EventPublishedTemplateData is what the publish announcement templates can
+// use.
+type EventPublishedTemplateData struct {
+	Name          string
+	EventID       string
+	Title         string
+	StartAt       string
+	OrganizerName string
+}
+
+// EventPublished renders the announcement that in's event was published.
+func (s Sender) EventPublished(in SendEventPublishedInput) (Email, error) {
+	data := EventPublishedTemplateData{
+		Name:          in.Name,
+		EventID:       in.EventID,
+		Title:         in.Title,
+		StartAt:       in.StartAt.UTC().Format("Mon 2 Jan 2006 15:04 MST"),
+		OrganizerName: s.Name,
+	}
+
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, "event_published.txt", data); err != nil {
+		return Email{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, "event_published.html", data); err != nil {
+		return Email{}, err
+	}
+
+	return Email{
+		Headers: s.Headers(Branding{}),
+		To:      (&mail.Address{Name: in.Name, Address: in.Email}).String(),
+		Subject: in.Title + " is published",
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// EmailVerificationTemplateData is what the verification templates can use.
+type EmailVerificationTemplateData struct {
+	Name          string
+	Token         string
+	VerifyURL     string
+	ExpiresAt     string
+	OrganizerName string
+}
+
+// EmailVerification renders the email that verifies in's address.
+func (s Sender) EmailVerification(in SendEmailVerificationInput) (Email, error) {
+	data := EmailVerificationTemplateData{
+		Name:          in.Name,
+		Token:         in.Token,
+		VerifyURL:     pageURL(s.VerifyPageURL, in.Token),
+		ExpiresAt:     in.ExpiresAt.UTC().Format("Mon 2 Jan 2006 15:04 MST"),
+		OrganizerName: s.Name,
+	}
+
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, "email_verification.txt", data); err != nil {
+		return Email{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, "email_verification.html", data); err != nil {
+		return Email{}, err
+	}
+
+	return Email{
+		Headers: s.Headers(Branding{}),
+		To:      (&mail.Address{Name: in.Name, Address: in.Email}).String(),
+		Subject: "Verify your email for " + s.Name,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSenderHeaders_UsesOrganizerBranding(t *testing.T) {
+	s := Sender{Address: "no-reply@eventhub.example", Name: "EventHub", ReplyTo: "support@eventhub.example", OrganizerName: true}
+
+	h := s.Headers(Branding{ReplyTo: "hello@jazzclub.example", DisplayName: "Jazz Club"})
+	if h.From != `"Jazz Club" <no-reply@eventhub.example>` {
+		t.Fatalf("unexpected From %q", h.From)
+	}
+	if h.ReplyTo != "<hello@jazzclub.example>" {
+		t.Fatalf("unexpected Reply-To %q", h.ReplyTo)
+	}
+
+	s.OrganizerName = false
+	if h := s.Headers(Branding{DisplayName: "Jazz Club"}); h.From != `"EventHub" <no-reply@eventhub.example>` {
+		t.Fatalf("expected the platform name to stay in From, got %q", h.From)
+	}
+}
+
+func TestSenderHeaders_FallsBackToPlatformDefaults(t *testing.T) {
+	s := Sender{Address: "no-reply@eventhub.example", Name: "EventHub", ReplyTo: "support@eventhub.example", OrganizerName: true}
+
+	h := s.Headers(Branding{})
+	if h.From != `"EventHub" <no-reply@eventhub.example>` || h.ReplyTo != "<support@eventhub.example>" {
+		t.Fatalf("unexpected fallback headers %+v", h)
+	}
+
+	s.ReplyTo = ""
+	if h := s.Headers(Branding{}); h.ReplyTo != "" {
+		t.Fatalf("expected no Reply-To without a platform default, got %q", h.ReplyTo)
+	}
+}
+
+func TestRegistrationConfirmation_RendersBranding(t *testing.T) {
+	s := Sender{Address: "no-reply@eventhub.example", Name: "EventHub", OrganizerName: true}
+
+	msg, err := s.RegistrationConfirmation(SendRegistrationConfirmationInput{
+		Email:          "ada@example.com",
+		Name:           "Ada",
+		RegistrationID: "reg-1",
+		Branding:       Branding{ReplyTo: "hello@jazzclub.example", DisplayName: "Jazz Club"},
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if msg.Subject != "Your registration with Jazz Club" || !strings.Contains(msg.Text, "reach Jazz Club") {
+		t.Fatalf("expected organizer branding in the message, got %q / %q", msg.Subject, msg.Text)
+	}
+
+	msg, err = s.RegistrationConfirmation(SendRegistrationConfirmationInput{Email: "ada@example.com", Name: "Ada", RegistrationID: "reg-1"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if msg.Subject != "Your registration with EventHub" || strings.Contains(msg.Text, "Reply to this email") {
+		t.Fatalf("expected the platform defaults, got %q / %q", msg.Subject, msg.Text)
+	}
+}
+
+func TestEmailVerification_LinksToTheVerifyPage(t *testing.T) {
+	s := Sender{Address: "no-reply@eventhub.example", Name: "EventHub", VerifyPageURL: "https://eventhub.example/verify"}
+	in := SendEmailVerificationInput{
+		Email:     "ada@example.com",
+		Name:      "Ada",
+		UserID:    "u-1",
+		Token:     "tok.sig",
+		ExpiresAt: time.Date(2026, time.June, 5, 19, 0, 0, 0, time.UTC),
+	}
+
+	msg, err := s.EmailVerification(in)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(msg.Text, "https://eventhub.example/verify?token=tok.sig") || !strings.Contains(msg.HTML, "https://eventhub.example/verify?token=tok.sig") {
+		t.Fatalf("expected the verify link in both parts, got %q / %q", msg.Text, msg.HTML)
+	}
+
+	// without a page the token itself is sent
+	s.VerifyPageURL = ""
+	msg, err = s.EmailVerification(in)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(msg.Text, "tok.sig") || strings.Contains(msg.Text, "?token=") {
+		t.Fatalf("expected the bare token, got %q", msg.Text)
+	}
+}
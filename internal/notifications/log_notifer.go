@@ -4,14 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/mail"
 	"os"
 	"strconv"
 	"time"
 )
 
-type LogNotifier struct{}
+type LogNotifier struct {
+	sender Sender
+}
+
+func NewLogNotifier() *LogNotifier { return &LogNotifier{sender: DefaultSender} }
 
-func NewLogNotifier() *LogNotifier { return &LogNotifier{} }
+// WithSender sets the platform identity confirmations are rendered with.
+func (n *LogNotifier) WithSender(s Sender) *LogNotifier {
+	n.sender = s
+	return n
+}
 
 func (n *LogNotifier) SendRegistrationConfirmation(ctx context.Context, in SendRegistrationConfirmationInput) error {
 	// Optional: simulate slow provider
@@ -31,8 +40,93 @@ func (n *LogNotifier) SendRegistrationConfirmation(ctx context.Context, in SendR
 		return fmt.Errorf("provider down (simulated)")
 	}
 
-	log.Printf("notification.registration_confirmation email=%s name=%s event=%s registration=%s",
-		in.Email, in.Name, in.EventID, in.RegistrationID,
+	if err := checkRecipient(in.Email); err != nil {
+		return err
+	}
+
+	msg, err := n.sender.RegistrationConfirmation(in)
+	if err != nil {
+		return fmt.Errorf("render confirmation: %w", err)
+	}
+
+	log.Printf("notification.registration_confirmation email=%s name=%s event=%s registration=%s cancel_link=%t from=%q reply_to=%q",
+		in.Email, in.Name, in.EventID, in.RegistrationID, in.CancelToken != "", msg.From, msg.ReplyTo,
+	)
+	return nil
+}
+
+func (n *LogNotifier) SendAccountExportReady(ctx context.Context, in SendAccountExportReadyInput) error {
+	if os.Getenv("NOTIFIER_FAIL") == "1" {
+		return fmt.Errorf("provider down (simulated)")
+	}
+
+	log.Printf("notification.account_export_ready email=%s job=%s expires_at=%s download_link=%t",
+		in.Email, in.JobID, in.ExpiresAt.Format(time.RFC3339), in.DownloadToken != "",
 	)
 	return nil
 }
+
+func (n *LogNotifier) SendCapacityAlert(ctx context.Context, in SendCapacityAlertInput) error {
+	if os.Getenv("NOTIFIER_FAIL") == "1" {
+		return fmt.Errorf("provider down (simulated)")
+	}
+
+	log.Printf("notification.capacity_alert email=%s event=%s threshold=%d registered=%d capacity=%d",
+		in.Email, in.EventID, in.Threshold, in.Registered, in.Capacity,
+	)
+	return nil
+}
+
+func (n *LogNotifier) SendEventReminder(ctx context.Context, in SendEventReminderInput) error {
+	if os.Getenv("NOTIFIER_FAIL") == "1" {
+		return fmt.Errorf("provider down (simulated)")
+	}
+
+	if err := checkRecipient(in.Email); err != nil {
+		return err
+	}
+
+	log.Printf("notification.event_reminder email=%s event=%s registration=%s start_at=%s cancel_link=%t",
+		in.Email, in.EventID, in.RegistrationID, in.StartAt.Format(time.RFC3339), in.CancelToken != "",
+	)
+	return nil
+}
+
+func (n *LogNotifier) SendEventPublished(ctx context.Context, in SendEventPublishedInput) error {
+	if os.Getenv("NOTIFIER_FAIL") == "1" {
+		return fmt.Errorf("provider down (simulated)")
+	}
+
+	if err := checkRecipient(in.Email); err != nil {
+		return err
+	}
+
+	log.Printf("notification.event_published email=%s event=%s registration=%s start_at=%s",
+		in.Email, in.EventID, in.RegistrationID, in.StartAt.Format(time.RFC3339),
+	)
+	return nil
+}
+
+func (n *LogNotifier) SendEmailVerification(ctx context.Context, in SendEmailVerificationInput) error {
+	if os.Getenv("NOTIFIER_FAIL") == "1" {
+		return fmt.Errorf("provider down (simulated)")
+	}
+
+	if err := checkRecipient(in.Email); err != nil {
+		return err
+	}
+
+	log.Printf("notification.email_verification email=%s user=%s expires_at=%s verify_link=%t",
+		in.Email, in.UserID, in.ExpiresAt.Format(time.RFC3339), in.Token != "",
+	)
+	return nil
+}
+
+// checkRecipient rejects what a real provider would bounce outright, so
+// permanent failures can be exercised without one.
+func checkRecipient(email string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return Permanent(fmt.Errorf("invalid recipient: %w", err))
+	}
+	return nil
+}
@@ -1,14 +1,126 @@
 package notifications
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
 
 type SendRegistrationConfirmationInput struct {
 	Email          string
 	Name           string
 	EventID        string
 	RegistrationID string
+
+	// empty when the event could not be looked up
+	EventTitle string
+
+	// signed token for the self-service DELETE /registrations/cancel link; empty when not configured
+	CancelToken string
+
+	// the event organizer's reply-to, display name and logo, if they set any
+	Branding Branding
 }
 
 type Notifier interface {
 	SendRegistrationConfirmation(ctx context.Context, input SendRegistrationConfirmationInput) error
+	SendEventPublished(ctx context.Context, input SendEventPublishedInput) error
+}
+
+// MessageIDNotifier is implemented by notifiers whose sends get a message id
+// from the provider, kept on the delivery to trace the email later.
+type MessageIDNotifier interface {
+	SendRegistrationConfirmationWithID(ctx context.Context, input SendRegistrationConfirmationInput) (string, error)
+}
+
+type SendAccountExportReadyInput struct {
+	Email  string
+	Name   string
+	JobID  string
+	Counts map[string]int
+
+	// signed token for GET /me/export/download
+	DownloadToken string
+	ExpiresAt     time.Time
+}
+
+// AccountExportNotifier is implemented by notifiers that can email the
+// account export download link.
+type AccountExportNotifier interface {
+	SendAccountExportReady(ctx context.Context, input SendAccountExportReadyInput) error
+}
+
+type SendCapacityAlertInput struct {
+	Email      string
+	Name       string
+	EventID    string
+	EventTitle string
+
+	// Threshold is the percentage of Capacity that Registered reached
+	Threshold  int
+	Capacity   int
+	Registered int
+}
+
+// CapacityAlertNotifier is implemented by notifiers that can tell an
+// organizer their event is filling up or sold out.
+type CapacityAlertNotifier interface {
+	SendCapacityAlert(ctx context.Context, input SendCapacityAlertInput) error
+}
+
+type SendEventReminderInput struct {
+	Email          string
+	Name           string
+	EventID        string
+	EventTitle     string
+	RegistrationID string
+	StartAt        time.Time
+
+	// same self-service cancel link as the confirmation; empty when not configured
+	CancelToken string
+}
+
+// EventReminderNotifier is implemented by notifiers that can remind an
+// attendee that their event starts soon.
+type EventReminderNotifier interface {
+	SendEventReminder(ctx context.Context, input SendEventReminderInput) error
+}
+
+type SendEventPublishedInput struct {
+	Email          string
+	Name           string
+	EventID        string
+	Title          string
+	StartAt        time.Time
+	RegistrationID string
+}
+
+type SendEmailVerificationInput struct {
+	Email  string
+	Name   string
+	UserID string
+
+	// signed token for POST /auth/verify-email
+	Token     string
+	ExpiresAt time.Time
+}
+
+// EmailVerificationNotifier is implemented by notifiers that can email a
+// user the link that verifies their address.
+type EmailVerificationNotifier interface {
+	SendEmailVerification(ctx context.Context, input SendEmailVerificationInput) error
+}
+
+// ErrUnsupported is returned for a notification the configured notifier
+// cannot send; retrying it cannot help.
+var ErrUnsupported = errors.New("notification not supported by this notifier")
+
+// ErrPermanent marks a send the provider rejected for good, such as a
+// malformed or unknown address; resending the same message cannot succeed.
+var ErrPermanent = errors.New("permanent delivery failure")
+
+// Permanent wraps err with ErrPermanent, keeping err matchable.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
 }
@@ -3,12 +3,40 @@ package notifications
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/geocoder89/eventhub/internal/alerting"
+	"github.com/geocoder89/eventhub/internal/observability"
 )
 
 var ErrCircuitOpen = errors.New("circuit breaker open")
 
+// Circuit breaker states.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// circuitGauge is each state's eventhub_notifier_circuit_state value.
+var circuitGauge = map[string]float64{CircuitClosed: 0, CircuitOpen: 1, CircuitHalfOpen: 2}
+
+// CircuitState is a snapshot of the breaker.
+type CircuitState struct {
+	State               string
+	ConsecutiveFailures int
+
+	// when the circuit last opened; zero while closed
+	OpenedAt time.Time
+
+	// how long an open circuit keeps refusing sends; zero once the cooldown
+	// is over, the next send then being the half-open trial
+	UntilHalfOpen time.Duration
+}
+
 type ProtectedNotifierConfig struct {
 	Timeout          time.Duration // hard timeout per send
 	FailureThreshold int           // consecutive failures to open circuit
@@ -17,15 +45,20 @@ type ProtectedNotifierConfig struct {
 }
 
 type ProtectedNotifier struct {
-	inner Notifier
-	cfg   ProtectedNotifierConfig
-	mu    sync.Mutex
+	inner    Notifier
+	fallback Notifier
+	cfg      ProtectedNotifierConfig
+	mu       sync.Mutex
 
-	state string // "closed" | "open" | "half_open"
+	state string // CircuitClosed | CircuitOpen | CircuitHalfOpen
 
 	consecutiveFailures int
 	openedAt            time.Time
 	halfOpenInFlight    int
+
+	alerter alerting.Alerter
+	prom    *observability.Prom
+	now     func() time.Time
 }
 
 func NewProtectedNotifier(inner Notifier, cfg ProtectedNotifierConfig) *ProtectedNotifier {
@@ -46,45 +79,220 @@ func NewProtectedNotifier(inner Notifier, cfg ProtectedNotifierConfig) *Protecte
 	return &ProtectedNotifier{
 		inner: inner,
 		cfg:   cfg,
-		state: "closed",
+		state: CircuitClosed,
+		now:   time.Now,
 	}
 }
 
+// WithAlerter raises an alert each time the circuit opens.
+func (n *ProtectedNotifier) WithAlerter(a alerting.Alerter) *ProtectedNotifier {
+	n.alerter = a
+	return n
+}
+
+// WithFallback sends the notifications the wrapped notifier cannot, such as
+// reminders through a webhook driver, with f instead. f is called directly,
+// outside the breaker.
+func (n *ProtectedNotifier) WithFallback(f Notifier) *ProtectedNotifier {
+	n.fallback = f
+	return n
+}
+
+// WithProm exports the breaker's state and transitions.
+func (n *ProtectedNotifier) WithProm(p *observability.Prom) *ProtectedNotifier {
+	n.prom = p
+	return n
+}
+
+// State reports where the breaker stands, for health checks.
+func (n *ProtectedNotifier) State() CircuitState {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	s := CircuitState{State: n.state, ConsecutiveFailures: n.consecutiveFailures}
+	if n.state != CircuitClosed {
+		s.OpenedAt = n.openedAt
+	}
+	if n.state == CircuitOpen {
+		s.UntilHalfOpen = max(n.cfg.Cooldown-n.now().Sub(n.openedAt), 0)
+	}
+	return s
+}
+
+// Cooldown is how long the circuit stays open once tripped; a send retried
+// sooner is refused with ErrCircuitOpen again.
+func (n *ProtectedNotifier) Cooldown() time.Duration {
+	return n.cfg.Cooldown
+}
+
 func (n *ProtectedNotifier) SendRegistrationConfirmation(ctx context.Context, input SendRegistrationConfirmationInput) error {
+	_, err := n.SendRegistrationConfirmationWithID(ctx, input)
+	return err
+}
+
+// SendRegistrationConfirmationWithID also returns the provider's message id,
+// empty when the wrapped notifier does not report one.
+func (n *ProtectedNotifier) SendRegistrationConfirmationWithID(ctx context.Context, input SendRegistrationConfirmationInput) (string, error) {
 	// fail-fast gate
 
-	if !n.allowRequest() {
-		return ErrCircuitOpen
+	if !n.allowRequest(ctx) {
+		return "", ErrCircuitOpen
 	}
 	// enforce timeout
 
 	sendCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
 	defer cancel()
 
-	err := n.inner.SendRegistrationConfirmation(sendCtx, input)
+	var id string
+	var err error
+	if inner, ok := n.inner.(MessageIDNotifier); ok {
+		id, err = inner.SendRegistrationConfirmationWithID(sendCtx, input)
+	} else {
+		err = n.inner.SendRegistrationConfirmation(sendCtx, input)
+	}
 
-	n.afterRequest(err)
+	n.afterRequest(ctx, err)
+
+	return id, err
+}
+
+// SendAccountExportReady goes through the same breaker; it fails with
+// ErrUnsupported when neither the wrapped notifier nor the fallback can
+// send export emails.
+func (n *ProtectedNotifier) SendAccountExportReady(ctx context.Context, input SendAccountExportReadyInput) error {
+	inner, ok := n.inner.(AccountExportNotifier)
+	if !ok {
+		if fallback, ok := n.fallback.(AccountExportNotifier); ok {
+			return fallback.SendAccountExportReady(ctx, input)
+		}
+		return ErrUnsupported
+	}
+
+	if !n.allowRequest(ctx) {
+		return ErrCircuitOpen
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	err := inner.SendAccountExportReady(sendCtx, input)
+
+	n.afterRequest(ctx, err)
+
+	return err
+}
+
+// SendCapacityAlert goes through the same breaker; it fails with
+// ErrUnsupported when neither the wrapped notifier nor the fallback can
+// send organizer alerts.
+func (n *ProtectedNotifier) SendCapacityAlert(ctx context.Context, input SendCapacityAlertInput) error {
+	inner, ok := n.inner.(CapacityAlertNotifier)
+	if !ok {
+		if fallback, ok := n.fallback.(CapacityAlertNotifier); ok {
+			return fallback.SendCapacityAlert(ctx, input)
+		}
+		return ErrUnsupported
+	}
+
+	if !n.allowRequest(ctx) {
+		return ErrCircuitOpen
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	err := inner.SendCapacityAlert(sendCtx, input)
+
+	n.afterRequest(ctx, err)
 
 	return err
 }
 
-func (n *ProtectedNotifier) allowRequest() bool {
+// SendEventReminder goes through the same breaker; it fails with
+// ErrUnsupported when neither the wrapped notifier nor the fallback can
+// send reminders.
+func (n *ProtectedNotifier) SendEventReminder(ctx context.Context, input SendEventReminderInput) error {
+	inner, ok := n.inner.(EventReminderNotifier)
+	if !ok {
+		if fallback, ok := n.fallback.(EventReminderNotifier); ok {
+			return fallback.SendEventReminder(ctx, input)
+		}
+		return ErrUnsupported
+	}
+
+	if !n.allowRequest(ctx) {
+		return ErrCircuitOpen
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	err := inner.SendEventReminder(sendCtx, input)
+
+	n.afterRequest(ctx, err)
+
+	return err
+}
+
+// SendEmailVerification goes through the same breaker; it fails with
+// ErrUnsupported when neither the wrapped notifier nor the fallback can
+// send verification emails.
+func (n *ProtectedNotifier) SendEmailVerification(ctx context.Context, input SendEmailVerificationInput) error {
+	inner, ok := n.inner.(EmailVerificationNotifier)
+	if !ok {
+		if fallback, ok := n.fallback.(EmailVerificationNotifier); ok {
+			return fallback.SendEmailVerification(ctx, input)
+		}
+		return ErrUnsupported
+	}
+
+	if !n.allowRequest(ctx) {
+		return ErrCircuitOpen
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	err := inner.SendEmailVerification(sendCtx, input)
+
+	n.afterRequest(ctx, err)
+
+	return err
+}
+
+// SendEventPublished goes through the same breaker.
+func (n *ProtectedNotifier) SendEventPublished(ctx context.Context, input SendEventPublishedInput) error {
+	if !n.allowRequest(ctx) {
+		return ErrCircuitOpen
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	err := n.inner.SendEventPublished(sendCtx, input)
+
+	n.afterRequest(ctx, err)
+
+	return err
+}
+
+func (n *ProtectedNotifier) allowRequest(ctx context.Context) bool {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
 	switch n.state {
-	case "closed":
+	case CircuitClosed:
 		return true
-	case "open":
+	case CircuitOpen:
 		// cooldown has passed? move to half open
 
-		if time.Since(n.openedAt) >= n.cfg.Cooldown {
-			n.state = "half_open"
+		if n.now().Sub(n.openedAt) >= n.cfg.Cooldown {
+			n.setState(ctx, CircuitHalfOpen, "cooldown elapsed")
 			n.halfOpenInFlight = 0
 			return true
 		}
 		return false
-	case "half_open":
+	case CircuitHalfOpen:
 		if n.halfOpenInFlight >= n.cfg.HalfOpenMaxCalls {
 			return false
 		}
@@ -98,35 +306,84 @@ func (n *ProtectedNotifier) allowRequest() bool {
 
 }
 
-func (n *ProtectedNotifier) afterRequest(err error) {
+func (n *ProtectedNotifier) afterRequest(ctx context.Context, err error) {
+	if opened, failures := n.recordResult(ctx, err); opened {
+		n.alertOpened(ctx, failures, err)
+	}
+}
+
+// recordResult updates the breaker and reports whether this failure opened
+// it, with the consecutive failures so far.
+func (n *ProtectedNotifier) recordResult(ctx context.Context, err error) (bool, int) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
 	// half-open call just finished
-	if n.state == "half_open" && n.halfOpenInFlight > 0 {
+	if n.state == CircuitHalfOpen && n.halfOpenInFlight > 0 {
 		n.halfOpenInFlight--
 	}
 
-	if err == nil {
+	// a permanent rejection is the provider answering, so it counts as a
+	// success for the breaker
+	if err == nil || errors.Is(err, ErrPermanent) {
 		// success => close circuit and reset counters
 		n.consecutiveFailures = 0
-		n.state = "closed"
-		return
+		n.setState(ctx, CircuitClosed, "send succeeded")
+		return false, 0
 	}
 
 	// failure
 	n.consecutiveFailures++
 
 	// if half-open failed, reopen immediately
-	if n.state == "half_open" {
-		n.state = "open"
-		n.openedAt = time.Now()
-		return
+	if n.state == CircuitHalfOpen {
+		n.openedAt = n.now()
+		n.setState(ctx, CircuitOpen, "half-open trial failed")
+		return true, n.consecutiveFailures
 	}
 
 	// if failures reached threshold, open circuit
 	if n.consecutiveFailures >= n.cfg.FailureThreshold {
-		n.state = "open"
-		n.openedAt = time.Now()
+		wasOpen := n.state == CircuitOpen
+		n.openedAt = n.now()
+		n.setState(ctx, CircuitOpen, "failure threshold reached")
+		return !wasOpen, n.consecutiveFailures
+	}
+	return false, n.consecutiveFailures
+}
+
+// setState moves the breaker to state, counting and logging the change;
+// staying put records nothing. Called with n.mu held.
+func (n *ProtectedNotifier) setState(ctx context.Context, state, reason string) {
+	from := n.state
+	if from == state {
+		return
+	}
+	n.state = state
+
+	n.prom.ObserveNotifierCircuit(from, state, circuitGauge[state])
+
+	level := slog.LevelInfo
+	if state == CircuitOpen {
+		level = slog.LevelWarn
+	}
+	slog.Default().Log(ctx, level, "notifier.circuit_transition",
+		"from", from,
+		"to", state,
+		"reason", reason,
+		"consecutive_failures", n.consecutiveFailures,
+	)
+}
+
+func (n *ProtectedNotifier) alertOpened(ctx context.Context, failures int, err error) {
+	if n.alerter == nil {
+		return
+	}
+
+	// the send's own deadline may be what just failed
+	ctx = context.WithoutCancel(ctx)
+	body := fmt.Sprintf("Notifier circuit opened after %d consecutive failures; sends fail fast for %s. Last error: %s", failures, n.cfg.Cooldown, observability.RedactEmails(err.Error()))
+	if aerr := n.alerter.Notify(ctx, alerting.SeverityCritical, "Notifier circuit open", body, map[string]string{"component": "notifier"}); aerr != nil {
+		slog.Default().WarnContext(ctx, "notifier.alert_failed", "err", aerr)
 	}
 }
@@ -0,0 +1,241 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/alerting"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type failingNotifier struct{}
+
+func (failingNotifier) SendRegistrationConfirmation(ctx context.Context, in SendRegistrationConfirmationInput) error {
+	return errors.New("provider rejected ann@example.com")
+}
+
+func (failingNotifier) SendEventPublished(ctx context.Context, in SendEventPublishedInput) error {
+	return errors.New("provider rejected ann@example.com")
+}
+
+type countingAlerter struct {
+	bodies []string
+}
+
+func (a *countingAlerter) Notify(ctx context.Context, severity alerting.Severity, title, body string, labels map[string]string) error {
+	a.bodies = append(a.bodies, body)
+	return nil
+}
+
+func TestProtectedNotifier_AlertsWhenCircuitOpens(t *testing.T) {
+	alerter := &countingAlerter{}
+	n := NewProtectedNotifier(failingNotifier{}, ProtectedNotifierConfig{FailureThreshold: 2}).WithAlerter(alerter)
+	ctx := context.Background()
+
+	_ = n.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{})
+	if len(alerter.bodies) != 0 {
+		t.Fatalf("expected no alert below the threshold, got %v", alerter.bodies)
+	}
+
+	_ = n.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{})
+	if err := n.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to be open, got %v", err)
+	}
+
+	if len(alerter.bodies) != 1 {
+		t.Fatalf("expected one alert for the open transition, got %d", len(alerter.bodies))
+	}
+	if strings.Contains(alerter.bodies[0], "ann@example.com") || !strings.Contains(alerter.bodies[0], "2 consecutive failures") {
+		t.Fatalf("unexpected alert body %q", alerter.bodies[0])
+	}
+}
+
+type rejectingNotifier struct{}
+
+func (rejectingNotifier) SendRegistrationConfirmation(ctx context.Context, in SendRegistrationConfirmationInput) error {
+	return Permanent(errors.New("mailbox does not exist"))
+}
+
+func (rejectingNotifier) SendEventPublished(ctx context.Context, in SendEventPublishedInput) error {
+	return Permanent(errors.New("mailbox does not exist"))
+}
+
+func TestProtectedNotifier_PermanentRejectionsKeepCircuitClosed(t *testing.T) {
+	n := NewProtectedNotifier(rejectingNotifier{}, ProtectedNotifierConfig{FailureThreshold: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		err := n.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{})
+		if !errors.Is(err, ErrPermanent) {
+			t.Fatalf("send %d: expected the permanent rejection, got %v", i, err)
+		}
+	}
+}
+
+func TestProtectedNotifier_PassesTheMessageIDThrough(t *testing.T) {
+	cfg, _ := startMockSMTP(t)
+	ctx := context.Background()
+	in := SendRegistrationConfirmationInput{Email: "ada@example.com", Name: "Ada"}
+
+	id, err := NewProtectedNotifier(NewSMTPNotifier(cfg), ProtectedNotifierConfig{}).SendRegistrationConfirmationWithID(ctx, in)
+	if err != nil || id == "" {
+		t.Fatalf("expected the SMTP message id, got %q err=%v", id, err)
+	}
+
+	// a notifier without ids still sends, with none to report
+	id, err = NewProtectedNotifier(NewLogNotifier(), ProtectedNotifierConfig{}).SendRegistrationConfirmationWithID(ctx, in)
+	if err != nil || id != "" {
+		t.Fatalf("expected no id from the log notifier, got %q err=%v", id, err)
+	}
+}
+
+func TestProtectedNotifier_SendEventPublished(t *testing.T) {
+	ctx := context.Background()
+	in := SendEventPublishedInput{Email: "ada@example.com", Name: "Ada", EventID: "evt-1", Title: "Go Meetup"}
+
+	if err := NewProtectedNotifier(NewLogNotifier(), ProtectedNotifierConfig{}).SendEventPublished(ctx, in); err != nil {
+		t.Fatalf("expected the log notifier to announce the publish, got %v", err)
+	}
+
+	// announcements count toward the same circuit as confirmations
+	p := NewProtectedNotifier(failingNotifier{}, ProtectedNotifierConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	if err := p.SendEventPublished(ctx, in); err == nil || errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected the provider's error, got %v", err)
+	}
+	if err := p.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{Email: "ada@example.com"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the failed announcement to open the circuit, got %v", err)
+	}
+}
+
+func TestProtectedNotifier_FallbackSendsWhatTheDriverCannot(t *testing.T) {
+	ctx := context.Background()
+	p := NewProtectedNotifier(failingNotifier{}, ProtectedNotifierConfig{FailureThreshold: 1, Cooldown: time.Minute}).
+		WithFallback(NewLogNotifier())
+
+	if err := p.SendEventReminder(ctx, SendEventReminderInput{Email: "ada@example.com", EventID: "evt-1"}); err != nil {
+		t.Fatalf("expected the reminder sent through the fallback, got %v", err)
+	}
+	if err := p.SendCapacityAlert(ctx, SendCapacityAlertInput{Email: "org@example.com", EventID: "evt-1"}); err != nil {
+		t.Fatalf("expected the alert sent through the fallback, got %v", err)
+	}
+	if err := p.SendAccountExportReady(ctx, SendAccountExportReadyInput{Email: "ada@example.com", JobID: "job-1"}); err != nil {
+		t.Fatalf("expected the export email sent through the fallback, got %v", err)
+	}
+
+	// fallback sends don't count against the wrapped notifier's circuit
+	if s := p.State(); s.State != CircuitClosed {
+		t.Fatalf("expected the circuit closed, got %+v", s)
+	}
+}
+
+// switchNotifier fails while fail is set.
+type switchNotifier struct{ fail bool }
+
+func (s *switchNotifier) SendRegistrationConfirmation(ctx context.Context, in SendRegistrationConfirmationInput) error {
+	if s.fail {
+		return errors.New("provider down")
+	}
+	return nil
+}
+
+func (s *switchNotifier) SendEventPublished(ctx context.Context, in SendEventPublishedInput) error {
+	return s.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{})
+}
+
+func TestProtectedNotifier_StateAndMetricsThroughACycle(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	reg := prometheus.NewRegistry()
+	prom := observability.NewProm(reg)
+	inner := &switchNotifier{fail: true}
+	n := NewProtectedNotifier(inner, ProtectedNotifierConfig{FailureThreshold: 2, Cooldown: time.Minute}).WithProm(prom)
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+	ctx := context.Background()
+	in := SendRegistrationConfirmationInput{}
+
+	transitions := func(from, to string) float64 {
+		return testutil.ToFloat64(prom.NotifierCircuitTransitions.WithLabelValues(from, to))
+	}
+	expect := func(state string, gauge float64) {
+		t.Helper()
+		if got := n.State().State; got != state {
+			t.Fatalf("state %q, want %q", got, state)
+		}
+		if got := testutil.ToFloat64(prom.NotifierCircuitState); got != gauge {
+			t.Fatalf("%s: gauge %v, want %v", state, got, gauge)
+		}
+	}
+
+	_ = n.SendRegistrationConfirmation(ctx, in)
+	expect(CircuitClosed, 0)
+	if s := n.State(); s.ConsecutiveFailures != 1 || !s.OpenedAt.IsZero() {
+		t.Fatalf("unexpected closed state %+v", s)
+	}
+
+	// closed -> open
+	_ = n.SendRegistrationConfirmation(ctx, in)
+	expect(CircuitOpen, 1)
+	now = now.Add(20 * time.Second)
+	if s := n.State(); s.ConsecutiveFailures != 2 || !s.OpenedAt.Equal(now.Add(-20*time.Second)) || s.UntilHalfOpen != 40*time.Second {
+		t.Fatalf("unexpected open state %+v", s)
+	}
+	if err := n.SendRegistrationConfirmation(ctx, in); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the open circuit to refuse, got %v", err)
+	}
+
+	// open -> half_open, and the failed trial reopens it
+	now = now.Add(time.Minute)
+	if s := n.State(); s.UntilHalfOpen != 0 {
+		t.Fatalf("expected the cooldown over, got %+v", s)
+	}
+	_ = n.SendRegistrationConfirmation(ctx, in)
+	expect(CircuitOpen, 1)
+
+	// open -> half_open -> closed on a good trial
+	now = now.Add(time.Minute)
+	inner.fail = false
+	if err := n.SendRegistrationConfirmation(ctx, in); err != nil {
+		t.Fatalf("expected the trial to go through, got %v", err)
+	}
+	expect(CircuitClosed, 0)
+	if s := n.State(); s.ConsecutiveFailures != 0 || !s.OpenedAt.IsZero() || s.UntilHalfOpen != 0 {
+		t.Fatalf("unexpected closed state %+v", s)
+	}
+
+	for _, tc := range []struct {
+		from, to string
+		want     float64
+	}{
+		{CircuitClosed, CircuitOpen, 1},
+		{CircuitOpen, CircuitHalfOpen, 2},
+		{CircuitHalfOpen, CircuitOpen, 1},
+		{CircuitHalfOpen, CircuitClosed, 1},
+	} {
+		if got := transitions(tc.from, tc.to); got != tc.want {
+			t.Fatalf("%s -> %s transitions %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+
+	out := logs.String()
+	for _, want := range []string{
+		`level=WARN msg=notifier.circuit_transition from=closed to=open reason="failure threshold reached" consecutive_failures=2`,
+		`from=open to=half_open reason="cooldown elapsed"`,
+		`from=half_open to=open reason="half-open trial failed"`,
+		`level=INFO msg=notifier.circuit_transition from=half_open to=closed reason="send succeeded" consecutive_failures=0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the logs:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is the relay confirmations are submitted to. The connection is
+// upgraded with STARTTLS whenever the server offers it, and credentials are
+// only sent over TLS (or to localhost); implicit TLS on port 465 is not
+// supported.
+type SMTPConfig struct {
+	Host     string
+	Port     int // 587 when zero
+	Username string
+	Password string
+}
+
+// SMTPNotifier sends registration confirmations, publish announcements and
+// email verifications over SMTP as multipart text and HTML emails. Other
+// notifications are not supported yet.
+type SMTPNotifier struct {
+	cfg    SMTPConfig
+	sender Sender
+	now    func() time.Time
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	return &SMTPNotifier{cfg: cfg, sender: DefaultSender, now: time.Now}
+}
+
+// WithSender sets the platform identity confirmations are sent as.
+func (n *SMTPNotifier) WithSender(s Sender) *SMTPNotifier {
+	n.sender = s
+	return n
+}
+
+func (n *SMTPNotifier) SendRegistrationConfirmation(ctx context.Context, in SendRegistrationConfirmationInput) error {
+	_, err := n.SendRegistrationConfirmationWithID(ctx, in)
+	return err
+}
+
+// SendRegistrationConfirmationWithID returns the Message-ID the email was
+// sent with, without its angle brackets.
+func (n *SMTPNotifier) SendRegistrationConfirmationWithID(ctx context.Context, in SendRegistrationConfirmationInput) (string, error) {
+	if err := checkRecipient(in.Email); err != nil {
+		return "", err
+	}
+
+	msg, err := n.sender.RegistrationConfirmation(in)
+	if err != nil {
+		return "", fmt.Errorf("render confirmation: %w", err)
+	}
+	return n.send(ctx, in.Email, msg)
+}
+
+func (n *SMTPNotifier) SendEventPublished(ctx context.Context, in SendEventPublishedInput) error {
+	if err := checkRecipient(in.Email); err != nil {
+		return err
+	}
+
+	msg, err := n.sender.EventPublished(in)
+	if err != nil {
+		return fmt.Errorf("render publish announcement: %w", err)
+	}
+	_, err = n.send(ctx, in.Email, msg)
+	return err
+}
+
+func (n *SMTPNotifier) SendEmailVerification(ctx context.Context, in SendEmailVerificationInput) error {
+	if err := checkRecipient(in.Email); err != nil {
+		return err
+	}
+
+	msg, err := n.sender.EmailVerification(in)
+	if err != nil {
+		return fmt.Errorf("render email verification: %w", err)
+	}
+	_, err = n.send(ctx, in.Email, msg)
+	return err
+}
+
+// send submits msg to email, which checkRecipient has accepted, and returns
+// its Message-ID.
+func (n *SMTPNotifier) send(ctx context.Context, email string, msg Email) (string, error) {
+	id, err := newMessageID(n.sender.Address)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := buildMessage(msg, id, n.now())
+	if err != nil {
+		return "", fmt.Errorf("build message: %w", err)
+	}
+
+	rcpt, _ := mail.ParseAddress(email)
+	if err := n.deliver(ctx, n.sender.Address, rcpt.Address, raw); err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return "", fmt.Errorf("%w: %w", cerr, err)
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// deliver runs one SMTP transaction. net/smtp has no context support, so ctx
+// ending expires the connection's deadline, failing whichever exchange is in
+// flight.
+func (n *SMTPNotifier) deliver(ctx context.Context, from, to string, msg []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port)))
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp greeting: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if n.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		// a 5xx here is the server refusing this recipient for good
+		var perr *textproto.Error
+		if errors.As(err, &perr) && perr.Code >= 500 {
+			return Permanent(fmt.Errorf("smtp rcpt to: %w", err))
+		}
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+
+	// the message is accepted once DATA is; a failed QUIT changes nothing
+	_ = c.Quit()
+	return nil
+}
+
+// newMessageID makes a Message-ID (without angle brackets) on the sender's
+// domain.
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	domain := "eventhub.local"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+	return hex.EncodeToString(b) + "@" + domain, nil
+}
+
+// buildMessage renders msg as a multipart/alternative email, text first so
+// clients that can show HTML pick the last part.
+func buildMessage(msg Email, messageID string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	header := func(k, v string) {
+		out.WriteString(k + ": " + v + "\r\n")
+	}
+	header("From", msg.From)
+	header("To", msg.To)
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", "<"+messageID+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,278 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpTranscript is what the mock server received in one session.
+type smtpTranscript struct {
+	auth string
+	from string
+	rcpt []string
+	data []byte
+}
+
+// startMockSMTP serves SMTP sessions on a local port and sends each
+// transcript on the returned channel. Recipients starting with "unknown" are
+// refused with 550.
+func startMockSMTP(t *testing.T) (SMTPConfig, <-chan smtpTranscript) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan smtpTranscript, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveMockSMTP(conn, out)
+		}
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	return SMTPConfig{Host: "127.0.0.1", Port: port, Username: "relay-user", Password: "relay-pass"}, out
+}
+
+func serveMockSMTP(conn net.Conn, out chan<- smtpTranscript) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	var tr smtpTranscript
+
+	_ = tp.PrintfLine("220 mock ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			_ = tp.PrintfLine("250-mock")
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			tr.auth = strings.TrimPrefix(arg, "PLAIN ")
+			_ = tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			tr.from = arg
+			_ = tp.PrintfLine("250 2.1.0 Ok")
+		case "RCPT":
+			if strings.HasPrefix(arg, "TO:<unknown") {
+				_ = tp.PrintfLine("550 5.1.1 No such user")
+				continue
+			}
+			tr.rcpt = append(tr.rcpt, arg)
+			_ = tp.PrintfLine("250 2.1.5 Ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			tr.data, err = tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			_ = tp.PrintfLine("250 2.0.0 Ok: queued as 4F1A2B")
+		case "QUIT":
+			_ = tp.PrintfLine("221 2.0.0 Bye")
+			out <- tr
+			return
+		default:
+			_ = tp.PrintfLine("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+func TestSMTPNotifier_SendsMultipartConfirmation(t *testing.T) {
+	cfg, sessions := startMockSMTP(t)
+	n := NewSMTPNotifier(cfg).WithSender(Sender{
+		Address:       "no-reply@eventhub.example",
+		Name:          "EventHub",
+		OrganizerName: true,
+		CancelPageURL: "https://eventhub.example/cancel",
+	})
+	n.now = func() time.Time { return time.Date(2026, time.May, 4, 9, 30, 0, 0, time.UTC) }
+
+	id, err := n.SendRegistrationConfirmationWithID(context.Background(), SendRegistrationConfirmationInput{
+		Email:          "ada@example.com",
+		Name:           "Ada <Lovelace>",
+		EventTitle:     "Jazz & Wine Night",
+		RegistrationID: "reg-1",
+		CancelToken:    "tok+1/2",
+		Branding:       Branding{ReplyTo: "hello@jazzclub.example", DisplayName: "Jazz Club"},
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var tr smtpTranscript
+	select {
+	case tr = <-sessions:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the mock server saw no complete session")
+	}
+
+	if auth, _ := base64.StdEncoding.DecodeString(tr.auth); string(auth) != "\x00relay-user\x00relay-pass" {
+		t.Fatalf("unexpected AUTH PLAIN credentials %q", auth)
+	}
+	if tr.from != "FROM:<no-reply@eventhub.example>" || len(tr.rcpt) != 1 || tr.rcpt[0] != "TO:<ada@example.com>" {
+		t.Fatalf("unexpected envelope from=%q rcpt=%q", tr.from, tr.rcpt)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(tr.data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	// the provider message id is what ends up on the delivery row
+	if id == "" || msg.Header.Get("Message-ID") != "<"+id+">" || !strings.HasSuffix(id, "@eventhub.example") {
+		t.Fatalf("expected the returned id %q as the Message-ID, got %q", id, msg.Header.Get("Message-ID"))
+	}
+	for k, want := range map[string]string{
+		"From":         `"Jazz Club" <no-reply@eventhub.example>`,
+		"To":           `"Ada <Lovelace>" <ada@example.com>`,
+		"Reply-To":     "<hello@jazzclub.example>",
+		"Subject":      "Your registration with Jazz Club",
+		"Date":         "Mon, 04 May 2026 09:30:00 +0000",
+		"Mime-Version": "1.0",
+	} {
+		if got := msg.Header.Get(k); got != want {
+			t.Fatalf("header %s = %q, want %q", k, got, want)
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q err=%v", mediaType, err)
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	bodies := map[string]string{}
+	var order []string
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		ct, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		b, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		bodies[ct] = string(b)
+		order = append(order, ct)
+	}
+	if strings.Join(order, ",") != "text/plain,text/html" {
+		t.Fatalf("expected a text then an HTML part, got %v", order)
+	}
+
+	link := "https://eventhub.example/cancel?token=tok%2B1%2F2"
+	text := bodies["text/plain"]
+	for _, want := range []string{"Hi Ada <Lovelace>,", "registered for Jazz & Wine Night", link, "reach Jazz Club"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text part lacks %q:\n%s", want, text)
+		}
+	}
+	html := bodies["text/html"]
+	for _, want := range []string{"Hi Ada &lt;Lovelace&gt;,", "<strong>Jazz &amp; Wine Night</strong>", `href="` + link + `"`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("HTML part lacks %q:\n%s", want, html)
+		}
+	}
+}
+
+func TestSMTPNotifier_SendsPublishAnnouncement(t *testing.T) {
+	cfg, sessions := startMockSMTP(t)
+	n := NewSMTPNotifier(cfg).WithSender(Sender{Address: "no-reply@eventhub.example", Name: "EventHub"})
+
+	err := n.SendEventPublished(context.Background(), SendEventPublishedInput{
+		Email:          "ada@example.com",
+		Name:           "Ada",
+		EventID:        "evt-1",
+		Title:          "Jazz & Wine Night",
+		StartAt:        time.Date(2026, time.June, 5, 19, 0, 0, 0, time.UTC),
+		RegistrationID: "reg-1",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var tr smtpTranscript
+	select {
+	case tr = <-sessions:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the mock server saw no complete session")
+	}
+	if len(tr.rcpt) != 1 || tr.rcpt[0] != "TO:<ada@example.com>" {
+		t.Fatalf("unexpected envelope rcpt=%q", tr.rcpt)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(tr.data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	dec := new(mime.WordDecoder)
+	if subject, _ := dec.DecodeHeader(msg.Header.Get("Subject")); subject != "Jazz & Wine Night is published" {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !strings.Contains(string(body), "Fri 5 Jun 2026 19:00 UTC") {
+		t.Fatalf("expected the start time in the message:\n%s", body)
+	}
+}
+
+func TestSMTPNotifier_RefusedRecipientIsPermanent(t *testing.T) {
+	cfg, _ := startMockSMTP(t)
+	n := NewSMTPNotifier(cfg)
+
+	err := n.SendRegistrationConfirmation(context.Background(), SendRegistrationConfirmationInput{Email: "unknown@example.com", Name: "Ghost"})
+	if !errors.Is(err, ErrPermanent) {
+		t.Fatalf("expected a 550 on RCPT to be permanent, got %v", err)
+	}
+}
+
+func TestSMTPNotifier_HonoursTheContextDeadline(t *testing.T) {
+	// accepts the connection but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			_, _ = bufio.NewReader(conn).ReadString('\n')
+			conn.Close()
+		}
+	}()
+
+	n := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = n.SendRegistrationConfirmation(ctx, SendRegistrationConfirmationInput{Email: "ada@example.com", Name: "Ada"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the send to give up at the deadline, took %s", elapsed)
+	}
+}
@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+)
+
+// WebhookNotifier hands registration confirmations, publish announcements and
+// email verifications to a service that sends them itself: each one is POSTed
+// as JSON, signed like outbound webhooks (X-EventHub-Signature, HMAC-SHA256 of
+// the body under the secret). Other notifications are not supported.
+type WebhookNotifier struct {
+	url     string
+	secret  string
+	client  *http.Client
+	timeout time.Duration
+}
+
+// DefaultWebhookTimeout bounds each webhook send. The worker gives the
+// notifier circuit the same budget, so the breaker and the request give up
+// together.
+const DefaultWebhookTimeout = 5 * time.Second
+
+// NewWebhookNotifier posts to url. A nil client gets http.DefaultClient;
+// each send is bounded by DefaultWebhookTimeout either way.
+func NewWebhookNotifier(url, secret string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookNotifier{url: url, secret: secret, client: client, timeout: DefaultWebhookTimeout}
+}
+
+// WithTimeout bounds each request, from connect to the end of the response.
+func (n *WebhookNotifier) WithTimeout(d time.Duration) *WebhookNotifier {
+	if d > 0 {
+		n.timeout = d
+	}
+	return n
+}
+
+// webhookConfirmation is the body of a confirmation POST.
+type webhookConfirmation struct {
+	Kind           string          `json:"kind"`
+	Email          string          `json:"email"`
+	Name           string          `json:"name"`
+	EventID        string          `json:"eventId"`
+	EventTitle     string          `json:"eventTitle,omitempty"`
+	RegistrationID string          `json:"registrationId"`
+	CancelToken    string          `json:"cancelToken,omitempty"`
+	Branding       webhookBranding `json:"branding"`
+}
+
+type webhookBranding struct {
+	ReplyTo     string `json:"replyTo,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	LogoURL     string `json:"logoUrl,omitempty"`
+}
+
+// webhookEventPublished is the body of a publish announcement POST.
+type webhookEventPublished struct {
+	Kind           string    `json:"kind"`
+	Email          string    `json:"email"`
+	Name           string    `json:"name"`
+	EventID        string    `json:"eventId"`
+	EventTitle     string    `json:"eventTitle"`
+	StartAt        time.Time `json:"startAt"`
+	RegistrationID string    `json:"registrationId"`
+}
+
+// webhookEmailVerification is the body of an email verification POST.
+type webhookEmailVerification struct {
+	Kind      string    `json:"kind"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	UserID    string    `json:"userId"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+const (
+	kindRegistrationConfirmation = "registration.confirmation"
+	kindEventPublished           = "event.published"
+	kindEmailVerification        = "email.verification"
+)
+
+// SendRegistrationConfirmation succeeds on any 2xx. A 4xx other than 408 and
+// 429 is the service refusing this confirmation and fails with ErrPermanent;
+// anything else (network errors, timeouts, 5xx) is worth retrying.
+func (n *WebhookNotifier) SendRegistrationConfirmation(ctx context.Context, in SendRegistrationConfirmationInput) error {
+	return n.post(ctx, kindRegistrationConfirmation, in.RegistrationID, webhookConfirmation{
+		Kind:           kindRegistrationConfirmation,
+		Email:          in.Email,
+		Name:           in.Name,
+		EventID:        in.EventID,
+		EventTitle:     in.EventTitle,
+		RegistrationID: in.RegistrationID,
+		CancelToken:    in.CancelToken,
+		Branding: webhookBranding{
+			ReplyTo:     in.Branding.ReplyTo,
+			DisplayName: in.Branding.DisplayName,
+			LogoURL:     in.Branding.LogoURL,
+		},
+	})
+}
+
+// SendEventPublished posts the announcement the same way, with the same
+// status handling.
+func (n *WebhookNotifier) SendEventPublished(ctx context.Context, in SendEventPublishedInput) error {
+	return n.post(ctx, kindEventPublished, in.RegistrationID, webhookEventPublished{
+		Kind:           kindEventPublished,
+		Email:          in.Email,
+		Name:           in.Name,
+		EventID:        in.EventID,
+		EventTitle:     in.Title,
+		StartAt:        in.StartAt,
+		RegistrationID: in.RegistrationID,
+	})
+}
+
+// SendEmailVerification posts the verification token the same way; the
+// service builds the link. Each token is its own delivery.
+func (n *WebhookNotifier) SendEmailVerification(ctx context.Context, in SendEmailVerificationInput) error {
+	return n.post(ctx, kindEmailVerification, in.UserID+":"+strconv.FormatInt(in.ExpiresAt.Unix(), 10), webhookEmailVerification{
+		Kind:      kindEmailVerification,
+		Email:     in.Email,
+		Name:      in.Name,
+		UserID:    in.UserID,
+		Token:     in.Token,
+		ExpiresAt: in.ExpiresAt,
+	})
+}
+
+// post sends v as a signed notification of kind. deliveryID is the same on
+// every retry, so the service can drop duplicates.
+func (n *WebhookNotifier) post(ctx context.Context, kind, deliveryID string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("notifier webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EventHub-Notifier/1")
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(n.secret, body))
+	req.Header.Set("X-EventHub-Event", kind)
+	req.Header.Set("X-EventHub-Delivery", deliveryID)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notifier webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// a short excerpt explains the refusal; the rest is drained so the
+	// connection can be reused
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("notifier webhook: status %d: %s", resp.StatusCode, strings.TrimSpace(string(excerpt)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+)
+
+var webhookInput = SendRegistrationConfirmationInput{
+	Email:          "ada@example.com",
+	Name:           "Ada",
+	EventID:        "evt-1",
+	EventTitle:     "Jazz Night",
+	RegistrationID: "reg-1",
+	CancelToken:    "tok",
+	Branding:       Branding{DisplayName: "Jazz Club"},
+}
+
+func TestWebhookNotifier_PostsSignedConfirmation(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL, "whsec_test", srv.Client())
+	if err := n.SendRegistrationConfirmation(context.Background(), webhookInput); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if got, want := gotHeader.Get(webhook.SignatureHeader), webhook.Sign("whsec_test", gotBody); got != want {
+		t.Fatalf("signature %q, want %q", got, want)
+	}
+	if gotHeader.Get("Content-Type") != "application/json" || gotHeader.Get("X-EventHub-Delivery") != "reg-1" {
+		t.Fatalf("unexpected headers %v", gotHeader)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(gotBody, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	for k, want := range map[string]any{
+		"kind":           "registration.confirmation",
+		"email":          "ada@example.com",
+		"eventId":        "evt-1",
+		"eventTitle":     "Jazz Night",
+		"registrationId": "reg-1",
+		"cancelToken":    "tok",
+	} {
+		if body[k] != want {
+			t.Fatalf("body %s = %v, want %v (%s)", k, body[k], want, gotBody)
+		}
+	}
+	if b, _ := body["branding"].(map[string]any); b["displayName"] != "Jazz Club" {
+		t.Fatalf("expected the branding in the body, got %s", gotBody)
+	}
+}
+
+func TestWebhookNotifier_PostsPublishAnnouncement(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL, "whsec_test", srv.Client())
+	err := n.SendEventPublished(context.Background(), SendEventPublishedInput{
+		Email:          "ada@example.com",
+		Name:           "Ada",
+		EventID:        "evt-1",
+		Title:          "Jazz Night",
+		StartAt:        time.Date(2026, time.June, 5, 19, 0, 0, 0, time.UTC),
+		RegistrationID: "reg-1",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if got, want := gotHeader.Get(webhook.SignatureHeader), webhook.Sign("whsec_test", gotBody); got != want {
+		t.Fatalf("signature %q, want %q", got, want)
+	}
+	if gotHeader.Get("X-EventHub-Event") != "event.published" || gotHeader.Get("X-EventHub-Delivery") != "reg-1" {
+		t.Fatalf("unexpected headers %v", gotHeader)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(gotBody, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	for k, want := range map[string]any{
+		"kind":           "event.published",
+		"email":          "ada@example.com",
+		"eventId":        "evt-1",
+		"eventTitle":     "Jazz Night",
+		"startAt":        "2026-06-05T19:00:00Z",
+		"registrationId": "reg-1",
+	} {
+		if body[k] != want {
+			t.Fatalf("body %s = %v, want %v (%s)", k, body[k], want, gotBody)
+		}
+	}
+}
+
+func TestWebhookNotifier_StatusMapping(t *testing.T) {
+	for _, tc := range []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, false},
+		{http.StatusBadRequest, true},
+		{http.StatusUnprocessableEntity, true},
+		{http.StatusTooManyRequests, false},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", tc.status)
+		}))
+
+		err := NewWebhookNotifier(srv.URL, "whsec_test", srv.Client()).SendRegistrationConfirmation(context.Background(), webhookInput)
+		srv.Close()
+
+		if err == nil {
+			t.Fatalf("%d: expected an error", tc.status)
+		}
+		if errors.Is(err, ErrPermanent) != tc.permanent {
+			t.Fatalf("%d: permanent=%v, want %v (%v)", tc.status, errors.Is(err, ErrPermanent), tc.permanent, err)
+		}
+	}
+}
+
+func TestWebhookNotifier_TimesOut(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	n := NewWebhookNotifier(srv.URL, "whsec_test", srv.Client()).WithTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	err := n.SendRegistrationConfirmation(context.Background(), webhookInput)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrPermanent) {
+		t.Fatalf("expected a retryable deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the send to give up at the timeout, took %s", elapsed)
+	}
+
+	// the caller's cancellation ends it too
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewWebhookNotifier(srv.URL, "whsec_test", srv.Client()).SendRegistrationConfirmation(ctx, webhookInput); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation, got %v", err)
+	}
+}
+
+func TestWebhookNotifier_BehindProtectedNotifier(t *testing.T) {
+	status := http.StatusBadRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	p := NewProtectedNotifier(NewWebhookNotifier(srv.URL, "whsec_test", srv.Client()), ProtectedNotifierConfig{FailureThreshold: 2, Cooldown: time.Minute})
+
+	// refusals are answers, so they never trip the circuit
+	for range 3 {
+		if err := p.SendRegistrationConfirmation(context.Background(), webhookInput); !errors.Is(err, ErrPermanent) {
+			t.Fatalf("expected the 400 passed through as permanent, got %v", err)
+		}
+	}
+
+	status = http.StatusServiceUnavailable
+	for range 2 {
+		_ = p.SendRegistrationConfirmation(context.Background(), webhookInput)
+	}
+	if err := p.SendRegistrationConfirmation(context.Background(), webhookInput); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected repeated 503s to open the circuit, got %v", err)
+	}
+}
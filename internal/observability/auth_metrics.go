@@ -0,0 +1,43 @@
+package observability
+
+import "time"
+
+// Auth metric results. Labels stay within this set so series count is fixed.
+const (
+	AuthResultSuccess            = "success"
+	AuthResultInvalidCredentials = "invalid_credentials"
+	AuthResultMissing            = "missing"
+	AuthResultInvalid            = "invalid"
+	AuthResultExpired            = "expired"
+	AuthResultReused             = "reused"
+	AuthResultError              = "error"
+)
+
+// ObserveLogin records one login attempt. A nil Prom is a no-op so handlers
+// built without metrics need no checks.
+func (p *Prom) ObserveLogin(result string, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.AuthLoginsTotal.WithLabelValues(result).Inc()
+	p.AuthLoginDuration.WithLabelValues(result).Observe(d.Seconds())
+}
+
+// ObserveRefresh records one refresh attempt; reuse also counts towards
+// auth_token_reuse_total.
+func (p *Prom) ObserveRefresh(result string) {
+	if p == nil {
+		return
+	}
+	p.AuthRefreshesTotal.WithLabelValues(result).Inc()
+	if result == AuthResultReused {
+		p.AuthTokenReuseTotal.Inc()
+	}
+}
+
+func (p *Prom) IncAuthLockout() {
+	if p == nil {
+		return
+	}
+	p.AuthLockoutsTotal.Inc()
+}
@@ -0,0 +1,39 @@
+package observability
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClass sorts an error for logs and metrics: a cancellation is the caller
+// giving up, not the server failing, and is kept out of error counts.
+type ErrClass string
+
+const (
+	ErrClassNone      ErrClass = ""
+	ErrClassCancelled ErrClass = "cancelled"
+	ErrClassFailure   ErrClass = "error"
+)
+
+// ClassifyErr tells a cancellation from a failure. A context error counts as
+// a cancellation when ctx, the caller's context, has ended as well: the
+// client disconnected or the process is shutting down. A deadline set further
+// in, such as a handler's DB budget, that fires while ctx is still live is a
+// failure.
+//
+// Without ctx (ObserveDB has none) only context.Canceled counts: deadlines in
+// this codebase are its own budgets, while cancellation only comes from a
+// departing caller or a shutdown.
+func ClassifyErr(ctx context.Context, err error) ErrClass {
+	switch {
+	case err == nil:
+		return ErrClassNone
+	case ctx == nil:
+		if errors.Is(err, context.Canceled) {
+			return ErrClassCancelled
+		}
+	case ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		return ErrClassCancelled
+	}
+	return ErrClassFailure
+}
@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestClassifyErr(t *testing.T) {
+	live := context.Background()
+
+	disconnected, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancelExpired := context.WithTimeout(context.Background(), 0)
+	defer cancelExpired()
+	<-expired.Done()
+
+	// what a query run under a departed request's or a DB budget's context returns
+	canceledQuery := fmt.Errorf("list events: %w", context.Canceled)
+	timedOutQuery := fmt.Errorf("list events: timeout: %w", context.DeadlineExceeded)
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want ErrClass
+	}{
+		{"no error", live, nil, ErrClassNone},
+		{"client disconnected", disconnected, canceledQuery, ErrClassCancelled},
+		{"caller's deadline passed", expired, timedOutQuery, ErrClassCancelled},
+		{"inner budget expired while the caller waits", live, timedOutQuery, ErrClassFailure},
+		{"cancellation the caller did not ask for", live, canceledQuery, ErrClassFailure},
+		{"real error after the caller left", disconnected, errors.New("syntax error"), ErrClassFailure},
+		{"no context, cancelled", nil, canceledQuery, ErrClassCancelled},
+		{"no context, budget expired", nil, timedOutQuery, ErrClassFailure},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ClassifyErr(tc.ctx, tc.err); got != tc.want {
+				t.Fatalf("ClassifyErr = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestObserveDB_CancelledQueriesAreNotErrors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p := NewProm(reg)
+
+	_ = p.ObserveDB("events.list", func() error { return fmt.Errorf("query: %w", context.Canceled) })
+	_ = p.ObserveDB("events.list", func() error { return fmt.Errorf("query: timeout: %w", context.DeadlineExceeded) })
+
+	want := `
+# HELP eventhub_db_errors_total DB errors by logical op and class.
+# TYPE eventhub_db_errors_total counter
+eventhub_db_errors_total{class="timeout",op="events.list"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "eventhub_db_errors_total"); err != nil {
+		t.Fatal(err)
+	}
+
+	for status, want := range map[string]uint64{"cancelled": 1, "error": 1} {
+		var m dto.Metric
+		if err := p.DbQueryDuration.WithLabelValues("events.list", status).(prometheus.Histogram).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetHistogram().GetSampleCount(); got != want {
+			t.Fatalf("status=%s timed %d queries, want %d", status, got, want)
+		}
+	}
+}
@@ -14,7 +14,11 @@ func (p *Prom) ObserveDB(op string, fn func() error) error {
 
 	status := "ok"
 
-	if err != nil {
+	switch ClassifyErr(nil, err) {
+	case ErrClassCancelled:
+		// the caller went away mid-query; not a database problem
+		status = "cancelled"
+	case ErrClassFailure:
 		status = "error"
 		p.DbErrorsTotal.WithLabelValues(op, classifyDBErr(err)).Inc()
 	}
@@ -1,16 +1,55 @@
 package observability
 
 import (
+	"strconv"
 	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
+// JobMetrics counts worker job outcomes in Prometheus counters, so the logged
+// snapshot and a scrape of /metrics report the same numbers. Register exposes
+// them; unregistered they still count for the log line.
 type JobMetrics struct {
-	claimed      atomic.Uint64
-	done         atomic.Uint64
-	failed       atomic.Uint64
-	retried      atomic.Uint64
-	deadLettered atomic.Uint64
+	events *prometheus.CounterVec
+
+	claimed      prometheus.Counter
+	done         prometheus.Counter
+	failed       prometheus.Counter
+	retried      prometheus.Counter
+	deadLettered prometheus.Counter
+	timedOut     prometheus.Counter
+	released     prometheus.Counter
+	interrupted  prometheus.Counter
+
+	// jobs executing right now, per job type
+	inFlightByType *prometheus.GaugeVec
+
+	// time from due to claimed, per job type
+	queueWait *prometheus.HistogramVec
+
+	// 1 while an admin has paused claiming
+	queuePaused prometheus.Gauge
+
+	// 1 on the instance running housekeeping, labelled with its worker id
+	housekeepingLeader *prometheus.GaugeVec
+
+	// how long the oldest due pending job has waited, as last read
+	oldestPending prometheus.Gauge
+
+	// jobs taken back from a dead worker, by what became of them
+	staleRequeues *prometheus.CounterVec
+
+	// jobs this worker found still locked under its own id at startup
+	orphansReleased prometheus.Counter
+
+	// jobs claimed with a type no handler is registered for
+	unknownTypes prometheus.Counter
+
+	// requests to the health listener, by route and status
+	healthRequests *prometheus.CounterVec
 
 	// duration stats (nanoseconds)
 	durationCount atomic.Uint64
@@ -19,27 +58,219 @@ type JobMetrics struct {
 }
 
 func NewJobMetrics() *JobMetrics {
-	m := &JobMetrics{}
+	events := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "eventhub",
+			Subsystem: "worker",
+			Name:      "job_events_total",
+			Help:      "Jobs claimed and their outcomes in this worker process.",
+		},
+		[]string{"event"}, // event=claimed|done|failed|retried|dead_lettered|timed_out|released|interrupted
+	)
+
+	queueWait := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "eventhub",
+			Subsystem: "jobs",
+			Name:      "queue_wait_seconds",
+			Help:      "Time jobs waited between becoming due and being started, by job type.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10), // 10ms to ~45min
+		},
+		[]string{"job_type"},
+	)
+
+	inFlightByType := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "eventhub",
+			Subsystem: "jobs",
+			Name:      "in_flight_by_type",
+			Help:      "Jobs executing in this worker process, by job type.",
+		},
+		[]string{"job_type"},
+	)
+
+	queuePaused := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "eventhub",
+		Subsystem: "jobs",
+		Name:      "queue_paused",
+		Help:      "1 while the queue is paused and this worker claims nothing, else 0.",
+	})
+
+	housekeepingLeader := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "eventhub",
+			Subsystem: "worker",
+			Name:      "housekeeping_leader",
+			Help:      "1 while this worker instance holds the housekeeping lock and runs the maintenance loops, else 0.",
+		},
+		[]string{"worker_id"},
+	)
+
+	oldestPending := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "eventhub",
+		Subsystem: "jobs",
+		Name:      "oldest_pending_seconds",
+		Help:      "How long the oldest due pending job has waited past its run_at, as last read by this worker; 0 when nothing is due.",
+	})
+
+	staleRequeues := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "eventhub",
+			Subsystem: "jobs",
+			Name:      "stale_requeues_total",
+			Help:      "Processing jobs whose lock expired, by outcome: requeued with an attempt used, dead-lettered out of attempts, or cancelled.",
+		},
+		[]string{"outcome"}, // outcome=requeued|dead_lettered|cancelled
+	)
+
+	orphansReleased := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "eventhub",
+		Subsystem: "worker",
+		Name:      "orphans_released_total",
+		Help:      "Processing jobs locked under this worker's id by an earlier process, handed back at startup.",
+	})
+
+	unknownTypes := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "eventhub",
+		Subsystem: "worker",
+		Name:      "unknown_job_types_total",
+		Help:      "Claimed jobs whose type this worker has no handler for; each is dead-lettered on its first attempt.",
+	})
+
+	healthRequests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "eventhub",
+			Subsystem: "worker",
+			Name:      "health_requests_total",
+			Help:      "Requests to the worker health endpoints, by route and response status.",
+		},
+		[]string{"path", "status"},
+	)
+
+	m := &JobMetrics{
+		events:             events,
+		queueWait:          queueWait,
+		queuePaused:        queuePaused,
+		inFlightByType:     inFlightByType,
+		claimed:            events.WithLabelValues("claimed"),
+		done:               events.WithLabelValues("done"),
+		failed:             events.WithLabelValues("failed"),
+		retried:            events.WithLabelValues("retried"),
+		deadLettered:       events.WithLabelValues("dead_lettered"),
+		timedOut:           events.WithLabelValues("timed_out"),
+		released:           events.WithLabelValues("released"),
+		interrupted:        events.WithLabelValues("interrupted"),
+		housekeepingLeader: housekeepingLeader,
+		oldestPending:      oldestPending,
+		staleRequeues:      staleRequeues,
+		orphansReleased:    orphansReleased,
+		unknownTypes:       unknownTypes,
+		healthRequests:     healthRequests,
+	}
 	m.durationMax.Store(0)
 	return m
 }
 
+// Register adds the job and health request counters, the queue wait histogram
+// and the paused, per-type in-flight, housekeeping leader and oldest pending
+// gauges to reg.
+func (m *JobMetrics) Register(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.events, m.queueWait, m.queuePaused, m.inFlightByType, m.housekeepingLeader, m.oldestPending, m.staleRequeues, m.orphansReleased, m.unknownTypes, m.healthRequests} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *JobMetrics) IncClaimed() {
-	m.claimed.Add(1)
+	m.claimed.Inc()
 }
 func (m *JobMetrics) IncDone() {
-	m.done.Add(1)
+	m.done.Inc()
 }
 func (m *JobMetrics) IncFailed() {
-	m.failed.Add(1)
+	m.failed.Inc()
 }
 
 func (m *JobMetrics) IncRetried() {
-	m.retried.Add(1)
+	m.retried.Inc()
 }
 
 func (m *JobMetrics) IncDeadLettered() {
-	m.deadLettered.Add(1)
+	m.deadLettered.Inc()
+}
+
+func (m *JobMetrics) IncTimedOut() {
+	m.timedOut.Inc()
+}
+
+// IncReleased counts a claimed job handed back unrun because its type was
+// at its concurrency limit.
+func (m *JobMetrics) IncReleased() {
+	m.released.Inc()
+}
+
+// IncInterrupted counts a job cut short by shutdown and handed back without
+// spending an attempt.
+func (m *JobMetrics) IncInterrupted() {
+	m.interrupted.Inc()
+}
+
+// AddOrphansReleased counts jobs released at startup that an earlier process
+// with the same worker id left processing.
+func (m *JobMetrics) AddOrphansReleased(n int64) {
+	m.orphansReleased.Add(float64(n))
+}
+
+// IncUnknownType counts a claimed job no handler is registered for.
+func (m *JobMetrics) IncUnknownType() {
+	m.unknownTypes.Inc()
+}
+
+// IncHealthRequest counts one request to the health listener.
+func (m *JobMetrics) IncHealthRequest(path string, status int) {
+	m.healthRequests.WithLabelValues(path, strconv.Itoa(status)).Inc()
+}
+
+// AddStaleRequeues counts one pass over jobs whose lock expired.
+func (m *JobMetrics) AddStaleRequeues(requeued, deadLettered, cancelled int64) {
+	m.staleRequeues.WithLabelValues("requeued").Add(float64(requeued))
+	m.staleRequeues.WithLabelValues("dead_lettered").Add(float64(deadLettered))
+	m.staleRequeues.WithLabelValues("cancelled").Add(float64(cancelled))
+}
+
+// SetInFlight reports how many jobs of jobType are executing.
+func (m *JobMetrics) SetInFlight(jobType string, n int) {
+	m.inFlightByType.WithLabelValues(jobType).Set(float64(n))
+}
+
+// ObserveQueueWait records how long a job of jobType waited to start.
+func (m *JobMetrics) ObserveQueueWait(jobType string, d time.Duration) {
+	m.queueWait.WithLabelValues(jobType).Observe(d.Seconds())
+}
+
+// SetQueuePaused reports whether this worker sees the queue paused.
+func (m *JobMetrics) SetQueuePaused(paused bool) {
+	if paused {
+		m.queuePaused.Set(1)
+		return
+	}
+	m.queuePaused.Set(0)
+}
+
+// SetHousekeepingLeader reports whether workerID currently runs housekeeping.
+func (m *JobMetrics) SetHousekeepingLeader(workerID string, leader bool) {
+	if leader {
+		m.housekeepingLeader.WithLabelValues(workerID).Set(1)
+		return
+	}
+	m.housekeepingLeader.WithLabelValues(workerID).Set(0)
+}
+
+// SetOldestPending reports the age of the oldest due pending job.
+func (m *JobMetrics) SetOldestPending(age time.Duration) {
+	m.oldestPending.Set(age.Seconds())
 }
 
 func (m *JobMetrics) ObserveDuration(d time.Duration) {
@@ -62,12 +293,60 @@ func (m *JobMetrics) ObserveDuration(d time.Duration) {
 	}
 }
 
+// JobCounts are the outcome totals since the process started.
+type JobCounts struct {
+	Claimed      uint64
+	Done         uint64
+	Failed       uint64
+	Retried      uint64
+	DeadLettered uint64
+	TimedOut     uint64
+}
+
+// Sub returns what was counted after earlier.
+func (c JobCounts) Sub(earlier JobCounts) JobCounts {
+	return JobCounts{
+		Claimed:      c.Claimed - earlier.Claimed,
+		Done:         c.Done - earlier.Done,
+		Failed:       c.Failed - earlier.Failed,
+		Retried:      c.Retried - earlier.Retried,
+		DeadLettered: c.DeadLettered - earlier.DeadLettered,
+		TimedOut:     c.TimedOut - earlier.TimedOut,
+	}
+}
+
+// counterReader is the part of a Prometheus counter that reads its total back.
+type counterReader interface {
+	Write(*dto.Metric) error
+}
+
+func readCounter(c counterReader) uint64 {
+	var m dto.Metric
+	if err := c.Write(&m); err != nil || m.Counter == nil {
+		return 0
+	}
+	return uint64(m.Counter.GetValue())
+}
+
+// Counts reads the outcome totals from the counters.
+func (m *JobMetrics) Counts() JobCounts {
+	return JobCounts{
+		Claimed:      readCounter(m.claimed),
+		Done:         readCounter(m.done),
+		Failed:       readCounter(m.failed),
+		Retried:      readCounter(m.retried),
+		DeadLettered: readCounter(m.deadLettered),
+		TimedOut:     readCounter(m.timedOut),
+	}
+}
+
 type JobMetricsSnapShot struct {
 	Claimed         uint64
 	Done            uint64
 	Failed          uint64
 	Retried         uint64
 	DeadLettered    uint64
+	TimedOut        uint64
 	DurationCount   uint64
 	AverageDuration time.Duration
 	MaxDuration     time.Duration
@@ -84,12 +363,14 @@ func (m *JobMetrics) Snapshot() JobMetricsSnapShot {
 		avg = time.Duration(float64(total) / float64(count))
 	}
 
+	c := m.Counts()
 	return JobMetricsSnapShot{
-		Claimed:         m.claimed.Load(),
-		Done:            m.done.Load(),
-		Failed:          m.failed.Load(),
-		Retried:         m.retried.Load(),
-		DeadLettered:    m.deadLettered.Load(),
+		Claimed:         c.Claimed,
+		Done:            c.Done,
+		Failed:          c.Failed,
+		Retried:         c.Retried,
+		DeadLettered:    c.DeadLettered,
+		TimedOut:        c.TimedOut,
 		DurationCount:   count,
 		AverageDuration: avg,
 		MaxDuration:     time.Duration(max),
@@ -0,0 +1,59 @@
+package observability
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LiveJobCounts holds the workers running in this process that persist daily
+// job stats, so GET /admin/jobs/stats can show their not-yet-flushed totals.
+// Like RecentJobErrors it is per instance: only cmd/all sees its own worker.
+var LiveJobCounts = NewLiveJobCountsRegistry()
+
+// LiveJobCount is one worker's totals for Day so far.
+type LiveJobCount struct {
+	WorkerID string
+	Day      time.Time
+	Counts   JobCounts
+}
+
+type LiveJobCountsRegistry struct {
+	mu      sync.Mutex
+	readers map[string]func() LiveJobCount
+}
+
+func NewLiveJobCountsRegistry() *LiveJobCountsRegistry {
+	return &LiveJobCountsRegistry{readers: make(map[string]func() LiveJobCount)}
+}
+
+// Register adds a worker's reader under workerID; call the returned func when
+// the worker stops.
+func (r *LiveJobCountsRegistry) Register(workerID string, read func() LiveJobCount) func() {
+	r.mu.Lock()
+	r.readers[workerID] = read
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.readers, workerID)
+		r.mu.Unlock()
+	}
+}
+
+// Snapshot reads every registered worker, ordered by worker id.
+func (r *LiveJobCountsRegistry) Snapshot() []LiveJobCount {
+	r.mu.Lock()
+	readers := make([]func() LiveJobCount, 0, len(r.readers))
+	for _, read := range r.readers {
+		readers = append(readers, read)
+	}
+	r.mu.Unlock()
+
+	out := make([]LiveJobCount, 0, len(readers))
+	for _, read := range readers {
+		out = append(out, read())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].WorkerID < out[j].WorkerID })
+	return out
+}
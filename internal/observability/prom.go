@@ -21,6 +21,30 @@ type Prom struct {
 	JobDuration  *prometheus.HistogramVec
 	JobResults   *prometheus.CounterVec
 	JobsInFlight prometheus.Gauge
+
+	JobEnqueueRejectedTotal *prometheus.CounterVec
+
+	// back-pressure guard decisions on API enqueues
+	JobEnqueueBackpressureTotal *prometheus.CounterVec
+
+	// registered_count verification
+	CounterDriftRowsTotal prometheus.Counter
+	CounterDriftMaxDelta  prometheus.Gauge
+
+	// GET /events pages served from an expired cache entry because the
+	// database could not be read
+	EventsCacheStaleServedTotal prometheus.Counter
+
+	// the notifier circuit breaker
+	NotifierCircuitState       prometheus.Gauge
+	NotifierCircuitTransitions *prometheus.CounterVec
+
+	// Auth; results only, never user identifiers
+	AuthLoginsTotal     *prometheus.CounterVec
+	AuthLoginDuration   *prometheus.HistogramVec
+	AuthRefreshesTotal  *prometheus.CounterVec
+	AuthTokenReuseTotal prometheus.Counter
+	AuthLockoutsTotal   prometheus.Counter
 }
 
 func NewProm(reg prometheus.Registerer) *Prom {
@@ -59,7 +83,7 @@ func NewProm(reg prometheus.Registerer) *Prom {
 				Help:      "DB operation latency (logical op, not raw SQL)",
 				Buckets:   []float64{0.005, 0.01, 0.02, 0.05, 0.1, 0.2, 0.35, 0.5, 1, 2, 5},
 			},
-			[]string{"op", "status"},
+			[]string{"op", "status"}, // status=ok|error|cancelled
 		),
 		DbErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -79,7 +103,7 @@ func NewProm(reg prometheus.Registerer) *Prom {
 				Help:      "Job execution duration by type and result",
 				Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60},
 			},
-			[]string{"job_type", "result"}, // result=done|retry|failed
+			[]string{"job_type", "result"}, // result=done|retry|dead_letter|cancelled|interrupted|failed
 		),
 		JobResults: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -88,7 +112,7 @@ func NewProm(reg prometheus.Registerer) *Prom {
 				Name:      "results_total",
 				Help:      "Job outcomes by type and result.",
 			},
-			[]string{"job_type", "result"}, // result=done|retry|failed
+			[]string{"job_type", "result"}, // result=done|retry|dead_letter|cancelled|failed
 		),
 		JobsInFlight: prometheus.NewGauge(
 			prometheus.GaugeOpts{
@@ -98,12 +122,168 @@ func NewProm(reg prometheus.Registerer) *Prom {
 				Help:      "Current number of executing jobs across workers(per process)",
 			},
 		),
+		JobEnqueueRejectedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "jobs",
+				Name:      "enqueue_rejected_total",
+				Help:      "Jobs refused at enqueue because their payload broke the size or nesting limit.",
+			},
+			[]string{"job_type", "reason"}, // reason=too_large|too_deep
+		),
+		JobEnqueueBackpressureTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "jobs",
+				Name:      "enqueue_backpressure_total",
+				Help:      "API enqueues judged by the back-pressure guard, by class and decision.",
+			},
+			[]string{"job_type", "class", "decision"}, // decision=allowed|rejected
+		),
+		CounterDriftRowsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "events",
+				Name:      "counter_drift_rows_total",
+				Help:      "Events whose registered_count had drifted and was repaired.",
+			},
+		),
+		CounterDriftMaxDelta: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "eventhub",
+				Subsystem: "events",
+				Name:      "counter_drift_max_delta",
+				Help:      "Largest absolute registered_count drift seen by the last verification run.",
+			},
+		),
+		EventsCacheStaleServedTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "events",
+				Name:      "cache_stale_served_total",
+				Help:      "Event list pages answered from an expired cache entry after the database read failed.",
+			},
+		),
+		NotifierCircuitState: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "eventhub",
+				Subsystem: "notifier",
+				Name:      "circuit_state",
+				Help:      "Notifier circuit breaker state: 0 closed, 1 open, 2 half-open.",
+			},
+		),
+		NotifierCircuitTransitions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "notifier",
+				Name:      "circuit_transitions_total",
+				Help:      "Notifier circuit breaker state changes.",
+			},
+			[]string{"from", "to"}, // closed|open|half_open
+		),
+		AuthLoginsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "auth",
+				Name:      "logins_total",
+				Help:      "Login attempts by result.",
+			},
+			[]string{"result"}, // result=success|invalid_credentials|error
+		),
+		AuthLoginDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "eventhub",
+				Subsystem: "auth",
+				Name:      "login_duration_seconds",
+				Help:      "Login latency by result, password hashing included.",
+				Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2, 5},
+			},
+			[]string{"result"},
+		),
+		AuthRefreshesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "auth",
+				Name:      "refreshes_total",
+				Help:      "Refresh token rotations by result.",
+			},
+			[]string{"result"}, // result=success|missing|invalid|expired|reused|error
+		),
+		AuthTokenReuseTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "auth",
+				Name:      "token_reuse_total",
+				Help:      "Refresh tokens presented again after they were rotated or revoked.",
+			},
+		),
+		AuthLockoutsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "eventhub",
+				Subsystem: "auth",
+				Name:      "lockouts_total",
+				Help:      "Login attempts turned away by the login rate limit.",
+			},
+		),
 	}
-	reg.MustRegister(p.RequestsTotal, p.RequestsDuration, p.InFlight, p.DbQueryDuration, p.DbErrorsTotal, p.JobDuration, p.JobResults, p.JobsInFlight)
+	reg.MustRegister(p.RequestsTotal, p.RequestsDuration, p.InFlight, p.DbQueryDuration, p.DbErrorsTotal, p.JobDuration, p.JobResults, p.JobsInFlight, p.JobEnqueueRejectedTotal, p.JobEnqueueBackpressureTotal, p.CounterDriftRowsTotal, p.CounterDriftMaxDelta,
+		p.EventsCacheStaleServedTotal, p.NotifierCircuitState, p.NotifierCircuitTransitions, p.AuthLoginsTotal, p.AuthLoginDuration, p.AuthRefreshesTotal, p.AuthTokenReuseTotal, p.AuthLockoutsTotal)
 
 	return p
 }
 
+// IncJobEnqueueRejected counts a payload refused at enqueue; nil-safe.
+func (p *Prom) IncJobEnqueueRejected(jobType, reason string) {
+	if p == nil {
+		return
+	}
+	p.JobEnqueueRejectedTotal.WithLabelValues(jobType, reason).Inc()
+}
+
+// IncJobEnqueueBackpressure counts one back-pressure guard decision; nil-safe.
+func (p *Prom) IncJobEnqueueBackpressure(jobType, class, decision string) {
+	if p == nil {
+		return
+	}
+	p.JobEnqueueBackpressureTotal.WithLabelValues(jobType, class, decision).Inc()
+}
+
+// ObserveJobResult records one finished job attempt of jobType, how long it
+// ran and how it ended; nil-safe.
+func (p *Prom) ObserveJobResult(jobType, result string, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.JobDuration.WithLabelValues(jobType, result).Observe(d.Seconds())
+	p.JobResults.WithLabelValues(jobType, result).Inc()
+}
+
+// AddJobsInFlight moves the executing jobs gauge by delta; nil-safe.
+func (p *Prom) AddJobsInFlight(delta float64) {
+	if p == nil {
+		return
+	}
+	p.JobsInFlight.Add(delta)
+}
+
+// IncEventsCacheStaleServed counts one stale event list served; nil-safe.
+func (p *Prom) IncEventsCacheStaleServed() {
+	if p == nil {
+		return
+	}
+	p.EventsCacheStaleServedTotal.Inc()
+}
+
+// ObserveNotifierCircuit records the breaker moving from one state to
+// another, value being the new state's gauge value; nil-safe.
+func (p *Prom) ObserveNotifierCircuit(from, to string, value float64) {
+	if p == nil {
+		return
+	}
+	p.NotifierCircuitState.Set(value)
+	p.NotifierCircuitTransitions.WithLabelValues(from, to).Inc()
+}
+
 func (p *Prom) GinHandleMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		start := time.Now()
@@ -0,0 +1,117 @@
+package observability
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RecentErrorsSize is how many errors each ring keeps.
+const RecentErrorsSize = 50
+
+// maxErrorText bounds the stored error text; the logs have the full story.
+const maxErrorText = 300
+
+// RecentHTTPErrors and RecentJobErrors hold this process's latest 5xx
+// responses and job failures for GET /admin/debug/recent-errors. They are
+// deliberately in-memory and per instance.
+var (
+	RecentHTTPErrors = NewErrorRing(RecentErrorsSize)
+	RecentJobErrors  = NewErrorRing(RecentErrorsSize)
+)
+
+// ErrorEvent is one recorded failure. HTTP errors fill Route/Status/Code/
+// RequestID, job failures fill JobType/JobID/Class.
+type ErrorEvent struct {
+	At        time.Time `json:"at"`
+	Route     string    `json:"route,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Code      string    `json:"code,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+	JobType   string    `json:"jobType,omitempty"`
+	JobID     string    `json:"jobId,omitempty"`
+	Class     string    `json:"class,omitempty"`
+	Error     string    `json:"error"`
+}
+
+// ErrorRing is a fixed-size, concurrency-safe ring of the latest errors.
+type ErrorRing struct {
+	mu    sync.Mutex
+	buf   []ErrorEvent
+	next  int
+	count int
+}
+
+func NewErrorRing(size int) *ErrorRing {
+	if size <= 0 {
+		size = RecentErrorsSize
+	}
+	return &ErrorRing{buf: make([]ErrorEvent, size)}
+}
+
+// Add records e, overwriting the oldest entry once full. The error text is
+// redacted and truncated before it is stored.
+func (r *ErrorRing) Add(e ErrorEvent) {
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	e.Error = truncate(RedactEmails(e.Error), maxErrorText)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.count < len(r.buf) {
+		r.count++
+	}
+}
+
+// Snapshot returns the recorded errors, newest first.
+func (r *ErrorRing) Snapshot() []ErrorEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]ErrorEvent, 0, r.count)
+	for i := 1; i <= r.count; i++ {
+		out = append(out, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+	return out
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// RedactEmails masks anything that looks like an email address.
+func RedactEmails(s string) string {
+	return emailPattern.ReplaceAllString(s, "[redacted-email]")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+var (
+	instanceOnce sync.Once
+	instanceID   string
+)
+
+// InstanceID names this process: hostname and pid.
+func InstanceID() string {
+	instanceOnce.Do(func() {
+		host, err := os.Hostname()
+		if err != nil || host == "" {
+			host = "unknown"
+		}
+		instanceID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	})
+	return instanceID
+}
@@ -0,0 +1,54 @@
+package observability
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestErrorRing_KeepsNewestFirst(t *testing.T) {
+	r := NewErrorRing(3)
+	for i := 1; i <= 5; i++ {
+		r.Add(ErrorEvent{JobID: strconv.Itoa(i), Error: "boom"})
+	}
+
+	got := r.Snapshot()
+	if len(got) != 3 || got[0].JobID != "5" || got[2].JobID != "3" {
+		t.Fatalf("expected jobs 5,4,3, got %+v", got)
+	}
+}
+
+func TestErrorRing_ConcurrentAdds(t *testing.T) {
+	r := NewErrorRing(RecentErrorsSize)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				r.Add(ErrorEvent{Error: "boom"})
+				_ = r.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(r.Snapshot()); got != RecentErrorsSize {
+		t.Fatalf("expected a full ring of %d, got %d", RecentErrorsSize, got)
+	}
+}
+
+func TestErrorRing_RedactsAndTruncates(t *testing.T) {
+	r := NewErrorRing(1)
+	r.Add(ErrorEvent{Error: "smtp rejected Ada.Lovelace+events@example.co.uk: " + strings.Repeat("x", 1000)})
+
+	got := r.Snapshot()[0].Error
+	if strings.Contains(got, "example.co.uk") || !strings.Contains(got, "[redacted-email]") {
+		t.Fatalf("expected the address redacted, got %q", got)
+	}
+	if len(got) > maxErrorText+len("…") {
+		t.Fatalf("expected the text truncated, got %d bytes", len(got))
+	}
+}
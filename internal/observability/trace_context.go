@@ -0,0 +1,37 @@
+package observability
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceContext is always W3C tracecontext, whatever the global propagator,
+// so what is stored with a job reads the same in every process.
+var traceContext = propagation.TraceContext{}
+
+// TraceParent returns the W3C traceparent and tracestate of the span in ctx,
+// for storing alongside work picked up later. Both are empty without a span.
+func TraceParent(ctx context.Context) (traceParent, traceState string) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return "", ""
+	}
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+	return carrier.Get("traceparent"), carrier.Get("tracestate")
+}
+
+// ContextWithTraceParent makes the span traceParent names the remote parent
+// of spans started from the returned context. A missing or malformed
+// traceParent leaves ctx as it is.
+func ContextWithTraceParent(ctx context.Context, traceParent, traceState string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{"traceparent": traceParent}
+	if traceState != "" {
+		carrier["tracestate"] = traceState
+	}
+	return traceContext.Extract(ctx, carrier)
+}
@@ -0,0 +1,265 @@
+package worker
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/exportlink"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+// AccountExportSources are the tables an account export reads. Every list
+// pages by (created_at, id) so no table is ever held in memory whole.
+type AccountExportSources struct {
+	Users interface {
+		GetByID(ctx context.Context, id string) (user.User, error)
+	}
+	Events interface {
+		ListByOrganizerCursor(ctx context.Context, organizerID string, limit int, afterCreatedAt time.Time, afterID string) ([]event.Event, error)
+	}
+	Registrations interface {
+		ListByOrganizerCursor(ctx context.Context, organizerID string, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, error)
+	}
+	Jobs interface {
+		ListByUserCursor(ctx context.Context, userID string, limit int, afterCreatedAt time.Time, afterID string) ([]job.Job, error)
+	}
+}
+
+type accountExporter struct {
+	sources AccountExportSources
+	store   exportstore.Store
+	links   *exportlink.Signer
+}
+
+// WithAccountExporter enables account.export: the user's data is zipped into
+// store and a signed download link is emailed through the notifier.
+func (w *Worker) WithAccountExporter(sources AccountExportSources, store exportstore.Store, links *exportlink.Signer) *Worker {
+	w.accountExport = &accountExporter{sources: sources, store: store, links: links}
+	return w.Register(jobs.TypeAccountExport, func(ctx context.Context, j job.Job) error {
+		var p jobs.AccountExportPayload
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+
+		return w.exportAccount(ctx, j.ID, p)
+	})
+}
+
+func (w *Worker) exportAccount(ctx context.Context, jobID string, p jobs.AccountExportPayload) error {
+	if w.accountExport == nil {
+		return fmt.Errorf("account export dependencies not configured")
+	}
+	notifier, ok := w.notifier.(notifications.AccountExportNotifier)
+	if !ok {
+		return jobs.NonRetryable(fmt.Errorf("notifier cannot send account export emails: %w", notifications.ErrUnsupported))
+	}
+	ex := w.accountExport
+
+	u, err := ex.sources.Users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return err
+	}
+
+	// one file per job: a retry overwrites its own earlier attempt
+	fileName := fmt.Sprintf("account_%s_%s.zip", p.UserID, jobID)
+
+	out, location, err := ex.store.Create(ctx, fileName)
+	if err != nil {
+		return err
+	}
+
+	progress := jobs.AccountExportResult{Stage: "profile"}
+	w.recordAccountExportProgress(ctx, jobID, progress)
+
+	zw := zip.NewWriter(out)
+	err = writeAccountExport(ctx, zw, ex.sources, u, exportPageSize, func(r jobs.AccountExportResult) {
+		w.recordAccountExportProgress(ctx, jobID, r)
+		progress = r
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	token, expiresAt := ex.links.Sign(jobID, u.ID)
+
+	progress.Stage = "done"
+	progress.FileName = fileName
+	progress.Location = location
+	progress.LinkExpiresAt = &expiresAt
+	w.recordAccountExportProgress(ctx, jobID, progress)
+
+	err = notifier.SendAccountExportReady(ctx, notifications.SendAccountExportReadyInput{
+		Email: u.Email,
+		Name:  u.Name,
+		JobID: jobID,
+		Counts: map[string]int{
+			"events":        progress.Events,
+			"registrations": progress.Registrations,
+			"jobs":          progress.Jobs,
+		},
+		DownloadToken: token,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, notifications.ErrCircuitOpen) {
+			return fmt.Errorf("notifier fail-fast: %w", err)
+		}
+		if errors.Is(err, notifications.ErrUnsupported) {
+			return jobs.NonRetryable(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// recordAccountExportProgress is best effort: a lost progress update never
+// fails the export itself.
+func (w *Worker) recordAccountExportProgress(ctx context.Context, jobID string, r jobs.AccountExportResult) {
+	rw, ok := w.repo.(JobResultWriter)
+	if !ok {
+		return
+	}
+
+	raw, err := json.Marshal(r)
+	if err == nil {
+		err = rw.SetResult(ctx, jobID, raw)
+	}
+	if err != nil {
+		log.Printf("account.export: store progress failed job=%s stage=%s err=%v", jobID, r.Stage, err)
+	}
+}
+
+// writeAccountExport writes profile.json, events.json, registrations.json and
+// jobs.json into zw, calling onProgress after each table.
+func writeAccountExport(
+	ctx context.Context,
+	zw *zip.Writer,
+	src AccountExportSources,
+	u user.User,
+	pageSize int,
+	onProgress func(jobs.AccountExportResult),
+) error {
+	var progress jobs.AccountExportResult
+
+	f, err := zw.Create("profile.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(u); err != nil {
+		return err
+	}
+
+	progress.Stage = "events"
+	onProgress(progress)
+	progress.Events, err = writeJSONArray(ctx, zw, "events.json", pageSize,
+		func(ctx context.Context, afterCreatedAt time.Time, afterID string) ([]event.Event, error) {
+			return src.Events.ListByOrganizerCursor(ctx, u.ID, pageSize, afterCreatedAt, afterID)
+		},
+		func(e event.Event) (time.Time, string) { return e.CreatedAt, e.ID },
+	)
+	if err != nil {
+		return fmt.Errorf("events: %w", err)
+	}
+
+	progress.Stage = "registrations"
+	onProgress(progress)
+	progress.Registrations, err = writeJSONArray(ctx, zw, "registrations.json", pageSize,
+		func(ctx context.Context, afterCreatedAt time.Time, afterID string) ([]registration.Registration, error) {
+			return src.Registrations.ListByOrganizerCursor(ctx, u.ID, pageSize, afterCreatedAt, afterID)
+		},
+		func(r registration.Registration) (time.Time, string) { return r.CreatedAt, r.ID },
+	)
+	if err != nil {
+		return fmt.Errorf("registrations: %w", err)
+	}
+
+	progress.Stage = "jobs"
+	onProgress(progress)
+	progress.Jobs, err = writeJSONArray(ctx, zw, "jobs.json", pageSize,
+		func(ctx context.Context, afterCreatedAt time.Time, afterID string) ([]job.Job, error) {
+			return src.Jobs.ListByUserCursor(ctx, u.ID, pageSize, afterCreatedAt, afterID)
+		},
+		func(j job.Job) (time.Time, string) { return j.CreatedAt, j.ID },
+	)
+	if err != nil {
+		return fmt.Errorf("jobs: %w", err)
+	}
+
+	onProgress(progress)
+	return nil
+}
+
+// writeJSONArray streams one zip entry as a JSON array, fetching a page at a
+// time after the last item's cursor key. It returns the number of items written.
+func writeJSONArray[T any](
+	ctx context.Context,
+	zw *zip.Writer,
+	name string,
+	pageSize int,
+	fetch func(ctx context.Context, afterCreatedAt time.Time, afterID string) ([]T, error),
+	key func(T) (time.Time, string),
+) (int, error) {
+	f, err := zw.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Write([]byte("[")); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	afterCreatedAt := time.Unix(0, 0).UTC()
+	afterID := "00000000-0000-0000-0000-000000000000"
+
+	for {
+		page, err := fetch(ctx, afterCreatedAt, afterID)
+		if err != nil {
+			return n, err
+		}
+
+		for _, item := range page {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return n, err
+			}
+			if n > 0 {
+				b = append([]byte(",\n"), b...)
+			} else {
+				b = append([]byte("\n"), b...)
+			}
+			if _, err := f.Write(b); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		if len(page) < pageSize {
+			break
+		}
+		afterCreatedAt, afterID = key(page[len(page)-1])
+	}
+
+	_, err = f.Write([]byte("\n]\n"))
+	return n, err
+}
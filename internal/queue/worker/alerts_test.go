@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/alerting"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+type sentAlert struct {
+	severity alerting.Severity
+	title    string
+	body     string
+	labels   map[string]string
+}
+
+type fakeAlerter struct {
+	mu   sync.Mutex
+	sent []sentAlert
+}
+
+func (f *fakeAlerter) Notify(ctx context.Context, severity alerting.Severity, title, body string, labels map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, sentAlert{severity, title, body, labels})
+	return nil
+}
+
+func TestHandleFailure_AlertsOnDeadLetterOnly(t *testing.T) {
+	alerter := &fakeAlerter{}
+	w := New(Config{}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).
+		WithAlerter(alerting.NewDeduper(alerter, time.Hour))
+
+	// a retry is not worth a ping
+	w.handleFailure(context.Background(), job.Job{ID: "job-1", Type: "email.send", Attempts: 0, MaxAttempts: 3}, errors.New("smtp down"))
+	if len(alerter.sent) != 0 {
+		t.Fatalf("expected no alert for a retry, got %+v", alerter.sent)
+	}
+
+	// a storm of dead-letters of one type collapses into one alert
+	for i := 0; i < 3; i++ {
+		w.handleFailure(context.Background(), job.Job{ID: "job-dead", Type: "email.send", Attempts: 2, MaxAttempts: 3}, errors.New("bad address bob@example.com"))
+	}
+	if len(alerter.sent) != 1 {
+		t.Fatalf("expected one deduplicated alert, got %d", len(alerter.sent))
+	}
+	got := alerter.sent[0]
+	if got.severity != alerting.SeverityCritical || got.labels["job_type"] != "email.send" || got.labels["error_class"] != "error" {
+		t.Fatalf("unexpected alert %+v", got)
+	}
+	if strings.Contains(got.body, "bob@example.com") {
+		t.Fatalf("expected emails redacted from the alert, got %q", got.body)
+	}
+}
+
+func TestRequeueStale_AlertsAtThreshold(t *testing.T) {
+	alerter := &fakeAlerter{}
+	var res job.StaleRequeue
+	repo := &fakeJobsRepo{
+		requeueStaleProcessingFn: func(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error) {
+			return res, nil
+		},
+	}
+	w := New(Config{WorkerID: "w-1", StaleRequeueAlertThreshold: 5}, repo, &fakeEventsRepo{}, nil, nil).
+		WithAlerter(alerter)
+
+	res = job.StaleRequeue{Requeued: 4}
+	w.requeueStale(context.Background())
+	if len(alerter.sent) != 0 {
+		t.Fatalf("expected no alert under the threshold, got %+v", alerter.sent)
+	}
+
+	// dead-lettered jobs lost their lock too
+	res = job.StaleRequeue{Requeued: 3, DeadLettered: 2}
+	w.requeueStale(context.Background())
+	if len(alerter.sent) != 1 || alerter.sent[0].labels["worker_id"] != "w-1" {
+		t.Fatalf("expected one stale requeue alert, got %+v", alerter.sent)
+	}
+}
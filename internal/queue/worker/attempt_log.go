@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JobAttemptStore persists execution history in batches.
+type JobAttemptStore interface {
+	InsertBatch(ctx context.Context, attempts []job.Attempt) error
+}
+
+// JobAttemptReader is implemented by attempt stores that can read a job's
+// history back. With it a retry's job.run span links to the spans of the
+// attempts before it, not only those still buffered in this worker.
+type JobAttemptReader interface {
+	ListByJob(ctx context.Context, jobID string) ([]job.Attempt, error)
+}
+
+// attemptLinksTimeout bounds the history read made before a retry starts.
+const attemptLinksTimeout = time.Second
+
+// attemptLogMaxPending bounds what a worker holds while the store is down;
+// past it new attempts are dropped and counted.
+const attemptLogMaxPending = 5000
+
+type attemptLog struct {
+	store JobAttemptStore
+	every time.Duration
+
+	mu      sync.Mutex
+	pending []job.Attempt
+	dropped int
+}
+
+// WithAttemptLog records every execution in store. Executors only append to
+// a buffer; a background loop writes it every `every` and once more on
+// shutdown, so the history costs the hot path no round trip. It is best
+// effort: a failed write is logged and its batch dropped.
+func (w *Worker) WithAttemptLog(store JobAttemptStore, every time.Duration) *Worker {
+	if every <= 0 {
+		every = 2 * time.Second
+	}
+	w.attempts = &attemptLog{store: store, every: every}
+	return w
+}
+
+// recordAttempt buffers one execution of j, with the span ctx carries. Emails
+// are masked in the error: the history is for debugging and is not covered by
+// erasure.
+func (w *Worker) recordAttempt(ctx context.Context, j job.Job, startedAt time.Time, d time.Duration, outcome job.AttemptOutcome, execErr error) {
+	if w.attempts == nil {
+		return
+	}
+
+	a := job.Attempt{
+		JobID:      j.ID,
+		Attempt:    j.Attempts + 1,
+		WorkerID:   w.cfg.WorkerID,
+		StartedAt:  startedAt.UTC(),
+		FinishedAt: startedAt.Add(d).UTC(),
+		Outcome:    outcome,
+		DurationMs: d.Milliseconds(),
+	}
+	if execErr != nil {
+		msg := observability.RedactEmails(execErr.Error())
+		a.Error = &msg
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		a.TraceID, a.SpanID = sc.TraceID().String(), sc.SpanID().String()
+	}
+
+	al := w.attempts
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if len(al.pending) >= attemptLogMaxPending {
+		al.dropped++
+		return
+	}
+	al.pending = append(al.pending, a)
+}
+
+// previousAttemptLinks links to the spans of j's earlier attempts: those in
+// the store and those this worker has not flushed yet. A failed read only
+// costs the links, so it is logged and the buffered ones are still used.
+func (w *Worker) previousAttemptLinks(ctx context.Context, j job.Job) []trace.Link {
+	if w.attempts == nil || j.Attempts == 0 {
+		return nil
+	}
+
+	var history []job.Attempt
+	if r, ok := w.attempts.store.(JobAttemptReader); ok {
+		rctx, cancel := context.WithTimeout(ctx, attemptLinksTimeout)
+		stored, err := r.ListByJob(rctx, j.ID)
+		cancel()
+		if err != nil {
+			log.Printf("worker.attempt_log history read failed job=%s err=%v", j.ID, err)
+		}
+		history = stored
+	}
+
+	al := w.attempts
+	al.mu.Lock()
+	for _, a := range al.pending {
+		if a.JobID == j.ID {
+			history = append(history, a)
+		}
+	}
+	al.mu.Unlock()
+
+	links := make([]trace.Link, 0, len(history))
+	seen := make(map[trace.SpanID]bool, len(history))
+	for _, a := range history {
+		sc, ok := attemptSpanContext(a)
+		if !ok || seen[sc.SpanID()] {
+			continue
+		}
+		seen[sc.SpanID()] = true
+		links = append(links, trace.Link{
+			SpanContext: sc,
+			Attributes:  []attribute.KeyValue{attribute.Int("job.attempt", a.Attempt)},
+		})
+	}
+	return links
+}
+
+func attemptSpanContext(a job.Attempt) (trace.SpanContext, bool) {
+	traceID, err := trace.TraceIDFromHex(a.TraceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(a.SpanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}), true
+}
+
+// flushAttempts writes what is buffered.
+func (w *Worker) flushAttempts(ctx context.Context) error {
+	al := w.attempts
+	al.mu.Lock()
+	batch, dropped := al.pending, al.dropped
+	al.pending, al.dropped = nil, 0
+	al.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("worker.attempt_log buffer full; dropped=%d", dropped)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return al.store.InsertBatch(ctx, batch)
+}
+
+func (w *Worker) attemptLogLoop(ctx context.Context) {
+	t := time.NewTicker(w.attempts.every)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			fctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+			if err := w.flushAttempts(fctx); err != nil {
+				log.Printf("worker.attempt_log flush failed err=%v", err)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+type fakeAttemptStore struct {
+	batches [][]job.Attempt
+}
+
+func (s *fakeAttemptStore) InsertBatch(ctx context.Context, attempts []job.Attempt) error {
+	s.batches = append(s.batches, attempts)
+	return nil
+}
+
+func TestRunWorker_RecordsEveryAttempt(t *testing.T) {
+	store := &fakeAttemptStore{}
+	w := New(Config{WorkerID: "w-1"}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).
+		WithAttemptLog(store, 0)
+	w.Register("test.flaky", func(ctx context.Context, j job.Job) error {
+		if j.Attempts == 0 {
+			return errors.New("mailbox ann@example.com is full")
+		}
+		return nil
+	})
+
+	jobsCh := make(chan job.Job, 3)
+	jobsCh <- job.Job{ID: "job-1", Type: "test.flaky", Attempts: 0, MaxAttempts: 3}
+	jobsCh <- job.Job{ID: "job-1", Type: "test.flaky", Attempts: 1, MaxAttempts: 3}
+	jobsCh <- job.Job{ID: "job-2", Type: "test.missing", Attempts: 0, MaxAttempts: 3}
+	close(jobsCh)
+	w.runWorker(context.Background(), 1, jobsCh)
+
+	if len(store.batches) != 0 {
+		t.Fatalf("expected executions to only buffer, got %d writes", len(store.batches))
+	}
+	if err := w.flushAttempts(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(store.batches) != 1 || len(store.batches[0]) != 3 {
+		t.Fatalf("expected one batch of 3 attempts, got %+v", store.batches)
+	}
+
+	got := store.batches[0]
+	want := []struct {
+		jobID   string
+		attempt int
+		outcome job.AttemptOutcome
+	}{
+		{"job-1", 1, job.AttemptRetried},
+		{"job-1", 2, job.AttemptDone},
+		{"job-2", 1, job.AttemptDeadLettered},
+	}
+	for i, w := range want {
+		a := got[i]
+		if a.JobID != w.jobID || a.Attempt != w.attempt || a.Outcome != w.outcome || a.WorkerID != "w-1" {
+			t.Fatalf("attempt %d: got %+v, want %+v", i, a, w)
+		}
+		if a.FinishedAt.Before(a.StartedAt) {
+			t.Fatalf("attempt %d finished before it started: %+v", i, a)
+		}
+	}
+	if got[0].Error == nil || strings.Contains(*got[0].Error, "ann@example.com") {
+		t.Fatalf("expected a redacted error on the retried attempt, got %v", got[0].Error)
+	}
+	if got[1].Error != nil {
+		t.Fatalf("expected no error on the done attempt, got %q", *got[1].Error)
+	}
+
+	if err := w.flushAttempts(context.Background()); err != nil || len(store.batches) != 1 {
+		t.Fatalf("expected an empty buffer not to write, got err=%v batches=%d", err, len(store.batches))
+	}
+}
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+// AttendanceSources look up events and finalize their attendance.
+type AttendanceSources struct {
+	Events interface {
+		GetByID(ctx context.Context, id string) (event.Event, error)
+	}
+	Attendance interface {
+		FinalizeEvent(ctx context.Context, eventID string) (event.AttendanceStats, error)
+	}
+}
+
+type attendanceFinalizer struct {
+	sources AttendanceSources
+	delay   time.Duration
+}
+
+// WithAttendanceFinalization enables event.finalize_attendance: publishing an
+// event schedules it for delay after the event starts, when confirmed
+// attendees who never checked in are marked no-shows.
+func (w *Worker) WithAttendanceFinalization(sources AttendanceSources, delay time.Duration, enq JobsEnqueuer) *Worker {
+	w.attendance = &attendanceFinalizer{sources: sources, delay: delay}
+	w.enqueuer = enq
+	return w.Register(jobs.TypeEventFinalizeAttendance, w.finalizeAttendance)
+}
+
+// scheduleAttendanceFinalization runs on every event.publish; the key carries
+// the start time, so republishing a moved event schedules the new slot.
+func (w *Worker) scheduleAttendanceFinalization(ctx context.Context, eventID string) error {
+	if w.attendance == nil || w.enqueuer == nil {
+		return nil
+	}
+
+	e, err := w.attendance.sources.Events.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("schedule attendance finalization: %w", err)
+	}
+
+	return w.enqueueAttendanceFinalization(ctx, e)
+}
+
+func (w *Worker) enqueueAttendanceFinalization(ctx context.Context, e event.Event) error {
+	raw, err := jobs.EventFinalizeAttendancePayload{EventID: e.ID, StartAt: e.StartAt}.JSON()
+	if err != nil {
+		return err
+	}
+
+	key := jobs.FinalizeAttendanceKey(e.ID, e.StartAt)
+	_, err = w.enqueuer.Create(ctx, job.CreateRequest{
+		Type:           jobs.TypeEventFinalizeAttendance,
+		Payload:        raw,
+		RunAt:          e.StartAt.Add(w.attendance.delay),
+		MaxAttempts:    5,
+		IdempotencyKey: &key,
+	})
+	if err != nil && !postgres.IsUniqueViolation(err) {
+		return fmt.Errorf("schedule attendance finalization: %w", err)
+	}
+	return nil
+}
+
+func (w *Worker) finalizeAttendance(ctx context.Context, j job.Job) error {
+	if w.attendance == nil {
+		return fmt.Errorf("attendance finalization not configured")
+	}
+	af := w.attendance
+
+	var p jobs.EventFinalizeAttendancePayload
+	if err := json.Unmarshal(j.Payload, &p); err != nil {
+		return invalidPayload(err)
+	}
+
+	e, err := af.sources.Events.GetByID(ctx, p.EventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// the event moved since this job was scheduled: hand over to a job for
+	// the new start time
+	if !e.StartAt.Equal(p.StartAt) {
+		if w.enqueuer == nil {
+			return nil
+		}
+		return w.enqueueAttendanceFinalization(ctx, e)
+	}
+
+	stats, err := af.sources.Attendance.FinalizeEvent(ctx, p.EventID)
+	if err != nil {
+		switch {
+		case errors.Is(err, event.ErrCheckInNotUsed):
+			log.Printf("attendance: skipped event=%s reason=check_in_not_used job=%s", p.EventID, j.ID)
+			return nil
+		case errors.Is(err, event.ErrNotFound):
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("attendance: finalized event=%s registrations=%d checked_in=%d no_shows=%d job=%s",
+		p.EventID, stats.Registrations, stats.CheckedIn, stats.NoShows, j.ID)
+
+	if rw, ok := w.repo.(JobResultWriter); ok {
+		raw, err := json.Marshal(stats)
+		if err == nil {
+			err = rw.SetResult(ctx, j.ID, raw)
+		}
+		if err != nil {
+			log.Printf("attendance: store result failed job=%s err=%v", j.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type fakeAttendance struct {
+	stats     event.AttendanceStats
+	err       error
+	finalized []string
+}
+
+func (f *fakeAttendance) FinalizeEvent(ctx context.Context, eventID string) (event.AttendanceStats, error) {
+	f.finalized = append(f.finalized, eventID)
+	return f.stats, f.err
+}
+
+func finalizeJob(t *testing.T, startAt time.Time) job.Job {
+	t.Helper()
+
+	raw, err := jobs.EventFinalizeAttendancePayload{EventID: "evt-1", StartAt: startAt}.JSON()
+	if err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	return job.Job{ID: "job-finalize", Type: jobs.TypeEventFinalizeAttendance, Payload: raw}
+}
+
+func TestFinalizeAttendance_StoresStats(t *testing.T) {
+	startAt := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Second)
+	attendance := &fakeAttendance{stats: event.AttendanceStats{EventID: "evt-1", Registrations: 4, CheckedIn: 3, NoShows: 1, NoShowRate: 0.25}}
+	repo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+
+	w := &Worker{repo: repo}
+	w.WithAttendanceFinalization(AttendanceSources{Events: fakeReminderEvents{startAt: startAt}, Attendance: attendance}, 12*time.Hour, repo)
+
+	if err := w.execute(context.Background(), finalizeJob(t, startAt)); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var got event.AttendanceStats
+	if err := json.Unmarshal(repo.results["job-finalize"], &got); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if got.NoShows != 1 || got.CheckedIn != 3 {
+		t.Fatalf("unexpected stored stats: %+v", got)
+	}
+}
+
+func TestFinalizeAttendance_SkipsWhenCheckInUnused(t *testing.T) {
+	startAt := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Second)
+	attendance := &fakeAttendance{err: event.ErrCheckInNotUsed}
+	repo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+
+	w := &Worker{repo: repo}
+	w.WithAttendanceFinalization(AttendanceSources{Events: fakeReminderEvents{startAt: startAt}, Attendance: attendance}, 12*time.Hour, repo)
+
+	if err := w.execute(context.Background(), finalizeJob(t, startAt)); err != nil {
+		t.Fatalf("a skipped event should not fail the job: %v", err)
+	}
+	if len(attendance.finalized) != 1 {
+		t.Fatalf("expected one finalization attempt, got %v", attendance.finalized)
+	}
+	if _, ok := repo.results["job-finalize"]; ok {
+		t.Fatalf("no stats expected for a skipped event")
+	}
+}
+
+func TestFinalizeAttendance_MovedEventReschedules(t *testing.T) {
+	scheduledFor := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Second)
+	movedTo := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	attendance := &fakeAttendance{}
+	repo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+
+	w := &Worker{repo: repo}
+	w.WithAttendanceFinalization(AttendanceSources{Events: fakeReminderEvents{startAt: movedTo}, Attendance: attendance}, 12*time.Hour, repo)
+
+	if err := w.execute(context.Background(), finalizeJob(t, scheduledFor)); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(attendance.finalized) != 0 {
+		t.Fatalf("a moved event must not be finalized early")
+	}
+	if len(repo.created) != 1 {
+		t.Fatalf("expected a job for the new start time, got %+v", repo.created)
+	}
+	next := repo.created[0]
+	if !next.RunAt.Equal(movedTo.Add(12*time.Hour)) || *next.IdempotencyKey != jobs.FinalizeAttendanceKey("evt-1", movedTo) {
+		t.Fatalf("unexpected rescheduled job: runAt=%s key=%s", next.RunAt, *next.IdempotencyKey)
+	}
+}
+
+func TestPublish_SchedulesAttendanceFinalization(t *testing.T) {
+	startAt := time.Now().UTC().Add(72 * time.Hour).Truncate(time.Second)
+	repo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+
+	w := &Worker{repo: repo, events: &fakeEventsRepo{}}
+	w.WithAttendanceFinalization(AttendanceSources{Events: fakeReminderEvents{startAt: startAt}, Attendance: &fakeAttendance{}}, 6*time.Hour, repo)
+	w.Register(jobs.TypeEventPublish, w.HandleEventPublish)
+
+	j := job.Job{ID: "job-publish", Type: "event.publish", Payload: json.RawMessage(`{"eventId":"evt-1"}`)}
+	if err := w.execute(context.Background(), j); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if len(repo.created) != 1 || repo.created[0].Type != jobs.TypeEventFinalizeAttendance {
+		t.Fatalf("expected a finalization job, got %+v", repo.created)
+	}
+	if !repo.created[0].RunAt.Equal(startAt.Add(6 * time.Hour)) {
+		t.Fatalf("expected runAt %s, got %s", startAt.Add(6*time.Hour), repo.created[0].RunAt)
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+// CapacityAlertSources look up who to alert and what to tell them.
+type CapacityAlertSources struct {
+	Users interface {
+		GetByID(ctx context.Context, id string) (user.User, error)
+	}
+	Events interface {
+		GetByID(ctx context.Context, id string) (event.Event, error)
+	}
+}
+
+// KeyedDeliveryGate is the send-once gate for deliveries deduplicated on a
+// key rather than a registration.
+type KeyedDeliveryGate interface {
+	TryStartKeyed(ctx context.Context, kind, dedupeKey, jobID, recipient string) error
+	MarkKeyedSent(ctx context.Context, kind, dedupeKey string, providerMessageID *string) error
+	MarkKeyedFailed(ctx context.Context, kind, dedupeKey, errMsg string) error
+}
+
+type capacityAlerter struct {
+	sources CapacityAlertSources
+	gate    KeyedDeliveryGate
+}
+
+// WithCapacityAlerts enables organizer.capacity_alert: the event's organizer
+// is emailed once per threshold, gated by (kind, event and threshold).
+func (w *Worker) WithCapacityAlerts(sources CapacityAlertSources, gate KeyedDeliveryGate) *Worker {
+	w.capacityAlerts = &capacityAlerter{sources: sources, gate: gate}
+	return w.Register(jobs.TypeOrganizerCapacityAlert, func(ctx context.Context, j job.Job) error {
+		var p jobs.OrganizerCapacityAlertPayload
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+
+		return w.sendCapacityAlert(ctx, j.ID, p)
+	})
+}
+
+func (w *Worker) sendCapacityAlert(ctx context.Context, jobID string, p jobs.OrganizerCapacityAlertPayload) error {
+	if w.capacityAlerts == nil {
+		return fmt.Errorf("capacity alert dependencies not configured")
+	}
+	notifier, ok := w.notifier.(notifications.CapacityAlertNotifier)
+	if !ok {
+		return jobs.NonRetryable(fmt.Errorf("notifier cannot send capacity alerts: %w", notifications.ErrUnsupported))
+	}
+	ca := w.capacityAlerts
+
+	e, err := ca.sources.Events.GetByID(ctx, p.EventID)
+	if err != nil {
+		// the event was deleted after the threshold was crossed
+		if errors.Is(err, event.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	organizer, err := ca.sources.Users.GetByID(ctx, p.OrganizerID)
+	if err != nil {
+		return err
+	}
+
+	key := jobs.CapacityAlertKey(p.EventID, p.Threshold)
+	err = ca.gate.TryStartKeyed(ctx, jobs.TypeOrganizerCapacityAlert, key, jobID, organizer.Email)
+	if err != nil {
+		if errors.Is(err, notificationsdelivery.ErrAlreadySent) {
+			return nil
+		}
+		if errors.Is(err, notificationsdelivery.ErrInProgress) {
+			return fmt.Errorf("capacity alert send in progress")
+		}
+		return err
+	}
+
+	err = notifier.SendCapacityAlert(ctx, notifications.SendCapacityAlertInput{
+		Email:      organizer.Email,
+		Name:       organizer.Name,
+		EventID:    e.ID,
+		EventTitle: e.Title,
+		Threshold:  p.Threshold,
+		Capacity:   p.Capacity,
+		Registered: p.Registered,
+	})
+	if err != nil {
+		_ = ca.gate.MarkKeyedFailed(ctx, jobs.TypeOrganizerCapacityAlert, key, err.Error())
+
+		if errors.Is(err, notifications.ErrCircuitOpen) {
+			return fmt.Errorf("notifier fail-fast: %w", err)
+		}
+		if errors.Is(err, notifications.ErrUnsupported) {
+			return jobs.NonRetryable(err)
+		}
+		return err
+	}
+
+	if err := ca.gate.MarkKeyedSent(ctx, jobs.TypeOrganizerCapacityAlert, key, nil); err != nil {
+		log.Printf("deliveries: mark sent failed key=%s job=%s err=%v", key, jobID, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+type fakeAlertSources struct{}
+
+func (fakeAlertSources) GetByID(ctx context.Context, id string) (user.User, error) {
+	return user.User{ID: id, Email: "organizer@example.com", Name: "Olu"}, nil
+}
+
+type fakeAlertEvents struct{ err error }
+
+func (f fakeAlertEvents) GetByID(ctx context.Context, id string) (event.Event, error) {
+	if f.err != nil {
+		return event.Event{}, f.err
+	}
+	return event.Event{ID: id, Title: "Go Meetup"}, nil
+}
+
+// fakeKeyedGate remembers which keys were sent, like the unique index would.
+type fakeKeyedGate struct {
+	sent   map[string]bool
+	failed map[string]string
+}
+
+func (g *fakeKeyedGate) TryStartKeyed(ctx context.Context, kind, dedupeKey, jobID, recipient string) error {
+	if g.sent[kind+"|"+dedupeKey] {
+		return notificationsdelivery.ErrAlreadySent
+	}
+	return nil
+}
+
+func (g *fakeKeyedGate) MarkKeyedSent(ctx context.Context, kind, dedupeKey string, providerMessageID *string) error {
+	g.sent[kind+"|"+dedupeKey] = true
+	return nil
+}
+
+func (g *fakeKeyedGate) MarkKeyedFailed(ctx context.Context, kind, dedupeKey, errMsg string) error {
+	g.failed[kind+"|"+dedupeKey] = errMsg
+	return nil
+}
+
+type fakeAlertNotifier struct {
+	sent []notifications.SendCapacityAlertInput
+	err  error
+}
+
+func (n *fakeAlertNotifier) SendRegistrationConfirmation(ctx context.Context, in notifications.SendRegistrationConfirmationInput) error {
+	return nil
+}
+
+func (n *fakeAlertNotifier) SendEventPublished(ctx context.Context, in notifications.SendEventPublishedInput) error {
+	return nil
+}
+
+func (n *fakeAlertNotifier) SendCapacityAlert(ctx context.Context, in notifications.SendCapacityAlertInput) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, in)
+	return nil
+}
+
+func capacityAlertJob(t *testing.T, id string, threshold int) job.Job {
+	t.Helper()
+
+	raw, err := jobs.OrganizerCapacityAlertPayload{
+		EventID:     "evt-1",
+		OrganizerID: "org-1",
+		Threshold:   threshold,
+		Capacity:    10,
+		Registered:  threshold / 10,
+	}.JSON()
+	if err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	return job.Job{ID: id, Type: jobs.TypeOrganizerCapacityAlert, Payload: raw}
+}
+
+func TestExecuteCapacityAlert_SendsOncePerThreshold(t *testing.T) {
+	notifier := &fakeAlertNotifier{}
+	gate := &fakeKeyedGate{sent: map[string]bool{}, failed: map[string]string{}}
+
+	w := &Worker{notifier: notifier}
+	w.WithCapacityAlerts(CapacityAlertSources{Users: fakeAlertSources{}, Events: fakeAlertEvents{}}, gate)
+
+	// a retried 90% job must not alert twice
+	for _, j := range []job.Job{capacityAlertJob(t, "job-90", 90), capacityAlertJob(t, "job-90-retry", 90), capacityAlertJob(t, "job-100", 100)} {
+		if err := w.execute(context.Background(), j); err != nil {
+			t.Fatalf("execute %s: %v", j.ID, err)
+		}
+	}
+
+	if len(notifier.sent) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(notifier.sent))
+	}
+	first := notifier.sent[0]
+	if first.Email != "organizer@example.com" || first.EventTitle != "Go Meetup" || first.Threshold != 90 {
+		t.Fatalf("unexpected alert: %+v", first)
+	}
+	if notifier.sent[1].Threshold != 100 {
+		t.Fatalf("expected second alert for 100%%, got %+v", notifier.sent[1])
+	}
+	if !gate.sent[jobs.TypeOrganizerCapacityAlert+"|"+jobs.CapacityAlertKey("evt-1", 100)] {
+		t.Fatalf("expected 100%% delivery to be marked sent: %+v", gate.sent)
+	}
+}
+
+func TestExecuteCapacityAlert_SendFailureMarksDeliveryFailed(t *testing.T) {
+	notifier := &fakeAlertNotifier{err: errors.New("smtp down")}
+	gate := &fakeKeyedGate{sent: map[string]bool{}, failed: map[string]string{}}
+
+	w := &Worker{notifier: notifier}
+	w.WithCapacityAlerts(CapacityAlertSources{Users: fakeAlertSources{}, Events: fakeAlertEvents{}}, gate)
+
+	if err := w.execute(context.Background(), capacityAlertJob(t, "job-90", 90)); err == nil {
+		t.Fatalf("expected send error to fail the job")
+	}
+	if got := gate.failed[jobs.TypeOrganizerCapacityAlert+"|"+jobs.CapacityAlertKey("evt-1", 90)]; got != "smtp down" {
+		t.Fatalf("expected delivery marked failed, got %q", got)
+	}
+}
+
+func TestExecuteCapacityAlert_DeletedEventIsNoop(t *testing.T) {
+	notifier := &fakeAlertNotifier{}
+	gate := &fakeKeyedGate{sent: map[string]bool{}, failed: map[string]string{}}
+
+	w := &Worker{notifier: notifier}
+	w.WithCapacityAlerts(CapacityAlertSources{Users: fakeAlertSources{}, Events: fakeAlertEvents{err: event.ErrNotFound}}, gate)
+
+	if err := w.execute(context.Background(), capacityAlertJob(t, "job-90", 90)); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Fatalf("expected no alert for a deleted event")
+	}
+}
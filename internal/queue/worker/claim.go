@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// BatchClaimer is implemented by job repos that can claim several jobs in one
+// round trip; without it the worker claims one job per query.
+type BatchClaimer interface {
+	ClaimBatch(ctx context.Context, workerID string, n int) ([]job.Job, error)
+}
+
+// claimUpTo claims at most n ready jobs, leaving out paused types and types
+// at their concurrency limit when the repo can. A partial batch comes back
+// with the error that cut it short; job.ErrJobNotFound only ends the batch.
+func (w *Worker) claimUpTo(ctx context.Context, n int) ([]job.Job, error) {
+	if excluded := w.excludedTypes(); len(excluded) > 0 {
+		if ec, ok := w.repo.(TypeExcludingClaimer); ok {
+			claimCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			return ec.ClaimBatchExcluding(claimCtx, w.cfg.WorkerID, n, excluded)
+		}
+	}
+	if bc, ok := w.repo.(BatchClaimer); ok {
+		claimCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		return bc.ClaimBatch(claimCtx, w.cfg.WorkerID, n)
+	}
+
+	batch := make([]job.Job, 0, n)
+	for len(batch) < n {
+		claimCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		j, err := w.repo.ClaimNext(claimCtx, w.cfg.WorkerID)
+		cancel()
+
+		if err != nil {
+			if errors.Is(err, job.ErrJobNotFound) {
+				return batch, nil
+			}
+			return batch, err
+		}
+		batch = append(batch, j)
+	}
+	return batch, nil
+}
+
+// excludedTypes are the types not to claim now: paused ones and those at
+// their concurrency limit, sorted and without repeats.
+func (w *Worker) excludedTypes() []string {
+	paused := w.pausedTypes()
+	full := w.slots.full()
+	if len(paused) == 0 {
+		return full
+	}
+
+	out := append(slices.Clone(paused), full...)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
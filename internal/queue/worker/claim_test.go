@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+type batchJobsRepo struct {
+	fakeJobsRepo
+	mu      sync.Mutex
+	pending []job.Job
+	asked   []int
+}
+
+func (r *batchJobsRepo) ClaimBatch(ctx context.Context, workerID string, n int) ([]job.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.asked = append(r.asked, n)
+	n = min(n, len(r.pending))
+	batch := r.pending[:n]
+	r.pending = r.pending[n:]
+	return batch, nil
+}
+
+func TestProcessLoop_ClaimsOneBatchPerTick(t *testing.T) {
+	repo := &batchJobsRepo{}
+	repo.claimNextFn = func(ctx context.Context, workerID string) (job.Job, error) {
+		t.Errorf("ClaimNext must not be used when the repo can claim in batches")
+		return job.Job{}, job.ErrJobNotFound
+	}
+	for _, id := range []string{"job-1", "job-2", "job-3"} {
+		repo.pending = append(repo.pending, job.Job{ID: id, Type: "crm.sync", MaxAttempts: 3})
+	}
+
+	release := make(chan struct{})
+	var ran sync.WaitGroup
+	ran.Add(3)
+	w := New(Config{PollInterval: 20 * time.Millisecond, Concurrency: 3}, repo, &fakeEventsRepo{}, nil, nil).
+		Register("crm.sync", func(ctx context.Context, j job.Job) error {
+			ran.Done()
+			<-release
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	ran.Wait()
+	asked := func() []int {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return append([]int(nil), repo.asked...)
+	}
+	before := asked()
+	// every executor is busy: further ticks must not claim anything
+	time.Sleep(100 * time.Millisecond)
+	after := asked()
+
+	close(release)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(before) == 0 || before[0] != 3 {
+		t.Fatalf("expected the first claim to cover all 3 executors, got %v", before)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("claimed while every executor was busy: %v", after)
+	}
+}
@@ -0,0 +1,30 @@
+package worker
+
+import "time"
+
+// clock is the worker's source of time: shutdown timers and the start stamp
+// queue waits are measured to. Tests swap it out so the readiness window and
+// grace period can be driven without real sleeps.
+type clock interface {
+	After(d time.Duration) <-chan time.Time
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// now reads the worker's clock; workers built as bare structs in tests have
+// none.
+func (w *Worker) now() time.Time {
+	if w.clock == nil {
+		return time.Now()
+	}
+	return w.clock.Now()
+}
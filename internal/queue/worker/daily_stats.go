@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+)
+
+// JobStatsStore persists a worker's job totals per UTC day.
+type JobStatsStore interface {
+	UpsertDaily(ctx context.Context, s job.DailyStats) error
+}
+
+type dailyStats struct {
+	store JobStatsStore
+	every time.Duration
+	now   func() time.Time
+
+	// runID keys this run's rows: a restarted container often comes back
+	// with the same hostname and pid, and must not overwrite its old row
+	runID string
+
+	mu   sync.Mutex
+	day  time.Time
+	base observability.JobCounts // the counters when day began
+}
+
+// WithDailyStats writes this run's totals for the current UTC day every
+// `every` and once more on shutdown, one row per run, so a restart keeps the
+// day's numbers. A day is closed by the first flush after midnight, so jobs
+// finished between midnight and that flush count towards the old day.
+func (w *Worker) WithDailyStats(store JobStatsStore, every time.Duration) *Worker {
+	if every <= 0 {
+		every = time.Minute
+	}
+	w.dailyStats = &dailyStats{store: store, every: every, now: time.Now}
+	return w
+}
+
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (ds *dailyStats) begin(workerID string, counts observability.JobCounts) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := ds.now()
+	ds.runID = workerID + "@" + now.UTC().Format(time.RFC3339)
+	ds.day, ds.base = utcDay(now), counts
+}
+
+func (ds *dailyStats) current() (time.Time, observability.JobCounts) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.day, ds.base
+}
+
+// liveDailyStats reports the unflushed totals for observability.LiveJobCounts.
+func (w *Worker) liveDailyStats() observability.LiveJobCount {
+	day, base := w.dailyStats.current()
+	return observability.LiveJobCount{WorkerID: w.dailyStats.runID, Day: day, Counts: w.metrics.Counts().Sub(base)}
+}
+
+// flushDailyStats writes the day's totals so far. After midnight the old day
+// gets its final write before counting starts over.
+func (w *Worker) flushDailyStats(ctx context.Context) error {
+	ds := w.dailyStats
+	counts := w.metrics.Counts()
+	today := utcDay(ds.now())
+	day, base := ds.current()
+
+	if err := ds.store.UpsertDaily(ctx, dailyStatsRow(ds.runID, day, counts.Sub(base))); err != nil {
+		return err
+	}
+
+	if !today.Equal(day) {
+		ds.mu.Lock()
+		ds.day, ds.base = today, counts
+		ds.mu.Unlock()
+	}
+	return nil
+}
+
+func dailyStatsRow(workerID string, day time.Time, c observability.JobCounts) job.DailyStats {
+	return job.DailyStats{
+		Day:          day,
+		WorkerID:     workerID,
+		Claimed:      int64(c.Claimed),
+		Done:         int64(c.Done),
+		Failed:       int64(c.Failed),
+		Retried:      int64(c.Retried),
+		DeadLettered: int64(c.DeadLettered),
+		TimedOut:     int64(c.TimedOut),
+	}
+}
+
+func (w *Worker) dailyStatsLoop(ctx context.Context) {
+	t := time.NewTicker(w.dailyStats.every)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			fctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+			if err := w.flushDailyStats(fctx); err != nil {
+				log.Printf("worker.daily_stats flush failed err=%v", err)
+			}
+			cancel()
+		}
+	}
+}
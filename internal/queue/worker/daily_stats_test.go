@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+)
+
+type fakeStatsStore struct {
+	mu   sync.Mutex
+	rows []job.DailyStats
+}
+
+func (f *fakeStatsStore) UpsertDaily(ctx context.Context, s job.DailyStats) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rows = append(f.rows, s)
+	return nil
+}
+
+func (f *fakeStatsStore) last(t *testing.T) job.DailyStats {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.rows) == 0 {
+		t.Fatalf("nothing was flushed")
+	}
+	return f.rows[len(f.rows)-1]
+}
+
+func TestDailyStats_FlushedOnShutdown(t *testing.T) {
+	repo := newLeaseJobsRepo(job.Job{ID: "job-1", Type: "test.ok", MaxAttempts: 3})
+	store := &fakeStatsStore{}
+
+	// the timer never fires: only the shutdown flush can write the row
+	w := New(Config{
+		WorkerID:     "worker-stats",
+		PollInterval: 10 * time.Millisecond,
+		Concurrency:  1,
+	}, repo, &fakeEventsRepo{}, nil, nil).
+		Register("test.ok", func(ctx context.Context, j job.Job) error { return nil }).
+		WithDailyStats(store, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	// the outcome is counted just after MarkDone, so wait on the counts
+	var live []observability.LiveJobCount
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		live = observability.LiveJobCounts.Snapshot()
+		if len(live) == 1 && live[0].Counts.Done == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(live) != 1 || !strings.HasPrefix(live[0].WorkerID, "worker-stats@") || live[0].Counts.Done != 1 {
+		t.Fatalf("expected the running worker's live counts, got %+v", live)
+	}
+
+	cancel()
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	got := store.last(t)
+	if got.WorkerID != live[0].WorkerID || !got.Day.Equal(utcDay(time.Now())) || got.Claimed != 1 || got.Done != 1 {
+		t.Fatalf("unexpected final flush: %+v", got)
+	}
+	if live := observability.LiveJobCounts.Snapshot(); len(live) != 0 {
+		t.Fatalf("expected the stopped worker to unregister, got %+v", live)
+	}
+}
+
+func TestDailyStats_DayRolloverStartsFromZero(t *testing.T) {
+	store := &fakeStatsStore{}
+	w := New(Config{WorkerID: "worker-stats"}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).
+		WithDailyStats(store, time.Hour)
+
+	now := time.Date(2026, 3, 17, 23, 59, 0, 0, time.UTC)
+	w.dailyStats.now = func() time.Time { return now }
+	w.dailyStats.begin("worker-stats", w.metrics.Counts())
+
+	w.metrics.IncClaimed()
+	w.metrics.IncDone()
+	now = now.Add(2 * time.Minute)
+	if err := w.flushDailyStats(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := store.last(t); got.Day.Format(time.DateOnly) != "2026-03-17" || got.Done != 1 {
+		t.Fatalf("expected the old day's final totals, got %+v", got)
+	}
+
+	w.metrics.IncClaimed()
+	w.metrics.IncFailed()
+	if err := w.flushDailyStats(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := store.last(t); got.Day.Format(time.DateOnly) != "2026-03-18" || got.Claimed != 1 || got.Done != 0 || got.Failed != 1 {
+		t.Fatalf("expected the new day to count from zero, got %+v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+// EmailVerificationUsers looks up who to send a verification link to.
+type EmailVerificationUsers interface {
+	GetByID(ctx context.Context, id string) (user.User, error)
+	EmailVerified(ctx context.Context, id string) (bool, error)
+}
+
+type emailVerifier struct {
+	users  EmailVerificationUsers
+	signer *emailverify.Signer
+}
+
+// WithEmailVerification enables user.email_verification: the user is
+// emailed a signed link for their current address, unless it is already
+// verified.
+func (w *Worker) WithEmailVerification(users EmailVerificationUsers, signer *emailverify.Signer) *Worker {
+	w.verifications = &emailVerifier{users: users, signer: signer}
+	return w.Register(jobs.TypeUserEmailVerification, func(ctx context.Context, j job.Job) error {
+		var p jobs.UserEmailVerificationPayload
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+
+		return w.sendEmailVerification(ctx, p)
+	})
+}
+
+func (w *Worker) sendEmailVerification(ctx context.Context, p jobs.UserEmailVerificationPayload) error {
+	if w.verifications == nil {
+		return fmt.Errorf("email verification dependencies not configured")
+	}
+	notifier, ok := w.notifier.(notifications.EmailVerificationNotifier)
+	if !ok {
+		return jobs.NonRetryable(fmt.Errorf("notifier cannot send email verifications: %w", notifications.ErrUnsupported))
+	}
+	ev := w.verifications
+
+	u, err := ev.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		// the account was deleted after the job was queued
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	verified, err := ev.users.EmailVerified(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return nil
+	}
+
+	// signed for the address read now, so a link sent before an email
+	// change cannot verify the new one
+	token, expiresAt := ev.signer.Sign(u.ID, u.Email)
+
+	err = notifier.SendEmailVerification(ctx, notifications.SendEmailVerificationInput{
+		Email:     u.Email,
+		Name:      u.Name,
+		UserID:    u.ID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, notifications.ErrCircuitOpen) {
+			return fmt.Errorf("notifier fail-fast: %w", err)
+		}
+		if errors.Is(err, notifications.ErrUnsupported) {
+			return jobs.NonRetryable(err)
+		}
+		return err
+	}
+	return nil
+}
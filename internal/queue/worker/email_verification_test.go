@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+type fakeVerificationUsers struct {
+	users    map[string]user.User
+	verified map[string]bool
+}
+
+func (f fakeVerificationUsers) GetByID(ctx context.Context, id string) (user.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return user.User{}, postgres.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (f fakeVerificationUsers) EmailVerified(ctx context.Context, id string) (bool, error) {
+	return f.verified[id], nil
+}
+
+type fakeVerificationNotifier struct {
+	sent []notifications.SendEmailVerificationInput
+}
+
+func (n *fakeVerificationNotifier) SendRegistrationConfirmation(ctx context.Context, in notifications.SendRegistrationConfirmationInput) error {
+	return nil
+}
+
+func (n *fakeVerificationNotifier) SendEventPublished(ctx context.Context, in notifications.SendEventPublishedInput) error {
+	return nil
+}
+
+func (n *fakeVerificationNotifier) SendEmailVerification(ctx context.Context, in notifications.SendEmailVerificationInput) error {
+	n.sent = append(n.sent, in)
+	return nil
+}
+
+func verificationJob(t *testing.T, userID string) job.Job {
+	t.Helper()
+
+	raw, err := jobs.UserEmailVerificationPayload{UserID: userID}.JSON()
+	if err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	return job.Job{ID: "job-" + userID, Type: jobs.TypeUserEmailVerification, Payload: raw}
+}
+
+func TestExecuteEmailVerification_SendsALinkForTheCurrentAddress(t *testing.T) {
+	notifier := &fakeVerificationNotifier{}
+	signer := emailverify.NewSigner("secret", time.Hour)
+	users := fakeVerificationUsers{
+		users: map[string]user.User{
+			"u-1": {ID: "u-1", Email: "ada@example.com", Name: "Ada"},
+			"u-2": {ID: "u-2", Email: "bob@example.com", Name: "Bob"},
+		},
+		verified: map[string]bool{"u-2": true},
+	}
+	w := &Worker{notifier: notifier}
+	w.WithEmailVerification(users, signer)
+
+	// u-2 is already verified and u-3 is gone: neither is emailed
+	for _, id := range []string{"u-1", "u-2", "u-3"} {
+		if err := w.execute(context.Background(), verificationJob(t, id)); err != nil {
+			t.Fatalf("execute %s: %v", id, err)
+		}
+	}
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("expected one verification email, got %+v", notifier.sent)
+	}
+	got := notifier.sent[0]
+	if got.Email != "ada@example.com" || got.UserID != "u-1" || got.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected email %+v", got)
+	}
+	claims, err := signer.Verify(got.Token)
+	if err != nil || claims.UserID != "u-1" || claims.Email != "ada@example.com" {
+		t.Fatalf("expected a token for u-1's address, got %+v (%v)", claims, err)
+	}
+}
+
+func TestExecuteEmailVerification_UnsupportedIsNotRetried(t *testing.T) {
+	w := &Worker{notifier: &fakeAnnounceNotifier{}}
+	w.WithEmailVerification(fakeVerificationUsers{}, emailverify.NewSigner("secret", time.Hour))
+
+	err := w.execute(context.Background(), verificationJob(t, "u-1"))
+	if !errors.Is(err, jobs.ErrNonRetryable) || !errors.Is(err, notifications.ErrUnsupported) {
+		t.Fatalf("expected a non-retryable unsupported error, got %v", err)
+	}
+}
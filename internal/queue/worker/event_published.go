@@ -0,0 +1,245 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+// announcePageSize is how many registrations are read, and their jobs
+// inserted, per round trip when an event is published.
+const announcePageSize = 500
+
+// PublishAnnouncementSources list an event's attendees when it is published
+// and re-check each one before sending.
+type PublishAnnouncementSources struct {
+	Registrations interface {
+		ListByEventCursor(ctx context.Context, eventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error)
+		GetByID(ctx context.Context, eventID, registrationID string) (registration.Registration, error)
+	}
+	Events interface {
+		GetByID(ctx context.Context, id string) (event.Event, error)
+	}
+}
+
+// BatchEnqueuer is implemented by job repos that insert many jobs in one
+// statement, skipping those whose idempotency key already exists.
+type BatchEnqueuer interface {
+	CreateMany(ctx context.Context, reqs []job.CreateRequest) ([]job.Job, error)
+}
+
+type publishAnnouncer struct {
+	sources PublishAnnouncementSources
+	gate    RegistrationDeliveryGate
+}
+
+// WithPublishAnnouncements enables registration.event_published: publishing
+// an event queues one notification per confirmed registration instead of
+// sending them inline, each sent at most once.
+func (w *Worker) WithPublishAnnouncements(sources PublishAnnouncementSources, gate RegistrationDeliveryGate, enq JobsEnqueuer) *Worker {
+	w.announcer = &publishAnnouncer{sources: sources, gate: gate}
+	w.enqueuer = enq
+	return w.Register(jobs.TypeRegistrationEventPublished, func(ctx context.Context, j job.Job) error {
+		var p jobs.RegistrationEventPublishedPayload
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+
+		return w.sendPublishAnnouncement(ctx, j.ID, p)
+	})
+}
+
+// announceOnce tells attendees about a publish once. With a marker, whoever
+// finds it set announces and clears it after the last job is queued, so a
+// retry of an attempt that failed half way finishes the work and nothing
+// later repeats it. Without one, only the publish that changed the event
+// announces it.
+func (w *Worker) announceOnce(ctx context.Context, eventID string, changed bool) error {
+	if w.announcer == nil || w.enqueuer == nil {
+		return nil
+	}
+
+	marker, ok := w.events.(PublishAnnouncementMarker)
+	if !ok {
+		if !changed {
+			return nil
+		}
+		return w.announcePublished(ctx, eventID)
+	}
+
+	pending, err := marker.PublishAnnouncementPending(ctx, eventID)
+	if err != nil || !pending {
+		return err
+	}
+	if err := w.announcePublished(ctx, eventID); err != nil {
+		return err
+	}
+	return marker.MarkPublishAnnounced(ctx, eventID)
+}
+
+// announcePublished queues a registration.event_published job for every
+// confirmed registration of eventID, a page at a time. Each job is keyed by
+// its registration, so running it again only adds the missing ones.
+func (w *Worker) announcePublished(ctx context.Context, eventID string) error {
+	if w.announcer == nil || w.enqueuer == nil {
+		return nil
+	}
+	regs := w.announcer.sources.Registrations
+
+	queued := 0
+	afterCreatedAt := time.Unix(0, 0).UTC()
+	afterID := "00000000-0000-0000-0000-000000000000"
+
+	for {
+		page, _, hasMore, err := regs.ListByEventCursor(ctx, eventID, registration.ListFilter{}, announcePageSize, afterCreatedAt, afterID)
+		if err != nil {
+			return fmt.Errorf("announce publish: %w", err)
+		}
+
+		reqs := make([]job.CreateRequest, 0, len(page))
+		for _, r := range page {
+			if r.Status != registration.StatusConfirmed {
+				continue
+			}
+			raw, err := jobs.RegistrationEventPublishedPayload{RegistrationID: r.ID, EventID: eventID}.JSON()
+			if err != nil {
+				return err
+			}
+			key := jobs.EventPublishedKey(r.ID)
+			reqs = append(reqs, job.CreateRequest{
+				Type:           jobs.TypeRegistrationEventPublished,
+				Payload:        raw,
+				MaxAttempts:    5,
+				IdempotencyKey: &key,
+			})
+		}
+
+		n, err := w.enqueueAll(ctx, reqs)
+		queued += n
+		if err != nil {
+			return fmt.Errorf("announce publish: %w", err)
+		}
+
+		if !hasMore || len(page) == 0 {
+			break
+		}
+		last := page[len(page)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+
+	if queued > 0 {
+		log.Printf("publish announcements: queued event=%s count=%d", eventID, queued)
+	}
+	return nil
+}
+
+// enqueueAll inserts reqs in one statement when the enqueuer can, one by one
+// otherwise, and reports how many were new.
+func (w *Worker) enqueueAll(ctx context.Context, reqs []job.CreateRequest) (int, error) {
+	if len(reqs) == 0 {
+		return 0, nil
+	}
+	if b, ok := w.enqueuer.(BatchEnqueuer); ok {
+		created, err := b.CreateMany(ctx, reqs)
+		return len(created), err
+	}
+
+	n := 0
+	for _, req := range reqs {
+		if _, err := w.enqueuer.Create(ctx, req); err != nil {
+			if postgres.IsUniqueViolation(err) {
+				continue
+			}
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (w *Worker) sendPublishAnnouncement(ctx context.Context, jobID string, p jobs.RegistrationEventPublishedPayload) error {
+	if w.announcer == nil {
+		return fmt.Errorf("publish announcement dependencies not configured")
+	}
+	if w.notifier == nil {
+		return fmt.Errorf("notifier not configured")
+	}
+	pa := w.announcer
+
+	// attendees who cancelled since, or events deleted or already started,
+	// get nothing
+	reg, err := pa.sources.Registrations.GetByID(ctx, p.EventID, p.RegistrationID)
+	if err != nil {
+		if errors.Is(err, registration.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if reg.Status != registration.StatusConfirmed {
+		return nil
+	}
+
+	e, err := pa.sources.Events.GetByID(ctx, p.EventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !e.StartAt.After(time.Now()) {
+		return nil
+	}
+
+	kind := notificationsdelivery.KindEventPublished
+	err = pa.gate.TryStartRegistrationDelivery(ctx, kind, jobID, reg.ID, reg.Email)
+	if err != nil {
+		if errors.Is(err, notificationsdelivery.ErrAlreadySent) {
+			return nil
+		}
+		if errors.Is(err, notificationsdelivery.ErrInProgress) {
+			return fmt.Errorf("publish announcement send in progress")
+		}
+		return err
+	}
+
+	if w.recipientQuarantined(ctx, reg.Email) {
+		return pa.gate.MarkRegistrationDeliverySkipped(ctx, kind, reg.ID, "recipient quarantined")
+	}
+
+	err = w.notifier.SendEventPublished(ctx, notifications.SendEventPublishedInput{
+		Email:          reg.Email,
+		Name:           reg.Name,
+		EventID:        e.ID,
+		Title:          e.Title,
+		StartAt:        e.StartAt,
+		RegistrationID: reg.ID,
+	})
+	if err != nil {
+		_ = pa.gate.MarkRegistrationDeliveryFailed(ctx, kind, reg.ID, err.Error())
+		w.recordDeliveryFailure(ctx, reg.Email, err)
+
+		if errors.Is(err, notifications.ErrCircuitOpen) {
+			return fmt.Errorf("notifier fail-fast: %w", err)
+		}
+		if errors.Is(err, notifications.ErrPermanent) || errors.Is(err, notifications.ErrUnsupported) {
+			return jobs.NonRetryable(err)
+		}
+		return err
+	}
+
+	if err := pa.gate.MarkRegistrationDeliverySent(ctx, kind, reg.ID, nil); err != nil {
+		log.Printf("deliveries: mark sent failed reg=%s job=%s err=%v", reg.ID, jobID, err)
+	}
+	return nil
+}
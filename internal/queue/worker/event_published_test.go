@@ -0,0 +1,284 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// announceRegistrations pages through regs and looks them up by id.
+type announceRegistrations struct {
+	pagedRegistrations
+}
+
+func (a *announceRegistrations) GetByID(ctx context.Context, eventID, registrationID string) (registration.Registration, error) {
+	for _, r := range a.regs {
+		if r.ID == registrationID {
+			return r, nil
+		}
+	}
+	return registration.Registration{}, registration.ErrNotFound
+}
+
+// keyedEnqueuer is a job table that skips requests whose idempotency key it
+// already holds, like the jobs repo. batches counts CreateMany calls; while
+// failAfter is set, Create fails once that many jobs are queued.
+type keyedEnqueuer struct {
+	jobs      []job.CreateRequest
+	keys      map[string]bool
+	batches   int
+	failAfter int
+}
+
+func (e *keyedEnqueuer) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
+	if e.failAfter > 0 && len(e.jobs) >= e.failAfter {
+		return job.Job{}, errors.New("connection reset")
+	}
+	if req.IdempotencyKey != nil {
+		if e.keys[*req.IdempotencyKey] {
+			return job.Job{}, &pgconn.PgError{Code: "23505"}
+		}
+		e.keys[*req.IdempotencyKey] = true
+	}
+	e.jobs = append(e.jobs, req)
+	return job.Job{ID: fmt.Sprintf("job-%d", len(e.jobs)), Type: req.Type, Payload: req.Payload}, nil
+}
+
+// batchEnqueuer also inserts a page in one call.
+type batchEnqueuer struct{ *keyedEnqueuer }
+
+func (e batchEnqueuer) CreateMany(ctx context.Context, reqs []job.CreateRequest) ([]job.Job, error) {
+	e.batches++
+	var created []job.Job
+	for _, req := range reqs {
+		if j, err := e.Create(ctx, req); err == nil {
+			created = append(created, j)
+		}
+	}
+	return created, nil
+}
+
+type fakeAnnounceNotifier struct {
+	sent []notifications.SendEventPublishedInput
+}
+
+func (n *fakeAnnounceNotifier) SendRegistrationConfirmation(ctx context.Context, in notifications.SendRegistrationConfirmationInput) error {
+	return nil
+}
+
+func (n *fakeAnnounceNotifier) SendEventPublished(ctx context.Context, in notifications.SendEventPublishedInput) error {
+	n.sent = append(n.sent, in)
+	return nil
+}
+
+// attendees makes n registrations for evt-1; every third is cancelled.
+func attendees(n int) *announceRegistrations {
+	regs := &announceRegistrations{}
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i := range n {
+		status := registration.StatusConfirmed
+		if i%3 == 2 {
+			status = registration.StatusCancelled
+		}
+		regs.regs = append(regs.regs, registration.Registration{
+			ID:        fmt.Sprintf("reg-%04d", i),
+			EventID:   "evt-1",
+			Email:     fmt.Sprintf("attendee%d@example.com", i),
+			Name:      "Attendee",
+			Status:    status,
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+		})
+	}
+	return regs
+}
+
+func publishJob(t *testing.T, attempts int) job.Job {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]string{"eventId": "evt-1"})
+	if err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	return job.Job{ID: "job-publish", Type: jobs.TypeEventPublish, Payload: raw, Attempts: attempts}
+}
+
+// markedEventsRepo sets the announcement marker when MarkPublished
+// publishes, like the events repo.
+type markedEventsRepo struct {
+	changed *bool
+	pending bool
+}
+
+func (r *markedEventsRepo) MarkPublished(ctx context.Context, eventID string) (bool, error) {
+	if *r.changed {
+		r.pending = true
+	}
+	return *r.changed, nil
+}
+
+func (r *markedEventsRepo) PublishAnnouncementPending(ctx context.Context, eventID string) (bool, error) {
+	return r.pending, nil
+}
+
+func (r *markedEventsRepo) MarkPublishAnnounced(ctx context.Context, eventID string) error {
+	r.pending = false
+	return nil
+}
+
+func announceWorker(regs *announceRegistrations, enq JobsEnqueuer, changed *bool) *Worker {
+	w := New(Config{}, &fakeJobsRepo{}, &markedEventsRepo{changed: changed}, nil, nil)
+	return w.WithPublishAnnouncements(PublishAnnouncementSources{
+		Registrations: regs,
+		Events:        fakeReminderEvents{startAt: time.Now().Add(72 * time.Hour)},
+	}, &fakeRegistrationGate{sent: map[string]bool{}}, enq)
+}
+
+func TestHandleEventPublish_QueuesOneAnnouncementPerConfirmedRegistration(t *testing.T) {
+	regs := attendees(1203)
+	enq := batchEnqueuer{&keyedEnqueuer{keys: map[string]bool{}}}
+	changed := true
+	w := announceWorker(regs, enq, &changed)
+
+	if err := w.HandleEventPublish(context.Background(), publishJob(t, 0)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	if len(enq.jobs) != 802 {
+		t.Fatalf("expected one job per confirmed registration (802), got %d", len(enq.jobs))
+	}
+	if enq.batches != 3 || len(regs.pages) != 3 || regs.pages[0] != announcePageSize {
+		t.Fatalf("expected three pages inserted a page at a time, got batches=%d pages=%v", enq.batches, regs.pages)
+	}
+
+	first := enq.jobs[0]
+	var p jobs.RegistrationEventPublishedPayload
+	if err := json.Unmarshal(first.Payload, &p); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if first.Type != jobs.TypeRegistrationEventPublished || p.RegistrationID != "reg-0000" || p.EventID != "evt-1" ||
+		first.IdempotencyKey == nil || *first.IdempotencyKey != jobs.EventPublishedKey("reg-0000") {
+		t.Fatalf("unexpected job %+v payload %+v", first, p)
+	}
+	for _, req := range enq.jobs {
+		if *req.IdempotencyKey == jobs.EventPublishedKey("reg-0002") {
+			t.Fatal("a cancelled registration was queued")
+		}
+	}
+}
+
+func TestHandleEventPublish_AlreadyPublishedDoesNotReannounce(t *testing.T) {
+	regs := attendees(6)
+	enq := &keyedEnqueuer{keys: map[string]bool{}}
+	changed := true
+	w := announceWorker(regs, enq, &changed)
+
+	if err := w.HandleEventPublish(context.Background(), publishJob(t, 0)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(enq.jobs) != 4 {
+		t.Fatalf("expected 4 announcements, got %d", len(enq.jobs))
+	}
+
+	// a later publish of the same event finds it published, and a retry of
+	// the publish that did it finds it announced
+	changed = false
+	regs.regs = append(regs.regs, registration.Registration{ID: "reg-late", EventID: "evt-1", Status: registration.StatusConfirmed, CreatedAt: time.Now()})
+	regs.pages = nil
+	for _, attempts := range []int{0, 1} {
+		if err := w.HandleEventPublish(context.Background(), publishJob(t, attempts)); err != nil {
+			t.Fatalf("republish: %v", err)
+		}
+	}
+	if len(enq.jobs) != 4 || len(regs.pages) != 0 {
+		t.Fatalf("an already-announced event was announced again: jobs=%d pages=%v", len(enq.jobs), regs.pages)
+	}
+}
+
+func TestHandleEventPublish_RetryFinishesAnInterruptedAnnouncement(t *testing.T) {
+	regs := attendees(6)
+	enq := &keyedEnqueuer{keys: map[string]bool{}, failAfter: 2}
+	changed := true
+	w := announceWorker(regs, enq, &changed)
+
+	if err := w.HandleEventPublish(context.Background(), publishJob(t, 0)); err == nil {
+		t.Fatal("expected the first attempt to fail half way")
+	}
+
+	// the retry finds the event published but its attendees not all told
+	changed = false
+	enq.failAfter = 0
+	if err := w.HandleEventPublish(context.Background(), publishJob(t, 1)); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(enq.jobs) != 4 {
+		t.Fatalf("expected the retry to queue the missing announcements, got %d jobs", len(enq.jobs))
+	}
+
+	if err := w.HandleEventPublish(context.Background(), publishJob(t, 2)); err != nil {
+		t.Fatalf("second retry: %v", err)
+	}
+	if len(enq.jobs) != 4 {
+		t.Fatalf("expected nothing more once announced, got %d jobs", len(enq.jobs))
+	}
+}
+
+func TestExecuteEventPublished_SendsOncePerRegistration(t *testing.T) {
+	regs := attendees(3)
+	notifier := &fakeAnnounceNotifier{}
+	gate := &fakeRegistrationGate{sent: map[string]bool{}}
+
+	w := &Worker{notifier: notifier}
+	w.WithPublishAnnouncements(PublishAnnouncementSources{
+		Registrations: regs,
+		Events:        fakeReminderEvents{startAt: time.Now().Add(72 * time.Hour)},
+	}, gate, &keyedEnqueuer{keys: map[string]bool{}})
+
+	run := func(id, regID string) {
+		t.Helper()
+		raw, _ := jobs.RegistrationEventPublishedPayload{RegistrationID: regID, EventID: "evt-1"}.JSON()
+		if err := w.execute(context.Background(), job.Job{ID: id, Type: jobs.TypeRegistrationEventPublished, Payload: raw}); err != nil {
+			t.Fatalf("execute %s: %v", id, err)
+		}
+	}
+	run("job-1", "reg-0000")
+	run("job-1-retry", "reg-0000")
+	// cancelled since the job was queued
+	run("job-2", "reg-0002")
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("expected 1 announcement, got %d", len(notifier.sent))
+	}
+	if got := notifier.sent[0]; got.Email != "attendee0@example.com" || got.Title != "Go Meetup" || got.EventID != "evt-1" || got.StartAt.IsZero() {
+		t.Fatalf("unexpected announcement %+v", got)
+	}
+	if !gate.sent[notificationsdelivery.KindEventPublished+"|reg-0000"] {
+		t.Fatalf("expected the delivery recorded under %s", notificationsdelivery.KindEventPublished)
+	}
+}
+
+func TestExecuteEventPublished_SkipsStartedEvents(t *testing.T) {
+	notifier := &fakeAnnounceNotifier{}
+	w := &Worker{notifier: notifier}
+	w.WithPublishAnnouncements(PublishAnnouncementSources{
+		Registrations: attendees(1),
+		Events:        fakeReminderEvents{startAt: time.Now().Add(-time.Hour)},
+	}, &fakeRegistrationGate{sent: map[string]bool{}}, nil)
+
+	raw, _ := jobs.RegistrationEventPublishedPayload{RegistrationID: "reg-0000", EventID: "evt-1"}.JSON()
+	if err := w.execute(context.Background(), job.Job{ID: "job-1", Type: jobs.TypeRegistrationEventPublished, Payload: raw}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Fatalf("expected nothing sent for a started event, got %+v", notifier.sent)
+	}
+}
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+)
+
+const defaultExportsCleanupBatch = 100
+
+// ExportsCleaner finds expired registration exports and removes their rows.
+type ExportsCleaner interface {
+	ListExpired(ctx context.Context, now time.Time, retention time.Duration, limit int) ([]registrationexport.CSVExport, error)
+	DeleteByJobIDs(ctx context.Context, jobIDs []string) (int64, error)
+}
+
+type exportCleaner struct {
+	repo      ExportsCleaner
+	store     exportstore.Store
+	retention time.Duration
+}
+
+// WithExportCleanup enables the daily exports.cleanup job: exports older than
+// retention (or their keepUntil) lose their file and then their row. Like
+// counter verification, the worker schedules today's run on start and each
+// run schedules the next.
+func (w *Worker) WithExportCleanup(repo ExportsCleaner, store exportstore.Store, retention time.Duration, enq JobsEnqueuer) *Worker {
+	w.exportCleanup = &exportCleaner{repo: repo, store: store, retention: retention}
+	w.enqueuer = enq
+	return w.Register(jobs.TypeExportsCleanup, w.cleanExports)
+}
+
+func (w *Worker) cleanExports(ctx context.Context, j job.Job) error {
+	if w.exportCleanup == nil {
+		return fmt.Errorf("export cleanup not configured")
+	}
+	ec := w.exportCleanup
+
+	var p jobs.ExportsCleanupPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = defaultExportsCleanupBatch
+	}
+
+	now := time.Now().UTC()
+	var res jobs.ExportsCleanupResult
+
+	for ctx.Err() == nil {
+		batch, err := ec.repo.ListExpired(ctx, now, ec.retention, p.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		res.Batches++
+
+		// blob first: a row without its file is harmless, a file without a
+		// row would never be found again
+		ids := make([]string, 0, len(batch))
+		for _, e := range batch {
+			if e.StoragePath != "" {
+				err := ec.store.Delete(ctx, e.StoragePath)
+				switch {
+				case errors.Is(err, exportstore.ErrNotFound):
+					res.MissingBlobs++
+				case err != nil:
+					res.Failed++
+					log.Printf("exports.cleanup: delete blob failed job=%s location=%s err=%v", e.JobID, e.StoragePath, err)
+					continue
+				}
+			}
+			ids = append(ids, e.JobID)
+		}
+
+		if len(ids) > 0 {
+			n, err := ec.repo.DeleteByJobIDs(ctx, ids)
+			if err != nil {
+				return err
+			}
+			res.Deleted += int(n)
+		}
+
+		// a short batch was the last one; a batch where nothing could be
+		// deleted would come back unchanged
+		if len(batch) < p.BatchSize || len(ids) == 0 {
+			break
+		}
+	}
+
+	log.Printf("exports.cleanup deleted=%d missing_blobs=%d failed=%d batches=%d job=%s", res.Deleted, res.MissingBlobs, res.Failed, res.Batches, j.ID)
+
+	if rw, ok := w.repo.(JobResultWriter); ok {
+		raw, err := json.Marshal(res)
+		if err == nil {
+			err = rw.SetResult(ctx, j.ID, raw)
+		}
+		if err != nil {
+			log.Printf("exports.cleanup: store result failed job=%s err=%v", j.ID, err)
+		}
+	}
+
+	if w.enqueuer != nil {
+		w.scheduleExportsCleanup(ctx, time.Now().UTC().Add(jobs.ExportsCleanupEvery))
+	}
+
+	return ctx.Err()
+}
+
+// scheduleExportsCleanup enqueues the cleanup for runAt's day; the per-day
+// idempotency key makes repeated calls no-ops.
+func (w *Worker) scheduleExportsCleanup(ctx context.Context, runAt time.Time) {
+	raw, err := jobs.ExportsCleanupPayload{}.JSON()
+	if err != nil {
+		return
+	}
+
+	key := jobs.ExportsCleanupKey(runAt)
+	_, err = w.enqueuer.Create(ctx, job.CreateRequest{
+		Type:           jobs.TypeExportsCleanup,
+		Payload:        raw,
+		RunAt:          runAt,
+		MaxAttempts:    3,
+		IdempotencyKey: &key,
+	})
+	if err != nil && !postgres.IsUniqueViolation(err) {
+		log.Printf("exports.cleanup: schedule failed key=%s err=%v", key, err)
+	}
+}
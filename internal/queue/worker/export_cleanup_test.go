@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// fakeExportsCleaner serves expired rows in batches and forgets deleted ones.
+type fakeExportsCleaner struct {
+	rows    []registrationexport.CSVExport
+	batches [][]string
+}
+
+func (f *fakeExportsCleaner) ListExpired(ctx context.Context, now time.Time, retention time.Duration, limit int) ([]registrationexport.CSVExport, error) {
+	var out []registrationexport.CSVExport
+	for _, r := range f.rows {
+		if len(out) == limit {
+			break
+		}
+		if !r.DeleteAfter(retention).After(now) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeExportsCleaner) DeleteByJobIDs(ctx context.Context, jobIDs []string) (int64, error) {
+	f.batches = append(f.batches, jobIDs)
+	drop := map[string]bool{}
+	for _, id := range jobIDs {
+		drop[id] = true
+	}
+	kept := f.rows[:0]
+	for _, r := range f.rows {
+		if !drop[r.JobID] {
+			kept = append(kept, r)
+		}
+	}
+	n := len(f.rows) - len(kept)
+	f.rows = kept
+	return int64(n), nil
+}
+
+func writeArtifact(t *testing.T, store exportstore.Store, name string) string {
+	t.Helper()
+	w, location, err := store.Create(context.Background(), name)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, _ = io.WriteString(w, "id\n")
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return location
+}
+
+func TestCleanExports_ToleratesMissingBlobsAndBatches(t *testing.T) {
+	store, err := exportstore.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	old := time.Now().UTC().Add(-40 * 24 * time.Hour)
+	present := writeArtifact(t, store, "present.csv")
+	cleaner := &fakeExportsCleaner{rows: []registrationexport.CSVExport{
+		{JobID: "job-present", StoragePath: present, CreatedAt: old},
+		{JobID: "job-missing", StoragePath: "already-gone.csv", CreatedAt: old},
+		{JobID: "job-inline", CreatedAt: old}, // csv_data row from before the store
+		{JobID: "job-fresh", StoragePath: writeArtifact(t, store, "fresh.csv"), CreatedAt: time.Now().UTC()},
+	}}
+
+	jobsRepo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+	w := &Worker{repo: jobsRepo}
+	w.WithExportCleanup(cleaner, store, 30*24*time.Hour, jobsRepo)
+
+	raw, _ := jobs.ExportsCleanupPayload{BatchSize: 2}.JSON()
+	if err := w.execute(context.Background(), job.Job{ID: "cleanup-1", Type: jobs.TypeExportsCleanup, Payload: raw}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if len(cleaner.rows) != 1 || cleaner.rows[0].JobID != "job-fresh" {
+		t.Fatalf("expected only the fresh export to remain, got %+v", cleaner.rows)
+	}
+	if len(cleaner.batches) != 2 {
+		t.Fatalf("expected 2 batches of deletes, got %v", cleaner.batches)
+	}
+	if _, err := store.Open(context.Background(), present); !errors.Is(err, exportstore.ErrNotFound) {
+		t.Fatalf("expected the expired file to be deleted, got %v", err)
+	}
+
+	var res jobs.ExportsCleanupResult
+	if err := json.Unmarshal(jobsRepo.results["cleanup-1"], &res); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if res.Deleted != 3 || res.MissingBlobs != 1 || res.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// each run schedules tomorrow's
+	if len(jobsRepo.created) != 1 || *jobsRepo.created[0].IdempotencyKey != jobs.ExportsCleanupKey(time.Now().UTC().Add(jobs.ExportsCleanupEvery)) {
+		t.Fatalf("expected the next cleanup to be scheduled, got %+v", jobsRepo.created)
+	}
+}
+
+func TestCleanExports_KeepUntilHoldsExportPastRetention(t *testing.T) {
+	store, err := exportstore.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	old := time.Now().UTC().Add(-40 * 24 * time.Hour)
+	keep := time.Now().UTC().Add(24 * time.Hour)
+	kept := writeArtifact(t, store, "kept.csv")
+	cleaner := &fakeExportsCleaner{rows: []registrationexport.CSVExport{
+		{JobID: "job-kept", StoragePath: kept, CreatedAt: old, KeepUntil: &keep},
+	}}
+
+	jobsRepo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+	w := &Worker{repo: jobsRepo}
+	w.WithExportCleanup(cleaner, store, 30*24*time.Hour, jobsRepo)
+
+	if err := w.execute(context.Background(), job.Job{ID: "cleanup-1", Type: jobs.TypeExportsCleanup}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(cleaner.rows) != 1 {
+		t.Fatalf("expected keepUntil to hold the export, got %+v", cleaner.rows)
+	}
+	if _, err := store.Open(context.Background(), kept); err != nil {
+		t.Fatalf("expected the kept file to remain: %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package worker
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// exportPageSize bounds how many registrations are held in memory at once.
+const exportPageSize = 500
+
+var registrationsCSVHeader = []string{
+	"registration_id",
+	"event_id",
+	"user_id",
+	"name",
+	"email",
+	"check_in_token",
+	"checked_in_at",
+	"created_at",
+}
+
+var activityCSVHeader = []string{
+	"activity_id",
+	"registration_id",
+	"kind",
+	"from_status",
+	"to_status",
+	"cancelled_by",
+	"cancellation_reason",
+	"occurred_at",
+}
+
+// RegistrationActivityReader is implemented by registration readers that can
+// page through an event's lifecycle history; exports asking for the activity
+// sheet need it.
+type RegistrationActivityReader interface {
+	ActivityByEventCursor(
+		ctx context.Context,
+		eventID string,
+		filter registration.ActivityFilter,
+		limit int,
+		afterAt time.Time,
+		afterID string,
+	) (items []registration.HistoryEntry, nextCursor *string, hasMore bool, err error)
+}
+
+func (w *Worker) exportRegistrationsCSV(ctx context.Context, jobID string, p jobs.RegistrationsExportCSVPayload) error {
+	if w.regsExport == nil || w.csvExports == nil || w.exportStore == nil {
+		return fmt.Errorf("registration csv export dependencies not configured")
+	}
+
+	var activity RegistrationActivityReader
+	if p.IncludeActivity {
+		var ok bool
+		if activity, ok = w.regsExport.(RegistrationActivityReader); !ok {
+			return fmt.Errorf("registration activity export not supported by the registrations reader")
+		}
+	}
+
+	// one file per job: a retry overwrites its own earlier attempt
+	fileName := fmt.Sprintf("event_%s_registrations_%s.csv", p.EventID, jobID)
+	contentType := "text/csv"
+	if activity != nil {
+		fileName = fmt.Sprintf("event_%s_registrations_%s.zip", p.EventID, jobID)
+		contentType = "application/zip"
+	}
+
+	out, location, err := w.exportStore.Create(ctx, fileName)
+	if err != nil {
+		return err
+	}
+
+	visibility := registration.PIIVisibility(p.PIIVisibility)
+	if visibility == "" {
+		visibility = registration.PIIFull
+	}
+
+	var rows int
+	if activity != nil {
+		rows, err = writeRegistrationsZip(ctx, out, w.regsExport, activity, p.EventID, exportPageSize, visibility)
+	} else {
+		rows, err = writeRegistrationsCSV(ctx, out, w.regsExport, p.EventID, exportPageSize, visibility)
+	}
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	var requestedBy *string
+	if p.RequestedBy != "" {
+		requestedBy = &p.RequestedBy
+	}
+
+	return w.csvExports.Save(ctx, registrationexport.CSVExport{
+		JobID:       jobID,
+		EventID:     p.EventID,
+		RequestedBy: requestedBy,
+		FileName:    fileName,
+		ContentType: contentType,
+		RowCount:    rows,
+		StoragePath: location,
+		CreatedAt:   time.Now().UTC(),
+		KeepUntil:   p.KeepUntil,
+	})
+}
+
+// writeRegistrationsCSV streams an event's registrations to out one page at a
+// time, projected for visibility, and returns the number of data rows written.
+func writeRegistrationsCSV(ctx context.Context, out io.Writer, reader RegistrationsExportReader, eventID string, pageSize int, visibility registration.PIIVisibility) (int, error) {
+	cw := csv.NewWriter(out)
+	if err := cw.Write(registrationsCSVHeader); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	afterCreatedAt := time.Unix(0, 0).UTC()
+	afterID := "00000000-0000-0000-0000-000000000000"
+
+	for {
+		page, _, hasMore, err := reader.ListByEventCursor(ctx, eventID, registration.ListFilter{}, pageSize, afterCreatedAt, afterID)
+		if err != nil {
+			return rows, err
+		}
+
+		for _, r := range page {
+			r = r.Project(visibility)
+			checkedInAt := ""
+			if r.CheckedInAt != nil {
+				checkedInAt = r.CheckedInAt.UTC().Format(time.RFC3339)
+			}
+
+			if err := cw.Write([]string{
+				r.ID,
+				r.EventID,
+				r.UserID,
+				r.Name,
+				r.Email,
+				r.CheckInToken,
+				checkedInAt,
+				r.CreatedAt.UTC().Format(time.RFC3339),
+			}); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+
+		// flush per page so the buffered writer never grows with the export
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return rows, err
+		}
+
+		if !hasMore || len(page) == 0 {
+			return rows, nil
+		}
+
+		last := page[len(page)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// writeRegistrationsZip writes the registrations sheet and the activity sheet
+// as two CSV files in one zip and returns the number of registration rows.
+func writeRegistrationsZip(ctx context.Context, out io.Writer, reader RegistrationsExportReader, activity RegistrationActivityReader, eventID string, pageSize int, visibility registration.PIIVisibility) (int, error) {
+	zw := zip.NewWriter(out)
+
+	f, err := zw.Create("registrations.csv")
+	if err != nil {
+		return 0, err
+	}
+	rows, err := writeRegistrationsCSV(ctx, f, reader, eventID, pageSize, visibility)
+	if err != nil {
+		return rows, err
+	}
+
+	f, err = zw.Create("activity.csv")
+	if err != nil {
+		return rows, err
+	}
+	if err := writeActivityCSV(ctx, f, activity, eventID, pageSize, visibility); err != nil {
+		return rows, err
+	}
+
+	return rows, zw.Close()
+}
+
+// writeActivityCSV streams every recorded transition of eventID's
+// registrations to out, oldest first, projected for visibility. Cancellations
+// carry who cancelled and why; the registrations sheet has no cancelled rows.
+func writeActivityCSV(ctx context.Context, out io.Writer, reader RegistrationActivityReader, eventID string, pageSize int, visibility registration.PIIVisibility) error {
+	cw := csv.NewWriter(out)
+	if err := cw.Write(activityCSVHeader); err != nil {
+		return err
+	}
+
+	afterAt := time.Unix(0, 0).UTC()
+	afterID := "00000000-0000-0000-0000-000000000000"
+
+	for {
+		page, _, hasMore, err := reader.ActivityByEventCursor(ctx, eventID, registration.ActivityFilter{}, pageSize, afterAt, afterID)
+		if err != nil {
+			return err
+		}
+
+		for _, h := range page {
+			h = h.Project(visibility)
+			if err := cw.Write([]string{
+				h.ID,
+				h.RegistrationID,
+				h.Kind,
+				h.FromStatus,
+				h.ToStatus,
+				h.CancelledBy,
+				h.CancellationReason,
+				h.OccurredAt.UTC().Format(time.RFC3339Nano),
+			}); err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+		if !hasMore || len(page) == 0 {
+			return nil
+		}
+
+		last := page[len(page)-1]
+		afterAt, afterID = last.OccurredAt, last.ID
+	}
+}
@@ -2,25 +2,56 @@ package worker
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/geocoder89/eventhub/internal/notifications"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// The health listener answers kubelet probes and scrapes, never a browser, so
+// every phase of a request is kept short: a client that trickles its headers
+// or holds a connection idle is dropped well before the probes back up.
+const (
+	defaultHealthMaxConns   = 32
+	healthReadHeaderTimeout = 2 * time.Second
+	healthReadTimeout       = 5 * time.Second
+	healthWriteTimeout      = 10 * time.Second
+	healthIdleTimeout       = 30 * time.Second
+	healthMaxHeaderBytes    = 8 << 10
+)
+
+func newHealthServer(h http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: healthReadHeaderTimeout,
+		ReadTimeout:       healthReadTimeout,
+		WriteTimeout:      healthWriteTimeout,
+		IdleTimeout:       healthIdleTimeout,
+		MaxHeaderBytes:    healthMaxHeaderBytes,
+	}
+}
+
 func (w *Worker) HealthHandler(reg *prometheus.Registry) http.Handler {
 	r := gin.New()
 
-	r.Use(gin.Recovery())
+	r.Use(gin.Recovery(), w.logHealthRequest)
 
 	// liveness: process is up
 
+	// queue figures ride along when known; liveness never depends on them
 	r.GET("/healthz", func(ctx *gin.Context) {
-		ctx.JSON(http.StatusOK, gin.H{
-			"ok": true,
-		})
+		body := gin.H{"ok": true}
+		if stats, ok := w.queueStats(ctx.Request.Context()); ok {
+			body["queue"] = queueHealthBody(stats)
+		}
+		if cb, ok := w.notifier.(CircuitReporter); ok {
+			body["notifierCircuit"] = circuitHealthBody(cb.State())
+		}
+		ctx.JSON(http.StatusOK, body)
 	})
 
 	// readiness: worker is able to claim + process
@@ -48,7 +79,18 @@ func (w *Worker) HealthHandler(reg *prometheus.Registry) http.Handler {
 			}
 		}
 
-		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+		// a backlog degrades but does not unready: another replica would not
+		// drain it any faster
+		degraded := w.queueDegraded(w.queueStats(c.Request.Context()))
+
+		// paused is still ready: the process is healthy and finishing its
+		// jobs, it just claims nothing until resumed
+		if w.isPaused() {
+			c.JSON(http.StatusOK, gin.H{"status": "paused", "paused": true, "degraded": degraded})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "ready", "paused": false, "degraded": degraded})
 	})
 
 	// Prometheus
@@ -60,3 +102,50 @@ func (w *Worker) HealthHandler(reg *prometheus.Registry) http.Handler {
 
 	return r
 }
+
+// CircuitReporter is implemented by notifiers behind a circuit breaker.
+type CircuitReporter interface {
+	State() notifications.CircuitState
+}
+
+// circuitHealthBody is the notifier circuit part of /healthz.
+func circuitHealthBody(s notifications.CircuitState) gin.H {
+	body := gin.H{
+		"state":                s.State,
+		"consecutiveFailures":  s.ConsecutiveFailures,
+		"untilHalfOpenSeconds": s.UntilHalfOpen.Seconds(),
+	}
+	if !s.OpenedAt.IsZero() {
+		body["openedAt"] = s.OpenedAt.UTC()
+	}
+	return body
+}
+
+// logHealthRequest logs each probe at debug level and counts it by route and
+// status. Unknown paths share one label so scanners cannot grow the series.
+func (w *Worker) logHealthRequest(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	path := c.FullPath()
+	if path == "" {
+		path = "unmatched"
+	}
+	status := c.Writer.Status()
+	if w.metrics != nil {
+		w.metrics.IncHealthRequest(path, status)
+	}
+	slog.Default().DebugContext(c.Request.Context(), "worker.health_request",
+		"path", path,
+		"status", status,
+		"remote_addr", c.Request.RemoteAddr,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
+
+// MountHealth serves the worker health endpoints from an existing router under
+// prefix (e.g. /worker/healthz), for processes that embed the worker.
+func (w *Worker) MountHealth(r gin.IRoutes, prefix string, reg *prometheus.Registry) {
+	h := http.StripPrefix(prefix, w.HealthHandler(reg))
+	r.GET(prefix+"/*path", gin.WrapH(h))
+}
@@ -3,11 +3,18 @@ package worker
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/geocoder89/eventhub/internal/notifications"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestHealthHandlerReadyz(t *testing.T) {
@@ -69,3 +76,127 @@ func TestHealthHandlerReadyz(t *testing.T) {
 		})
 	}
 }
+
+func TestMountHealthServesUnderPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := &Worker{ready: true}
+	r := gin.New()
+	w.MountHealth(r, "/worker", nil)
+
+	for _, path := range []string{"/worker/healthz", "/worker/readyz"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s status=%d want=%d", path, rr.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestServeHealth_ProbesSurviveStalledConnections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := New(Config{HealthMaxConns: 3, ReadinessWindow: time.Millisecond}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil)
+	w.PromRegistry = prometheus.NewRegistry()
+	if err := w.metrics.Register(w.PromRegistry); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.serveHealth(ctx, ln) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	base := "http://" + ln.Addr().String()
+	stall := func() net.Conn {
+		t.Helper()
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		// headers that never finish
+		if _, err := conn.Write([]byte("GET /healthz HTTP/1.1\r\nHost: worker\r\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	probe := func(timeout time.Duration) {
+		t.Helper()
+		client := &http.Client{Timeout: timeout, Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get(base + "/readyz")
+		if err != nil {
+			t.Fatalf("probe: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("probe status=%d", resp.StatusCode)
+		}
+	}
+
+	// stalled clients below the cap leave room for the probe, answered at once
+	slow := stall()
+	stall()
+	probe(time.Second)
+
+	// at the cap the probe waits in the accept queue until the header
+	// timeout drops a stalled client
+	stall()
+	probe(healthReadHeaderTimeout + 2*time.Second)
+
+	_ = slow.SetReadDeadline(time.Now().Add(healthReadHeaderTimeout + 2*time.Second))
+	if _, err := io.ReadAll(slow); err != nil {
+		t.Fatalf("expected the server to close the stalled connection, got %v", err)
+	}
+
+	want := `eventhub_worker_health_requests_total{path="/readyz",status="200"} 2`
+	if err := testutil.GatherAndCompare(w.PromRegistry, strings.NewReader(`
+# HELP eventhub_worker_health_requests_total Requests to the worker health endpoints, by route and response status.
+# TYPE eventhub_worker_health_requests_total counter
+`+want+"\n"), "eventhub_worker_health_requests_total"); err != nil {
+		t.Fatalf("expected the probes counted: %v", err)
+	}
+}
+
+type downNotifier struct{}
+
+func (downNotifier) SendRegistrationConfirmation(ctx context.Context, in notifications.SendRegistrationConfirmationInput) error {
+	return errors.New("provider down")
+}
+
+func (downNotifier) SendEventPublished(ctx context.Context, in notifications.SendEventPublishedInput) error {
+	return errors.New("provider down")
+}
+
+func TestHealthz_EmbedsNotifierCircuit(t *testing.T) {
+	protected := notifications.NewProtectedNotifier(downNotifier{}, notifications.ProtectedNotifierConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	w := &Worker{ready: true, repo: &fakeJobsRepo{}, notifier: protected}
+
+	_, body := getHealth(t, w, "/healthz")
+	circuit, ok := body["notifierCircuit"].(map[string]any)
+	if !ok || circuit["state"] != "closed" || circuit["consecutiveFailures"] != float64(0) || circuit["openedAt"] != nil {
+		t.Fatalf("expected a closed circuit, got %+v", body)
+	}
+
+	_ = protected.SendRegistrationConfirmation(context.Background(), notifications.SendRegistrationConfirmationInput{})
+
+	_, body = getHealth(t, w, "/healthz")
+	circuit, _ = body["notifierCircuit"].(map[string]any)
+	until, _ := circuit["untilHalfOpenSeconds"].(float64)
+	if circuit["state"] != "open" || circuit["consecutiveFailures"] != float64(1) || circuit["openedAt"] == nil || until <= 0 || until > 60 {
+		t.Fatalf("expected the open circuit reported, got %+v", circuit)
+	}
+
+	// a notifier without a breaker reports none
+	w.notifier = downNotifier{}
+	if _, body = getHealth(t, w, "/healthz"); body["notifierCircuit"] != nil {
+		t.Fatalf("expected no circuit, got %+v", body)
+	}
+}
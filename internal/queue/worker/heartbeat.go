@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// LockExtender is implemented by job repos that can renew a claimed job's
+// lock; without it a job must finish within LockTTL or be requeued under it.
+type LockExtender interface {
+	ExtendLock(ctx context.Context, id, workerID string) error
+}
+
+// JobCanceller is implemented by job repos that record admin-cancelled jobs;
+// without it an aborted job is dead-lettered instead. The job must still be
+// locked by workerID, or job.ErrLockLost is returned.
+type JobCanceller interface {
+	MarkCancelled(ctx context.Context, id, workerID string, errMsg string) error
+}
+
+// startHeartbeat renews j's lock every LockTTL/3 while it runs. The returned
+// context is cancelled if the lock is lost, so the handler stops working on a
+// job another worker may now own, or if an admin asked to cancel the job.
+// stop ends the heartbeat and returns job.ErrLockLost or
+// job.ErrCancelRequested if either happened; after a lost lock the job's
+// outcome is not ours to record.
+func (w *Worker) startHeartbeat(ctx context.Context, jobID string) (hbCtx context.Context, stop func() error) {
+	ext, ok := w.repo.(LockExtender)
+	every := w.cfg.LockTTL / 3
+	if !ok || every <= 0 {
+		return ctx, func() error { return nil }
+	}
+
+	hbCtx, cancel := context.WithCancelCause(ctx)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		t := time.NewTicker(every)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-quit:
+				return
+			case <-hbCtx.Done():
+				return
+			case <-t.C:
+			}
+
+			extCtx, cancelExt := context.WithTimeout(hbCtx, every)
+			err := ext.ExtendLock(extCtx, jobID, w.cfg.WorkerID)
+			cancelExt()
+
+			switch {
+			case errors.Is(err, job.ErrLockLost):
+				log.Printf("worker.heartbeat lock lost job=%s worker_id=%s", jobID, w.cfg.WorkerID)
+				cancel(job.ErrLockLost)
+				return
+			case errors.Is(err, job.ErrCancelRequested):
+				log.Printf("worker.heartbeat cancel requested job=%s worker_id=%s", jobID, w.cfg.WorkerID)
+				cancel(job.ErrCancelRequested)
+				return
+			case err != nil && hbCtx.Err() == nil:
+				// the lock is still good until LockTTL; try again next beat
+				log.Printf("worker.heartbeat extend failed job=%s err=%v", jobID, err)
+			}
+		}
+	}()
+
+	return hbCtx, func() error {
+		close(quit)
+		<-done
+		var stopped error
+		if cause := context.Cause(hbCtx); errors.Is(cause, job.ErrLockLost) || errors.Is(cause, job.ErrCancelRequested) {
+			stopped = cause
+		}
+		cancel(nil)
+		return stopped
+	}
+}
@@ -0,0 +1,276 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// leaseJobsRepo models one job's lock like the jobs table: claims stamp
+// locked_at/locked_by, the stale requeue frees expired locks and ExtendLock
+// renews only the holder's lock.
+type leaseJobsRepo struct {
+	fakeJobsRepo
+	mu       sync.Mutex
+	j        job.Job
+	lockedAt time.Time
+	lockedBy string
+	extends  int
+
+	cancelRequested bool
+	cancelledWith   string
+}
+
+func newLeaseJobsRepo(j job.Job) *leaseJobsRepo {
+	j.Status = job.StatusPending
+	return &leaseJobsRepo{j: j}
+}
+
+func (r *leaseJobsRepo) ClaimNext(ctx context.Context, workerID string) (job.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.j.Status != job.StatusPending {
+		return job.Job{}, job.ErrJobNotFound
+	}
+	r.j.Status, r.lockedAt, r.lockedBy = job.StatusProcessing, time.Now(), workerID
+	r.j.Attempts++
+	return r.j, nil
+}
+
+func (r *leaseJobsRepo) RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.j.Status == job.StatusProcessing && time.Since(r.lockedAt) > lockTTL {
+		r.j.Status, r.lockedBy = job.StatusPending, ""
+		return job.StaleRequeue{Requeued: 1}, nil
+	}
+	return job.StaleRequeue{}, nil
+}
+
+func (r *leaseJobsRepo) MarkDone(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.j.Status = job.StatusDone
+	return nil
+}
+
+func (r *leaseJobsRepo) ExtendLock(ctx context.Context, id, workerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.j.Status != job.StatusProcessing || r.lockedBy != workerID {
+		return job.ErrLockLost
+	}
+	r.lockedAt = time.Now()
+	r.extends++
+	if r.cancelRequested {
+		return job.ErrCancelRequested
+	}
+	return nil
+}
+
+func (r *leaseJobsRepo) MarkCancelled(ctx context.Context, id, workerID string, errMsg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lockedBy != workerID {
+		return job.ErrLockLost
+	}
+
+	r.j.Status, r.lockedBy, r.cancelledWith = job.StatusCancelled, "", errMsg
+	return nil
+}
+
+func (r *leaseJobsRepo) status() job.Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.j.Status
+}
+
+// noExtendRepo hides ExtendLock, as a repo without heartbeats would.
+type noExtendRepo struct{ JobsRepository }
+
+// runSlowJobOnTwoWorkers runs a job four LockTTLs long on two workers that
+// both requeue stale locks, returning how many times it executed.
+func runSlowJobOnTwoWorkers(t *testing.T, repo JobsRepository, lease *leaseJobsRepo) int32 {
+	t.Helper()
+
+	const lockTTL = 60 * time.Millisecond
+	var runs atomic.Int32
+	slow := func(ctx context.Context, j job.Job) error {
+		runs.Add(1)
+		select {
+		case <-time.After(4 * lockTTL):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var dones []<-chan error
+	for _, id := range []string{"worker-a", "worker-b"} {
+		w := New(Config{
+			WorkerID:        id,
+			PollInterval:    10 * time.Millisecond,
+			Concurrency:     1,
+			LockTTL:         lockTTL,
+			RequeueInterval: 10 * time.Millisecond,
+		}, repo, &fakeEventsRepo{}, nil, nil).Register("export.big", slow)
+		dones = append(dones, runAsync(ctx, w))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for lease.status() != job.StatusDone && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// give a double claim the chance to show up
+	time.Sleep(2 * lockTTL)
+
+	cancel()
+	for _, done := range dones {
+		if err := <-done; err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	}
+	if lease.status() != job.StatusDone {
+		t.Fatalf("job never finished, status=%s", lease.status())
+	}
+	return runs.Load()
+}
+
+func TestHeartbeat_SlowJobIsNotProcessedTwice(t *testing.T) {
+	repo := newLeaseJobsRepo(job.Job{ID: "job-slow", Type: "export.big", MaxAttempts: 3})
+
+	if runs := runSlowJobOnTwoWorkers(t, repo, repo); runs != 1 {
+		t.Fatalf("expected one execution with heartbeats, got %d", runs)
+	}
+	if repo.extends == 0 {
+		t.Fatalf("expected the lock to be extended while the job ran")
+	}
+}
+
+func TestHeartbeat_WithoutExtendLockSlowJobIsRequeued(t *testing.T) {
+	repo := newLeaseJobsRepo(job.Job{ID: "job-slow", Type: "export.big", MaxAttempts: 3})
+
+	// the failure mode heartbeats exist for
+	if runs := runSlowJobOnTwoWorkers(t, noExtendRepo{repo}, repo); runs < 2 {
+		t.Fatalf("expected the stale requeue to hand the job out again, got %d runs", runs)
+	}
+}
+
+func TestHeartbeat_LostLockCancelsJobAndSkipsBookkeeping(t *testing.T) {
+	repo := newLeaseJobsRepo(job.Job{ID: "job-taken", Type: "export.big", MaxAttempts: 3})
+	var recorded atomic.Int32
+	repo.rescheduleFn = func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+		recorded.Add(1)
+		return nil
+	}
+	repo.markFailedFn = func(ctx context.Context, id string, errMsg string) error {
+		recorded.Add(1)
+		return nil
+	}
+
+	stopped := make(chan error, 1)
+	w := New(Config{WorkerID: "worker-a", PollInterval: 10 * time.Millisecond, Concurrency: 1, LockTTL: 30 * time.Millisecond}, repo, &fakeEventsRepo{}, nil, nil).
+		Register("export.big", func(ctx context.Context, j job.Job) error {
+			// another worker takes the job over mid-run
+			repo.mu.Lock()
+			repo.lockedBy = "worker-b"
+			repo.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				stopped <- context.Cause(ctx)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				stopped <- nil
+				return nil
+			}
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	var cause error
+	select {
+	case cause = <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("handler never ran")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !errors.Is(cause, job.ErrLockLost) {
+		t.Fatalf("expected the job context cancelled with ErrLockLost, got %v", cause)
+	}
+	if recorded.Load() != 0 || repo.status() != job.StatusProcessing {
+		t.Fatalf("the new holder's job must be left alone: recorded=%d status=%s", recorded.Load(), repo.status())
+	}
+}
+
+func TestHeartbeat_CancelRequestAbortsJobWithoutRetry(t *testing.T) {
+	repo := newLeaseJobsRepo(job.Job{ID: "job-cancel", Type: "export.big", MaxAttempts: 3})
+	var retried atomic.Int32
+	repo.rescheduleFn = func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+		retried.Add(1)
+		return nil
+	}
+
+	stopped := make(chan error, 1)
+	w := New(Config{WorkerID: "worker-a", PollInterval: 10 * time.Millisecond, Concurrency: 1, LockTTL: 30 * time.Millisecond}, repo, &fakeEventsRepo{}, nil, nil).
+		Register("export.big", func(ctx context.Context, j job.Job) error {
+			// an admin cancels the job while it runs
+			repo.mu.Lock()
+			repo.cancelRequested = true
+			repo.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				stopped <- context.Cause(ctx)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				stopped <- nil
+				return nil
+			}
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	var cause error
+	select {
+	case cause = <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("handler never ran")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for repo.status() != job.StatusCancelled && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !errors.Is(cause, job.ErrCancelRequested) {
+		t.Fatalf("expected the job context cancelled with ErrCancelRequested, got %v", cause)
+	}
+	if repo.status() != job.StatusCancelled || retried.Load() != 0 {
+		t.Fatalf("expected the job cancelled without a retry: status=%s retried=%d", repo.status(), retried.Load())
+	}
+	if repo.cancelledWith != job.ErrCancelRequested.Error() {
+		t.Fatalf("expected the cancellation recorded as the last error, got %q", repo.cancelledWith)
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// LeaderLock is a lock at most one worker instance holds at a time, so the
+// housekeeping loops run once across replicas instead of once per replica.
+// The holder keeps it until Release or until its connection dies, after which
+// another instance's TryAcquire gets it.
+type LeaderLock interface {
+	// TryAcquire takes the lock when it is free and reports whether this
+	// instance holds it. A holder calling again confirms it still does.
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// WithLeaderLock runs the housekeeping loops (the stale requeue) only on the
+// instance holding lock. Every instance tries for it once per
+// RequeueInterval, so a dead leader is replaced within one interval. Without
+// a lock every instance runs housekeeping.
+func (w *Worker) WithLeaderLock(lock LeaderLock) *Worker {
+	w.leadership = &leadership{lock: lock}
+	return w
+}
+
+type leadership struct {
+	lock LeaderLock
+
+	mu     sync.Mutex
+	leader bool
+}
+
+// housekeepingLeader reports whether this instance should run housekeeping
+// now, trying for the lock when it does not hold it. A failed check counts as
+// not leading; the next tick tries again.
+func (w *Worker) housekeepingLeader(ctx context.Context) bool {
+	l := w.leadership
+	if l == nil {
+		return true
+	}
+
+	lctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+	leader, err := l.lock.TryAcquire(lctx)
+	cancel()
+	if err != nil {
+		log.Printf("worker.housekeeping leader check failed worker_id=%s err=%v", w.cfg.WorkerID, err)
+		leader = false
+	}
+
+	l.mu.Lock()
+	changed := leader != l.leader
+	l.leader = leader
+	l.mu.Unlock()
+
+	if changed {
+		if leader {
+			log.Printf("worker.housekeeping leader acquired worker_id=%s", w.cfg.WorkerID)
+		} else {
+			log.Printf("worker.housekeeping leader lost worker_id=%s", w.cfg.WorkerID)
+		}
+	}
+	if w.metrics != nil {
+		w.metrics.SetHousekeepingLeader(w.cfg.WorkerID, leader)
+	}
+	return leader
+}
+
+// leadsHousekeeping reports whether this instance held the lock at the last
+// housekeepingLeader check, without asking the lock again.
+func (w *Worker) leadsHousekeeping() bool {
+	l := w.leadership
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader
+}
+
+// resignHousekeeping hands the lock back on shutdown so another instance
+// takes over at its next tick rather than after the connection times out.
+func (w *Worker) resignHousekeeping() {
+	l := w.leadership
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	wasLeader := l.leader
+	l.leader = false
+	l.mu.Unlock()
+	if !wasLeader {
+		return
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+	defer cancel()
+	if err := l.lock.Release(rctx); err != nil {
+		log.Printf("worker.housekeeping release failed worker_id=%s err=%v", w.cfg.WorkerID, err)
+	}
+	if w.metrics != nil {
+		w.metrics.SetHousekeepingLeader(w.cfg.WorkerID, false)
+	}
+}
@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// sharedLock stands in for the advisory lock: one holder across the handles
+// made from it.
+type sharedLock struct {
+	mu     sync.Mutex
+	holder string
+}
+
+func (s *sharedLock) handle(id string) *lockHandle {
+	return &lockHandle{shared: s, id: id}
+}
+
+type lockHandle struct {
+	shared *sharedLock
+	id     string
+}
+
+func (h *lockHandle) TryAcquire(ctx context.Context) (bool, error) {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+
+	if h.shared.holder == "" {
+		h.shared.holder = h.id
+	}
+	return h.shared.holder == h.id, nil
+}
+
+func (h *lockHandle) Release(ctx context.Context) error {
+	h.shared.mu.Lock()
+	defer h.shared.mu.Unlock()
+
+	if h.shared.holder == h.id {
+		h.shared.holder = ""
+	}
+	return nil
+}
+
+func newHousekeepingWorker(id string, lock *sharedLock, requeues *atomic.Int64) *Worker {
+	repo := &fakeJobsRepo{
+		requeueStaleProcessingFn: func(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error) {
+			requeues.Add(1)
+			return job.StaleRequeue{}, nil
+		},
+	}
+	w := New(Config{
+		PollInterval:       10 * time.Millisecond,
+		WorkerID:           id,
+		RequeueInterval:    5 * time.Millisecond,
+		MetricsLogInterval: time.Hour,
+	}, repo, &fakeEventsRepo{}, nil, nil).WithLeaderLock(lock.handle(id))
+	w.clock = newFakeClock()
+	return w
+}
+
+func TestHousekeepingLeader_OneInstancePerTick(t *testing.T) {
+	lock := &sharedLock{}
+	var unused atomic.Int64
+	a := newHousekeepingWorker("w-a", lock, &unused)
+	b := newHousekeepingWorker("w-b", lock, &unused)
+	ctx := context.Background()
+
+	for tick := 0; tick < 3; tick++ {
+		if !a.housekeepingLeader(ctx) {
+			t.Fatalf("tick %d: expected w-a to lead", tick)
+		}
+		if b.housekeepingLeader(ctx) {
+			t.Fatalf("tick %d: expected w-b to skip housekeeping", tick)
+		}
+	}
+
+	a.resignHousekeeping()
+
+	if !b.housekeepingLeader(ctx) {
+		t.Fatalf("expected w-b to take over on the tick after w-a resigned")
+	}
+	if a.housekeepingLeader(ctx) {
+		t.Fatalf("expected w-a to stay out while w-b leads")
+	}
+}
+
+func TestHousekeepingLeader_NoLockAlwaysLeads(t *testing.T) {
+	w := &Worker{}
+	if !w.housekeepingLeader(context.Background()) {
+		t.Fatalf("expected a worker without a leader lock to run housekeeping")
+	}
+	w.resignHousekeeping()
+}
+
+func TestRun_OnlyLeaderRequeuesAndStandbyTakesOver(t *testing.T) {
+	lock := &sharedLock{}
+	var requeuesA, requeuesB atomic.Int64
+
+	a := newHousekeepingWorker("w-a", lock, &requeuesA)
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := runAsync(ctxA, a)
+
+	// let w-a take the lock before w-b starts contending
+	deadline := time.Now().Add(time.Second)
+	for requeuesA.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("w-a never ran housekeeping")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	b := newHousekeepingWorker("w-b", lock, &requeuesB)
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	doneB := runAsync(ctxB, b)
+
+	time.Sleep(50 * time.Millisecond)
+	if n := requeuesB.Load(); n != 0 {
+		t.Fatalf("standby w-b ran housekeeping %d times while w-a led", n)
+	}
+
+	cancelA()
+	if err := waitRun(t, doneA); err != nil {
+		t.Fatalf("Run w-a: %v", err)
+	}
+
+	deadline = time.Now().Add(time.Second)
+	for requeuesB.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("w-b never took over housekeeping after w-a stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelB()
+	if err := waitRun(t, doneB); err != nil {
+		t.Fatalf("Run w-b: %v", err)
+	}
+}
+
+func TestLogMetrics_OnlyLeaderReadsQueueStats(t *testing.T) {
+	lock := &sharedLock{}
+	var unused atomic.Int64
+	ctx := context.Background()
+
+	newWorker := func(id string) (*Worker, *statsJobsRepo) {
+		w := newHousekeepingWorker(id, lock, &unused)
+		repo := &statsJobsRepo{fakeJobsRepo: w.repo.(*fakeJobsRepo)}
+		w.repo = repo
+		return w, repo
+	}
+	a, aRepo := newWorker("w-a")
+	b, bRepo := newWorker("w-b")
+
+	a.housekeepingLeader(ctx)
+	b.housekeepingLeader(ctx)
+	a.logMetrics(ctx)
+	b.logMetrics(ctx)
+
+	if aRepo.calls != 1 || bRepo.calls != 0 {
+		t.Fatalf("expected only the leader to read the queue, got leader=%d standby=%d", aRepo.calls, bRepo.calls)
+	}
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// releasingJobsRepo is a fakeJobsRepo that can hand jobs back.
+type releasingJobsRepo struct {
+	*fakeJobsRepo
+	released []string
+}
+
+func (r *releasingJobsRepo) Release(ctx context.Context, id, workerID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.released = append(r.released, id)
+	return nil
+}
+
+func TestRunWorker_ShutdownReleasesTheJobWithoutSpendingAnAttempt(t *testing.T) {
+	repo := &releasingJobsRepo{fakeJobsRepo: &fakeJobsRepo{
+		rescheduleFn: func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+			t.Fatalf("a job cut short by shutdown must not be rescheduled as a retry")
+			return nil
+		},
+		markFailedFn: func(ctx context.Context, id string, errMsg string) error {
+			t.Fatalf("a job cut short by shutdown must not be failed")
+			return nil
+		},
+	}}
+
+	reg := prometheus.NewRegistry()
+	prom := observability.NewProm(reg)
+	w := New(Config{Prom: prom, WorkerID: "w-test", JobTimeout: time.Minute}, repo, &fakeEventsRepo{}, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Register("export.big", func(jctx context.Context, j job.Job) error {
+		// shutdown arrives mid-job
+		cancel()
+		<-jctx.Done()
+		return jctx.Err()
+	})
+
+	jobsCh := make(chan job.Job, 1)
+	jobsCh <- job.Job{ID: "job-1", Type: "export.big", Attempts: 1, MaxAttempts: 3}
+	close(jobsCh)
+	w.runWorker(ctx, 1, jobsCh)
+
+	if len(repo.released) != 1 || repo.released[0] != "job-1" {
+		t.Fatalf("expected job-1 released, got %v", repo.released)
+	}
+	if got := testutil.ToFloat64(prom.JobResults.WithLabelValues("export.big", "interrupted")); got != 1 {
+		t.Fatalf("expected one interrupted result, got %v", got)
+	}
+	if s := w.metrics.Snapshot(); s.Retried != 0 || s.Failed != 0 {
+		t.Fatalf("an interrupted job counted as a failure: %+v", s)
+	}
+}
+
+func TestRunWorker_HandlerDeadlineIsStillAFailure(t *testing.T) {
+	var retried string
+	repo := &releasingJobsRepo{fakeJobsRepo: &fakeJobsRepo{
+		rescheduleFn: func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+			retried = id
+			return nil
+		},
+	}}
+
+	w := New(Config{WorkerID: "w-test", JobTimeout: time.Minute}, repo, &fakeEventsRepo{}, nil, nil)
+	w.Register("notify.slow", func(jctx context.Context, j job.Job) error {
+		// the handler's own call budget runs out while the worker is running
+		cctx, cancel := context.WithTimeout(jctx, time.Millisecond)
+		defer cancel()
+		<-cctx.Done()
+		return cctx.Err()
+	})
+
+	jobsCh := make(chan job.Job, 1)
+	jobsCh <- job.Job{ID: "job-2", Type: "notify.slow", MaxAttempts: 3}
+	close(jobsCh)
+	w.runWorker(context.Background(), 1, jobsCh)
+
+	if len(repo.released) != 0 {
+		t.Fatalf("a handler timeout must not release the job, released %v", repo.released)
+	}
+	if retried != "job-2" {
+		t.Fatalf("expected job-2 retried, got %q", retried)
+	}
+	if s := w.metrics.Snapshot(); s.Retried != 1 {
+		t.Fatalf("expected retried=1, got %+v", s)
+	}
+}
@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// JobsPurger deletes one batch of old terminal jobs.
+type JobsPurger interface {
+	PurgeTerminal(ctx context.Context, olderThan time.Duration, statuses []string, limit int) (int64, error)
+}
+
+// WithJobsPurge enables the jobs.purge job, which deletes old finished jobs in
+// batches. It does not reschedule itself: create a schedule for it.
+func (w *Worker) WithJobsPurge(purger JobsPurger) *Worker {
+	w.jobsPurger = purger
+	return w.Register(jobs.TypeJobsPurge, w.purgeJobs)
+}
+
+func (w *Worker) purgeJobs(ctx context.Context, j job.Job) error {
+	if w.jobsPurger == nil {
+		return fmt.Errorf("jobs purge not configured")
+	}
+
+	var p jobs.JobsPurgePayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+	}
+	if len(p.Statuses) == 0 {
+		p.Statuses = []string{string(job.StatusDone)}
+	}
+	if p.OlderThanDays <= 0 {
+		p.OlderThanDays = jobs.DefaultPurgeOlderThanDays
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = jobs.DefaultPurgeBatchSize
+	}
+	olderThan := time.Duration(p.OlderThanDays) * 24 * time.Hour
+
+	var res jobs.JobsPurgeResult
+	for ctx.Err() == nil {
+		n, err := w.jobsPurger.PurgeTerminal(ctx, olderThan, p.Statuses, p.BatchSize)
+		if errors.Is(err, job.ErrNotPurgeable) {
+			// a schedule asking for pending jobs will not get better on retry
+			return jobs.NonRetryable(err)
+		}
+		if err != nil {
+			return err
+		}
+		res.Batches++
+		res.Deleted += n
+
+		if n < int64(p.BatchSize) {
+			break
+		}
+	}
+
+	log.Printf("jobs.purge deleted=%d batches=%d statuses=%v older_than_days=%d job=%s", res.Deleted, res.Batches, p.Statuses, p.OlderThanDays, j.ID)
+
+	if rw, ok := w.repo.(JobResultWriter); ok {
+		raw, err := json.Marshal(res)
+		if err == nil {
+			err = rw.SetResult(ctx, j.ID, raw)
+		}
+		if err != nil {
+			log.Printf("jobs.purge: store result failed job=%s err=%v", j.ID, err)
+		}
+	}
+
+	return ctx.Err()
+}
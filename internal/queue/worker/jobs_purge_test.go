@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type fakeJobsPurger struct {
+	batches   []int64
+	calls     int
+	statuses  []string
+	olderThan time.Duration
+	err       error
+}
+
+func (f *fakeJobsPurger) PurgeTerminal(ctx context.Context, olderThan time.Duration, statuses []string, limit int) (int64, error) {
+	f.statuses, f.olderThan = statuses, olderThan
+	if f.err != nil {
+		return 0, f.err
+	}
+	n := f.batches[f.calls]
+	f.calls++
+	return n, nil
+}
+
+func TestPurgeJobs_LoopsUntilAShortBatch(t *testing.T) {
+	purger := &fakeJobsPurger{batches: []int64{2, 2, 1, 0}}
+	w := New(Config{}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).WithJobsPurge(purger)
+
+	err := w.purgeJobs(context.Background(), job.Job{ID: "job-1", Payload: []byte(`{"batchSize":2}`)})
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purger.calls != 3 {
+		t.Fatalf("expected to stop after the short batch, got %d calls", purger.calls)
+	}
+	if len(purger.statuses) != 1 || purger.statuses[0] != "done" || purger.olderThan != 30*24*time.Hour {
+		t.Fatalf("expected the done/30 day defaults, got %v %s", purger.statuses, purger.olderThan)
+	}
+}
+
+func TestPurgeJobs_NonTerminalStatusIsNotRetried(t *testing.T) {
+	purger := &fakeJobsPurger{err: job.ErrNotPurgeable}
+	w := New(Config{}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).WithJobsPurge(purger)
+
+	err := w.purgeJobs(context.Background(), job.Job{ID: "job-1", Payload: []byte(`{"statuses":["pending"]}`)})
+	if !errors.Is(err, jobs.ErrNonRetryable) {
+		t.Fatalf("expected a non-retryable error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// RegistrationUserLinker links one batch of registrations to the verified
+// accounts that share their email.
+type RegistrationUserLinker interface {
+	LinkUsersBatch(ctx context.Context, afterID string, limit int) (registration.UserLinkBatch, error)
+}
+
+// WithRegistrationLinking enables the registrations.link_users job, a
+// one-off backfill of registrations.user_id. New verifications are linked as
+// they happen, so it only needs running for rows from before that.
+func (w *Worker) WithRegistrationLinking(linker RegistrationUserLinker) *Worker {
+	w.userLinker = linker
+	return w.RegisterWithProgress(jobs.TypeRegistrationsLinkUsers, w.linkRegistrationUsers)
+}
+
+func (w *Worker) linkRegistrationUsers(ctx context.Context, j job.Job, report ProgressReporter) error {
+	if w.userLinker == nil {
+		return fmt.Errorf("registration linking not configured")
+	}
+
+	var p jobs.RegistrationsLinkUsersPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = jobs.DefaultLinkUsersBatchSize
+	}
+
+	var res jobs.RegistrationsLinkUsersResult
+	afterID := "00000000-0000-0000-0000-000000000000"
+	for ctx.Err() == nil {
+		batch, err := w.userLinker.LinkUsersBatch(ctx, afterID, p.BatchSize)
+		if err != nil {
+			return err
+		}
+		res.Batches++
+		res.Scanned += batch.Scanned
+		res.Linked += batch.Linked
+		res.Conflicts += len(batch.Conflicts)
+
+		// two accounts could claim these; which one is a person's call
+		for _, id := range batch.Conflicts {
+			slog.Default().WarnContext(ctx, "registrations.link_users.conflict",
+				"job_id", j.ID,
+				"registration_id", id,
+			)
+		}
+
+		report(int64(res.Scanned), 0, "linked "+strconv.FormatInt(res.Linked, 10)+", conflicts "+strconv.Itoa(res.Conflicts))
+
+		if batch.Scanned < p.BatchSize || batch.LastID == "" {
+			break
+		}
+		afterID = batch.LastID
+	}
+
+	slog.Default().InfoContext(ctx, "registrations.link_users.done",
+		"job_id", j.ID,
+		"scanned", res.Scanned,
+		"linked", res.Linked,
+		"conflicts", res.Conflicts,
+		"batches", res.Batches,
+	)
+
+	if rw, ok := w.repo.(JobResultWriter); ok {
+		raw, err := json.Marshal(res)
+		if err == nil {
+			err = rw.SetResult(ctx, j.ID, raw)
+		}
+		if err != nil {
+			slog.Default().WarnContext(ctx, "registrations.link_users.store_result_failed", "job_id", j.ID, "err", err)
+		}
+	}
+
+	return ctx.Err()
+}
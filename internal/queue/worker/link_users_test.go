@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+)
+
+type fakeUserLinker struct {
+	batches []registration.UserLinkBatch
+	after   []string
+}
+
+func (f *fakeUserLinker) LinkUsersBatch(ctx context.Context, afterID string, limit int) (registration.UserLinkBatch, error) {
+	f.after = append(f.after, afterID)
+	b := f.batches[len(f.after)-1]
+	return b, nil
+}
+
+func TestLinkRegistrationUsers_PagesPastConflicts(t *testing.T) {
+	linker := &fakeUserLinker{batches: []registration.UserLinkBatch{
+		{Scanned: 2, Linked: 1, Conflicts: []string{"r-2"}, LastID: "r-2"},
+		{Scanned: 2, Linked: 2, LastID: "r-4"},
+		{Scanned: 1, Linked: 0, LastID: "r-5"},
+	}}
+	w := New(Config{}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).WithRegistrationLinking(linker)
+
+	var reports []int64
+	err := w.linkRegistrationUsers(context.Background(), job.Job{ID: "job-1", Payload: []byte(`{"batchSize":2}`)}, func(done, total int64, message string) {
+		reports = append(reports, done)
+	})
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+
+	// the conflict is passed over, not retried on every batch
+	want := []string{"00000000-0000-0000-0000-000000000000", "r-2", "r-4"}
+	if len(linker.after) != len(want) {
+		t.Fatalf("expected %d batches, got %v", len(want), linker.after)
+	}
+	for i := range want {
+		if linker.after[i] != want[i] {
+			t.Fatalf("batch %d started after %q, want %q", i, linker.after[i], want[i])
+		}
+	}
+	if len(reports) != 3 || reports[2] != 5 {
+		t.Fatalf("expected progress after each batch up to 5 scanned, got %v", reports)
+	}
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+)
+
+// OwnedJobsReleaser is implemented by job repos that can hand back every job
+// still processing under a worker id. Without it orphans wait for the stale
+// requeue.
+type OwnedJobsReleaser interface {
+	ReleaseOwnedBy(ctx context.Context, workerID string) (int64, error)
+}
+
+// releaseOrphans runs once as Run starts. Worker ids are host and pid, which
+// repeat across container restarts, so a job locked under ours belongs to a
+// run that died with the previous process; releasing it now saves waiting
+// out LockTTL. Attempts are left alone, as the run never got to finish.
+func (w *Worker) releaseOrphans(ctx context.Context) {
+	r, ok := w.repo.(OwnedJobsReleaser)
+	if !ok {
+		return
+	}
+
+	rctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+	n, err := r.ReleaseOwnedBy(rctx, w.cfg.WorkerID)
+	if err != nil {
+		// not fatal: the stale requeue still gets them after LockTTL
+		slog.Default().WarnContext(ctx, "worker.orphans_release_failed", "worker_id", w.cfg.WorkerID, "err", err)
+		return
+	}
+	if n == 0 {
+		return
+	}
+
+	if w.metrics != nil {
+		w.metrics.AddOrphansReleased(n)
+	}
+	slog.Default().InfoContext(ctx, "worker.orphans_released", "worker_id", w.cfg.WorkerID, "released", n)
+}
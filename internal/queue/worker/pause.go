@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// QueuePauseReader is implemented by job repos that store the admin's
+// pause switch. Workers on other repos never pause.
+type QueuePauseReader interface {
+	IsPaused(ctx context.Context) (bool, error)
+}
+
+// PausedTypesReader is implemented by job repos that can pause job types on
+// their own. Workers on other repos claim every type.
+type PausedTypesReader interface {
+	PausedTypes(ctx context.Context) ([]job.PausedType, error)
+}
+
+// pauseCacheTTL is how long a read of the switch is trusted, so a poll every
+// PollInterval does not cost a query each; a pause takes effect within it.
+const pauseCacheTTL = 5 * time.Second
+
+type pauseState struct {
+	mu        sync.Mutex
+	paused    bool
+	types     []string
+	checkedAt time.Time
+}
+
+// queuePaused reports whether claiming is paused, reading the switch and the
+// paused types at most once per pauseCacheTTL. A failed read keeps the last
+// known state.
+func (w *Worker) queuePaused(ctx context.Context) bool {
+	ps := &w.pause
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	now := w.now()
+	if !ps.checkedAt.IsZero() && now.Sub(ps.checkedAt) < pauseCacheTTL {
+		return ps.paused
+	}
+	if reader, ok := w.repo.(PausedTypesReader); ok {
+		cctx, cancel := context.WithTimeout(ctx, time.Second)
+		paused, err := reader.PausedTypes(cctx)
+		cancel()
+		if err != nil {
+			log.Printf("worker: paused types check failed; keeping types=%v err=%v", ps.types, err)
+		} else {
+			types := make([]string, 0, len(paused))
+			for _, p := range paused {
+				types = append(types, p.Type)
+			}
+			if !slices.Equal(types, ps.types) {
+				log.Printf("worker: paused types=%v worker_id=%s", types, w.cfg.WorkerID)
+			}
+			ps.types = types
+		}
+	}
+
+	if reader, ok := w.repo.(QueuePauseReader); ok {
+		cctx, cancel := context.WithTimeout(ctx, time.Second)
+		paused, err := reader.IsPaused(cctx)
+		cancel()
+		if err != nil {
+			log.Printf("worker: queue pause check failed; keeping paused=%t err=%v", ps.paused, err)
+			return ps.paused
+		}
+
+		if paused != ps.paused {
+			log.Printf("worker: queue paused=%t worker_id=%s", paused, w.cfg.WorkerID)
+		}
+		ps.paused = paused
+		if w.metrics != nil {
+			w.metrics.SetQueuePaused(paused)
+		}
+	}
+
+	ps.checkedAt = now
+	return ps.paused
+}
+
+// pausedTypes is the paused job types as of the last queuePaused read,
+// sorted by name.
+func (w *Worker) pausedTypes() []string {
+	w.pause.mu.Lock()
+	defer w.pause.mu.Unlock()
+	return w.pause.types
+}
+
+// isPaused is the last known state, for readiness.
+func (w *Worker) isPaused() bool {
+	w.pause.mu.Lock()
+	defer w.pause.mu.Unlock()
+	return w.pause.paused
+}
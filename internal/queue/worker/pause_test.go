@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/gin-gonic/gin"
+)
+
+type pausableJobsRepo struct {
+	*fakeJobsRepo
+	paused atomic.Bool
+	err    error
+	reads  atomic.Int32
+}
+
+func (r *pausableJobsRepo) IsPaused(ctx context.Context) (bool, error) {
+	r.reads.Add(1)
+	return r.paused.Load(), r.err
+}
+
+func TestQueuePaused_CachesTheSwitch(t *testing.T) {
+	clk := newFakeClock()
+	clk.now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &pausableJobsRepo{fakeJobsRepo: &fakeJobsRepo{}}
+	repo.paused.Store(true)
+
+	w := New(Config{}, repo, &fakeEventsRepo{}, nil, nil)
+	w.clock = clk
+	ctx := context.Background()
+
+	if !w.queuePaused(ctx) || repo.reads.Load() != 1 {
+		t.Fatalf("expected the first check to read the switch")
+	}
+
+	// resumed, but the cached pause holds until the TTL runs out
+	repo.paused.Store(false)
+	clk.now = clk.now.Add(pauseCacheTTL - time.Second)
+	if !w.queuePaused(ctx) || repo.reads.Load() != 1 {
+		t.Fatalf("expected the cached state within the TTL, reads=%d", repo.reads.Load())
+	}
+
+	clk.now = clk.now.Add(2 * time.Second)
+	if w.queuePaused(ctx) || repo.reads.Load() != 2 {
+		t.Fatalf("expected a fresh read after the TTL, reads=%d", repo.reads.Load())
+	}
+
+	// a failed read keeps the last known state
+	repo.paused.Store(true)
+	repo.err = errors.New("db down")
+	clk.now = clk.now.Add(pauseCacheTTL)
+	if w.queuePaused(ctx) {
+		t.Fatalf("expected a failed read to keep running")
+	}
+}
+
+func TestProcessLoop_ClaimsNothingWhilePaused(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var claims atomic.Int32
+	repo := &pausableJobsRepo{fakeJobsRepo: &fakeJobsRepo{
+		claimNextFn: func(ctx context.Context, workerID string) (job.Job, error) {
+			claims.Add(1)
+			return job.Job{}, job.ErrJobNotFound
+		},
+	}}
+	repo.paused.Store(true)
+
+	w := New(Config{Concurrency: 2, PollInterval: 5 * time.Millisecond, ShutdownGrace: time.Second}, repo, &fakeEventsRepo{}, nil, nil)
+	w.setReady(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	w.processLoop(ctx)
+
+	if n := claims.Load(); n != 0 {
+		t.Fatalf("expected no claims while paused, got %d", n)
+	}
+
+	w.setReady(true)
+	rec := httptest.NewRecorder()
+	w.HealthHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"paused":true`) {
+		t.Fatalf("expected readyz to report paused, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+// typePausingJobsRepo pauses types and records what claims excluded.
+type typePausingJobsRepo struct {
+	fakeJobsRepo
+	mu       sync.Mutex
+	paused   []job.PausedType
+	reads    int
+	excluded [][]string
+}
+
+func (r *typePausingJobsRepo) PausedTypes(ctx context.Context) ([]job.PausedType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	return r.paused, nil
+}
+
+func (r *typePausingJobsRepo) ClaimBatch(ctx context.Context, workerID string, n int) ([]job.Job, error) {
+	return r.ClaimBatchExcluding(ctx, workerID, n, nil)
+}
+
+func (r *typePausingJobsRepo) ClaimBatchExcluding(ctx context.Context, workerID string, n int, excludeTypes []string) ([]job.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.excluded = append(r.excluded, excludeTypes)
+	return nil, nil
+}
+
+func TestClaim_SkipsPausedTypes(t *testing.T) {
+	clk := newFakeClock()
+	clk.now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &typePausingJobsRepo{paused: []job.PausedType{{Type: "registration.confirmation"}}}
+	w := New(Config{TypeConcurrency: map[string]int{"crm.sync": 1}}, repo, &fakeEventsRepo{}, nil, nil)
+	w.clock = clk
+	ctx := context.Background()
+
+	w.queuePaused(ctx)
+	if !w.slots.acquire("crm.sync") {
+		t.Fatalf("expected a free crm.sync slot")
+	}
+	if _, err := w.claimUpTo(ctx, 2); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	// resumed: the cached set holds until the next read, one TTL later
+	repo.mu.Lock()
+	repo.paused = nil
+	repo.mu.Unlock()
+	w.queuePaused(ctx)
+	w.slots.release("crm.sync")
+	if _, err := w.claimUpTo(ctx, 2); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	clk.now = clk.now.Add(pauseCacheTTL)
+	w.queuePaused(ctx)
+	if _, err := w.claimUpTo(ctx, 2); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	want := [][]string{{"crm.sync", "registration.confirmation"}, {"registration.confirmation"}, nil}
+	if len(repo.excluded) != len(want) {
+		t.Fatalf("excluded = %v, want %v", repo.excluded, want)
+	}
+	for i := range want {
+		if !slices.Equal(repo.excluded[i], want[i]) {
+			t.Fatalf("claim %d excluded %v, want %v", i, repo.excluded[i], want[i])
+		}
+	}
+	if repo.reads != 2 {
+		t.Fatalf("expected the paused set read once per TTL, got %d reads", repo.reads)
+	}
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+const (
+	defaultPayloadReportLimit = 100
+	maxPayloadReportLimit     = 1000
+)
+
+// OversizedPayloadFinder lists stored jobs over a payload size.
+type OversizedPayloadFinder interface {
+	OversizedPayloads(ctx context.Context, maxBytes, limit int) ([]job.OversizedPayload, error)
+}
+
+type payloadReporter struct {
+	finder   OversizedPayloadFinder
+	maxBytes int
+}
+
+// WithPayloadReport enables the on-demand jobs.payload_report job, which lists
+// jobs whose payload is over maxBytes (the enqueue limit) without touching them.
+func (w *Worker) WithPayloadReport(finder OversizedPayloadFinder, maxBytes int) *Worker {
+	w.payloadReport = &payloadReporter{finder: finder, maxBytes: maxBytes}
+	return w.Register(jobs.TypeJobsPayloadReport, w.reportPayloads)
+}
+
+func (w *Worker) reportPayloads(ctx context.Context, j job.Job) error {
+	if w.payloadReport == nil {
+		return fmt.Errorf("payload report not configured")
+	}
+
+	var p jobs.JobsPayloadReportPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+	}
+	if p.MaxBytes <= 0 {
+		p.MaxBytes = w.payloadReport.maxBytes
+	}
+	if p.MaxBytes <= 0 {
+		p.MaxBytes = job.DefaultPayloadLimits.MaxBytes
+	}
+	if p.Limit <= 0 {
+		p.Limit = defaultPayloadReportLimit
+	}
+	p.Limit = min(p.Limit, maxPayloadReportLimit)
+
+	// one extra row tells a full page from a truncated one
+	offenders, err := w.payloadReport.finder.OversizedPayloads(ctx, p.MaxBytes, p.Limit+1)
+	if err != nil {
+		return err
+	}
+
+	report := job.PayloadReport{MaxBytes: p.MaxBytes, Offenders: offenders}
+	if len(offenders) > p.Limit {
+		report.Offenders, report.Truncated = offenders[:p.Limit], true
+	}
+
+	log.Printf("jobs.payload_report max_bytes=%d offenders=%d truncated=%t job=%s", p.MaxBytes, len(report.Offenders), report.Truncated, j.ID)
+
+	if rw, ok := w.repo.(JobResultWriter); ok {
+		raw, err := json.Marshal(report)
+		if err == nil {
+			err = rw.SetResult(ctx, j.ID, raw)
+		}
+		if err != nil {
+			return fmt.Errorf("store report: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type fakePayloadFinder struct {
+	rows     []job.OversizedPayload
+	maxBytes int
+	limit    int
+}
+
+func (f *fakePayloadFinder) OversizedPayloads(ctx context.Context, maxBytes, limit int) ([]job.OversizedPayload, error) {
+	f.maxBytes, f.limit = maxBytes, limit
+	return f.rows[:min(limit, len(f.rows))], nil
+}
+
+func runPayloadReport(t *testing.T, finder *fakePayloadFinder, p jobs.JobsPayloadReportPayload) job.PayloadReport {
+	t.Helper()
+
+	repo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+	w := &Worker{repo: repo}
+	w.WithPayloadReport(finder, 4096)
+
+	raw, _ := p.JSON()
+	j := job.Job{ID: "job-report", Type: jobs.TypeJobsPayloadReport, Payload: raw}
+	if err := w.execute(context.Background(), j); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var got job.PayloadReport
+	if err := json.Unmarshal(repo.results[j.ID], &got); err != nil {
+		t.Fatalf("decode stored report: %v (%s)", err, repo.results[j.ID])
+	}
+	return got
+}
+
+func TestPayloadReport_ListsOffendersWithoutChangingThem(t *testing.T) {
+	finder := &fakePayloadFinder{rows: []job.OversizedPayload{
+		{ID: "a", Type: "event.publish", Status: job.StatusFailed, Size: 9000},
+		{ID: "b", Type: "webhook.deliver", Status: job.StatusPending, Size: 5000},
+	}}
+
+	got := runPayloadReport(t, finder, jobs.JobsPayloadReportPayload{})
+
+	if finder.maxBytes != 4096 {
+		t.Fatalf("expected the worker's limit by default, got %d", finder.maxBytes)
+	}
+	if got.MaxBytes != 4096 || got.Truncated || len(got.Offenders) != 2 {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+	if got.Offenders[0].ID != "a" || got.Offenders[0].Size != 9000 || got.Offenders[1].Status != job.StatusPending {
+		t.Fatalf("offenders not reported as found: %+v", got.Offenders)
+	}
+}
+
+func TestPayloadReport_TruncatesAtLimit(t *testing.T) {
+	finder := &fakePayloadFinder{rows: []job.OversizedPayload{
+		{ID: "a", Size: 300}, {ID: "b", Size: 200}, {ID: "c", Size: 150},
+	}}
+
+	got := runPayloadReport(t, finder, jobs.JobsPayloadReportPayload{MaxBytes: 100, Limit: 2})
+
+	if finder.maxBytes != 100 || finder.limit != 3 {
+		t.Fatalf("expected maxBytes=100 and one row past the limit, got %d/%d", finder.maxBytes, finder.limit)
+	}
+	if !got.Truncated || len(got.Offenders) != 2 || got.Offenders[1].ID != "b" {
+		t.Fatalf("expected the two largest and truncated, got %+v", got)
+	}
+
+	got = runPayloadReport(t, finder, jobs.JobsPayloadReportPayload{MaxBytes: 100, Limit: 3})
+	if got.Truncated || len(got.Offenders) != 3 {
+		t.Fatalf("an exactly full page is not truncated, got %+v", got)
+	}
+}
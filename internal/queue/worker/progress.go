@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// JobProgressWriter is implemented by job repos that can store how far a
+// running job has got. Without it progress reports are dropped.
+type JobProgressWriter interface {
+	UpdateProgress(ctx context.Context, id string, p job.Progress) error
+}
+
+// ProgressReporter records how far the running job has got; total is 0 when
+// the job cannot tell. A report never fails the job: one that cannot be
+// stored is logged and dropped.
+type ProgressReporter func(done, total int64, message string)
+
+// ProgressHandlerFunc is a HandlerFunc for long-running jobs, given a
+// reporter for the job it runs.
+type ProgressHandlerFunc func(ctx context.Context, j job.Job, report ProgressReporter) error
+
+// RegisterWithProgress adds a handler for jobType that reports its progress,
+// shown on the admin job detail. Same rules as Register.
+func (r *HandlerRegistry) RegisterWithProgress(jobType string, fn ProgressHandlerFunc) {
+	if fn == nil {
+		panic("worker: RegisterWithProgress needs a handler")
+	}
+	r.Register(jobType, func(ctx context.Context, j job.Job) error {
+		return fn(ctx, j, progressReporterFrom(ctx))
+	})
+}
+
+// RegisterWithProgress adds a progress-reporting handler for a custom job
+// type; see HandlerRegistry.RegisterWithProgress.
+func (w *Worker) RegisterWithProgress(jobType string, fn ProgressHandlerFunc) *Worker {
+	w.Handlers().RegisterWithProgress(jobType, fn)
+	return w
+}
+
+type progressReporterKey struct{}
+
+func withProgressReporter(ctx context.Context, report ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, report)
+}
+
+// progressReporterFrom returns the reporter execute put on ctx, or one that
+// drops every report when the handler runs outside a worker.
+func progressReporterFrom(ctx context.Context) ProgressReporter {
+	if report, ok := ctx.Value(progressReporterKey{}).(ProgressReporter); ok {
+		return report
+	}
+	return func(done, total int64, message string) {}
+}
+
+// progressReporter writes j's reports through the repo, which keeps them to
+// one write per second per job.
+func (w *Worker) progressReporter(ctx context.Context, j job.Job) ProgressReporter {
+	pw, ok := w.repo.(JobProgressWriter)
+	if !ok {
+		return func(done, total int64, message string) {}
+	}
+
+	return func(done, total int64, message string) {
+		pctx, cancel := bookkeepingContext(ctx)
+		defer cancel()
+
+		p := job.Progress{Done: done, Total: total, Message: message}
+		if err := pw.UpdateProgress(pctx, j.ID, p); err != nil {
+			slog.Default().WarnContext(ctx, "job.progress_failed",
+				"worker_id", w.cfg.WorkerID,
+				"job_id", j.ID,
+				"job_type", j.Type,
+				"err", err,
+			)
+		}
+	}
+}
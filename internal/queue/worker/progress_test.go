@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+type progressJobsRepo struct {
+	*fakeJobsRepo
+
+	mu  sync.Mutex
+	ids []string
+	got []job.Progress
+	err error
+}
+
+func (r *progressJobsRepo) UpdateProgress(ctx context.Context, id string, p job.Progress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ids = append(r.ids, id)
+	r.got = append(r.got, p)
+	return r.err
+}
+
+func TestRegisterWithProgress_ReportsThroughRepo(t *testing.T) {
+	repo := &progressJobsRepo{fakeJobsRepo: &fakeJobsRepo{}}
+	w := (&Worker{repo: repo}).RegisterWithProgress("archive.build", func(ctx context.Context, j job.Job, report ProgressReporter) error {
+		report(1200, 54000, "rows")
+		report(54000, 54000, "done")
+		return nil
+	})
+
+	if err := w.execute(context.Background(), job.Job{ID: "job-archive", Type: "archive.build"}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if len(repo.got) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(repo.got))
+	}
+	if repo.ids[0] != "job-archive" || repo.got[0].Done != 1200 || repo.got[0].Total != 54000 || repo.got[0].Message != "rows" {
+		t.Fatalf("unexpected first report %s %+v", repo.ids[0], repo.got[0])
+	}
+	if !repo.got[1].Finished() {
+		t.Fatalf("expected the last report to finish the work, got %+v", repo.got[1])
+	}
+}
+
+func TestRegisterWithProgress_FailedWriteDoesNotFailJob(t *testing.T) {
+	repo := &progressJobsRepo{fakeJobsRepo: &fakeJobsRepo{}, err: errors.New("db down")}
+	w := (&Worker{repo: repo}).RegisterWithProgress("archive.build", func(ctx context.Context, j job.Job, report ProgressReporter) error {
+		report(1, 2, "")
+		return nil
+	})
+
+	if err := w.execute(context.Background(), job.Job{ID: "job-archive", Type: "archive.build"}); err != nil {
+		t.Fatalf("expected the job to succeed despite the failed report, got %v", err)
+	}
+}
+
+func TestRegisterWithProgress_RepoWithoutProgressDropsReports(t *testing.T) {
+	ran := false
+	w := (&Worker{repo: &fakeJobsRepo{}}).RegisterWithProgress("archive.build", func(ctx context.Context, j job.Job, report ProgressReporter) error {
+		report(1, 2, "")
+		ran = true
+		return nil
+	})
+
+	if err := w.execute(context.Background(), job.Job{ID: "job-archive", Type: "archive.build"}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !ran {
+		t.Fatalf("handler not called")
+	}
+}
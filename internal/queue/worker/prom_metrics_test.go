@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRunWorker_ServesPromJobMetricsOnHealthServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reg := prometheus.NewRegistry()
+	prom := observability.NewProm(reg)
+
+	w := New(Config{Prom: prom}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil)
+	w.clock = newFakeClock()
+	w.Register("test.ok", func(ctx context.Context, j job.Job) error { return nil })
+	w.Register("test.broken", func(ctx context.Context, j job.Job) error { return errors.New("boom") })
+
+	jobsCh := make(chan job.Job, 3)
+	jobsCh <- job.Job{ID: "job-1", Type: "test.ok", MaxAttempts: 3}
+	jobsCh <- job.Job{ID: "job-2", Type: "test.broken", Attempts: 0, MaxAttempts: 3}
+	jobsCh <- job.Job{ID: "job-3", Type: "test.broken", Attempts: 2, MaxAttempts: 3}
+	close(jobsCh)
+	w.runWorker(context.Background(), 1, jobsCh)
+
+	srv := httptest.NewServer(w.HealthHandler(reg))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	body := string(raw)
+
+	for _, want := range []string{
+		`eventhub_jobs_duration_seconds_count{job_type="test.ok",result="done"} 1`,
+		`eventhub_jobs_duration_seconds_count{job_type="test.broken",result="retry"} 1`,
+		`eventhub_jobs_duration_seconds_count{job_type="test.broken",result="dead_letter"} 1`,
+		`eventhub_jobs_results_total{job_type="test.ok",result="done"} 1`,
+		`eventhub_jobs_results_total{job_type="test.broken",result="dead_letter"} 1`,
+		`eventhub_jobs_in_flight 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the scrape, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestPromJobResult(t *testing.T) {
+	cases := map[job.AttemptOutcome]string{
+		job.AttemptDone:         "done",
+		job.AttemptRetried:      "retry",
+		job.AttemptDeadLettered: "dead_letter",
+		job.AttemptCancelled:    "cancelled",
+		job.AttemptError:        "failed",
+		job.AttemptInterrupted:  "interrupted",
+	}
+	for outcome, want := range cases {
+		if got := promJobResult(outcome); got != want {
+			t.Fatalf("%s: got %q, want %q", outcome, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/alerting"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/observability"
+)
+
+// RecipientQuarantine counts permanent send failures per recipient and
+// quarantines the ones that keep failing.
+type RecipientQuarantine interface {
+	RecordPermanentFailure(ctx context.Context, email, errMsg string, policy notificationsdelivery.QuarantinePolicy) (bool, error)
+	IsQuarantined(ctx context.Context, email string) (bool, error)
+}
+
+// quarantineCacheTTL is how long a recipient's quarantine status is trusted,
+// so a burst of sends to one address costs one query; a lifted quarantine
+// takes effect within it.
+const quarantineCacheTTL = 30 * time.Second
+
+// quarantineCacheMax is the size past which expired entries are dropped.
+const quarantineCacheMax = 10000
+
+type quarantineEntry struct {
+	quarantined bool
+	checkedAt   time.Time
+}
+
+type recipientQuarantine struct {
+	store  RecipientQuarantine
+	policy notificationsdelivery.QuarantinePolicy
+
+	mu    sync.Mutex
+	cache map[string]quarantineEntry
+}
+
+// WithRecipientQuarantine makes confirmations and reminders skip quarantined
+// recipients, recording them as skipped_quarantined, and quarantines a
+// recipient after policy.Threshold permanent failures within policy.Window.
+// A zero threshold still honors existing quarantines but adds none.
+func (w *Worker) WithRecipientQuarantine(store RecipientQuarantine, policy notificationsdelivery.QuarantinePolicy) *Worker {
+	w.quarantine = &recipientQuarantine{store: store, policy: policy, cache: map[string]quarantineEntry{}}
+	return w
+}
+
+// recipientQuarantined reports whether sends to email should be skipped. A
+// failed read lets the send go ahead: a bounce costs less than a lost email.
+func (w *Worker) recipientQuarantined(ctx context.Context, email string) bool {
+	q := w.quarantine
+	if q == nil {
+		return false
+	}
+	key := notificationsdelivery.NormalizeRecipient(email)
+	now := w.now()
+
+	q.mu.Lock()
+	entry, ok := q.cache[key]
+	q.mu.Unlock()
+	if ok && now.Sub(entry.checkedAt) < quarantineCacheTTL {
+		return entry.quarantined
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, time.Second)
+	quarantined, err := q.store.IsQuarantined(cctx, key)
+	cancel()
+	if err != nil {
+		log.Printf("worker: quarantine check failed; sending anyway err=%v", err)
+		return false
+	}
+
+	q.remember(key, quarantined, now)
+	return quarantined
+}
+
+// recordDeliveryFailure counts err against email when it is a permanent
+// rejection, alerting when that quarantines email. Other errors are left to
+// the job's retries.
+func (w *Worker) recordDeliveryFailure(ctx context.Context, email string, err error) {
+	q := w.quarantine
+	if q == nil || q.policy.Threshold <= 0 || !errors.Is(err, notifications.ErrPermanent) {
+		return
+	}
+	key := notificationsdelivery.NormalizeRecipient(email)
+
+	quarantined, rerr := q.store.RecordPermanentFailure(ctx, key, err.Error(), q.policy)
+	if rerr != nil {
+		log.Printf("worker: record permanent failure failed err=%v", rerr)
+		return
+	}
+	if !quarantined {
+		return
+	}
+
+	q.remember(key, true, w.now())
+	w.alert(ctx, alerting.SeverityWarning, "Recipient quarantined",
+		fmt.Sprintf("A recipient was quarantined after %d permanent delivery failures within %s; deliveries to it are skipped until an admin lifts it (GET /admin/suppressions lists them). Last error: %s",
+			q.policy.Threshold, q.policy.Window, observability.RedactEmails(err.Error())),
+		map[string]string{"component": "notifier"},
+	)
+}
+
+func (q *recipientQuarantine) remember(key string, quarantined bool, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.cache) >= quarantineCacheMax {
+		for k, e := range q.cache {
+			if now.Sub(e.checkedAt) >= quarantineCacheTTL {
+				delete(q.cache, k)
+			}
+		}
+	}
+	q.cache[key] = quarantineEntry{quarantined: quarantined, checkedAt: now}
+}
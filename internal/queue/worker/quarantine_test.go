@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+// fakeQuarantineStore counts failures per recipient like the repo, minus the
+// window, and counts the reads that reach it.
+type fakeQuarantineStore struct {
+	failures    map[string]int
+	quarantined map[string]bool
+	reads       int
+}
+
+func newFakeQuarantineStore() *fakeQuarantineStore {
+	return &fakeQuarantineStore{failures: map[string]int{}, quarantined: map[string]bool{}}
+}
+
+func (s *fakeQuarantineStore) RecordPermanentFailure(ctx context.Context, email, errMsg string, policy notificationsdelivery.QuarantinePolicy) (bool, error) {
+	s.failures[email]++
+	if s.quarantined[email] || s.failures[email] < policy.Threshold {
+		return false, nil
+	}
+	s.quarantined[email] = true
+	return true, nil
+}
+
+func (s *fakeQuarantineStore) IsQuarantined(ctx context.Context, email string) (bool, error) {
+	s.reads++
+	return s.quarantined[email], nil
+}
+
+func (s *fakeQuarantineStore) lift(email string) {
+	delete(s.quarantined, email)
+	delete(s.failures, email)
+}
+
+// rejectingReminderNotifier bounces every reminder while reject is set.
+type rejectingReminderNotifier struct {
+	fakeReminderNotifier
+	reject bool
+	calls  int
+}
+
+func (n *rejectingReminderNotifier) SendEventReminder(ctx context.Context, in notifications.SendEventReminderInput) error {
+	n.calls++
+	if n.reject {
+		return notifications.Permanent(errors.New("550 mailbox unavailable"))
+	}
+	return n.fakeReminderNotifier.SendEventReminder(ctx, in)
+}
+
+// skipRecordingGate also remembers which deliveries were skipped.
+type skipRecordingGate struct {
+	fakeRegistrationGate
+	skipped []string
+}
+
+func (g *skipRecordingGate) MarkRegistrationDeliverySkipped(ctx context.Context, kind, registrationID, reason string) error {
+	g.skipped = append(g.skipped, registrationID)
+	return nil
+}
+
+func newQuarantineWorker(store *fakeQuarantineStore, notifier *rejectingReminderNotifier, gate *skipRecordingGate, alerter *fakeAlerter, clock *fakeClock) *Worker {
+	w := &Worker{notifier: notifier, clock: clock, alerter: alerter}
+	w.WithReminders(ReminderSources{
+		Registrations: &fakeReminderRegistrations{status: registration.StatusConfirmed},
+		Events:        fakeReminderEvents{startAt: time.Now().Add(23 * time.Hour)},
+	}, gate)
+	return w.WithRecipientQuarantine(store, notificationsdelivery.QuarantinePolicy{Threshold: 3, Window: 24 * time.Hour})
+}
+
+func TestQuarantine_ThresholdQuarantinesAndAlertsOnce(t *testing.T) {
+	store := newFakeQuarantineStore()
+	notifier := &rejectingReminderNotifier{reject: true}
+	gate := &skipRecordingGate{fakeRegistrationGate: fakeRegistrationGate{sent: map[string]bool{}}}
+	alerter := &fakeAlerter{}
+	w := newQuarantineWorker(store, notifier, gate, alerter, &fakeClock{now: time.Now()})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		err := w.execute(ctx, reminderJob(t, "job-1"))
+		if !errors.Is(err, jobs.ErrNonRetryable) || !errors.Is(err, notifications.ErrPermanent) {
+			t.Fatalf("attempt %d: expected a non-retryable permanent failure, got %v", i, err)
+		}
+	}
+	if !store.quarantined["ada@example.com"] {
+		t.Fatalf("expected the recipient quarantined after 3 failures, got %v", store.failures)
+	}
+	if len(alerter.sent) != 1 || alerter.sent[0].title != "Recipient quarantined" {
+		t.Fatalf("expected one quarantine alert, got %+v", alerter.sent)
+	}
+
+	// later deliveries short-circuit without reaching the notifier
+	for i := 0; i < 2; i++ {
+		if err := w.execute(ctx, reminderJob(t, "job-2")); err != nil {
+			t.Fatalf("execute quarantined: %v", err)
+		}
+	}
+	if notifier.calls != 3 {
+		t.Fatalf("expected no sends once quarantined, got %d", notifier.calls)
+	}
+	if len(gate.skipped) != 2 || len(alerter.sent) != 1 {
+		t.Fatalf("expected two skips and still one alert, got skipped=%v alerts=%d", gate.skipped, len(alerter.sent))
+	}
+}
+
+func TestQuarantine_TransientFailuresDoNotCount(t *testing.T) {
+	store := newFakeQuarantineStore()
+	w := &Worker{}
+	w.WithRecipientQuarantine(store, notificationsdelivery.QuarantinePolicy{Threshold: 1, Window: time.Hour})
+
+	w.recordDeliveryFailure(context.Background(), "ada@example.com", errors.New("connection reset"))
+	w.recordDeliveryFailure(context.Background(), "ada@example.com", notifications.ErrCircuitOpen)
+	if len(store.failures) != 0 {
+		t.Fatalf("expected only permanent failures counted, got %v", store.failures)
+	}
+}
+
+func TestQuarantine_LiftLetsDeliveriesRetry(t *testing.T) {
+	store := newFakeQuarantineStore()
+	store.quarantined["ada@example.com"] = true
+	notifier := &rejectingReminderNotifier{}
+	gate := &skipRecordingGate{fakeRegistrationGate: fakeRegistrationGate{sent: map[string]bool{}}}
+	clock := &fakeClock{now: time.Now()}
+	w := newQuarantineWorker(store, notifier, gate, &fakeAlerter{}, clock)
+	ctx := context.Background()
+
+	if err := w.execute(ctx, reminderJob(t, "job-1")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(gate.skipped) != 1 || notifier.calls != 0 {
+		t.Fatalf("expected the quarantined delivery skipped, got skipped=%v calls=%d", gate.skipped, notifier.calls)
+	}
+
+	// the cached status holds until it expires
+	store.lift("ada@example.com")
+	if err := w.execute(ctx, reminderJob(t, "job-2")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if notifier.calls != 0 || store.reads != 1 {
+		t.Fatalf("expected the cache to answer, got calls=%d reads=%d", notifier.calls, store.reads)
+	}
+
+	clock.now = clock.now.Add(quarantineCacheTTL)
+	if err := w.execute(ctx, reminderJob(t, "job-3")); err != nil {
+		t.Fatalf("execute after lift: %v", err)
+	}
+	if len(notifier.sent) != 1 || !gate.sent[jobs.TypeRegistrationReminder+"|reg-1"] {
+		t.Fatalf("expected the reminder sent after the lift, got %+v", notifier.sent)
+	}
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/gin-gonic/gin"
+)
+
+// JobStatsReader is implemented by job repos that can summarise the queue.
+// Without it the health endpoints report no queue figures and never degrade.
+type JobStatsReader interface {
+	Stats(ctx context.Context) (job.Stats, error)
+}
+
+// queueStatsTTL is how long one read of the queue figures is reused, so
+// probes and scrapes every few seconds do not each cost an aggregate query.
+const queueStatsTTL = 5 * time.Second
+
+type queueHealthState struct {
+	mu        sync.Mutex
+	stats     job.Stats
+	ok        bool
+	checkedAt time.Time
+}
+
+// queueStats returns the queue figures, reading them at most once per
+// queueStatsTTL under a one second timeout. It reports false when they are
+// unknown: the repo cannot tell, or every read so far has failed. A failed
+// read keeps the last known figures.
+func (w *Worker) queueStats(ctx context.Context) (job.Stats, bool) {
+	reader, ok := w.repo.(JobStatsReader)
+	if !ok {
+		return job.Stats{}, false
+	}
+
+	qs := &w.queueHealth
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	now := w.now()
+	if !qs.checkedAt.IsZero() && now.Sub(qs.checkedAt) < queueStatsTTL {
+		return qs.stats, qs.ok
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, time.Second)
+	stats, err := reader.Stats(cctx)
+	cancel()
+	// a failed read is not retried before the TTL either, so a slow database
+	// is not hit again by every probe
+	qs.checkedAt = now
+	if err != nil {
+		log.Printf("worker: queue stats read failed worker_id=%s err=%v", w.cfg.WorkerID, err)
+		return qs.stats, qs.ok
+	}
+
+	qs.stats, qs.ok = stats, true
+	if w.metrics != nil {
+		w.metrics.SetOldestPending(stats.OldestPendingAge)
+	}
+	return stats, true
+}
+
+// queueDegraded reports whether the oldest due pending job has waited longer
+// than Config.DegradedPendingAge. Unknown figures are not degraded.
+func (w *Worker) queueDegraded(stats job.Stats, ok bool) bool {
+	limit := w.cfg.DegradedPendingAge
+	return ok && limit > 0 && stats.OldestPendingAge > limit
+}
+
+// queueHealthBody is the queue part of /healthz.
+func queueHealthBody(stats job.Stats) gin.H {
+	counts := make(map[string]int64, len(stats.Counts))
+	for status, n := range stats.Counts {
+		counts[string(status)] = n
+	}
+	return gin.H{
+		"counts":               counts,
+		"oldestPendingSeconds": stats.OldestPendingAge.Seconds(),
+	}
+}
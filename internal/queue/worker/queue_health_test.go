@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type statsJobsRepo struct {
+	*fakeJobsRepo
+	stats job.Stats
+	err   error
+	calls int
+}
+
+func (r *statsJobsRepo) Stats(ctx context.Context) (job.Stats, error) {
+	r.calls++
+	return r.stats, r.err
+}
+
+func healthClock() *fakeClock {
+	clk := newFakeClock()
+	clk.now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return clk
+}
+
+func getHealth(t *testing.T, w *Worker, path string) (int, map[string]any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	rr := httptest.NewRecorder()
+	w.HealthHandler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s: decode %q: %v", path, rr.Body.String(), err)
+	}
+	return rr.Code, body
+}
+
+func TestHealthz_ReportsQueueFigures(t *testing.T) {
+	repo := &statsJobsRepo{fakeJobsRepo: &fakeJobsRepo{}, stats: job.Stats{
+		Counts:           map[job.Status]int64{job.StatusPending: 12, job.StatusProcessing: 3, job.StatusDone: 40},
+		OldestPendingAge: 90 * time.Second,
+	}}
+	w := &Worker{ready: true, repo: repo, clock: healthClock()}
+
+	code, body := getHealth(t, w, "/healthz")
+	if code != http.StatusOK {
+		t.Fatalf("status=%d", code)
+	}
+	queue, ok := body["queue"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected queue figures, got %+v", body)
+	}
+	counts, _ := queue["counts"].(map[string]any)
+	if counts["pending"] != float64(12) || counts["processing"] != float64(3) || counts["done"] != float64(40) {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+	if queue["oldestPendingSeconds"] != float64(90) {
+		t.Fatalf("unexpected oldest pending age %+v", queue)
+	}
+}
+
+func TestHealthz_OmitsQueueWhenUnknown(t *testing.T) {
+	for name, w := range map[string]*Worker{
+		"repo without stats": {ready: true, repo: &fakeJobsRepo{}},
+		"failing read":       {ready: true, repo: &statsJobsRepo{fakeJobsRepo: &fakeJobsRepo{}, err: errors.New("db down")}, clock: healthClock()},
+	} {
+		code, body := getHealth(t, w, "/healthz")
+		if code != http.StatusOK {
+			t.Fatalf("%s: liveness must not depend on the queue, got %d", name, code)
+		}
+		if _, ok := body["queue"]; ok {
+			t.Fatalf("%s: expected no queue figures, got %+v", name, body)
+		}
+	}
+}
+
+func TestReadyz_DegradedPastOldestPendingThreshold(t *testing.T) {
+	repo := &statsJobsRepo{fakeJobsRepo: &fakeJobsRepo{}}
+	clk := healthClock()
+	w := &Worker{ready: true, repo: repo, clock: clk, cfg: Config{DegradedPendingAge: 10 * time.Minute}}
+
+	repo.stats = job.Stats{OldestPendingAge: 9 * time.Minute}
+	if code, body := getHealth(t, w, "/readyz"); code != http.StatusOK || body["degraded"] != false {
+		t.Fatalf("under the threshold: status=%d body=%+v", code, body)
+	}
+
+	// cached: the next probe inside the TTL does not read again
+	repo.stats = job.Stats{OldestPendingAge: 11 * time.Minute}
+	if _, body := getHealth(t, w, "/readyz"); body["degraded"] != false || repo.calls != 1 {
+		t.Fatalf("expected the cached figures reused, got %+v after %d reads", body, repo.calls)
+	}
+
+	clk.now = clk.now.Add(queueStatsTTL)
+	code, body := getHealth(t, w, "/readyz")
+	if code != http.StatusOK || body["degraded"] != true || body["status"] != "ready" {
+		t.Fatalf("over the threshold: status=%d body=%+v", code, body)
+	}
+}
+
+func TestReadyz_ZeroThresholdNeverDegrades(t *testing.T) {
+	repo := &statsJobsRepo{fakeJobsRepo: &fakeJobsRepo{}, stats: job.Stats{OldestPendingAge: 24 * time.Hour}}
+	w := &Worker{ready: true, repo: repo, clock: healthClock()}
+
+	if _, body := getHealth(t, w, "/readyz"); body["degraded"] != false {
+		t.Fatalf("expected no degradation without a threshold, got %+v", body)
+	}
+}
+
+func TestQueueStats_SetsOldestPendingGauge(t *testing.T) {
+	metrics := observability.NewJobMetrics()
+	reg := prometheus.NewRegistry()
+	if err := metrics.Register(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	repo := &statsJobsRepo{fakeJobsRepo: &fakeJobsRepo{}, stats: job.Stats{OldestPendingAge: 42 * time.Second}}
+	w := &Worker{repo: repo, clock: healthClock(), metrics: metrics}
+	w.queueStats(context.Background())
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() == "eventhub_jobs_oldest_pending_seconds" {
+			if got := mf.GetMetric()[0].GetGauge().GetValue(); got != 42 {
+				t.Fatalf("gauge = %v, want 42", got)
+			}
+			return
+		}
+	}
+	t.Fatalf("eventhub_jobs_oldest_pending_seconds not registered")
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// JobTimingWriter is implemented by job repos that can store how long an
+// attempt waited to be claimed and how long it ran.
+type JobTimingWriter interface {
+	SetTiming(ctx context.Context, id string, queueWait, execution time.Duration) error
+}
+
+// queueWait is how long j sat claimable before startedAt. A job is claimable
+// from the later of its run_at and its insert, so a job delayed on purpose
+// only starts waiting once it is due. Never negative, and 0 for a job that
+// carries neither time.
+func queueWait(j job.Job, startedAt time.Time) time.Duration {
+	ready := j.RunAt
+	if j.CreatedAt.After(ready) {
+		ready = j.CreatedAt
+	}
+	if ready.IsZero() || startedAt.Before(ready) {
+		return 0
+	}
+	return startedAt.Sub(ready)
+}
+
+// recordTiming stores the attempt's durations on the job row. Best effort: a
+// lost write only blanks the admin detail.
+func (w *Worker) recordTiming(ctx context.Context, j job.Job, wait, execution time.Duration) {
+	tw, ok := w.repo.(JobTimingWriter)
+	if !ok {
+		return
+	}
+
+	bctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+	if err := tw.SetTiming(bctx, j.ID, wait, execution); err != nil {
+		slog.Default().WarnContext(ctx, "job.timing_write_failed",
+			"job_id", j.ID,
+			"err", err,
+		)
+	}
+}
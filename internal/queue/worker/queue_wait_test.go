@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type timingJobsRepo struct {
+	*fakeJobsRepo
+	queueWait, execution time.Duration
+	calls                int
+}
+
+func (r *timingJobsRepo) SetTiming(ctx context.Context, id string, queueWait, execution time.Duration) error {
+	r.queueWait, r.execution = queueWait, execution
+	r.calls++
+	return nil
+}
+
+func TestQueueWait_MeasuredFromWhenTheJobWasDue(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		job     job.Job
+		started time.Time
+		want    time.Duration
+	}{
+		{"immediate job waits from its insert", job.Job{CreatedAt: created, RunAt: created}, created.Add(1500 * time.Millisecond), 1500 * time.Millisecond},
+		{"run_at a hair before the insert", job.Job{CreatedAt: created, RunAt: created.Add(-time.Millisecond)}, created.Add(time.Second), time.Second},
+		{"delayed job waits from run_at", job.Job{CreatedAt: created, RunAt: created.Add(time.Hour)}, created.Add(time.Hour + 3*time.Second), 3 * time.Second},
+		{"started early never goes negative", job.Job{CreatedAt: created, RunAt: created.Add(time.Hour)}, created.Add(time.Minute), 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := queueWait(tc.job, tc.started); got != tc.want {
+				t.Fatalf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunWorker_RecordsQueueWait(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clk := newFakeClock()
+	clk.now = created.Add(2*time.Hour + 4*time.Second)
+
+	repo := &timingJobsRepo{fakeJobsRepo: &fakeJobsRepo{}}
+	metrics := observability.NewJobMetrics()
+	reg := prometheus.NewRegistry()
+	if err := metrics.Register(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	w := New(Config{}, repo, &fakeEventsRepo{}, nil, nil)
+	w.clock, w.metrics = clk, metrics
+	w.Register("test.delayed", func(ctx context.Context, j job.Job) error { return nil })
+
+	jobsCh := make(chan job.Job, 1)
+	jobsCh <- job.Job{ID: "job-1", Type: "test.delayed", CreatedAt: created, RunAt: created.Add(2 * time.Hour), MaxAttempts: 3}
+	close(jobsCh)
+	w.runWorker(context.Background(), 1, jobsCh)
+
+	if repo.calls != 1 || repo.queueWait != 4*time.Second {
+		t.Fatalf("expected a 4s queue wait stored once, got %s over %d calls", repo.queueWait, repo.calls)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "eventhub_jobs_queue_wait_seconds" {
+			continue
+		}
+		h := mf.GetMetric()[0].GetHistogram()
+		if len(mf.GetMetric()) != 1 || h.GetSampleCount() != 1 || h.GetSampleSum() != 4 {
+			t.Fatalf("expected one 4s observation, got %v", mf)
+		}
+		if label := mf.GetMetric()[0].GetLabel()[0]; label.GetName() != "job_type" || label.GetValue() != "test.delayed" {
+			t.Fatalf("unexpected label %v", label)
+		}
+		return
+	}
+	t.Fatalf("eventhub_jobs_queue_wait_seconds not registered")
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// ErrUnknownJobType is returned for jobs no handler is registered for. Such
+// jobs are dead-lettered on the first attempt: retrying cannot help.
+var ErrUnknownJobType = errors.New("unknown job type")
+
+// HandlerFunc runs one job; an error sends it through retry/dead-letter.
+type HandlerFunc func(ctx context.Context, j job.Job) error
+
+// HandlerRegistry maps job types to the handlers that run them.
+type HandlerRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+	timeouts map[string]time.Duration
+	policies map[string]jobs.RetryPolicy
+}
+
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{
+		handlers: make(map[string]HandlerFunc),
+		timeouts: make(map[string]time.Duration),
+		policies: make(map[string]jobs.RetryPolicy),
+	}
+}
+
+// Register adds the handler for jobType. Registering a type twice is a wiring
+// bug, so it panics at startup rather than silently replacing a handler.
+func (r *HandlerRegistry) Register(jobType string, fn HandlerFunc) {
+	if jobType == "" || fn == nil {
+		panic("worker: Register needs a job type and a handler")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[jobType]; exists {
+		panic(fmt.Sprintf("worker: handler for job type %q registered twice", jobType))
+	}
+	r.handlers[jobType] = fn
+}
+
+// Lookup returns the handler for jobType, if any.
+func (r *HandlerRegistry) Lookup(jobType string) (HandlerFunc, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fn, ok := r.handlers[jobType]
+	return fn, ok
+}
+
+// SetTimeout gives jobType its own execution timeout instead of
+// Config.JobTimeout. A zero d lets the type run unbounded.
+func (r *HandlerRegistry) SetTimeout(jobType string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timeouts[jobType] = d
+}
+
+// Timeout returns jobType's own timeout, if one was set.
+func (r *HandlerRegistry) Timeout(jobType string) (time.Duration, bool) {
+	if r == nil {
+		return 0, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.timeouts[jobType]
+	return d, ok
+}
+
+// SetRetryPolicy gives jobType its own retry policy.
+func (r *HandlerRegistry) SetRetryPolicy(jobType string, p jobs.RetryPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policies[jobType] = p
+}
+
+// RetryPolicy returns jobType's retry policy, if one was set.
+func (r *HandlerRegistry) RetryPolicy(jobType string) (jobs.RetryPolicy, bool) {
+	if r == nil {
+		return jobs.RetryPolicy{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.policies[jobType]
+	return p, ok
+}
+
+// Types lists the registered job types, sorted.
+func (r *HandlerRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0, len(r.handlers))
+	for t := range r.handlers {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Register adds a handler for a custom job type; see HandlerRegistry.Register.
+func (w *Worker) Register(jobType string, fn HandlerFunc) *Worker {
+	if w.handlers == nil {
+		w.handlers = NewHandlerRegistry()
+	}
+	w.handlers.Register(jobType, fn)
+	return w
+}
+
+// Handlers exposes the worker's registry, e.g. to log what it will run.
+func (w *Worker) Handlers() *HandlerRegistry {
+	if w.handlers == nil {
+		w.handlers = NewHandlerRegistry()
+	}
+	return w.handlers
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegistry_RunsCustomJobType(t *testing.T) {
+	var ran string
+	w := (&Worker{}).Register("crm.sync", func(ctx context.Context, j job.Job) error {
+		ran = j.ID
+		return nil
+	})
+
+	if err := w.execute(context.Background(), job.Job{ID: "job-crm", Type: "crm.sync"}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if ran != "job-crm" {
+		t.Fatalf("custom handler not called")
+	}
+}
+
+func TestRegistry_DuplicateTypePanics(t *testing.T) {
+	w := (&Worker{}).Register("crm.sync", func(ctx context.Context, j job.Job) error { return nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected registering crm.sync twice to panic")
+		}
+	}()
+	w.Register("crm.sync", func(ctx context.Context, j job.Job) error { return nil })
+}
+
+func TestRegistry_UnknownTypeIsDeadLetteredImmediately(t *testing.T) {
+	var rescheduled, failed bool
+	repo := &fakeJobsRepo{
+		rescheduleFn: func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+			rescheduled = true
+			return nil
+		},
+		markFailedFn: func(ctx context.Context, id string, errMsg string) error {
+			failed = true
+			return nil
+		},
+	}
+	w := New(Config{}, repo, &fakeEventsRepo{}, nil, nil)
+	reg := prometheus.NewRegistry()
+	if err := w.metrics.Register(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	j := job.Job{ID: "job-x", Type: "nobody.handles.this", Attempts: 0, MaxAttempts: 25}
+	start := time.Now()
+	err := w.execute(context.Background(), j)
+	if !errors.Is(err, ErrUnknownJobType) || !errors.Is(err, jobs.ErrNonRetryable) {
+		t.Fatalf("expected a non-retryable ErrUnknownJobType, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected the unknown type to fail at once, took %s", elapsed)
+	}
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP eventhub_worker_unknown_job_types_total Claimed jobs whose type this worker has no handler for; each is dead-lettered on its first attempt.
+# TYPE eventhub_worker_unknown_job_types_total counter
+eventhub_worker_unknown_job_types_total 1
+`), "eventhub_worker_unknown_job_types_total"); err != nil {
+		t.Fatalf("expected the unknown type counted: %v", err)
+	}
+
+	w.handleFailure(context.Background(), j, err)
+	if rescheduled || !failed {
+		t.Fatalf("expected an immediate dead-letter, rescheduled=%v failed=%v", rescheduled, failed)
+	}
+	if recent := observability.RecentJobErrors.Snapshot(); len(recent) == 0 || recent[0].JobID != "job-x" || recent[0].Class != "unknown_job_type" {
+		t.Fatalf("expected the failure in the recent job errors, got %+v", recent)
+	}
+}
+
+func TestRegistry_TestJobsOnlyWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		w := New(Config{EnableTestJobs: enabled}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil)
+		for _, jobType := range []string{"test.crash", "test.slow"} {
+			if _, ok := w.handlers.Lookup(jobType); ok != enabled {
+				t.Fatalf("EnableTestJobs=%v: %s registered=%v", enabled, jobType, ok)
+			}
+		}
+	}
+}
+
+func TestRegistry_TestCrashJobStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- testCrashJob(ctx, job.Job{ID: "job-crash", Type: "test.crash"}) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("test.crash kept running after its context was cancelled")
+	}
+}
@@ -13,7 +13,7 @@ import (
 
 type fakeJobsRepo struct {
 	claimNextFn              func(ctx context.Context, workerID string) (job.Job, error)
-	requeueStaleProcessingFn func(ctx context.Context, lockTTL time.Duration) (int64, error)
+	requeueStaleProcessingFn func(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error)
 	rescheduleFn             func(ctx context.Context, id string, runAt time.Time, errMsg string) error
 	markFailedFn             func(ctx context.Context, id string, errMsg string) error
 	markDoneFn               func(ctx context.Context, id string) error
@@ -26,11 +26,11 @@ func (f *fakeJobsRepo) ClaimNext(ctx context.Context, workerID string) (job.Job,
 	return job.Job{}, job.ErrJobNotFound
 }
 
-func (f *fakeJobsRepo) RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (int64, error) {
+func (f *fakeJobsRepo) RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error) {
 	if f.requeueStaleProcessingFn != nil {
 		return f.requeueStaleProcessingFn(ctx, lockTTL)
 	}
-	return 0, nil
+	return job.StaleRequeue{}, nil
 }
 
 func (f *fakeJobsRepo) Reschedule(ctx context.Context, id string, runAt time.Time, errMsg string) error {
@@ -237,29 +237,3 @@ func TestProcessOne_NoClaimedJobReturnsFalseNil(t *testing.T) {
 		t.Fatalf("expected processed=false when no jobs are available")
 	}
 }
-
-func TestExponentialBackoff_StaysWithinExpectedBounds(t *testing.T) {
-	tests := []struct {
-		name    string
-		attempt int
-		base    time.Duration
-	}{
-		{name: "attempt 0", attempt: 0, base: 2 * time.Second},
-		{name: "attempt 3", attempt: 3, base: 16 * time.Second},
-		{name: "capped", attempt: 20, base: 5 * time.Minute},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			delay := ExponentialBackoff(tt.attempt)
-			maxAllowed := tt.base + 250*time.Millisecond
-
-			if delay < tt.base {
-				t.Fatalf("delay below base: got=%s base=%s", delay, tt.base)
-			}
-			if delay > maxAllowed {
-				t.Fatalf("delay above jitter cap: got=%s max=%s", delay, maxAllowed)
-			}
-		})
-	}
-}
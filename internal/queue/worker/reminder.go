@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+// ReminderSources schedule reminders and re-check them before sending.
+type ReminderSources struct {
+	Registrations interface {
+		GetByID(ctx context.Context, eventID, registrationID string) (registration.Registration, error)
+		EnqueueReminders(ctx context.Context, eventID string) (int64, error)
+	}
+	Events interface {
+		GetByID(ctx context.Context, id string) (event.Event, error)
+	}
+}
+
+// RegistrationDeliveryGate is the send-once gate for notifications sent once
+// per registration and kind.
+type RegistrationDeliveryGate interface {
+	TryStartRegistrationDelivery(ctx context.Context, kind, jobID, registrationID, recipient string) error
+	MarkRegistrationDeliverySent(ctx context.Context, kind, registrationID string, providerMessageID *string) error
+	MarkRegistrationDeliveryFailed(ctx context.Context, kind, registrationID, errMsg string) error
+	MarkRegistrationDeliverySkipped(ctx context.Context, kind, registrationID, reason string) error
+}
+
+type reminderSender struct {
+	sources ReminderSources
+	gate    RegistrationDeliveryGate
+}
+
+// WithReminders enables registration.reminder: publishing an event schedules
+// one reminder per confirmed registration, sent jobs.ReminderLead before it
+// starts.
+func (w *Worker) WithReminders(sources ReminderSources, gate RegistrationDeliveryGate) *Worker {
+	w.reminders = &reminderSender{sources: sources, gate: gate}
+	return w.Register(jobs.TypeRegistrationReminder, func(ctx context.Context, j job.Job) error {
+		var p jobs.RegistrationReminderPayload
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+
+		return w.sendReminder(ctx, j.ID, p)
+	})
+}
+
+// scheduleReminders runs on every event.publish, not only the first, so a
+// retried publish still schedules what an earlier attempt missed.
+func (w *Worker) scheduleReminders(ctx context.Context, eventID string) error {
+	if w.reminders == nil {
+		return nil
+	}
+
+	n, err := w.reminders.sources.Registrations.EnqueueReminders(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("schedule reminders: %w", err)
+	}
+	if n > 0 {
+		log.Printf("reminders: scheduled event=%s count=%d", eventID, n)
+	}
+	return nil
+}
+
+func (w *Worker) sendReminder(ctx context.Context, jobID string, p jobs.RegistrationReminderPayload) error {
+	if w.reminders == nil {
+		return fmt.Errorf("reminder dependencies not configured")
+	}
+	notifier, ok := w.notifier.(notifications.EventReminderNotifier)
+	if !ok {
+		return jobs.NonRetryable(fmt.Errorf("notifier cannot send reminders: %w", notifications.ErrUnsupported))
+	}
+	rs := w.reminders
+
+	// the job was scheduled up to weeks ago; skip attendees who cancelled and
+	// events that were deleted or already started since
+	reg, err := rs.sources.Registrations.GetByID(ctx, p.EventID, p.RegistrationID)
+	if err != nil {
+		if errors.Is(err, registration.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if reg.Status != registration.StatusConfirmed {
+		return nil
+	}
+
+	e, err := rs.sources.Events.GetByID(ctx, p.EventID)
+	if err != nil {
+		if errors.Is(err, event.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !e.StartAt.After(time.Now()) {
+		return nil
+	}
+
+	err = rs.gate.TryStartRegistrationDelivery(ctx, jobs.TypeRegistrationReminder, jobID, reg.ID, reg.Email)
+	if err != nil {
+		if errors.Is(err, notificationsdelivery.ErrAlreadySent) {
+			return nil
+		}
+		if errors.Is(err, notificationsdelivery.ErrInProgress) {
+			return fmt.Errorf("reminder send in progress")
+		}
+		return err
+	}
+
+	if w.recipientQuarantined(ctx, reg.Email) {
+		return rs.gate.MarkRegistrationDeliverySkipped(ctx, jobs.TypeRegistrationReminder, reg.ID, "recipient quarantined")
+	}
+
+	input := notifications.SendEventReminderInput{
+		Email:          reg.Email,
+		Name:           reg.Name,
+		EventID:        e.ID,
+		EventTitle:     e.Title,
+		RegistrationID: reg.ID,
+		StartAt:        e.StartAt,
+	}
+	if w.cancelTokens != nil {
+		input.CancelToken = w.cancelTokens.Sign(reg.ID, e.ID)
+	}
+
+	err = notifier.SendEventReminder(ctx, input)
+	if err != nil {
+		_ = rs.gate.MarkRegistrationDeliveryFailed(ctx, jobs.TypeRegistrationReminder, reg.ID, err.Error())
+		w.recordDeliveryFailure(ctx, reg.Email, err)
+
+		if errors.Is(err, notifications.ErrCircuitOpen) {
+			return fmt.Errorf("notifier fail-fast: %w", err)
+		}
+		if errors.Is(err, notifications.ErrPermanent) || errors.Is(err, notifications.ErrUnsupported) {
+			return jobs.NonRetryable(err)
+		}
+		return err
+	}
+
+	if err := rs.gate.MarkRegistrationDeliverySent(ctx, jobs.TypeRegistrationReminder, reg.ID, nil); err != nil {
+		log.Printf("deliveries: mark sent failed reg=%s job=%s err=%v", reg.ID, jobID, err)
+	}
+	return nil
+}
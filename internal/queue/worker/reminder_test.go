@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+type fakeReminderRegistrations struct {
+	status    string
+	scheduled []string
+}
+
+func (f *fakeReminderRegistrations) GetByID(ctx context.Context, eventID, registrationID string) (registration.Registration, error) {
+	return registration.Registration{ID: registrationID, EventID: eventID, Email: "ada@example.com", Name: "Ada", Status: f.status}, nil
+}
+
+func (f *fakeReminderRegistrations) EnqueueReminders(ctx context.Context, eventID string) (int64, error) {
+	f.scheduled = append(f.scheduled, eventID)
+	return 1, nil
+}
+
+type fakeReminderEvents struct{ startAt time.Time }
+
+func (f fakeReminderEvents) GetByID(ctx context.Context, id string) (event.Event, error) {
+	return event.Event{ID: id, Title: "Go Meetup", StartAt: f.startAt}, nil
+}
+
+// fakeRegistrationGate remembers which (kind, registration) pairs were sent.
+type fakeRegistrationGate struct {
+	sent map[string]bool
+}
+
+func (g *fakeRegistrationGate) TryStartRegistrationDelivery(ctx context.Context, kind, jobID, registrationID, recipient string) error {
+	if g.sent[kind+"|"+registrationID] {
+		return notificationsdelivery.ErrAlreadySent
+	}
+	return nil
+}
+
+func (g *fakeRegistrationGate) MarkRegistrationDeliverySent(ctx context.Context, kind, registrationID string, providerMessageID *string) error {
+	g.sent[kind+"|"+registrationID] = true
+	return nil
+}
+
+func (g *fakeRegistrationGate) MarkRegistrationDeliveryFailed(ctx context.Context, kind, registrationID, errMsg string) error {
+	return nil
+}
+
+func (g *fakeRegistrationGate) MarkRegistrationDeliverySkipped(ctx context.Context, kind, registrationID, reason string) error {
+	return nil
+}
+
+type fakeReminderNotifier struct {
+	sent []notifications.SendEventReminderInput
+}
+
+func (n *fakeReminderNotifier) SendRegistrationConfirmation(ctx context.Context, in notifications.SendRegistrationConfirmationInput) error {
+	return nil
+}
+
+func (n *fakeReminderNotifier) SendEventPublished(ctx context.Context, in notifications.SendEventPublishedInput) error {
+	return nil
+}
+
+func (n *fakeReminderNotifier) SendEventReminder(ctx context.Context, in notifications.SendEventReminderInput) error {
+	n.sent = append(n.sent, in)
+	return nil
+}
+
+func reminderJob(t *testing.T, id string) job.Job {
+	t.Helper()
+
+	raw, err := jobs.RegistrationReminderPayload{RegistrationID: "reg-1", EventID: "evt-1"}.JSON()
+	if err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	return job.Job{ID: id, Type: jobs.TypeRegistrationReminder, Payload: raw}
+}
+
+func TestExecuteReminder_SendsOncePerRegistration(t *testing.T) {
+	notifier := &fakeReminderNotifier{}
+	gate := &fakeRegistrationGate{sent: map[string]bool{}}
+	regs := &fakeReminderRegistrations{status: registration.StatusConfirmed}
+
+	w := &Worker{notifier: notifier}
+	w.WithReminders(ReminderSources{Registrations: regs, Events: fakeReminderEvents{startAt: time.Now().Add(23 * time.Hour)}}, gate)
+
+	for _, id := range []string{"job-1", "job-1-retry"} {
+		if err := w.execute(context.Background(), reminderJob(t, id)); err != nil {
+			t.Fatalf("execute %s: %v", id, err)
+		}
+	}
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("expected 1 reminder, got %d", len(notifier.sent))
+	}
+	if got := notifier.sent[0]; got.Email != "ada@example.com" || got.EventTitle != "Go Meetup" || got.RegistrationID != "reg-1" {
+		t.Fatalf("unexpected reminder: %+v", got)
+	}
+	if !gate.sent[jobs.TypeRegistrationReminder+"|reg-1"] {
+		t.Fatalf("expected delivery recorded under %s", jobs.TypeRegistrationReminder)
+	}
+}
+
+func TestExecuteReminder_UnsupportedByTheDriverIsNotRetried(t *testing.T) {
+	// a driver that only sends confirmations, behind the breaker as in cmd
+	notifier := notifications.NewProtectedNotifier(&fakeAnnounceNotifier{}, notifications.ProtectedNotifierConfig{})
+	gate := &fakeRegistrationGate{sent: map[string]bool{}}
+	regs := &fakeReminderRegistrations{status: registration.StatusConfirmed}
+
+	w := &Worker{notifier: notifier}
+	w.WithReminders(ReminderSources{Registrations: regs, Events: fakeReminderEvents{startAt: time.Now().Add(23 * time.Hour)}}, gate)
+
+	err := w.execute(context.Background(), reminderJob(t, "job-1"))
+	if !errors.Is(err, notifications.ErrUnsupported) || !errors.Is(err, jobs.ErrNonRetryable) {
+		t.Fatalf("expected a non-retryable ErrUnsupported, got %v", err)
+	}
+}
+
+func TestExecuteReminder_SkipsStaleReminders(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		startAt time.Time
+	}{
+		{"cancelled registration", registration.StatusCancelled, time.Now().Add(23 * time.Hour)},
+		{"waitlisted registration", registration.StatusWaitlisted, time.Now().Add(23 * time.Hour)},
+		{"event already started", registration.StatusConfirmed, time.Now().Add(-time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeReminderNotifier{}
+			gate := &fakeRegistrationGate{sent: map[string]bool{}}
+
+			w := &Worker{notifier: notifier}
+			w.WithReminders(ReminderSources{
+				Registrations: &fakeReminderRegistrations{status: tt.status},
+				Events:        fakeReminderEvents{startAt: tt.startAt},
+			}, gate)
+
+			if err := w.execute(context.Background(), reminderJob(t, "job-1")); err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			if len(notifier.sent) != 0 {
+				t.Fatalf("expected no reminder, got %+v", notifier.sent)
+			}
+		})
+	}
+}
+
+func TestExecutePublish_SchedulesRemindersEvenWhenAlreadyPublished(t *testing.T) {
+	regs := &fakeReminderRegistrations{}
+	events := &fakeEventsRepo{markPublishedFn: func(ctx context.Context, eventID string) (bool, error) {
+		return false, nil
+	}}
+
+	w := &Worker{events: events}
+	w.WithReminders(ReminderSources{Registrations: regs, Events: fakeReminderEvents{}}, &fakeRegistrationGate{})
+	w.Register(jobs.TypeEventPublish, w.HandleEventPublish)
+
+	raw, _ := json.Marshal(publishPayload{EventID: "evt-1"})
+	if err := w.execute(context.Background(), job.Job{ID: "job-1", Type: "event.publish", Payload: raw}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(regs.scheduled) != 1 || regs.scheduled[0] != "evt-1" {
+		t.Fatalf("expected reminders scheduled for evt-1, got %v", regs.scheduled)
+	}
+}
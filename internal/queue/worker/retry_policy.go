@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+// AttemptLimitRescheduler is implemented by job repos that can move a job's
+// max_attempts while rescheduling it. Without it a policy can only lower the
+// row's limit: claims skip rows at max_attempts, so raising it would strand
+// the job.
+type AttemptLimitRescheduler interface {
+	RescheduleWithMaxAttempts(ctx context.Context, id string, runAt time.Time, errMsg string, maxAttempts int) error
+}
+
+// WithRetryPolicies sets per-type retry policies; see jobs.RetryPolicy.
+func (w *Worker) WithRetryPolicies(policies map[string]jobs.RetryPolicy) *Worker {
+	for jobType, p := range policies {
+		w.Handlers().SetRetryPolicy(jobType, p)
+	}
+	return w
+}
+
+// retryDecision is what handleFailure does with a failed attempt.
+type retryDecision struct {
+	retry       bool
+	delay       time.Duration
+	maxAttempts int
+}
+
+// decideRetry applies j's policy to a failed attempt; see jobs.RetryPolicy
+// for the precedence.
+func (w *Worker) decideRetry(j job.Job, execErr error) retryDecision {
+	p, hasPolicy := w.handlers.RetryPolicy(j.Type)
+
+	maxAttempts := job.DefaultMaxAttempts
+	if j.MaxAttempts > 0 {
+		maxAttempts = j.MaxAttempts
+	}
+	if hasPolicy && p.MaxAttempts > 0 {
+		maxAttempts = p.MaxAttempts
+		if _, ok := w.repo.(AttemptLimitRescheduler); !ok && j.MaxAttempts > 0 {
+			maxAttempts = min(maxAttempts, j.MaxAttempts)
+		}
+	}
+	d := retryDecision{maxAttempts: maxAttempts}
+
+	switch {
+	case errors.Is(execErr, jobs.ErrNonRetryable), errors.Is(execErr, ErrUnknownJobType):
+		return d
+	case hasPolicy && p.NonRetryableErrors != nil && p.NonRetryableErrors(execErr):
+		return d
+	case j.Attempts+1 >= maxAttempts:
+		return d
+	}
+
+	d.retry = true
+	d.delay = p.Over(w.cfg.Backoff).Delay(j.Attempts)
+	if errors.Is(execErr, notifications.ErrCircuitOpen) {
+		d.delay = max(d.delay, w.circuitCooldown())
+	}
+	return d
+}
+
+// CircuitCooldown is implemented by notifiers behind a circuit breaker, such
+// as notifications.ProtectedNotifier.
+type CircuitCooldown interface {
+	Cooldown() time.Duration
+}
+
+// circuitCooldown is how long the notifier's circuit stays open, or zero for
+// a notifier without one.
+func (w *Worker) circuitCooldown() time.Duration {
+	if c, ok := w.notifier.(CircuitCooldown); ok {
+		return c.Cooldown()
+	}
+	return 0
+}
+
+// reschedule records a retry, moving the row's max_attempts when the policy
+// changed it and the repo can.
+func (w *Worker) reschedule(ctx context.Context, j job.Job, runAt time.Time, errMsg string, maxAttempts int) error {
+	if r, ok := w.repo.(AttemptLimitRescheduler); ok && maxAttempts != j.MaxAttempts {
+		return r.RescheduleWithMaxAttempts(ctx, j.ID, runAt, errMsg, maxAttempts)
+	}
+	return w.repo.Reschedule(ctx, j.ID, runAt, errMsg)
+}
@@ -0,0 +1,219 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+)
+
+type failureOutcome struct {
+	rescheduled int
+	deadLetters int
+	runAt       time.Time
+}
+
+func trackFailures(repo *fakeJobsRepo) *failureOutcome {
+	out := &failureOutcome{}
+	repo.rescheduleFn = func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+		out.rescheduled++
+		out.runAt = runAt
+		return nil
+	}
+	repo.markFailedFn = func(ctx context.Context, id string, errMsg string) error {
+		out.deadLetters++
+		return nil
+	}
+	return out
+}
+
+func TestHandleFailure_RetryPrecedence(t *testing.T) {
+	exact := jobs.Backoff{Base: time.Second, Multiplier: 2, Max: time.Hour, Jitter: jobs.JitterNone}
+
+	cases := []struct {
+		name      string
+		policy    *jobs.RetryPolicy
+		job       job.Job
+		wantRetry bool
+		wantDelay time.Duration
+	}{
+		// policy > job row
+		{"policy limit wins over the row", &jobs.RetryPolicy{MaxAttempts: 2}, job.Job{Attempts: 1, MaxAttempts: 5}, false, 0},
+		{"policy delays replace the backoff", &jobs.RetryPolicy{BaseDelay: 10 * time.Second, MaxDelay: 15 * time.Second}, job.Job{Attempts: 1, MaxAttempts: 5}, true, 15 * time.Second},
+		// job row > default
+		{"row limit with retries left", nil, job.Job{Attempts: 1, MaxAttempts: 3}, true, 2 * time.Second},
+		{"row limit exhausted", nil, job.Job{Attempts: 2, MaxAttempts: 3}, false, 0},
+		{"policy without a limit falls back to the row", &jobs.RetryPolicy{BaseDelay: time.Minute}, job.Job{Attempts: 2, MaxAttempts: 3}, false, 0},
+		// default
+		{"default limit with retries left", nil, job.Job{Attempts: job.DefaultMaxAttempts - 2}, true, time.Hour},
+		{"default limit exhausted", nil, job.Job{Attempts: job.DefaultMaxAttempts - 1}, false, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeJobsRepo{}
+			out := trackFailures(repo)
+			w := New(Config{Backoff: exact}, repo, &fakeEventsRepo{}, nil, nil)
+			if tc.policy != nil {
+				w.WithRetryPolicies(map[string]jobs.RetryPolicy{"test.flaky": *tc.policy})
+			}
+
+			j := tc.job
+			j.ID, j.Type = "job-1", "test.flaky"
+			before := time.Now().UTC()
+			w.handleFailure(context.Background(), j, errors.New("boom"))
+
+			if tc.wantRetry {
+				if out.rescheduled != 1 || out.deadLetters != 0 {
+					t.Fatalf("expected a retry, got rescheduled=%d deadLetters=%d", out.rescheduled, out.deadLetters)
+				}
+				if delay := out.runAt.Sub(before); delay < tc.wantDelay || delay > tc.wantDelay+time.Second {
+					t.Fatalf("expected a delay of %s, got %s", tc.wantDelay, delay)
+				}
+				return
+			}
+			if out.rescheduled != 0 || out.deadLetters != 1 {
+				t.Fatalf("expected a dead-letter, got rescheduled=%d deadLetters=%d", out.rescheduled, out.deadLetters)
+			}
+		})
+	}
+}
+
+func TestHandleFailure_NonRetryableDeadLettersImmediately(t *testing.T) {
+	errPermanent := errors.New("mailbox does not exist")
+
+	for name, err := range map[string]error{
+		"jobs.ErrNonRetryable":      fmt.Errorf("decode: %w", jobs.ErrNonRetryable),
+		"jobs.NonRetryable wrapper": jobs.NonRetryable(errors.New("bad payload")),
+		"policy NonRetryableErrors": fmt.Errorf("smtp: %w", errPermanent),
+		"unknown job type sentinel": fmt.Errorf("%w: nope", ErrUnknownJobType),
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeJobsRepo{}
+			out := trackFailures(repo)
+			w := New(Config{}, repo, &fakeEventsRepo{}, nil, nil).
+				WithRetryPolicies(map[string]jobs.RetryPolicy{
+					"test.flaky": {MaxAttempts: 50, NonRetryableErrors: jobs.MatchErrors(errPermanent)},
+				})
+
+			w.handleFailure(context.Background(), job.Job{ID: "job-1", Type: "test.flaky", MaxAttempts: 50}, err)
+
+			if out.rescheduled != 0 || out.deadLetters != 1 {
+				t.Fatalf("expected an immediate dead-letter, got rescheduled=%d deadLetters=%d", out.rescheduled, out.deadLetters)
+			}
+		})
+	}
+}
+
+func TestHandleFailure_UndecodablePayloadAndUnknownTypeDeadLetter(t *testing.T) {
+	repo := &fakeJobsRepo{}
+	out := trackFailures(repo)
+	w := New(Config{}, repo, &fakeEventsRepo{}, nil, nil)
+	w.Register(jobs.TypeEventPublish, w.HandleEventPublish)
+
+	for _, j := range []job.Job{
+		{ID: "job-1", Type: jobs.TypeEventPublish, Payload: []byte(`{"eventId":`), MaxAttempts: 5},
+		{ID: "job-2", Type: "test.nope", Payload: []byte(`{}`), MaxAttempts: 5},
+	} {
+		err := w.execute(context.Background(), j)
+		if !errors.Is(err, jobs.ErrNonRetryable) {
+			t.Fatalf("%s: expected a non-retryable error, got %v", j.Type, err)
+		}
+		w.handleFailure(context.Background(), j, err)
+	}
+
+	if out.rescheduled != 0 || out.deadLetters != 2 {
+		t.Fatalf("expected both dead-lettered at once, got rescheduled=%d deadLetters=%d", out.rescheduled, out.deadLetters)
+	}
+}
+
+func TestHandleFailure_CircuitOpenWaitsOutTheCooldown(t *testing.T) {
+	exact := jobs.Backoff{Base: time.Second, Multiplier: 2, Max: time.Hour, Jitter: jobs.JitterNone}
+	notifier := notifications.NewProtectedNotifier(notifications.NewLogNotifier(), notifications.ProtectedNotifierConfig{
+		Cooldown: 10 * time.Minute,
+	})
+
+	cases := []struct {
+		name      string
+		attempts  int
+		err       error
+		wantDelay time.Duration
+	}{
+		{"plain failure keeps the backoff", 0, errors.New("boom"), time.Second},
+		{"open circuit waits for the cooldown", 0, fmt.Errorf("notifier fail-fast: %w", notifications.ErrCircuitOpen), 10 * time.Minute},
+		{"backoff past the cooldown stands", 10, fmt.Errorf("notifier fail-fast: %w", notifications.ErrCircuitOpen), 1024 * time.Second},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeJobsRepo{}
+			out := trackFailures(repo)
+			w := New(Config{Backoff: exact}, repo, &fakeEventsRepo{}, notifier, nil)
+
+			before := time.Now().UTC()
+			w.handleFailure(context.Background(), job.Job{ID: "job-1", Type: "test.flaky", Attempts: tc.attempts, MaxAttempts: 20}, tc.err)
+
+			if out.rescheduled != 1 || out.deadLetters != 0 {
+				t.Fatalf("expected a retry, got rescheduled=%d deadLetters=%d", out.rescheduled, out.deadLetters)
+			}
+			if delay := out.runAt.Sub(before); delay < tc.wantDelay || delay > tc.wantDelay+time.Second {
+				t.Fatalf("expected a delay of %s, got %s", tc.wantDelay, delay)
+			}
+		})
+	}
+}
+
+type limitJobsRepo struct {
+	*fakeJobsRepo
+	gotMaxAttempts int
+}
+
+func (r *limitJobsRepo) RescheduleWithMaxAttempts(ctx context.Context, id string, runAt time.Time, errMsg string, maxAttempts int) error {
+	r.gotMaxAttempts = maxAttempts
+	return nil
+}
+
+func TestHandleFailure_PolicyRaisingTheLimitNeedsTheRepo(t *testing.T) {
+	policies := map[string]jobs.RetryPolicy{"test.flaky": {MaxAttempts: 10}}
+	j := job.Job{ID: "job-1", Type: "test.flaky", Attempts: 2, MaxAttempts: 3}
+
+	// the row would stop being claimed at 3, so the repo moves its limit
+	repo := &limitJobsRepo{fakeJobsRepo: &fakeJobsRepo{}}
+	New(Config{}, repo, &fakeEventsRepo{}, nil, nil).WithRetryPolicies(policies).
+		handleFailure(context.Background(), j, errors.New("boom"))
+	if repo.gotMaxAttempts != 10 {
+		t.Fatalf("expected max_attempts moved to 10, got %d", repo.gotMaxAttempts)
+	}
+
+	// without that capability the row's limit caps the policy
+	plain := &fakeJobsRepo{}
+	out := trackFailures(plain)
+	New(Config{}, plain, &fakeEventsRepo{}, nil, nil).WithRetryPolicies(policies).
+		handleFailure(context.Background(), j, errors.New("boom"))
+	if out.deadLetters != 1 {
+		t.Fatalf("expected a dead-letter at the row's limit, got rescheduled=%d", out.rescheduled)
+	}
+}
+
+func TestHandleFailure_UsesConfiguredBackoff(t *testing.T) {
+	var got time.Time
+	repo := &fakeJobsRepo{}
+	repo.rescheduleFn = func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+		got = runAt
+		return nil
+	}
+
+	w := New(Config{Backoff: jobs.Backoff{Base: time.Minute, Jitter: jobs.JitterNone}}, repo, &fakeEventsRepo{}, nil, nil)
+	before := time.Now().UTC()
+	w.handleFailure(context.Background(), job.Job{ID: "job-1", Attempts: 1, MaxAttempts: 5}, errors.New("boom"))
+
+	// attempt 1 with the default multiplier: 2 minutes out
+	if d := got.Sub(before); d < 2*time.Minute || d > 2*time.Minute+time.Second {
+		t.Fatalf("expected a retry 2m out, got %s", d)
+	}
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// scheduleBatch bounds the schedules enqueued per tick; the rest wait a tick.
+const scheduleBatch = 100
+
+// ScheduleEnqueuer turns due recurring schedules into jobs. It returns how
+// many were enqueued; an instance that does not get the scheduler lock
+// enqueues none.
+type ScheduleEnqueuer interface {
+	EnqueueDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type scheduler struct {
+	schedules ScheduleEnqueuer
+	every     time.Duration
+}
+
+// WithScheduler checks for due schedules every `every`. Any number of
+// workers may run it: the enqueuer lets one of them schedule at a time.
+func (w *Worker) WithScheduler(schedules ScheduleEnqueuer, every time.Duration) *Worker {
+	if every <= 0 {
+		every = 15 * time.Second
+	}
+	w.scheduler = &scheduler{schedules: schedules, every: every}
+	return w
+}
+
+func (w *Worker) schedulerLoop(ctx context.Context) {
+	t := time.NewTicker(w.scheduler.every)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			w.enqueueDueSchedules(ctx)
+		}
+	}
+}
+
+func (w *Worker) enqueueDueSchedules(ctx context.Context) {
+	sctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+	defer cancel()
+
+	n, err := w.scheduler.schedules.EnqueueDue(sctx, time.Now().UTC(), scheduleBatch)
+	if err != nil {
+		log.Printf("worker.scheduler enqueue failed err=%v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("worker.scheduler enqueued=%d", n)
+	}
+}
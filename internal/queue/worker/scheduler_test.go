@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeScheduleEnqueuer struct {
+	mu    sync.Mutex
+	calls []time.Time
+	limit int
+}
+
+func (f *fakeScheduleEnqueuer) EnqueueDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, now)
+	f.limit = limit
+	return 1, nil
+}
+
+func (f *fakeScheduleEnqueuer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func TestScheduler_EnqueuesDueSchedulesOnEveryTick(t *testing.T) {
+	schedules := &fakeScheduleEnqueuer{}
+	w := New(Config{WorkerID: "worker-sched", PollInterval: time.Hour}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).
+		WithScheduler(schedules, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for schedules.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if schedules.count() < 3 {
+		t.Fatalf("expected a check per tick, got %d", schedules.count())
+	}
+	schedules.mu.Lock()
+	defer schedules.mu.Unlock()
+	if schedules.limit != scheduleBatch || schedules.calls[0].Location() != time.UTC {
+		t.Fatalf("expected batches of %d at UTC times, got limit=%d now=%s", scheduleBatch, schedules.limit, schedules.calls[0])
+	}
+}
@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/alerting"
+	"github.com/geocoder89/eventhub/internal/canceltoken"
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/emailverify"
+	"github.com/geocoder89/eventhub/internal/exportlink"
+	"github.com/geocoder89/eventhub/internal/exportstore"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewNotifier builds the notifier NOTIFIER_DRIVER selects behind the circuit
+// breaker, each driver with a send timeout that fits it.
+func NewNotifier(cfg config.Config, alerter alerting.Alerter, prom *observability.Prom) *notifications.ProtectedNotifier {
+	sender := notifications.Sender{
+		Address:       cfg.EmailFromAddress,
+		Name:          cfg.EmailFromName,
+		ReplyTo:       cfg.EmailReplyTo,
+		OrganizerName: cfg.EmailFromOrganizerName,
+		CancelPageURL: cfg.EmailCancelPageURL,
+		VerifyPageURL: cfg.EmailVerifyPageURL,
+	}
+	var base notifications.Notifier = notifications.NewLogNotifier().WithSender(sender)
+	sendTimeout := 2 * time.Second
+	switch cfg.NotifierDriver {
+	case "smtp":
+		base = notifications.NewSMTPNotifier(notifications.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+		}).WithSender(sender)
+		// dial, STARTTLS, AUTH and DATA are several round trips to the relay
+		sendTimeout = 10 * time.Second
+	case "webhook":
+		base = notifications.NewWebhookNotifier(cfg.NotifierWebhookURL, cfg.NotifierWebhookSecret, nil)
+		sendTimeout = notifications.DefaultWebhookTimeout
+	}
+
+	return notifications.NewProtectedNotifier(base, notifications.ProtectedNotifierConfig{
+		Timeout:          sendTimeout,
+		FailureThreshold: 3,
+		Cooldown:         15 * time.Second,
+		HalfOpenMaxCalls: 1,
+	}).WithAlerter(alerter).WithProm(prom).
+		// smtp and webhook only send confirmations, announcements and
+		// verifications; the rest is logged rather than retried against a driver that can never
+		// send it
+		WithFallback(notifications.NewLogNotifier().WithSender(sender))
+}
+
+// unboundedJobTypes run for as long as their data takes: exports and sweeps
+// over whole tables. Their lock heartbeat keeps them from being requeued, so
+// they get no Config.JobTimeout.
+var unboundedJobTypes = []string{
+	jobs.TypeRegistrationsExportCSV,
+	jobs.TypeAccountExport,
+	jobs.TypeExportsCleanup,
+	jobs.TypeJobsPurge,
+	jobs.TypeJobsPayloadReport,
+	jobs.TypeEventsVerifyCounters,
+	jobs.TypeRegistrationsLinkUsers,
+}
+
+// NewFromConfig builds the worker cmd/worker and cmd/all run, with every job
+// type registered. healthAddr empty leaves the health server to the caller
+// (see MountHealth).
+func NewFromConfig(cfg config.Config, pool *pgxpool.Pool, prom *observability.Prom, workerID, healthAddr string) (*Worker, error) {
+	exportStore, err := exportstore.NewDirStore(cfg.ExportsDir)
+	if err != nil {
+		return nil, fmt.Errorf("export store %s: %w", cfg.ExportsDir, err)
+	}
+
+	agingInterval, agingMaxBoost := cfg.JobAging()
+	jobsRepo := postgres.NewJobsRepo(pool, prom).
+		WithAging(job.Aging{Interval: agingInterval, MaxBoost: agingMaxBoost}).
+		WithPayloadLimits(job.PayloadLimits{MaxBytes: cfg.JobPayloadMaxBytes, MaxDepth: cfg.JobPayloadMaxDepth})
+	eventsRepo := postgres.NewEventsRepo(pool, prom)
+	registrationsRepo := postgres.NewRegistrationsRepo(pool, prom).WithJobs(jobsRepo)
+	registrationCSVExportsRepo := postgres.NewRegistrationCSVExportsRepo(pool)
+	deliveriesRepo := postgres.NewNotificationsDeliveriesRepo(pool)
+
+	alerter := alerting.NewDefault(cfg.AlertSlackWebhookURL, cfg.AlertSlackMaxPerMinute)
+
+	w := New(Config{
+		PollInterval:    2 * time.Second,
+		WorkerID:        workerID,
+		Concurrency:     1,
+		ShutdownGrace:   10 * time.Second,
+		LockTTL:         30 * time.Second,
+		JobTimeout:      cfg.JobTimeout,
+		TypeConcurrency: cfg.TypeConcurrency(),
+		Prom:            prom,
+		HealthAddr:      healthAddr,
+
+		DegradedPendingAge: cfg.WorkerDegradedPendingAge,
+		EnableTestJobs:     cfg.WorkerEnableTestJobs,
+
+		StaleRequeueAlertThreshold: cfg.AlertStaleRequeueThreshold,
+	}, jobsRepo, eventsRepo, NewNotifier(cfg, alerter, prom), deliveriesRepo).
+		WithWakeups(postgres.ListenNewJobs(pool)).
+		WithLeaderLock(postgres.NewHousekeepingLock(pool)).
+		WithAlerter(alerter).
+		WithRetryPolicies(jobs.DefaultRetryPolicies).
+		WithCounterVerification(postgres.NewEventCountersRepo(pool, prom), jobsRepo).
+		WithCancelTokens(canceltoken.NewSigner(cfg.CancelTokenSigningSecret(), cfg.CancelTokenTTL())).
+		WithRegistrationCSVExporter(registrationsRepo, registrationCSVExportsRepo, exportStore).
+		WithExportCleanup(registrationCSVExportsRepo, exportStore, cfg.ExportRetention(), jobsRepo).
+		WithPayloadReport(jobsRepo, cfg.JobPayloadMaxBytes).
+		WithJobsPurge(jobsRepo).
+		WithRegistrationLinking(registrationsRepo).
+		WithDailyStats(postgres.NewJobStatsRepo(pool, prom), cfg.JobStatsFlushInterval).
+		WithAttemptLog(postgres.NewJobAttemptsRepo(pool, prom), 0).
+		WithScheduler(postgres.NewSchedulesRepo(pool, prom).WithJobs(jobsRepo), cfg.JobSchedulerInterval).
+		WithAccountExporter(AccountExportSources{
+			Users:         postgres.NewUsersRepo(pool),
+			Events:        eventsRepo,
+			Registrations: registrationsRepo,
+			Jobs:          jobsRepo,
+		}, exportStore, exportlink.NewSigner(cfg.ExportLinkSigningSecret(), cfg.ExportLinkTTL())).
+		WithEmailVerification(postgres.NewUsersRepo(pool), emailverify.NewSigner(cfg.EmailVerifySigningSecret(), cfg.EmailVerifyTTL())).
+		WithCapacityAlerts(CapacityAlertSources{
+			Users:  postgres.NewUsersRepo(pool),
+			Events: eventsRepo,
+		}, deliveriesRepo).
+		WithReminders(ReminderSources{
+			Registrations: registrationsRepo,
+			Events:        eventsRepo,
+		}, deliveriesRepo).
+		WithPublishAnnouncements(PublishAnnouncementSources{
+			Registrations: registrationsRepo,
+			Events:        eventsRepo,
+		}, deliveriesRepo, jobsRepo).
+		WithRecipientQuarantine(postgres.NewRecipientQuarantinesRepo(pool, prom), notificationsdelivery.QuarantinePolicy{
+			Threshold: cfg.RecipientQuarantineThreshold,
+			Window:    cfg.RecipientQuarantineWindow,
+		}).
+		WithAttendanceFinalization(AttendanceSources{
+			Events:     eventsRepo,
+			Attendance: postgres.NewAttendanceRepo(pool, prom),
+		}, cfg.AttendanceFinalizeDelay(), jobsRepo).
+		WithWebhooks(postgres.NewWebhooksRepo(pool, prom), nil).
+		WithReadinessCheck(func(cctx context.Context) error {
+			return pool.Ping(cctx)
+		})
+	// core job types; features enabled above registered their own
+	w.Register(jobs.TypeEventPublish, w.HandleEventPublish)
+	w.Register(jobs.TypeRegistrationConfirmation, w.HandleRegistrationConfirmation)
+
+	unbounded := make(map[string]time.Duration, len(unboundedJobTypes))
+	for _, jobType := range unboundedJobTypes {
+		unbounded[jobType] = 0
+	}
+	w.WithJobTimeouts(unbounded)
+
+	return w, nil
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/config"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/geocoder89/eventhub/internal/notifications"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestNewNotifier_LogsWhatTheDriverCannotSend(t *testing.T) {
+	for _, driver := range []string{"log", "smtp", "webhook"} {
+		n := NewNotifier(config.Config{NotifierDriver: driver, NotifierWebhookURL: "http://127.0.0.1:1"}, nil, nil)
+
+		err := n.SendEventReminder(context.Background(), notifications.SendEventReminderInput{Email: "ada@example.com", EventID: "evt-1"})
+		if err != nil {
+			t.Fatalf("%s: expected the reminder logged, got %v", driver, err)
+		}
+	}
+}
+
+func TestNewFromConfig_LongRunningTypesHaveNoTimeout(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://eventhub@127.0.0.1:1/eventhub")
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	defer pool.Close()
+
+	w, err := NewFromConfig(config.Config{ExportsDir: t.TempDir(), NotifierDriver: "log", JobTimeout: 25 * time.Second}, pool, nil, "w-1", "")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	for _, jobType := range []string{
+		jobs.TypeRegistrationsExportCSV,
+		jobs.TypeAccountExport,
+		jobs.TypeExportsCleanup,
+		jobs.TypeJobsPurge,
+		jobs.TypeJobsPayloadReport,
+		jobs.TypeEventsVerifyCounters,
+		jobs.TypeRegistrationsLinkUsers,
+	} {
+		if d := w.jobTimeout(jobType); d != 0 {
+			t.Fatalf("%s: timeout %s, want none", jobType, d)
+		}
+	}
+	if d := w.jobTimeout(jobs.TypeRegistrationConfirmation); d != 25*time.Second {
+		t.Fatalf("expected other types on JOB_TIMEOUT, got %s", d)
+	}
+}
@@ -0,0 +1,293 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type fakeTimer struct {
+	d  time.Duration
+	ch chan time.Time
+}
+
+// fakeClock hands every requested timer to the test, which fires it explicitly.
+type fakeClock struct {
+	timers chan fakeTimer
+	now    time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{timers: make(chan fakeTimer, 8)}
+}
+
+// Now is fixed unless a test sets now.
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.timers <- fakeTimer{d: d, ch: ch}
+	return ch
+}
+
+// waitTimer returns the next timer requested for d, failing the test if none
+// shows up. Timers for other durations are left pending.
+func (c *fakeClock) waitTimer(t *testing.T, d time.Duration) fakeTimer {
+	t.Helper()
+
+	var other []fakeTimer
+	defer func() {
+		for _, ft := range other {
+			c.timers <- ft
+		}
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case ft := <-c.timers:
+			if ft.d == d {
+				return ft
+			}
+			other = append(other, ft)
+		case <-deadline:
+			t.Fatalf("no timer requested for %s", d)
+			return fakeTimer{}
+		}
+	}
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+func runAsync(ctx context.Context, w *Worker) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	return done
+}
+
+func assertRunning(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Run returned early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func waitRun(t *testing.T, done <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Run did not return")
+		return nil
+	}
+}
+
+func readyzStatus(addr string) (int, error) {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://" + addr + "/readyz")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestRun_ShutdownKeepsHealthServerUpForReadinessWindow(t *testing.T) {
+	addr := freeAddr(t)
+	clk := newFakeClock()
+
+	w := New(Config{
+		PollInterval:    10 * time.Millisecond,
+		WorkerID:        "w-test",
+		Concurrency:     1,
+		HealthAddr:      addr,
+		ReadinessWindow: 5 * time.Second,
+	}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil)
+	w.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := runAsync(ctx, w)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		code, err := readyzStatus(addr)
+		if err == nil && code == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health server never became ready: code=%d err=%v", code, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	window := clk.waitTimer(t, 5*time.Second)
+
+	// inside the window: still listening, but reporting not ready
+	code, err := readyzStatus(addr)
+	if err != nil {
+		t.Fatalf("health server should stay up during the readiness window: %v", err)
+	}
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 during the readiness window, got %d", code)
+	}
+	assertRunning(t, done)
+
+	window.ch <- time.Now()
+
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, err := readyzStatus(addr); err == nil {
+		t.Fatalf("health server still serving after Run returned")
+	}
+}
+
+func TestRun_WaitsForInFlightJobsUpToGrace(t *testing.T) {
+	clk := newFakeClock()
+
+	var claimed atomic.Bool
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	repo := &fakeJobsRepo{
+		claimNextFn: func(ctx context.Context, workerID string) (job.Job, error) {
+			if claimed.CompareAndSwap(false, true) {
+				return job.Job{ID: "job-1", Type: "event.publish", Payload: []byte(`{"eventId":"e1"}`), MaxAttempts: 3}, nil
+			}
+			return job.Job{}, job.ErrJobNotFound
+		},
+	}
+	events := &fakeEventsRepo{
+		markPublishedFn: func(ctx context.Context, eventID string) (bool, error) {
+			close(started)
+			<-release
+			return true, nil
+		},
+	}
+
+	w := New(Config{
+		PollInterval:  10 * time.Millisecond,
+		WorkerID:      "w-test",
+		Concurrency:   1,
+		ShutdownGrace: 10 * time.Second,
+	}, repo, events, nil, nil)
+	w.Register(jobs.TypeEventPublish, w.HandleEventPublish)
+	w.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := runAsync(ctx, w)
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("job never started")
+	}
+
+	cancel()
+	grace := clk.waitTimer(t, 10*time.Second)
+	assertRunning(t, done)
+
+	grace.ch <- time.Now()
+
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestRun_AwaitsBackgroundLoops(t *testing.T) {
+	var mu sync.Mutex
+	requeues := 0
+
+	repo := &fakeJobsRepo{
+		requeueStaleProcessingFn: func(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error) {
+			mu.Lock()
+			requeues++
+			mu.Unlock()
+			return job.StaleRequeue{}, nil
+		},
+	}
+
+	w := New(Config{
+		PollInterval:       10 * time.Millisecond,
+		WorkerID:           "w-test",
+		RequeueInterval:    time.Millisecond,
+		MetricsLogInterval: time.Hour,
+	}, repo, &fakeEventsRepo{}, nil, nil)
+	w.clock = newFakeClock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	mu.Lock()
+	after := requeues
+	mu.Unlock()
+	if after == 0 {
+		t.Fatalf("expected the requeue loop to have run")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requeues != after {
+		t.Fatalf("requeue loop kept running after Run returned (%d -> %d)", after, requeues)
+	}
+}
+
+func TestRun_ReturnsHealthServerError(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer taken.Close()
+
+	w := New(Config{
+		PollInterval: 10 * time.Millisecond,
+		WorkerID:     "w-test",
+		HealthAddr:   taken.Addr().String(),
+	}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil)
+	w.clock = newFakeClock()
+
+	done := runAsync(context.Background(), w)
+
+	if err := waitRun(t, done); err == nil {
+		t.Fatalf("expected Run to fail when the health address is in use")
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// ErrJobTimeout is returned when a handler outlives its job type's timeout.
+// It is retried like any other failure.
+var ErrJobTimeout = errors.New("job timeout exceeded")
+
+// defaultJobTimeout sits under the 30s LockTTL the binaries use, so a stuck
+// job fails and is retried before the stale requeue hands it to a second worker.
+const defaultJobTimeout = 25 * time.Second
+
+// bookkeepingTimeout bounds the Reschedule/MarkFailed/MarkDone writes that
+// follow a job.
+const bookkeepingTimeout = 5 * time.Second
+
+// WithJobTimeouts overrides Config.JobTimeout for the given job types.
+func (w *Worker) WithJobTimeouts(timeouts map[string]time.Duration) *Worker {
+	for jobType, d := range timeouts {
+		w.Handlers().SetTimeout(jobType, d)
+	}
+	return w
+}
+
+// jobTimeout is the execution limit for jobType; zero means none.
+func (w *Worker) jobTimeout(jobType string) time.Duration {
+	if d, ok := w.handlers.Timeout(jobType); ok {
+		return d
+	}
+	return w.cfg.JobTimeout
+}
+
+// runWithTimeout runs fn under timeout. A handler that ignores its context
+// (a hung SMTP dial, a time.Sleep) is abandoned when the deadline fires so the
+// worker slot frees up; on shutdown the handler is waited for instead, leaving
+// ShutdownGrace in charge.
+func runWithTimeout(ctx context.Context, timeout time.Duration, fn HandlerFunc, j job.Job) error {
+	if timeout <= 0 {
+		return fn(ctx, j)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(runCtx, j)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return ErrJobTimeout
+		}
+		return err
+	case <-runCtx.Done():
+		if ctx.Err() != nil {
+			return <-done
+		}
+		return ErrJobTimeout
+	}
+}
+
+// bookkeepingContext detaches ctx from its cancellation, keeping request id and
+// trace, so a job's outcome is still recorded after a timeout or shutdown.
+func bookkeepingContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), bookkeepingTimeout)
+}
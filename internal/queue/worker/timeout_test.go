@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+)
+
+func TestJobTimeout_StuckHandlerIsRetriedWithFreshContext(t *testing.T) {
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	var gotErr string
+	var ctxErr error
+	repo := &fakeJobsRepo{
+		rescheduleFn: func(ctx context.Context, id string, runAt time.Time, errMsg string) error {
+			gotErr, ctxErr = errMsg, ctx.Err()
+			return nil
+		},
+		markFailedFn: func(ctx context.Context, id string, errMsg string) error {
+			t.Fatalf("a timed out job with attempts left must be retried, not failed")
+			return nil
+		},
+	}
+	metrics := observability.NewJobMetrics()
+	w := &Worker{cfg: Config{JobTimeout: 50 * time.Millisecond}, repo: repo, metrics: metrics}
+	// ignores its context, like a notifier stuck in a dial
+	w.Register("notify.stuck", func(ctx context.Context, j job.Job) error {
+		<-stuck
+		return nil
+	})
+
+	jobsCh := make(chan job.Job, 1)
+	jobsCh <- job.Job{ID: "job-stuck", Type: "notify.stuck", MaxAttempts: 3}
+	close(jobsCh)
+
+	start := time.Now()
+	w.runWorker(context.Background(), 1, jobsCh)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("worker slot held for %s", elapsed)
+	}
+	if gotErr != "job timeout exceeded" || ctxErr != nil {
+		t.Fatalf("expected a retry with a live context, got err=%q ctxErr=%v", gotErr, ctxErr)
+	}
+	if s := metrics.Snapshot(); s.TimedOut != 1 || s.Retried != 1 {
+		t.Fatalf("expected timedOut=1 retried=1, got %+v", s)
+	}
+}
+
+func TestJobTimeout_PerTypeOverride(t *testing.T) {
+	w := (&Worker{cfg: Config{JobTimeout: 10 * time.Millisecond}}).
+		Register("export.big", func(ctx context.Context, j job.Job) error {
+			select {
+			case <-time.After(50 * time.Millisecond):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}).
+		WithJobTimeouts(map[string]time.Duration{"export.big": time.Second})
+
+	if err := w.execute(context.Background(), job.Job{ID: "job-big", Type: "export.big"}); err != nil {
+		t.Fatalf("expected the per-type timeout to apply, got %v", err)
+	}
+
+	w.Handlers().SetTimeout("export.big", 10*time.Millisecond)
+	if err := w.execute(context.Background(), job.Job{ID: "job-big", Type: "export.big"}); !errors.Is(err, ErrJobTimeout) {
+		t.Fatalf("expected ErrJobTimeout, got %v", err)
+	}
+}
+
+func TestJobTimeout_ShutdownWaitsForHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := (&Worker{cfg: Config{JobTimeout: time.Minute}}).
+		Register("export.big", func(ctx context.Context, j job.Job) error {
+			cancel()
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+
+	if err := w.execute(ctx, job.Job{ID: "job-big", Type: "export.big"}); err != nil {
+		t.Fatalf("expected the handler's own result on shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+// recordSpans installs a recording tracer provider. The worker's tracer is
+// bound to the first provider set, so every test shares this one.
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	})
+	return spans
+}
+
+func jobRunSpan(rec *tracetest.SpanRecorder, jobID string) sdktrace.ReadOnlySpan {
+	for _, s := range rec.Ended() {
+		if s.Name() != "job.run" {
+			continue
+		}
+		for _, a := range s.Attributes() {
+			if a.Key == "job.id" && a.Value.AsString() == jobID {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+func runOneJob(t *testing.T, j job.Job) trace.SpanContext {
+	t.Helper()
+
+	repo := newLeaseJobsRepo(j)
+	seen := make(chan trace.SpanContext, 1)
+	w := New(Config{WorkerID: "worker-a", PollInterval: 10 * time.Millisecond, Concurrency: 1, LockTTL: time.Second}, repo, &fakeEventsRepo{}, nil, nil).
+		Register(j.Type, func(ctx context.Context, j job.Job) error {
+			seen <- trace.SpanContextFromContext(ctx)
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, w)
+
+	var sc trace.SpanContext
+	select {
+	case sc = <-seen:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job never ran")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for repo.status() != job.StatusDone && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return sc
+}
+
+func TestTraceContext_JobRunContinuesTheEnqueuingTrace(t *testing.T) {
+	rec := recordSpans()
+
+	// the API request that enqueued the job
+	reqCtx, reqSpan := otel.Tracer("test-api").Start(context.Background(), "POST /admin/events/:id/publish")
+	traceParent, traceState := observability.TraceParent(reqCtx)
+	reqSpan.End()
+	if traceParent == "" {
+		t.Fatal("expected a traceparent from the request span")
+	}
+
+	j := job.New(job.CreateRequest{Type: "event.publish", TraceParent: traceParent, TraceState: traceState})
+	sc := runOneJob(t, j)
+
+	want := reqSpan.SpanContext()
+	if sc.TraceID() != want.TraceID() {
+		t.Fatalf("job ran in trace %s, want the request's %s", sc.TraceID(), want.TraceID())
+	}
+
+	span := jobRunSpan(rec, j.ID)
+	if span == nil {
+		t.Fatal("expected a job.run span")
+	}
+	if span.SpanContext().TraceID() != want.TraceID() || span.Parent().SpanID() != want.SpanID() {
+		t.Fatalf("expected job.run a child of the request span %s, got trace %s parent %s",
+			want.SpanID(), span.SpanContext().TraceID(), span.Parent().SpanID())
+	}
+}
+
+func TestTraceContext_JobWithoutTraceStartsItsOwn(t *testing.T) {
+	rec := recordSpans()
+
+	j := job.New(job.CreateRequest{Type: "event.publish"})
+	sc := runOneJob(t, j)
+	if !sc.IsValid() {
+		t.Fatal("expected the job to run in a span")
+	}
+
+	span := jobRunSpan(rec, j.ID)
+	if span == nil || span.Parent().IsValid() {
+		t.Fatalf("expected a root job.run span, got %v", span)
+	}
+}
+
+func TestTraceContext_MalformedTraceParentIsIgnored(t *testing.T) {
+	recordSpans()
+
+	bad := "not-a-traceparent"
+	j := job.New(job.CreateRequest{Type: "event.publish"})
+	j.TraceParent = &bad
+	if sc := runOneJob(t, j); !sc.IsValid() {
+		t.Fatal("expected the job to run in a fresh span")
+	}
+}
+
+// historyAttemptStore keeps what is flushed and reads it back, like the
+// Postgres store.
+type historyAttemptStore struct {
+	mu       sync.Mutex
+	attempts []job.Attempt
+}
+
+func (s *historyAttemptStore) InsertBatch(ctx context.Context, attempts []job.Attempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, attempts...)
+	return nil
+}
+
+func (s *historyAttemptStore) ListByJob(ctx context.Context, jobID string) ([]job.Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []job.Attempt
+	for _, a := range s.attempts {
+		if a.JobID == jobID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func spansFor(rec *tracetest.SpanRecorder, name, jobID string) []sdktrace.ReadOnlySpan {
+	var out []sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		if s.Name() != name {
+			continue
+		}
+		for _, a := range s.Attributes() {
+			if a.Key == "job.id" && a.Value.AsString() == jobID {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func linkedSpanIDs(s sdktrace.ReadOnlySpan) []trace.SpanID {
+	var ids []trace.SpanID
+	for _, l := range s.Links() {
+		ids = append(ids, l.SpanContext.SpanID())
+	}
+	return ids
+}
+
+func TestTraceContext_RetriesLinkEarlierAttempts(t *testing.T) {
+	rec := recordSpans()
+
+	store := &historyAttemptStore{}
+	w := New(Config{WorkerID: "w-1"}, &fakeJobsRepo{}, &fakeEventsRepo{}, nil, nil).
+		WithAttemptLog(store, 0).
+		Register("test.flaky", func(ctx context.Context, j job.Job) error { return errors.New("still down") })
+
+	jobID := job.New(job.CreateRequest{Type: "test.flaky"}).ID
+	run := func(attempts int) {
+		jobsCh := make(chan job.Job, 1)
+		jobsCh <- job.Job{ID: jobID, Type: "test.flaky", Attempts: attempts, MaxAttempts: 3}
+		close(jobsCh)
+		w.runWorker(context.Background(), 1, jobsCh)
+	}
+
+	// the first attempt reaches the store, the second is still buffered when
+	// the third, final one starts
+	run(0)
+	if err := w.flushAttempts(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	run(1)
+	run(2)
+
+	runs := spansFor(rec, "job.run", jobID)
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 job.run spans, got %d", len(runs))
+	}
+	for i, s := range runs {
+		var attempt int64
+		for _, a := range s.Attributes() {
+			if a.Key == "job.attempt" {
+				attempt = a.Value.AsInt64()
+			}
+		}
+		if attempt != int64(i+1) {
+			t.Fatalf("span %d: expected job.attempt=%d, got %d", i, i+1, attempt)
+		}
+	}
+
+	first, second, final := runs[0].SpanContext().SpanID(), runs[1].SpanContext().SpanID(), runs[2].SpanContext().SpanID()
+	if got := linkedSpanIDs(runs[0]); len(got) != 0 {
+		t.Fatalf("expected the first attempt unlinked, got %v", got)
+	}
+	if got := linkedSpanIDs(runs[1]); len(got) != 1 || got[0] != first {
+		t.Fatalf("expected the second attempt linked to %s, got %v", first, got)
+	}
+	if got := linkedSpanIDs(runs[2]); len(got) != 2 || got[0] != first || got[1] != second {
+		t.Fatalf("expected the final attempt linked to %s and %s, got %v", first, second, got)
+	}
+
+	dl := spansFor(rec, "job.dead_letter", jobID)
+	if len(dl) != 1 {
+		t.Fatalf("expected one job.dead_letter span, got %d", len(dl))
+	}
+	if got := linkedSpanIDs(dl[0]); len(got) != 1 || got[0] != final {
+		t.Fatalf("expected the dead-letter linked to the final attempt %s, got %v", final, got)
+	}
+	if dl[0].Parent().IsValid() {
+		t.Fatalf("expected the dead-letter in a trace of its own, got parent %s", dl[0].Parent().SpanID())
+	}
+}
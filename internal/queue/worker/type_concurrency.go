@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+)
+
+// JobReleaser is implemented by job repos that can hand a claimed job back
+// unrun, due now and with its attempts untouched. Without it a job over its
+// type's limit runs anyway.
+type JobReleaser interface {
+	Release(ctx context.Context, id, workerID string) error
+}
+
+// TypeExcludingClaimer is implemented by job repos that can leave some job
+// types out of a claim, so types at their limit are not claimed only to be
+// released again.
+type TypeExcludingClaimer interface {
+	ClaimBatchExcluding(ctx context.Context, workerID string, n int, excludeTypes []string) ([]job.Job, error)
+}
+
+// typeSlots counts the jobs executing per type against Config.TypeConcurrency.
+// Types without a positive limit are counted but never refused. A nil
+// typeSlots refuses nothing and counts nothing.
+type typeSlots struct {
+	mu       sync.Mutex
+	limits   map[string]int
+	inFlight map[string]int
+	metrics  *observability.JobMetrics
+}
+
+func newTypeSlots(limits map[string]int, metrics *observability.JobMetrics) *typeSlots {
+	return &typeSlots{limits: limits, inFlight: map[string]int{}, metrics: metrics}
+}
+
+// acquire takes a slot for jobType, reporting false when its limit is reached.
+func (s *typeSlots) acquire(jobType string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit := s.limits[jobType]; limit > 0 && s.inFlight[jobType] >= limit {
+		return false
+	}
+	s.inFlight[jobType]++
+	s.report(jobType)
+	return true
+}
+
+// add takes a slot for jobType whatever its limit, for a job that could not
+// be handed back.
+func (s *typeSlots) add(jobType string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight[jobType]++
+	s.report(jobType)
+}
+
+func (s *typeSlots) release(jobType string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inFlight[jobType] > 0 {
+		s.inFlight[jobType]--
+	}
+	s.report(jobType)
+}
+
+// full lists the types at their limit, in name order.
+func (s *typeSlots) full() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []string
+	for jobType, limit := range s.limits {
+		if limit > 0 && s.inFlight[jobType] >= limit {
+			out = append(out, jobType)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// report must be called with mu held.
+func (s *typeSlots) report(jobType string) {
+	if s.metrics != nil {
+		s.metrics.SetInFlight(jobType, s.inFlight[jobType])
+	}
+}
+
+// releaseOverLimit hands j back to the queue because its type has no free
+// slot. It reports whether it did; false means the repo cannot release and
+// the caller runs j over the limit instead.
+func (w *Worker) releaseOverLimit(ctx context.Context, j job.Job) bool {
+	r, ok := w.repo.(JobReleaser)
+	if !ok {
+		slog.Default().WarnContext(ctx, "job.type_limit_exceeded",
+			"worker_id", w.cfg.WorkerID,
+			"job_id", j.ID,
+			"job_type", j.Type,
+			"limit", w.cfg.TypeConcurrency[j.Type],
+		)
+		return false
+	}
+
+	rctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+	if err := r.Release(rctx, j.ID, w.cfg.WorkerID); err != nil {
+		// the lock expires and the stale requeue returns it
+		slog.Default().WarnContext(ctx, "job.release_failed",
+			"worker_id", w.cfg.WorkerID,
+			"job_id", j.ID,
+			"job_type", j.Type,
+			"err", err,
+		)
+		return true
+	}
+
+	if w.metrics != nil {
+		w.metrics.IncReleased()
+	}
+	slog.Default().InfoContext(ctx, "job.released",
+		"worker_id", w.cfg.WorkerID,
+		"job_id", j.ID,
+		"job_type", j.Type,
+		"limit", w.cfg.TypeConcurrency[j.Type],
+	)
+	return true
+}
@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// memoryQueue is a job table: claims take pending jobs in order, releases
+// put them back at the end, done jobs are counted.
+type memoryQueue struct {
+	fakeJobsRepo
+	mu        sync.Mutex
+	pending   []job.Job
+	done      int
+	released  int
+	excluding bool
+}
+
+func (q *memoryQueue) ClaimBatch(ctx context.Context, workerID string, n int) ([]job.Job, error) {
+	return q.claim(n, nil), nil
+}
+
+func (q *memoryQueue) claim(n int, exclude []string) []job.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var batch, rest []job.Job
+	for _, j := range q.pending {
+		if len(batch) < n && !slices.Contains(exclude, j.Type) {
+			batch = append(batch, j)
+			continue
+		}
+		rest = append(rest, j)
+	}
+	q.pending = rest
+	return batch
+}
+
+func (q *memoryQueue) Release(ctx context.Context, id, workerID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.released++
+	q.pending = append(q.pending, job.Job{ID: id, Type: typeOf(id), MaxAttempts: 3})
+	return nil
+}
+
+func (q *memoryQueue) MarkDone(ctx context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.done++
+	return nil
+}
+
+func (q *memoryQueue) doneCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.done
+}
+
+// excludingQueue also leaves full types out of claims.
+type excludingQueue struct {
+	*memoryQueue
+}
+
+func (q excludingQueue) ClaimBatchExcluding(ctx context.Context, workerID string, n int, excludeTypes []string) ([]job.Job, error) {
+	return q.claim(n, excludeTypes), nil
+}
+
+// job ids are "<type>#<n>" so a released job can be rebuilt from its id
+func typeOf(id string) string {
+	for i := len(id) - 1; i >= 0; i-- {
+		if id[i] == '#' {
+			return id[:i]
+		}
+	}
+	return id
+}
+
+func TestTypeConcurrency_LimitHoldsUnderLoad(t *testing.T) {
+	for name, excluding := range map[string]bool{"release only": false, "claim excludes full types": true} {
+		t.Run(name, func(t *testing.T) {
+			q := &memoryQueue{}
+			for i := 0; i < 20; i++ {
+				q.pending = append(q.pending, job.Job{ID: fmt.Sprintf("export#%d", i), Type: "export", MaxAttempts: 3})
+			}
+			for i := 0; i < 5; i++ {
+				q.pending = append(q.pending, job.Job{ID: fmt.Sprintf("confirm#%d", i), Type: "confirm", MaxAttempts: 3})
+			}
+			total := len(q.pending)
+
+			var repo JobsRepository = q
+			if excluding {
+				repo = excludingQueue{q}
+			}
+
+			var running, maxRunning atomic.Int32
+			var confirmsDone atomic.Int32
+			w := New(Config{
+				PollInterval:    5 * time.Millisecond,
+				Concurrency:     4,
+				TypeConcurrency: map[string]int{"export": 2},
+			}, repo, &fakeEventsRepo{}, nil, nil).
+				Register("export", func(ctx context.Context, j job.Job) error {
+					n := running.Add(1)
+					for {
+						m := maxRunning.Load()
+						if n <= m || maxRunning.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					running.Add(-1)
+					return nil
+				}).
+				Register("confirm", func(ctx context.Context, j job.Job) error {
+					confirmsDone.Add(1)
+					return nil
+				})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := runAsync(ctx, w)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for q.doneCount() < total && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			if got := q.doneCount(); got != total {
+				t.Fatalf("expected all %d jobs done, got %d", total, got)
+			}
+			if got := maxRunning.Load(); got > 2 {
+				t.Fatalf("expected at most 2 exports at once, saw %d", got)
+			}
+			if confirmsDone.Load() != 5 {
+				t.Fatalf("expected every confirmation to run, got %d", confirmsDone.Load())
+			}
+		})
+	}
+}
+
+func TestClaimUpTo_SkipsFullTypes(t *testing.T) {
+	q := &memoryQueue{pending: []job.Job{
+		{ID: "export#1", Type: "export"},
+		{ID: "confirm#1", Type: "confirm"},
+	}}
+	w := New(Config{TypeConcurrency: map[string]int{"export": 1}}, excludingQueue{q}, &fakeEventsRepo{}, nil, nil)
+	w.slots.acquire("export")
+
+	batch, err := w.claimUpTo(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(batch) != 1 || batch[0].ID != "confirm#1" {
+		t.Fatalf("expected only the confirmation claimed while export is full, got %v", batch)
+	}
+}
+
+func TestTypeConcurrency_OverLimitJobIsReleasedNotRun(t *testing.T) {
+	q := &memoryQueue{}
+	w := New(Config{TypeConcurrency: map[string]int{"export": 1}}, q, &fakeEventsRepo{}, nil, nil).
+		Register("export", func(ctx context.Context, j job.Job) error {
+			t.Errorf("job %s ran over its type limit", j.ID)
+			return nil
+		})
+
+	// one export already holds the only slot
+	if !w.slots.acquire("export") {
+		t.Fatal("expected the first slot to be free")
+	}
+
+	jobsCh := make(chan job.Job, 1)
+	jobsCh <- job.Job{ID: "export#1", Type: "export", MaxAttempts: 3}
+	close(jobsCh)
+	w.runWorker(context.Background(), 1, jobsCh)
+
+	if q.released != 1 || len(q.pending) != 1 || q.pending[0].ID != "export#1" {
+		t.Fatalf("expected the job handed back to the queue, released=%d pending=%v", q.released, q.pending)
+	}
+	if w.busy.Load() != 0 {
+		t.Fatalf("a released job must not hold an executor, busy=%d", w.busy.Load())
+	}
+	if got := w.slots.full(); len(got) != 1 || got[0] != "export" {
+		t.Fatalf("expected export still full from the running job, got %v", got)
+	}
+}
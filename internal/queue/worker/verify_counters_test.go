@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type fakeCounterVerifier struct {
+	since     time.Time
+	batchSize int
+	stats     event.DriftStats
+}
+
+func (f *fakeCounterVerifier) VerifyActive(ctx context.Context, since time.Time, batchSize int) (event.DriftStats, error) {
+	f.since = since
+	f.batchSize = batchSize
+	return f.stats, nil
+}
+
+type resultJobsRepo struct {
+	fakeJobsRepo
+	results map[string]json.RawMessage
+	created []job.CreateRequest
+}
+
+func (r *resultJobsRepo) SetResult(ctx context.Context, id string, result json.RawMessage) error {
+	r.results[id] = result
+	return nil
+}
+
+func (r *resultJobsRepo) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
+	r.created = append(r.created, req)
+	return job.New(req), nil
+}
+
+func TestExecuteVerifyCounters_StoresStatsAndSchedulesNextRun(t *testing.T) {
+	repo := &resultJobsRepo{results: map[string]json.RawMessage{}}
+	verifier := &fakeCounterVerifier{stats: event.DriftStats{Checked: 12, Drifted: 2, MaxDelta: 5}}
+
+	w := &Worker{repo: repo}
+	w.WithCounterVerification(verifier, repo)
+
+	payload, _ := jobs.EventsVerifyCountersPayload{LookbackDays: 30, BatchSize: 50}.JSON()
+	j := job.Job{ID: "job-verify", Type: jobs.TypeEventsVerifyCounters, Payload: payload}
+
+	before := time.Now().UTC()
+	if err := w.execute(context.Background(), j); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if verifier.batchSize != 50 {
+		t.Fatalf("expected batch size from payload, got %d", verifier.batchSize)
+	}
+	if d := before.Sub(verifier.since); d < 29*24*time.Hour || d > 31*24*time.Hour {
+		t.Fatalf("expected ~30 day lookback, got %s", d)
+	}
+
+	var got event.DriftStats
+	if err := json.Unmarshal(repo.results[j.ID], &got); err != nil {
+		t.Fatalf("decode stored result: %v (%s)", err, repo.results[j.ID])
+	}
+	if got != verifier.stats {
+		t.Fatalf("stored result=%+v want %+v", got, verifier.stats)
+	}
+
+	if len(repo.created) != 1 {
+		t.Fatalf("expected next run to be scheduled, got %d creates", len(repo.created))
+	}
+	next := repo.created[0]
+	if next.Type != jobs.TypeEventsVerifyCounters || next.IdempotencyKey == nil {
+		t.Fatalf("unexpected scheduled job: %+v", next)
+	}
+	if *next.IdempotencyKey != jobs.VerifyCountersKey(next.RunAt) || !next.RunAt.After(before.Add(6*24*time.Hour)) {
+		t.Fatalf("expected next weekly run, got runAt=%s key=%s", next.RunAt, *next.IdempotencyKey)
+	}
+}
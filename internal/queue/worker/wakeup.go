@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// WakeupListenFunc blocks, calling notify whenever a job becomes claimable,
+// until ctx is done or its connection fails.
+type WakeupListenFunc func(ctx context.Context, notify func(jobType string)) error
+
+// wakeupRetry is how long the worker falls back to plain polling after the
+// wakeup connection drops.
+const wakeupRetry = 5 * time.Second
+
+// WithWakeups makes the worker claim as soon as listen reports a new job
+// rather than on its next poll. The poll keeps running as the fallback.
+func (w *Worker) WithWakeups(listen WakeupListenFunc) *Worker {
+	w.wakeupListen = listen
+	w.wake = make(chan struct{}, 1)
+	return w
+}
+
+// nudge asks the producer loop to claim now; nudges pending a claim collapse.
+func (w *Worker) nudge() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Worker) wakeupLoop(ctx context.Context) {
+	for {
+		err := w.wakeupListen(ctx, func(string) { w.nudge() })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("worker.wakeups listen failed; polling every %s until it reconnects: %v", w.cfg.PollInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.clock.After(wakeupRetry):
+		}
+		// anything enqueued while disconnected is picked up by the poll, but
+		// there is no reason to wait for it
+		w.nudge()
+	}
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+func TestWakeups_ClaimWithoutWaitingForPoll(t *testing.T) {
+	var pending atomic.Bool
+	repo := &fakeJobsRepo{
+		claimNextFn: func(ctx context.Context, workerID string) (job.Job, error) {
+			if pending.CompareAndSwap(true, false) {
+				return job.Job{ID: "job-1", Type: "crm.sync", MaxAttempts: 3}, nil
+			}
+			return job.Job{}, job.ErrJobNotFound
+		},
+	}
+
+	notifications := make(chan string)
+	listen := func(ctx context.Context, notify func(string)) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobType := <-notifications:
+				notify(jobType)
+			}
+		}
+	}
+
+	ran := make(chan time.Time, 1)
+	w := New(Config{PollInterval: time.Hour, Concurrency: 1}, repo, &fakeEventsRepo{}, nil, nil).
+		WithWakeups(listen).
+		Register("crm.sync", func(ctx context.Context, j job.Job) error {
+			ran <- time.Now()
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := runAsync(ctx, w)
+
+	pending.Store(true)
+	enqueuedAt := time.Now()
+	notifications <- "crm.sync"
+
+	select {
+	case at := <-ran:
+		if latency := at.Sub(enqueuedAt); latency > 500*time.Millisecond {
+			t.Fatalf("claimed after %s", latency)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("job not claimed after the wakeup")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestWakeups_ReconnectAfterListenFailure(t *testing.T) {
+	clock := newFakeClock()
+	var calls atomic.Int32
+	w := (&Worker{clock: clock}).WithWakeups(func(ctx context.Context, notify func(string)) error {
+		if calls.Add(1) == 1 {
+			return errors.New("connection reset")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		w.wakeupLoop(ctx)
+		close(stopped)
+	}()
+
+	clock.waitTimer(t, wakeupRetry).ch <- time.Now()
+
+	// the reconnect nudges the producer to catch up on anything it missed
+	select {
+	case <-w.wake:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a catch-up nudge after reconnecting")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the listener restarted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	<-stopped
+}
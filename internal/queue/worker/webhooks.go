@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// WebhookStore looks up webhooks and logs every delivery attempt.
+type WebhookStore interface {
+	GetByID(ctx context.Context, id string) (webhook.Webhook, error)
+	RecordDelivery(ctx context.Context, d webhook.Delivery) error
+}
+
+type webhookDeliverer struct {
+	store  WebhookStore
+	client *http.Client
+}
+
+// WithWebhooks enables webhook.deliver. A nil client gets a 10s timeout.
+func (w *Worker) WithWebhooks(store WebhookStore, client *http.Client) *Worker {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	w.webhooks = &webhookDeliverer{store: store, client: client}
+	return w.Register(jobs.TypeWebhookDeliver, w.deliverWebhook)
+}
+
+// deliverWebhook posts the payload, signed with the webhook's secret. A
+// transport error or a non-2xx answer fails the job so it is retried with
+// the usual backoff; a webhook deleted or disabled since is dropped.
+func (w *Worker) deliverWebhook(ctx context.Context, j job.Job) error {
+	if w.webhooks == nil {
+		return fmt.Errorf("webhook delivery not configured")
+	}
+	wd := w.webhooks
+
+	var p jobs.WebhookDeliverPayload
+	if err := json.Unmarshal(j.Payload, &p); err != nil {
+		return invalidPayload(err)
+	}
+
+	hook, err := wd.store.GetByID(ctx, p.WebhookID)
+	if err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !hook.Subscribes(p.Kind) {
+		log.Printf("webhook: skipped webhook=%s kind=%s reason=unsubscribed job=%s", hook.ID, p.Kind, j.ID)
+		return nil
+	}
+
+	body, err := json.Marshal(webhook.Envelope{ID: j.ID, Kind: p.Kind, OccurredAt: p.OccurredAt, Data: p.Data})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EventHub-Webhooks/1")
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, body))
+	req.Header.Set("X-EventHub-Event", p.Kind)
+	req.Header.Set("X-EventHub-Delivery", j.ID)
+
+	d := webhook.Delivery{WebhookID: hook.ID, JobID: j.ID, Kind: p.Kind, Attempt: j.Attempts + 1}
+
+	start := time.Now()
+	resp, sendErr := wd.client.Do(req)
+	d.DurationMS = time.Since(start).Milliseconds()
+
+	if sendErr == nil {
+		// drain so the connection can be reused
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+
+		code := resp.StatusCode
+		d.StatusCode = &code
+		if !d.Succeeded() {
+			sendErr = fmt.Errorf("webhook %s answered %d", hook.ID, code)
+		}
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		d.Error = &msg
+	}
+
+	if err := wd.store.RecordDelivery(ctx, d); err != nil {
+		log.Printf("webhook: record delivery failed webhook=%s job=%s err=%v", hook.ID, j.ID, err)
+	}
+
+	if sendErr != nil {
+		return sendErr
+	}
+
+	log.Printf("webhook: delivered webhook=%s kind=%s status=%d attempt=%d job=%s", hook.ID, p.Kind, *d.StatusCode, d.Attempt, j.ID)
+	return nil
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+type fakeWebhookStore struct {
+	hook       webhook.Webhook
+	deliveries []webhook.Delivery
+}
+
+func (f *fakeWebhookStore) GetByID(ctx context.Context, id string) (webhook.Webhook, error) {
+	if id != f.hook.ID {
+		return webhook.Webhook{}, webhook.ErrNotFound
+	}
+	return f.hook, nil
+}
+
+func (f *fakeWebhookStore) RecordDelivery(ctx context.Context, d webhook.Delivery) error {
+	f.deliveries = append(f.deliveries, d)
+	return nil
+}
+
+func webhookJob(t *testing.T, attempts int) job.Job {
+	t.Helper()
+
+	raw, err := jobs.WebhookDeliverPayload{
+		WebhookID:  "wh-1",
+		Kind:       webhook.KindRegistrationCreated,
+		OccurredAt: time.Now().UTC(),
+		Data:       json.RawMessage(`{"registrationId":"reg-1"}`),
+	}.JSON()
+	if err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	return job.Job{ID: "job-webhook", Type: jobs.TypeWebhookDeliver, Payload: raw, Attempts: attempts}
+}
+
+func TestDeliverWebhook_SignsBody(t *testing.T) {
+	var gotSig, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotSig, gotBody = r.Header.Get(webhook.SignatureHeader), string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := &fakeWebhookStore{hook: webhook.Webhook{
+		ID: "wh-1", URL: srv.URL, Secret: "whsec_test", Enabled: true,
+		EventKinds: []string{webhook.KindRegistrationCreated},
+	}}
+	w := (&Worker{}).WithWebhooks(store, srv.Client())
+
+	if err := w.execute(context.Background(), webhookJob(t, 0)); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if gotSig != webhook.Sign("whsec_test", []byte(gotBody)) {
+		t.Fatalf("signature %q does not match body %s", gotSig, gotBody)
+	}
+	var env webhook.Envelope
+	if err := json.Unmarshal([]byte(gotBody), &env); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if env.ID != "job-webhook" || env.Kind != webhook.KindRegistrationCreated || string(env.Data) != `{"registrationId":"reg-1"}` {
+		t.Fatalf("unexpected envelope: %+v", env)
+	}
+	if len(store.deliveries) != 1 || !store.deliveries[0].Succeeded() || store.deliveries[0].Attempt != 1 {
+		t.Fatalf("expected one successful delivery logged, got %+v", store.deliveries)
+	}
+}
+
+func TestDeliverWebhook_Non2xxIsRetried(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	store := &fakeWebhookStore{hook: webhook.Webhook{
+		ID: "wh-1", URL: srv.URL, Secret: "whsec_test", Enabled: true,
+		EventKinds: []string{webhook.KindRegistrationCreated},
+	}}
+	w := (&Worker{}).WithWebhooks(store, srv.Client())
+
+	if err := w.execute(context.Background(), webhookJob(t, 2)); err == nil {
+		t.Fatalf("expected a 503 to fail the job")
+	}
+	if len(store.deliveries) != 1 {
+		t.Fatalf("expected the failed attempt logged, got %+v", store.deliveries)
+	}
+	d := store.deliveries[0]
+	if d.StatusCode == nil || *d.StatusCode != http.StatusServiceUnavailable || d.Error == nil || d.Attempt != 3 {
+		t.Fatalf("unexpected delivery: %+v", d)
+	}
+}
+
+func TestDeliverWebhook_DisabledIsDropped(t *testing.T) {
+	store := &fakeWebhookStore{hook: webhook.Webhook{
+		ID: "wh-1", URL: "http://127.0.0.1:1", Enabled: false,
+		EventKinds: []string{webhook.KindRegistrationCreated},
+	}}
+	w := (&Worker{}).WithWebhooks(store, nil)
+
+	if err := w.execute(context.Background(), webhookJob(t, 0)); err != nil {
+		t.Fatalf("a disabled webhook should not fail the job: %v", err)
+	}
+	if len(store.deliveries) != 0 {
+		t.Fatalf("expected no delivery, got %+v", store.deliveries)
+	}
+}
@@ -1,33 +1,40 @@
 package worker
 
 import (
-	"bytes"
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/actorctx"
+	"github.com/geocoder89/eventhub/internal/alerting"
+	"github.com/geocoder89/eventhub/internal/canceltoken"
+	"github.com/geocoder89/eventhub/internal/domain/event"
 	"github.com/geocoder89/eventhub/internal/domain/job"
 	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
 	"github.com/geocoder89/eventhub/internal/domain/registration"
 	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
+	"github.com/geocoder89/eventhub/internal/exportstore"
 	"github.com/geocoder89/eventhub/internal/jobs"
 	"github.com/geocoder89/eventhub/internal/notifications"
 	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/geocoder89/eventhub/internal/repo/postgres"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/netutil"
+	"golang.org/x/sync/errgroup"
 )
 
 type publishPayload struct {
@@ -38,8 +45,10 @@ type publishPayload struct {
 type JobsRepository interface {
 	ClaimNext(ctx context.Context, workerID string) (job.Job, error)
 	// FetchNextPending(ctx context.Context) (job.Job, error)
-	RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (int64, error)
+	RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error)
 	Reschedule(ctx context.Context, id string, runAt time.Time, errMsg string) error
+	// MarkFailed is terminal: the job is dead-lettered along with the status
+	// change, so nothing else needs to record it.
 	MarkFailed(ctx context.Context, id string, errMsg string) error
 	MarkDone(ctx context.Context, id string) error
 }
@@ -48,21 +57,101 @@ type EventsRepository interface {
 	MarkPublished(ctx context.Context, eventID string) (bool, error)
 }
 
+// PublishAnnouncementMarker is implemented by events repositories that
+// record, in the same write that publishes an event, that its attendees are
+// still to be told, until MarkPublishAnnounced.
+type PublishAnnouncementMarker interface {
+	PublishAnnouncementPending(ctx context.Context, eventID string) (bool, error)
+	MarkPublishAnnounced(ctx context.Context, eventID string) error
+}
+
+// EventReader is implemented by events repositories that can load an event,
+// which lets confirmations name the event.
+type EventReader interface {
+	GetByID(ctx context.Context, id string) (event.Event, error)
+}
+
+// RegistrationsExportReader pages through an event's registrations so exports
+// never hold the whole list in memory.
 type RegistrationsExportReader interface {
-	ListForEventExport(ctx context.Context, eventID string) ([]registration.Registration, error)
+	ListByEventCursor(
+		ctx context.Context,
+		eventID string,
+		filter registration.ListFilter,
+		limit int,
+		afterCreatedAt time.Time,
+		afterID string,
+	) (items []registration.Registration, nextCursor *string, hasMore bool, err error)
 }
 
 type RegistrationCSVExportsWriter interface {
 	Save(ctx context.Context, export registrationexport.CSVExport) error
 }
 
+type CounterVerifier interface {
+	VerifyActive(ctx context.Context, since time.Time, batchSize int) (event.DriftStats, error)
+}
+
+type JobsEnqueuer interface {
+	Create(ctx context.Context, req job.CreateRequest) (job.Job, error)
+}
+
+// JobResultWriter is implemented by job repos that can persist a job's outcome.
+type JobResultWriter interface {
+	SetResult(ctx context.Context, id string, result json.RawMessage) error
+}
+
 type Config struct {
 	PollInterval  time.Duration
 	WorkerID      string
 	Concurrency   int // concurrency control
 	ShutdownGrace time.Duration
-	LockTTL       time.Duration
-	HealthAddr    string
+	// LockTTL is how long a processing job's lock lasts before the stale
+	// requeue takes it back; running jobs renew it every LockTTL/3.
+	LockTTL    time.Duration
+	HealthAddr string
+
+	// ReadinessWindow is how long /readyz reports 503 before the health
+	// listener shuts down; HealthShutdownTimeout bounds that shutdown.
+	ReadinessWindow       time.Duration
+	HealthShutdownTimeout time.Duration
+	// HealthMaxConns caps open connections to the health listener; further
+	// connections wait in the accept queue. Zero means 32.
+	HealthMaxConns     int
+	MetricsLogInterval time.Duration
+	RequeueInterval    time.Duration
+
+	// JobTimeout bounds a single job run; WithJobTimeouts overrides it per
+	// type. With lock heartbeats it may exceed LockTTL.
+	JobTimeout time.Duration
+
+	// Backoff spaces out retries of failed jobs; zero fields use
+	// jobs.DefaultBackoff.
+	Backoff jobs.Backoff
+
+	// StaleRequeueAlertThreshold raises an alert when one stale requeue pass
+	// takes back at least this many jobs; zero never alerts.
+	StaleRequeueAlertThreshold int
+
+	// TypeConcurrency caps how many jobs of a type run at once in this
+	// worker, so one flooded type cannot take every slot. Types at their cap
+	// are left out of claims, and a job claimed over it is released back to
+	// the queue rather than waiting for a slot. Missing or zero means no cap.
+	TypeConcurrency map[string]int
+
+	// EnableTestJobs registers the test.* job types the e2e scripts use to
+	// crash or drain a worker mid-job. Off, such jobs are unknown types and
+	// dead-letter on their first attempt.
+	EnableTestJobs bool
+
+	// DegradedPendingAge makes /readyz report "degraded": true, still with a
+	// 200, once the oldest due pending job has waited longer than this. Zero
+	// never degrades.
+	DegradedPendingAge time.Duration
+
+	// Prom gets the per-type job duration, result and in-flight series
+	// (eventhub_jobs_*); nil leaves them out.
+	Prom *observability.Prom
 }
 
 type Worker struct {
@@ -72,12 +161,60 @@ type Worker struct {
 	metrics        *observability.JobMetrics
 	regsExport     RegistrationsExportReader
 	csvExports     RegistrationCSVExportsWriter
+	exportStore    exportstore.Store
 	notifier       notifications.Notifier
 	deliveries     *postgres.NotificationsDeliveriesRepo
 	readyMu        sync.RWMutex
 	ready          bool
+	pause          pauseState
+	queueHealth    queueHealthState
 	readinessCheck func(ctx context.Context) error
 	PromRegistry   *prometheus.Registry
+	cancelTokens   *canceltoken.Signer
+	counters       CounterVerifier
+	enqueuer       JobsEnqueuer
+	accountExport  *accountExporter
+	capacityAlerts *capacityAlerter
+	verifications  *emailVerifier
+	reminders      *reminderSender
+	announcer      *publishAnnouncer
+	quarantine     *recipientQuarantine
+	exportCleanup  *exportCleaner
+	attendance     *attendanceFinalizer
+	webhooks       *webhookDeliverer
+	payloadReport  *payloadReporter
+	jobsPurger     JobsPurger
+	userLinker     RegistrationUserLinker
+	dailyStats     *dailyStats
+	attempts       *attemptLog
+	scheduler      *scheduler
+	handlers       *HandlerRegistry
+	wakeupListen   WakeupListenFunc
+	wake           chan struct{}
+	busy           atomic.Int32
+	slots          *typeSlots
+	leadership     *leadership
+	clock          clock
+	alerter        alerting.Alerter
+}
+
+// promJobResult is the result label eventhub_jobs_* record an attempt's
+// outcome under. An outcome that could not be recorded counts as failed.
+func promJobResult(outcome job.AttemptOutcome) string {
+	switch outcome {
+	case job.AttemptDone:
+		return "done"
+	case job.AttemptRetried:
+		return "retry"
+	case job.AttemptDeadLettered:
+		return "dead_letter"
+	case job.AttemptCancelled:
+		return "cancelled"
+	case job.AttemptInterrupted:
+		return "interrupted"
+	default:
+		return "failed"
+	}
 }
 
 func optional(v *string) string {
@@ -129,30 +266,116 @@ func New(cfg Config, repo JobsRepository, events EventsRepository, notifier noti
 	if cfg.ShutdownGrace <= 0 {
 		cfg.ShutdownGrace = 10 * time.Second
 	}
-	return &Worker{
+
+	if cfg.ReadinessWindow <= 0 {
+		cfg.ReadinessWindow = 5 * time.Second
+	}
+
+	if cfg.HealthShutdownTimeout <= 0 {
+		cfg.HealthShutdownTimeout = 2 * time.Second
+	}
+	if cfg.HealthMaxConns <= 0 {
+		cfg.HealthMaxConns = defaultHealthMaxConns
+	}
+
+	if cfg.MetricsLogInterval <= 0 {
+		cfg.MetricsLogInterval = 30 * time.Second
+	}
+
+	if cfg.RequeueInterval <= 0 {
+		cfg.RequeueInterval = 10 * time.Second
+	}
+
+	if cfg.JobTimeout <= 0 {
+		cfg.JobTimeout = defaultJobTimeout
+	}
+	cfg.Backoff = cfg.Backoff.WithDefaults()
+	metrics := observability.NewJobMetrics()
+	w := &Worker{
 		cfg:        cfg,
 		repo:       repo,
 		events:     events,
-		metrics:    observability.NewJobMetrics(),
+		metrics:    metrics,
+		slots:      newTypeSlots(cfg.TypeConcurrency, metrics),
 		notifier:   notifier,
 		deliveries: deliveries,
 		ready:      true,
+		handlers:   NewHandlerRegistry(),
+		clock:      realClock{},
+	}
+	if !cfg.EnableTestJobs {
+		return w
 	}
+	// the e2e scripts kill or drain the worker mid-job, so these outlast the default
+	w.Register("test.crash", testCrashJob)
+	w.Register("test.slow", testSlowJob)
+	return w.WithJobTimeouts(map[string]time.Duration{
+		"test.crash": 2 * time.Minute,
+		"test.slow":  5 * time.Minute,
+	})
 }
 
-func (w *Worker) WithRegistrationCSVExporter(reader RegistrationsExportReader, writer RegistrationCSVExportsWriter) *Worker {
+// WithRegistrationCSVExporter enables registrations.export_csv: rows are
+// streamed into store and writer records where the file landed.
+func (w *Worker) WithRegistrationCSVExporter(reader RegistrationsExportReader, writer RegistrationCSVExportsWriter, store exportstore.Store) *Worker {
 	w.regsExport = reader
 	w.csvExports = writer
+	w.exportStore = store
+	return w.Register(jobs.TypeRegistrationsExportCSV, func(ctx context.Context, j job.Job) error {
+		var p jobs.RegistrationsExportCSVPayload
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+
+		return w.exportRegistrationsCSV(ctx, j.ID, p)
+	})
+}
+
+// WithCancelTokens embeds a signed self-service cancellation token in every
+// registration confirmation.
+func (w *Worker) WithCancelTokens(signer *canceltoken.Signer) *Worker {
+	w.cancelTokens = signer
 	return w
 }
 
+// WithCounterVerification enables the weekly events.verify_counters job; the
+// worker schedules the current week's run on start and each run schedules the next.
+func (w *Worker) WithCounterVerification(v CounterVerifier, enq JobsEnqueuer) *Worker {
+	w.counters = v
+	w.enqueuer = enq
+	return w.Register(jobs.TypeEventsVerifyCounters, w.verifyCounters)
+}
+
 func (w *Worker) WithReadinessCheck(check func(ctx context.Context) error) *Worker {
 	w.readinessCheck = check
 	return w
 }
 
+// WithAlerter pings a human on dead-letters and stale requeue
+// storms; without one those are only logged.
+func (w *Worker) WithAlerter(a alerting.Alerter) *Worker {
+	w.alerter = a
+	return w
+}
+
+// alert never fails the caller: an alert that cannot be sent is logged.
+func (w *Worker) alert(ctx context.Context, severity alerting.Severity, title, body string, labels map[string]string) {
+	if w.alerter == nil {
+		return
+	}
+	if err := w.alerter.Notify(ctx, severity, title, body, labels); err != nil {
+		slog.Default().WarnContext(ctx, "worker.alert_failed", "title", title, "err", err)
+	}
+}
+
 var tracer = otel.Tracer("eventhub-worker")
 
+func (w *Worker) setReady(ready bool) {
+	w.readyMu.Lock()
+	w.ready = ready
+	w.readyMu.Unlock()
+}
+
 func (w *Worker) logMetricsLoop(ctx context.Context, every time.Duration) {
 	t := time.NewTicker(every)
 
@@ -164,17 +387,28 @@ func (w *Worker) logMetricsLoop(ctx context.Context, every time.Duration) {
 			return
 
 		case <-t.C:
-			s := w.metrics.Snapshot()
-			log.Printf(
-				"job metrics claimed=%d done=%d failed=%d retried=%d dlq=%d duration_count=%d dur_avg=%s duration_max=%s",
-				s.Claimed, s.Done, s.Failed, s.Retried, s.DeadLettered, s.DurationCount, s.AverageDuration, s.MaxDuration,
-			)
+			w.logMetrics(ctx)
 		}
 	}
 }
 
+// logMetrics logs this instance's job totals. The housekeeping leader also
+// refreshes eventhub_jobs_oldest_pending_seconds between probes; the queue is
+// shared, so one replica reading it is enough.
+func (w *Worker) logMetrics(ctx context.Context) {
+	if w.leadsHousekeeping() {
+		w.queueStats(ctx)
+	}
+
+	s := w.metrics.Snapshot()
+	log.Printf(
+		"job metrics claimed=%d done=%d failed=%d retried=%d dlq=%d timed_out=%d duration_count=%d dur_avg=%s duration_max=%s",
+		s.Claimed, s.Done, s.Failed, s.Retried, s.DeadLettered, s.TimedOut, s.DurationCount, s.AverageDuration, s.MaxDuration,
+	)
+}
+
 func (w *Worker) requeueLoop(ctx context.Context) {
-	t := time.NewTicker(10 * time.Second)
+	t := time.NewTicker(w.cfg.RequeueInterval)
 	defer t.Stop()
 
 	for {
@@ -183,65 +417,190 @@ func (w *Worker) requeueLoop(ctx context.Context) {
 			return
 
 		case <-t.C:
-			// short timeout for housekeeping
-			hctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-			n, err := w.repo.RequeueStaleProcessing(hctx, w.cfg.LockTTL)
+			if w.housekeepingLeader(ctx) {
+				w.requeueStale(ctx)
+			}
+		}
 
-			cancel()
+	}
+}
 
-			if err != nil {
-				log.Printf("worker.requeue_stale error=%v", err)
-				continue
-			}
-			if n > 0 {
-				log.Printf("worker.requeue_stale count=%d", n)
-			}
+// requeueStale takes back jobs whose lock expired. Each counts as an attempt,
+// so a job that keeps killing its worker ends up dead-lettered. Many at once
+// usually means workers are dying mid-job, so that raises an alert.
+func (w *Worker) requeueStale(ctx context.Context) {
+	// short timeout for housekeeping
+	hctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	res, err := w.repo.RequeueStaleProcessing(hctx, w.cfg.LockTTL)
+
+	cancel()
+
+	if err != nil {
+		log.Printf("worker.requeue_stale error=%v", err)
+		return
+	}
+	n := res.Total()
+	if n > 0 {
+		log.Printf("worker.requeue_stale count=%d requeued=%d dead_lettered=%d cancelled=%d",
+			n, res.Requeued, res.DeadLettered, res.Cancelled)
+		if w.metrics != nil {
+			w.metrics.AddStaleRequeues(res.Requeued, res.DeadLettered, res.Cancelled)
 		}
+	}
 
+	if t := w.cfg.StaleRequeueAlertThreshold; t > 0 && n >= int64(t) {
+		w.alert(ctx, alerting.SeverityWarning, "Stale jobs requeued",
+			fmt.Sprintf("%d processing jobs lost their lock in one pass (threshold %d): %d requeued, %d dead-lettered, %d cancelled.",
+				n, t, res.Requeued, res.DeadLettered, res.Cancelled),
+			map[string]string{"worker_id": w.cfg.WorkerID},
+		)
 	}
 }
 
+// Run claims and executes jobs until ctx is cancelled. Every background loop
+// runs in one errgroup and Run returns only after all of them have exited; a
+// health server failure cancels the rest and is returned.
 func (w *Worker) Run(ctx context.Context) error {
-	// health server
-	srv := &http.Server{
-		Addr:              w.cfg.HealthAddr,
-		Handler:           w.HealthHandler(w.PromRegistry),
-		ReadHeaderTimeout: 5 * time.Second,
+	g, gctx := errgroup.WithContext(ctx)
+
+	// an empty HealthAddr means the caller mounts the health endpoints itself
+	// (see MountHealth), so there is no listener of our own to manage.
+	if w.cfg.HealthAddr != "" {
+		ln, err := net.Listen("tcp", w.cfg.HealthAddr)
+		if err != nil {
+			return fmt.Errorf("worker health server: %w", err)
+		}
+
+		log.Printf("worker health server starting on %s", ln.Addr())
+		log.Printf("worker boot pid=%d worker_id=%s health_addr=%s", os.Getpid(), w.cfg.WorkerID, ln.Addr())
+
+		g.Go(func() error {
+			return w.serveHealth(gctx, ln)
+		})
 	}
 
-	healthDone := make(chan struct{})
+	// before the first claim, so nothing new is mistaken for an orphan
+	w.releaseOrphans(ctx)
 
-	go func() {
-		log.Printf("worker health server starting on %s", w.cfg.HealthAddr)
-		log.Printf("worker boot pid=%d worker_id=%s health_addr=%s", os.Getpid(), w.cfg.WorkerID, w.cfg.HealthAddr)
+	if w.counters != nil && w.enqueuer != nil {
+		w.scheduleVerifyCounters(ctx, time.Now().UTC())
+	}
+	if w.exportCleanup != nil && w.enqueuer != nil {
+		w.scheduleExportsCleanup(ctx, time.Now().UTC())
+	}
 
-		err := srv.ListenAndServe()
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Printf("worker health server error: %v", err)
+	if w.PromRegistry != nil {
+		if err := w.metrics.Register(w.PromRegistry); err != nil {
+			log.Printf("worker.metrics register failed err=%v", err)
 		}
-		close(healthDone)
-	}()
+	}
+	if w.dailyStats != nil {
+		w.dailyStats.begin(w.cfg.WorkerID, w.metrics.Counts())
+		defer observability.LiveJobCounts.Register(w.dailyStats.runID, w.liveDailyStats)()
 
-	// On shutdown: flip readiness -> keep alive briefly -> then shutdown server
-	go func() {
-		<-ctx.Done()
+		g.Go(func() error {
+			w.dailyStatsLoop(gctx)
+			return nil
+		})
+	}
 
-		w.readyMu.Lock()
-		w.ready = false
-		w.readyMu.Unlock()
+	if w.attempts != nil {
+		g.Go(func() error {
+			w.attemptLogLoop(gctx)
+			return nil
+		})
+	}
+
+	if w.scheduler != nil {
+		g.Go(func() error {
+			w.schedulerLoop(gctx)
+			return nil
+		})
+	}
 
-		time.Sleep(5 * time.Second) // 503 observation window
+	g.Go(func() error {
+		w.logMetricsLoop(gctx, w.cfg.MetricsLogInterval)
+		return nil
+	})
+	g.Go(func() error {
+		w.requeueLoop(gctx)
+		return nil
+	})
+	g.Go(func() error {
+		w.processLoop(gctx)
+		return nil
+	})
+	if w.wakeupListen != nil {
+		g.Go(func() error {
+			w.wakeupLoop(gctx)
+			return nil
+		})
+	}
 
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		_ = srv.Shutdown(shutdownCtx)
+	err := g.Wait()
+	w.resignHousekeeping()
+
+	// in-flight jobs have drained by now, so this write has the day's last outcomes
+	if w.dailyStats != nil {
+		fctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+		if ferr := w.flushDailyStats(fctx); ferr != nil {
+			log.Printf("worker.daily_stats final flush failed err=%v", ferr)
+		}
+		cancel()
+	}
+	if w.attempts != nil {
+		fctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+		if ferr := w.flushAttempts(fctx); ferr != nil {
+			log.Printf("worker.attempt_log final flush failed err=%v", ferr)
+		}
+		cancel()
+	}
+	return err
+}
+
+// serveHealth serves the health endpoints on ln. On shutdown readiness flips
+// first and the listener stays up for ReadinessWindow so load balancers observe
+// the 503 before the port goes away.
+func (w *Worker) serveHealth(ctx context.Context, ln net.Listener) error {
+	srv := newHealthServer(w.HealthHandler(w.PromRegistry))
+	ln = netutil.LimitListener(ln, w.cfg.HealthMaxConns)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
 	}()
 
-	// Worker loops
-	jobsCh := make(chan job.Job)
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("worker health server: %w", err)
+	case <-ctx.Done():
+	}
+
+	w.setReady(false)
+
+	select {
+	case <-w.clock.After(w.cfg.ReadinessWindow): // 503 observation window
+	case err := <-serveErr:
+		return fmt.Errorf("worker health server: %w", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), w.cfg.HealthShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("worker health server: %w", err)
+	}
+	return nil
+}
 
-	go w.logMetricsLoop(ctx, 30*time.Second)
-	go w.requeueLoop(ctx)
+// processLoop feeds claimed jobs to Concurrency executors until ctx is
+// cancelled, then waits up to ShutdownGrace for in-flight jobs.
+func (w *Worker) processLoop(ctx context.Context) {
+	jobsCh := make(chan job.Job)
 
 	var wg sync.WaitGroup
 	for i := 0; i < w.cfg.Concurrency; i++ {
@@ -255,6 +614,40 @@ func (w *Worker) Run(ctx context.Context) error {
 	ticker := time.NewTicker(w.cfg.PollInterval)
 	defer ticker.Stop()
 
+	// claimBatch claims one job per idle executor and hands them over; false
+	// means ctx ended while waiting for a free one.
+	claimBatch := func() bool {
+		idle := w.cfg.Concurrency - int(w.busy.Load())
+		if idle <= 0 {
+			return true
+		}
+		// paused: claim nothing, but keep running what was already claimed
+		if w.queuePaused(ctx) {
+			return true
+		}
+
+		batch, err := w.claimUpTo(ctx, idle)
+		if err != nil {
+			log.Printf("worker: claim error: %v", err)
+		}
+
+		for i, j := range batch {
+			select {
+			case jobsCh <- j:
+				if w.metrics != nil {
+					w.metrics.IncClaimed()
+				}
+			case <-ctx.Done():
+				log.Printf("worker: %d claimed jobs left undelivered at shutdown; the stale requeue returns them", len(batch)-i)
+				return false
+			}
+		}
+		return true
+	}
+
+	// nil unless WithWakeups was used; a nil channel never fires
+	var wake <-chan struct{} = w.wake
+
 producerLoop:
 	for {
 		select {
@@ -263,31 +656,19 @@ producerLoop:
 			break producerLoop
 
 		case <-ticker.C:
-			for i := 0; i < w.cfg.Concurrency; i++ {
-				claimCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
-				j, err := w.repo.ClaimNext(claimCtx, w.cfg.WorkerID)
-				cancel()
-
-				if err != nil {
-					if errors.Is(err, job.ErrJobNotFound) {
-						break
-					}
-					log.Printf("worker: claim error: %v", err)
-					break
-				}
+			if !claimBatch() {
+				break producerLoop
+			}
 
-				select {
-				case jobsCh <- j:
-					if w.metrics != nil {
-						w.metrics.IncClaimed()
-					}
-				case <-ctx.Done():
-					break producerLoop
-				}
+		case <-wake:
+			if !claimBatch() {
+				break producerLoop
 			}
 		}
 	}
 
+	// readiness also flips here for embedded workers without their own health server
+	w.setReady(false)
 	close(jobsCh)
 
 	done := make(chan struct{})
@@ -299,23 +680,27 @@ producerLoop:
 	select {
 	case <-done:
 		log.Println("worker: all in-flight jobs completed")
-	case <-time.After(w.cfg.ShutdownGrace):
+	case <-w.clock.After(w.cfg.ShutdownGrace):
 		log.Printf("worker: shutdown grace (%s) exceeded; exiting", w.cfg.ShutdownGrace)
 	}
-
-	// IMPORTANT: keep process alive until health server finishes
-	select {
-	case <-healthDone:
-	case <-time.After(7 * time.Second): // 5s window + 2s shutdown buffer
-	}
-
-	return nil
 }
 
 func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan job.Job) {
 
 	for j := range jobsChan {
+		if !w.slots.acquire(j.Type) {
+			if w.releaseOverLimit(ctx, j) {
+				continue
+			}
+			w.slots.add(j.Type)
+		}
+		w.busy.Add(1)
+		w.cfg.Prom.AddJobsInFlight(1)
 		start := time.Now()
+		wait := queueWait(j, w.now())
+		if w.metrics != nil {
+			w.metrics.ObserveQueueWait(j.Type, wait)
+		}
 		carrier, _ := traceCarrierFromPayload(j.Payload)
 
 		// Build execCtx (actor context etc.)
@@ -341,6 +726,7 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 			attribute.String("job.type", j.Type),
 			attribute.Int("job.attempts", j.Attempts),
 			attribute.Int("job.max_attempts", j.MaxAttempts),
+			attribute.Int64("job.queue_wait_ms", wait.Milliseconds()),
 			attribute.String("worker.id", w.cfg.WorkerID),
 			attribute.Int("worker.num", workerNum),
 		}
@@ -361,10 +747,19 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 			spanAttrs = append(spanAttrs, attribute.String("registration.id", carrier.RegistrationID))
 		}
 
+		// a job enqueued inside a trace runs as part of it
+		if j.TraceParent != nil {
+			execCtx = observability.ContextWithTraceParent(execCtx, *j.TraceParent, optional(j.TraceState))
+		}
+
+		// retries link back to the attempts before them, which ran in
+		// traces of their own
+		spanAttrs = append(spanAttrs, attribute.Int("job.attempt", j.Attempts+1))
 		execCtx, span := tracer.Start(execCtx, "job.run",
 			trace.WithAttributes(
 				spanAttrs...,
 			),
+			trace.WithLinks(w.previousAttemptLinks(execCtx, j)...),
 		)
 
 		// Always end span
@@ -381,20 +776,75 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 				"attempts", fmt.Sprintf("%d/%d", j.Attempts, j.MaxAttempts),
 			)
 
-			// Execute
-			if err := w.execute(execCtx, j); err != nil {
+			// Execute, renewing the lock while the handler runs
+			hbCtx, stopHeartbeat := w.startHeartbeat(execCtx, j.ID)
+			err := w.execute(hbCtx, j)
+			hbErr := stopHeartbeat()
+			if errors.Is(hbErr, job.ErrLockLost) {
+				// requeued or claimed elsewhere: whoever holds it now records the outcome
+				span.SetAttributes(attribute.String("job.result", "lock_lost"))
+				if w.metrics != nil {
+					w.metrics.ObserveDuration(time.Since(start))
+				}
+				w.recordAttempt(execCtx, j, start, time.Since(start), job.AttemptLockLost, err)
+				slog.Default().WarnContext(execCtx, "job.lock_lost",
+					"worker_num", workerNum,
+					"worker_id", w.cfg.WorkerID,
+					"job_id", j.ID,
+					"job_type", j.Type,
+					"request_id", reqID,
+					"err", err,
+				)
+				return
+			}
+			if err != nil && errors.Is(hbErr, job.ErrCancelRequested) {
+				// the handler stopped because we asked it to; don't retry
+				err = job.ErrCancelRequested
+			}
+			if err != nil {
+				// handle retry/dead-letter
+				outcome := w.handleFailure(execCtx, j, err)
+
+				d := time.Since(start)
+				if outcome == job.AttemptInterrupted {
+					if w.metrics != nil {
+						w.metrics.ObserveDuration(d)
+						w.metrics.IncInterrupted()
+					}
+					w.cfg.Prom.ObserveJobResult(j.Type, promJobResult(outcome), d)
+					span.SetAttributes(
+						attribute.Int64("job.duration_ms", d.Milliseconds()),
+						attribute.String("job.result", "interrupted"),
+					)
+					slog.Default().InfoContext(execCtx, "job.interrupted",
+						"worker_num", workerNum,
+						"worker_id", w.cfg.WorkerID,
+						"job_id", j.ID,
+						"job_type", j.Type,
+						"request_id", reqID,
+						"duration_ms", d.Milliseconds(),
+						"err", err,
+					)
+					return
+				}
+
 				// span bookkeeping
 				span.RecordError(err)
 				span.SetStatus(codes.Error, err.Error())
+				if errors.Is(err, ErrJobTimeout) {
+					span.SetAttributes(attribute.Bool("job.timeout", true))
+					if w.metrics != nil {
+						w.metrics.IncTimedOut()
+					}
+				}
 
-				// handle retry/dead-letter
-				w.handleFailure(execCtx, j, err)
-
-				d := time.Since(start)
+				w.recordTiming(execCtx, j, wait, d)
+				w.recordAttempt(execCtx, j, start, d, outcome, err)
 				if w.metrics != nil {
 					w.metrics.ObserveDuration(d)
 					w.metrics.IncFailed()
 				}
+				w.cfg.Prom.ObserveJobResult(j.Type, promJobResult(outcome), d)
 
 				span.SetAttributes(
 					attribute.Int64("job.duration_ms", d.Milliseconds()),
@@ -408,6 +858,7 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 					"job_id", j.ID,
 					"job_type", j.Type,
 					"request_id", reqID,
+					"queue_wait_ms", wait.Milliseconds(),
 					"duration_ms", d.Milliseconds(),
 					"err", err,
 				)
@@ -415,7 +866,9 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 			}
 
 			// Mark done
-			if err := w.repo.MarkDone(execCtx, j.ID); err != nil {
+			doneCtx, cancelDone := bookkeepingContext(execCtx)
+			defer cancelDone()
+			if err := w.repo.MarkDone(doneCtx, j.ID); err != nil {
 				span.RecordError(err)
 				span.SetStatus(codes.Error, "mark_done_failed")
 
@@ -440,16 +893,24 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 					"err", err,
 				)
 
-				_ = w.repo.MarkFailed(execCtx, j.ID, "mark_done_failed: "+err.Error())
+				outcome := job.AttemptDeadLettered
+				if ferr := w.repo.MarkFailed(doneCtx, j.ID, "mark_done_failed: "+err.Error()); ferr != nil {
+					outcome = job.AttemptError
+				}
+				w.cfg.Prom.ObserveJobResult(j.Type, promJobResult(outcome), d)
+				w.recordAttempt(execCtx, j, start, d, outcome, err)
 				return
 			}
 
 			// Success
 			d := time.Since(start)
+			w.recordTiming(execCtx, j, wait, d)
+			w.recordAttempt(execCtx, j, start, d, job.AttemptDone, nil)
 			if w.metrics != nil {
 				w.metrics.ObserveDuration(d)
 				w.metrics.IncDone()
 			}
+			w.cfg.Prom.ObserveJobResult(j.Type, promJobResult(job.AttemptDone), d)
 
 			span.SetStatus(codes.Ok, "done")
 			span.SetAttributes(
@@ -464,224 +925,239 @@ func (w *Worker) runWorker(ctx context.Context, workerNum int, jobsChan <-chan j
 				"job_type", j.Type,
 				"request_id", reqID,
 				"user_id", optional(j.UserID),
+				"queue_wait_ms", wait.Milliseconds(),
 				"duration_ms", d.Milliseconds(),
 			)
 		}()
+		w.busy.Add(-1)
+		w.cfg.Prom.AddJobsInFlight(-1)
+		w.slots.release(j.Type)
 	}
 }
 
 func (w *Worker) execute(ctx context.Context, j job.Job) error {
-	// simple implementation, the real behavior would be done in subsequent days.
-
-	switch j.Type {
-	case "event.publish":
-		var p publishPayload
-		if err := json.Unmarshal(j.Payload, &p); err != nil {
-			return fmt.Errorf("invalid payload: %w", err)
-		}
-
-		changed, err := w.events.MarkPublished(ctx, p.EventID)
-		if err != nil {
-			return err
-		}
-		if !changed {
-			// already published => idempotent no-op
-			return nil
+	fn, ok := w.handlers.Lookup(j.Type)
+	if !ok {
+		if w.metrics != nil {
+			w.metrics.IncUnknownType()
 		}
+		return jobs.NonRetryable(fmt.Errorf("%w: %s", ErrUnknownJobType, j.Type))
+	}
+	ctx = withProgressReporter(ctx, w.progressReporter(ctx, j))
+	return runWithTimeout(ctx, w.jobTimeout(j.Type), fn, j)
+}
 
-		// future: side effects like notifications/webhooks
-		return nil
+// HandleEventPublish runs event.publish jobs.
+func (w *Worker) HandleEventPublish(ctx context.Context, j job.Job) error {
+	var p publishPayload
+	if err := json.Unmarshal(j.Payload, &p); err != nil {
+		return invalidPayload(err)
+	}
 
-	case jobs.TypeRegistrationConfirmation:
-		var p jobs.RegistrationConfirmationPayload
-		if err := json.Unmarshal(j.Payload, &p); err != nil {
-			return fmt.Errorf("invalid payload: %w", err)
-		}
+	// already published => MarkPublished is a no-op, but reminders and
+	// attendance finalization are still scheduled so a retry catches up on
+	// a failed first attempt
+	changed, err := w.events.MarkPublished(ctx, p.EventID)
+	if err != nil {
+		return err
+	}
 
-		if w.notifier == nil {
-			return fmt.Errorf("notifier not configured")
-		}
+	if err := w.announceOnce(ctx, p.EventID, changed); err != nil {
+		return err
+	}
 
-		if w.deliveries == nil {
-			return fmt.Errorf("deliveries repo not configured")
-		}
+	if err := w.scheduleReminders(ctx, p.EventID); err != nil {
+		return err
+	}
+	return w.scheduleAttendanceFinalization(ctx, p.EventID)
+}
 
-		// Send-once gate
+// HandleRegistrationConfirmation runs registration.confirmation jobs: the
+// confirmation email is sent at most once per registration.
+func (w *Worker) HandleRegistrationConfirmation(ctx context.Context, j job.Job) error {
+	var p jobs.RegistrationConfirmationPayload
+	if err := json.Unmarshal(j.Payload, &p); err != nil {
+		return invalidPayload(err)
+	}
 
-		err := w.deliveries.TryStartRegistration(ctx, j.ID, p.RegistrationID, p.Email)
+	if w.notifier == nil {
+		return fmt.Errorf("notifier not configured")
+	}
 
-		if err != nil {
-			// Already sent == success (idempotent no-op)
+	if w.deliveries == nil {
+		return fmt.Errorf("deliveries repo not configured")
+	}
 
-			if errors.Is(err, notificationsdelivery.ErrAlreadySent) {
-				return nil
-			}
+	// Send-once gate
 
-			// Another attempt is sending == retry later
+	err := w.deliveries.TryStartRegistration(ctx, j.ID, p.RegistrationID, p.Email)
 
-			if errors.Is(err, notificationsdelivery.ErrInProgress) {
-				return fmt.Errorf("confirmation send in progress")
-			}
+	if err != nil {
+		// Already sent == success (idempotent no-op)
 
-			return err
+		if errors.Is(err, notificationsdelivery.ErrAlreadySent) {
+			return nil
 		}
 
-		// Day 45: replaced initial log from day 43 with a notifier/email provider.
-		err = w.notifier.SendRegistrationConfirmation(ctx, notifications.SendRegistrationConfirmationInput{
-			Email:          p.Email,
-			Name:           p.Name,
-			EventID:        p.EventID,
-			RegistrationID: p.RegistrationID,
-		})
+		// Another attempt is sending == retry later
 
-		if err != nil {
-			// ALWAYS mark failed on any send error
-			_ = w.deliveries.MarkRegistrationConfirmationFailed(
-				ctx,
-				p.RegistrationID,
-				err.Error(),
-			)
-
-			if errors.Is(err, notifications.ErrCircuitOpen) {
-				return fmt.Errorf("notifier fail-fast: %w", err)
-			}
-
-			return err
-		}
-		// 3) Mark sent
-		if err := w.deliveries.MarkRegistrationConfirmationSent(ctx, p.RegistrationID, nil); err != nil {
-			log.Printf("deliveries: mark sent failed reg=%s job=%s err=%v", p.RegistrationID, j.ID, err)
+		if errors.Is(err, notificationsdelivery.ErrInProgress) {
+			return fmt.Errorf("confirmation send in progress")
 		}
-		return nil
 
-	case jobs.TypeRegistrationsExportCSV:
-		var p jobs.RegistrationsExportCSVPayload
-		if err := json.Unmarshal(j.Payload, &p); err != nil {
-			return fmt.Errorf("invalid payload: %w", err)
-		}
+		return err
+	}
 
-		if w.regsExport == nil || w.csvExports == nil {
-			return fmt.Errorf("registration csv export dependencies not configured")
-		}
+	if w.recipientQuarantined(ctx, p.Email) {
+		return w.deliveries.MarkRegistrationConfirmationSkipped(ctx, p.RegistrationID, "recipient quarantined")
+	}
 
-		regs, err := w.regsExport.ListForEventExport(ctx, p.EventID)
-		if err != nil {
-			return err
-		}
+	input := notifications.SendRegistrationConfirmationInput{
+		Email:          p.Email,
+		Name:           p.Name,
+		EventID:        p.EventID,
+		RegistrationID: p.RegistrationID,
+		Branding: notifications.Branding{
+			ReplyTo:     p.ReplyTo,
+			DisplayName: p.OrganizerName,
+			LogoURL:     p.LogoURL,
+		},
+	}
+	if w.cancelTokens != nil {
+		input.CancelToken = w.cancelTokens.Sign(p.RegistrationID, p.EventID)
+	}
+	input.EventTitle = w.confirmationEventTitle(ctx, p.EventID)
+
+	// Day 45: replaced initial log from day 43 with a notifier/email provider.
+	var messageID string
+	if n, ok := w.notifier.(notifications.MessageIDNotifier); ok {
+		messageID, err = n.SendRegistrationConfirmationWithID(ctx, input)
+	} else {
+		err = w.notifier.SendRegistrationConfirmation(ctx, input)
+	}
 
-		csvData, err := buildRegistrationsCSV(regs)
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		// ALWAYS mark failed on any send error
+		_ = w.deliveries.MarkRegistrationConfirmationFailed(
+			ctx,
+			p.RegistrationID,
+			err.Error(),
+		)
+		w.recordDeliveryFailure(ctx, p.Email, err)
 
-		var requestedBy *string
-		if p.RequestedBy != "" {
-			requestedBy = &p.RequestedBy
+		if errors.Is(err, notifications.ErrCircuitOpen) {
+			return fmt.Errorf("notifier fail-fast: %w", err)
 		}
-
-		fileName := fmt.Sprintf("event_%s_registrations_%s.csv", p.EventID, time.Now().UTC().Format("20060102_150405"))
-		return w.csvExports.Save(ctx, registrationexport.CSVExport{
-			JobID:       j.ID,
-			EventID:     p.EventID,
-			RequestedBy: requestedBy,
-			FileName:    fileName,
-			ContentType: "text/csv",
-			RowCount:    len(regs),
-			Data:        csvData,
-			CreatedAt:   time.Now().UTC(),
-		})
-
-	case "test.crash":
-		time.Sleep(60 * time.Second)
-
-		return fmt.Errorf("unknown job type: %s", j.Type)
-
-	case "test.slow":
-		log.Printf("test.slow begin pid=%d job=%s", os.Getpid(), j.ID)
-
-		d := 120 * time.Second
-		if v := os.Getenv("TEST_SLOW_SLEEP"); v != "" {
-			if parsed, err := time.ParseDuration(v); err == nil {
-				d = parsed
-			}
+		// resending the same email to the same address cannot succeed
+		if errors.Is(err, notifications.ErrPermanent) {
+			return jobs.NonRetryable(err)
 		}
 
-		time.Sleep(d)
-		log.Printf("test.slow end pid=%d job=%s", os.Getpid(), j.ID)
-		return nil
-
-	default:
-		time.Sleep(750 * time.Millisecond)
-		return fmt.Errorf("unknown job type: %s", j.Type)
+		return err
 	}
+	// 3) Mark sent
+	var providerMessageID *string
+	if messageID != "" {
+		providerMessageID = &messageID
+	}
+	if err := w.deliveries.MarkRegistrationConfirmationSent(ctx, p.RegistrationID, providerMessageID); err != nil {
+		log.Printf("deliveries: mark sent failed reg=%s job=%s err=%v", p.RegistrationID, j.ID, err)
+	}
+	return nil
 }
 
-func buildRegistrationsCSV(regs []registration.Registration) ([]byte, error) {
-	buf := &bytes.Buffer{}
-	w := csv.NewWriter(buf)
-
-	if err := w.Write([]string{
-		"registration_id",
-		"event_id",
-		"user_id",
-		"name",
-		"email",
-		"check_in_token",
-		"checked_in_at",
-		"created_at",
-	}); err != nil {
-		return nil, err
+// confirmationEventTitle looks up the event's title for the confirmation when
+// the events repository can read events. A failed lookup only costs the
+// title; the email still goes out.
+func (w *Worker) confirmationEventTitle(ctx context.Context, eventID string) string {
+	events, ok := w.events.(EventReader)
+	if !ok {
+		return ""
 	}
 
-	for _, r := range regs {
-		checkedInAt := ""
-		if r.CheckedInAt != nil {
-			checkedInAt = r.CheckedInAt.UTC().Format(time.RFC3339)
-		}
+	e, err := events.GetByID(ctx, eventID)
+	if err != nil {
+		slog.Default().WarnContext(ctx, "registration.confirmation_event_lookup_failed", "event_id", eventID, "err", err)
+		return ""
+	}
+	return e.Title
+}
 
-		if err := w.Write([]string{
-			r.ID,
-			r.EventID,
-			r.UserID,
-			r.Name,
-			r.Email,
-			r.CheckInToken,
-			checkedInAt,
-			r.CreatedAt.UTC().Format(time.RFC3339),
-		}); err != nil {
-			return nil, err
-		}
+// test.* jobs exercise crash recovery and graceful shutdown in the e2e scripts.
+func testCrashJob(ctx context.Context, j job.Job) error {
+	// the script kills the worker inside this window; a shutdown or timeout
+	// ends it early instead of holding the worker for the full minute
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(60 * time.Second):
 	}
 
-	w.Flush()
-	if err := w.Error(); err != nil {
-		return nil, err
+	return fmt.Errorf("unknown job type: %s", j.Type)
+}
+
+func testSlowJob(ctx context.Context, j job.Job) error {
+	log.Printf("test.slow begin pid=%d job=%s", os.Getpid(), j.ID)
+
+	d := 120 * time.Second
+	if v := os.Getenv("TEST_SLOW_SLEEP"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			d = parsed
+		}
 	}
 
-	return buf.Bytes(), nil
+	time.Sleep(d)
+	log.Printf("test.slow end pid=%d job=%s", os.Getpid(), j.ID)
+	return nil
 }
 
-func (w *Worker) handleFailure(ctx context.Context, j job.Job, execError error) {
+// handleFailure records a failed execution as a retry, a dead-letter or a
+// cancellation and reports which one landed.
+func (w *Worker) handleFailure(ctx context.Context, j job.Job, execError error) job.AttemptOutcome {
 	errMsg := execError.Error()
 	reqID := requestIDFromContext(ctx)
+	interrupted := observability.ClassifyErr(ctx, execError) == observability.ErrClassCancelled
+
+	// the job's own context may be dead (timeout, shutdown); record the outcome anyway
+	ctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+
+	// stopped by shutdown rather than failing: run it again elsewhere
+	// without counting this as an attempt
+	if interrupted && w.releaseInterrupted(ctx, j) {
+		return job.AttemptInterrupted
+	}
+
+	observability.RecentJobErrors.Add(observability.ErrorEvent{
+		JobType: j.Type,
+		JobID:   j.ID,
+		Class:   jobErrorClass(execError),
+		Error:   errMsg,
+	})
+
+	if errors.Is(execError, job.ErrCancelRequested) {
+		return w.recordCancelled(ctx, j, errMsg)
+	}
 
 	// How many attempts will this failure represent?
 	nextAttempt := j.Attempts + 1
+	decision := w.decideRetry(j, execError)
 
 	// if we have retries left, let us reschedule with exponential backoff
 
-	if nextAttempt < j.MaxAttempts {
-		delay := ExponentialBackoff(j.Attempts)
-		runAt := time.Now().UTC().Add(delay)
+	if decision.retry {
+		runAt := time.Now().UTC().Add(decision.delay)
 
-		if err := w.repo.Reschedule(ctx, j.ID, runAt, errMsg); err != nil {
+		if err := w.reschedule(ctx, j, runAt, errMsg, decision.maxAttempts); err != nil {
 			slog.Default().ErrorContext(ctx, "job.reschedule_failed",
 				"job_id", j.ID,
 				"request_id", reqID,
 				"err", err,
 			)
-			_ = w.repo.MarkFailed(ctx, j.ID, "reschedule_failed: "+errMsg)
-			return
+			if err := w.repo.MarkFailed(ctx, j.ID, "reschedule_failed: "+errMsg); err != nil {
+				return job.AttemptError
+			}
+			return job.AttemptDeadLettered
 		}
 
 		if w.metrics != nil {
@@ -692,21 +1168,37 @@ func (w *Worker) handleFailure(ctx context.Context, j job.Job, execError error)
 			"job_id", j.ID,
 			"request_id", reqID,
 			"attempt", nextAttempt,
-			"max_attempts", j.MaxAttempts,
+			"max_attempts", decision.maxAttempts,
 			"next_run", runAt.Format(time.RFC3339),
 			"err", errMsg,
 		)
-		return
+		return job.AttemptRetried
 	}
 
-	// Otherwise dead-letter it (status=failed + last_error)``
-	if err := w.repo.MarkFailed(ctx, j.ID, errMsg); err != nil {
+	// Otherwise dead-letter it (status=failed + a dead_letters copy). The
+	// dead-letter gets a trace of its own linked to the final attempt, so a
+	// replay much later does not extend that attempt's trace.
+	dlOpts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			attribute.String("job.id", j.ID),
+			attribute.String("job.type", j.Type),
+			attribute.String("job.error_class", jobErrorClass(execError)),
+		),
+	}
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		dlOpts = append(dlOpts, trace.WithLinks(trace.LinkFromContext(ctx, attribute.Int("job.attempt", nextAttempt))))
+	}
+	dlCtx, dlSpan := tracer.Start(ctx, "job.dead_letter", dlOpts...)
+	defer dlSpan.End()
+	if err := w.repo.MarkFailed(dlCtx, j.ID, errMsg); err != nil {
+		dlSpan.RecordError(err)
 		slog.Default().ErrorContext(ctx, "job.mark_failed_write_failed",
 			"job_id", j.ID,
 			"request_id", reqID,
 			"err", err,
 		)
-		return
+		return job.AttemptError
 	}
 
 	if w.metrics != nil {
@@ -717,8 +1209,166 @@ func (w *Worker) handleFailure(ctx context.Context, j job.Job, execError error)
 		"job_id", j.ID,
 		"request_id", reqID,
 		"attempt", nextAttempt,
-		"max_attempts", j.MaxAttempts,
+		"max_attempts", decision.maxAttempts,
 		"err", errMsg,
 	)
 
+	w.alert(ctx, alerting.SeverityCritical, "Job dead-lettered",
+		fmt.Sprintf("Job %s failed after %d attempts: %s", j.ID, nextAttempt, observability.RedactEmails(errMsg)),
+		map[string]string{"job_type": j.Type, "error_class": jobErrorClass(execError)},
+	)
+	return job.AttemptDeadLettered
+}
+
+// recordCancelled stores a job aborted on an admin's request. Repos that
+// cannot record cancellation dead-letter it, which at least stops retries.
+func (w *Worker) recordCancelled(ctx context.Context, j job.Job, errMsg string) job.AttemptOutcome {
+	reqID := requestIDFromContext(ctx)
+
+	var err error
+	if c, ok := w.repo.(JobCanceller); ok {
+		err = c.MarkCancelled(ctx, j.ID, w.cfg.WorkerID, errMsg)
+	} else {
+		err = w.repo.MarkFailed(ctx, j.ID, errMsg)
+	}
+	if errors.Is(err, job.ErrLockLost) {
+		// requeued as stale meanwhile; its outcome is the new holder's
+		slog.Default().WarnContext(ctx, "job.mark_cancelled_lock_lost",
+			"job_id", j.ID,
+			"request_id", reqID,
+			"worker_id", w.cfg.WorkerID,
+		)
+		return job.AttemptLockLost
+	}
+	if err != nil {
+		slog.Default().ErrorContext(ctx, "job.mark_cancelled_failed",
+			"job_id", j.ID,
+			"request_id", reqID,
+			"err", err,
+		)
+		return job.AttemptError
+	}
+
+	slog.Default().WarnContext(ctx, "job.cancelled",
+		"job_id", j.ID,
+		"request_id", reqID,
+		"attempt", j.Attempts+1,
+	)
+	return job.AttemptCancelled
+}
+
+// invalidPayload wraps a payload decode error. The payload is stored with the
+// job, so no later attempt decodes it any better.
+func invalidPayload(err error) error {
+	return jobs.NonRetryable(fmt.Errorf("invalid payload: %w", err))
+}
+
+// releaseInterrupted hands a job the worker's shutdown cut short back to the
+// queue, due now with its attempts untouched. False when the repo cannot
+// release or the release failed; the caller then handles it as a failure.
+func (w *Worker) releaseInterrupted(ctx context.Context, j job.Job) bool {
+	r, ok := w.repo.(JobReleaser)
+	if !ok {
+		return false
+	}
+	if err := r.Release(ctx, j.ID, w.cfg.WorkerID); err != nil {
+		slog.Default().WarnContext(ctx, "job.release_failed",
+			"worker_id", w.cfg.WorkerID,
+			"job_id", j.ID,
+			"job_type", j.Type,
+			"err", err,
+		)
+		return false
+	}
+	return true
+}
+
+// jobErrorClass buckets a failure so the recent-errors view shows at a glance
+// whether jobs are timing out, misconfigured or hitting a down dependency.
+func jobErrorClass(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.Is(err, ErrUnknownJobType):
+		return "unknown_job_type"
+	case errors.Is(err, jobs.ErrNonRetryable):
+		return "non_retryable"
+	case errors.Is(err, job.ErrCancelRequested):
+		return "cancelled"
+	case errors.Is(err, ErrJobTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, notifications.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "invalid_payload"
+	case errors.As(err, &pgErr):
+		return "database"
+	default:
+		return "error"
+	}
+}
+
+func (w *Worker) verifyCounters(ctx context.Context, j job.Job) error {
+	if w.counters == nil {
+		return fmt.Errorf("counter verifier not configured")
+	}
+
+	var p jobs.EventsVerifyCountersPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &p); err != nil {
+			return invalidPayload(err)
+		}
+	}
+	if p.LookbackDays <= 0 {
+		p.LookbackDays = 90
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -p.LookbackDays)
+	stats, err := w.counters.VerifyActive(ctx, since, p.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("events.verify_counters checked=%d drifted=%d max_delta=%d job=%s", stats.Checked, stats.Drifted, stats.MaxDelta, j.ID)
+
+	if rw, ok := w.repo.(JobResultWriter); ok {
+		raw, err := json.Marshal(stats)
+		if err == nil {
+			err = rw.SetResult(ctx, j.ID, raw)
+		}
+		if err != nil {
+			log.Printf("events.verify_counters: store result failed job=%s err=%v", j.ID, err)
+		}
+	}
+
+	if w.enqueuer != nil {
+		w.scheduleVerifyCounters(ctx, time.Now().UTC().Add(jobs.VerifyCountersEvery))
+	}
+
+	return nil
+}
+
+// scheduleVerifyCounters enqueues the verification run for runAt's week. The
+// per-week idempotency key makes repeated calls (restarts, several workers) no-ops.
+func (w *Worker) scheduleVerifyCounters(ctx context.Context, runAt time.Time) {
+	raw, err := jobs.EventsVerifyCountersPayload{}.JSON()
+	if err != nil {
+		return
+	}
+
+	key := jobs.VerifyCountersKey(runAt)
+	_, err = w.enqueuer.Create(ctx, job.CreateRequest{
+		Type:           jobs.TypeEventsVerifyCounters,
+		Payload:        raw,
+		RunAt:          runAt,
+		MaxAttempts:    3,
+		IdempotencyKey: &key,
+	})
+	if err != nil && !postgres.IsUniqueViolation(err) {
+		log.Printf("events.verify_counters: schedule failed key=%s err=%v", key, err)
+	}
 }
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/geocoder89/eventhub/internal/jobs"
+)
+
+// after returns the items strictly after the cursor, at most limit of them.
+func after[T any](items []T, key func(T) (time.Time, string), limit int, afterCreatedAt time.Time, afterID string) []T {
+	out := make([]T, 0, limit)
+	for _, it := range items {
+		at, id := key(it)
+		if at.After(afterCreatedAt) || (at.Equal(afterCreatedAt) && id > afterID) {
+			out = append(out, it)
+			if len(out) == limit {
+				break
+			}
+		}
+	}
+	return out
+}
+
+type fakeAccountSources struct {
+	user   user.User
+	events []event.Event
+	regs   []registration.Registration
+	jobs   []job.Job
+	calls  map[string]int
+}
+
+func (f *fakeAccountSources) GetByID(ctx context.Context, id string) (user.User, error) {
+	return f.user, nil
+}
+
+type fakeOrganizerEvents struct{ *fakeAccountSources }
+
+func (f fakeOrganizerEvents) ListByOrganizerCursor(ctx context.Context, organizerID string, limit int, afterCreatedAt time.Time, afterID string) ([]event.Event, error) {
+	f.calls["events"]++
+	return after(f.events, func(e event.Event) (time.Time, string) { return e.CreatedAt, e.ID }, limit, afterCreatedAt, afterID), nil
+}
+
+type fakeOrganizerRegistrations struct{ *fakeAccountSources }
+
+func (f fakeOrganizerRegistrations) ListByOrganizerCursor(ctx context.Context, organizerID string, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, error) {
+	f.calls["registrations"]++
+	return after(f.regs, func(r registration.Registration) (time.Time, string) { return r.CreatedAt, r.ID }, limit, afterCreatedAt, afterID), nil
+}
+
+func (f *fakeAccountSources) ListByUserCursor(ctx context.Context, userID string, limit int, afterCreatedAt time.Time, afterID string) ([]job.Job, error) {
+	f.calls["jobs"]++
+	return after(f.jobs, func(j job.Job) (time.Time, string) { return j.CreatedAt, j.ID }, limit, afterCreatedAt, afterID), nil
+}
+
+func TestWriteAccountExport(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	f := &fakeAccountSources{
+		user:  user.User{ID: "user-1", Email: "org@example.com", Name: "Org", PasswordHash: "secret-hash"},
+		calls: map[string]int{},
+	}
+	for i := 0; i < 3; i++ {
+		f.events = append(f.events, event.Event{ID: "event-" + string(rune('1'+i)), CreatedAt: base})
+	}
+	for i := 0; i < 4; i++ {
+		f.regs = append(f.regs, registration.Registration{ID: "reg-" + string(rune('1'+i)), CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	src := AccountExportSources{
+		Users:         f,
+		Events:        fakeOrganizerEvents{f},
+		Registrations: fakeOrganizerRegistrations{f},
+		Jobs:          f,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var stages []string
+	var last jobs.AccountExportResult
+	err := writeAccountExport(context.Background(), zw, src, f.user, 2, func(r jobs.AccountExportResult) {
+		stages = append(stages, r.Stage)
+		last = r
+	})
+	if err != nil {
+		t.Fatalf("writeAccountExport: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	if last.Events != 3 || last.Registrations != 4 || last.Jobs != 0 {
+		t.Fatalf("unexpected final counts: %+v", last)
+	}
+	if got := strings.Join(stages, ","); got != "events,registrations,jobs,jobs" {
+		t.Fatalf("unexpected progress stages %s", got)
+	}
+	// tied created_at on events still pages by id; a full last page costs one empty fetch
+	if f.calls["events"] != 2 || f.calls["registrations"] != 3 || f.calls["jobs"] != 1 {
+		t.Fatalf("unexpected page fetches %v", f.calls)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+
+	files := map[string][]byte{}
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", zf.Name, err)
+		}
+		files[zf.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var profile map[string]any
+	if err := json.Unmarshal(files["profile.json"], &profile); err != nil {
+		t.Fatalf("decode profile.json: %v", err)
+	}
+	if profile["email"] != "org@example.com" {
+		t.Fatalf("unexpected profile %v", profile)
+	}
+	if bytes.Contains(files["profile.json"], []byte("secret-hash")) {
+		t.Fatalf("profile.json must not contain the password hash")
+	}
+
+	for name, want := range map[string]int{"events.json": 3, "registrations.json": 4, "jobs.json": 0} {
+		var items []json.RawMessage
+		if err := json.Unmarshal(files[name], &items); err != nil {
+			t.Fatalf("decode %s: %v body=%s", name, err, files[name])
+		}
+		if len(items) != want {
+			t.Fatalf("%s: got %d items, want %d", name, len(items), want)
+		}
+	}
+}
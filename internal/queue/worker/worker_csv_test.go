@@ -1,6 +1,9 @@
 package worker
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/csv"
 	"strings"
 	"testing"
@@ -9,46 +12,73 @@ import (
 	"github.com/geocoder89/eventhub/internal/domain/registration"
 )
 
-func TestBuildRegistrationsCSV(t *testing.T) {
+// pagedRegistrations serves regs through the cursor API and records page sizes.
+type pagedRegistrations struct {
+	regs  []registration.Registration
+	pages []int
+}
+
+func (p *pagedRegistrations) ListByEventCursor(ctx context.Context, eventID string, filter registration.ListFilter, limit int, afterCreatedAt time.Time, afterID string) ([]registration.Registration, *string, bool, error) {
+	start := 0
+	for i, r := range p.regs {
+		if r.CreatedAt.After(afterCreatedAt) || (r.CreatedAt.Equal(afterCreatedAt) && r.ID > afterID) {
+			start = i
+			break
+		}
+		start = i + 1
+	}
+
+	end := start + limit
+	hasMore := end < len(p.regs)
+	if end > len(p.regs) {
+		end = len(p.regs)
+	}
+
+	page := p.regs[start:end]
+	p.pages = append(p.pages, len(page))
+	return page, nil, hasMore, nil
+}
+
+func TestWriteRegistrationsCSV(t *testing.T) {
 	checkedInAt := time.Date(2026, 2, 22, 10, 30, 0, 0, time.UTC)
 	createdAt1 := time.Date(2026, 2, 21, 9, 0, 0, 0, time.UTC)
-	createdAt2 := createdAt1.Add(2 * time.Hour)
 
-	regs := []registration.Registration{
-		{
-			ID:           "reg-1",
-			EventID:      "event-1",
-			UserID:       "user-1",
-			Name:         "First User",
-			Email:        "first@example.com",
-			CheckInToken: "token-1",
-			CheckedInAt:  nil,
-			CreatedAt:    createdAt1,
-		},
-		{
-			ID:           "reg-2",
+	reader := &pagedRegistrations{}
+	for i, email := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		r := registration.Registration{
+			ID:           "reg-" + string(rune('1'+i)),
 			EventID:      "event-1",
-			UserID:       "user-2",
-			Name:         "Second User",
-			Email:        "second@example.com",
-			CheckInToken: "token-2",
-			CheckedInAt:  &checkedInAt,
-			CreatedAt:    createdAt2,
-		},
+			UserID:       "user-" + string(rune('1'+i)),
+			Name:         "User",
+			Email:        email,
+			CheckInToken: "token",
+			CreatedAt:    createdAt1.Add(time.Duration(i) * time.Hour),
+		}
+		if i == 1 {
+			r.CheckedInAt = &checkedInAt
+		}
+		reader.regs = append(reader.regs, r)
 	}
 
-	out, err := buildRegistrationsCSV(regs)
+	var out bytes.Buffer
+	n, err := writeRegistrationsCSV(context.Background(), &out, reader, "event-1", 2, registration.PIIFull)
 	if err != nil {
-		t.Fatalf("buildRegistrationsCSV returned error: %v", err)
+		t.Fatalf("writeRegistrationsCSV returned error: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 rows written, got %d", n)
+	}
+	if len(reader.pages) != 2 || reader.pages[0] != 2 || reader.pages[1] != 1 {
+		t.Fatalf("expected pages of 2 then 1, got %v", reader.pages)
 	}
 
-	rows, err := csv.NewReader(strings.NewReader(string(out))).ReadAll()
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
 	if err != nil {
 		t.Fatalf("parse csv output: %v", err)
 	}
 
-	if len(rows) != 3 {
-		t.Fatalf("expected 3 rows (header + 2), got %d", len(rows))
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows (header + 3), got %d", len(rows))
 	}
 
 	wantHeader := []string{
@@ -71,10 +101,131 @@ func TestBuildRegistrationsCSV(t *testing.T) {
 	if rows[1][6] != "" {
 		t.Fatalf("expected empty checked_in_at for first row, got %q", rows[1][6])
 	}
-	if rows[2][0] != "reg-2" || rows[2][4] != "second@example.com" {
+	if rows[2][0] != "reg-2" || rows[2][6] != checkedInAt.Format(time.RFC3339) {
 		t.Fatalf("unexpected second data row: %v", rows[2])
 	}
-	if rows[2][6] != checkedInAt.Format(time.RFC3339) {
-		t.Fatalf("unexpected checked_in_at for second row: got=%q want=%q", rows[2][6], checkedInAt.Format(time.RFC3339))
+	if rows[3][0] != "reg-3" || rows[3][4] != "third@example.com" {
+		t.Fatalf("unexpected third data row: %v", rows[3])
+	}
+}
+
+// pagedActivity serves history entries through the cursor API, one page per call.
+type pagedActivity struct {
+	entries []registration.HistoryEntry
+	calls   int
+}
+
+func (p *pagedActivity) ActivityByEventCursor(ctx context.Context, eventID string, filter registration.ActivityFilter, limit int, afterAt time.Time, afterID string) ([]registration.HistoryEntry, *string, bool, error) {
+	start := p.calls * limit
+	p.calls++
+	end := min(start+limit, len(p.entries))
+	return p.entries[start:end], nil, end < len(p.entries), nil
+}
+
+func TestWriteRegistrationsZip_AddsActivitySheet(t *testing.T) {
+	at := time.Date(2026, 2, 21, 9, 0, 0, 0, time.UTC)
+	reader := &pagedRegistrations{regs: []registration.Registration{{ID: "reg-1", EventID: "event-1", Email: "first@example.com", CreatedAt: at}}}
+	activity := &pagedActivity{entries: []registration.HistoryEntry{
+		{ID: "h-1", RegistrationID: "reg-1", Kind: registration.HistoryWaitlisted, ToStatus: registration.StatusWaitlisted, OccurredAt: at},
+		{ID: "h-2", RegistrationID: "reg-1", Kind: registration.HistoryPromoted, FromStatus: registration.StatusWaitlisted, ToStatus: registration.StatusConfirmed, OccurredAt: at.Add(time.Hour)},
+		{ID: "h-3", RegistrationID: "reg-1", Kind: registration.HistoryCheckedIn, FromStatus: registration.StatusConfirmed, ToStatus: registration.StatusConfirmed, OccurredAt: at.Add(2 * time.Hour)},
+	}}
+
+	var out bytes.Buffer
+	n, err := writeRegistrationsZip(context.Background(), &out, reader, activity, "event-1", 2, registration.PIIFull)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 registration row, got %d err=%v", n, err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	sheets := map[string][][]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		rows, err := csv.NewReader(rc).ReadAll()
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("parse %s: %v", f.Name, err)
+		}
+		sheets[f.Name] = rows
+	}
+
+	if len(sheets["registrations.csv"]) != 2 {
+		t.Fatalf("expected header + 1 registration, got %v", sheets["registrations.csv"])
+	}
+	got := sheets["activity.csv"]
+	if len(got) != 4 || got[0][2] != "kind" {
+		t.Fatalf("expected header + 3 activity rows, got %v", got)
+	}
+	if got[2][2] != registration.HistoryPromoted || got[2][3] != registration.StatusWaitlisted || got[3][7] != at.Add(2*time.Hour).Format(time.RFC3339Nano) {
+		t.Fatalf("unexpected activity rows: %v", got)
+	}
+}
+
+func TestWriteRegistrationsCSV_MaskedSnapshot(t *testing.T) {
+	reader := &pagedRegistrations{regs: []registration.Registration{{
+		ID:           "reg-1",
+		EventID:      "event-1",
+		Name:         "Sam Smith",
+		Email:        "sam@example.com",
+		CheckInToken: "token",
+		CreatedAt:    time.Date(2026, 2, 21, 9, 0, 0, 0, time.UTC),
+	}}}
+
+	var out bytes.Buffer
+	if _, err := writeRegistrationsCSV(context.Background(), &out, reader, "event-1", 10, registration.PIIMasked); err != nil {
+		t.Fatalf("writeRegistrationsCSV returned error: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv output: %v", err)
+	}
+	if got := rows[1]; got[3] != "S***" || got[4] != "s***@example.com" || got[5] != "" {
+		t.Fatalf("expected name, email and token masked, got %v", got)
+	}
+}
+
+func TestWriteActivityCSV_CancellationDetails(t *testing.T) {
+	at := time.Date(2026, 2, 21, 9, 0, 0, 0, time.UTC)
+	entries := []registration.HistoryEntry{{
+		ID:                 "h-1",
+		RegistrationID:     "reg-1",
+		Kind:               registration.HistoryCancelled,
+		FromStatus:         registration.StatusConfirmed,
+		ToStatus:           registration.StatusCancelled,
+		CancelledBy:        registration.CancelledBySelf,
+		CancellationReason: "plans changed",
+		OccurredAt:         at,
+	}}
+
+	for _, tc := range []struct {
+		visibility registration.PIIVisibility
+		wantBy     string
+		wantReason string
+	}{
+		{visibility: registration.PIIFull, wantBy: registration.CancelledBySelf, wantReason: "plans changed"},
+		{visibility: registration.PIIMasked},
+	} {
+		var out bytes.Buffer
+		if err := writeActivityCSV(context.Background(), &out, &pagedActivity{entries: entries}, "event-1", 10, tc.visibility); err != nil {
+			t.Fatalf("%s: writeActivityCSV returned error: %v", tc.visibility, err)
+		}
+
+		rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: parse csv output: %v", tc.visibility, err)
+		}
+		if rows[0][5] != "cancelled_by" || rows[0][6] != "cancellation_reason" {
+			t.Fatalf("unexpected header: %v", rows[0])
+		}
+		if got := rows[1]; got[5] != tc.wantBy || got[6] != tc.wantReason {
+			t.Fatalf("%s: got cancelled_by=%q reason=%q, want %q %q", tc.visibility, got[5], got[6], tc.wantBy, tc.wantReason)
+		}
 	}
 }
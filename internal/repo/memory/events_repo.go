@@ -1,43 +1,38 @@
 package memory
 
 import (
-	"errors"
+	"context"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/domain/event"
-	"github.com/google/uuid"
+	"github.com/geocoder89/eventhub/internal/utils"
 )
 
-// errors not found
-
-var ErrNotFound = errors.New("event not found")
-
+// EventsRepo keeps events in a map, for tests and local wiring that should
+// not need Postgres. It follows the postgres repo's contract: deleted events
+// are hidden from reads and a missing event is event.ErrNotFound.
 type EventsRepo struct {
-	mu    sync.RWMutex
-	items map[string]event.Event // {"key": "value"}
-
+	mu       sync.RWMutex
+	items    map[string]event.Event // {"key": "value"}
+	deleted  map[string]bool
+	branding map[string]event.Branding
 }
 
 func NewEventsRepo() *EventsRepo {
 	return &EventsRepo{
-		items: make(map[string]event.Event),
+		items:    make(map[string]event.Event),
+		deleted:  make(map[string]bool),
+		branding: make(map[string]event.Branding),
 	}
 }
 
-func (r *EventsRepo) Create(req event.CreateEventRequest) (event.Event, error) {
-	now := time.Now()
-	e := event.Event{
-		ID:          uuid.NewString(),
-		Title:       req.Title,
-		Description: req.Description,
-		City:        req.City,
-		StartAt:     req.StartAt,
-		Capacity:    req.Capacity,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
+func (r *EventsRepo) Create(ctx context.Context, req event.CreateEventRequest) (event.Event, error) {
+	e := event.NewFromCreateRequest(req)
+	e.Category = strings.ToLower(strings.TrimSpace(req.Category))
+
 	r.mu.Lock()
 	r.items[e.ID] = e
 	r.mu.Unlock()
@@ -45,33 +40,229 @@ func (r *EventsRepo) Create(req event.CreateEventRequest) (event.Event, error) {
 	return e, nil
 }
 
-func (r *EventsRepo) GetByID(id string) (event.Event, error) {
+func (r *EventsRepo) GetByID(ctx context.Context, id string) (event.Event, error) {
 	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	e, ok := r.items[id]
-	r.mu.RUnlock()
+	if !ok || r.deleted[id] {
+		return event.Event{}, event.ErrNotFound
+	}
+	return e, nil
+}
+
+// GetByIDs returns the live events among ids; ids with no live event are
+// left out.
+func (r *EventsRepo) GetByIDs(ctx context.Context, ids []string) ([]event.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]event.Event, 0, len(ids))
+	for _, id := range ids {
+		if e, ok := r.items[id]; ok && !r.deleted[id] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *EventsRepo) List(ctx context.Context, filter event.ListEventsFilter) ([]event.Event, int, error) {
+	all := r.matching(filter)
+	total := len(all)
+
+	start := min(max(filter.Offset, 0), total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+
+	return all[start:end], total, nil
+}
+
+func (r *EventsRepo) ListCursor(ctx context.Context, filter event.ListEventsFilter, afterStartAt time.Time, afterID string) (items []event.Event, nextCursor *string, hasMore bool, err error) {
+	out := make([]event.Event, 0, filter.Limit)
+	for _, e := range r.matching(filter) {
+		// keyset condition: (start_at, id) > (afterStartAt, afterID)
+		if e.StartAt.Before(afterStartAt) || (e.StartAt.Equal(afterStartAt) && e.ID <= afterID) {
+			continue
+		}
+		if len(out) == filter.Limit {
+			hasMore = true
+			break
+		}
+		out = append(out, e)
+	}
+
+	if hasMore && len(out) > 0 {
+		last := out[len(out)-1]
+		cur, encErr := utils.EncodeEventCursor(last.StartAt, last.ID)
+		if encErr != nil {
+			return nil, nil, false, encErr
+		}
+		nextCursor = &cur
+	}
+
+	return out, nextCursor, hasMore, nil
+}
+
+func (r *EventsRepo) Count(ctx context.Context, filter event.ListEventsFilter) (int, error) {
+	return len(r.matching(filter)), nil
+}
 
+func (r *EventsRepo) Update(ctx context.Context, id string, req event.UpdateEventRequest) (event.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.items[id]
+	if !ok || r.deleted[id] {
+		return event.Event{}, event.ErrNotFound
+	}
+
+	e.Title = req.Title
+	e.Description = req.Description
+	e.City = req.City
+	e.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	e.Tags = req.Tags
+	e.StartAt = req.StartAt
+	e.Capacity = req.Capacity
+	e.RequiresAuth = req.RequiresAuth
+	e.AllowedEmailDomains = event.NormalizeEmailDomains(req.AllowedEmailDomains)
+	e.MaxQuantity = max(req.MaxQuantity, 1)
+	e.CapacityAlertThresholds = event.NormalizeCapacityAlertThresholds(req.CapacityAlertThresholds)
+	e.RegistrationOpensAt = req.RegistrationOpensAt
+	e.RegistrationClosesAt = req.RegistrationClosesAt
+	e.UpdatedAt = time.Now()
+	r.items[id] = e
+
+	return e, nil
+}
+
+func (r *EventsRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok || r.deleted[id] {
+		return event.ErrNotFound
+	}
+	r.deleted[id] = true
+	return nil
+}
+
+func (r *EventsRepo) Restore(ctx context.Context, id string) (event.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.items[id]
 	if !ok {
-		return event.Event{}, ErrNotFound
+		return event.Event{}, event.ErrNotFound
 	}
+	delete(r.deleted, id)
 	return e, nil
 }
 
-func (r *EventsRepo) List() ([]event.Event, error) {
+// Organizers maps each live event in ids to its organizer ("" when it has
+// none). Unknown and deleted events are absent from the map.
+func (r *EventsRepo) Organizers(ctx context.Context, ids []string) (map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make(map[string]string, len(ids))
+	for _, id := range ids {
+		if e, ok := r.items[id]; ok && !r.deleted[id] {
+			out[id] = e.OrganizerID
+		}
+	}
+	return out, nil
+}
+
+// GetAccess returns a live event's visibility and organizer.
+func (r *EventsRepo) GetAccess(ctx context.Context, id string) (event.Access, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.items[id]
+	if !ok || r.deleted[id] {
+		return event.Access{}, event.ErrNotFound
+	}
+	return e.Access(), nil
+}
+
+func (r *EventsRepo) GetBranding(ctx context.Context, id string) (event.Branding, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.items[id]; !ok || r.deleted[id] {
+		return event.Branding{}, event.ErrNotFound
+	}
+	return r.branding[id], nil
+}
+
+func (r *EventsRepo) UpdateBranding(ctx context.Context, id string, b event.Branding) (event.Branding, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok || r.deleted[id] {
+		return event.Branding{}, event.ErrNotFound
+	}
+	r.branding[id] = b
+	return b, nil
+}
+
+// matching returns the live events passing filter, ordered by start time
+// then id like the postgres listing. Query is a plain substring match.
+func (r *EventsRepo) matching(filter event.ListEventsFilter) []event.Event {
 	// Rlock to make safe reads,so multiple reads can happen concurrently without blocking each other.
 	r.mu.RLock()
-	// Create an out slice using the make function which is of size 0 and has a capacity of the length of items
 	out := make([]event.Event, 0, len(r.items))
-	for _, e := range r.items {
+	for id, e := range r.items {
+		if r.deleted[id] || !matches(e, filter) {
+			continue
+		}
 		out = append(out, e)
 	}
-	// a good improvement is to handle stable ordering. more or less ordering by startAt
+	r.mu.RUnlock()
 
 	sort.Slice(out, func(i, j int) bool {
+		if out[i].StartAt.Equal(out[j].StartAt) {
+			return out[i].ID < out[j].ID
+		}
 		return out[i].StartAt.Before(out[j].StartAt)
 	})
 
-	// at this stage we expect a value so we return it
-
-	return out, nil
+	return out
+}
 
+func matches(e event.Event, f event.ListEventsFilter) bool {
+	if f.City != nil && e.City != *f.City {
+		return false
+	}
+	if f.Category != nil && e.Category != strings.ToLower(strings.TrimSpace(*f.Category)) {
+		return false
+	}
+	if f.Tag != nil {
+		tag := strings.ToLower(strings.TrimSpace(*f.Tag))
+		found := false
+		for _, t := range e.Tags {
+			if strings.ToLower(t) == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.From != nil && e.StartAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && e.StartAt.After(*f.To) {
+		return false
+	}
+	if f.Query != nil {
+		q := strings.ToLower(strings.TrimSpace(*f.Query))
+		if q != "" && !strings.Contains(strings.ToLower(e.Title+" "+e.Description), q) {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geocoder89/eventhub/internal/domain/apikey"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeysRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewAPIKeysRepo(pool *pgxpool.Pool, prom *observability.Prom) *APIKeysRepo {
+	return &APIKeysRepo{pool: pool, prom: prom}
+}
+
+func (r *APIKeysRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+const apiKeyColumns = `id, owner_id::text, name, prefix, event_ids::text[], COALESCE(organizer_id::text, ''), actions, created_at, revoked_at`
+
+func scanAPIKey(row pgx.Row) (apikey.Key, error) {
+	var k apikey.Key
+	var actions []string
+
+	err := row.Scan(&k.ID, &k.OwnerID, &k.Name, &k.Prefix, &k.EventIDs, &k.OrganizerID, &actions, &k.CreatedAt, &k.RevokedAt)
+	if err != nil {
+		return apikey.Key{}, err
+	}
+
+	k.Actions = make([]apikey.Action, 0, len(actions))
+	for _, a := range actions {
+		k.Actions = append(k.Actions, apikey.Action(a))
+	}
+	return k, nil
+}
+
+// Create stores k under hash; the raw key itself is never persisted.
+func (r *APIKeysRepo) Create(ctx context.Context, k apikey.Key, hash string) (apikey.Key, error) {
+	actions := make([]string, 0, len(k.Actions))
+	for _, a := range k.Actions {
+		actions = append(actions, string(a))
+	}
+	if k.EventIDs == nil {
+		k.EventIDs = []string{}
+	}
+
+	var out apikey.Key
+	err := r.observe("api_keys.create", func() error {
+		var err error
+		out, err = scanAPIKey(r.pool.QueryRow(ctx, `
+			INSERT INTO api_keys (id, owner_id, name, prefix, key_hash, event_ids, organizer_id, actions, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6::uuid[], NULLIF($7, '')::uuid, $8, NOW())
+			RETURNING `+apiKeyColumns,
+			k.ID, k.OwnerID, k.Name, k.Prefix, hash, k.EventIDs, k.OrganizerID, actions,
+		))
+		return err
+	})
+
+	return out, err
+}
+
+// GetByHash returns the active key with this hash; revoked keys are not found.
+func (r *APIKeysRepo) GetByHash(ctx context.Context, hash string) (apikey.Key, error) {
+	var out apikey.Key
+	err := r.observe("api_keys.get_by_hash", func() error {
+		var err error
+		out, err = scanAPIKey(r.pool.QueryRow(ctx, `
+			SELECT `+apiKeyColumns+`
+			FROM api_keys
+			WHERE key_hash = $1 AND revoked_at IS NULL
+		`, hash))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apikey.Key{}, apikey.ErrNotFound
+		}
+		return apikey.Key{}, err
+	}
+
+	return out, nil
+}
+
+func (r *APIKeysRepo) ListByOwner(ctx context.Context, ownerID string) ([]apikey.Key, error) {
+	out := make([]apikey.Key, 0)
+
+	err := r.observe("api_keys.list_by_owner", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT `+apiKeyColumns+`
+			FROM api_keys
+			WHERE owner_id = $1
+			ORDER BY created_at DESC
+		`, ownerID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			k, err := scanAPIKey(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, k)
+		}
+		return rows.Err()
+	})
+
+	return out, err
+}
+
+// Revoke disables one of ownerID's keys. Revoking twice, or someone else's
+// key, is ErrNotFound.
+func (r *APIKeysRepo) Revoke(ctx context.Context, ownerID, id string) error {
+	return r.observe("api_keys.revoke", func() error {
+		tag, err := r.pool.Exec(ctx, `
+			UPDATE api_keys
+			SET revoked_at = NOW()
+			WHERE id = $1 AND owner_id = $2 AND revoked_at IS NULL
+		`, id, ownerID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return apikey.ErrNotFound
+		}
+		return nil
+	})
+}
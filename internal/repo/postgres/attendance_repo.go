@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AttendanceRepo finalizes event attendance and reads the recorded stats.
+type AttendanceRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewAttendanceRepo(pool *pgxpool.Pool, prom *observability.Prom) *AttendanceRepo {
+	return &AttendanceRepo{pool: pool, prom: prom}
+}
+
+func (r *AttendanceRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// FinalizeEvent marks the event's confirmed registrations that never checked
+// in as no_show and records the outcome. An event that is already finalized
+// returns its recorded stats unchanged; one nobody checked in to is left
+// alone with event.ErrCheckInNotUsed.
+func (r *AttendanceRepo) FinalizeEvent(ctx context.Context, eventID string) (stats event.AttendanceStats, err error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// the registration lock, so no check-in or sign-up lands half-way
+	err = r.observe("attendance.finalize.lock", func() error {
+		var id string
+		return tx.QueryRow(ctx, `SELECT id FROM events WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, eventID).Scan(&id)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = event.ErrNotFound
+		}
+		return
+	}
+
+	stats, err = r.getTx(ctx, tx, eventID)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, event.ErrAttendanceNotFinalized) {
+		return
+	}
+
+	var checkedIn int
+	err = r.observe("attendance.finalize.checked_in", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT COUNT(*)
+			FROM registrations
+			WHERE event_id = $1
+			  AND status = 'confirmed'
+			  AND checked_in_at IS NOT NULL
+		`, eventID).Scan(&checkedIn)
+	})
+	if err != nil {
+		return
+	}
+	if checkedIn == 0 {
+		err = event.ErrCheckInNotUsed
+		return
+	}
+
+	// no-shows give their seats back, keeping registered_count equal to the
+	// confirmed seats the counter verification recounts
+	var noShows int
+	err = r.observe("attendance.finalize.mark_no_shows", func() error {
+		return tx.QueryRow(ctx, `
+			WITH marked AS (
+				UPDATE registrations
+				SET status = 'no_show',
+				    updated_at = NOW()
+				WHERE event_id = $1
+				  AND status = 'confirmed'
+				  AND checked_in_at IS NULL
+				RETURNING id, quantity
+			), recorded AS (
+				INSERT INTO registration_events (registration_id, event_id, kind, from_status, to_status)
+				SELECT id, $1, 'no_show', 'confirmed', 'no_show' FROM marked
+			), released AS (
+				UPDATE events
+				SET registered_count = GREATEST(registered_count - (SELECT COALESCE(SUM(quantity), 0) FROM marked), 0)
+				WHERE id = $1
+			)
+			SELECT COUNT(*) FROM marked
+		`, eventID).Scan(&noShows)
+	})
+	if err != nil {
+		return
+	}
+
+	err = r.observe("attendance.finalize.record", func() error {
+		return tx.QueryRow(ctx, `
+			INSERT INTO event_attendance_stats (event_id, registrations, checked_in, no_shows)
+			VALUES ($1, $2, $3, $4)
+			RETURNING finalized_at
+		`, eventID, checkedIn+noShows, checkedIn, noShows).Scan(&stats.FinalizedAt)
+	})
+	if err != nil {
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return
+	}
+
+	stats.EventID = eventID
+	stats.Registrations = checkedIn + noShows
+	stats.CheckedIn = checkedIn
+	stats.NoShows = noShows
+	stats.NoShowRate = event.NoShowRate(noShows, stats.Registrations)
+	return
+}
+
+func (r *AttendanceRepo) getTx(ctx context.Context, tx pgx.Tx, eventID string) (event.AttendanceStats, error) {
+	var s event.AttendanceStats
+	err := r.observe("attendance.get", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT event_id, registrations, checked_in, no_shows, finalized_at
+			FROM event_attendance_stats
+			WHERE event_id = $1
+		`, eventID).Scan(&s.EventID, &s.Registrations, &s.CheckedIn, &s.NoShows, &s.FinalizedAt)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, event.ErrAttendanceNotFinalized
+	}
+	s.NoShowRate = event.NoShowRate(s.NoShows, s.Registrations)
+	return s, err
+}
+
+// StatsForEvent returns the recorded attendance of a finalized event.
+func (r *AttendanceRepo) StatsForEvent(ctx context.Context, eventID string) (event.AttendanceStats, error) {
+	var s event.AttendanceStats
+	err := r.observe("attendance.stats_for_event", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT s.event_id, s.registrations, s.checked_in, s.no_shows, s.finalized_at
+			FROM event_attendance_stats s
+			JOIN events e ON e.id = s.event_id
+			WHERE s.event_id = $1
+			  AND e.deleted_at IS NULL
+		`, eventID).Scan(&s.EventID, &s.Registrations, &s.CheckedIn, &s.NoShows, &s.FinalizedAt)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, event.ErrAttendanceNotFinalized
+	}
+	s.NoShowRate = event.NoShowRate(s.NoShows, s.Registrations)
+	return s, err
+}
+
+// OrganizerRollup sums the finalized attendance of the organizer's events,
+// with the recentLimit most recently started ones listed individually.
+func (r *AttendanceRepo) OrganizerRollup(ctx context.Context, organizerID string, recentLimit int) (event.AttendanceRollup, error) {
+	out := event.AttendanceRollup{Recent: []event.AttendanceStats{}}
+
+	err := r.observe("attendance.organizer_rollup", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT COUNT(*), COALESCE(SUM(s.registrations), 0), COALESCE(SUM(s.checked_in), 0), COALESCE(SUM(s.no_shows), 0)
+			FROM event_attendance_stats s
+			JOIN events e ON e.id = s.event_id
+			WHERE e.organizer_id = $1
+			  AND e.deleted_at IS NULL
+		`, organizerID).Scan(&out.Events, &out.Registrations, &out.CheckedIn, &out.NoShows)
+	})
+	if err != nil {
+		return out, err
+	}
+	out.NoShowRate = event.NoShowRate(out.NoShows, out.Registrations)
+
+	var rows pgx.Rows
+	err = r.observe("attendance.organizer_recent", func() error {
+		var qerr error
+		rows, qerr = r.pool.Query(ctx, `
+			SELECT s.event_id, s.registrations, s.checked_in, s.no_shows, s.finalized_at
+			FROM event_attendance_stats s
+			JOIN events e ON e.id = s.event_id
+			WHERE e.organizer_id = $1
+			  AND e.deleted_at IS NULL
+			ORDER BY e.start_at DESC, e.id DESC
+			LIMIT $2
+		`, organizerID, recentLimit)
+		return qerr
+	})
+	if err != nil {
+		return out, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s event.AttendanceStats
+		if err := rows.Scan(&s.EventID, &s.Registrations, &s.CheckedIn, &s.NoShows, &s.FinalizedAt); err != nil {
+			return out, err
+		}
+		s.NoShowRate = event.NoShowRate(s.NoShows, s.Registrations)
+		out.Recent = append(out.Recent, s)
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// Dead letters are written by MarkFailed and consumed by Retry,
+// RetryManyFailed and ReplayDeadLetter; these are the admin reads and the
+// single replay.
+
+const deadLetterColumns = `
+	id, job_id, type, payload, attempts, max_attempts, priority,
+	idempotency_key, user_id, job_created_at, failed_at, final_error,
+	replayed_at, replayed_job_id
+`
+
+func scanDeadLetter(row pgx.Row) (job.DeadLetter, error) {
+	var d job.DeadLetter
+	err := row.Scan(
+		&d.ID, &d.JobID, &d.Type, &d.Payload, &d.Attempts, &d.MaxAttempts, &d.Priority,
+		&d.IdempotencyKey, &d.UserID, &d.JobCreatedAt, &d.FailedAt, &d.FinalError,
+		&d.ReplayedAt, &d.ReplayedJobID,
+	)
+	return d, err
+}
+
+// ListDeadLettersCursor pages through dead letters, most recently failed
+// first, starting after (afterFailedAt, afterID).
+func (r *JobsRepo) ListDeadLettersCursor(
+	ctx context.Context,
+	limit int,
+	afterFailedAt time.Time,
+	afterID string,
+) (items []job.DeadLetter, nextCursor *string, hasMore bool, err error) {
+	out := make([]job.DeadLetter, 0, limit)
+
+	err = r.observe("dead_letters.list_cursor", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT `+deadLetterColumns+`
+			FROM dead_letters
+			WHERE (failed_at, id) < ($1, $2)
+			ORDER BY failed_at DESC, id DESC
+			LIMIT $3
+		`, afterFailedAt, afterID, limit+1)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			d, err := scanDeadLetter(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, d)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if len(out) > limit {
+		hasMore = true
+		out = out[:limit]
+		last := out[len(out)-1]
+
+		cur, encErr := utils.EncodeDeadLetterCursor(last.FailedAt, last.ID)
+		if encErr != nil {
+			return nil, nil, false, encErr
+		}
+		nextCursor = &cur
+	}
+
+	return out, nextCursor, hasMore, nil
+}
+
+func (r *JobsRepo) GetDeadLetter(ctx context.Context, id string) (job.DeadLetter, error) {
+	var d job.DeadLetter
+	err := r.observe("dead_letters.get", func() error {
+		var err error
+		d, err = scanDeadLetter(r.pool.QueryRow(ctx, `
+			SELECT `+deadLetterColumns+`
+			FROM dead_letters
+			WHERE id = $1
+		`, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return job.DeadLetter{}, job.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return job.DeadLetter{}, err
+	}
+	return d, nil
+}
+
+// ReplayDeadLetter enqueues a fresh pending job from the dead letter (new ID,
+// no attempts, original payload) and marks it replayed. The dead letter is
+// locked for the transaction, so concurrent replays enqueue one job and the
+// rest get job.ErrDeadLetterReplayed.
+func (r *JobsRepo) ReplayDeadLetter(ctx context.Context, id string) (job.Job, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return job.Job{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var d job.DeadLetter
+	err = r.observe("dead_letters.replay.lock", func() error {
+		var err error
+		d, err = scanDeadLetter(tx.QueryRow(ctx, `
+			SELECT `+deadLetterColumns+`
+			FROM dead_letters
+			WHERE id = $1
+			FOR UPDATE
+		`, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return job.Job{}, job.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return job.Job{}, err
+	}
+	if d.Replayed() {
+		return job.Job{}, job.ErrDeadLetterReplayed
+	}
+
+	j, err := r.CreateTx(ctx, tx, d.ReplayRequest())
+	if err != nil {
+		return job.Job{}, err
+	}
+
+	err = r.observe("dead_letters.replay.mark", func() error {
+		_, err := tx.Exec(ctx, `
+			UPDATE dead_letters
+			SET replayed_at = NOW(),
+			    replayed_job_id = $2
+			WHERE id = $1
+		`, d.ID, j.ID)
+		return err
+	})
+	if err != nil {
+		return job.Job{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return job.Job{}, err
+	}
+	return j, nil
+}
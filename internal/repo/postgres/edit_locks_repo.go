@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EditLocksRepo keeps the advisory locks admins take on events they edit.
+// Callers pass now, so expiry follows the same clock that sets expires_at.
+type EditLocksRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewEditLocksRepo(pool *pgxpool.Pool, prom *observability.Prom) *EditLocksRepo {
+	return &EditLocksRepo{pool: pool, prom: prom}
+}
+
+func (r *EditLocksRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// Acquire takes the lock on eventID for holderID until now+ttl. The holder
+// of a lock renews it this way and keeps its token; an expired lock is taken
+// over. A live lock of someone else's is returned with ErrEditLockHeld, and
+// event.ErrNotFound when the event does not exist.
+func (r *EditLocksRepo) Acquire(ctx context.Context, eventID, holderID string, now time.Time, ttl time.Duration) (event.EditLock, error) {
+	var l event.EditLock
+
+	err := r.observe("edit_locks.acquire", func() error {
+		return r.pool.QueryRow(ctx, `
+			WITH upsert AS (
+				INSERT INTO edit_locks AS l (event_id, holder_id, token, acquired_at, expires_at)
+				SELECT e.id, $2, gen_random_uuid()::text, $3, $4
+				FROM events e
+				WHERE e.id = $1 AND e.deleted_at IS NULL
+				ON CONFLICT (event_id) DO UPDATE
+				SET token = CASE WHEN l.holder_id = EXCLUDED.holder_id THEN l.token ELSE EXCLUDED.token END,
+				    acquired_at = CASE
+				        WHEN l.holder_id = EXCLUDED.holder_id AND l.expires_at > $3 THEN l.acquired_at
+				        ELSE EXCLUDED.acquired_at
+				    END,
+				    holder_id = EXCLUDED.holder_id,
+				    expires_at = EXCLUDED.expires_at
+				WHERE l.holder_id = EXCLUDED.holder_id OR l.expires_at <= $3
+				RETURNING l.event_id, l.holder_id, l.token, l.acquired_at, l.expires_at
+			)
+			SELECT u.event_id::text, u.holder_id::text, COALESCE(us.email, ''), u.token, u.acquired_at, u.expires_at
+			FROM upsert u
+			LEFT JOIN users us ON us.id = u.holder_id
+		`, eventID, holderID, now, now.Add(ttl)).Scan(&l.EventID, &l.HolderID, &l.HolderEmail, &l.Token, &l.AcquiredAt, &l.ExpiresAt)
+	})
+	if err == nil {
+		return l, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return event.EditLock{}, err
+	}
+
+	// nothing written: either the event is gone or the lock is someone else's
+	held, err := r.Get(ctx, eventID, now)
+	if err != nil {
+		if errors.Is(err, event.ErrEditLockNotFound) {
+			return event.EditLock{}, event.ErrNotFound
+		}
+		return event.EditLock{}, err
+	}
+	return held, event.ErrEditLockHeld
+}
+
+// Get returns the live lock on eventID, or ErrEditLockNotFound. An expired
+// lock found on the way is deleted.
+func (r *EditLocksRepo) Get(ctx context.Context, eventID string, now time.Time) (event.EditLock, error) {
+	var l event.EditLock
+
+	err := r.observe("edit_locks.get", func() error {
+		return r.pool.QueryRow(ctx, `
+			WITH expired AS (
+				DELETE FROM edit_locks WHERE event_id = $1 AND expires_at <= $2
+			)
+			SELECT l.event_id::text, l.holder_id::text, COALESCE(u.email, ''), l.token, l.acquired_at, l.expires_at
+			FROM edit_locks l
+			LEFT JOIN users u ON u.id = l.holder_id
+			WHERE l.event_id = $1 AND l.expires_at > $2
+		`, eventID, now).Scan(&l.EventID, &l.HolderID, &l.HolderEmail, &l.Token, &l.AcquiredAt, &l.ExpiresAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return event.EditLock{}, event.ErrEditLockNotFound
+		}
+		return event.EditLock{}, err
+	}
+	return l, nil
+}
+
+// Release drops holderID's lock on eventID, or anyone's when force is set.
+// With no live lock there is nothing to release and it succeeds; a live lock
+// of someone else's is returned with ErrEditLockHeld.
+func (r *EditLocksRepo) Release(ctx context.Context, eventID, holderID string, force bool, now time.Time) (event.EditLock, error) {
+	var released bool
+
+	err := r.observe("edit_locks.release", func() error {
+		tag, err := r.pool.Exec(ctx, `
+			DELETE FROM edit_locks
+			WHERE event_id = $1
+			  AND (holder_id = $2 OR expires_at <= $3 OR $4)
+		`, eventID, holderID, now, force)
+		released = tag.RowsAffected() > 0
+		return err
+	})
+	if err != nil || released {
+		return event.EditLock{}, err
+	}
+
+	held, err := r.Get(ctx, eventID, now)
+	if err != nil {
+		if errors.Is(err, event.ErrEditLockNotFound) {
+			return event.EditLock{}, nil
+		}
+		return event.EditLock{}, err
+	}
+	return held, event.ErrEditLockHeld
+}
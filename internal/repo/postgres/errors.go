@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/user"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const uniqueViolationCode = "23505"
+
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return true
+	}
+	return false
+}
+
+// ConstraintName returns the constraint (or unique index) a Postgres error
+// was raised for, "" for anything else.
+func ConstraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
+
+// uniqueConstraintErrors is what a violation of each unique constraint means
+// to callers. Every unique constraint the migrations create is either listed
+// here or in uniqueConstraintExemptions; TestUniqueConstraintsAreMapped
+// keeps the two in step with db/migrations.
+var uniqueConstraintErrors = map[string]error{
+	"users_email_key":                      user.ErrEmailTaken,
+	"registrations_event_lower_email_uniq": registration.ErrAlreadyRegistered,
+	"jobs_idempotency_key_uniq":            job.ErrDuplicateIdempotencyKey,
+}
+
+// uniqueConstraintExemptions are the unique constraints a request can't
+// collide on, with the reason why.
+var uniqueConstraintExemptions = map[string]string{
+	"events_pkey":                                    "generated UUID",
+	"users_pkey":                                     "generated UUID",
+	"registrations_pkey":                             "generated UUID",
+	"jobs_pkey":                                      "generated UUID",
+	"refresh_tokens_pkey":                            "generated UUID",
+	"api_keys_pkey":                                  "generated UUID",
+	"webhooks_pkey":                                  "generated UUID",
+	"webhook_deliveries_pkey":                        "serial id",
+	"notification_deliveries_pkey":                   "serial id",
+	"admin_action_audits_pkey":                       "generated UUID",
+	"privacy_audit_pkey":                             "generated UUID",
+	"registration_csv_exports_pkey":                  "one row per export job",
+	"event_attendance_stats_pkey":                    "written once under the event lock",
+	"event_funnel_daily_pkey":                        "upserted with ON CONFLICT",
+	"job_stats_daily_pkey":                           "upserted with ON CONFLICT",
+	"job_schedules_pkey":                             "generated UUID",
+	"registration_events_pkey":                       "generated UUID",
+	"dead_letters_pkey":                              "generated UUID",
+	"job_attempts_pkey":                              "serial id",
+	"queue_state_pkey":                               "single row seeded by its migration",
+	"service_instances_pkey":                         "upserted with ON CONFLICT",
+	"paused_job_types_pkey":                          "upserted with ON CONFLICT",
+	"recipient_quarantines_pkey":                     "upserted with ON CONFLICT",
+	"edit_locks_pkey":                                "upserted with ON CONFLICT",
+	"dead_letters_job_unreplayed_uniq":               "inserted with ON CONFLICT DO NOTHING",
+	"idempotent_responses_pkey":                      "inserted with ON CONFLICT DO NOTHING",
+	"api_keys_key_hash_key":                          "hash of 32 random bytes",
+	"idx_registrations_event_check_in_token":         "random check-in token",
+	"notification_deliveries_kind_registration_uniq": "dedupe; callers check IsUniqueViolation",
+	"notification_deliveries_kind_dedupe_key_uniq":   "dedupe; callers check IsUniqueViolation",
+}
+
+// mapUniqueViolation turns a violation of a known unique constraint into its
+// domain error. The Postgres error stays wrapped, so IsUniqueViolation still
+// holds for callers that treat any duplicate as "already done".
+func mapUniqueViolation(err error) error {
+	if !IsUniqueViolation(err) {
+		return err
+	}
+	if domainErr, ok := uniqueConstraintErrors[ConstraintName(err)]; ok {
+		return fmt.Errorf("%w: %w", domainErr, err)
+	}
+	return err
+}
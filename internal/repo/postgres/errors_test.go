@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	createTableRe  = regexp.MustCompile(`(?i)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+	namedUniqueRe  = regexp.MustCompile(`(?i)CONSTRAINT (\w+) (?:UNIQUE|PRIMARY KEY)`)
+	uniqueIndexRe  = regexp.MustCompile(`(?i)CREATE UNIQUE INDEX (?:IF NOT EXISTS )?(\w+)`)
+	dropRe         = regexp.MustCompile(`(?i)DROP (?:CONSTRAINT|INDEX) (?:IF EXISTS )?(\w+)`)
+	inlineColumnRe = regexp.MustCompile(`^(\w+)\s+\w+.*\b(UNIQUE|PRIMARY KEY)\b`)
+	tableKeyRe     = regexp.MustCompile(`(?i)^PRIMARY KEY\s*\(`)
+)
+
+// migrationUniqueConstraints replays the Up half of every migration and
+// returns the unique constraints and indexes that exist at the end, named the
+// way Postgres names them.
+func migrationUniqueConstraints(t *testing.T) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	sort.Strings(files)
+
+	live := map[string]bool{}
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		up, _, _ := strings.Cut(string(raw), "-- +goose Down")
+
+		table := ""
+		for _, line := range strings.Split(up, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "--") || line == "" {
+				continue
+			}
+			if m := createTableRe.FindStringSubmatch(line); m != nil {
+				table = m[1]
+				continue
+			}
+			if strings.HasPrefix(line, ")") {
+				table = ""
+			}
+
+			if m := dropRe.FindStringSubmatch(line); m != nil {
+				delete(live, m[1])
+			}
+			if m := namedUniqueRe.FindStringSubmatch(line); m != nil {
+				live[m[1]] = true
+				continue
+			}
+			if m := uniqueIndexRe.FindStringSubmatch(line); m != nil {
+				live[m[1]] = true
+				continue
+			}
+			if table == "" {
+				continue
+			}
+			if tableKeyRe.MatchString(line) {
+				live[table+"_pkey"] = true
+				continue
+			}
+			if m := inlineColumnRe.FindStringSubmatch(line); m != nil {
+				if strings.EqualFold(m[2], "PRIMARY KEY") {
+					live[table+"_pkey"] = true
+				} else {
+					live[table+"_"+m[1]+"_key"] = true
+				}
+			}
+		}
+	}
+
+	out := make([]string, 0, len(live))
+	for name := range live {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestUniqueConstraintsAreMapped(t *testing.T) {
+	found := migrationUniqueConstraints(t)
+
+	seen := map[string]bool{}
+	for _, name := range found {
+		seen[name] = true
+		_, mapped := uniqueConstraintErrors[name]
+		_, exempt := uniqueConstraintExemptions[name]
+		switch {
+		case mapped && exempt:
+			t.Errorf("%s is both mapped and exempt", name)
+		case !mapped && !exempt:
+			t.Errorf("%s has no domain error; map it in uniqueConstraintErrors or exempt it", name)
+		}
+	}
+
+	// stale entries hide a renamed constraint
+	for name := range uniqueConstraintErrors {
+		if !seen[name] {
+			t.Errorf("uniqueConstraintErrors lists %s, which no migration creates", name)
+		}
+	}
+	for name := range uniqueConstraintExemptions {
+		if !seen[name] {
+			t.Errorf("uniqueConstraintExemptions lists %s, which no migration creates", name)
+		}
+	}
+}
+
+func TestMapUniqueViolation_KeepsPgError(t *testing.T) {
+	err := mapUniqueViolation(&pgconn.PgError{Code: "23505", ConstraintName: "jobs_idempotency_key_uniq"})
+	if !errors.Is(err, job.ErrDuplicateIdempotencyKey) || !IsUniqueViolation(err) {
+		t.Fatalf("expected a duplicate key error that is still a unique violation, got %v", err)
+	}
+
+	other := &pgconn.PgError{Code: "23505", ConstraintName: "api_keys_key_hash_key"}
+	if err := mapUniqueViolation(other); err != other {
+		t.Fatalf("expected an unmapped constraint to pass through, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EventCountersRepo verifies and repairs events.registered_count against the
+// confirmed rows in registrations.
+type EventCountersRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewEventCountersRepo(pool *pgxpool.Pool, prom *observability.Prom) *EventCountersRepo {
+	return &EventCountersRepo{pool: pool, prom: prom}
+}
+
+func (r *EventCountersRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// RecountEvent recomputes the counter for one event in its own short
+// transaction. The event row is locked FOR UPDATE (the same lock registration
+// takes) so the recount cannot race a live sign-up or cancellation.
+func (r *EventCountersRepo) RecountEvent(ctx context.Context, eventID string) (repair event.CounterRepair, err error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	repair.EventID = eventID
+
+	err = r.observe("event_counters.recount.lock", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT registered_count
+			FROM events
+			WHERE id = $1
+			FOR UPDATE
+		`, eventID).Scan(&repair.Stored)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = event.ErrNotFound
+		}
+		return
+	}
+
+	err = r.observe("event_counters.recount.count", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(quantity), 0)
+			FROM registrations
+			WHERE event_id = $1
+			  AND status = 'confirmed'
+		`, eventID).Scan(&repair.Actual)
+	})
+	if err != nil {
+		return
+	}
+
+	repair.Delta = repair.Actual - repair.Stored
+
+	if repair.Delta != 0 {
+		err = r.observe("event_counters.recount.repair", func() error {
+			_, e := tx.Exec(ctx, `UPDATE events SET registered_count = $2 WHERE id = $1`, eventID, repair.Actual)
+			return e
+		})
+		if err != nil {
+			return
+		}
+		repair.Repaired = true
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return
+	}
+
+	if repair.Repaired && r.prom != nil {
+		r.prom.CounterDriftRowsTotal.Inc()
+	}
+
+	return
+}
+
+// Availability reads the cached registered_count, so it stays a single
+// indexed lookup however busy the event is.
+func (r *EventCountersRepo) Availability(ctx context.Context, eventID string) (a event.Availability, err error) {
+	a.EventID = eventID
+
+	err = r.observe("event_counters.availability", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT capacity, registered_count, max_quantity, requires_auth, allowed_email_domains
+			FROM events
+			WHERE id = $1
+			  AND deleted_at IS NULL
+		`, eventID).Scan(&a.Capacity, &a.RegisteredCount, &a.MaxQuantity, &a.RequiresAuth, &a.AllowedEmailDomains)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = event.ErrNotFound
+		}
+		return
+	}
+
+	a.Remaining = a.Capacity - a.RegisteredCount
+	if a.Remaining < 0 {
+		a.Remaining = 0
+	}
+	a.Full = a.Remaining == 0
+
+	return
+}
+
+// PlatformStats counts published, undeleted events, those of them starting
+// in [monthStart, monthEnd), and their registrations that were neither
+// cancelled nor kept only as a duplicate, in one aggregate query.
+func (r *EventCountersRepo) PlatformStats(ctx context.Context, monthStart, monthEnd time.Time) (s event.PlatformStats, err error) {
+	err = r.observe("events.platform_stats", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT COUNT(*),
+			       COALESCE(SUM(reg.active), 0),
+			       COUNT(*) FILTER (WHERE e.start_at >= $1 AND e.start_at < $2)
+			FROM events e
+			LEFT JOIN LATERAL (
+				SELECT COUNT(*) AS active
+				FROM registrations r
+				WHERE r.event_id = e.id AND r.status <> 'cancelled' AND r.duplicate_of IS NULL
+			) reg ON TRUE
+			WHERE e.deleted_at IS NULL AND e.published_at IS NOT NULL
+		`, monthStart, monthEnd).Scan(&s.EventsHosted, &s.Registrations, &s.EventsThisMonth)
+	})
+	return
+}
+
+// ListActiveEventIDs pages (by id) through events that were touched, or had a
+// registration touched, since the given time.
+func (r *EventCountersRepo) ListActiveEventIDs(ctx context.Context, since time.Time, afterID string, limit int) ([]string, error) {
+	var rows pgx.Rows
+	err := r.observe("event_counters.list_active", func() error {
+		var qerr error
+		rows, qerr = r.pool.Query(ctx, `
+			SELECT e.id
+			FROM events e
+			WHERE e.deleted_at IS NULL
+			  AND e.id > $2
+			  AND (
+				e.updated_at >= $1
+				OR EXISTS (
+					SELECT 1 FROM registrations r
+					WHERE r.event_id = e.id
+					  AND r.updated_at >= $1
+				)
+			  )
+			ORDER BY e.id ASC
+			LIMIT $3
+		`, since, afterID, limit)
+		return qerr
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if scanErr := rows.Scan(&id); scanErr != nil {
+			return nil, scanErr
+		}
+		ids = append(ids, id)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return ids, nil
+}
+
+// VerifyActive recounts every event with activity since the given time,
+// batchSize events at a time, and reports the drift it found and repaired.
+func (r *EventCountersRepo) VerifyActive(ctx context.Context, since time.Time, batchSize int) (event.DriftStats, error) {
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+
+	var stats event.DriftStats
+	afterID := "00000000-0000-0000-0000-000000000000"
+
+	for {
+		ids, err := r.ListActiveEventIDs(ctx, since, afterID, batchSize)
+		if err != nil {
+			return stats, err
+		}
+
+		for _, id := range ids {
+			repair, err := r.RecountEvent(ctx, id)
+			if err != nil {
+				// deleted between listing and locking: nothing to repair
+				if errors.Is(err, event.ErrNotFound) {
+					continue
+				}
+				return stats, err
+			}
+			stats.Add(repair)
+		}
+
+		if len(ids) < batchSize {
+			break
+		}
+		afterID = ids[len(ids)-1]
+	}
+
+	if r.prom != nil {
+		r.prom.CounterDriftMaxDelta.Set(float64(stats.MaxDelta))
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/funnel"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EventFunnelRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewEventFunnelRepo(pool *pgxpool.Pool, prom *observability.Prom) *EventFunnelRepo {
+	return &EventFunnelRepo{pool: pool, prom: prom}
+}
+
+func (r *EventFunnelRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// ApplyIncrements upserts a batch of counter deltas in a single round trip.
+func (r *EventFunnelRepo) ApplyIncrements(ctx context.Context, incs []funnel.Increment) error {
+	if len(incs) == 0 {
+		return nil
+	}
+
+	op := "event_funnel.apply_increments"
+
+	return r.observe(op, func() error {
+		batch := &pgx.Batch{}
+		for _, inc := range incs {
+			batch.Queue(`
+				INSERT INTO event_funnel_daily (event_id, day, stage, reason, count, updated_at)
+				VALUES ($1, $2, $3, $4, $5, NOW())
+				ON CONFLICT (event_id, day, stage, reason)
+				DO UPDATE SET count = event_funnel_daily.count + EXCLUDED.count,
+				              updated_at = NOW()
+			`, inc.EventID, inc.Day, string(inc.Stage), inc.Reason, inc.Count)
+		}
+
+		return r.pool.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (r *EventFunnelRepo) DailySeries(ctx context.Context, eventID string, from, to time.Time) ([]funnel.DailyCount, error) {
+	op := "event_funnel.daily_series"
+
+	var rows pgx.Rows
+	err := r.observe(op, func() error {
+		var qerr error
+		rows, qerr = r.pool.Query(ctx, `
+			SELECT day, stage, reason, SUM(count)::bigint
+			FROM event_funnel_daily
+			WHERE event_id = $1
+			  AND day BETWEEN $2 AND $3
+			GROUP BY day, stage, reason
+			ORDER BY day ASC, stage ASC, reason ASC
+		`, eventID, from, to)
+		return qerr
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]funnel.DailyCount, 0)
+	for rows.Next() {
+		var dc funnel.DailyCount
+		var stage string
+		if scanErr := rows.Scan(&dc.Day, &stage, &dc.Reason, &dc.Count); scanErr != nil {
+			return nil, scanErr
+		}
+		dc.Stage = funnel.Stage(stage)
+		out = append(out, dc)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return out, nil
+}
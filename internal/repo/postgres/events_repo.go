@@ -74,10 +74,15 @@ func (r *EventsRepo) Create(ctx context.Context, req event.CreateEventRequest) (
 	e.Tags = normalizeEventTags(req.Tags)
 	op := "events.create"
 
+	e.DescriptionHTMLGzip, err = event.GzipDescriptionHTML(e.Description)
+	if err != nil {
+		return event.Event{}, err
+	}
+
 	err = r.observe(op, func() error {
 		_, err = r.pool.Exec(ctx,
-			`INSERT INTO events(id,title, description, city, category, tags, start_at, capacity,created_at, updated_at) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
-			e.ID, e.Title, e.Description, e.City, e.Category, e.Tags, e.StartAt, e.Capacity, e.CreatedAt, e.UpdatedAt,
+			`INSERT INTO events(id,title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, organizer_id, capacity_alert_thresholds, registration_opens_at, registration_closes_at, created_at, updated_at, description_html_gz, visibility) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,NULLIF($12, '')::uuid,$13,$14,$15,$16,$17,$18,$19)`,
+			e.ID, e.Title, e.Description, e.City, e.Category, e.Tags, e.StartAt, e.Capacity, e.RequiresAuth, e.AllowedEmailDomains, e.MaxQuantity, e.OrganizerID, e.CapacityAlertThresholds, e.RegistrationOpensAt, e.RegistrationClosesAt, e.CreatedAt, e.UpdatedAt, e.DescriptionHTMLGzip, e.Visibility,
 		)
 
 		return err
@@ -91,6 +96,10 @@ func (r *EventsRepo) Create(ctx context.Context, req event.CreateEventRequest) (
 
 }
 
+// listedEventsCond keeps listings and their counts to live public events;
+// unlisted and private ones are only reached by id.
+const listedEventsCond = "deleted_at IS NULL AND visibility = 'public'"
+
 func (r *EventsRepo) List(ctx context.Context, filteredEvents event.ListEventsFilter) ([]event.Event, int, error) {
 	var rows pgx.Rows
 	var err error
@@ -105,14 +114,23 @@ func (r *EventsRepo) List(ctx context.Context, filteredEvents event.ListEventsFi
 		tags,
 		start_at, 
 		capacity,
+		requires_auth,
+		allowed_email_domains,
+		max_quantity,
+		capacity_alert_thresholds,
+		COALESCE(organizer_id::text, ''),
+		registration_opens_at,
+		registration_closes_at,
+		registered_count,
 	  created_at,
 		updated_at,
+		visibility,
 		COUNT(*) OVER() AS TOTAL
 	FROM events 
 	`
 
 	var conds []string
-	conds = append(conds, "deleted_at IS NULL")
+	conds = append(conds, listedEventsCond)
 	var args []interface{}
 
 	argsPosition := 1
@@ -191,7 +209,7 @@ func (r *EventsRepo) List(ctx context.Context, filteredEvents event.ListEventsFi
 		var e event.Event
 		var t int
 
-		err = rows.Scan(&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.CreatedAt, &e.UpdatedAt, &t)
+		err = rows.Scan(&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.RequiresAuth, &e.AllowedEmailDomains, &e.MaxQuantity, &e.CapacityAlertThresholds, &e.OrganizerID, &e.RegistrationOpensAt, &e.RegistrationClosesAt, &e.RegisteredCount, &e.CreatedAt, &e.UpdatedAt, &e.Visibility, &t)
 
 		if err != nil {
 			return nil, 0, err
@@ -214,7 +232,7 @@ func (r *EventsRepo) Count(ctx context.Context, filteredEvents event.ListEventsF
 	op := "events.count"
 
 	var conds []string
-	conds = append(conds, "deleted_at IS NULL")
+	conds = append(conds, listedEventsCond)
 	var args []interface{}
 	argsPos := 1
 
@@ -280,7 +298,7 @@ func (r *EventsRepo) ListCursor(
 	op := "events.list_cursor"
 
 	var conds []string
-	conds = append(conds, "deleted_at IS NULL")
+	conds = append(conds, listedEventsCond)
 	var args []interface{}
 	argsPos := 1
 
@@ -330,8 +348,14 @@ func (r *EventsRepo) ListCursor(
 	args = append(args, afterStartAt, afterID)
 	argsPos += 2
 
+	// the compressed HTML is only read off disk for lists that ask for it
+	descriptionHTMLCol := "NULL::bytea"
+	if filteredEvents.IncludeDescriptionHTML {
+		descriptionHTMLCol = "description_html_gz"
+	}
+
 	q := `
-		SELECT id, title, description, city, category, tags, start_at, capacity, created_at, updated_at
+		SELECT id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility, ` + descriptionHTMLCol + `
 		FROM events
 	`
 	if len(conds) > 0 {
@@ -358,7 +382,7 @@ func (r *EventsRepo) ListCursor(
 	for rows.Next() {
 		var e event.Event
 		if scanErr := rows.Scan(
-			&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.CreatedAt, &e.UpdatedAt,
+			&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.RequiresAuth, &e.AllowedEmailDomains, &e.MaxQuantity, &e.CapacityAlertThresholds, &e.OrganizerID, &e.RegistrationOpensAt, &e.RegistrationClosesAt, &e.RegisteredCount, &e.CreatedAt, &e.UpdatedAt, &e.Visibility, &e.DescriptionHTMLGzip,
 		); scanErr != nil {
 			return nil, nil, false, scanErr
 		}
@@ -383,13 +407,55 @@ func (r *EventsRepo) ListCursor(
 	return out, nextCursor, hasMore, nil
 }
 
+// ListByOrganizerCursor pages through the events organizerID created, oldest
+// first, starting after (afterCreatedAt, afterID).
+func (r *EventsRepo) ListByOrganizerCursor(
+	ctx context.Context,
+	organizerID string,
+	limit int,
+	afterCreatedAt time.Time,
+	afterID string,
+) ([]event.Event, error) {
+	var rows pgx.Rows
+	err := r.observe("events.list_by_organizer_cursor", func() error {
+		var qerr error
+		rows, qerr = r.pool.Query(ctx, `
+			SELECT id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility
+			FROM events
+			WHERE organizer_id = $1
+			  AND deleted_at IS NULL
+			  AND (created_at, id) > ($2, $3)
+			ORDER BY created_at ASC, id ASC
+			LIMIT $4
+		`, organizerID, afterCreatedAt, afterID, limit)
+		return qerr
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]event.Event, 0, limit)
+	for rows.Next() {
+		var e event.Event
+		if scanErr := rows.Scan(
+			&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.RequiresAuth, &e.AllowedEmailDomains, &e.MaxQuantity, &e.CapacityAlertThresholds, &e.OrganizerID, &e.RegistrationOpensAt, &e.RegistrationClosesAt, &e.RegisteredCount, &e.CreatedAt, &e.UpdatedAt, &e.Visibility,
+		); scanErr != nil {
+			return nil, scanErr
+		}
+		out = append(out, e)
+	}
+
+	return out, rows.Err()
+}
+
 func (r *EventsRepo) GetByID(ctx context.Context, id string) (event.Event, error) {
 	var e event.Event
 	var err error
 	op := "events.GetByID"
 
 	err = r.observe(op, func() error {
-		return r.pool.QueryRow(ctx, `SELECT id, title, description, city, category, tags, start_at, capacity, created_at, updated_at FROM events WHERE id =$1 AND deleted_at IS NULL`, id).Scan(&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.CreatedAt, &e.UpdatedAt)
+		return r.pool.QueryRow(ctx, `SELECT id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility, description_html_gz FROM events WHERE id =$1 AND deleted_at IS NULL`, id).Scan(&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.RequiresAuth, &e.AllowedEmailDomains, &e.MaxQuantity, &e.CapacityAlertThresholds, &e.OrganizerID, &e.RegistrationOpensAt, &e.RegistrationClosesAt, &e.RegisteredCount, &e.CreatedAt, &e.UpdatedAt, &e.Visibility, &e.DescriptionHTMLGzip)
 	})
 
 	if err != nil {
@@ -399,12 +465,42 @@ func (r *EventsRepo) GetByID(ctx context.Context, id string) (event.Event, error
 	return e, nil
 }
 
+// GetByIDs returns the live events among ids in one query, in no particular
+// order; ids with no live event are left out.
+func (r *EventsRepo) GetByIDs(ctx context.Context, ids []string) ([]event.Event, error) {
+	out := make([]event.Event, 0, len(ids))
+	err := r.observe("events.get_by_ids", func() error {
+		rows, err := r.pool.Query(ctx, `SELECT id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility, description_html_gz FROM events WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL`, ids)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var e event.Event
+			if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.City, &e.Category, &e.Tags, &e.StartAt, &e.Capacity, &e.RequiresAuth, &e.AllowedEmailDomains, &e.MaxQuantity, &e.CapacityAlertThresholds, &e.OrganizerID, &e.RegistrationOpensAt, &e.RegistrationClosesAt, &e.RegisteredCount, &e.CreatedAt, &e.UpdatedAt, &e.Visibility, &e.DescriptionHTMLGzip); err != nil {
+				return err
+			}
+			out = append(out, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *EventsRepo) Update(ctx context.Context, id string, req event.UpdateEventRequest) (event.Event, error) {
 	var e event.Event
 	var err error
 	op := "events.update"
 	category := normalizeEventCategory(req.Category)
 	tags := normalizeEventTags(req.Tags)
+	descriptionHTML, err := event.GzipDescriptionHTML(req.Description)
+	if err != nil {
+		return event.Event{}, err
+	}
 
 	err = r.observe(op, func() error {
 		return r.pool.QueryRow(
@@ -417,10 +513,18 @@ func (r *EventsRepo) Update(ctx context.Context, id string, req event.UpdateEven
 					capacity = $6,
 					category = $7,
 					tags = $8,
+					requires_auth = $9,
+					allowed_email_domains = $10,
+					max_quantity = $11,
+					capacity_alert_thresholds = $12,
+					registration_opens_at = $13,
+					registration_closes_at = $14,
+					description_html_gz = $15,
+					visibility = COALESCE(NULLIF($16, ''), visibility),
 					updated_at = NOW()
 			WHERE id = $1
 				AND deleted_at IS NULL
-			RETURNING id, title, description, city, category, tags, start_at, capacity, created_at, updated_at`,
+			RETURNING id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility`,
 			id,
 			req.Title,
 			req.Description,
@@ -429,6 +533,14 @@ func (r *EventsRepo) Update(ctx context.Context, id string, req event.UpdateEven
 			req.Capacity,
 			category,
 			tags,
+			req.RequiresAuth,
+			event.NormalizeEmailDomains(req.AllowedEmailDomains),
+			max(req.MaxQuantity, 1),
+			event.NormalizeCapacityAlertThresholds(req.CapacityAlertThresholds),
+			req.RegistrationOpensAt,
+			req.RegistrationClosesAt,
+			descriptionHTML,
+			string(req.Visibility),
 		).Scan(
 			&e.ID,
 			&e.Title,
@@ -438,8 +550,17 @@ func (r *EventsRepo) Update(ctx context.Context, id string, req event.UpdateEven
 			&e.Tags,
 			&e.StartAt,
 			&e.Capacity,
+			&e.RequiresAuth,
+			&e.AllowedEmailDomains,
+			&e.MaxQuantity,
+			&e.CapacityAlertThresholds,
+			&e.OrganizerID,
+			&e.RegistrationOpensAt,
+			&e.RegistrationClosesAt,
+			&e.RegisteredCount,
 			&e.CreatedAt,
 			&e.UpdatedAt,
+			&e.Visibility,
 		)
 	})
 
@@ -497,7 +618,7 @@ func (r *EventsRepo) Restore(ctx context.Context, id string) (event.Event, error
 			    updated_at = NOW()
 			WHERE id = $1
 			  AND deleted_at IS NOT NULL
-			RETURNING id, title, description, city, category, tags, start_at, capacity, created_at, updated_at
+			RETURNING id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility
 		`, id).Scan(
 			&e.ID,
 			&e.Title,
@@ -507,8 +628,17 @@ func (r *EventsRepo) Restore(ctx context.Context, id string) (event.Event, error
 			&e.Tags,
 			&e.StartAt,
 			&e.Capacity,
+			&e.RequiresAuth,
+			&e.AllowedEmailDomains,
+			&e.MaxQuantity,
+			&e.CapacityAlertThresholds,
+			&e.OrganizerID,
+			&e.RegistrationOpensAt,
+			&e.RegistrationClosesAt,
+			&e.RegisteredCount,
 			&e.CreatedAt,
 			&e.UpdatedAt,
+			&e.Visibility,
 		)
 	})
 	if err == nil {
@@ -520,7 +650,7 @@ func (r *EventsRepo) Restore(ctx context.Context, id string) (event.Event, error
 
 	err = r.observe(op+".check_active", func() error {
 		return r.pool.QueryRow(ctx, `
-			SELECT id, title, description, city, category, tags, start_at, capacity, created_at, updated_at
+			SELECT id, title, description, city, category, tags, start_at, capacity, requires_auth, allowed_email_domains, max_quantity, capacity_alert_thresholds, COALESCE(organizer_id::text, ''), registration_opens_at, registration_closes_at, registered_count, created_at, updated_at, visibility
 			FROM events
 			WHERE id = $1
 			  AND deleted_at IS NULL
@@ -533,8 +663,17 @@ func (r *EventsRepo) Restore(ctx context.Context, id string) (event.Event, error
 			&e.Tags,
 			&e.StartAt,
 			&e.Capacity,
+			&e.RequiresAuth,
+			&e.AllowedEmailDomains,
+			&e.MaxQuantity,
+			&e.CapacityAlertThresholds,
+			&e.OrganizerID,
+			&e.RegistrationOpensAt,
+			&e.RegistrationClosesAt,
+			&e.RegisteredCount,
 			&e.CreatedAt,
 			&e.UpdatedAt,
+			&e.Visibility,
 		)
 	})
 	if err == nil {
@@ -558,6 +697,7 @@ func (r *EventsRepo) MarkPublished(ctx context.Context, eventID string) (bool, e
 		tag, err = r.pool.Exec(ctx, `
 		UPDATE events
 		SET published_at = NOW(),
+		    publish_announce_pending = true,
 		    updated_at = NOW()
 			WHERE id = $1
 			  AND published_at IS NULL
@@ -570,3 +710,179 @@ func (r *EventsRepo) MarkPublished(ctx context.Context, eventID string) (bool, e
 	}
 	return tag.RowsAffected() == 1, nil
 }
+
+// PublishAnnouncementPending reports whether the attendees of a published
+// event have yet to be told about it: MarkPublished sets the marker, and
+// MarkPublishAnnounced clears it.
+func (r *EventsRepo) PublishAnnouncementPending(ctx context.Context, eventID string) (bool, error) {
+	var pending bool
+
+	err := r.observe("events.publish_announcement_pending", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT publish_announce_pending
+			FROM events
+			WHERE id = $1
+			  AND deleted_at IS NULL
+		`, eventID).Scan(&pending)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return pending, err
+}
+
+// MarkPublishAnnounced clears the marker once every announcement is queued.
+func (r *EventsRepo) MarkPublishAnnounced(ctx context.Context, eventID string) error {
+	return r.observe("events.mark_publish_announced", func() error {
+		_, err := r.pool.Exec(ctx, `
+			UPDATE events
+			SET publish_announce_pending = false
+			WHERE id = $1
+		`, eventID)
+		return err
+	})
+}
+
+func (r *EventsRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// PublishedTx maps each live event in ids to whether it is published, locking
+// the rows until tx ends so none is deleted or published behind a batch
+// publish checking them. Unknown and deleted events are absent from the map.
+func (r *EventsRepo) PublishedTx(ctx context.Context, tx pgx.Tx, ids []string) (map[string]bool, error) {
+	out := make(map[string]bool, len(ids))
+
+	err := r.observe("events.published_tx", func() error {
+		rows, err := tx.Query(ctx, `
+			SELECT id::text, published_at IS NOT NULL
+			FROM events
+			WHERE id = ANY($1::uuid[])
+			  AND deleted_at IS NULL
+			ORDER BY id
+			FOR UPDATE
+		`, ids)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id string
+			var published bool
+			if err := rows.Scan(&id, &published); err != nil {
+				return err
+			}
+			out[id] = published
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Organizers maps each live event in ids to its organizer ("" when it has
+// none). Unknown and deleted events are absent from the map.
+func (r *EventsRepo) Organizers(ctx context.Context, ids []string) (map[string]string, error) {
+	out := make(map[string]string, len(ids))
+
+	err := r.observe("events.organizers", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id::text, COALESCE(organizer_id::text, '')
+			FROM events
+			WHERE id = ANY($1::uuid[])
+			  AND deleted_at IS NULL
+		`, ids)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id, organizerID string
+			if err := rows.Scan(&id, &organizerID); err != nil {
+				return err
+			}
+			out[id] = organizerID
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetAccess returns a live event's visibility and organizer, for deciding
+// who may open it without loading the rest of the event.
+func (r *EventsRepo) GetAccess(ctx context.Context, id string) (event.Access, error) {
+	var a event.Access
+
+	err := r.observe("events.get_access", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT visibility, COALESCE(organizer_id::text, '')
+			FROM events
+			WHERE id = $1
+			  AND deleted_at IS NULL
+		`, id).Scan(&a.Visibility, &a.OrganizerID)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return event.Access{}, event.ErrNotFound
+	}
+	if err != nil {
+		return event.Access{}, err
+	}
+	return a, nil
+}
+
+// GetBranding returns the organizer branding of a live event.
+func (r *EventsRepo) GetBranding(ctx context.Context, id string) (event.Branding, error) {
+	var b event.Branding
+
+	err := r.observe("events.get_branding", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT COALESCE(branding_reply_to, ''), COALESCE(branding_display_name, ''), COALESCE(branding_logo_url, '')
+			FROM events
+			WHERE id = $1
+			  AND deleted_at IS NULL
+		`, id).Scan(&b.ReplyTo, &b.DisplayName, &b.LogoURL)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return event.Branding{}, event.ErrNotFound
+		}
+		return event.Branding{}, err
+	}
+
+	return b, nil
+}
+
+// UpdateBranding replaces a live event's branding; empty fields are stored as NULL.
+func (r *EventsRepo) UpdateBranding(ctx context.Context, id string, b event.Branding) (event.Branding, error) {
+	var out event.Branding
+
+	err := r.observe("events.update_branding", func() error {
+		return r.pool.QueryRow(ctx, `
+			UPDATE events
+			SET branding_reply_to = NULLIF($2, ''),
+			    branding_display_name = NULLIF($3, ''),
+			    branding_logo_url = NULLIF($4, ''),
+			    updated_at = NOW()
+			WHERE id = $1
+			  AND deleted_at IS NULL
+			RETURNING COALESCE(branding_reply_to, ''), COALESCE(branding_display_name, ''), COALESCE(branding_logo_url, '')
+		`, id, b.ReplyTo, b.DisplayName, b.LogoURL).Scan(&out.ReplyTo, &out.DisplayName, &out.LogoURL)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return event.Branding{}, event.ErrNotFound
+		}
+		return event.Branding{}, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// housekeepingLockKey is the advisory lock key the worker replicas contend
+// for; it spells "job_hkpr".
+const housekeepingLockKey int64 = 0x6a6f625f686b7072
+
+// AdvisoryLock is a session-level Postgres advisory lock. The session holding
+// it is a connection taken out of the pool for good, so the lock lasts until
+// Release or until that connection dies, when the server drops it and the
+// next TryAcquire from any instance gets it.
+type AdvisoryLock struct {
+	pool *pgxpool.Pool
+	key  int64
+
+	mu   sync.Mutex
+	conn *pgx.Conn
+}
+
+// NewHousekeepingLock is the lock electing the worker replica that runs
+// housekeeping.
+func NewHousekeepingLock(pool *pgxpool.Pool) *AdvisoryLock {
+	return &AdvisoryLock{pool: pool, key: housekeepingLockKey}
+}
+
+// TryAcquire takes the lock when it is free. While held it pings the holding
+// connection instead, so a lock lost with its connection is reported as lost
+// and tried for again.
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.pool == nil {
+		return false, errors.New("advisory lock: no database pool")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		_ = l.conn.Close(context.Background())
+		l.conn = nil
+	}
+
+	pc, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := pc.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired); err != nil {
+		pc.Release()
+		return false, err
+	}
+	if !acquired {
+		pc.Release()
+		return false, nil
+	}
+
+	l.conn = pc.Hijack()
+	return true, nil
+}
+
+// Release unlocks and closes the holding connection; it does nothing when
+// the lock is not held.
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+
+	_, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key)
+	if cerr := conn.Close(context.Background()); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geocoder89/eventhub/internal/domain/idempotency"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotentResponsesRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewIdempotentResponsesRepo(pool *pgxpool.Pool, prom *observability.Prom) *IdempotentResponsesRepo {
+	return &IdempotentResponsesRepo{pool: pool, prom: prom}
+}
+
+func (r *IdempotentResponsesRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// Save records the first response for a key; later saves for the same key are
+// ignored so the original always wins.
+func (r *IdempotentResponsesRepo) Save(ctx context.Context, resp idempotency.Response) error {
+	return r.observe("idempotent_responses.save", func() error {
+		_, err := r.pool.Exec(ctx, `
+			INSERT INTO idempotent_responses (idempotency_key, status_code, body, job_id, created_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (idempotency_key) DO NOTHING
+		`, resp.Key, resp.StatusCode, resp.Body, resp.JobID)
+		return err
+	})
+}
+
+func (r *IdempotentResponsesRepo) Get(ctx context.Context, key string) (idempotency.Response, error) {
+	var out idempotency.Response
+
+	err := r.observe("idempotent_responses.get", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT idempotency_key, status_code, body, job_id::text, created_at
+			FROM idempotent_responses
+			WHERE idempotency_key = $1
+		`, key).Scan(&out.Key, &out.StatusCode, &out.Body, &out.JobID, &out.CreatedAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return idempotency.Response{}, idempotency.ErrNotFound
+		}
+		return idempotency.Response{}, err
+	}
+
+	return out, nil
+}
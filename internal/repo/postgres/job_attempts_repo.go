@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobAttemptsRepo stores the per-execution history of jobs.
+type JobAttemptsRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewJobAttemptsRepo(pool *pgxpool.Pool, prom *observability.Prom) *JobAttemptsRepo {
+	return &JobAttemptsRepo{pool: pool, prom: prom}
+}
+
+func (r *JobAttemptsRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// InsertBatch writes attempts in one statement. Attempts of jobs deleted
+// since they ran are skipped rather than failing the batch.
+func (r *JobAttemptsRepo) InsertBatch(ctx context.Context, attempts []job.Attempt) error {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	jobIDs := make([]string, len(attempts))
+	numbers := make([]int32, len(attempts))
+	workerIDs := make([]string, len(attempts))
+	startedAts := make([]time.Time, len(attempts))
+	finishedAts := make([]time.Time, len(attempts))
+	outcomes := make([]string, len(attempts))
+	errs := make([]*string, len(attempts))
+	durations := make([]int64, len(attempts))
+	traceIDs := make([]string, len(attempts))
+	spanIDs := make([]string, len(attempts))
+	for i, a := range attempts {
+		jobIDs[i], numbers[i], workerIDs[i] = a.JobID, int32(a.Attempt), a.WorkerID
+		startedAts[i], finishedAts[i] = a.StartedAt, a.FinishedAt
+		outcomes[i], errs[i], durations[i] = string(a.Outcome), a.Error, a.DurationMs
+		traceIDs[i], spanIDs[i] = a.TraceID, a.SpanID
+	}
+
+	return r.observe("job_attempts.insert_batch", func() error {
+		_, err := r.pool.Exec(ctx, `
+			INSERT INTO job_attempts (job_id, attempt, worker_id, started_at, finished_at, outcome, error, duration_ms, trace_id, span_id)
+			SELECT u.job_id, u.attempt, u.worker_id, u.started_at, u.finished_at, u.outcome, u.error, u.duration_ms,
+			       NULLIF(u.trace_id, ''), NULLIF(u.span_id, '')
+			FROM unnest($1::uuid[], $2::int[], $3::text[], $4::timestamptz[], $5::timestamptz[], $6::text[], $7::text[], $8::bigint[], $9::text[], $10::text[])
+				AS u(job_id, attempt, worker_id, started_at, finished_at, outcome, error, duration_ms, trace_id, span_id)
+			WHERE EXISTS (SELECT 1 FROM jobs j WHERE j.id = u.job_id)
+		`, jobIDs, numbers, workerIDs, startedAts, finishedAts, outcomes, errs, durations, traceIDs, spanIDs)
+		return err
+	})
+}
+
+// ListByJob returns jobID's attempts in the order they ran.
+func (r *JobAttemptsRepo) ListByJob(ctx context.Context, jobID string) ([]job.Attempt, error) {
+	out := []job.Attempt{}
+	err := r.observe("job_attempts.list_by_job", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT job_id, attempt, worker_id, started_at, finished_at, outcome, error, duration_ms,
+			       COALESCE(trace_id, ''), COALESCE(span_id, '')
+			FROM job_attempts
+			WHERE job_id = $1
+			ORDER BY attempt, started_at, id
+		`, jobID)
+		if err != nil {
+			return err
+		}
+
+		out, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (job.Attempt, error) {
+			var a job.Attempt
+			err := row.Scan(&a.JobID, &a.Attempt, &a.WorkerID, &a.StartedAt, &a.FinishedAt, &a.Outcome, &a.Error, &a.DurationMs, &a.TraceID, &a.SpanID)
+			return a, err
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
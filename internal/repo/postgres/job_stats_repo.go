@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobStatsRepo stores the workers' daily job outcome rollups.
+type JobStatsRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewJobStatsRepo(pool *pgxpool.Pool, prom *observability.Prom) *JobStatsRepo {
+	return &JobStatsRepo{pool: pool, prom: prom}
+}
+
+func (r *JobStatsRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// UpsertDaily records s as the worker's totals for its day, replacing what
+// that worker wrote earlier the same day.
+func (r *JobStatsRepo) UpsertDaily(ctx context.Context, s job.DailyStats) error {
+	return r.observe("job_stats.upsert_daily", func() error {
+		_, err := r.pool.Exec(ctx, `
+			INSERT INTO job_stats_daily (day, worker_id, claimed, done, failed, retried, dead_lettered, timed_out, updated_at)
+			VALUES ($1::date, $2, $3, $4, $5, $6, $7, $8, NOW())
+			ON CONFLICT (day, worker_id) DO UPDATE
+			SET claimed = EXCLUDED.claimed,
+			    done = EXCLUDED.done,
+			    failed = EXCLUDED.failed,
+			    retried = EXCLUDED.retried,
+			    dead_lettered = EXCLUDED.dead_lettered,
+			    timed_out = EXCLUDED.timed_out,
+			    updated_at = NOW()
+		`, s.Day.UTC().Format(time.DateOnly), s.WorkerID, s.Claimed, s.Done, s.Failed, s.Retried, s.DeadLettered, s.TimedOut)
+		return err
+	})
+}
+
+// ListDaily returns the per-worker rows for days from through to (inclusive).
+func (r *JobStatsRepo) ListDaily(ctx context.Context, from, to time.Time) ([]job.DailyStats, error) {
+	out := []job.DailyStats{}
+
+	err := r.observe("job_stats.list_daily", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT day, worker_id, claimed, done, failed, retried, dead_lettered, timed_out
+			FROM job_stats_daily
+			WHERE day BETWEEN $1::date AND $2::date
+			ORDER BY day DESC, worker_id
+		`, from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var s job.DailyStats
+			if err := rows.Scan(&s.Day, &s.WorkerID, &s.Claimed, &s.Done, &s.Failed, &s.Retried, &s.DeadLettered, &s.TimedOut); err != nil {
+				return err
+			}
+			s.Day = s.Day.UTC()
+			out = append(out, s)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -2,9 +2,11 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/domain/job"
@@ -20,6 +22,15 @@ var ErrJobNotFailed = errors.New("job is not failed")
 type JobsRepo struct {
 	pool *pgxpool.Pool
 	prom *observability.Prom
+
+	// claim order boost for jobs left waiting; off by default
+	aging job.Aging
+
+	limits job.PayloadLimits
+
+	// last progress write per job, for UpdateProgress's rate limit
+	progressMu      sync.Mutex
+	progressWritten map[string]time.Time
 }
 
 func (repo *JobsRepo) observe(op string, fn func() error) error {
@@ -30,101 +41,285 @@ func (repo *JobsRepo) observe(op string, fn func() error) error {
 }
 
 func NewJobsRepo(pool *pgxpool.Pool, prom *observability.Prom) *JobsRepo {
-	return &JobsRepo{pool: pool, prom: prom}
+	return &JobsRepo{pool: pool, prom: prom, limits: job.DefaultPayloadLimits}
 }
 
-func IsUniqueViolation(err error) bool {
-	var pgErr *pgconn.PgError
+// WithPayloadLimits replaces job.DefaultPayloadLimits for every Create*.
+func (r *JobsRepo) WithPayloadLimits(l job.PayloadLimits) *JobsRepo {
+	r.limits = l
+	return r
+}
 
-	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-		return true
+// checkPayload turns away payloads over the limits before they are stored and
+// then re-read on every claim and retry.
+func (r *JobsRepo) checkPayload(req job.CreateRequest) error {
+	err := r.limits.Check(req.Payload)
+
+	var limitErr *job.PayloadLimitError
+	if errors.As(err, &limitErr) {
+		reason := "too_large"
+		if limitErr.TooDeep() {
+			reason = "too_deep"
+		}
+		r.prom.IncJobEnqueueRejected(req.Type, reason)
 	}
-	return false
+	return err
 }
 
-func (r *JobsRepo) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
-	j := job.New(req)
-	op := "jobs.create"
+// WithAging makes ClaimNext order by effective priority, see job.Aging.
+func (r *JobsRepo) WithAging(a job.Aging) *JobsRepo {
+	r.aging = a
+	return r
+}
 
-	var err error
+// effectivePrioritySQL is the priority a pending job is claimed at. Both
+// settings come from config, never from requests, so they are inlined.
+func (r *JobsRepo) effectivePrioritySQL() string {
+	if !r.aging.Enabled() {
+		return "priority"
+	}
+	return fmt.Sprintf(
+		"(priority + LEAST(FLOOR(GREATEST(EXTRACT(EPOCH FROM NOW() - run_at), 0) / %d)::int, %d))",
+		int64(r.aging.Interval/time.Second), r.aging.MaxBoost,
+	)
+}
 
-	err = r.observe(op, func() error {
-		_, err = r.pool.Exec(ctx, `INSERT INTO jobs(
-	 id, type, payload, status, attempts,max_attempts, run_at, locked_at, locked_by, last_error,idempotency_key,priority,user_id, created_at, updated_at
-	 ) VALUES (
-		$1,$2,$3,$4,
-		$5,$6,$7,$8,$9,
-		$10,$11,$12,$13,$14,$15
-	 
-	 )
-	 
-	 `, j.ID, j.Type, j.Payload, string(j.Status), j.Attempts, j.MaxAttempts, j.RunAt, j.LockedAt, j.LockedBy, j.LastError, req.IdempotencyKey, j.Priority, j.UserID, j.CreatedAt, j.UpdatedAt)
+// insertJobSQL inserts one job built by job.New; insertJobArgs are its
+// arguments. Create, CreateOrGet and CreateTx all insert through them.
+const insertJobSQL = `
+	INSERT INTO jobs (
+		id, type, payload, status, attempts, max_attempts, run_at, locked_at, locked_by, last_error,
+		idempotency_key, priority, user_id, created_at, updated_at, trace_parent, trace_state
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+		$11, $12, $13, $14, $15, $16, $17
+	)`
+
+func insertJobArgs(j job.Job) []any {
+	return []any{
+		j.ID, j.Type, j.Payload, string(j.Status), j.Attempts, j.MaxAttempts, j.RunAt, j.LockedAt, j.LockedBy, j.LastError,
+		j.IdempotencyKey, j.Priority, j.UserID, j.CreatedAt, j.UpdatedAt, j.TraceParent, j.TraceState,
+	}
+}
+
+func (r *JobsRepo) Create(ctx context.Context, req job.CreateRequest) (job.Job, error) {
+	if err := r.checkPayload(req); err != nil {
+		return job.Job{}, err
+	}
+	j := job.New(withTraceParent(ctx, req))
 
+	err := r.observe("jobs.create", func() error {
+		_, err := r.pool.Exec(ctx, insertJobSQL, insertJobArgs(j)...)
 		return err
 	})
-
 	if err != nil {
+		return job.Job{}, mapUniqueViolation(err)
+	}
+	return j, nil
+}
+
+// CreateOrGet creates req's job, or returns the job already holding its
+// idempotency key; created reports which. Concurrent callers with the same
+// key all get the one job back. Without a key it is Create.
+func (r *JobsRepo) CreateOrGet(ctx context.Context, req job.CreateRequest) (job.Job, bool, error) {
+	if req.IdempotencyKey == nil {
+		j, err := r.Create(ctx, req)
+		return j, err == nil, err
+	}
+	if err := r.checkPayload(req); err != nil {
+		return job.Job{}, false, err
+	}
+	j := job.New(withTraceParent(ctx, req))
+
+	// the holder of the key can be purged between the insert and the read,
+	// so a miss on the read tries the insert once more
+	for range 2 {
+		var inserted bool
+		err := r.observe("jobs.create_or_get", func() error {
+			var id string
+			err := r.pool.QueryRow(ctx, insertJobSQL+`
+				ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+				RETURNING id
+			`, insertJobArgs(j)...).Scan(&id)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			inserted = err == nil
+			return err
+		})
+		if err != nil {
+			return job.Job{}, false, mapUniqueViolation(err)
+		}
+		if inserted {
+			return j, true, nil
+		}
+
+		existing, err := r.GetByIdempotencyKey(ctx, *req.IdempotencyKey)
+		if errors.Is(err, job.ErrJobNotFound) {
+			continue
+		}
+		return existing, false, err
+	}
+	return job.Job{}, false, job.ErrJobNotFound
+}
+
+func (r *JobsRepo) CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error) {
+	if err := r.checkPayload(req); err != nil {
 		return job.Job{}, err
 	}
+	j := job.New(withTraceParent(ctx, req))
 
+	err := r.observe("jobs.create_tx", func() error {
+		_, err := tx.Exec(ctx, insertJobSQL, insertJobArgs(j)...)
+		return err
+	})
+	if err != nil {
+		return job.Job{}, mapUniqueViolation(err)
+	}
 	return j, nil
 }
 
-func (r *JobsRepo) CreateTx(ctx context.Context, tx pgx.Tx, req job.CreateRequest) (job.Job, error) {
-	j := job.New(req)
+// withTraceParent fills in req's trace context from the span in ctx unless
+// the caller set one, so a job enqueued while serving a request or running
+// another job continues that trace.
+func withTraceParent(ctx context.Context, req job.CreateRequest) job.CreateRequest {
+	if req.TraceParent == "" {
+		req.TraceParent, req.TraceState = observability.TraceParent(ctx)
+	}
+	return req
+}
 
-	op := "jobs.create_tx"
-	var err error
+// CreateManyTx inserts reqs in one statement. Requests whose idempotency key
+// already exists are skipped rather than failing the transaction, so the
+// returned jobs are only the ones inserted now.
+func (r *JobsRepo) CreateManyTx(ctx context.Context, tx pgx.Tx, reqs []job.CreateRequest) ([]job.Job, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	for _, req := range reqs {
+		if err := r.checkPayload(req); err != nil {
+			return nil, err
+		}
+	}
+
+	all := make(map[string]job.Job, len(reqs))
+	ids := make([]string, len(reqs))
+	types := make([]string, len(reqs))
+	payloads := make([]string, len(reqs))
+	maxAttempts := make([]int32, len(reqs))
+	runAts := make([]time.Time, len(reqs))
+	keys := make([]*string, len(reqs))
+	priorities := make([]int32, len(reqs))
+	userIDs := make([]*string, len(reqs))
+	traceParents := make([]*string, len(reqs))
+	traceStates := make([]*string, len(reqs))
+	for i, req := range reqs {
+		j := job.New(withTraceParent(ctx, req))
+		all[j.ID] = j
+		ids[i], types[i], payloads[i] = j.ID, j.Type, string(j.Payload)
+		maxAttempts[i], runAts[i], keys[i] = int32(j.MaxAttempts), j.RunAt, j.IdempotencyKey
+		priorities[i], userIDs[i] = int32(j.Priority), j.UserID
+		traceParents[i], traceStates[i] = j.TraceParent, j.TraceState
+	}
 
-	err = r.observe(
-		op, func() error {
-
-			_, err = tx.Exec(ctx, `INSERT INTO jobs(
-	 id, type, payload, status, attempts,max_attempts, run_at, locked_at, locked_by, last_error,idempotency_key,priority,user_id, created_at, updated_at
-	 ) VALUES (
-		$1,$2,$3,$4,
-		$5,$6,$7,$8,$9,
-		$10,$11,$12,$13,$14,$15
-	 
-	 )
-	 
-	 `, j.ID, j.Type, j.Payload, string(j.Status), j.Attempts, j.MaxAttempts, j.RunAt, j.LockedAt, j.LockedBy, j.LastError, req.IdempotencyKey, j.Priority, j.UserID, j.CreatedAt, j.UpdatedAt)
+	var created []job.Job
+	err := r.observe("jobs.create_many_tx", func() error {
+		rows, err := tx.Query(ctx, `
+			INSERT INTO jobs (id, type, payload, status, attempts, max_attempts, run_at, idempotency_key, priority, user_id, created_at, updated_at, trace_parent, trace_state)
+			SELECT u.id, u.type, u.payload::jsonb, 'pending', 0, u.max_attempts, u.run_at, u.idempotency_key, u.priority, u.user_id::uuid, NOW(), NOW(), u.trace_parent, u.trace_state
+			FROM unnest($1::uuid[], $2::text[], $3::text[], $4::int[], $5::timestamptz[], $6::text[], $7::int[], $8::text[], $9::text[], $10::text[])
+				AS u(id, type, payload, max_attempts, run_at, idempotency_key, priority, user_id, trace_parent, trace_state)
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		`, ids, types, payloads, maxAttempts, runAts, keys, priorities, userIDs, traceParents, traceStates)
+		if err != nil {
 			return err
-		},
-	)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			created = append(created, all[id])
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// CreateMany is CreateManyTx in a transaction of its own, for callers
+// enqueueing a batch outside any other write.
+func (r *JobsRepo) CreateMany(ctx context.Context, reqs []job.CreateRequest) ([]job.Job, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
 
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return job.Job{}, err
+		return nil, err
 	}
-	return j, nil
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	created, err := r.CreateManyTx(ctx, tx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
+// MarkFailed dead-letters the job: the status change and its dead_letters
+// copy are one statement, so a failed job always has one.
 func (r *JobsRepo) MarkFailed(ctx context.Context, id string, errMsg string) error {
-	var tag pgconn.CommandTag
+	var n int
 	var err error
 	op := "jobs.mark_failed"
 
 	err = r.observe(op, func() error {
-		tag, err = r.pool.Exec(ctx, `
-		UPDATE jobs
-		SET status = 'failed',
-		    locked_at = NULL,
-		    locked_by = NULL,
-		    last_error = $2,
-		    updated_at = NOW()
-		WHERE id = $1
-	`, id, errMsg)
-		return err
+		return r.pool.QueryRow(ctx, `
+		WITH failed AS (
+			UPDATE jobs
+			SET status = 'failed',
+			    locked_at = NULL,
+			    locked_by = NULL,
+			    last_error = $2,
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING id, type, payload, attempts, max_attempts, priority,
+			          idempotency_key, user_id, created_at, updated_at, last_error
+		), dead AS (
+			INSERT INTO dead_letters (
+				job_id, type, payload, attempts, max_attempts, priority,
+				idempotency_key, user_id, job_created_at, failed_at, final_error
+			)
+			SELECT id, type, payload, attempts, max_attempts, priority,
+			       idempotency_key, user_id, created_at, updated_at, last_error
+			FROM failed
+			ON CONFLICT (job_id) WHERE replayed_at IS NULL DO NOTHING
+		)
+		SELECT COUNT(*) FROM failed
+	`, id, errMsg).Scan(&n)
 	})
 
 	if err != nil {
 		return err
 	}
-	if tag.RowsAffected() == 0 {
+	if n == 0 {
 		return job.ErrJobNotFound
 	}
 	return nil
 }
+
 func (r *JobsRepo) MarkDone(ctx context.Context, id string) error {
 	var tag pgconn.CommandTag
 	var err error
@@ -165,7 +360,7 @@ func (r *JobsRepo) Reschedule(ctx context.Context, id string, runAt time.Time, e
 		// Useful for retries/backoff
 		tag, err = r.pool.Exec(ctx, `
 		UPDATE jobs
-		SET status = 'pending',
+		SET status = CASE WHEN cancellation_requested THEN 'cancelled' ELSE 'pending' END,
 		    attempts = attempts + 1,
 		    run_at = $2,
 		    locked_at = NULL,
@@ -188,9 +383,41 @@ func (r *JobsRepo) Reschedule(ctx context.Context, id string, runAt time.Time, e
 	return nil
 }
 
+// RescheduleWithMaxAttempts is Reschedule that also sets max_attempts, for
+// job types whose retry policy overrides the limit the job was enqueued with.
+func (r *JobsRepo) RescheduleWithMaxAttempts(ctx context.Context, id string, runAt time.Time, errMsg string, maxAttempts int) error {
+	var tag pgconn.CommandTag
+
+	err := r.observe("jobs.reschedule", func() error {
+		var err error
+		tag, err = r.pool.Exec(ctx, `
+			UPDATE jobs
+			SET status = CASE WHEN cancellation_requested THEN 'cancelled' ELSE 'pending' END,
+			    attempts = attempts + 1,
+			    max_attempts = $4,
+			    run_at = $2,
+			    locked_at = NULL,
+			    locked_by = NULL,
+			    last_error = $3,
+			    updated_at = NOW()
+			WHERE id = $1
+		`, id, runAt, errMsg, maxAttempts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return job.ErrJobNotFound
+	}
+	return nil
+}
+
 func (r *JobsRepo) ClaimNext(ctx context.Context, workerID string) (job.Job, error) {
 	// Single statement claim using SKIP LOCKED pattern.
 	// Only claims jobs ready to run (pending, run_at <= now), and not exceeded max_attempts.
+	// Paused types are read in the same statement; the worker's batch claims
+	// pass its cached set to ClaimBatchExcluding instead.
 	var j job.Job
 	var status string
 	var err error
@@ -205,7 +432,8 @@ func (r *JobsRepo) ClaimNext(ctx context.Context, workerID string) (job.Job, err
 			WHERE status = 'pending'
 			  AND run_at <= NOW()
 			  AND attempts < max_attempts
-			ORDER BY priority DESC, run_at ASC, created_at ASC
+			  AND NOT EXISTS (SELECT 1 FROM paused_job_types p WHERE p.type = jobs.type)
+			ORDER BY `+r.effectivePrioritySQL()+` DESC, run_at ASC, created_at ASC
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
 		)
@@ -218,12 +446,14 @@ func (r *JobsRepo) ClaimNext(ctx context.Context, workerID string) (job.Job, err
 		RETURNING id, type, payload, status,
 		          attempts, max_attempts,
 		          run_at, locked_at, locked_by,
-		          last_error,idempotency_key,priority,user_id, created_at, updated_at
+		          last_error,idempotency_key,priority,user_id, created_at, updated_at,
+		          trace_parent, trace_state
 	`, workerID).Scan(
 			&j.ID, &j.Type, &j.Payload, &status,
 			&j.Attempts, &j.MaxAttempts,
 			&j.RunAt, &j.LockedAt, &j.LockedBy,
 			&j.LastError, &j.IdempotencyKey, &j.Priority, &j.UserID, &j.CreatedAt, &j.UpdatedAt,
+			&j.TraceParent, &j.TraceState,
 		)
 
 	})
@@ -239,6 +469,148 @@ func (r *JobsRepo) ClaimNext(ctx context.Context, workerID string) (job.Job, err
 	return j, nil
 }
 
+// ClaimBatch claims up to n ready jobs for workerID in one statement, in
+// ClaimNext's order. An empty slice means nothing was ready.
+func (r *JobsRepo) ClaimBatch(ctx context.Context, workerID string, n int) ([]job.Job, error) {
+	return r.ClaimBatchExcluding(ctx, workerID, n, nil)
+}
+
+// ClaimBatchExcluding is ClaimBatch that leaves jobs of excludeTypes alone,
+// for a worker whose slots for those types are full or whose types are
+// paused.
+func (r *JobsRepo) ClaimBatchExcluding(ctx context.Context, workerID string, n int, excludeTypes []string) ([]job.Job, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if excludeTypes == nil {
+		excludeTypes = []string{}
+	}
+
+	out := make([]job.Job, 0, n)
+
+	err := r.observe("jobs.claim_batch", func() error {
+		// UPDATE ... RETURNING has no order of its own, so the ranking columns
+		// ride along from the locking select and the outer query re-sorts
+		rows, err := r.pool.Query(ctx, `
+		WITH next AS (
+			SELECT id, `+r.effectivePrioritySQL()+` AS effective_priority
+			FROM jobs
+			WHERE status = 'pending'
+			  AND run_at <= NOW()
+			  AND attempts < max_attempts
+			  AND type <> ALL($3::text[])
+			ORDER BY `+r.effectivePrioritySQL()+` DESC, run_at ASC, created_at ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT $2
+		), claimed AS (
+			UPDATE jobs
+			SET status = 'processing',
+			    locked_at = NOW(),
+			    locked_by = $1,
+			    updated_at = NOW()
+			FROM next
+			WHERE jobs.id = next.id
+			RETURNING jobs.id, jobs.type, jobs.payload, jobs.status,
+			          jobs.attempts, jobs.max_attempts,
+			          jobs.run_at, jobs.locked_at, jobs.locked_by,
+			          jobs.last_error, jobs.idempotency_key, jobs.priority, jobs.user_id, jobs.created_at, jobs.updated_at,
+			          jobs.trace_parent, jobs.trace_state,
+			          next.effective_priority
+		)
+		SELECT id, type, payload, status,
+		       attempts, max_attempts,
+		       run_at, locked_at, locked_by,
+		       last_error, idempotency_key, priority, user_id, created_at, updated_at,
+		       trace_parent, trace_state
+		FROM claimed
+		ORDER BY effective_priority DESC, run_at ASC, created_at ASC
+	`, workerID, n, excludeTypes)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var j job.Job
+			var status string
+			if err := rows.Scan(
+				&j.ID, &j.Type, &j.Payload, &status,
+				&j.Attempts, &j.MaxAttempts,
+				&j.RunAt, &j.LockedAt, &j.LockedBy,
+				&j.LastError, &j.IdempotencyKey, &j.Priority, &j.UserID, &j.CreatedAt, &j.UpdatedAt,
+				&j.TraceParent, &j.TraceState,
+			); err != nil {
+				return err
+			}
+			j.Status = job.Status(status)
+			out = append(out, j)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Release hands a job claimed by workerID back without running it: pending
+// again, due now, attempts untouched. A job whose cancellation was requested
+// is cancelled instead. job.ErrLockLost means workerID no longer holds it.
+func (r *JobsRepo) Release(ctx context.Context, id, workerID string) error {
+	var tag pgconn.CommandTag
+
+	err := r.observe("jobs.release", func() error {
+		var err error
+		tag, err = r.pool.Exec(ctx, `
+			UPDATE jobs
+			SET status = CASE WHEN cancellation_requested THEN 'cancelled' ELSE 'pending' END,
+			    run_at = NOW(),
+			    locked_at = NULL,
+			    locked_by = NULL,
+			    updated_at = NOW()
+			WHERE id = $1
+			  AND status = 'processing'
+			  AND locked_by = $2
+		`, id, workerID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return job.ErrLockLost
+	}
+	return nil
+}
+
+// ReleaseOwnedBy hands back every job still processing under workerID,
+// pending again with its attempts untouched, or cancelled if that was asked
+// for. It is for a worker starting up under its previous ID: those locks
+// belong to runs that died with the old process. It returns how many were
+// released.
+func (r *JobsRepo) ReleaseOwnedBy(ctx context.Context, workerID string) (int64, error) {
+	var tag pgconn.CommandTag
+
+	err := r.observe("jobs.release_owned_by", func() error {
+		var err error
+		tag, err = r.pool.Exec(ctx, `
+			UPDATE jobs
+			SET status = CASE WHEN cancellation_requested THEN 'cancelled' ELSE 'pending' END,
+			    locked_at = NULL,
+			    locked_by = NULL,
+			    updated_at = NOW()
+			WHERE status = 'processing'
+			  AND locked_by = $1
+		`, workerID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 func (r *JobsRepo) FetchNextPending(ctx context.Context) (job.Job, error) {
 	var j job.Job
 	var status string
@@ -315,39 +687,160 @@ func (r *JobsRepo) GetByIdempotencyKey(ctx context.Context, key string) (job.Job
 	return j, nil
 }
 
-// Requeue a stale processing job i.e lockTTL is greater than the time now i.e it is stale
+// ExtendLock renews workerID's lock on a processing job so the stale requeue
+// leaves it alone. job.ErrLockLost means the job is no longer ours;
+// job.ErrCancelRequested means an admin asked for it to stop.
+func (r *JobsRepo) ExtendLock(ctx context.Context, id, workerID string) error {
+	var cancelRequested bool
+	err := r.observe("jobs.extend_lock", func() error {
+		return r.pool.QueryRow(ctx, `
+			UPDATE jobs
+			SET locked_at = NOW()
+			WHERE id = $1
+			  AND status = 'processing'
+			  AND locked_by = $2
+			RETURNING cancellation_requested
+		`, id, workerID).Scan(&cancelRequested)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return job.ErrLockLost
+	}
+	if err != nil {
+		return err
+	}
+	if cancelRequested {
+		return job.ErrCancelRequested
+	}
+	return nil
+}
 
-func (r *JobsRepo) RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (int64, error) {
-	secs := int64(lockTTL.Seconds())
-	if secs <= 0 {
-		secs = 30
+// Cancel stops a job: a pending job becomes cancelled at once, a processing
+// one is flagged for its worker to abort. It returns the job's status after
+// the call. Finished jobs give job.ErrJobNotCancellable.
+func (r *JobsRepo) Cancel(ctx context.Context, id string) (job.Status, error) {
+	var status job.Status
+	err := r.observe("jobs.admin.cancel", func() error {
+		return r.pool.QueryRow(ctx, `
+			UPDATE jobs
+			SET status = CASE WHEN status = 'pending' THEN 'cancelled' ELSE status END,
+			    cancellation_requested = (status = 'processing'),
+			    updated_at = NOW()
+			WHERE id = $1
+			  AND status IN ('pending', 'processing')
+			RETURNING status
+		`, id).Scan(&status)
+	})
+	if err == nil {
+		return status, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
 	}
-	var rows int64
+
+	// nothing updated: tell a missing job from a finished one
+	err = r.observe("jobs.admin.cancel.check_status", func() error {
+		return r.pool.QueryRow(ctx, `SELECT status FROM jobs WHERE id = $1`, id).Scan(&status)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", job.ErrJobNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return status, job.ErrJobNotCancellable
+}
+
+// MarkCancelled records that workerID aborted a job on an admin's request.
+// job.ErrLockLost means the job is no longer workerID's to record: the stale
+// requeue took it back and another worker may hold it now.
+func (r *JobsRepo) MarkCancelled(ctx context.Context, id, workerID string, errMsg string) error {
+	var tag pgconn.CommandTag
 	var err error
 
-	op := "jobs.requeue_stale"
-	err = r.observe(op, func() error {
-		tag, err := r.pool.Exec(ctx, `
+	err = r.observe("jobs.mark_cancelled", func() error {
+		tag, err = r.pool.Exec(ctx, `
 		UPDATE jobs
-		SET status = 'pending',
+		SET status = 'cancelled',
 		    locked_at = NULL,
 		    locked_by = NULL,
+		    last_error = $2,
 		    updated_at = NOW()
-		WHERE status = 'processing'
-		  AND locked_at IS NOT NULL
-		  AND locked_at < NOW() - ($1 * INTERVAL '1 second')
-	`, secs)
+		WHERE id = $1
+		  AND status = 'processing'
+		  AND locked_by = $3
+	`, id, errMsg, workerID)
+		return err
+	})
 
-		if err != nil {
-			return err
-		}
-		rows = tag.RowsAffected()
-		return nil
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return job.ErrLockLost
+	}
+	return nil
+}
 
-	})
+// Requeue a stale processing job i.e lockTTL is greater than the time now i.e it is stale
 
-	return rows, err
+// RequeueStaleProcessing takes back processing jobs whose lock is older
+// than lockTTL, counting each as a failed attempt with job.StaleLockError: a
+// job with attempts left goes back to pending, one without is dead-lettered
+// like MarkFailed does, so a job that keeps crashing its worker stops being
+// retried. Jobs an admin asked to cancel are cancelled without using one.
+func (r *JobsRepo) RequeueStaleProcessing(ctx context.Context, lockTTL time.Duration) (job.StaleRequeue, error) {
+	secs := int64(lockTTL.Seconds())
+	if secs <= 0 {
+		secs = 30
+	}
 
+	var res job.StaleRequeue
+	err := r.observe("jobs.requeue_stale", func() error {
+		return r.pool.QueryRow(ctx, `
+		WITH stale AS (
+			SELECT id
+			FROM jobs
+			WHERE status = 'processing'
+			  AND locked_at IS NOT NULL
+			  AND locked_at < NOW() - ($1 * INTERVAL '1 second')
+			FOR UPDATE SKIP LOCKED
+		), taken AS (
+			UPDATE jobs j
+			SET status = CASE
+			        WHEN j.cancellation_requested THEN 'cancelled'
+			        WHEN j.attempts + 1 >= j.max_attempts THEN 'failed'
+			        ELSE 'pending'
+			    END,
+			    attempts = CASE WHEN j.cancellation_requested THEN j.attempts ELSE j.attempts + 1 END,
+			    last_error = CASE WHEN j.cancellation_requested THEN j.last_error ELSE $2 END,
+			    locked_at = NULL,
+			    locked_by = NULL,
+			    updated_at = NOW()
+			FROM stale
+			WHERE j.id = stale.id
+			RETURNING j.id, j.type, j.payload, j.status, j.attempts, j.max_attempts, j.priority,
+			          j.idempotency_key, j.user_id, j.created_at, j.updated_at, j.last_error
+		), dead AS (
+			INSERT INTO dead_letters (
+				job_id, type, payload, attempts, max_attempts, priority,
+				idempotency_key, user_id, job_created_at, failed_at, final_error
+			)
+			SELECT id, type, payload, attempts, max_attempts, priority,
+			       idempotency_key, user_id, created_at, updated_at, last_error
+			FROM taken
+			WHERE status = 'failed'
+			ON CONFLICT (job_id) WHERE replayed_at IS NULL DO NOTHING
+		)
+		SELECT COUNT(*) FILTER (WHERE status = 'pending'),
+		       COUNT(*) FILTER (WHERE status = 'failed'),
+		       COUNT(*) FILTER (WHERE status = 'cancelled')
+		FROM taken
+	`, secs, job.StaleLockError).Scan(&res.Requeued, &res.DeadLettered, &res.Cancelled)
+	})
+	if err != nil {
+		return job.StaleRequeue{}, err
+	}
+	return res, nil
 }
 
 // Admin ops endpoints
@@ -365,7 +858,8 @@ func (r *JobsRepo) ListCursor(
 		SELECT id, type, payload, status, attempts,
 		       max_attempts, run_at, locked_at, locked_by,
 		       last_error, idempotency_key, priority, user_id,
-		       created_at, updated_at
+		       created_at, updated_at,
+		       CASE WHEN status = 'pending' THEN ` + r.effectivePrioritySQL() + ` END
 		FROM jobs
 	`
 
@@ -420,7 +914,7 @@ func (r *JobsRepo) ListCursor(
 			&j.Attempts, &j.MaxAttempts,
 			&j.RunAt, &j.LockedAt, &j.LockedBy,
 			&j.LastError, &j.IdempotencyKey, &j.Priority, &j.UserID,
-			&j.CreatedAt, &j.UpdatedAt,
+			&j.CreatedAt, &j.UpdatedAt, &j.EffectivePriority,
 		); scanErr != nil {
 			return nil, nil, false, scanErr
 		}
@@ -447,6 +941,126 @@ func (r *JobsRepo) ListCursor(
 	return out, nextCursor, hasMore, nil
 }
 
+// ListScheduledCursor pages through pending jobs whose run_at falls inside
+// filter, soonest first, starting after (afterRunAt, afterID). Unlike
+// ListCursor it orders by (run_at, id) ascending, so operators read what is
+// coming next.
+func (r *JobsRepo) ListScheduledCursor(
+	ctx context.Context,
+	filter job.ScheduledFilter,
+	limit int,
+	afterRunAt time.Time,
+	afterID string,
+) (items []job.Job, nextCursor *string, hasMore bool, err error) {
+	var rows pgx.Rows
+	err = r.observe("jobs.admin.list_scheduled_cursor", func() error {
+		var qerr error
+		rows, qerr = r.pool.Query(ctx, `
+			SELECT id, type, payload, status, attempts,
+			       max_attempts, run_at, locked_at, locked_by,
+			       last_error, idempotency_key, priority, user_id,
+			       created_at, updated_at
+			FROM jobs
+			WHERE status = 'pending'
+			  AND run_at > COALESCE($1::timestamptz, NOW())
+			  AND ($2::timestamptz IS NULL OR run_at < $2)
+			  AND (run_at, id) > ($3, $4)
+			ORDER BY run_at ASC, id ASC
+			LIMIT $5
+		`, filter.RunAfter, filter.RunBefore, afterRunAt, afterID, limit+1)
+		return qerr
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer rows.Close()
+
+	items = make([]job.Job, 0, limit)
+	for rows.Next() {
+		var j job.Job
+		var st string
+		if scanErr := rows.Scan(
+			&j.ID, &j.Type, &j.Payload, &st,
+			&j.Attempts, &j.MaxAttempts,
+			&j.RunAt, &j.LockedAt, &j.LockedBy,
+			&j.LastError, &j.IdempotencyKey, &j.Priority, &j.UserID,
+			&j.CreatedAt, &j.UpdatedAt,
+		); scanErr != nil {
+			return nil, nil, false, scanErr
+		}
+		j.Status = job.Status(st)
+		items = append(items, j)
+	}
+	if rows.Err() != nil {
+		return nil, nil, false, rows.Err()
+	}
+
+	if len(items) > limit {
+		hasMore = true
+		items = items[:limit]
+		last := items[len(items)-1]
+
+		cur, encErr := utils.EncodeScheduledJobCursor(last.RunAt, last.ID)
+		if encErr != nil {
+			return nil, nil, false, encErr
+		}
+		nextCursor = &cur
+	}
+
+	return items, nextCursor, hasMore, nil
+}
+
+// ListByUserCursor pages through the jobs userID initiated, oldest first,
+// starting after (afterCreatedAt, afterID).
+func (r *JobsRepo) ListByUserCursor(
+	ctx context.Context,
+	userID string,
+	limit int,
+	afterCreatedAt time.Time,
+	afterID string,
+) ([]job.Job, error) {
+	var rows pgx.Rows
+	err := r.observe("jobs.list_by_user_cursor", func() error {
+		var qerr error
+		rows, qerr = r.pool.Query(ctx, `
+			SELECT id, type, payload, status, attempts,
+			       max_attempts, run_at, locked_at, locked_by,
+			       last_error, idempotency_key, priority, user_id,
+			       created_at, updated_at
+			FROM jobs
+			WHERE user_id = $1
+			  AND (created_at, id) > ($2, $3)
+			ORDER BY created_at ASC, id ASC
+			LIMIT $4
+		`, userID, afterCreatedAt, afterID, limit)
+		return qerr
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]job.Job, 0, limit)
+	for rows.Next() {
+		var j job.Job
+		var st string
+
+		if scanErr := rows.Scan(
+			&j.ID, &j.Type, &j.Payload, &st,
+			&j.Attempts, &j.MaxAttempts,
+			&j.RunAt, &j.LockedAt, &j.LockedBy,
+			&j.LastError, &j.IdempotencyKey, &j.Priority, &j.UserID,
+			&j.CreatedAt, &j.UpdatedAt,
+		); scanErr != nil {
+			return nil, scanErr
+		}
+		j.Status = job.Status(st)
+		out = append(out, j)
+	}
+
+	return out, rows.Err()
+}
+
 func (r *JobsRepo) Count(ctx context.Context, status *string) (int, error) {
 	op := "jobs.admin.count"
 
@@ -482,7 +1096,8 @@ func (r *JobsRepo) GetByID(ctx context.Context, id string) (job.Job, error) {
 		       attempts, max_attempts,
 		       run_at, locked_at, locked_by,
 		       last_error, idempotency_key,priority,user_id,
-		       created_at, updated_at
+		       created_at, updated_at, result,
+		       queue_wait_ms, execution_ms, progress
 		FROM jobs
 		WHERE id = $1
 	`, id).Scan(
@@ -490,7 +1105,8 @@ func (r *JobsRepo) GetByID(ctx context.Context, id string) (job.Job, error) {
 			&j.Attempts, &j.MaxAttempts,
 			&j.RunAt, &j.LockedAt, &j.LockedBy,
 			&j.LastError, &j.IdempotencyKey, &j.Priority, &j.UserID,
-			&j.CreatedAt, &j.UpdatedAt,
+			&j.CreatedAt, &j.UpdatedAt, &j.Result,
+			&j.QueueWaitMs, &j.ExecutionMs, &j.Progress,
 		)
 	})
 	if err != nil {
@@ -504,15 +1120,155 @@ func (r *JobsRepo) GetByID(ctx context.Context, id string) (job.Job, error) {
 	return j, nil
 }
 
-func (r *JobsRepo) Retry(ctx context.Context, id string) error {
+// SetResult stores the outcome a job reports, shown on the admin job detail.
+func (r *JobsRepo) SetResult(ctx context.Context, id string, result json.RawMessage) error {
+	return r.observe("jobs.set_result", func() error {
+		_, err := r.pool.Exec(ctx, `
+			UPDATE jobs
+			SET result = $2,
+			    updated_at = NOW()
+			WHERE id = $1
+		`, id, result)
+		return err
+	})
+}
+
+// progressWriteInterval is the least time between two progress writes for
+// one job; reports in between are dropped.
+const progressWriteInterval = time.Second
+
+// UpdateProgress stores p as the progress of the processing job id, at most
+// once per progressWriteInterval per job; a report inside the interval is
+// dropped without error, except the one finishing the work, so the detail
+// never stops short of the end. updated_at is left alone, as for SetTiming.
+func (r *JobsRepo) UpdateProgress(ctx context.Context, id string, p job.Progress) error {
+	now := time.Now().UTC()
+	if !r.progressDue(id, now, p.Finished()) {
+		return nil
+	}
+
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = now
+	}
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	return r.observe("jobs.update_progress", func() error {
+		_, err := r.pool.Exec(ctx, `
+			UPDATE jobs
+			SET progress = $2
+			WHERE id = $1
+			  AND status = 'processing'
+		`, id, raw)
+		return err
+	})
+}
+
+// progressDue reports whether a progress write for id may go out at now and
+// records it if so. Entries for jobs not heard from in a minute are swept on
+// the way, so finished jobs do not pile up.
+func (r *JobsRepo) progressDue(id string, now time.Time, final bool) bool {
+	r.progressMu.Lock()
+	defer r.progressMu.Unlock()
+
+	if r.progressWritten == nil {
+		r.progressWritten = make(map[string]time.Time)
+	}
+
+	last, seen := r.progressWritten[id]
+	if seen && !final && now.Sub(last) < progressWriteInterval {
+		return false
+	}
+
+	for other, at := range r.progressWritten {
+		if now.Sub(at) > time.Minute {
+			delete(r.progressWritten, other)
+		}
+	}
+	if final {
+		delete(r.progressWritten, id)
+	} else {
+		r.progressWritten[id] = now
+	}
+	return true
+}
+
+// SetTiming stores the latest attempt's queue wait and run time, shown on
+// the admin job detail. updated_at is left alone: this is bookkeeping after
+// the outcome, not a change to it.
+func (r *JobsRepo) SetTiming(ctx context.Context, id string, queueWait, execution time.Duration) error {
+	return r.observe("jobs.set_timing", func() error {
+		_, err := r.pool.Exec(ctx, `
+			UPDATE jobs
+			SET queue_wait_ms = $2,
+			    execution_ms = $3
+			WHERE id = $1
+		`, id, queueWait.Milliseconds(), execution.Milliseconds())
+		return err
+	})
+}
+
+// OversizedPayloads lists up to limit jobs whose stored payload is larger
+// than maxBytes, largest first. It only reads. Sizes are of the jsonb text
+// form, which can differ slightly from what was originally sent.
+func (r *JobsRepo) OversizedPayloads(ctx context.Context, maxBytes, limit int) ([]job.OversizedPayload, error) {
+	out := []job.OversizedPayload{}
+
+	err := r.observe("jobs.oversized_payloads", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id, type, status, octet_length(payload::text) AS size, created_at
+			FROM jobs
+			WHERE octet_length(payload::text) > $1
+			ORDER BY size DESC, id
+			LIMIT $2
+		`, maxBytes, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var o job.OversizedPayload
+			if err := rows.Scan(&o.ID, &o.Type, &o.Status, &o.Size, &o.CreatedAt); err != nil {
+				return err
+			}
+			out = append(out, o)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Retry puts a failed job back on the queue in place and marks its dead
+// letter replayed in the same statement. A job whose dead letter was already
+// replayed into a fresh job is refused with job.ErrDeadLetterReplayed, so the
+// work is not queued twice. A non-nil priority replaces the job's own.
+func (r *JobsRepo) Retry(ctx context.Context, id string, priority *int) error {
 	// check job exists + status
 	var status string
+	var replayedElsewhere bool
 
 	var err error
 	op := "jobs.admin.retry.check_status"
 
 	err = r.observe(op, func() error {
-		return r.pool.QueryRow(ctx, `SELECT status FROM jobs WHERE id = $1`, id).Scan(&status)
+		return r.pool.QueryRow(ctx, `
+		SELECT j.status,
+		       COALESCE((
+		           SELECT d.replayed_job_id <> j.id
+		           FROM dead_letters d
+		           WHERE d.job_id = j.id
+		           ORDER BY d.failed_at DESC
+		           LIMIT 1
+		       ), FALSE)
+		FROM jobs j
+		WHERE j.id = $1
+	`, id).Scan(&status, &replayedElsewhere)
 	})
 
 	if err != nil {
@@ -525,72 +1281,220 @@ func (r *JobsRepo) Retry(ctx context.Context, id string) error {
 	if status != "failed" {
 		return ErrJobNotFailed
 	}
+	if replayedElsewhere {
+		return job.ErrDeadLetterReplayed
+	}
 
 	// 2) requeue
-
+	var n int
 	requeueOp := "jobs.admin.retry.requeue"
 
 	requeueFn := func() error {
-		_, e := r.pool.Exec(ctx, `
-		UPDATE jobs
-		SET status = 'pending',
-		    run_at = NOW(),
-		    locked_at = NULL,
-		    locked_by = NULL,
-		    last_error = NULL,
-		    updated_at = NOW()
-		WHERE id = $1
-	`, id)
-		return e
+		return r.pool.QueryRow(ctx, `
+		WITH requeued AS (
+			UPDATE jobs
+			SET status = 'pending',
+			    run_at = NOW(),
+			    locked_at = NULL,
+			    locked_by = NULL,
+			    last_error = NULL,
+			    cancellation_requested = FALSE,
+			    priority = COALESCE($2, priority),
+			    updated_at = NOW()
+			WHERE id = $1 AND status = 'failed'
+			RETURNING id
+		), replayed AS (
+			UPDATE dead_letters
+			SET replayed_at = NOW(),
+			    replayed_job_id = $1
+			WHERE job_id IN (SELECT id FROM requeued)
+			  AND replayed_at IS NULL
+		)
+		SELECT COUNT(*) FROM requeued
+	`, id, priority).Scan(&n)
 	}
 
-	return r.observe(requeueOp, requeueFn)
-
+	if err := r.observe(requeueOp, requeueFn); err != nil {
+		return err
+	}
+	if n == 0 {
+		// retried or removed since the check
+		return ErrJobNotFailed
+	}
+	return nil
 }
 
-// POST /admin/jobs/reprocess-dead?limit=50
+// RetryManyFailed replays up to limit unreplayed dead letters, most recently
+// failed first, as fresh pending jobs. Each insert and the stamp on its dead
+// letter are one statement; dead letters another caller is replaying are
+// skipped. The replays continue the trace in ctx, as Create's jobs do.
+// Callers cap limit; see handlers.BulkLimits.
 func (r *JobsRepo) RetryManyFailed(ctx context.Context, limit int) (int64, error) {
-	var tag pgconn.CommandTag
+	var n int64
 	op := "jobs.admin.retry_many_failed"
-	var err error
 
 	if limit <= 0 {
-		limit = 50
+		return 0, fmt.Errorf("retry many failed: limit must be positive, got %d", limit)
 	}
 
-	if limit > 500 {
-		limit = 500
-
-	}
+	traceParent, traceState := observability.TraceParent(ctx)
 
 	fn := func() error {
-		tag, err = r.pool.Exec(ctx,
-			`
+		return r.pool.QueryRow(ctx, `
 		WITH picked AS (
-			SELECT id
-			FROM jobs
-			WHERE status = 'failed'
-			ORDER BY updated_at DESC
+			SELECT id, type, payload, max_attempts, priority, user_id,
+			       gen_random_uuid() AS new_job_id
+			FROM dead_letters
+			WHERE replayed_at IS NULL
+			ORDER BY failed_at DESC, id DESC
 			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), inserted AS (
+			INSERT INTO jobs (id, type, payload, status, attempts, max_attempts, run_at, priority, user_id, created_at, updated_at, trace_parent, trace_state)
+			SELECT new_job_id, type, payload, 'pending', 0, max_attempts, NOW(), priority, user_id, NOW(), NOW(), NULLIF($2, ''), NULLIF($3, '')
+			FROM picked
+			RETURNING id
+		), replayed AS (
+			UPDATE dead_letters d
+			SET replayed_at = NOW(),
+			    replayed_job_id = p.new_job_id
+			FROM picked p
+			WHERE d.id = p.id
+			RETURNING d.id
 		)
-		UPDATE jobs
-		SET status = 'pending',
-		    run_at = NOW(),
-		    locked_at = NULL,
-		    locked_by = NULL,
-		    last_error = NULL,
-		    updated_at = NOW()
-		WHERE id IN (SELECT id FROM picked)
-		`, limit)
+		SELECT COUNT(*) FROM replayed
+		`, limit, traceParent, traceState).Scan(&n)
+	}
 
-		return err
+	if err := r.observe(op, fn); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// PurgeTerminal deletes up to limit jobs in statuses last updated more than
+// olderThan ago, oldest first, and returns how many went; callers loop while
+// a batch comes back full. Only done, failed and cancelled jobs qualify,
+// whatever statuses says. Jobs that still own a registration export are kept
+// for exports.cleanup, which removes the file before the row. Dead letters
+// are a separate table and survive.
+func (r *JobsRepo) PurgeTerminal(ctx context.Context, olderThan time.Duration, statuses []string, limit int) (int64, error) {
+	if limit <= 0 {
+		return 0, fmt.Errorf("purge terminal: limit must be positive, got %d", limit)
+	}
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("purge terminal: olderThan must be positive, got %s", olderThan)
+	}
+	if len(statuses) == 0 {
+		return 0, fmt.Errorf("purge terminal: no statuses")
+	}
+	for _, s := range statuses {
+		if !job.Status(s).Terminal() {
+			return 0, fmt.Errorf("%w: %q", job.ErrNotPurgeable, s)
+		}
 	}
 
-	err = r.observe(op, fn)
+	var n int64
+	err := r.observe("jobs.admin.purge_terminal", func() error {
+		return r.pool.QueryRow(ctx, `
+		WITH picked AS (
+			SELECT id
+			FROM jobs
+			WHERE status = ANY($1::text[])
+			  AND status IN ('done', 'failed', 'cancelled')
+			  AND updated_at < NOW() - make_interval(secs => $2)
+			  AND NOT EXISTS (SELECT 1 FROM registration_csv_exports e WHERE e.job_id = jobs.id)
+			ORDER BY updated_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		), deleted AS (
+			DELETE FROM jobs j
+			USING picked p
+			WHERE j.id = p.id
+			RETURNING j.id
+		)
+		SELECT COUNT(*) FROM deleted
+		`, statuses, olderThan.Seconds(), limit).Scan(&n)
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	return tag.RowsAffected(), nil
+	return n, nil
+}
+
+// QueueStats counts the due pending backlog and the age of its oldest job,
+// for the API's enqueue guard. Callers are expected to cache the answer; the
+// count is over every due pending row.
+func (r *JobsRepo) QueueStats(ctx context.Context) (job.QueueStats, error) {
+	var (
+		stats      job.QueueStats
+		ageSeconds float64
+	)
+	err := r.observe("jobs.queue_stats", func() error {
+		return r.pool.QueryRow(ctx, `
+		SELECT COUNT(*),
+		       COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(run_at)), 0)::float8
+		FROM jobs
+		WHERE status = 'pending'
+		  AND run_at <= NOW()
+		`).Scan(&stats.DuePending, &ageSeconds)
+	})
+	if err != nil {
+		return job.QueueStats{}, err
+	}
+
+	stats.OldestPendingAge = time.Duration(ageSeconds * float64(time.Second))
+	return stats, nil
+}
+
+// Stats counts jobs per status, every status present even at zero, and the
+// age of the oldest due pending job, in one pass over the jobs table. It is
+// read by the worker's health endpoints, which cache it.
+func (r *JobsRepo) Stats(ctx context.Context) (job.Stats, error) {
+	stats := job.Stats{Counts: map[job.Status]int64{
+		job.StatusPending:    0,
+		job.StatusProcessing: 0,
+		job.StatusDone:       0,
+		job.StatusFailed:     0,
+		job.StatusCancelled:  0,
+	}}
+
+	var oldestSeconds float64
+	err := r.observe("jobs.stats", func() error {
+		rows, err := r.pool.Query(ctx, `
+		SELECT status,
+		       COUNT(*),
+		       COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(run_at) FILTER (WHERE run_at <= NOW())), 0)::float8
+		FROM jobs
+		GROUP BY status
+		`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				status string
+				n      int64
+				age    float64
+			)
+			if err := rows.Scan(&status, &n, &age); err != nil {
+				return err
+			}
+			stats.Counts[job.Status(status)] = n
+			if job.Status(status) == job.StatusPending {
+				oldestSeconds = age
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return job.Stats{}, err
+	}
 
+	stats.OldestPendingAge = time.Duration(oldestSeconds * float64(time.Second))
+	return stats, nil
 }
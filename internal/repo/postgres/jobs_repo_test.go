@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// paddedPayload is a valid JSON object of exactly n bytes.
+func paddedPayload(n int) []byte {
+	const wrap = len(`{"pad":""}`)
+	return []byte(`{"pad":"` + strings.Repeat("x", n-wrap) + `"}`)
+}
+
+func nestedPayload(depth int) []byte {
+	return []byte(strings.Repeat(`{"a":`, depth) + "1" + strings.Repeat("}", depth))
+}
+
+func TestCheckPayload_SizeBoundary(t *testing.T) {
+	prom := observability.NewProm(prometheus.NewRegistry())
+	repo := NewJobsRepo(nil, prom).WithPayloadLimits(job.PayloadLimits{MaxBytes: 1024, MaxDepth: 32})
+
+	if err := repo.checkPayload(job.CreateRequest{Type: "t", Payload: paddedPayload(1024)}); err != nil {
+		t.Fatalf("payload at the limit should pass, got %v", err)
+	}
+
+	err := repo.checkPayload(job.CreateRequest{Type: "t", Payload: paddedPayload(1025)})
+	var limitErr *job.PayloadLimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, job.ErrPayloadTooLarge) {
+		t.Fatalf("expected a payload limit error, got %v", err)
+	}
+	if limitErr.Size != 1025 || limitErr.MaxBytes != 1024 || limitErr.TooDeep() {
+		t.Fatalf("unexpected measurement: %+v", limitErr)
+	}
+	if got := testutil.ToFloat64(prom.JobEnqueueRejectedTotal.WithLabelValues("t", "too_large")); got != 1 {
+		t.Fatalf("expected one too_large rejection counted, got %v", got)
+	}
+}
+
+func TestCheckPayload_DepthBoundary(t *testing.T) {
+	prom := observability.NewProm(prometheus.NewRegistry())
+	repo := NewJobsRepo(nil, prom).WithPayloadLimits(job.PayloadLimits{MaxBytes: 1024, MaxDepth: 4})
+
+	if err := repo.checkPayload(job.CreateRequest{Type: "t", Payload: nestedPayload(4)}); err != nil {
+		t.Fatalf("payload at the depth limit should pass, got %v", err)
+	}
+	// brackets inside strings are not nesting
+	if err := repo.checkPayload(job.CreateRequest{Type: "t", Payload: []byte(`{"s":"[[[[[[{{{{\"]]"}`)}); err != nil {
+		t.Fatalf("brackets in strings should not count, got %v", err)
+	}
+
+	err := repo.checkPayload(job.CreateRequest{Type: "t", Payload: nestedPayload(5)})
+	var limitErr *job.PayloadLimitError
+	if !errors.As(err, &limitErr) || !limitErr.TooDeep() || limitErr.Depth != 5 || limitErr.MaxDepth != 4 {
+		t.Fatalf("expected a depth violation of 5 over 4, got %v", err)
+	}
+	if got := testutil.ToFloat64(prom.JobEnqueueRejectedTotal.WithLabelValues("t", "too_deep")); got != 1 {
+		t.Fatalf("expected one too_deep rejection counted, got %v", got)
+	}
+}
+
+func TestCreate_RejectsOversizedPayloadBeforeTheDatabase(t *testing.T) {
+	// a nil pool would panic if Create got as far as the insert
+	repo := NewJobsRepo(nil, nil).WithPayloadLimits(job.PayloadLimits{MaxBytes: 64})
+	req := job.CreateRequest{Type: "t", Payload: paddedPayload(65)}
+
+	if _, err := repo.Create(context.Background(), req); !errors.Is(err, job.ErrPayloadTooLarge) {
+		t.Fatalf("Create: expected ErrPayloadTooLarge, got %v", err)
+	}
+	if _, err := repo.CreateTx(context.Background(), nil, req); !errors.Is(err, job.ErrPayloadTooLarge) {
+		t.Fatalf("CreateTx: expected ErrPayloadTooLarge, got %v", err)
+	}
+	if _, err := repo.CreateManyTx(context.Background(), nil, []job.CreateRequest{{Type: "t", Payload: paddedPayload(10)}, req}); !errors.Is(err, job.ErrPayloadTooLarge) {
+		t.Fatalf("CreateManyTx: expected ErrPayloadTooLarge, got %v", err)
+	}
+}
+
+func TestUpdateProgress_RateLimitedPerJob(t *testing.T) {
+	repo := NewJobsRepo(nil, nil)
+	start := time.Date(2026, 3, 27, 9, 0, 0, 0, time.UTC)
+
+	if !repo.progressDue("job-a", start, false) {
+		t.Fatalf("expected the first report written")
+	}
+	if repo.progressDue("job-a", start.Add(500*time.Millisecond), false) {
+		t.Fatalf("expected a second report within the interval dropped")
+	}
+	if !repo.progressDue("job-b", start.Add(500*time.Millisecond), false) {
+		t.Fatalf("expected another job's report written")
+	}
+	if !repo.progressDue("job-a", start.Add(600*time.Millisecond), true) {
+		t.Fatalf("expected the finishing report written inside the interval")
+	}
+	if !repo.progressDue("job-a", start.Add(time.Second), false) {
+		t.Fatalf("expected a report after the interval written")
+	}
+
+	repo.progressDue("job-c", start.Add(2*time.Minute), false)
+	if _, ok := repo.progressWritten["job-b"]; ok {
+		t.Fatalf("expected jobs not heard from in a minute swept")
+	}
+}
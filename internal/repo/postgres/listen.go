@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geocoder89/eventhub/internal/eventchanges"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListenEventChanges LISTENs on eventchanges.Channel.
+func ListenEventChanges(pool *pgxpool.Pool) eventchanges.ListenFunc {
+	return listenChannel(pool, eventchanges.Channel)
+}
+
+// ListenNewJobs LISTENs on jobs.NewJobsChannel, passing each claimable job's type.
+func ListenNewJobs(pool *pgxpool.Pool) func(ctx context.Context, notify func(jobType string)) error {
+	return listenChannel(pool, jobs.NewJobsChannel)
+}
+
+// listenChannel LISTENs on channel over a connection taken out of the pool for
+// good; it is closed rather than returned, so no pooled connection is left
+// subscribed. It blocks until ctx is done or the connection fails.
+func listenChannel(pool *pgxpool.Pool, channel string) func(ctx context.Context, notify func(payload string)) error {
+	return func(ctx context.Context, notify func(payload string)) error {
+		if pool == nil {
+			return errors.New(channel + ": no database pool")
+		}
+
+		pc, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		conn := pc.Hijack()
+		defer func() {
+			_ = conn.Close(context.Background())
+		}()
+
+		if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return err
+		}
+
+		for {
+			n, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			notify(n.Payload)
+		}
+	}
+}
@@ -18,14 +18,26 @@ func NewNotificationsDeliveriesRepo(pool *pgxpool.Pool) *NotificationsDeliveries
 	return &NotificationsDeliveriesRepo{pool: pool}
 }
 
+const kindRegistrationConfirmation = "registration.confirmation"
+
 func (r *NotificationsDeliveriesRepo) TryStartRegistration(
 	ctx context.Context,
 	jobID string,
 	registrationID string,
 	recipient string,
 ) error {
-	kind := "registration.confirmation"
+	return r.TryStartRegistrationDelivery(ctx, kindRegistrationConfirmation, jobID, registrationID, recipient)
+}
 
+// TryStartRegistrationDelivery is the send-once gate for any notification
+// kind sent once per registration.
+func (r *NotificationsDeliveriesRepo) TryStartRegistrationDelivery(
+	ctx context.Context,
+	kind string,
+	jobID string,
+	registrationID string,
+	recipient string,
+) error {
 	// 1) Insert if missing
 	_, err := r.pool.Exec(ctx, `
 		INSERT INTO notification_deliveries (kind, registration_id, job_id, recipient, status, created_at, updated_at)
@@ -39,7 +51,8 @@ func (r *NotificationsDeliveriesRepo) TryStartRegistration(
 		return err
 	}
 
-	// 2) Row exists. If it was failed, "claim" it for retry by switching back to sending.
+	// 2) Row exists. If it was failed, or skipped for a quarantine since
+	// lifted, "claim" it for retry by switching back to sending.
 	// This is atomic: only one worker can flip failed -> sending.
 	tag, uErr := r.pool.Exec(ctx, `
 		UPDATE notification_deliveries
@@ -48,7 +61,7 @@ func (r *NotificationsDeliveriesRepo) TryStartRegistration(
 		    recipient = $4,
 		    last_error = NULL,
 		    updated_at = NOW()
-		WHERE kind = $1 AND registration_id = $2 AND status = 'failed'
+		WHERE kind = $1 AND registration_id = $2 AND status IN ('failed', 'skipped_quarantined')
 	`, kind, registrationID, jobID, recipient)
 
 	if uErr != nil {
@@ -89,8 +102,15 @@ func (r *NotificationsDeliveriesRepo) MarkRegistrationConfirmationSent(
 	registrationID string,
 	providerMessageID *string,
 ) error {
-	kind := "registration.confirmation"
+	return r.MarkRegistrationDeliverySent(ctx, kindRegistrationConfirmation, registrationID, providerMessageID)
+}
 
+func (r *NotificationsDeliveriesRepo) MarkRegistrationDeliverySent(
+	ctx context.Context,
+	kind string,
+	registrationID string,
+	providerMessageID *string,
+) error {
 	_, err := r.pool.Exec(ctx, `
 		UPDATE notification_deliveries
 		SET status = 'sent',
@@ -109,8 +129,15 @@ func (r *NotificationsDeliveriesRepo) MarkRegistrationConfirmationFailed(
 	registrationID string,
 	errMsg string,
 ) error {
-	kind := "registration.confirmation"
+	return r.MarkRegistrationDeliveryFailed(ctx, kindRegistrationConfirmation, registrationID, errMsg)
+}
 
+func (r *NotificationsDeliveriesRepo) MarkRegistrationDeliveryFailed(
+	ctx context.Context,
+	kind string,
+	registrationID string,
+	errMsg string,
+) error {
 	_, err := r.pool.Exec(ctx, `
 		UPDATE notification_deliveries
 		SET status = 'failed',
@@ -121,3 +148,129 @@ func (r *NotificationsDeliveriesRepo) MarkRegistrationConfirmationFailed(
 
 	return err
 }
+
+func (r *NotificationsDeliveriesRepo) MarkRegistrationConfirmationSkipped(
+	ctx context.Context,
+	registrationID string,
+	reason string,
+) error {
+	return r.MarkRegistrationDeliverySkipped(ctx, kindRegistrationConfirmation, registrationID, reason)
+}
+
+// MarkRegistrationDeliverySkipped records a delivery not attempted because
+// its recipient is quarantined; a later job may still claim it.
+func (r *NotificationsDeliveriesRepo) MarkRegistrationDeliverySkipped(
+	ctx context.Context,
+	kind string,
+	registrationID string,
+	reason string,
+) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = $3,
+		    last_error = $4,
+		    updated_at = NOW()
+		WHERE kind = $1 AND registration_id = $2
+	`, kind, registrationID, notificationsdelivery.StatusSkippedQuarantined, reason)
+
+	return err
+}
+
+// TryStartKeyed is the send-once gate for deliveries that are not tied to a
+// registration: one delivery per (kind, dedupeKey). It returns
+// ErrAlreadySent or ErrInProgress like TryStartRegistration.
+func (r *NotificationsDeliveriesRepo) TryStartKeyed(
+	ctx context.Context,
+	kind string,
+	dedupeKey string,
+	jobID string,
+	recipient string,
+) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_deliveries (kind, dedupe_key, job_id, recipient, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'sending', NOW(), NOW())
+	`, kind, dedupeKey, jobID, recipient)
+
+	if err == nil {
+		return nil
+	}
+	if !IsUniqueViolation(err) {
+		return err
+	}
+
+	// only one worker can flip failed -> sending
+	tag, uErr := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = 'sending',
+		    job_id = $3,
+		    recipient = $4,
+		    last_error = NULL,
+		    updated_at = NOW()
+		WHERE kind = $1 AND dedupe_key = $2 AND status = 'failed'
+	`, kind, dedupeKey, jobID, recipient)
+
+	if uErr != nil {
+		return uErr
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	var status string
+	var sentAt *time.Time
+
+	qErr := r.pool.QueryRow(ctx, `
+		SELECT status, sent_at
+		FROM notification_deliveries
+		WHERE kind = $1 AND dedupe_key = $2
+	`, kind, dedupeKey).Scan(&status, &sentAt)
+
+	if qErr != nil {
+		if errors.Is(qErr, pgx.ErrNoRows) {
+			return nil
+		}
+		return qErr
+	}
+
+	if sentAt != nil || status == "sent" {
+		return notificationsdelivery.ErrAlreadySent
+	}
+
+	return notificationsdelivery.ErrInProgress
+}
+
+func (r *NotificationsDeliveriesRepo) MarkKeyedSent(
+	ctx context.Context,
+	kind string,
+	dedupeKey string,
+	providerMessageID *string,
+) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = 'sent',
+		    sent_at = NOW(),
+		    provider_message_id = $3,
+		    last_error = NULL,
+		    updated_at = NOW()
+		WHERE kind = $1 AND dedupe_key = $2
+	`, kind, dedupeKey, providerMessageID)
+
+	return err
+}
+
+func (r *NotificationsDeliveriesRepo) MarkKeyedFailed(
+	ctx context.Context,
+	kind string,
+	dedupeKey string,
+	errMsg string,
+) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = 'failed',
+		    last_error = $3,
+		    updated_at = NOW()
+		WHERE kind = $1 AND dedupe_key = $2
+	`, kind, dedupeKey, errMsg)
+
+	return err
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/privacy"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PrivacyRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewPrivacyRepo(pool *pgxpool.Pool, prom *observability.Prom) *PrivacyRepo {
+	return &PrivacyRepo{pool: pool, prom: prom}
+}
+
+func (r *PrivacyRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// EraseByEmail removes the user with req.Email, their registrations (by
+// email or account) and refresh tokens, and redacts the address from
+// notification deliveries, job payloads and dead letters, which are kept. It
+// all happens in one transaction together with the privacy_audit row.
+func (r *PrivacyRepo) EraseByEmail(ctx context.Context, req privacy.ErasureRequest) (summary privacy.ErasureSummary, err error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			summary = privacy.ErasureSummary{}
+		}
+	}()
+
+	var userIDs []string
+	err = r.observe("privacy.erase.users_lookup", func() error {
+		rows, e := tx.Query(ctx, `SELECT id FROM users WHERE LOWER(email) = LOWER($1) FOR UPDATE`, req.Email)
+		if e != nil {
+			return e
+		}
+		userIDs, e = pgx.CollectRows(rows, pgx.RowTo[string])
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	// confirmed seats go back to their events; the waitlist is not promoted
+	// here, the next cancellation or recount picks that up
+	err = r.observe("privacy.erase.registrations", func() error {
+		tag, e := tx.Exec(ctx, `
+			WITH deleted AS (
+				DELETE FROM registrations
+				WHERE LOWER(email) = LOWER($1) OR user_id = ANY($2::uuid[])
+				RETURNING event_id, status, quantity
+			), seats AS (
+				SELECT event_id, SUM(quantity) AS quantity
+				FROM deleted
+				WHERE status = 'confirmed'
+				GROUP BY event_id
+			), adjusted AS (
+				UPDATE events e
+				SET registered_count = GREATEST(e.registered_count - s.quantity, 0)
+				FROM seats s
+				WHERE e.id = s.event_id
+			)
+			SELECT 1 FROM deleted
+		`, req.Email, userIDs)
+		summary.Registrations = tag.RowsAffected()
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	err = r.observe("privacy.erase.notification_deliveries", func() error {
+		tag, e := tx.Exec(ctx, `
+			UPDATE notification_deliveries
+			SET recipient = $2, updated_at = NOW()
+			WHERE LOWER(recipient) = LOWER($1)
+		`, req.Email, privacy.Redacted)
+		summary.NotificationDeliveries = tag.RowsAffected()
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	// match the address as a whole JSON string so "ann@x.io" leaves "joann@x.io" alone
+	quoted := `"` + regexp.QuoteMeta(req.Email) + `"`
+	redacted, _ := json.Marshal(privacy.Redacted)
+	err = r.observe("privacy.erase.jobs", func() error {
+		tag, e := tx.Exec(ctx, `
+			UPDATE jobs
+			SET payload = regexp_replace(payload::text, $1, $2, 'gi')::jsonb,
+			    last_error = regexp_replace(last_error, $3, $4, 'gi'),
+			    user_id = CASE WHEN user_id = ANY($5::uuid[]) THEN NULL ELSE user_id END,
+			    updated_at = NOW()
+			WHERE payload::text ~* $1 OR user_id = ANY($5::uuid[])
+		`, quoted, string(redacted), regexp.QuoteMeta(req.Email), privacy.Redacted, userIDs)
+		summary.Jobs = tag.RowsAffected()
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	// dead letters are copies of jobs and outlive them
+	err = r.observe("privacy.erase.dead_letters", func() error {
+		tag, e := tx.Exec(ctx, `
+			UPDATE dead_letters
+			SET payload = regexp_replace(payload::text, $1, $2, 'gi')::jsonb,
+			    final_error = regexp_replace(final_error, $3, $4, 'gi'),
+			    user_id = CASE WHEN user_id = ANY($5::uuid[]) THEN NULL ELSE user_id END
+			WHERE payload::text ~* $1 OR final_error ~* $3 OR user_id = ANY($5::uuid[])
+		`, quoted, string(redacted), regexp.QuoteMeta(req.Email), privacy.Redacted, userIDs)
+		summary.DeadLetters = tag.RowsAffected()
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	err = r.observe("privacy.erase.refresh_tokens", func() error {
+		tag, e := tx.Exec(ctx, `DELETE FROM refresh_tokens WHERE user_id = ANY($1::uuid[])`, userIDs)
+		summary.RefreshTokens = tag.RowsAffected()
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	err = r.observe("privacy.erase.users", func() error {
+		tag, e := tx.Exec(ctx, `DELETE FROM users WHERE id = ANY($1::uuid[])`, userIDs)
+		summary.Users = tag.RowsAffected()
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	affected, err := json.Marshal(summary)
+	if err != nil {
+		return
+	}
+	err = r.observe("privacy.erase.audit", func() error {
+		_, e := tx.Exec(ctx, `
+			INSERT INTO privacy_audit (id, action, subject_email_sha256, requested_by_user_id, requested_by_email, request_id, affected, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, uuid.NewString(), privacy.ActionEraseByEmail, privacy.EmailDigest(req.Email),
+			nullableString(req.RequestedByUserID), nullableString(req.RequestedByEmail), nullableString(req.RequestID),
+			affected, time.Now().UTC())
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+)
+
+// SetPaused pauses or resumes claiming for every worker. by is the admin
+// flipping it; pausing an already paused queue keeps the original time and
+// admin.
+func (r *JobsRepo) SetPaused(ctx context.Context, paused bool, by string) (job.QueueState, error) {
+	var st job.QueueState
+	err := r.observe("jobs.queue.set_paused", func() error {
+		return r.pool.QueryRow(ctx, `
+			INSERT INTO queue_state (id, paused, paused_at, paused_by, updated_at)
+			VALUES (TRUE, $1, CASE WHEN $1 THEN NOW() END, CASE WHEN $1 THEN NULLIF($2, '')::uuid END, NOW())
+			ON CONFLICT (id) DO UPDATE
+			SET paused = EXCLUDED.paused,
+			    paused_at = CASE WHEN queue_state.paused AND EXCLUDED.paused THEN queue_state.paused_at ELSE EXCLUDED.paused_at END,
+			    paused_by = CASE WHEN queue_state.paused AND EXCLUDED.paused THEN queue_state.paused_by ELSE EXCLUDED.paused_by END,
+			    updated_at = NOW()
+			RETURNING paused, paused_at, paused_by::text, updated_at
+		`, paused, by).Scan(&st.Paused, &st.PausedAt, &st.PausedBy, &st.UpdatedAt)
+	})
+	if err != nil {
+		return job.QueueState{}, err
+	}
+
+	st.PausedTypes, err = r.PausedTypes(ctx)
+	if err != nil {
+		return job.QueueState{}, err
+	}
+	return st, nil
+}
+
+// QueueState reads the switch; a missing row reads as running.
+func (r *JobsRepo) QueueState(ctx context.Context) (job.QueueState, error) {
+	var st job.QueueState
+	err := r.observe("jobs.queue.state", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT
+				COALESCE(bool_or(paused), FALSE),
+				max(paused_at),
+				max(paused_by::text),
+				COALESCE(max(updated_at), NOW())
+			FROM queue_state
+		`).Scan(&st.Paused, &st.PausedAt, &st.PausedBy, &st.UpdatedAt)
+	})
+	if err != nil {
+		return job.QueueState{}, err
+	}
+
+	st.PausedTypes, err = r.PausedTypes(ctx)
+	if err != nil {
+		return job.QueueState{}, err
+	}
+	return st, nil
+}
+
+// IsPaused reports whether workers should stop claiming.
+func (r *JobsRepo) IsPaused(ctx context.Context) (bool, error) {
+	var paused bool
+	err := r.observe("jobs.queue.is_paused", func() error {
+		return r.pool.QueryRow(ctx, `SELECT COALESCE(bool_or(paused), FALSE) FROM queue_state`).Scan(&paused)
+	})
+	return paused, err
+}
+
+// PauseType stops workers claiming jobs of jobType. by is the admin pausing
+// it; pausing a paused type keeps the original time and admin.
+func (r *JobsRepo) PauseType(ctx context.Context, jobType, by string) (job.PausedType, error) {
+	var p job.PausedType
+	err := r.observe("jobs.queue.pause_type", func() error {
+		return r.pool.QueryRow(ctx, `
+			INSERT INTO paused_job_types (type, paused_at, paused_by)
+			VALUES ($1, NOW(), NULLIF($2, '')::uuid)
+			ON CONFLICT (type) DO UPDATE
+			SET type = EXCLUDED.type
+			RETURNING type, paused_at, paused_by::text
+		`, jobType, by).Scan(&p.Type, &p.PausedAt, &p.PausedBy)
+	})
+	if err != nil {
+		return job.PausedType{}, err
+	}
+	return p, nil
+}
+
+// ResumeType lets workers claim jobs of jobType again, reporting whether it
+// was paused.
+func (r *JobsRepo) ResumeType(ctx context.Context, jobType string) (bool, error) {
+	var resumed bool
+	err := r.observe("jobs.queue.resume_type", func() error {
+		tag, err := r.pool.Exec(ctx, `DELETE FROM paused_job_types WHERE type = $1`, jobType)
+		resumed = tag.RowsAffected() > 0
+		return err
+	})
+	return resumed, err
+}
+
+// PausedTypes lists the paused job types by name.
+func (r *JobsRepo) PausedTypes(ctx context.Context) ([]job.PausedType, error) {
+	out := []job.PausedType{}
+	err := r.observe("jobs.queue.paused_types", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT type, paused_at, paused_by::text
+			FROM paused_job_types
+			ORDER BY type
+		`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var p job.PausedType
+			if err := rows.Scan(&p.Type, &p.PausedAt, &p.PausedBy); err != nil {
+				return err
+			}
+			out = append(out, p)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+
+	notificationsdelivery "github.com/geocoder89/eventhub/internal/domain/notifications_delivery"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RecipientQuarantinesRepo counts permanent send failures per recipient and
+// keeps the recipients quarantined for them.
+type RecipientQuarantinesRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewRecipientQuarantinesRepo(pool *pgxpool.Pool, prom *observability.Prom) *RecipientQuarantinesRepo {
+	return &RecipientQuarantinesRepo{pool: pool, prom: prom}
+}
+
+func (r *RecipientQuarantinesRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// RecordPermanentFailure counts one permanent failure for email, starting a
+// new window when the last one has run out, and quarantines email once the
+// window holds policy.Threshold of them. It reports whether this call is the
+// one that quarantined it.
+func (r *RecipientQuarantinesRepo) RecordPermanentFailure(ctx context.Context, email, errMsg string, policy notificationsdelivery.QuarantinePolicy) (bool, error) {
+	email = notificationsdelivery.NormalizeRecipient(email)
+	var quarantined bool
+
+	err := r.observe("recipient_quarantines.record_failure", func() error {
+		return r.pool.QueryRow(ctx, `
+			INSERT INTO recipient_quarantines AS q (email, failures, window_started_at, last_error, last_failed_at, quarantined_at)
+			VALUES ($1, 1, NOW(), $2, NOW(), CASE WHEN $3::int <= 1 THEN NOW() END)
+			ON CONFLICT (email) DO UPDATE
+			SET failures = CASE
+			        WHEN q.window_started_at < NOW() - make_interval(secs => $4::float8) THEN 1
+			        ELSE q.failures + 1
+			    END,
+			    window_started_at = CASE
+			        WHEN q.window_started_at < NOW() - make_interval(secs => $4::float8) THEN NOW()
+			        ELSE q.window_started_at
+			    END,
+			    last_error = EXCLUDED.last_error,
+			    last_failed_at = NOW(),
+			    quarantined_at = COALESCE(q.quarantined_at, CASE
+			        WHEN q.window_started_at >= NOW() - make_interval(secs => $4::float8)
+			         AND q.failures + 1 >= $3::int THEN NOW()
+			    END)
+			RETURNING quarantined_at IS NOT NULL AND quarantined_at = NOW()
+		`, email, errMsg, policy.Threshold, policy.Window.Seconds()).Scan(&quarantined)
+	})
+	if err != nil {
+		return false, err
+	}
+	return quarantined, nil
+}
+
+// IsQuarantined reports whether deliveries to email are quarantined.
+func (r *RecipientQuarantinesRepo) IsQuarantined(ctx context.Context, email string) (bool, error) {
+	var quarantined bool
+
+	err := r.observe("recipient_quarantines.is_quarantined", func() error {
+		return r.pool.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM recipient_quarantines
+				WHERE email = $1 AND quarantined_at IS NOT NULL
+			)
+		`, notificationsdelivery.NormalizeRecipient(email)).Scan(&quarantined)
+	})
+	if err != nil {
+		return false, err
+	}
+	return quarantined, nil
+}
+
+// ListQuarantined returns every quarantined recipient, most recent first.
+func (r *RecipientQuarantinesRepo) ListQuarantined(ctx context.Context) ([]notificationsdelivery.Quarantine, error) {
+	out := []notificationsdelivery.Quarantine{}
+
+	err := r.observe("recipient_quarantines.list", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT email, failures, COALESCE(last_error, ''), last_failed_at, quarantined_at
+			FROM recipient_quarantines
+			WHERE quarantined_at IS NOT NULL
+			ORDER BY quarantined_at DESC, email
+		`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var q notificationsdelivery.Quarantine
+			if err := rows.Scan(&q.Email, &q.Failures, &q.LastError, &q.LastFailedAt, &q.QuarantinedAt); err != nil {
+				return err
+			}
+			out = append(out, q)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Lift ends email's quarantine and forgets its failures, so it takes a full
+// threshold of new ones to quarantine it again. It returns
+// ErrQuarantineNotFound when email is not quarantined.
+func (r *RecipientQuarantinesRepo) Lift(ctx context.Context, email string) error {
+	return r.observe("recipient_quarantines.lift", func() error {
+		tag, err := r.pool.Exec(ctx, `
+			DELETE FROM recipient_quarantines
+			WHERE email = $1 AND quarantined_at IS NOT NULL
+		`, notificationsdelivery.NormalizeRecipient(email))
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notificationsdelivery.ErrQuarantineNotFound
+		}
+		return nil
+	})
+}
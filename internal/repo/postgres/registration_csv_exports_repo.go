@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/geocoder89/eventhub/internal/domain/registrationexport"
 	"github.com/jackc/pgx/v5"
@@ -20,9 +21,9 @@ func NewRegistrationCSVExportsRepo(pool *pgxpool.Pool) *RegistrationCSVExportsRe
 func (r *RegistrationCSVExportsRepo) Save(ctx context.Context, export registrationexport.CSVExport) error {
 	_, err := r.pool.Exec(ctx, `
 		INSERT INTO registration_csv_exports (
-			job_id, event_id, requested_by, file_name, content_type, row_count, csv_data, created_at
+			job_id, event_id, requested_by, file_name, content_type, row_count, storage_path, csv_data, created_at, keep_until
 		) VALUES (
-			$1,$2,$3,$4,$5,$6,$7,$8
+			$1,$2,$3,$4,$5,$6,NULLIF($7, ''),$8,$9,$10
 		)
 	`,
 		export.JobID,
@@ -31,8 +32,10 @@ func (r *RegistrationCSVExportsRepo) Save(ctx context.Context, export registrati
 		export.FileName,
 		export.ContentType,
 		export.RowCount,
+		export.StoragePath,
 		export.Data,
 		export.CreatedAt,
+		export.KeepUntil,
 	)
 	return err
 }
@@ -41,7 +44,8 @@ func (r *RegistrationCSVExportsRepo) GetByJobID(ctx context.Context, jobID strin
 	var out registrationexport.CSVExport
 
 	err := r.pool.QueryRow(ctx, `
-		SELECT job_id, event_id, requested_by, file_name, content_type, row_count, csv_data, created_at
+		SELECT job_id, event_id, requested_by, file_name, content_type, row_count,
+		       COALESCE(storage_path, ''), COALESCE(csv_data, ''::bytea), created_at, keep_until
 		FROM registration_csv_exports
 		WHERE job_id = $1
 	`, jobID).Scan(
@@ -51,8 +55,10 @@ func (r *RegistrationCSVExportsRepo) GetByJobID(ctx context.Context, jobID strin
 		&out.FileName,
 		&out.ContentType,
 		&out.RowCount,
+		&out.StoragePath,
 		&out.Data,
 		&out.CreatedAt,
+		&out.KeepUntil,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -64,6 +70,82 @@ func (r *RegistrationCSVExportsRepo) GetByJobID(ctx context.Context, jobID strin
 	return out, nil
 }
 
+// deleteAfterSQL mirrors CSVExport.DeleteAfter; $1 is the retention in seconds.
+const deleteAfterSQL = `GREATEST(created_at + ($1 * INTERVAL '1 second'), COALESCE(keep_until, '-infinity'))`
+
+// List returns export metadata, without file contents, soonest deletion first.
+func (r *RegistrationCSVExportsRepo) List(ctx context.Context, filter registrationexport.ListFilter, retention time.Duration) ([]registrationexport.CSVExport, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT job_id, event_id, requested_by, file_name, content_type, row_count,
+		       COALESCE(storage_path, ''), created_at, keep_until
+		FROM registration_csv_exports
+		WHERE ($2::uuid IS NULL OR event_id = $2)
+		  AND ($3::timestamptz IS NULL OR `+deleteAfterSQL+` <= $3)
+		ORDER BY `+deleteAfterSQL+`, job_id
+		LIMIT $4
+	`, int64(retention.Seconds()), nullableString(filter.EventID), filter.DueBy, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanExportMetadata(rows)
+}
+
+// ListExpired returns up to limit exports past their DeleteAfter at now,
+// oldest first, for the cleanup job.
+func (r *RegistrationCSVExportsRepo) ListExpired(ctx context.Context, now time.Time, retention time.Duration, limit int) ([]registrationexport.CSVExport, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT job_id, event_id, requested_by, file_name, content_type, row_count,
+		       COALESCE(storage_path, ''), created_at, keep_until
+		FROM registration_csv_exports
+		WHERE `+deleteAfterSQL+` <= $2
+		ORDER BY created_at, job_id
+		LIMIT $3
+	`, int64(retention.Seconds()), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanExportMetadata(rows)
+}
+
+// DeleteByJobIDs removes export rows; it commits on its own so each cleanup
+// batch is durable before the next starts.
+func (r *RegistrationCSVExportsRepo) DeleteByJobIDs(ctx context.Context, jobIDs []string) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM registration_csv_exports
+		WHERE job_id = ANY($1::uuid[])
+	`, jobIDs)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanExportMetadata(rows pgx.Rows) ([]registrationexport.CSVExport, error) {
+	var out []registrationexport.CSVExport
+	for rows.Next() {
+		var e registrationexport.CSVExport
+		if err := rows.Scan(
+			&e.JobID,
+			&e.EventID,
+			&e.RequestedBy,
+			&e.FileName,
+			&e.ContentType,
+			&e.RowCount,
+			&e.StoragePath,
+			&e.CreatedAt,
+			&e.KeepUntil,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
 func nullableStringPtr(s *string) interface{} {
 	if s == nil || *s == "" {
 		return nil
@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/jobs"
+	"github.com/jackc/pgx/v5"
+)
+
+// emailChangedError is left on confirmation jobs and deliveries superseded
+// by an email change.
+const emailChangedError = "registration email changed"
+
+// ChangeEmail moves a registration to newEmail and makes sure the corrected
+// address is the only one mailed from now on, all in one transaction:
+//
+//   - confirmation jobs still queued for the old address are cancelled (a
+//     running one is asked to stop) and the confirmation delivery row is
+//     reset so it can be sent again;
+//   - a pending reminder is rewritten to the new address;
+//   - a confirmed registration gets a fresh confirmation job, returned as
+//     confirmation. Waitlisted ones are confirmed on promotion, which reads
+//     the new address, so confirmation is the zero Job for them.
+//
+// The per-event uniqueness is checked against newEmail under the event row
+// lock, giving registration.ErrAlreadyRegistered on a clash. Cancelled
+// registrations give registration.ErrAlreadyCancelled and an address equal to
+// the current one registration.ErrEmailUnchanged.
+func (repo *RegistrationRepo) ChangeEmail(ctx context.Context, eventID, registrationID, newEmail string) (reg registration.Registration, confirmation job.Job, err error) {
+	newEmail = registration.NormalizeEmail(newEmail)
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// same lock CreateTx takes, so a sign-up with newEmail cannot slip in
+	// between the duplicate check and the update
+	var allowedDomains []string
+	var p jobs.RegistrationConfirmationPayload
+	err = repo.observe("registrations.change_email.lock_event", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT allowed_email_domains,
+			       COALESCE(branding_reply_to, ''), COALESCE(branding_display_name, ''), COALESCE(branding_logo_url, '')
+			FROM events
+			WHERE id = $1
+			  AND deleted_at IS NULL
+			FOR UPDATE
+		`, eventID).Scan(&allowedDomains, &p.ReplyTo, &p.OrganizerName, &p.LogoURL)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = event.ErrNotFound
+		}
+		return
+	}
+
+	err = repo.observe("registrations.change_email.lock_registration", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT id, event_id, COALESCE(user_id::text, ''), name, email, status, quantity, waitlist_position, check_in_token, checked_in_at, cancelled_at, created_at, updated_at
+			FROM registrations
+			WHERE id = $1 AND event_id = $2
+			FOR UPDATE
+		`, registrationID, eventID).Scan(&reg.ID, &reg.EventID, &reg.UserID, &reg.Name, &reg.Email, &reg.Status, &reg.Quantity, &reg.WaitlistPosition, &reg.CheckInToken, &reg.CheckedInAt, &reg.CancelledAt, &reg.CreatedAt, &reg.UpdatedAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = registration.ErrNotFound
+		}
+		return
+	}
+
+	switch {
+	case reg.IsCancelled():
+		err = registration.ErrAlreadyCancelled
+		return
+	case registration.NormalizeEmail(reg.Email) == newEmail:
+		err = registration.ErrEmailUnchanged
+		return
+	case !event.EmailDomainAllowed(newEmail, allowedDomains):
+		err = registration.ErrEmailDomainNotAllowed
+		return
+	}
+
+	var exists bool
+	err = repo.observe("registrations.change_email.duplicate_check", func() error {
+		return tx.QueryRow(ctx, `SELECT EXISTS(
+			SELECT 1 FROM registrations
+			WHERE event_id = $1 AND LOWER(email) = $2
+			  AND status <> 'cancelled'
+			  AND id <> $3
+		)`, eventID, newEmail, registrationID).Scan(&exists)
+	})
+	if err != nil {
+		return
+	}
+	if exists {
+		err = registration.ErrAlreadyRegistered
+		return
+	}
+
+	err = repo.observe("registrations.change_email", func() error {
+		return tx.QueryRow(ctx, `
+			UPDATE registrations
+			SET email = $2,
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING updated_at
+		`, registrationID, newEmail).Scan(&reg.UpdatedAt)
+	})
+	if err != nil {
+		err = mapUniqueViolation(err)
+		return
+	}
+	reg.Email = newEmail
+
+	if err = repo.recordHistoryTx(ctx, tx, eventID, []string{registrationID}, registration.HistoryEmailChanged, reg.Status, reg.Status); err != nil {
+		return
+	}
+
+	// nothing queued for the old address may still go out
+	err = repo.observe("registrations.change_email.cancel_confirmations", func() error {
+		_, e := tx.Exec(ctx, `
+			UPDATE jobs
+			SET status = CASE WHEN status = 'pending' THEN 'cancelled' ELSE status END,
+			    cancellation_requested = (status = 'processing'),
+			    last_error = CASE WHEN status = 'pending' THEN $3 ELSE last_error END,
+			    updated_at = NOW()
+			WHERE type = $1
+			  AND status IN ('pending', 'processing')
+			  AND payload->>'registrationId' = $2
+		`, jobs.TypeRegistrationConfirmation, registrationID, emailChangedError)
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	err = repo.observe("registrations.change_email.reset_delivery", func() error {
+		_, e := tx.Exec(ctx, `
+			UPDATE notification_deliveries
+			SET status = 'failed',
+			    sent_at = NULL,
+			    provider_message_id = NULL,
+			    last_error = $3,
+			    updated_at = NOW()
+			WHERE kind = $1 AND registration_id = $2
+		`, kindRegistrationConfirmation, registrationID, emailChangedError)
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	err = repo.observe("registrations.change_email.retarget_reminder", func() error {
+		_, e := tx.Exec(ctx, `
+			UPDATE jobs
+			SET payload = jsonb_set(payload, '{email}', to_jsonb($3::text)),
+			    updated_at = NOW()
+			WHERE type = $1
+			  AND idempotency_key = 'reminder:' || $2
+			  AND status = 'pending'
+		`, jobs.TypeRegistrationReminder, registrationID, newEmail)
+		return e
+	})
+	if err != nil {
+		return
+	}
+
+	if reg.Status == registration.StatusConfirmed {
+		p.RegistrationID = reg.ID
+		p.EventID = reg.EventID
+		p.Email = reg.Email
+		p.Name = reg.Name
+		p.RequestedAt = time.Now().UTC()
+
+		raw, jerr := p.JSON()
+		if jerr != nil {
+			err = jerr
+			return
+		}
+
+		// no idempotency key: the original confirmation holds it, and every
+		// correction is meant to send again
+		req := job.CreateRequest{
+			Type:        jobs.TypeRegistrationConfirmation,
+			Payload:     raw,
+			RunAt:       time.Now().UTC(),
+			MaxAttempts: 10,
+		}
+		if reg.UserID != "" {
+			uid := reg.UserID
+			req.UserID = &uid
+		}
+
+		confirmation, err = repo.jobs.CreateTx(ctx, tx, req)
+		if err != nil {
+			return
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
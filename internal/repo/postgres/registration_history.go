@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// recordHistoryTx writes one registration_events row of kind for each of
+// registrationIDs. from is empty for transitions that create the registration.
+func (repo *RegistrationRepo) recordHistoryTx(ctx context.Context, tx pgx.Tx, eventID string, registrationIDs []string, kind, from, to string) error {
+	return repo.observe("registrations.history.record", func() error {
+		_, e := tx.Exec(ctx, `
+			INSERT INTO registration_events (registration_id, event_id, kind, from_status, to_status)
+			SELECT u.id, $1, $3, NULLIF($4, ''), $5
+			FROM unnest($2::uuid[]) WITH ORDINALITY AS u(id, n)
+			ORDER BY u.n
+		`, eventID, registrationIDs, kind, from, to)
+		return e
+	})
+}
+
+// recordCancelledTx writes the HistoryCancelled row for registrationID, which
+// was in status from, with who cancelled it and why.
+func (repo *RegistrationRepo) recordCancelledTx(ctx context.Context, tx pgx.Tx, eventID, registrationID, from string, c registration.Cancellation) error {
+	return repo.observe("registrations.history.record", func() error {
+		_, e := tx.Exec(ctx, `
+			INSERT INTO registration_events (registration_id, event_id, kind, from_status, to_status, cancelled_by, cancellation_reason)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''))
+		`, registrationID, eventID, registration.HistoryCancelled, from, registration.StatusCancelled, c.By, c.Reason)
+		return e
+	})
+}
+
+const historyColumns = `id, registration_id, event_id, kind, COALESCE(from_status, ''), to_status, COALESCE(cancelled_by, ''), COALESCE(cancellation_reason, ''), occurred_at`
+
+func scanHistory(rows pgx.Rows) ([]registration.HistoryEntry, error) {
+	defer rows.Close()
+
+	var out []registration.HistoryEntry
+	for rows.Next() {
+		var h registration.HistoryEntry
+		if err := rows.Scan(&h.ID, &h.RegistrationID, &h.EventID, &h.Kind, &h.FromStatus, &h.ToStatus, &h.CancelledBy, &h.CancellationReason, &h.OccurredAt); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// History returns a registration's transitions, oldest first. An unknown
+// registration gives registration.ErrNotFound.
+func (repo *RegistrationRepo) History(ctx context.Context, eventID, registrationID string) ([]registration.HistoryEntry, error) {
+	if _, err := repo.GetByID(ctx, eventID, registrationID); err != nil {
+		return nil, err
+	}
+
+	var out []registration.HistoryEntry
+	err := repo.observe("registrations.history", func() error {
+		rows, qerr := repo.pool.Query(ctx, `
+			SELECT `+historyColumns+`
+			FROM registration_events
+			WHERE registration_id = $1
+			  AND event_id = $2
+			ORDER BY occurred_at ASC, id ASC
+		`, registrationID, eventID)
+		if qerr != nil {
+			return qerr
+		}
+		out, qerr = scanHistory(rows)
+		return qerr
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []registration.HistoryEntry{}
+	}
+	return out, nil
+}
+
+// ActivityByEventCursor pages through every transition recorded for
+// eventID's registrations, oldest first, starting after (afterAt, afterID).
+func (repo *RegistrationRepo) ActivityByEventCursor(
+	ctx context.Context,
+	eventID string,
+	filter registration.ActivityFilter,
+	limit int,
+	afterAt time.Time,
+	afterID string,
+) (items []registration.HistoryEntry, nextCursor *string, hasMore bool, err error) {
+	err = repo.observe("registrations.activity_by_event_cursor", func() error {
+		rows, qerr := repo.pool.Query(ctx, `
+			SELECT `+historyColumns+`
+			FROM registration_events
+			WHERE event_id = $1
+			  AND (occurred_at, id) > ($2, $3)
+			  AND ($5::timestamptz IS NULL OR occurred_at >= $5)
+			  AND ($6::timestamptz IS NULL OR occurred_at < $6)
+			ORDER BY occurred_at ASC, id ASC
+			LIMIT $4
+		`, eventID, afterAt, afterID, limit+1, filter.From, filter.To)
+		if qerr != nil {
+			return qerr
+		}
+		items, qerr = scanHistory(rows)
+		return qerr
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if items == nil {
+		items = []registration.HistoryEntry{}
+	}
+
+	if len(items) > limit {
+		hasMore = true
+		items = items[:limit]
+		last := items[len(items)-1]
+		cur, encErr := utils.EncodeRegistrationCursor(last.OccurredAt, last.ID)
+		if encErr != nil {
+			return nil, nil, false, encErr
+		}
+		nextCursor = &cur
+	}
+
+	return items, nextCursor, hasMore, nil
+}
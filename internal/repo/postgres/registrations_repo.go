@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/domain/event"
+	"github.com/geocoder89/eventhub/internal/domain/job"
 	"github.com/geocoder89/eventhub/internal/domain/registration"
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/jobs"
 	"github.com/geocoder89/eventhub/internal/observability"
 	"github.com/geocoder89/eventhub/internal/utils"
 	"github.com/jackc/pgx/v5"
@@ -17,15 +21,70 @@ import (
 type RegistrationRepo struct {
 	pool *pgxpool.Pool
 	prom *observability.Prom
+	jobs *JobsRepo
+
+	// registrations are still accepted this long after start_at
+	gracePeriod time.Duration
+
+	// the registration window is checked against this clock
+	now func() time.Time
+
+	// runs between the registration insert and its confirmation enqueue
+	afterInsert func(ctx context.Context, reg registration.Registration) error
+}
+
+// EnqueueConfirmation builds the confirmation job for reg, a registration
+// inserted but not yet committed. CreateWithConfirmation inserts the job in
+// the same transaction, so a registration never commits without its email.
+type EnqueueConfirmation interface {
+	ConfirmationJob(ctx context.Context, reg registration.Registration) (job.CreateRequest, error)
+}
+
+// EnqueueConfirmationFunc adapts a function to EnqueueConfirmation.
+type EnqueueConfirmationFunc func(ctx context.Context, reg registration.Registration) (job.CreateRequest, error)
+
+func (f EnqueueConfirmationFunc) ConfirmationJob(ctx context.Context, reg registration.Registration) (job.CreateRequest, error) {
+	return f(ctx, reg)
 }
 
 func NewRegistrationsRepo(pool *pgxpool.Pool, prom *observability.Prom) *RegistrationRepo {
 	return &RegistrationRepo{
 		pool: pool,
 		prom: prom,
+		jobs: NewJobsRepo(pool, prom),
+		now:  time.Now,
 	}
 }
 
+// WithJobs enqueues the jobs registration changes start (confirmations,
+// reminders, webhooks) through jobs, so its payload limits apply to them.
+func (repo *RegistrationRepo) WithJobs(jobs *JobsRepo) *RegistrationRepo {
+	repo.jobs = jobs
+	return repo
+}
+
+func (repo *RegistrationRepo) WithGracePeriod(d time.Duration) *RegistrationRepo {
+	if d < 0 {
+		d = 0
+	}
+	repo.gracePeriod = d
+	return repo
+}
+
+// WithClock replaces the clock registration windows are checked against.
+func (repo *RegistrationRepo) WithClock(now func() time.Time) *RegistrationRepo {
+	repo.now = now
+	return repo
+}
+
+// WithFaultAfterInsert runs hook between CreateWithConfirmation's
+// registration insert and its confirmation enqueue; an error aborts the
+// transaction there. Tests use it to crash the flow at its weakest point.
+func (repo *RegistrationRepo) WithFaultAfterInsert(hook func(ctx context.Context, reg registration.Registration) error) *RegistrationRepo {
+	repo.afterInsert = hook
+	return repo
+}
+
 func (repo *RegistrationRepo) observe(op string, fn func() error) error {
 	if repo.prom != nil {
 
@@ -39,14 +98,90 @@ func (repo *RegistrationRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
 }
 
 func (repo *RegistrationRepo) CreateTx(ctx context.Context, tx pgx.Tx, req registration.CreateRegistrationRequest) (reg registration.Registration, err error) {
-	// check duplicate emails for events
+	// 1) lock event row + check capacity
+	var capacity int
+	var current int
+	var maxQuantity int
+	var ended bool
+	var requiresAuth bool
+	var allowedDomains []string
+	var remind bool
+	var window event.Event
+	err = repo.observe("registrations.create_tx.capacity_lock", func() error {
+		return tx.QueryRow(ctx, `
+		SELECT e.capacity,
+			e.max_quantity,
+			e.start_at + ($2 * INTERVAL '1 second') < NOW() AS ended,
+			e.requires_auth OR e.visibility = 'private',
+			e.allowed_email_domains,
+			e.published_at IS NOT NULL AND e.start_at - ($3 * INTERVAL '1 second') > NOW() AS remind,
+			e.registration_opens_at,
+			e.registration_closes_at
+		FROM events e
+		WHERE e.id = $1
+		  AND e.deleted_at IS NULL
+		FOR UPDATE
+	`, req.EventID, int64(repo.gracePeriod.Seconds()), int64(jobs.ReminderLead.Seconds())).Scan(&capacity, &maxQuantity, &ended, &requiresAuth, &allowedDomains, &remind, &window.RegistrationOpensAt, &window.RegistrationClosesAt)
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = event.ErrNotFound
+		}
+
+		return
+	}
+
+	current, err = repo.confirmedSeatsTx(ctx, tx, "registrations.create_tx.count", req.EventID)
+	if err != nil {
+		return
+	}
 
+	if ended {
+		err = registration.ErrEventEnded
+		return
+	}
+
+	switch window.RegistrationWindowAt(repo.now()) {
+	case event.RegistrationNotOpen:
+		err = registration.ErrRegistrationNotOpen
+		return
+	case event.RegistrationClosed:
+		err = registration.ErrRegistrationClosed
+		return
+	}
+
+	quantity := max(req.Quantity, 1)
+	if quantity > maxQuantity {
+		err = &registration.QuantityLimitError{Max: maxQuantity}
+		return
+	}
+
+	if requiresAuth && req.UserID == "" {
+		err = registration.ErrAuthRequired
+		return
+	}
+
+	// on restricted events the token's email is the one that counts, so the
+	// domain rule cannot be dodged by typing a different address
+	if (requiresAuth || len(allowedDomains) > 0) && req.AuthEmail != "" {
+		req.Email = req.AuthEmail
+	}
+
+	if !event.EmailDomainAllowed(req.Email, allowedDomains) {
+		err = registration.ErrEmailDomainNotAllowed
+		return
+	}
+
+	// 2) check duplicate emails for events; rows stored before emails were
+	// normalized may still be mixed case, so compare lowercased on both sides
 	var exists bool
 
 	err = repo.observe("registrations.create_tx.duplicate_check", func() error {
 		return tx.QueryRow(ctx, `SELECT EXISTS(
 			SELECT 1 FROM registrations
-			WHERE event_id = $1 AND email = $2
+			WHERE event_id = $1 AND LOWER(email) = LOWER($2)
+			  AND status <> 'cancelled'
 		)`, req.EventID, req.Email).Scan(&exists)
 	})
 
@@ -59,52 +194,211 @@ func (repo *RegistrationRepo) CreateTx(ctx context.Context, tx pgx.Tx, req regis
 		return
 	}
 
-	// 2) lock event row + check capacity
-	var capacity int
-	var current int
-	err = repo.observe("registrations.create_tx.capacity_lock", func() error {
+	// all requested seats or none; a partial fit is still full
+	full := current+quantity > capacity
+	if full && !req.JoinWaitlist {
+		err = &registration.FullError{Remaining: max(capacity-current, 0)}
+		return
+	}
+
+	reg = registration.NewFromCreateRequest(req)
+
+	if full {
+		// the event row is locked above, so the sequence hands out gap-free positions
+		var position int64
+		err = repo.observe("registrations.create_tx.waitlist_seq", func() error {
+			return tx.QueryRow(ctx, `
+			UPDATE events
+			SET waitlist_seq = waitlist_seq + 1
+			WHERE id = $1
+			RETURNING waitlist_seq
+		`, req.EventID).Scan(&position)
+		})
+		if err != nil {
+			return
+		}
+
+		reg.Status = registration.StatusWaitlisted
+		reg.WaitlistPosition = &position
+	} else {
+		err = repo.adjustRegisteredCountTx(ctx, tx, req.EventID, reg.Quantity)
+		if err != nil {
+			return
+		}
+	}
+
+	err = repo.observe("registrations.create_tx.insert", func() error {
+		_, e := tx.Exec(ctx, `
+		INSERT INTO registrations (id, event_id, user_id, name, email, status, quantity, waitlist_position, check_in_token, created_at, updated_at)
+		VALUES ($1,$2,NULLIF($3, '')::uuid,$4,$5,$6,$7,$8,$9,$10,$11)
+	`, reg.ID, reg.EventID, reg.UserID, reg.Name, reg.Email, reg.Status, reg.Quantity, reg.WaitlistPosition, reg.CheckInToken, reg.CreatedAt, reg.UpdatedAt)
+		return e
+	})
+
+	if err != nil {
+		err = mapUniqueViolation(err)
+		return
+	}
+
+	kind := registration.HistoryCreated
+	if reg.Status == registration.StatusWaitlisted {
+		kind = registration.HistoryWaitlisted
+	}
+	if err = repo.recordHistoryTx(ctx, tx, req.EventID, []string{reg.ID}, kind, "", reg.Status); err != nil {
+		return
+	}
+
+	// publishing scheduled reminders for everyone registered before it;
+	// later sign-ups get theirs here
+	if remind && reg.Status == registration.StatusConfirmed {
+		if _, err = repo.enqueueRemindersTx(ctx, tx, req.EventID, []string{reg.ID}); err != nil {
+			return
+		}
+	}
+
+	err = repo.enqueueWebhooksTx(ctx, tx, webhook.KindRegistrationCreated, reg.ID)
+	return
+}
+
+// confirmedSeatsTx sums the seats held by confirmed registrations. It must run
+// as its own statement after the event row is locked: under READ COMMITTED a
+// subquery in the locking SELECT keeps the snapshot taken before the lock wait
+// and misses rows committed by the transaction it waited on.
+func (repo *RegistrationRepo) confirmedSeatsTx(ctx context.Context, tx pgx.Tx, op, eventID string) (int, error) {
+	var seats int
+	err := repo.observe(op, func() error {
 		return tx.QueryRow(ctx, `
-		SELECT e.capacity,
-			(SELECT COUNT(*) FROM registrations r WHERE r.event_id = e.id) AS current
+			SELECT COALESCE(SUM(quantity), 0)
+			FROM registrations
+			WHERE event_id = $1
+			  AND status = 'confirmed'
+		`, eventID).Scan(&seats)
+	})
+	return seats, err
+}
+
+// ImportTx inserts already validated, in-batch deduplicated rows as confirmed
+// registrations for eventID. Emails already registered for the event are
+// skipped and left out of created. Sign-up rules (auth, email domains, closing
+// time) do not apply to imports; capacity does unless overrideCapacity is set.
+// Reminders and registration.created webhooks are queued in tx, as for a
+// sign-up.
+func (repo *RegistrationRepo) ImportTx(ctx context.Context, tx pgx.Tx, eventID string, rows []registration.ImportRow, overrideCapacity bool) (created []registration.Registration, err error) {
+	var capacity, current int
+	err = repo.observe("registrations.import_tx.capacity_lock", func() error {
+		return tx.QueryRow(ctx, `
+		SELECT e.capacity
 		FROM events e
 		WHERE e.id = $1
 		  AND e.deleted_at IS NULL
 		FOR UPDATE
-	`, req.EventID).Scan(&capacity, &current)
+	`, eventID).Scan(&capacity)
 	})
-
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = event.ErrNotFound
 		}
+		return
+	}
 
+	current, err = repo.confirmedSeatsTx(ctx, tx, "registrations.import_tx.count", eventID)
+	if err != nil {
 		return
 	}
 
-	if current >= capacity {
-		err = registration.ErrEventFull
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, registration.NormalizeEmail(row.Email))
+	}
+
+	existing := make(map[string]bool)
+	err = repo.observe("registrations.import_tx.existing", func() error {
+		r, qerr := tx.Query(ctx, `
+			SELECT LOWER(email)
+			FROM registrations
+			WHERE event_id = $1 AND LOWER(email) = ANY($2)
+			  AND status <> 'cancelled'
+		`, eventID, emails)
+		if qerr != nil {
+			return qerr
+		}
+		defer r.Close()
+
+		for r.Next() {
+			var email string
+			if scanErr := r.Scan(&email); scanErr != nil {
+				return scanErr
+			}
+			existing[email] = true
+		}
+		return r.Err()
+	})
+	if err != nil {
 		return
 	}
 
-	reg = registration.NewFromCreateRequest(req)
+	// stagger created_at so listings keep the uploaded order
+	now := time.Now().UTC()
+	created = make([]registration.Registration, 0, len(rows))
+	for i, row := range rows {
+		if existing[emails[i]] {
+			continue
+		}
+		reg := registration.NewFromCreateRequest(registration.CreateRegistrationRequest{
+			EventID: eventID,
+			Name:    row.Name,
+			Email:   row.Email,
+		})
+		reg.CreatedAt = now.Add(time.Duration(len(created)) * time.Microsecond)
+		reg.UpdatedAt = reg.CreatedAt
+		created = append(created, reg)
+	}
 
-	err = repo.observe("registrations.create_tx.insert", func() error {
+	if len(created) == 0 {
+		return
+	}
+
+	if !overrideCapacity && current+len(created) > capacity {
+		created = nil
+		err = &registration.FullError{Remaining: max(capacity-current, 0)}
+		return
+	}
+
+	ids := make([]string, len(created))
+	names := make([]string, len(created))
+	regEmails := make([]string, len(created))
+	tokens := make([]string, len(created))
+	createdAts := make([]time.Time, len(created))
+	for i, reg := range created {
+		ids[i], names[i], regEmails[i], tokens[i], createdAts[i] = reg.ID, reg.Name, reg.Email, reg.CheckInToken, reg.CreatedAt
+	}
+
+	err = repo.observe("registrations.import_tx.insert", func() error {
 		_, e := tx.Exec(ctx, `
-		INSERT INTO registrations (id, event_id, user_id, name, email, check_in_token, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
-	`, reg.ID, reg.EventID, reg.UserID, reg.Name, reg.Email, reg.CheckInToken, reg.CreatedAt, reg.UpdatedAt)
+			INSERT INTO registrations (id, event_id, name, email, status, quantity, check_in_token, created_at, updated_at)
+			SELECT u.id, $1, u.name, u.email, 'confirmed', 1, u.token, u.created_at, u.created_at
+			FROM unnest($2::uuid[], $3::text[], $4::text[], $5::text[], $6::timestamptz[]) AS u(id, name, email, token, created_at)
+		`, eventID, ids, names, regEmails, tokens, createdAts)
 		return e
 	})
-
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "registrations_event_email_uniq" {
-			err = registration.ErrAlreadyRegistered
-			return
-		}
+		created = nil
 		return
 	}
 
+	err = repo.adjustRegisteredCountTx(ctx, tx, eventID, len(created))
+	if err == nil {
+		err = repo.recordHistoryTx(ctx, tx, eventID, ids, registration.HistoryCreated, "", registration.StatusConfirmed)
+	}
+	if err == nil {
+		_, err = repo.enqueueRemindersTx(ctx, tx, eventID, ids)
+	}
+	if err == nil {
+		err = repo.enqueueWebhooksTx(ctx, tx, webhook.KindRegistrationCreated, ids...)
+	}
+	if err != nil {
+		created = nil
+	}
 	return
 }
 
@@ -158,20 +452,64 @@ func (repo *RegistrationRepo) Create(ctx context.Context, req registration.Creat
 	// return reg, nil
 }
 
-func (repo *RegistrationRepo) ListByEvent(ctx context.Context, eventID string) (regs []registration.Registration, err error) {
+// CreateWithConfirmation creates a registration and, unless it is
+// waitlisted, enqueues the confirmation job confirm builds for it, in one
+// transaction: either both commit or neither does. Waitlisted registrations
+// return a zero job; they are confirmed on promotion.
+func (repo *RegistrationRepo) CreateWithConfirmation(ctx context.Context, req registration.CreateRegistrationRequest, confirm EnqueueConfirmation) (registration.Registration, job.Job, error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return registration.Registration{}, job.Job{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	reg, err := repo.CreateTx(ctx, tx, req)
+	if err != nil {
+		return registration.Registration{}, job.Job{}, err
+	}
+
+	var created job.Job
+	if !reg.IsWaitlisted() {
+		if repo.afterInsert != nil {
+			if err := repo.afterInsert(ctx, reg); err != nil {
+				return registration.Registration{}, job.Job{}, err
+			}
+		}
+
+		jobReq, err := confirm.ConfirmationJob(ctx, reg)
+		if err != nil {
+			return registration.Registration{}, job.Job{}, err
+		}
+		// the key embeds the new registration's id, so it cannot already
+		// be taken; a conflict here would abort the transaction anyway
+		created, err = repo.jobs.CreateTx(ctx, tx, jobReq)
+		if err != nil {
+			return registration.Registration{}, job.Job{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return registration.Registration{}, job.Job{}, err
+	}
+	return reg, created, nil
+}
+
+func (repo *RegistrationRepo) ListByEvent(ctx context.Context, eventID string, filter registration.ListFilter) (regs []registration.Registration, err error) {
 	var rows pgx.Rows
 
 	err = repo.observe("registrations.list_by_event", func() error {
 		rows, err = repo.pool.Query(ctx,
 			`
-	SELECT r.id, r.event_id, r.user_id, r.name, r.email, r.check_in_token, r.checked_in_at, r.created_at, r.updated_at
+	SELECT r.id, r.event_id, COALESCE(r.user_id::text, '') AS user_id, r.name, r.email, r.status, r.quantity, r.waitlist_position, r.check_in_token, r.checked_in_at, r.cancelled_at, COALESCE(r.cancelled_by, ''), COALESCE(r.cancellation_reason, ''), r.created_at, r.updated_at
 	FROM registrations r
 	JOIN events e ON e.id = r.event_id
 	WHERE r.event_id = $1
 	  AND e.deleted_at IS NULL
+	  AND ($2::boolean IS NULL OR (r.checked_in_at IS NOT NULL) = $2)
+	  AND ($3 OR r.status <> 'cancelled')
 	ORDER BY r.created_at ASC, r.id ASC
 	`,
-			eventID,
+			eventID, filter.CheckedIn, filter.IncludeCancelled,
 		)
 		return err
 	})
@@ -187,7 +525,7 @@ func (repo *RegistrationRepo) ListByEvent(ctx context.Context, eventID string) (
 	for rows.Next() {
 		var r registration.Registration
 
-		e := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.CheckInToken, &r.CheckedInAt, &r.CreatedAt, &r.UpdatedAt)
+		e := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Status, &r.Quantity, &r.WaitlistPosition, &r.CheckInToken, &r.CheckedInAt, &r.CancelledAt, &r.CancelledBy, &r.CancellationReason, &r.CreatedAt, &r.UpdatedAt)
 
 		if e != nil {
 			err = e
@@ -230,7 +568,7 @@ func (repo *RegistrationRepo) ListByEvent(ctx context.Context, eventID string) (
 	return
 }
 
-func (repo *RegistrationRepo) CountForEvent(ctx context.Context, eventID string) (int, error) {
+func (repo *RegistrationRepo) CountForEvent(ctx context.Context, eventID string, filter registration.ListFilter) (int, error) {
 	op := "registrations.count_for_event"
 	var total int
 	err := repo.observe(op, func() error {
@@ -240,7 +578,9 @@ func (repo *RegistrationRepo) CountForEvent(ctx context.Context, eventID string)
 			JOIN events e ON e.id = r.event_id
 			WHERE r.event_id = $1
 			  AND e.deleted_at IS NULL
-		`, eventID).Scan(&total)
+			  AND ($2::boolean IS NULL OR (r.checked_in_at IS NOT NULL) = $2)
+			  AND ($3 OR r.status <> 'cancelled')
+		`, eventID, filter.CheckedIn, filter.IncludeCancelled).Scan(&total)
 	})
 	return total, err
 }
@@ -248,6 +588,7 @@ func (repo *RegistrationRepo) CountForEvent(ctx context.Context, eventID string)
 func (repo *RegistrationRepo) ListByEventCursor(
 	ctx context.Context,
 	eventID string,
+	filter registration.ListFilter,
 	limit int,
 	afterCreatedAt time.Time,
 	afterID string,
@@ -255,12 +596,14 @@ func (repo *RegistrationRepo) ListByEventCursor(
 	op := "registrations.list_by_event_cursor"
 
 	q := `
-		SELECT r.id, r.event_id, r.user_id, r.name, r.email, r.check_in_token, r.checked_in_at, r.created_at, r.updated_at
+		SELECT r.id, r.event_id, COALESCE(r.user_id::text, '') AS user_id, r.name, r.email, r.status, r.quantity, r.waitlist_position, r.check_in_token, r.checked_in_at, r.cancelled_at, COALESCE(r.cancelled_by, ''), COALESCE(r.cancellation_reason, ''), r.created_at, r.updated_at
 		FROM registrations r
 		JOIN events e ON e.id = r.event_id
 		WHERE r.event_id = $1
 		  AND e.deleted_at IS NULL
 		  AND (r.created_at, r.id) > ($2, $3)
+		  AND ($5::boolean IS NULL OR (r.checked_in_at IS NOT NULL) = $5)
+		  AND ($6 OR r.status <> 'cancelled')
 		ORDER BY r.created_at ASC, r.id ASC
 		LIMIT $4
 	`
@@ -269,7 +612,7 @@ func (repo *RegistrationRepo) ListByEventCursor(
 	var rows pgx.Rows
 	err = repo.observe(op, func() error {
 		var qerr error
-		rows, qerr = repo.pool.Query(ctx, q, eventID, afterCreatedAt, afterID, limitPlusOne)
+		rows, qerr = repo.pool.Query(ctx, q, eventID, afterCreatedAt, afterID, limitPlusOne, filter.CheckedIn, filter.IncludeCancelled)
 		return qerr
 	})
 	if err != nil {
@@ -281,7 +624,7 @@ func (repo *RegistrationRepo) ListByEventCursor(
 
 	for rows.Next() {
 		var r registration.Registration
-		if scanErr := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.CheckInToken, &r.CheckedInAt, &r.CreatedAt, &r.UpdatedAt); scanErr != nil {
+		if scanErr := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Status, &r.Quantity, &r.WaitlistPosition, &r.CheckInToken, &r.CheckedInAt, &r.CancelledAt, &r.CancelledBy, &r.CancellationReason, &r.CreatedAt, &r.UpdatedAt); scanErr != nil {
 			return nil, nil, false, scanErr
 		}
 		out = append(out, r)
@@ -304,17 +647,276 @@ func (repo *RegistrationRepo) ListByEventCursor(
 	return out, nextCursor, hasMore, nil
 }
 
+// SearchByEmail finds registrations for an email (case-insensitive, exact)
+// across every event, newest first. Paging is keyset on (created_at, id) DESC.
+func (repo *RegistrationRepo) SearchByEmail(
+	ctx context.Context,
+	email string,
+	limit int,
+	beforeCreatedAt time.Time,
+	beforeID string,
+) (items []registration.WithEvent, nextCursor *string, hasMore bool, err error) {
+	op := "registrations.search_by_email"
+
+	var rows pgx.Rows
+	err = repo.observe(op, func() error {
+		var qerr error
+		rows, qerr = repo.pool.Query(ctx, `
+			SELECT r.id, r.event_id, COALESCE(r.user_id::text, '') AS user_id, r.name, r.email, r.status, r.quantity, r.waitlist_position, r.check_in_token, r.checked_in_at, r.cancelled_at, COALESCE(r.cancelled_by, ''), COALESCE(r.cancellation_reason, ''), r.created_at, r.updated_at,
+			       e.title, e.city, e.start_at
+			FROM registrations r
+			JOIN events e ON e.id = r.event_id
+			WHERE LOWER(r.email) = LOWER($1)
+			  AND (r.created_at, r.id) < ($2, $3)
+			ORDER BY r.created_at DESC, r.id DESC
+			LIMIT $4
+		`, email, beforeCreatedAt, beforeID, limit+1)
+		return qerr
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer rows.Close()
+
+	out := make([]registration.WithEvent, 0, limit)
+	for rows.Next() {
+		var r registration.WithEvent
+		if scanErr := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Status, &r.Quantity, &r.WaitlistPosition, &r.CheckInToken, &r.CheckedInAt, &r.CancelledAt, &r.CancelledBy, &r.CancellationReason, &r.CreatedAt, &r.UpdatedAt, &r.EventTitle, &r.EventCity, &r.EventStartAt); scanErr != nil {
+			return nil, nil, false, scanErr
+		}
+		out = append(out, r)
+	}
+	if rows.Err() != nil {
+		return nil, nil, false, rows.Err()
+	}
+
+	if len(out) > limit {
+		hasMore = true
+		out = out[:limit]
+		last := out[len(out)-1]
+		cur, encErr := utils.EncodeRegistrationCursor(last.CreatedAt, last.ID)
+		if encErr != nil {
+			return nil, nil, false, encErr
+		}
+		nextCursor = &cur
+	}
+
+	return out, nextCursor, hasMore, nil
+}
+
+// soleVerifiedEmail selects user $1's email, lowercased, when it is verified
+// and no other verified account holds it; no row otherwise. Only such an
+// email may stand in for user_id on registrations not yet linked.
+const soleVerifiedEmail = `
+	SELECT LOWER(u.email)
+	FROM users u
+	WHERE u.id = $1
+	  AND u.email_verified_at IS NOT NULL
+	  AND NOT EXISTS (
+		SELECT 1 FROM users o
+		WHERE o.id <> u.id
+		  AND o.email_verified_at IS NOT NULL
+		  AND LOWER(o.email) = LOWER(u.email)
+	  )`
+
+// ListByUserCursor pages through userID's registrations with their events,
+// ordered by event start. Paging is keyset on (start_at, registration id).
+// Rows linked to the user come first in the match; unlinked ones are found
+// by the user's verified email, unless another verified account shares it.
+// This is the attendee's own view, so who cancelled and why is not loaded.
+func (repo *RegistrationRepo) ListByUserCursor(
+	ctx context.Context,
+	userID string,
+	limit int,
+	afterStartAt time.Time,
+	afterID string,
+) (items []registration.WithEvent, nextCursor *string, hasMore bool, err error) {
+	var rows pgx.Rows
+	err = repo.observe("registrations.list_by_user_cursor", func() error {
+		var qerr error
+		rows, qerr = repo.pool.Query(ctx, `
+			SELECT r.id, r.event_id, COALESCE(r.user_id::text, '') AS user_id, r.name, r.email, r.status, r.quantity, r.waitlist_position, r.check_in_token, r.checked_in_at, r.cancelled_at, r.created_at, r.updated_at,
+			       e.title, e.city, e.start_at
+			FROM registrations r
+			JOIN events e ON e.id = r.event_id
+			WHERE (r.user_id = $1
+			       OR (r.user_id IS NULL AND LOWER(r.email) = (`+soleVerifiedEmail+`)))
+			  AND e.deleted_at IS NULL
+			  AND (e.start_at, r.id) > ($2, $3)
+			ORDER BY e.start_at ASC, r.id ASC
+			LIMIT $4
+		`, userID, afterStartAt, afterID, limit+1)
+		return qerr
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer rows.Close()
+
+	out := make([]registration.WithEvent, 0, limit)
+	for rows.Next() {
+		var r registration.WithEvent
+		if scanErr := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Status, &r.Quantity, &r.WaitlistPosition, &r.CheckInToken, &r.CheckedInAt, &r.CancelledAt, &r.CreatedAt, &r.UpdatedAt, &r.EventTitle, &r.EventCity, &r.EventStartAt); scanErr != nil {
+			return nil, nil, false, scanErr
+		}
+		out = append(out, r)
+	}
+	if rows.Err() != nil {
+		return nil, nil, false, rows.Err()
+	}
+
+	if len(out) > limit {
+		hasMore = true
+		out = out[:limit]
+		last := out[len(out)-1]
+		cur, encErr := utils.EncodeEventCursor(last.EventStartAt, last.ID)
+		if encErr != nil {
+			return nil, nil, false, encErr
+		}
+		nextCursor = &cur
+	}
+
+	return out, nextCursor, hasMore, nil
+}
+
+// ClaimByVerifiedEmail links anonymous registrations made with userID's
+// account email to the account. Only a verified email may claim, and
+// registrations already tied to an account are never moved. An email another
+// verified account also holds claims nothing.
+func (repo *RegistrationRepo) ClaimByVerifiedEmail(ctx context.Context, userID string) (int64, error) {
+	var verified bool
+	err := repo.observe("registrations.claim.verified", func() error {
+		return repo.pool.QueryRow(ctx, `
+			SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1
+		`, userID).Scan(&verified)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, registration.ErrEmailNotVerified
+		}
+		return 0, err
+	}
+	if !verified {
+		return 0, registration.ErrEmailNotVerified
+	}
+
+	var tag pgconn.CommandTag
+	err = repo.observe("registrations.claim", func() error {
+		var e error
+		tag, e = repo.pool.Exec(ctx, `
+			UPDATE registrations r
+			SET user_id = $1::uuid,
+			    updated_at = NOW()
+			WHERE r.user_id IS NULL
+			  AND LOWER(r.email) = (`+soleVerifiedEmail+`)
+		`, userID)
+		return e
+	})
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// LinkUsersBatch links up to limit unlinked registrations after afterID, in
+// id order, to the verified account with the same email. A registration
+// whose email more than one verified account holds is left alone and listed
+// in Conflicts rather than given to either.
+func (repo *RegistrationRepo) LinkUsersBatch(ctx context.Context, afterID string, limit int) (registration.UserLinkBatch, error) {
+	var res registration.UserLinkBatch
+	var lastID *string
+
+	err := repo.observe("registrations.link_users_batch", func() error {
+		return repo.pool.QueryRow(ctx, `
+			WITH batch AS (
+				SELECT id, LOWER(email) AS email
+				FROM registrations
+				WHERE user_id IS NULL
+				  AND id > $1
+				ORDER BY id
+				LIMIT $2
+			), matches AS (
+				SELECT b.id, COUNT(*) AS accounts, MIN(u.id::text) AS user_id
+				FROM batch b
+				JOIN users u ON LOWER(u.email) = b.email AND u.email_verified_at IS NOT NULL
+				GROUP BY b.id
+			), linked AS (
+				UPDATE registrations r
+				SET user_id = m.user_id::uuid,
+				    updated_at = NOW()
+				FROM matches m
+				WHERE r.id = m.id
+				  AND m.accounts = 1
+				  AND r.user_id IS NULL
+				RETURNING r.id
+			)
+			SELECT (SELECT COUNT(*) FROM batch),
+			       (SELECT id::text FROM batch ORDER BY id DESC LIMIT 1),
+			       (SELECT COUNT(*) FROM linked),
+			       ARRAY(SELECT id::text FROM matches WHERE accounts > 1 ORDER BY id)
+		`, afterID, limit).Scan(&res.Scanned, &lastID, &res.Linked, &res.Conflicts)
+	})
+	if err != nil {
+		return registration.UserLinkBatch{}, err
+	}
+	if lastID != nil {
+		res.LastID = *lastID
+	}
+	return res, nil
+}
+
+// ListByOrganizerCursor pages through registrations for every event
+// organizerID created, oldest first, starting after (afterCreatedAt, afterID).
+func (repo *RegistrationRepo) ListByOrganizerCursor(
+	ctx context.Context,
+	organizerID string,
+	limit int,
+	afterCreatedAt time.Time,
+	afterID string,
+) ([]registration.Registration, error) {
+	var rows pgx.Rows
+	err := repo.observe("registrations.list_by_organizer_cursor", func() error {
+		var qerr error
+		rows, qerr = repo.pool.Query(ctx, `
+			SELECT r.id, r.event_id, COALESCE(r.user_id::text, '') AS user_id, r.name, r.email, r.status, r.quantity, r.waitlist_position, r.check_in_token, r.checked_in_at, r.cancelled_at, r.created_at, r.updated_at
+			FROM registrations r
+			JOIN events e ON e.id = r.event_id
+			WHERE e.organizer_id = $1
+			  AND e.deleted_at IS NULL
+			  AND (r.created_at, r.id) > ($2, $3)
+			ORDER BY r.created_at ASC, r.id ASC
+			LIMIT $4
+		`, organizerID, afterCreatedAt, afterID, limit)
+		return qerr
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]registration.Registration, 0, limit)
+	for rows.Next() {
+		var r registration.Registration
+		if scanErr := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Status, &r.Quantity, &r.WaitlistPosition, &r.CheckInToken, &r.CheckedInAt, &r.CancelledAt, &r.CreatedAt, &r.UpdatedAt); scanErr != nil {
+			return nil, scanErr
+		}
+		out = append(out, r)
+	}
+
+	return out, rows.Err()
+}
+
 func (repo *RegistrationRepo) GetByID(ctx context.Context, eventID, registrationID string) (foundReg registration.Registration, newErr error) {
 	var r registration.Registration
 	err := repo.observe("registrations.get_by_id", func() error {
 		return repo.pool.QueryRow(ctx,
 			`
-		SELECT id, event_id, user_id, name, email, check_in_token, checked_in_at, created_at, updated_at
+		SELECT id, event_id, COALESCE(user_id::text, '') AS user_id, name, email, status, quantity, waitlist_position, check_in_token, checked_in_at, cancelled_at, COALESCE(cancelled_by, ''), COALESCE(cancellation_reason, ''), created_at, updated_at
 		FROM registrations
 		WHERE id = $1 AND event_id = $2
 		`,
 			registrationID, eventID,
-		).Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.CheckInToken, &r.CheckedInAt, &r.CreatedAt, &r.UpdatedAt)
+		).Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Status, &r.Quantity, &r.WaitlistPosition, &r.CheckInToken, &r.CheckedInAt, &r.CancelledAt, &r.CancelledBy, &r.CancellationReason, &r.CreatedAt, &r.UpdatedAt)
 	})
 
 	if err != nil {
@@ -331,66 +933,448 @@ func (repo *RegistrationRepo) GetByID(ctx context.Context, eventID, registration
 	return
 }
 
-// Delete removes a single registration for an event
+// HasActiveRegistration reports whether userID holds a registration for
+// eventID that isn't cancelled; it is what lets a registrant see a private
+// event.
+func (repo *RegistrationRepo) HasActiveRegistration(ctx context.Context, eventID, userID string) (bool, error) {
+	var exists bool
+	err := repo.observe("registrations.has_active", func() error {
+		return repo.pool.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM registrations
+				WHERE event_id = $1 AND user_id = $2 AND status <> 'cancelled'
+			)
+		`, eventID, userID).Scan(&exists)
+	})
+	return exists, err
+}
 
-func (repo *RegistrationRepo) Delete(ctx context.Context, eventID, registrationID string) (err error) {
+// Cancel marks a registration cancelled, keeping the row and its delivery
+// history. When confirmed seats are freed, waitlisted registrations that fit
+// are promoted in order in the same transaction and a
+// registration.confirmation job is enqueued for each. c is stored on the row
+// and on its cancelled history entry. Cancelling twice reports
+// ErrAlreadyCancelled.
+func (repo *RegistrationRepo) Cancel(ctx context.Context, eventID, registrationID string, c registration.Cancellation) (err error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
 
-	var tag pgconn.CommandTag
-	op := "registrations.delete"
-	err = repo.observe(op, func() error {
-		var err error
-		tag, err = repo.pool.Exec(ctx, `DELETE FROM registrations WHERE id = $1 AND event_id = $2`, registrationID, eventID)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-		return err
+	// same lock CreateTx takes, so promotion and new sign-ups cannot interleave
+	err = repo.observe("registrations.cancel.lock_event", func() error {
+		_, e := tx.Exec(ctx, `SELECT id FROM events WHERE id = $1 FOR UPDATE`, eventID)
+		return e
 	})
+	if err != nil {
+		return
+	}
 
+	// the row lock makes the status read and the transition one step
+	var status string
+	var quantity int
+	err = repo.observe("registrations.cancel.lock_registration", func() error {
+		return tx.QueryRow(ctx, `
+			SELECT status, quantity
+			FROM registrations
+			WHERE id = $1 AND event_id = $2
+			FOR UPDATE
+		`, registrationID, eventID).Scan(&status, &quantity)
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = registration.ErrNotFound
+		}
 		return
 	}
 
-	if tag.RowsAffected() == 0 {
-		err = registration.ErrNotFound
+	if status == registration.StatusCancelled {
+		err = registration.ErrAlreadyCancelled
+		return
+	}
+
+	err = repo.observe("registrations.cancel", func() error {
+		_, e := tx.Exec(ctx, `
+			UPDATE registrations
+			SET status = 'cancelled',
+			    cancelled_at = NOW(),
+			    cancelled_by = NULLIF($2, ''),
+			    cancellation_reason = NULLIF($3, ''),
+			    waitlist_position = NULL,
+			    updated_at = NOW()
+			WHERE id = $1
+		`, registrationID, c.By, c.Reason)
+		return e
+	})
+	if err != nil {
+		return
+	}
 
+	err = repo.recordCancelledTx(ctx, tx, eventID, registrationID, status, c)
+	if err != nil {
 		return
 	}
 
+	if status == registration.StatusConfirmed {
+		if err = repo.adjustRegisteredCountTx(ctx, tx, eventID, -quantity); err != nil {
+			return
+		}
+		if err = repo.promoteWaitlistedTx(ctx, tx, eventID); err != nil {
+			return
+		}
+	}
+
+	if err = repo.enqueueWebhooksTx(ctx, tx, webhook.KindRegistrationCancelled, registrationID); err != nil {
+		return
+	}
+
+	err = tx.Commit(ctx)
 	return
 }
 
-func (repo *RegistrationRepo) ListForEventExport(ctx context.Context, eventID string) ([]registration.Registration, error) {
-	op := "registrations.list_for_event_export"
+// adjustRegisteredCountTx keeps the cached events.registered_count in step
+// with the seats held by confirmed registrations. When seats are added it also
+// enqueues an organizer.capacity_alert for every alert threshold the count
+// crossed, keyed per event and threshold so each fires once. Callers must hold
+// the event row lock.
+func (repo *RegistrationRepo) adjustRegisteredCountTx(ctx context.Context, tx pgx.Tx, eventID string, delta int) error {
+	var registered, capacity int
+	var thresholds []int
+	var organizerID string
+
+	err := repo.observe("registrations.adjust_registered_count", func() error {
+		return tx.QueryRow(ctx, `
+			UPDATE events
+			SET registered_count = GREATEST(registered_count + $2, 0)
+			WHERE id = $1
+			RETURNING registered_count, capacity, capacity_alert_thresholds, COALESCE(organizer_id::text, '')
+		`, eventID, delta).Scan(&registered, &capacity, &thresholds, &organizerID)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
 
-	var rows pgx.Rows
-	err := repo.observe(op, func() error {
-		var qerr error
-		rows, qerr = repo.pool.Query(ctx, `
-			SELECT r.id, r.event_id, r.user_id, r.name, r.email, r.check_in_token, r.checked_in_at, r.created_at, r.updated_at
-			FROM registrations r
-			JOIN events e ON e.id = r.event_id
-			WHERE r.event_id = $1
-			  AND e.deleted_at IS NULL
-			ORDER BY r.created_at ASC, r.id ASC
-		`, eventID)
-		return qerr
+	// events without an organizer have nobody to alert
+	if delta <= 0 || organizerID == "" {
+		return nil
+	}
+
+	crossed := event.CrossedCapacityThresholds(thresholds, capacity, registered-delta, registered)
+	if len(crossed) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	reqs := make([]job.CreateRequest, 0, len(crossed))
+	for _, t := range crossed {
+		raw, err := jobs.OrganizerCapacityAlertPayload{
+			EventID:     eventID,
+			OrganizerID: organizerID,
+			Threshold:   t,
+			Capacity:    capacity,
+			Registered:  registered,
+			RequestedAt: now,
+		}.JSON()
+		if err != nil {
+			return err
+		}
+
+		key := jobs.CapacityAlertKey(eventID, t)
+		uid := organizerID
+		reqs = append(reqs, job.CreateRequest{
+			Type:           jobs.TypeOrganizerCapacityAlert,
+			Payload:        raw,
+			RunAt:          now,
+			MaxAttempts:    10,
+			IdempotencyKey: &key,
+			UserID:         &uid,
+		})
+	}
+
+	// ON CONFLICT DO NOTHING: a threshold crossed again after cancellations
+	// keeps its original job
+	_, err = repo.jobs.CreateManyTx(ctx, tx, reqs)
+	return err
+}
+
+// reminderPageSize is how many reminders are enqueued per statement.
+const reminderPageSize = 500
+
+// reminderDueSQL lists the confirmed registrations of a published event ($1),
+// limited to the ids in $2 unless it is NULL, that are due a reminder: the
+// event starts more than the lead ($3 seconds) from now. It pages by id after
+// $4, $5 at a time.
+const reminderDueSQL = `
+	SELECT r.id, r.email, r.name, r.user_id::text, e.start_at
+	FROM registrations r
+	JOIN events e ON e.id = r.event_id
+	WHERE r.event_id = $1
+	  AND ($2::uuid[] IS NULL OR r.id = ANY($2::uuid[]))
+	  AND r.status = 'confirmed'
+	  AND e.published_at IS NOT NULL
+	  AND e.deleted_at IS NULL
+	  AND e.start_at - ($3 * INTERVAL '1 second') > NOW()
+	  AND r.id > $4
+	ORDER BY r.id
+	LIMIT $5
+`
+
+// EnqueueReminders schedules a reminder for every confirmed registration of
+// eventID that does not have one yet. It runs when the event is published and
+// is safe to repeat.
+func (repo *RegistrationRepo) EnqueueReminders(ctx context.Context, eventID string) (n int64, err error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	n, err = repo.enqueueRemindersTx(ctx, tx, eventID, nil)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
+
+// enqueueRemindersTx schedules a registration.reminder at start_at minus
+// jobs.ReminderLead for registrationIDs (all of eventID's when nil), through
+// the jobs repo so payload limits and the trace in ctx apply. Registrations
+// that already have one are skipped; it returns how many were scheduled.
+func (repo *RegistrationRepo) enqueueRemindersTx(ctx context.Context, tx pgx.Tx, eventID string, registrationIDs []string) (int64, error) {
+	var n int64
+	afterID := "00000000-0000-0000-0000-000000000000"
+	for {
+		var page []jobs.RegistrationReminderPayload
+		var userIDs []*string
+		err := repo.observe("registrations.enqueue_reminders_tx", func() error {
+			rows, e := tx.Query(ctx, reminderDueSQL, eventID, registrationIDs, int64(jobs.ReminderLead.Seconds()), afterID, reminderPageSize)
+			if e != nil {
+				return e
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var p jobs.RegistrationReminderPayload
+				var userID *string
+				if e := rows.Scan(&p.RegistrationID, &p.Email, &p.Name, &userID, &p.StartAt); e != nil {
+					return e
+				}
+				page = append(page, p)
+				userIDs = append(userIDs, userID)
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return n, err
+		}
+		if len(page) == 0 {
+			return n, nil
+		}
+
+		now := time.Now().UTC()
+		reqs := make([]job.CreateRequest, 0, len(page))
+		for i, p := range page {
+			p.EventID = eventID
+			p.RequestedAt = now
+			raw, err := p.JSON()
+			if err != nil {
+				return n, err
+			}
+			key := jobs.ReminderKey(p.RegistrationID)
+			reqs = append(reqs, job.CreateRequest{
+				Type:           jobs.TypeRegistrationReminder,
+				Payload:        raw,
+				RunAt:          p.StartAt.Add(-jobs.ReminderLead),
+				MaxAttempts:    10,
+				IdempotencyKey: &key,
+				UserID:         userIDs[i],
+			})
+		}
+
+		created, err := repo.jobs.CreateManyTx(ctx, tx, reqs)
+		if err != nil {
+			return n, err
+		}
+		n += int64(len(created))
+
+		if len(page) < reminderPageSize {
+			return n, nil
+		}
+		afterID = page[len(page)-1].RegistrationID
+	}
+}
+
+// enqueueWebhooksTx queues a webhook.deliver for every enabled webhook
+// subscribed to kind and each of registrationIDs, carrying the registration
+// as it stands in tx. The jobs commit or roll back with the registration
+// change itself.
+func (repo *RegistrationRepo) enqueueWebhooksTx(ctx context.Context, tx pgx.Tx, kind string, registrationIDs ...string) error {
+	webhookIDs, err := NewWebhooksRepo(repo.pool, repo.prom).SubscribedTx(ctx, tx, kind)
+	if err != nil || len(webhookIDs) == 0 || len(registrationIDs) == 0 {
+		return err
+	}
+
+	var regs []webhook.RegistrationData
+	err = repo.observe("registrations.webhook_data", func() error {
+		rows, e := tx.Query(ctx, `
+			SELECT id, event_id, COALESCE(user_id::text, ''), name, email, status, quantity
+			FROM registrations
+			WHERE id = ANY($1::uuid[])
+			ORDER BY created_at, id
+		`, registrationIDs)
+		if e != nil {
+			return e
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var data webhook.RegistrationData
+			if e := rows.Scan(&data.RegistrationID, &data.EventID, &data.UserID, &data.Name, &data.Email, &data.Status, &data.Quantity); e != nil {
+				return e
+			}
+			regs = append(regs, data)
+		}
+		return rows.Err()
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	out := make([]registration.Registration, 0)
-	for rows.Next() {
-		var r registration.Registration
-		if scanErr := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.CheckInToken, &r.CheckedInAt, &r.CreatedAt, &r.UpdatedAt); scanErr != nil {
-			return nil, scanErr
+	now := time.Now().UTC()
+	reqs := make([]job.CreateRequest, 0, len(regs)*len(webhookIDs))
+	for _, data := range regs {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		for _, webhookID := range webhookIDs {
+			payload, err := jobs.WebhookDeliverPayload{WebhookID: webhookID, Kind: kind, OccurredAt: now, Data: raw}.JSON()
+			if err != nil {
+				return err
+			}
+
+			key := jobs.WebhookDeliverKey(webhookID, kind, data.RegistrationID)
+			reqs = append(reqs, job.CreateRequest{
+				Type:           jobs.TypeWebhookDeliver,
+				Payload:        payload,
+				RunAt:          now,
+				MaxAttempts:    8,
+				IdempotencyKey: &key,
+			})
 		}
-		out = append(out, r)
 	}
-	if rows.Err() != nil {
-		return nil, rows.Err()
+
+	_, err = repo.jobs.CreateManyTx(ctx, tx, reqs)
+	return err
+}
+
+// promoteWaitlistedTx confirms waitlisted registrations for eventID in
+// waitlist order while the head of the queue fits in the free seats, and
+// enqueues a confirmation for each. A head that needs more seats than are
+// free blocks the queue rather than being skipped. Callers must hold the
+// event row lock.
+func (repo *RegistrationRepo) promoteWaitlistedTx(ctx context.Context, tx pgx.Tx, eventID string) error {
+	for {
+		ok, err := repo.promoteNextTx(ctx, tx, eventID)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// promoteNextTx confirms the earliest waitlisted registration for eventID if
+// its seats fit, and enqueues its confirmation. ok is false when the waitlist
+// is empty or its head does not fit.
+func (repo *RegistrationRepo) promoteNextTx(ctx context.Context, tx pgx.Tx, eventID string) (ok bool, err error) {
+	var r registration.Registration
+	var p jobs.RegistrationConfirmationPayload
+
+	err = repo.observe("registrations.promote_waitlisted", func() error {
+		return tx.QueryRow(ctx, `
+			UPDATE registrations r
+			SET status = 'confirmed',
+			    waitlist_position = NULL,
+			    updated_at = NOW()
+			FROM events ev
+			WHERE ev.id = r.event_id
+			  AND r.id = (
+				SELECT w.id
+				FROM registrations w
+				JOIN events e ON e.id = w.event_id
+				WHERE w.event_id = $1
+				  AND w.status = 'waitlisted'
+				  AND w.quantity <= e.capacity - (
+					SELECT COALESCE(SUM(quantity), 0)
+					FROM registrations
+					WHERE event_id = $1
+					  AND status = 'confirmed'
+				  )
+				  AND w.waitlist_position = (
+					SELECT MIN(waitlist_position)
+					FROM registrations
+					WHERE event_id = $1
+					  AND status = 'waitlisted'
+				  )
+			)
+			RETURNING r.id, r.event_id, COALESCE(r.user_id::text, '') AS user_id, r.name, r.email, r.quantity,
+				COALESCE(ev.branding_reply_to, ''), COALESCE(ev.branding_display_name, ''), COALESCE(ev.branding_logo_url, '')
+		`, eventID).Scan(&r.ID, &r.EventID, &r.UserID, &r.Name, &r.Email, &r.Quantity, &p.ReplyTo, &p.OrganizerName, &p.LogoURL)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+		return
+	}
+
+	if err = repo.adjustRegisteredCountTx(ctx, tx, eventID, r.Quantity); err != nil {
+		return
+	}
+	if err = repo.recordHistoryTx(ctx, tx, eventID, []string{r.ID}, registration.HistoryPromoted, registration.StatusWaitlisted, registration.StatusConfirmed); err != nil {
+		return
+	}
+	if _, err = repo.enqueueRemindersTx(ctx, tx, eventID, []string{r.ID}); err != nil {
+		return
+	}
+
+	p.RegistrationID = r.ID
+	p.EventID = r.EventID
+	p.Email = r.Email
+	p.Name = r.Name
+	p.RequestedAt = time.Now().UTC()
+
+	raw, err := p.JSON()
+	if err != nil {
+		return
+	}
+
+	// same key the register handler uses, so a promoted registration is confirmed once
+	key := "registration:confirm:" + r.ID
+	req := job.CreateRequest{
+		Type:           jobs.TypeRegistrationConfirmation,
+		Payload:        raw,
+		RunAt:          time.Now().UTC(),
+		MaxAttempts:    10,
+		IdempotencyKey: &key,
+	}
+	if r.UserID != "" {
+		uid := r.UserID
+		req.UserID = &uid
+	}
+
+	_, err = repo.jobs.CreateTx(ctx, tx, req)
+	if err != nil {
+		return
 	}
 
-	return out, nil
+	return true, nil
 }
 
 func (repo *RegistrationRepo) CheckInByToken(ctx context.Context, eventID, token string) (registration.Registration, error) {
@@ -401,21 +1385,32 @@ func (repo *RegistrationRepo) CheckInByToken(ctx context.Context, eventID, token
 
 	err := repo.observe(op, func() error {
 		return repo.pool.QueryRow(ctx, `
-			UPDATE registrations
-			SET checked_in_at = $3,
-			    updated_at = $3
-			WHERE event_id = $1
-			  AND check_in_token = $2
-			  AND checked_in_at IS NULL
-			RETURNING id, event_id, user_id, name, email, check_in_token, checked_in_at, created_at, updated_at
+			WITH checked AS (
+				UPDATE registrations
+				SET checked_in_at = $3,
+				    updated_at = $3
+				WHERE event_id = $1
+				  AND check_in_token = $2
+				  AND status = 'confirmed'
+				  AND checked_in_at IS NULL
+				RETURNING id, event_id, COALESCE(user_id::text, '') AS user_id, name, email, status, quantity, waitlist_position, check_in_token, checked_in_at, cancelled_at, created_at, updated_at
+			), recorded AS (
+				INSERT INTO registration_events (registration_id, event_id, kind, from_status, to_status)
+				SELECT id, event_id, 'checked_in', status, status FROM checked
+			)
+			SELECT * FROM checked
 		`, eventID, token, now).Scan(
 			&r.ID,
 			&r.EventID,
 			&r.UserID,
 			&r.Name,
 			&r.Email,
+			&r.Status,
+			&r.Quantity,
+			&r.WaitlistPosition,
 			&r.CheckInToken,
 			&r.CheckedInAt,
+			&r.CancelledAt,
 			&r.CreatedAt,
 			&r.UpdatedAt,
 		)
@@ -450,3 +1445,74 @@ func (repo *RegistrationRepo) CheckInByToken(ctx context.Context, eventID, token
 
 	return registration.Registration{}, registration.ErrNotFound
 }
+
+// CheckIn marks a confirmed registration as checked in. It only ever sets
+// checked_in_at once; a second call reports ErrAlreadyCheckedIn.
+func (repo *RegistrationRepo) CheckIn(ctx context.Context, eventID, registrationID string) (registration.Registration, error) {
+	op := "registrations.check_in"
+
+	var r registration.Registration
+	now := time.Now().UTC()
+
+	err := repo.observe(op, func() error {
+		return repo.pool.QueryRow(ctx, `
+			WITH checked AS (
+				UPDATE registrations
+				SET checked_in_at = $3,
+				    updated_at = $3
+				WHERE event_id = $1
+				  AND id = $2
+				  AND status = 'confirmed'
+				  AND checked_in_at IS NULL
+				RETURNING id, event_id, COALESCE(user_id::text, '') AS user_id, name, email, status, quantity, waitlist_position, check_in_token, checked_in_at, cancelled_at, created_at, updated_at
+			), recorded AS (
+				INSERT INTO registration_events (registration_id, event_id, kind, from_status, to_status)
+				SELECT id, event_id, 'checked_in', status, status FROM checked
+			)
+			SELECT * FROM checked
+		`, eventID, registrationID, now).Scan(
+			&r.ID,
+			&r.EventID,
+			&r.UserID,
+			&r.Name,
+			&r.Email,
+			&r.Status,
+			&r.Quantity,
+			&r.WaitlistPosition,
+			&r.CheckInToken,
+			&r.CheckedInAt,
+			&r.CancelledAt,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		)
+	})
+	if err == nil {
+		return r, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return registration.Registration{}, err
+	}
+
+	var alreadyCheckedIn bool
+	err = repo.observe(op+".exists_checked", func() error {
+		return repo.pool.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1
+				FROM registrations
+				WHERE event_id = $1
+				  AND id = $2
+				  AND checked_in_at IS NOT NULL
+			)
+		`, eventID, registrationID).Scan(&alreadyCheckedIn)
+	})
+	if err != nil {
+		return registration.Registration{}, err
+	}
+
+	if alreadyCheckedIn {
+		return registration.Registration{}, registration.ErrAlreadyCheckedIn
+	}
+
+	return registration.Registration{}, registration.ErrNotFound
+}
@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/domain/job"
+	"github.com/geocoder89/eventhub/internal/domain/schedule"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// schedulerLockKey is the advisory lock held while due schedules are turned
+// into jobs, so only one worker instance schedules at a time.
+const schedulerLockKey int64 = 0x6a6f625f73636864 // "job_schd"
+
+type SchedulesRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+	jobs *JobsRepo
+}
+
+func NewSchedulesRepo(pool *pgxpool.Pool, prom *observability.Prom) *SchedulesRepo {
+	return &SchedulesRepo{pool: pool, prom: prom, jobs: NewJobsRepo(pool, prom)}
+}
+
+// WithJobs enqueues through jobs, so its payload limits apply to schedules
+// both when they are saved and when they run.
+func (r *SchedulesRepo) WithJobs(jobs *JobsRepo) *SchedulesRepo {
+	r.jobs = jobs
+	return r
+}
+
+func (r *SchedulesRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+const scheduleColumns = `id, type, payload, cron, next_run_at, last_run_at, enabled, created_at, updated_at`
+
+func scanSchedule(row pgx.Row) (schedule.Schedule, error) {
+	var s schedule.Schedule
+	err := row.Scan(&s.ID, &s.Type, &s.Payload, &s.Cron, &s.NextRunAt, &s.LastRunAt, &s.Enabled, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schedule.Schedule{}, schedule.ErrNotFound
+	}
+	return s, err
+}
+
+func (r *SchedulesRepo) Create(ctx context.Context, s schedule.Schedule) (schedule.Schedule, error) {
+	if err := r.jobs.checkPayload(job.CreateRequest{Type: s.Type, Payload: s.Payload}); err != nil {
+		return schedule.Schedule{}, err
+	}
+
+	var out schedule.Schedule
+	err := r.observe("schedules.create", func() error {
+		var err error
+		out, err = scanSchedule(r.pool.QueryRow(ctx, `
+			INSERT INTO job_schedules (id, type, payload, cron, next_run_at, enabled, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING `+scheduleColumns,
+			s.ID, s.Type, s.Payload, s.Cron, s.NextRunAt, s.Enabled, s.CreatedAt, s.UpdatedAt,
+		))
+		return err
+	})
+	return out, err
+}
+
+func (r *SchedulesRepo) GetByID(ctx context.Context, id string) (schedule.Schedule, error) {
+	var out schedule.Schedule
+	err := r.observe("schedules.get_by_id", func() error {
+		var err error
+		out, err = scanSchedule(r.pool.QueryRow(ctx, `SELECT `+scheduleColumns+` FROM job_schedules WHERE id = $1`, id))
+		return err
+	})
+	return out, err
+}
+
+// List returns every schedule, oldest first.
+func (r *SchedulesRepo) List(ctx context.Context) ([]schedule.Schedule, error) {
+	out := []schedule.Schedule{}
+	err := r.observe("schedules.list", func() error {
+		rows, err := r.pool.Query(ctx, `SELECT `+scheduleColumns+` FROM job_schedules ORDER BY created_at, id`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			s, err := scanSchedule(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, s)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// Update replaces the schedule's settings and moves its next run to the
+// expression's next match from now.
+func (r *SchedulesRepo) Update(ctx context.Context, id string, req schedule.UpdateRequest) (schedule.Schedule, error) {
+	c, err := schedule.ParseCron(req.Cron)
+	if err != nil {
+		return schedule.Schedule{}, err
+	}
+	payload := schedule.NormalizePayload(req.Payload)
+	if err := r.jobs.checkPayload(job.CreateRequest{Type: req.Type, Payload: payload}); err != nil {
+		return schedule.Schedule{}, err
+	}
+
+	var out schedule.Schedule
+	err = r.observe("schedules.update", func() error {
+		var err error
+		out, err = scanSchedule(r.pool.QueryRow(ctx, `
+			UPDATE job_schedules
+			SET type = $2,
+			    payload = $3,
+			    cron = $4,
+			    next_run_at = $5,
+			    enabled = $6,
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+scheduleColumns,
+			id, req.Type, payload, req.Cron, c.Next(time.Now()), req.Enabled,
+		))
+		return err
+	})
+	return out, err
+}
+
+// Delete removes the schedule. Jobs it already enqueued still run.
+func (r *SchedulesRepo) Delete(ctx context.Context, id string) error {
+	return r.observe("schedules.delete", func() error {
+		tag, err := r.pool.Exec(ctx, `DELETE FROM job_schedules WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return schedule.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// EnqueueDue turns up to limit enabled schedules due at now into jobs and
+// moves each one's next run past now; runs missed while no worker was up
+// collapse into one. It returns how many jobs were enqueued, 0 when another
+// instance holds the scheduler lock. Every job carries the occurrence's
+// idempotency key, so an occurrence is never enqueued twice.
+//
+// The schedules are read FOR UPDATE in the same transaction as the inserts,
+// so a schedule disabled before this commits produces nothing.
+func (r *SchedulesRepo) EnqueueDue(ctx context.Context, now time.Time, limit int) (n int, err error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var locked bool
+	err = r.observe("schedules.enqueue_due.lock", func() error {
+		return tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, schedulerLockKey).Scan(&locked)
+	})
+	if err != nil || !locked {
+		return 0, err
+	}
+
+	var due []schedule.Schedule
+	err = r.observe("schedules.enqueue_due.select", func() error {
+		rows, err := tx.Query(ctx, `
+			SELECT `+scheduleColumns+`
+			FROM job_schedules
+			WHERE enabled AND next_run_at <= $1
+			ORDER BY next_run_at, id
+			LIMIT $2
+			FOR UPDATE
+		`, now, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			s, err := scanSchedule(rows)
+			if err != nil {
+				return err
+			}
+			due = append(due, s)
+		}
+		return rows.Err()
+	})
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+
+	reqs := make([]job.CreateRequest, len(due))
+	ids := make([]string, len(due))
+	nextRuns := make([]time.Time, len(due))
+	lastRuns := make([]time.Time, len(due))
+	for i, s := range due {
+		c, err := schedule.ParseCron(s.Cron)
+		if err != nil {
+			return 0, fmt.Errorf("schedule %s: %w", s.ID, err)
+		}
+
+		key := schedule.IdempotencyKey(s.ID, s.NextRunAt)
+		reqs[i] = job.CreateRequest{Type: s.Type, Payload: s.Payload, RunAt: s.NextRunAt, IdempotencyKey: &key}
+		ids[i], nextRuns[i], lastRuns[i] = s.ID, c.Next(now), s.NextRunAt
+	}
+
+	created, err := r.jobs.CreateManyTx(ctx, tx, reqs)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.observe("schedules.enqueue_due.advance", func() error {
+		_, err := tx.Exec(ctx, `
+			UPDATE job_schedules s
+			SET next_run_at = u.next_run_at,
+			    last_run_at = u.last_run_at,
+			    updated_at = NOW()
+			FROM unnest($1::uuid[], $2::timestamptz[], $3::timestamptz[]) AS u(id, next_run_at, last_run_at)
+			WHERE s.id = u.id
+		`, ids, nextRuns, lastRuns)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(created), nil
+}
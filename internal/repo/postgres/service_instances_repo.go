@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/geocoder89/eventhub/internal/configdrift"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ServiceInstancesRepo keeps the config fingerprints running processes
+// announce; it is the configdrift.Store.
+type ServiceInstancesRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewServiceInstancesRepo(pool *pgxpool.Pool, prom *observability.Prom) *ServiceInstancesRepo {
+	return &ServiceInstancesRepo{pool: pool, prom: prom}
+}
+
+func (r *ServiceInstancesRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+// Announce upserts in as seen now. Rows not seen for a day, left by
+// processes that crashed, are deleted on the way.
+func (r *ServiceInstancesRepo) Announce(ctx context.Context, in configdrift.Instance) error {
+	parts, err := json.Marshal(in.Parts)
+	if err != nil {
+		return err
+	}
+
+	return r.observe("service_instances.announce", func() error {
+		_, err := r.pool.Exec(ctx, `
+			WITH pruned AS (
+				DELETE FROM service_instances
+				WHERE last_seen_at < NOW() - INTERVAL '1 day'
+				  AND id <> $1
+			)
+			INSERT INTO service_instances (id, service, fingerprint, fingerprint_parts, started_at, last_seen_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())
+			ON CONFLICT (id) DO UPDATE
+			SET service = EXCLUDED.service,
+			    fingerprint = EXCLUDED.fingerprint,
+			    fingerprint_parts = EXCLUDED.fingerprint_parts,
+			    started_at = EXCLUDED.started_at,
+			    last_seen_at = NOW()
+		`, in.ID, in.Service, in.Fingerprint, parts, in.StartedAt)
+		return err
+	})
+}
+
+// ListLive returns the instances seen since since, oldest start first.
+func (r *ServiceInstancesRepo) ListLive(ctx context.Context, since time.Time) ([]configdrift.Instance, error) {
+	out := []configdrift.Instance{}
+
+	err := r.observe("service_instances.list_live", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id, service, fingerprint, fingerprint_parts, started_at, last_seen_at
+			FROM service_instances
+			WHERE last_seen_at >= $1
+			ORDER BY started_at, id
+		`, since)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var in configdrift.Instance
+			var parts []byte
+			if err := rows.Scan(&in.ID, &in.Service, &in.Fingerprint, &parts, &in.StartedAt, &in.LastSeenAt); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(parts, &in.Parts); err != nil {
+				return err
+			}
+			out = append(out, in)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Remove deletes the instance with id; an unknown id is not an error.
+func (r *ServiceInstancesRepo) Remove(ctx context.Context, id string) error {
+	return r.observe("service_instances.remove", func() error {
+		_, err := r.pool.Exec(ctx, `DELETE FROM service_instances WHERE id = $1`, id)
+		return err
+	})
+}
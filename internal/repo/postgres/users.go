@@ -3,17 +3,16 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/geocoder89/eventhub/internal/domain/user"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var ErrUserNotFound = errors.New("user not found")
-var ErrEmailAlreadyUsed = errors.New("email is already in use")
 
 type UsersRepo struct {
 	pool *pgxpool.Pool
@@ -23,7 +22,11 @@ func NewUsersRepo(pool *pgxpool.Pool) *UsersRepo {
 	return &UsersRepo{pool: pool}
 }
 
-func (r *UsersRepo) Create(ctx context.Context, email, passwordHash, name, role string) (user.User, error) {
+// Create inserts a user; role must be one of the user.Role constants.
+func (r *UsersRepo) Create(ctx context.Context, email, passwordHash, name string, role user.Role) (user.User, error) {
+	if !role.Valid() {
+		return user.User{}, fmt.Errorf("create user: %w %q", user.ErrUnknownRole, role)
+	}
 	now := time.Now().UTC()
 	u := user.User{
 		ID:           uuid.NewString(),
@@ -41,12 +44,7 @@ func (r *UsersRepo) Create(ctx context.Context, email, passwordHash, name, role
 	`, u.ID, u.Email, u.PasswordHash, u.Name, u.Role, u.CreatedAt, u.UpdatedAt)
 
 	if err != nil {
-		var pgErr *pgconn.PgError
-
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return user.User{}, ErrEmailAlreadyUsed
-		}
-		return user.User{}, err
+		return user.User{}, mapUniqueViolation(err)
 	}
 
 	return u, nil
@@ -81,3 +79,77 @@ func (r *UsersRepo) GetByEmail(ctx context.Context, email string) (user.User, er
 	}
 	return u, nil
 }
+
+func (r *UsersRepo) GetByID(ctx context.Context, id string) (user.User, error) {
+	var u user.User
+
+	err := r.pool.QueryRow(
+		ctx,
+		`SELECT id, email, password_hash, name, role, created_at, updated_at
+         FROM users
+         WHERE id = $1`,
+		id,
+	).Scan(
+		&u.ID,
+		&u.Email,
+		&u.PasswordHash,
+		&u.Name,
+		&u.Role,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, ErrUserNotFound
+		}
+
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+// ReplacePasswordHash swaps oldHash for newHash, unless the password changed
+// in between. It reports whether the row was updated.
+func (r *UsersRepo) ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users
+		SET password_hash = $3, updated_at = NOW()
+		WHERE id = $1 AND password_hash = $2`,
+		id, oldHash, newHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// EmailVerified reports whether the user has verified their email.
+func (r *UsersRepo) EmailVerified(ctx context.Context, id string) (bool, error) {
+	var verified bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`,
+		id,
+	).Scan(&verified)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrUserNotFound
+	}
+	return verified, err
+}
+
+// MarkEmailVerified records that the user proved they own email. It reports
+// false when the user is gone or no longer has that address. Verifying again
+// keeps the first time; the first one links the user's earlier registrations
+// (see the users_link_registrations_on_email_verified trigger).
+func (r *UsersRepo) MarkEmailVerified(ctx context.Context, id, email string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1 AND LOWER(email) = LOWER($2)`,
+		id, email,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
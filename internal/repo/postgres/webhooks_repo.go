@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geocoder89/eventhub/internal/domain/webhook"
+	"github.com/geocoder89/eventhub/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhooksRepo struct {
+	pool *pgxpool.Pool
+	prom *observability.Prom
+}
+
+func NewWebhooksRepo(pool *pgxpool.Pool, prom *observability.Prom) *WebhooksRepo {
+	return &WebhooksRepo{pool: pool, prom: prom}
+}
+
+func (r *WebhooksRepo) observe(op string, fn func() error) error {
+	if r.prom != nil {
+		return r.prom.ObserveDB(op, fn)
+	}
+	return fn()
+}
+
+const webhookColumns = `id, url, secret, enabled, event_kinds, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (webhook.Webhook, error) {
+	var w webhook.Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Enabled, &w.EventKinds, &w.CreatedAt, &w.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return webhook.Webhook{}, webhook.ErrNotFound
+	}
+	return w, err
+}
+
+func (r *WebhooksRepo) Create(ctx context.Context, w webhook.Webhook) (webhook.Webhook, error) {
+	var out webhook.Webhook
+	err := r.observe("webhooks.create", func() error {
+		var err error
+		out, err = scanWebhook(r.pool.QueryRow(ctx, `
+			INSERT INTO webhooks (id, url, secret, enabled, event_kinds, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+webhookColumns,
+			w.ID, w.URL, w.Secret, w.Enabled, w.EventKinds, w.CreatedAt, w.UpdatedAt,
+		))
+		return err
+	})
+	return out, err
+}
+
+func (r *WebhooksRepo) GetByID(ctx context.Context, id string) (webhook.Webhook, error) {
+	var out webhook.Webhook
+	err := r.observe("webhooks.get_by_id", func() error {
+		var err error
+		out, err = scanWebhook(r.pool.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+		return err
+	})
+	return out, err
+}
+
+// List returns every webhook, oldest first.
+func (r *WebhooksRepo) List(ctx context.Context) ([]webhook.Webhook, error) {
+	out := []webhook.Webhook{}
+	err := r.observe("webhooks.list", func() error {
+		rows, err := r.pool.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			w, err := scanWebhook(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, w)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// Update replaces the webhook's settings, keeping its secret when req has none.
+func (r *WebhooksRepo) Update(ctx context.Context, id string, req webhook.UpdateRequest) (webhook.Webhook, error) {
+	var out webhook.Webhook
+	err := r.observe("webhooks.update", func() error {
+		var err error
+		out, err = scanWebhook(r.pool.QueryRow(ctx, `
+			UPDATE webhooks
+			SET url = $2,
+			    event_kinds = $3,
+			    enabled = $4,
+			    secret = COALESCE(NULLIF($5, ''), secret),
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+webhookColumns,
+			id, req.URL, webhook.NormalizeKinds(req.EventKinds), req.Enabled, req.Secret,
+		))
+		return err
+	})
+	return out, err
+}
+
+// Delete removes the webhook and its delivery log. Deliveries still queued
+// find it gone and are dropped.
+func (r *WebhooksRepo) Delete(ctx context.Context, id string) error {
+	return r.observe("webhooks.delete", func() error {
+		tag, err := r.pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return webhook.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// SubscribedTx returns the ids of enabled webhooks listening for kind.
+func (r *WebhooksRepo) SubscribedTx(ctx context.Context, tx pgx.Tx, kind string) ([]string, error) {
+	var ids []string
+	err := r.observe("webhooks.subscribed_tx", func() error {
+		rows, err := tx.Query(ctx, `
+			SELECT id::text
+			FROM webhooks
+			WHERE enabled AND event_kinds @> ARRAY[$1]::text[]
+			ORDER BY created_at, id
+		`, kind)
+		if err != nil {
+			return err
+		}
+		ids, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		return err
+	})
+	return ids, err
+}
+
+// RecordDelivery logs one delivery attempt.
+func (r *WebhooksRepo) RecordDelivery(ctx context.Context, d webhook.Delivery) error {
+	return r.observe("webhooks.record_delivery", func() error {
+		_, err := r.pool.Exec(ctx, `
+			INSERT INTO webhook_deliveries (webhook_id, job_id, kind, attempt, status_code, error, duration_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, d.WebhookID, d.JobID, d.Kind, d.Attempt, d.StatusCode, d.Error, d.DurationMS)
+		return err
+	})
+}
+
+// ListDeliveries returns the webhook's most recent delivery attempts, newest first.
+func (r *WebhooksRepo) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]webhook.Delivery, error) {
+	out := []webhook.Delivery{}
+	err := r.observe("webhooks.list_deliveries", func() error {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id, webhook_id::text, job_id::text, kind, attempt, status_code, error, duration_ms, created_at
+			FROM webhook_deliveries
+			WHERE webhook_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		`, webhookID, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var d webhook.Delivery
+			if err := rows.Scan(&d.ID, &d.WebhookID, &d.JobID, &d.Kind, &d.Attempt, &d.StatusCode, &d.Error, &d.DurationMS, &d.CreatedAt); err != nil {
+				return err
+			}
+			out = append(out, d)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
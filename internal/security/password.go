@@ -1,20 +1,271 @@
 package security
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
-// Hash password hashes a plain text password with bcrypt.
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Stored hashes carry their scheme as a "$<id>$" prefix (the PHC string
+// format), so verification can pick the right algorithm for any row.
+const (
+	SchemeBcrypt   = "bcrypt"
+	SchemeArgon2id = "argon2id"
+)
+
+var (
+	ErrMismatch      = errors.New("password does not match")
+	ErrUnknownScheme = errors.New("unknown password hash scheme")
+)
+
+// Hasher is one password hashing scheme.
+type Hasher interface {
+	Scheme() string
+	// Owns reports whether hash was produced by this scheme.
+	Owns(hash string) bool
+	Hash(plain string) (string, error)
+	Verify(hash, plain string) error
+	// Outdated reports whether hash used weaker parameters than the hasher's own.
+	Outdated(hash string) bool
+}
+
+// Params selects the active scheme and its cost; zero values take the defaults.
+type Params struct {
+	Scheme     string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// Argon2Params are the argon2id cost parameters (RFC 9106 naming).
+type Argon2Params struct {
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	argon2SaltLen            = 16
+	argon2KeyLen             = 32
+)
+
+func (p Params) withDefaults() Params {
+	if p.Scheme == "" {
+		p.Scheme = SchemeArgon2id
+	}
+	if p.BcryptCost == 0 {
+		p.BcryptCost = bcrypt.DefaultCost
+	}
+	if p.Argon2.MemoryKiB == 0 {
+		p.Argon2.MemoryKiB = defaultArgon2Memory
+	}
+	if p.Argon2.Iterations == 0 {
+		p.Argon2.Iterations = defaultArgon2Iterations
+	}
+	if p.Argon2.Parallelism == 0 {
+		p.Argon2.Parallelism = defaultArgon2Parallelism
+	}
+	return p
+}
+
+// Validate reports every problem with p at once, for startup checks.
+func (p Params) Validate() []string {
+	p = p.withDefaults()
+	var issues []string
+
+	switch p.Scheme {
+	case SchemeBcrypt, SchemeArgon2id:
+	default:
+		issues = append(issues, fmt.Sprintf("password hash scheme must be %q or %q (got %q)", SchemeBcrypt, SchemeArgon2id, p.Scheme))
+	}
+	if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+		issues = append(issues, fmt.Sprintf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	if p.Argon2.MemoryKiB < 8*uint32(p.Argon2.Parallelism) {
+		issues = append(issues, "argon2 memory must be at least 8 KiB per thread")
+	}
+	return issues
+}
+
+// Passwords hashes with the active scheme and verifies against any known one.
+type Passwords struct {
+	active  Hasher
+	hashers []Hasher
+}
+
+// NewPasswords registers bcrypt and argon2id with the costs in p and makes
+// p.Scheme the one new hashes use.
+func NewPasswords(p Params) (*Passwords, error) {
+	if issues := p.Validate(); len(issues) > 0 {
+		return nil, errors.New(strings.Join(issues, "; "))
+	}
+	p = p.withDefaults()
+
+	pw := &Passwords{hashers: []Hasher{
+		bcryptHasher{cost: p.BcryptCost},
+		argon2idHasher{params: p.Argon2},
+	}}
+	for _, h := range pw.hashers {
+		if h.Scheme() == p.Scheme {
+			pw.active = h
+		}
+	}
+	return pw, nil
+}
+
+var defaultPasswords, _ = NewPasswords(Params{})
+
+// DefaultPasswords uses argon2id at the default cost.
+func DefaultPasswords() *Passwords {
+	return defaultPasswords
+}
+
+func (p *Passwords) Hash(plain string) (string, error) {
+	return p.active.Hash(plain)
+}
+
+// Verify checks plain against hash, whatever scheme produced it. needsRehash
+// is true when the password matched but hash is not of the active scheme
+// and cost, so the caller should store a fresh Hash.
+func (p *Passwords) Verify(hash, plain string) (needsRehash bool, err error) {
+	for _, h := range p.hashers {
+		if !h.Owns(hash) {
+			continue
+		}
+		if err := h.Verify(hash, plain); err != nil {
+			return false, err
+		}
+		return h.Scheme() != p.active.Scheme() || h.Outdated(hash), nil
+	}
+	return false, ErrUnknownScheme
+}
+
+// HashPassword hashes a plain text password with the default scheme.
 func HashPassword(plain string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	return defaultPasswords.Hash(plain)
+}
 
+// CheckPassword compares a stored hash of any known scheme with a plaintext password.
+func CheckPassword(hash, plain string) error {
+	_, err := defaultPasswords.Verify(hash, plain)
+	return err
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (bcryptHasher) Scheme() string { return SchemeBcrypt }
+
+func (bcryptHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (b bcryptHasher) Hash(plain string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), b.cost)
 	if err != nil {
 		return "", err
 	}
-
 	return string(hash), nil
 }
 
-// helper that compares a bcrypt hash with a plaintext password.
+func (bcryptHasher) Verify(hash, plain string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
 
-func CheckPassword(hash, plain string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
+func (b bcryptHasher) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < b.cost
+}
+
+// argon2idHasher writes $argon2id$v=19$m=<KiB>,t=<iterations>,p=<threads>$<salt>$<key>
+// with unpadded base64, the format the reference implementation uses.
+type argon2idHasher struct {
+	params Argon2Params
+}
+
+const argon2idPrefix = "$argon2id$"
+
+func (argon2idHasher) Scheme() string { return SchemeArgon2id }
+
+func (argon2idHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, argon2idPrefix)
+}
+
+func (a argon2idHasher) Hash(plain string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := a.params
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.MemoryKiB, p.Parallelism, argon2KeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, p.MemoryKiB, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (argon2idHasher) Verify(hash, plain string) error {
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	got := argon2.IDKey([]byte(plain), salt, p.Iterations, p.MemoryKiB, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(got, key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func (a argon2idHasher) Outdated(hash string) bool {
+	p, _, _, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return p.MemoryKiB < a.params.MemoryKiB || p.Iterations < a.params.Iterations || p.Parallelism < a.params.Parallelism
+}
+
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("argon2id: malformed hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("argon2id: unsupported version %q", parts[2])
+	}
+
+	var p Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.MemoryKiB, &p.Iterations, &p.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("argon2id: malformed parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("argon2id: malformed salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("argon2id: malformed key")
+	}
+
+	return p, salt, key, nil
 }
@@ -0,0 +1,110 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheap argon2 costs keep the tests fast
+var testArgon2 = Argon2Params{MemoryKiB: 64, Iterations: 1, Parallelism: 1}
+
+func mustPasswords(t *testing.T, p Params) *Passwords {
+	t.Helper()
+	pw, err := NewPasswords(p)
+	if err != nil {
+		t.Fatalf("NewPasswords: %v", err)
+	}
+	return pw
+}
+
+func TestPasswords_VerifiesAcrossSchemes(t *testing.T) {
+	bcryptPW := mustPasswords(t, Params{Scheme: SchemeBcrypt, BcryptCost: bcrypt.MinCost, Argon2: testArgon2})
+	argonPW := mustPasswords(t, Params{Scheme: SchemeArgon2id, BcryptCost: bcrypt.MinCost, Argon2: testArgon2})
+
+	bcryptHash, err := bcryptPW.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("bcrypt hash: %v", err)
+	}
+	argonHash, err := argonPW.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("argon2id hash: %v", err)
+	}
+	if !strings.HasPrefix(bcryptHash, "$2a$") || !strings.HasPrefix(argonHash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected prefixes: %q, %q", bcryptHash, argonHash)
+	}
+
+	for _, pw := range []*Passwords{bcryptPW, argonPW} {
+		for _, hash := range []string{bcryptHash, argonHash} {
+			if _, err := pw.Verify(hash, "correct horse"); err != nil {
+				t.Fatalf("Verify(%q) = %v", hash[:10], err)
+			}
+			if _, err := pw.Verify(hash, "wrong horse"); !errors.Is(err, ErrMismatch) {
+				t.Fatalf("Verify(%q) with wrong password = %v, want ErrMismatch", hash[:10], err)
+			}
+		}
+	}
+
+	if _, err := argonPW.Verify("plaintext-in-the-db", "plaintext-in-the-db"); !errors.Is(err, ErrUnknownScheme) {
+		t.Fatalf("expected ErrUnknownScheme, got %v", err)
+	}
+}
+
+func TestPasswords_NeedsRehash(t *testing.T) {
+	cheapBcrypt := mustPasswords(t, Params{Scheme: SchemeBcrypt, BcryptCost: bcrypt.MinCost, Argon2: testArgon2})
+	lowCost, _ := cheapBcrypt.Hash("pw")
+
+	weakArgon := mustPasswords(t, Params{Scheme: SchemeArgon2id, Argon2: testArgon2})
+	weakArgonHash, _ := weakArgon.Hash("pw")
+
+	active := mustPasswords(t, Params{
+		Scheme:     SchemeArgon2id,
+		BcryptCost: bcrypt.MinCost + 1,
+		Argon2:     Argon2Params{MemoryKiB: 128, Iterations: 1, Parallelism: 1},
+	})
+	current, _ := active.Hash("pw")
+
+	tests := []struct {
+		name string
+		pw   *Passwords
+		hash string
+		want bool
+	}{
+		{"old scheme", active, lowCost, true},
+		{"weaker argon2 memory", active, weakArgonHash, true},
+		{"current scheme and cost", active, current, false},
+		{"bcrypt below configured cost", mustPasswords(t, Params{Scheme: SchemeBcrypt, BcryptCost: bcrypt.MinCost + 1}), lowCost, true},
+		{"bcrypt at configured cost", cheapBcrypt, lowCost, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pw.Verify(tt.hash, "pw")
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("needsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// a failed match never asks for a rehash
+	if got, _ := active.Verify(lowCost, "nope"); got {
+		t.Fatalf("mismatch should not request a rehash")
+	}
+}
+
+func TestParams_Validate(t *testing.T) {
+	if issues := (Params{}).Validate(); len(issues) != 0 {
+		t.Fatalf("defaults should be valid, got %v", issues)
+	}
+	if issues := (Params{Scheme: "md5"}).Validate(); len(issues) != 1 {
+		t.Fatalf("expected one issue for unknown scheme, got %v", issues)
+	}
+	if _, err := NewPasswords(Params{Argon2: Argon2Params{MemoryKiB: 8, Parallelism: 4}}); err == nil {
+		t.Fatalf("expected error when argon2 memory is below 8 KiB per thread")
+	}
+}
@@ -39,7 +39,8 @@ func DecodeEventCursor(cursor string) (EventCursor, error) {
 	if err := json.Unmarshal(raw, &c); err != nil {
 		return EventCursor{}, err
 	}
-	if c.ID == "" || c.StartAt.IsZero() {
+	// the id goes straight into a uuid comparison; reject tampered values here
+	if !IsUUID(c.ID) || c.StartAt.IsZero() {
 		return EventCursor{}, errors.New("invalid cursor payload")
 	}
 	return c, nil
@@ -70,7 +71,7 @@ func DecodeRegistrationCursor(cursor string) (RegistrationCursor, error) {
 	if err := json.Unmarshal(raw, &c); err != nil {
 		return RegistrationCursor{}, err
 	}
-	if c.ID == "" || c.CreatedAt.IsZero() {
+	if !IsUUID(c.ID) || c.CreatedAt.IsZero() {
 		return RegistrationCursor{}, errors.New("invalid cursor payload")
 	}
 	return c, nil
@@ -96,8 +97,72 @@ func DecodeJobCursor(cursor string) (JobCursor, error) {
 	if err := json.Unmarshal(raw, &c); err != nil {
 		return JobCursor{}, err
 	}
-	if c.ID == "" || c.UpdatedAt.IsZero() {
+	if !IsUUID(c.ID) || c.UpdatedAt.IsZero() {
 		return JobCursor{}, errors.New("invalid cursor payload")
 	}
 	return c, nil
 }
+
+type DeadLetterCursor struct {
+	FailedAt time.Time `json:"failedAt"`
+	ID       string    `json:"id"`
+}
+
+func EncodeDeadLetterCursor(failedAt time.Time, id string) (string, error) {
+	b, err := json.Marshal(DeadLetterCursor{FailedAt: failedAt, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func DecodeDeadLetterCursor(cursor string) (DeadLetterCursor, error) {
+	if cursor == "" {
+		return DeadLetterCursor{}, errors.New("empty cursor")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return DeadLetterCursor{}, err
+	}
+	var c DeadLetterCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return DeadLetterCursor{}, err
+	}
+	if !IsUUID(c.ID) || c.FailedAt.IsZero() {
+		return DeadLetterCursor{}, errors.New("invalid cursor payload")
+	}
+	return c, nil
+}
+
+// ScheduledJobCursor pages the scheduled jobs listing, which runs in
+// (run_at, id) order rather than the admin listing's updated_at.
+type ScheduledJobCursor struct {
+	RunAt time.Time `json:"runAt"`
+	ID    string    `json:"id"`
+}
+
+func EncodeScheduledJobCursor(runAt time.Time, id string) (string, error) {
+	b, err := json.Marshal(ScheduledJobCursor{RunAt: runAt, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func DecodeScheduledJobCursor(cursor string) (ScheduledJobCursor, error) {
+	if cursor == "" {
+		return ScheduledJobCursor{}, errors.New("empty cursor")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ScheduledJobCursor{}, err
+	}
+	var c ScheduledJobCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return ScheduledJobCursor{}, err
+	}
+	if !IsUUID(c.ID) || c.RunAt.IsZero() {
+		return ScheduledJobCursor{}, errors.New("invalid cursor payload")
+	}
+	return c, nil
+}
@@ -6,7 +6,7 @@ import (
 	"time"
 )
 
-func BuildEventsListCacheKey(limit int, city, category, tag *string, from, to *time.Time, query *string) string {
+func BuildEventsListCacheKey(limit int, city, category, tag *string, from, to *time.Time, query *string, includeDescriptionHTML bool) string {
 	c := ""
 	if city != nil {
 		c = strings.ToLower(strings.TrimSpace(*city))
@@ -32,11 +32,17 @@ func BuildEventsListCacheKey(limit int, city, category, tag *string, from, to *t
 		t = to.UTC().Format(time.RFC3339Nano)
 	}
 
+	inc := ""
+	if includeDescriptionHTML {
+		inc = "descriptionHtml"
+	}
+
 	return "events:list:v3:limit=" + strconv.Itoa(limit) +
 		":city=" + c +
 		":category=" + cat +
 		":tag=" + tg +
 		":from=" + f +
 		":to=" + t +
-		":q=" + q
+		":q=" + q +
+		":include=" + inc
 }
@@ -0,0 +1,19 @@
+// Package validation holds field-level problems found in request bodies. The
+// domain request types report them and the HTTP layer writes them out as the
+// 400 details.fields array.
+package validation
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Validator is implemented by request bodies with rules spanning several
+// fields, which per-field binding tags cannot express. Validate may run on a
+// body whose tag rules already failed, so it skips checks whose inputs are
+// missing.
+type Validator interface {
+	Validate() []FieldError
+}